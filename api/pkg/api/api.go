@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 
+	listener_autolinks "getsturdy.com/api/pkg/autolinks/listener"
 	workers_ci "getsturdy.com/api/pkg/ci/workers"
+	worker_emails "getsturdy.com/api/pkg/emails/worker"
 	worker_gc "getsturdy.com/api/pkg/gc/worker"
 	"getsturdy.com/api/pkg/gitserver"
 	httpx "getsturdy.com/api/pkg/http"
 	"getsturdy.com/api/pkg/metrics"
+	worker_notification "getsturdy.com/api/pkg/notification/worker"
 	"getsturdy.com/api/pkg/pprof"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
+	listener_webhooks "getsturdy.com/api/pkg/webhooks/listener"
+	worker_webhooks "getsturdy.com/api/pkg/webhooks/worker"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -20,13 +25,18 @@ type Starter interface {
 }
 
 type API struct {
-	httpServer       *httpx.Server
-	snapshotterQueue worker_snapshots.Queue
-	ciBuildQueue     *workers_ci.BuildQueue
-	gcQueue          *worker_gc.Queue
-	gitsrv           *gitserver.Server
-	pprof            *pprof.Server
-	metrics          *metrics.Server
+	httpServer        *httpx.Server
+	snapshotterQueue  worker_snapshots.Queue
+	ciBuildQueue      *workers_ci.BuildQueue
+	gcQueue           *worker_gc.Queue
+	emailQueue        *worker_emails.Queue
+	digestWorker      *worker_notification.Worker
+	webhooksQueue     *worker_webhooks.Queue
+	webhooksListener  *listener_webhooks.Listener
+	autolinksListener *listener_autolinks.Listener
+	gitsrv            *gitserver.Server
+	pprof             *pprof.Server
+	metrics           *metrics.Server
 }
 
 func ProvideAPI(
@@ -34,18 +44,28 @@ func ProvideAPI(
 	snapshotterQueue worker_snapshots.Queue,
 	ciBuildQueue *workers_ci.BuildQueue,
 	gcQueue *worker_gc.Queue,
+	emailQueue *worker_emails.Queue,
+	digestWorker *worker_notification.Worker,
+	webhooksQueue *worker_webhooks.Queue,
+	webhooksListener *listener_webhooks.Listener,
+	autolinksListener *listener_autolinks.Listener,
 	gitsrv *gitserver.Server,
 	pprof *pprof.Server,
 	metrics *metrics.Server,
 ) *API {
 	return &API{
-		httpServer:       httpServer,
-		snapshotterQueue: snapshotterQueue,
-		ciBuildQueue:     ciBuildQueue,
-		gcQueue:          gcQueue,
-		gitsrv:           gitsrv,
-		pprof:            pprof,
-		metrics:          metrics,
+		httpServer:        httpServer,
+		snapshotterQueue:  snapshotterQueue,
+		ciBuildQueue:      ciBuildQueue,
+		gcQueue:           gcQueue,
+		emailQueue:        emailQueue,
+		digestWorker:      digestWorker,
+		webhooksQueue:     webhooksQueue,
+		webhooksListener:  webhooksListener,
+		autolinksListener: autolinksListener,
+		gitsrv:            gitsrv,
+		pprof:             pprof,
+		metrics:           metrics,
 	}
 }
 
@@ -72,6 +92,40 @@ func (a *API) Start(ctx context.Context) error {
 		}
 		return nil
 	})
+	// email retry queue
+	wg.Go(func() error {
+		if err := a.emailQueue.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start email queue: %w", err)
+		}
+		return nil
+	})
+	// notification digests
+	wg.Go(func() error {
+		if err := a.digestWorker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start notification digest worker: %w", err)
+		}
+		return nil
+	})
+	// webhook deliveries
+	wg.Go(func() error {
+		if err := a.webhooksQueue.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start webhooks queue: %w", err)
+		}
+		return nil
+	})
+	wg.Go(func() error {
+		if err := a.webhooksListener.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start webhooks listener: %w", err)
+		}
+		return nil
+	})
+	// closing keywords in landed changes
+	wg.Go(func() error {
+		if err := a.autolinksListener.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start autolinks listener: %w", err)
+		}
+		return nil
+	})
 	// Start the git HTTP server
 	wg.Go(func() error {
 		if err := a.gitsrv.Start(); err != nil {
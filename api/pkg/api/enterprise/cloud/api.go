@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	"getsturdy.com/api/pkg/api"
+	listener_github "getsturdy.com/api/pkg/github/enterprise/listener"
 	workers_github "getsturdy.com/api/pkg/github/enterprise/workers"
+	worker_remote "getsturdy.com/api/pkg/remote/enterprise/worker"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -16,6 +18,8 @@ type API struct {
 	githubClonerQueue   *workers_github.ClonerQueue
 	githubImporterQueue workers_github.ImporterQueue
 	githubWebhooksQueue *workers_github.WebhooksQueue
+	remotePollerWorker  *worker_remote.Worker
+	githubListener      *listener_github.Listener
 }
 
 func ProvideAPI(
@@ -24,12 +28,16 @@ func ProvideAPI(
 	githubClonerQueue *workers_github.ClonerQueue,
 	githubImporterQueue workers_github.ImporterQueue,
 	githubWebhooksQueue *workers_github.WebhooksQueue,
+	remotePollerWorker *worker_remote.Worker,
+	githubListener *listener_github.Listener,
 ) *API {
 	return &API{
 		ossAPI:              ossAPI,
 		githubClonerQueue:   githubClonerQueue,
 		githubImporterQueue: githubImporterQueue,
 		githubWebhooksQueue: githubWebhooksQueue,
+		remotePollerWorker:  remotePollerWorker,
+		githubListener:      githubListener,
 	}
 }
 
@@ -61,5 +69,19 @@ func (a *API) Start(ctx context.Context) error {
 		return nil
 	})
 
+	wg.Go(func() error {
+		if err := a.remotePollerWorker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start remote poller worker: %w", err)
+		}
+		return nil
+	})
+
+	wg.Go(func() error {
+		if err := a.githubListener.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start github listener: %w", err)
+		}
+		return nil
+	})
+
 	return wg.Wait()
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 
 	"getsturdy.com/api/pkg/api"
+	listener_github "getsturdy.com/api/pkg/github/enterprise/listener"
 	workers_github "getsturdy.com/api/pkg/github/enterprise/workers"
 	workers_license "getsturdy.com/api/pkg/installations/enterprise/selfhosted/worker"
 	worker_installation_statistics "getsturdy.com/api/pkg/installations/statistics/enterprise/selfhosted/worker"
+	worker_remote "getsturdy.com/api/pkg/remote/enterprise/worker"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -20,6 +22,8 @@ type API struct {
 	licenseWorker                *workers_license.Worker
 	installationStatisticsWorker *worker_installation_statistics.Worker
 	githubWebhooksQueue          *workers_github.WebhooksQueue
+	remotePollerWorker           *worker_remote.Worker
+	githubListener               *listener_github.Listener
 }
 
 func ProvideAPI(
@@ -30,6 +34,8 @@ func ProvideAPI(
 	licenseWorker *workers_license.Worker,
 	installationStatisticsWorker *worker_installation_statistics.Worker,
 	githubWebhooksQueue *workers_github.WebhooksQueue,
+	remotePollerWorker *worker_remote.Worker,
+	githubListener *listener_github.Listener,
 ) *API {
 	return &API{
 		ossAPI:                       ossAPI,
@@ -38,6 +44,8 @@ func ProvideAPI(
 		licenseWorker:                licenseWorker,
 		installationStatisticsWorker: installationStatisticsWorker,
 		githubWebhooksQueue:          githubWebhooksQueue,
+		remotePollerWorker:           remotePollerWorker,
+		githubListener:               githubListener,
 	}
 }
 
@@ -83,5 +91,19 @@ func (a *API) Start(ctx context.Context) error {
 		return nil
 	})
 
+	wg.Go(func() error {
+		if err := a.remotePollerWorker.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start remote poller worker: %w", err)
+		}
+		return nil
+	})
+
+	wg.Go(func() error {
+		if err := a.githubListener.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start github listener: %w", err)
+		}
+		return nil
+	})
+
 	return wg.Wait()
 }
@@ -7,6 +7,7 @@ import (
 	"getsturdy.com/api/pkg/api"
 	"getsturdy.com/api/pkg/api/enterprise/selfhosted"
 	"getsturdy.com/api/pkg/di"
+	module_oidc "getsturdy.com/api/pkg/oidc/enterprise/selfhosted"
 	module_queue "getsturdy.com/api/pkg/queue/module"
 	module_remote "getsturdy.com/api/pkg/remote/module"
 )
@@ -14,6 +15,7 @@ import (
 func Module(c *di.Container) {
 	common(c)
 	c.Import(module_queue.Module)
+	c.Import(module_oidc.Module)
 	c.Import(module_remote.Module)
 
 	c.Register(api.ProvideAPI)
@@ -5,8 +5,10 @@ import (
 
 	module_workspace_activity "getsturdy.com/api/pkg/activity/module"
 	module_analytics "getsturdy.com/api/pkg/analytics/module"
+	module_audit "getsturdy.com/api/pkg/audit/module"
 	module_auth "getsturdy.com/api/pkg/auth/module"
 	module_author "getsturdy.com/api/pkg/author/module"
+	module_autolinks "getsturdy.com/api/pkg/autolinks/module"
 	module_aws "getsturdy.com/api/pkg/aws/module"
 	module_blobs "getsturdy.com/api/pkg/blobs/module"
 	module_change "getsturdy.com/api/pkg/changes/module"
@@ -21,9 +23,12 @@ import (
 	module_emails "getsturdy.com/api/pkg/emails/module"
 	module_email_transactional "getsturdy.com/api/pkg/emails/transactional/module"
 	module_events "getsturdy.com/api/pkg/events"
+	module_events_fanout "getsturdy.com/api/pkg/events/fanout"
+	module_exports "getsturdy.com/api/pkg/exports/module"
 	module_features "getsturdy.com/api/pkg/features/module"
 	module_file "getsturdy.com/api/pkg/file/module"
 	module_gc "getsturdy.com/api/pkg/gc/module"
+	module_gitlab "getsturdy.com/api/pkg/gitlab/module"
 	module_gitserver "getsturdy.com/api/pkg/gitserver"
 	module_graphql "getsturdy.com/api/pkg/graphql"
 	module_http "getsturdy.com/api/pkg/http/module"
@@ -40,17 +45,22 @@ import (
 	module_onboarding "getsturdy.com/api/pkg/onboarding/module"
 	module_onetime "getsturdy.com/api/pkg/onetime/module"
 	module_organization "getsturdy.com/api/pkg/organization/module"
+	module_personaltokens "getsturdy.com/api/pkg/personaltokens/module"
 	module_pki "getsturdy.com/api/pkg/pki/module"
 	"getsturdy.com/api/pkg/pprof"
 	module_presence "getsturdy.com/api/pkg/presence/module"
 	module_review "getsturdy.com/api/pkg/review/module"
+	module_scim "getsturdy.com/api/pkg/scim/module"
 	module_servicetokens "getsturdy.com/api/pkg/servicetokens/module"
+	module_sessions "getsturdy.com/api/pkg/sessions/module"
 	module_statuses "getsturdy.com/api/pkg/statuses/module"
 	module_suggestions "getsturdy.com/api/pkg/suggestions/module"
 	module_sync "getsturdy.com/api/pkg/sync/module"
+	module_twofactor "getsturdy.com/api/pkg/twofactor/module"
 	module_user "getsturdy.com/api/pkg/users/module"
 	module_view "getsturdy.com/api/pkg/view/module"
 	module_waitinglist "getsturdy.com/api/pkg/waitinglist"
+	module_webhooks "getsturdy.com/api/pkg/webhooks/module"
 	module_workspace "getsturdy.com/api/pkg/workspaces/module"
 	module_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/module"
 	module_vcs "getsturdy.com/api/vcs/module"
@@ -64,8 +74,10 @@ func common(c *di.Container) {
 	c.Import(module_aws.Module)
 	c.Import(module_blobs.Module)
 	c.Import(module_analytics.Module)
+	c.Import(module_audit.Module)
 	c.Import(module_auth.Module)
 	c.Import(module_author.Module)
+	c.Import(module_autolinks.Module)
 	c.Import(module_change.Module)
 	c.Import(module_ci.Module)
 	c.Import(module_codebase.Module)
@@ -75,9 +87,12 @@ func common(c *di.Container) {
 	c.Import(module_emails.Module)
 	c.Import(module_email_transactional.Module)
 	c.Import(module_events.Module)
+	c.Import(module_events_fanout.Module)
+	c.Import(module_exports.Module)
 	c.Import(module_features.Module)
 	c.Import(module_file.Module)
 	c.Import(module_gc.Module)
+	c.Import(module_gitlab.Module)
 	c.Import(module_gitserver.Module)
 	c.Import(module_graphql.Module)
 	c.Import(module_http.Module)
@@ -93,16 +108,21 @@ func common(c *di.Container) {
 	c.Import(module_onboarding.Module)
 	c.Import(module_onetime.Module)
 	c.Import(module_organization.Module)
+	c.Import(module_personaltokens.Module)
 	c.Import(module_pki.Module)
 	c.Import(module_presence.Module)
 	c.Import(module_review.Module)
+	c.Import(module_scim.Module)
 	c.Import(module_servicetokens.Module)
+	c.Import(module_sessions.Module)
 	c.Import(module_statuses.Module)
 	c.Import(module_suggestions.Module)
 	c.Import(module_sync.Module)
+	c.Import(module_twofactor.Module)
 	c.Import(module_user.Module)
 	c.Import(module_view.Module)
 	c.Import(module_waitinglist.Module)
+	c.Import(module_webhooks.Module)
 	c.Import(module_workspace.Module)
 	c.Import(module_workspace_activity.Module)
 	c.Import(module_workspace_watchers.Module)
@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+)
+
+type ID string
+
+type Action string
+
+const (
+	ActionCodebaseCreated     Action = "codebase.created"
+	ActionCodebaseUpdated     Action = "codebase.updated"
+	ActionCodebaseUserAdded   Action = "codebase.user_added"
+	ActionCodebaseUserRemoved Action = "codebase.user_removed"
+	ActionCodebaseExported    Action = "codebase.exported"
+	ActionCodebaseImported    Action = "codebase.imported"
+
+	ActionACLUpdated Action = "acl.updated"
+
+	ActionServiceTokenCreated Action = "service_token.created"
+	ActionServiceTokenRevoked Action = "service_token.revoked"
+
+	ActionWebhookCreated Action = "webhook.created"
+	ActionWebhookDeleted Action = "webhook.deleted"
+
+	ActionAutolinkCreated Action = "autolink.created"
+	ActionAutolinkDeleted Action = "autolink.deleted"
+
+	ActionRemoteCreated Action = "remote.created"
+	ActionRemoteUpdated Action = "remote.updated"
+	ActionRemoteDeleted Action = "remote.deleted"
+
+	ActionRemoteHostKeyConfirmed Action = "remote.host_key_confirmed"
+	ActionRemoteHostKeyRotated   Action = "remote.host_key_rotated"
+
+	ActionGitLabIntegrationCreated Action = "gitlab_integration.created"
+	ActionGitLabIntegrationUpdated Action = "gitlab_integration.updated"
+
+	ActionWorkspaceArchived   Action = "workspace.archived"
+	ActionWorkspaceUnarchived Action = "workspace.unarchived"
+	ActionChangeLanded        Action = "change.landed"
+
+	ActionOrganizationCreated       Action = "organization.created"
+	ActionOrganizationUpdated       Action = "organization.updated"
+	ActionOrganizationMemberAdded   Action = "organization.member_added"
+	ActionOrganizationMemberRemoved Action = "organization.member_removed"
+
+	ActionOrganizationMemberTwoFactorReset Action = "organization.member_two_factor_reset"
+
+	ActionInstallationSigningKeysRotated Action = "installation.signing_keys_rotated"
+
+	ActionGarbageCollectionTriggered     Action = "garbage_collection.triggered"
+	ActionGarbageCollectionPolicyUpdated Action = "garbage_collection.policy_updated"
+)
+
+type TargetType string
+
+const (
+	TargetCodebase     TargetType = "codebase"
+	TargetACL          TargetType = "acl"
+	TargetServiceToken TargetType = "service_token"
+	TargetRemote       TargetType = "remote"
+	TargetWebhook      TargetType = "webhook"
+	TargetAutolink     TargetType = "autolink"
+	TargetWorkspace    TargetType = "workspace"
+	TargetChange       TargetType = "change"
+	TargetOrganization TargetType = "organization"
+	TargetUser         TargetType = "user"
+	TargetInstallation TargetType = "installation"
+
+	TargetGitLabIntegration TargetType = "gitlab_integration"
+)
+
+// Entry is a single record in the audit log. Entries are never updated or deleted.
+type Entry struct {
+	ID             ID            `json:"id" db:"id"`
+	OrganizationID *string       `json:"organization_id,omitempty" db:"organization_id"`
+	CodebaseID     *codebases.ID `json:"codebase_id,omitempty" db:"codebase_id"`
+	// ActorID is the user that performed the action, it is nil if the action was performed by Sturdy
+	// itself, or by a service token.
+	ActorID    *users.ID  `json:"actor_id,omitempty" db:"actor_id"`
+	IP         *string    `json:"ip,omitempty" db:"ip"`
+	Action     Action     `json:"action" db:"action"`
+	TargetType TargetType `json:"target_type" db:"target_type"`
+	TargetID   string     `json:"target_id" db:"target_id"`
+	// Before and After are the JSON encoded values of the target before and after the action.
+	Before    Value     `json:"before,omitempty" db:"before"`
+	After     Value     `json:"after,omitempty" db:"after"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Value is a JSON encoded value. It's stored as JSONB in the database.
+type Value json.RawMessage
+
+func NewValue(v any) (Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return data, nil
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	*v = append((*v)[0:0], data...)
+	return nil
+}
+
+func (v Value) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	// JSONB columns don't accept bytea
+	return string(v), nil
+}
+
+func (v *Value) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*v = nil
+	case []byte:
+		*v = append(Value(nil), src...)
+	case string:
+		*v = Value(src)
+	default:
+		return fmt.Errorf("unsupported type %T", src)
+	}
+	return nil
+}
+
+type EntryOptions struct {
+	OrganizationID *string
+	CodebaseID     *codebases.ID
+	Before         any
+	After          any
+}
+
+type EntryOption func(*EntryOptions)
+
+// OrganizationID sets the organization that the entry belongs to.
+func OrganizationID(id string) EntryOption {
+	return func(o *EntryOptions) {
+		o.OrganizationID = &id
+	}
+}
+
+// CodebaseID sets the codebase that the entry belongs to. If no organization is set, the entry
+// belongs to the organization of the codebase.
+func CodebaseID(id codebases.ID) EntryOption {
+	return func(o *EntryOptions) {
+		o.CodebaseID = &id
+	}
+}
+
+// Before sets the value of the target before the action. Values are stored as JSON, make sure
+// to not pass any secrets.
+func Before(v any) EntryOption {
+	return func(o *EntryOptions) {
+		o.Before = v
+	}
+}
+
+// After sets the value of the target after the action. Values are stored as JSON, make sure
+// to not pass any secrets.
+func After(v any) EntryOption {
+	return func(o *EntryOptions) {
+		o.After = v
+	}
+}
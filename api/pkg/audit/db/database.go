@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/audit"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{
+		db: db,
+	}
+}
+
+func (d *database) Create(ctx context.Context, entry *audit.Entry) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO audit_log (
+			id, organization_id, codebase_id, actor_id, ip, action, target_type, target_id, before, after, created_at
+		) VALUES (
+			:id, :organization_id, :codebase_id, :actor_id, :ip, :action, :target_type, :target_id, :before, :after, :created_at
+		)
+	`, entry); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) ListByOrganizationID(ctx context.Context, organizationID string, filter Filter) ([]*audit.Entry, error) {
+	actions := make([]string, 0, len(filter.Actions))
+	for _, action := range filter.Actions {
+		actions = append(actions, string(action))
+	}
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	entries := []*audit.Entry{}
+	if err := d.db.SelectContext(ctx, &entries, `
+		SELECT
+			id, organization_id, codebase_id, actor_id, ip, action, target_type, target_id, before, after, created_at
+		FROM audit_log
+		WHERE organization_id = $1
+		  AND (cardinality($2::TEXT[]) = 0 OR action = ANY($2))
+		  AND ($3::TEXT IS NULL OR actor_id = $3)
+		  AND ($4::TEXT IS NULL OR codebase_id = $4)
+		  AND ($5::TEXT IS NULL OR target_type = $5)
+		  AND ($6::TEXT IS NULL OR target_id = $6)
+		  AND ($7::TIMESTAMP WITH TIME ZONE IS NULL OR created_at >= $7)
+		  AND ($8::TIMESTAMP WITH TIME ZONE IS NULL OR created_at < $8)
+		  AND ($9::TEXT IS NULL OR (created_at, id) < (SELECT created_at, id FROM audit_log WHERE id = $9))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $10
+	`, organizationID,
+		pq.Array(actions),
+		filter.ActorID,
+		filter.CodebaseID,
+		filter.TargetType,
+		filter.TargetID,
+		filter.Since,
+		filter.Until,
+		filter.Before,
+		limit,
+	); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/audit"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	// entries are stored in the order that they were created
+	entries []*audit.Entry
+}
+
+func NewMemory() Repository {
+	return &memory{}
+}
+
+func (m *memory) Create(_ context.Context, entry *audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memory) ListByOrganizationID(_ context.Context, organizationID string, filter Filter) ([]*audit.Entry, error) {
+	var res []*audit.Entry
+	before := filter.Before != nil
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		entry := m.entries[i]
+		if before {
+			before = entry.ID != *filter.Before
+			continue
+		}
+		if entry.OrganizationID == nil || *entry.OrganizationID != organizationID {
+			continue
+		}
+		if !matches(entry, filter) {
+			continue
+		}
+		res = append(res, entry)
+		if filter.Limit > 0 && len(res) == filter.Limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func matches(entry *audit.Entry, filter Filter) bool {
+	if len(filter.Actions) > 0 {
+		found := false
+		for _, action := range filter.Actions {
+			if entry.Action == action {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *filter.ActorID) {
+		return false
+	}
+	if filter.CodebaseID != nil && (entry.CodebaseID == nil || *entry.CodebaseID != *filter.CodebaseID) {
+		return false
+	}
+	if filter.TargetType != nil && entry.TargetType != *filter.TargetType {
+		return false
+	}
+	if filter.TargetID != nil && entry.TargetID != *filter.TargetID {
+		return false
+	}
+	if filter.Since != nil && entry.CreatedAt.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && !entry.CreatedAt.Before(*filter.Until) {
+		return false
+	}
+	return true
+}
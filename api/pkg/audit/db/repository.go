@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+)
+
+// Filter is used to filter the entries of an organization. All fields are optional.
+type Filter struct {
+	Actions    []audit.Action
+	ActorID    *users.ID
+	CodebaseID *codebases.ID
+	TargetType *audit.TargetType
+	TargetID   *string
+	Since      *time.Time
+	Until      *time.Time
+	// Before is the ID of an entry, only entries that are older than it are returned.
+	Before *audit.ID
+	Limit  int
+}
+
+// Repository is append only, there is intentionally no way to update or delete entries.
+type Repository interface {
+	Create(context.Context, *audit.Entry) error
+	// ListByOrganizationID returns the entries of the organization, newest first.
+	ListByOrganizationID(context.Context, string, Filter) ([]*audit.Entry, error)
+}
@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type auditLogRootResolver struct {
+	service             *service_audit.Service
+	organizationService *service_organization.Service
+	authService         *service_auth.Service
+
+	authorRootResolver resolvers.AuthorRootResolver
+}
+
+func New(
+	service *service_audit.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+
+	authorRootResolver resolvers.AuthorRootResolver,
+) resolvers.AuditLogRootResolver {
+	return &auditLogRootResolver{
+		service:             service,
+		organizationService: organizationService,
+		authService:         authService,
+
+		authorRootResolver: authorRootResolver,
+	}
+}
+
+func (r *auditLogRootResolver) InternalListByOrganizationID(ctx context.Context, organizationID string, args resolvers.OrganizationAuditLogArgs) ([]resolvers.AuditLogEntryResolver, error) {
+	org, err := r.organizationService.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.authService.CanAdministrateOrganization(ctx, org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	f, err := filter(args.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := r.service.ListByOrganizationID(ctx, org.ID, f)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.AuditLogEntryResolver, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, &auditLogEntryResolver{root: r, entry: entry})
+	}
+	return res, nil
+}
+
+const (
+	defaultLimit = 100
+	maxLimit     = 100
+)
+
+func filter(input *resolvers.OrganizationAuditLogInput) (db_audit.Filter, error) {
+	f := db_audit.Filter{Limit: defaultLimit}
+	if input == nil {
+		return f, nil
+	}
+
+	if input.Limit != nil {
+		switch limit := int(*input.Limit); {
+		case limit < 1:
+			// a limit of 0 would list all entries
+			return db_audit.Filter{}, gqlerrors.Error(gqlerrors.ErrBadRequest, "limit", "limit must be at least 1")
+		case limit > maxLimit:
+			f.Limit = maxLimit
+		default:
+			f.Limit = limit
+		}
+	}
+	if input.Actions != nil {
+		for _, action := range *input.Actions {
+			f.Actions = append(f.Actions, audit.Action(action))
+		}
+	}
+	if input.ActorID != nil {
+		actorID := users.ID(*input.ActorID)
+		f.ActorID = &actorID
+	}
+	if input.CodebaseID != nil {
+		codebaseID := codebases.ID(*input.CodebaseID)
+		f.CodebaseID = &codebaseID
+	}
+	if input.TargetType != nil {
+		targetType := audit.TargetType(*input.TargetType)
+		f.TargetType = &targetType
+	}
+	if input.TargetID != nil {
+		targetID := string(*input.TargetID)
+		f.TargetID = &targetID
+	}
+	if input.Since != nil {
+		since := time.Unix(int64(*input.Since), 0)
+		f.Since = &since
+	}
+	if input.Until != nil {
+		until := time.Unix(int64(*input.Until), 0)
+		f.Until = &until
+	}
+	if input.Before != nil {
+		before := audit.ID(*input.Before)
+		f.Before = &before
+	}
+	return f, nil
+}
+
+type auditLogEntryResolver struct {
+	root  *auditLogRootResolver
+	entry *audit.Entry
+}
+
+func (r *auditLogEntryResolver) ID() graphql.ID {
+	return graphql.ID(r.entry.ID)
+}
+
+func (r *auditLogEntryResolver) Action() string {
+	return string(r.entry.Action)
+}
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (resolvers.AuthorResolver, error) {
+	if r.entry.ActorID == nil {
+		return nil, nil
+	}
+	author, err := r.root.authorRootResolver.Author(ctx, graphql.ID(*r.entry.ActorID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return author, nil
+}
+
+func (r *auditLogEntryResolver) IP() *string {
+	return r.entry.IP
+}
+
+func (r *auditLogEntryResolver) TargetType() string {
+	return string(r.entry.TargetType)
+}
+
+func (r *auditLogEntryResolver) TargetID() graphql.ID {
+	return graphql.ID(r.entry.TargetID)
+}
+
+func (r *auditLogEntryResolver) CodebaseID() *graphql.ID {
+	if r.entry.CodebaseID == nil {
+		return nil
+	}
+	id := graphql.ID(*r.entry.CodebaseID)
+	return &id
+}
+
+func (r *auditLogEntryResolver) Before() *string {
+	if len(r.entry.Before) == 0 {
+		return nil
+	}
+	s := string(r.entry.Before)
+	return &s
+}
+
+func (r *auditLogEntryResolver) After() *string {
+	if len(r.entry.After) == 0 {
+		return nil
+	}
+	s := string(r.entry.After)
+	return &s
+}
+
+func (r *auditLogEntryResolver) CreatedAt() int32 {
+	return int32(r.entry.CreatedAt.Unix())
+}
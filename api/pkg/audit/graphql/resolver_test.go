@@ -0,0 +1,33 @@
+package graphql
+
+import (
+	"testing"
+
+	"getsturdy.com/api/pkg/graphql/resolvers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_limit(t *testing.T) {
+	limit := func(l int32) *resolvers.OrganizationAuditLogInput {
+		return &resolvers.OrganizationAuditLogInput{Limit: &l}
+	}
+
+	f, err := filter(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultLimit, f.Limit)
+
+	f, err = filter(limit(10))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, f.Limit)
+
+	f, err = filter(limit(100000))
+	assert.NoError(t, err)
+	assert.Equal(t, maxLimit, f.Limit)
+
+	_, err = filter(limit(0))
+	assert.Error(t, err)
+
+	_, err = filter(limit(-1))
+	assert.Error(t, err)
+}
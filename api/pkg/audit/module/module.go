@@ -0,0 +1,16 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/audit/db"
+	"getsturdy.com/api/pkg/audit/graphql"
+	"getsturdy.com/api/pkg/audit/routes"
+	"getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/di"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Import(graphql.Module)
+	c.Import(routes.Module)
+	c.Import(service.Module)
+}
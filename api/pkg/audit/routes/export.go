@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ExportRoute func(*gin.Context)
+
+const exportPageSize = 1000
+
+// NewExportRoute returns a route that exports the audit log of an organization as JSON lines, one entry
+// per line, newest first. Only the administrators of the organization can export it.
+//
+// The same filters as in the GraphQL API are supported as query parameters: action (can be repeated),
+// actor_id, codebase_id, target_type, target_id, since and until (RFC 3339).
+func NewExportRoute(
+	auditService *service_audit.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+	logger *zap.Logger,
+) ExportRoute {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		org, err := organizationService.GetByID(ctx, c.Param("id"))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if err := authService.CanAdministrateOrganization(ctx, org); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		filter, err := parseFilter(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Limit = exportPageSize
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "audit-log-"+org.ID+".jsonl"))
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		for {
+			entries, err := auditService.ListByOrganizationID(ctx, org.ID, filter)
+			if err != nil {
+				// headers are already sent, all we can do is to stop writing
+				logger.Error("failed to list audit log", zap.Error(err))
+				return
+			}
+
+			for _, entry := range entries {
+				if err := encoder.Encode(entry); err != nil {
+					logger.Error("failed to write audit log entry", zap.Error(err))
+					return
+				}
+			}
+			c.Writer.Flush()
+
+			if len(entries) < exportPageSize {
+				return
+			}
+			filter.Before = &entries[len(entries)-1].ID
+		}
+	}
+}
+
+func parseFilter(c *gin.Context) (db_audit.Filter, error) {
+	filter := db_audit.Filter{}
+
+	for _, action := range c.QueryArray("action") {
+		filter.Actions = append(filter.Actions, audit.Action(action))
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id := users.ID(actorID)
+		filter.ActorID = &id
+	}
+	if codebaseID := c.Query("codebase_id"); codebaseID != "" {
+		id := codebases.ID(codebaseID)
+		filter.CodebaseID = &id
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		t := audit.TargetType(targetType)
+		filter.TargetType = &t
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		filter.TargetID = &targetID
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since: %w", err)
+		}
+		filter.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return filter, fmt.Errorf("invalid until: %w", err)
+		}
+		filter.Until = &t
+	}
+
+	return filter, nil
+}
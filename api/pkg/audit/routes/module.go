@@ -0,0 +1,7 @@
+package routes
+
+import "getsturdy.com/api/pkg/di"
+
+func Module(c *di.Container) {
+	c.Register(NewExportRoute)
+}
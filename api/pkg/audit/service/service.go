@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	"getsturdy.com/api/pkg/auth"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/ip"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo         db_audit.Repository
+	codebaseRepo db_codebases.CodebaseRepository
+}
+
+func New(
+	repo db_audit.Repository,
+	codebaseRepo db_codebases.CodebaseRepository,
+) *Service {
+	return &Service{
+		repo:         repo,
+		codebaseRepo: codebaseRepo,
+	}
+}
+
+// Record appends an entry to the audit log. The actor and the IP address are read from the context.
+func (svc *Service) Record(ctx context.Context, action audit.Action, targetType audit.TargetType, targetID string, opts ...audit.EntryOption) error {
+	options := &audit.EntryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	entry := &audit.Entry{
+		ID:             audit.ID(uuid.NewString()),
+		OrganizationID: options.OrganizationID,
+		CodebaseID:     options.CodebaseID,
+		Action:         action,
+		TargetType:     targetType,
+		TargetID:       targetID,
+		CreatedAt:      time.Now(),
+	}
+
+	if userID, err := auth.UserID(ctx); err == nil {
+		entry.ActorID = &userID
+	}
+
+	if remoteIP, ok := ip.FromContext(ctx); ok && remoteIP != nil && *remoteIP != nil {
+		s := remoteIP.String()
+		entry.IP = &s
+	}
+
+	if entry.OrganizationID == nil && entry.CodebaseID != nil {
+		cb, err := svc.codebaseRepo.GetAllowArchived(*entry.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get codebase: %w", err)
+		}
+		entry.OrganizationID = cb.OrganizationID
+	}
+
+	var err error
+	if entry.Before, err = audit.NewValue(options.Before); err != nil {
+		return err
+	}
+	if entry.After, err = audit.NewValue(options.After); err != nil {
+		return err
+	}
+
+	if err := svc.repo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+func (svc *Service) ListByOrganizationID(ctx context.Context, organizationID string, filter db_audit.Filter) ([]*audit.Entry, error) {
+	entries, err := svc.repo.ListByOrganizationID(ctx, organizationID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	return entries, nil
+}
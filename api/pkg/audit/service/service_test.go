@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/internal/inmemory"
+	"getsturdy.com/api/pkg/ip"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	orgID := "org"
+	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
+	assert.NoError(t, codebaseRepo.Create(codebases.Codebase{ID: "codebase", OrganizationID: &orgID}))
+
+	svc := service_audit.New(db_audit.NewMemory(), codebaseRepo)
+
+	ctx := auth.NewContext(context.Background(), &auth.Subject{ID: "alice", Type: auth.SubjectUser})
+	ctx = ip.NewContext(ctx, net.ParseIP("127.0.0.1"))
+
+	type value struct {
+		Name string `json:"name"`
+	}
+
+	assert.NoError(t, svc.Record(ctx, audit.ActionCodebaseUpdated, audit.TargetCodebase, "codebase",
+		audit.CodebaseID("codebase"),
+		audit.Before(value{Name: "before"}),
+		audit.After(value{Name: "after"}),
+	))
+	assert.NoError(t, svc.Record(context.Background(), audit.ActionOrganizationUpdated, audit.TargetOrganization, orgID,
+		audit.OrganizationID(orgID),
+	))
+
+	entries, err := svc.ListByOrganizationID(context.Background(), orgID, db_audit.Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		// newest first
+		assert.Equal(t, audit.ActionOrganizationUpdated, entries[0].Action)
+		assert.Nil(t, entries[0].ActorID)
+		assert.Nil(t, entries[0].IP)
+
+		assert.Equal(t, audit.ActionCodebaseUpdated, entries[1].Action)
+		assert.Equal(t, &orgID, entries[1].OrganizationID)
+		assert.Equal(t, users.ID("alice"), *entries[1].ActorID)
+		assert.Equal(t, "127.0.0.1", *entries[1].IP)
+		assert.JSONEq(t, `{"name":"before"}`, string(entries[1].Before))
+		assert.JSONEq(t, `{"name":"after"}`, string(entries[1].After))
+	}
+
+	actorID := users.ID("alice")
+	filtered, err := svc.ListByOrganizationID(context.Background(), orgID, db_audit.Filter{ActorID: &actorID})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+
+	older, err := svc.ListByOrganizationID(context.Background(), orgID, db_audit.Filter{Before: &entries[0].ID})
+	assert.NoError(t, err)
+	if assert.Len(t, older, 1) {
+		assert.Equal(t, entries[1].ID, older[0].ID)
+	}
+
+	none, err := svc.ListByOrganizationID(context.Background(), "other-org", db_audit.Filter{})
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+}
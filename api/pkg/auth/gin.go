@@ -8,6 +8,8 @@ import (
 	"getsturdy.com/api/pkg/ctxlog"
 	"getsturdy.com/api/pkg/jwt"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	"getsturdy.com/api/pkg/users"
 
 	"github.com/gin-gonic/gin"
@@ -18,23 +20,34 @@ const (
 	ginContextKey = "auth.subject"
 )
 
-func GinMiddleware(logger *zap.Logger, jwtService *service_jwt.Service) gin.HandlerFunc {
+// GinMiddleware authenticates the request. Requests that are authenticated with a personal access token
+// must have the api:write scope to use other methods than GET, HEAD and OPTIONS, and the api:read scope
+// otherwise.
+func GinMiddleware(logger *zap.Logger, jwtService *service_jwt.Service, personalTokensService *service_personaltokens.Service, sessionsService *service_sessions.Service) gin.HandlerFunc {
+	return ginMiddleware(logger, jwtService, personalTokensService, sessionsService, isWriteRequest)
+}
+
+// GinGraphQLMiddleware authenticates GraphQL requests. Requests that are authenticated with a personal access
+// token must have one of the api scopes. Queries are sent with POST, so the GraphQL handler checks that
+// mutations have the api:write scope.
+func GinGraphQLMiddleware(logger *zap.Logger, jwtService *service_jwt.Service, personalTokensService *service_personaltokens.Service, sessionsService *service_sessions.Service) gin.HandlerFunc {
+	return ginMiddleware(logger, jwtService, personalTokensService, sessionsService, func(*gin.Context) bool { return false })
+}
+
+func ginMiddleware(logger *zap.Logger, jwtService *service_jwt.Service, personalTokensService *service_personaltokens.Service, sessionsService *service_sessions.Service, isWrite func(*gin.Context) bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, shouldRefresh, err := jwtFromRequest(c.Request, jwtService)
-		if err != nil && !errors.Is(err, ErrUnauthenticated) {
+		subject, err := subjectFromGinRequest(c, logger, jwtService, personalTokensService, sessionsService)
+		if err != nil {
 			ctxlog.ErrorOrWarn(logger, "failed to authenticate user", err)
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
 
-		if shouldRefresh {
-			if err := refreshToken(c, token, jwtService); err != nil {
-				ctxlog.ErrorOrWarn(logger, "failed to refresh token", err)
-			}
+		if err := subject.RequireAPIScope(isWrite(c)); err != nil {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
 		}
 
-		subject := subjectFromToken(token)
-
 		c.Set(ginContextKey, subject)
 		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), subject))
 
@@ -42,19 +55,97 @@ func GinMiddleware(logger *zap.Logger, jwtService *service_jwt.Service) gin.Hand
 	}
 }
 
-func refreshToken(c *gin.Context, token *jwt.Token, jwtService *service_jwt.Service) error {
-	token, err := jwtService.IssueToken(c.Request.Context(), token.Subject, oneMonth, token.Type)
+func isWriteRequest(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+func subjectFromGinRequest(c *gin.Context, logger *zap.Logger, jwtService *service_jwt.Service, personalTokensService *service_personaltokens.Service, sessionsService *service_sessions.Service) (*Subject, error) {
+	personalToken, found, err := personalTokenFromRequest(c.Request, personalTokensService)
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return subjectFromToken(nil), nil
+	case err != nil:
+		return nil, err
+	case found:
+		return subjectFromPersonalToken(personalToken), nil
+	}
+
+	token, shouldRefresh, err := jwtFromRequest(c.Request, jwtService)
+	if err != nil && !errors.Is(err, ErrUnauthenticated) {
+		return nil, err
+	}
+
+	if token != nil && token.SessionID != "" {
+		remoteIP, _ := c.RemoteIP()
+		if err := sessionsService.Touch(c.Request.Context(), token.SessionID, remoteIP); err != nil {
+			ctxlog.ErrorOrWarn(logger, "failed to touch session", err)
+		}
+	}
+
+	if shouldRefresh {
+		if err := refreshToken(c, token, jwtService, sessionsService); err != nil {
+			ctxlog.ErrorOrWarn(logger, "failed to refresh token", err)
+		}
+	}
+
+	return subjectFromToken(token), nil
+}
+
+// refreshToken issues a new token in the same session. Tokens that were issued before sessions existed are
+// moved into a new session.
+func refreshToken(c *gin.Context, token *jwt.Token, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) error {
+	sessionID := token.SessionID
+	if sessionID == "" {
+		var err error
+		if sessionID, err = newSession(c, users.ID(token.Subject), sessionsService); err != nil {
+			return err
+		}
+	}
+	return setAuthCookieForSession(c, users.ID(token.Subject), sessionID, jwtService)
+}
+
+// SetAuthCookieForUser logs the user in, by starting a new session.
+func SetAuthCookieForUser(c *gin.Context, userID users.ID, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) error {
+	sessionID, err := newSession(c, userID, sessionsService)
 	if err != nil {
-		return fmt.Errorf("failed to issue new token: %w", err)
+		return err
 	}
+	return setAuthCookieForSession(c, userID, sessionID, jwtService)
+}
 
-	isSecure := c.Request.URL.Scheme == "https"
-	setAuthCookie(c.Writer, isSecure, token.Token)
-	return nil
+// RefreshAuthCookie issues a new auth cookie in the session that the request is authenticated with. A new
+// session is started if the request is not authenticated with one. Requests that are authenticated with a
+// personal access token do not get a cookie.
+func RefreshAuthCookie(c *gin.Context, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) error {
+	subject, ok := FromContext(c.Request.Context())
+	if !ok || subject.Type != SubjectUser {
+		return ErrUnauthenticated
+	}
+	if subject.IsPersonalAccessToken() {
+		return nil
+	}
+	if subject.SessionID == "" {
+		return SetAuthCookieForUser(c, users.ID(subject.ID), jwtService, sessionsService)
+	}
+	return setAuthCookieForSession(c, users.ID(subject.ID), subject.SessionID, jwtService)
+}
+
+func newSession(c *gin.Context, userID users.ID, sessionsService *service_sessions.Service) (string, error) {
+	remoteIP, _ := c.RemoteIP()
+	session, err := sessionsService.Create(c.Request.Context(), userID, c.Request.UserAgent(), remoteIP)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return session.ID, nil
 }
 
-func SetAuthCookieForUser(c *gin.Context, userID users.ID, jwtService *service_jwt.Service) error {
-	token, err := jwtService.IssueToken(c.Request.Context(), userID.String(), oneMonth, jwt.TokenTypeAuth)
+func setAuthCookieForSession(c *gin.Context, userID users.ID, sessionID string, jwtService *service_jwt.Service) error {
+	token, err := jwtService.IssueToken(c.Request.Context(), userID.String(), oneMonth, jwt.TokenTypeAuth, service_jwt.WithSessionID(sessionID))
 	if err != nil {
 		return fmt.Errorf("failed to issue new token: %w", err)
 	}
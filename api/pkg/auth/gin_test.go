@@ -12,7 +12,13 @@ import (
 	"getsturdy.com/api/pkg/auth"
 	"getsturdy.com/api/pkg/jwt"
 	db_jwt_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_jwt_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/personaltokens"
+	db_personaltokens "getsturdy.com/api/pkg/personaltokens/db"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+	db_sessions "getsturdy.com/api/pkg/sessions/db"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -25,13 +31,13 @@ var (
 )
 
 func TestGinMiddleware__shouldAllowCIAuthInHeader(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeCI)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -58,13 +64,13 @@ func TestGinMiddleware__shouldAllowCIAuthInHeader(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldAllowUserAuthInHeader(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -91,13 +97,13 @@ func TestGinMiddleware__shouldAllowUserAuthInHeader(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldNotRefreshExpiringHeaderToken(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", time.Hour, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -124,13 +130,13 @@ func TestGinMiddleware__shouldNotRefreshExpiringHeaderToken(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldRefreshExpiringCookie(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", time.Hour, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -164,13 +170,13 @@ func TestGinMiddleware__shouldRefreshExpiringCookie(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldAllowUserAuthInCookie(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
 	router.GET("/ping", func(c *gin.Context) {
 		subject, found := auth.SubjectFromGinContext(c)
 		if assert.True(t, found) {
@@ -200,10 +206,10 @@ func TestGinMiddleware__shouldAllowUserAuthInCookie(t *testing.T) {
 }
 
 func TestGinMiddleware__shouldAllowNoAuth(t *testing.T) {
-	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory())
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
 
 	router := gin.New()
-	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService))
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
 	router.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
@@ -218,3 +224,136 @@ func TestGinMiddleware__shouldAllowNoAuth(t *testing.T) {
 	assert.Len(t, w.Result().Cookies(), 0)
 	assert.Equal(t, "pong", w.Body.String())
 }
+
+func TestGinMiddleware__shouldAllowPersonalAccessTokenInHeader(t *testing.T) {
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+	personalTokensService := service_personaltokens.New(db_personaltokens.NewMemory())
+
+	token, _, err := personalTokensService.Create(context.Background(), "user-id", "bot", []personaltokens.Scope{personaltokens.ScopeAPIRead}, nil)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, personalTokensService, service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
+	router.GET("/ping", func(c *gin.Context) {
+		subject, found := auth.FromContext(c.Request.Context())
+		if assert.True(t, found) {
+			assert.Equal(t, "user-id", subject.ID)
+			assert.Equal(t, auth.SubjectUser, subject.Type)
+			assert.True(t, subject.HasScope(personaltokens.ScopeAPIRead))
+			assert.False(t, subject.HasScope(personaltokens.ScopeAPIWrite))
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/ping", nil)
+	assert.NoError(t, err)
+	req.Header.Add("Authorization", fmt.Sprintf("bearer %s", token))
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, w.Result().Cookies(), 0)
+}
+
+func TestGinMiddleware__shouldNotAllowInvalidPersonalAccessToken(t *testing.T) {
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+
+	router := gin.New()
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
+	router.GET("/ping", func(c *gin.Context) {
+		subject, found := auth.FromContext(c.Request.Context())
+		if assert.True(t, found) {
+			assert.Equal(t, auth.SubjectAnonymous, subject.Type)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/ping", nil)
+	assert.NoError(t, err)
+	req.Header.Add("Authorization", fmt.Sprintf("bearer %s", personaltokens.Format("id", "secret")))
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGinMiddleware__shouldNotAllowRevokedSession(t *testing.T) {
+	jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+	sessionsService := service_sessions.New(db_sessions.NewMemory(), jwtTokenService)
+
+	session, err := sessionsService.Create(context.Background(), "id", "curl/7.79.1", nil)
+	assert.NoError(t, err)
+
+	token, err := jwtTokenService.IssueToken(context.Background(), "id", oneMonth, jwt.TokenTypeAuth, service_jwt.WithSessionID(session.ID))
+	assert.NoError(t, err)
+
+	var subjects []*auth.Subject
+	router := gin.New()
+	router.Use(auth.GinMiddleware(zap.NewNop(), jwtTokenService, service_personaltokens.New(db_personaltokens.NewMemory()), sessionsService))
+	router.GET("/ping", func(c *gin.Context) {
+		subject, _ := auth.FromContext(c.Request.Context())
+		subjects = append(subjects, subject)
+	})
+
+	ping := func() {
+		req, err := http.NewRequest("GET", "/ping", nil)
+		assert.NoError(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "auth",
+			Value: token.Token,
+		})
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	ping()
+	assert.NoError(t, sessionsService.Revoke(context.Background(), session))
+	ping()
+
+	if assert.Len(t, subjects, 2) {
+		assert.Equal(t, auth.SubjectUser, subjects[0].Type)
+		assert.Equal(t, session.ID, subjects[0].SessionID)
+		assert.Equal(t, auth.SubjectAnonymous, subjects[1].Type)
+	}
+}
+
+func TestGinMiddleware__shouldRequireAPIScopes(t *testing.T) {
+	cases := []struct {
+		name       string
+		middleware func(*zap.Logger, *service_jwt.Service, *service_personaltokens.Service, *service_sessions.Service) gin.HandlerFunc
+		method     string
+		scope      personaltokens.Scope
+		expected   int
+	}{
+		{name: "git token", middleware: auth.GinMiddleware, method: http.MethodGet, scope: personaltokens.ScopeGitRead, expected: http.StatusForbidden},
+		{name: "read token, read", middleware: auth.GinMiddleware, method: http.MethodGet, scope: personaltokens.ScopeAPIRead, expected: http.StatusOK},
+		{name: "read token, write", middleware: auth.GinMiddleware, method: http.MethodPost, scope: personaltokens.ScopeAPIRead, expected: http.StatusForbidden},
+		{name: "write token, write", middleware: auth.GinMiddleware, method: http.MethodPost, scope: personaltokens.ScopeAPIWrite, expected: http.StatusOK},
+		{name: "git token, graphql", middleware: auth.GinGraphQLMiddleware, method: http.MethodPost, scope: personaltokens.ScopeGitWrite, expected: http.StatusForbidden},
+		{name: "read token, graphql", middleware: auth.GinGraphQLMiddleware, method: http.MethodPost, scope: personaltokens.ScopeAPIRead, expected: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jwtTokenService := service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory())
+			personalTokensService := service_personaltokens.New(db_personaltokens.NewMemory())
+
+			token, _, err := personalTokensService.Create(context.Background(), "user-id", "bot", []personaltokens.Scope{tc.scope}, nil)
+			assert.NoError(t, err)
+
+			router := gin.New()
+			router.Use(tc.middleware(zap.NewNop(), jwtTokenService, personalTokensService, service_sessions.New(db_sessions.NewMemory(), jwtTokenService)))
+			router.Handle(tc.method, "/ping", func(c *gin.Context) {
+				c.String(http.StatusOK, "pong")
+			})
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.method, "/ping", nil)
+			assert.NoError(t, err)
+			req.Header.Add("Authorization", fmt.Sprintf("bearer %s", token))
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 
 	"getsturdy.com/api/pkg/jwt"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/personaltokens"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -40,7 +42,13 @@ var (
 	})
 )
 
-func SubjectFromRequest(r *http.Request, jwtService *service_jwt.Service) (*Subject, error) {
+func SubjectFromRequest(r *http.Request, jwtService *service_jwt.Service, personalTokensService *service_personaltokens.Service) (*Subject, error) {
+	if token, found, err := personalTokenFromRequest(r, personalTokensService); err != nil {
+		return nil, err
+	} else if found {
+		return subjectFromPersonalToken(token), nil
+	}
+
 	jwt, _, err := jwtFromRequest(r, jwtService)
 	if err != nil {
 		return nil, err
@@ -56,7 +64,7 @@ func jwtFromRequest(r *http.Request, jwtService *service_jwt.Service) (*jwt.Toke
 	}
 
 	jwtToken, err := jwtService.Verify(r.Context(), token, jwt.TokenTypeAuth, jwt.TokenTypeCI)
-	if errors.Is(err, service_jwt.ErrInvalidToken) || errors.Is(err, service_jwt.ErrTokenExpired) {
+	if errors.Is(err, service_jwt.ErrInvalidToken) || errors.Is(err, service_jwt.ErrTokenExpired) || errors.Is(err, service_jwt.ErrTokenRevoked) {
 		return nil, false, ErrUnauthenticated
 	} else if err != nil {
 		return nil, false, fmt.Errorf("failed to verify token: %w", err)
@@ -69,6 +77,24 @@ func jwtFromRequest(r *http.Request, jwtService *service_jwt.Service) (*jwt.Toke
 	return jwtToken, shouldRefresh, nil
 }
 
+// personalTokenFromRequest returns the personal access token from the authorization header. found is false
+// if the request is not authenticated with a personal access token.
+func personalTokenFromRequest(r *http.Request, personalTokensService *service_personaltokens.Service) (*personaltokens.Token, bool, error) {
+	token, fromHeader := tokenFromHeaders(r.Header)
+	if !fromHeader || !strings.HasPrefix(token, personaltokens.Prefix) {
+		return nil, false, nil
+	}
+
+	personalToken, err := personalTokensService.Authenticate(r.Context(), token)
+	if errors.Is(err, service_personaltokens.ErrInvalidToken) || errors.Is(err, service_personaltokens.ErrTokenInactive) {
+		return nil, false, ErrUnauthenticated
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to authenticate personal access token: %w", err)
+	}
+
+	return personalToken, true, nil
+}
+
 var (
 	oneDay           = time.Hour * 24
 	oneMonth         = 30 * oneDay
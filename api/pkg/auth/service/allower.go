@@ -127,26 +127,19 @@ func (s *Service) getUserCodebaseAllower(ctx context.Context, userID users.ID, c
 		return nil, fmt.Errorf("failed to get acl policy: %w", err)
 	}
 
-	user, err := s.userService.GetByID(ctx, userID)
+	identities, err := s.aclProvider.UserIdentities(ctx, userID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return noneAllowed, nil
 	} else if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to get user identities: %w", err)
 	}
 
-	allowedByEmail := aclPolicy.Policy.List(
-		acl.Identity{Type: acl.Users, ID: user.Email},
-		acl.ActionWrite,
-		acl.Files,
-	)
-
-	allowedByID := aclPolicy.Policy.List(
-		acl.Identity{Type: acl.Users, ID: user.ID.String()},
-		acl.ActionWrite,
-		acl.Files,
-	)
+	allowed := []string{}
+	for _, identity := range identities {
+		allowed = append(allowed, aclPolicy.Policy.List(identity, acl.ActionWrite, acl.Files)...)
+	}
 
-	return unidiff.NewAllower(append(allowedByEmail, allowedByID...)...)
+	return unidiff.NewAllower(allowed...)
 }
 
 func (s *Service) getCIWorkspaceAllower(ctx context.Context, workspaceID string, workspace *workspaces.Workspace) (*unidiff.Allower, error) {
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/organization"
+	"getsturdy.com/api/pkg/version"
+)
+
+// CanAdministrateInstallation checks if the user can manage the installation itself, such as rotating the
+// keys used to sign tokens.
+//
+// Self-hosted installations are administrated by the members of the organization that was created during
+// the first time setup. The cloud installation can not be administrated through the API.
+func (s *Service) CanAdministrateInstallation(ctx context.Context) (*organization.Organization, error) {
+	if version.Type == version.DistributionTypeCloud {
+		return nil, fmt.Errorf("cloud installation can not be administrated: %w", auth.ErrForbidden)
+	}
+
+	org, err := s.organizationService.GetFirst(ctx)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("installation is not set up: %w", auth.ErrForbidden)
+	default:
+		return nil, fmt.Errorf("failed to get installation organization: %w", err)
+	}
+
+	if err := s.CanWrite(ctx, org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
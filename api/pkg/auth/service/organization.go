@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/organization"
+)
+
+// CanAdministrateOrganization checks if the user can manage the organization, such as reading its audit log.
+// Organizations are administrated by the user that created them.
+func (s *Service) CanAdministrateOrganization(ctx context.Context, org *organization.Organization) error {
+	if err := s.CanWrite(ctx, org); err != nil {
+		return err
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if org.CreatedBy != userID {
+		return fmt.Errorf("user is not an administrator of the organization: %w", auth.ErrForbidden)
+	}
+
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	service_organization "getsturdy.com/api/pkg/organization/service"
 	"getsturdy.com/api/pkg/review"
 	"getsturdy.com/api/pkg/suggestions"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users"
 	service_user "getsturdy.com/api/pkg/users/service"
 	"getsturdy.com/api/pkg/view"
@@ -31,6 +32,7 @@ type Service struct {
 	workspaceService    service_workspace.Service
 	aclProvider         *provider_acl.Provider
 	organizationService *service_organization.Service
+	twoFactorService    *service_twofactor.Service
 }
 
 func New(
@@ -40,6 +42,7 @@ func New(
 	workspaceService service_workspace.Service,
 	aclProvider *provider_acl.Provider,
 	organizationService *service_organization.Service,
+	twoFactorService *service_twofactor.Service,
 ) *Service {
 	return &Service{
 		codebaseService:     codebaseService,
@@ -48,6 +51,7 @@ func New(
 		workspaceService:    workspaceService,
 		aclProvider:         aclProvider,
 		organizationService: organizationService,
+		twoFactorService:    twoFactorService,
 	}
 }
 
@@ -70,6 +74,19 @@ func (s *Service) CanWrite(ctx context.Context, obj any) error {
 	return s.hasAccess(ctx, accessTypeWrite, obj)
 }
 
+// checkScope checks that a user authenticated with a personal access token has the scope required for
+// the access type.
+func checkScope(subject *auth.Subject, at accessType) error {
+	switch at {
+	case accessTypeRead:
+		return subject.RequireAPIScope(false)
+	case accessTypeWrite:
+		return subject.RequireAPIScope(true)
+	default:
+		return fmt.Errorf("unknown access type: %w", auth.ErrForbidden)
+	}
+}
+
 // hasAccess checks if the user has the given permission on the given object.
 //nolint:cyclop
 func (s *Service) hasAccess(ctx context.Context, at accessType, obj any) error {
@@ -84,6 +101,9 @@ func (s *Service) hasAccess(ctx context.Context, at accessType, obj any) error {
 
 	switch subject.Type {
 	case auth.SubjectUser:
+		if err := checkScope(subject, at); err != nil {
+			return err
+		}
 		subjectID := users.ID(subject.ID)
 		switch object := obj.(type) {
 		case review.Review:
@@ -247,7 +267,14 @@ func (s *Service) canUserAccessCodebase(ctx context.Context, userID users.ID, at
 	}
 
 	if accessAllowed {
-		return nil
+		if codebase.OrganizationID == nil {
+			return nil
+		}
+		org, err := s.organizationService.GetByID(ctx, *codebase.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to check if user can access codebase: %w", err)
+		}
+		return s.checkTwoFactor(ctx, userID, org)
 	}
 
 	if codebase.OrganizationID != nil {
@@ -466,6 +493,10 @@ func (s *Service) canAnonymousAccessReview(ctx context.Context, at accessType, r
 }
 
 func (s *Service) canUserAccessOrganization(ctx context.Context, userID users.ID, at accessType, org *organization.Organization) error {
+	if err := s.checkTwoFactor(ctx, userID, org); err != nil {
+		return err
+	}
+
 	// user can access a organization if they are a member of it
 	_, err := s.organizationService.GetMemberByUserIDAndOrganizationID(ctx, userID, org.ID)
 	if err == nil {
@@ -486,6 +517,33 @@ func (s *Service) canUserAccessOrganization(ctx context.Context, userID users.ID
 	return fmt.Errorf("user does not have access to organization: %w", auth.ErrForbidden)
 }
 
+// checkTwoFactor checks that the user has enabled two-factor authentication, if the organization requires it.
+// Users that log in with single sign-on don't have a password, and are expected to use the second factor of
+// their identity provider.
+func (s *Service) checkTwoFactor(ctx context.Context, userID users.ID, org *organization.Organization) error {
+	if !org.RequireTwoFactor {
+		return nil
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.PasswordHash == "" {
+		return nil
+	}
+
+	enabled, err := s.twoFactorService.Enabled(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+	if !enabled {
+		return fmt.Errorf("organization requires two-factor authentication: %w", auth.ErrForbidden)
+	}
+
+	return nil
+}
+
 func (s *Service) canAnonymousAccessOrganization(ctx context.Context, at accessType, org *organization.Organization) error {
 	return fmt.Errorf("anonymous users can't access organizations: %w", auth.ErrForbidden)
 }
@@ -65,7 +65,7 @@ func TestCanWrite_codebase(t *testing.T) {
 
 	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
 	codebaseUserRepo := inmemory.NewInMemoryCodebaseUserRepo()
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	authService := service_auth.New(
 		codebaseService,
@@ -74,6 +74,7 @@ func TestCanWrite_codebase(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 	)
 
 	for _, tc := range cases {
@@ -161,11 +162,11 @@ func TestCanRead_codebase(t *testing.T) {
 	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
 	codebaseUserRepo := inmemory.NewInMemoryCodebaseUserRepo()
 	analyticsService := service_analytics.New(zap.NewNop(), disabled.NewClient(zap.NewNop()))
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil)
 
 	organizationRepo := inmemory.NewInMemoryOrganizationRepo()
 	organizationMemberRepo := inmemory.NewInMemoryOrganizationMemberRepository()
-	organizationService := service_organization.New(nil, organizationRepo, organizationMemberRepo, analyticsService)
+	organizationService := service_organization.New(nil, organizationRepo, organizationMemberRepo, analyticsService, nil)
 
 	authService := service_auth.New(
 		codebaseService,
@@ -174,6 +175,7 @@ func TestCanRead_codebase(t *testing.T) {
 		nil,
 		nil,
 		organizationService,
+		nil,
 	)
 
 	for _, tc := range cases {
@@ -264,12 +266,12 @@ func TestCanReadWrite_organization(t *testing.T) {
 	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
 	codebaseUserRepo := inmemory.NewInMemoryCodebaseUserRepo()
 	analyticsService := service_analytics.New(zap.NewNop(), disabled.NewClient(zap.NewNop()))
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, analyticsService, nil, nil)
 
 	organizationRepo := inmemory.NewInMemoryOrganizationRepo()
 	organizationMemberRepo := inmemory.NewInMemoryOrganizationMemberRepository()
 
-	organizationService := service_organization.New(nil, organizationRepo, organizationMemberRepo, analyticsService)
+	organizationService := service_organization.New(nil, organizationRepo, organizationMemberRepo, analyticsService, nil)
 
 	authService := service_auth.New(
 		codebaseService,
@@ -278,6 +280,7 @@ func TestCanReadWrite_organization(t *testing.T) {
 		nil,
 		nil,
 		organizationService,
+		nil,
 	)
 
 	for _, tc := range cases {
@@ -326,3 +329,38 @@ func TestCanReadWrite_organization(t *testing.T) {
 		})
 	}
 }
+
+func TestCanAdministrateOrganization(t *testing.T) {
+	analyticsService := service_analytics.New(zap.NewNop(), disabled.NewClient(zap.NewNop()))
+	codebaseService := service_codebase.New(inmemory.NewInMemoryCodebaseRepo(), inmemory.NewInMemoryCodebaseUserRepo(), nil, nil, nil, nil, nil, analyticsService, nil, nil)
+
+	organizationRepo := inmemory.NewInMemoryOrganizationRepo()
+	organizationMemberRepo := inmemory.NewInMemoryOrganizationMemberRepository()
+	organizationService := service_organization.New(nil, organizationRepo, organizationMemberRepo, analyticsService, nil)
+
+	authService := service_auth.New(
+		codebaseService,
+		nil,
+		nil,
+		nil,
+		nil,
+		organizationService,
+		nil,
+	)
+
+	ctx := context.Background()
+	creatorID := users.ID(uuid.NewString())
+	memberID := users.ID(uuid.NewString())
+
+	org := &organization.Organization{ID: uuid.NewString(), CreatedBy: creatorID}
+	assert.NoError(t, organizationRepo.Create(ctx, *org))
+	for _, userID := range []users.ID{creatorID, memberID} {
+		assert.NoError(t, organizationMemberRepo.Create(ctx, organization.Member{ID: uuid.NewString(), OrganizationID: org.ID, UserID: userID}))
+	}
+
+	creatorCtx := auth.NewContext(ctx, &auth.Subject{ID: creatorID.String(), Type: auth.SubjectUser})
+	assert.NoError(t, authService.CanAdministrateOrganization(creatorCtx, org))
+
+	memberCtx := auth.NewContext(ctx, &auth.Subject{ID: memberID.String(), Type: auth.SubjectUser})
+	assert.ErrorIs(t, authService.CanAdministrateOrganization(memberCtx, org), auth.ErrForbidden)
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"context"
+	"fmt"
 
 	"getsturdy.com/api/pkg/jwt"
+	"getsturdy.com/api/pkg/personaltokens"
 	"getsturdy.com/api/pkg/users"
 )
 
@@ -24,6 +26,45 @@ func (st SubjectType) String() string {
 type Subject struct {
 	ID   string
 	Type SubjectType
+	// Scopes is set if the subject is authenticated with a personal access token, and limits what the
+	// subject can do. It is nil for browser sessions, which are not limited.
+	Scopes []personaltokens.Scope
+	// SessionID is the session that the subject is authenticated with, if any.
+	SessionID string
+}
+
+// IsPersonalAccessToken returns true if the subject is authenticated with a personal access token.
+func (s *Subject) IsPersonalAccessToken() bool {
+	return s.Scopes != nil
+}
+
+// HasScope returns true if the subject is allowed to do what the scope grants. Subjects that are not
+// authenticated with a personal access token have all scopes.
+func (s *Subject) HasScope(scope personaltokens.Scope) bool {
+	if !s.IsPersonalAccessToken() {
+		return true
+	}
+	for _, sc := range s.Scopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAPIScope returns ErrForbidden if the subject is authenticated with a personal access token that
+// does not grant access to the API. Writes require the api:write scope, reads either of the api scopes.
+func (s *Subject) RequireAPIScope(write bool) error {
+	if s.HasScope(personaltokens.ScopeAPIWrite) {
+		return nil
+	}
+	if write {
+		return fmt.Errorf("token is missing the %s scope: %w", personaltokens.ScopeAPIWrite, ErrForbidden)
+	}
+	if s.HasScope(personaltokens.ScopeAPIRead) {
+		return nil
+	}
+	return fmt.Errorf("token is missing the %s scope: %w", personaltokens.ScopeAPIRead, ErrForbidden)
 }
 
 var (
@@ -39,8 +80,21 @@ func subjectFromToken(token *jwt.Token) *Subject {
 	}
 
 	return &Subject{
-		ID:   token.Subject,
-		Type: convertType[token.Type],
+		ID:        token.Subject,
+		Type:      convertType[token.Type],
+		SessionID: token.SessionID,
+	}
+}
+
+func subjectFromPersonalToken(token *personaltokens.Token) *Subject {
+	scopes := make([]personaltokens.Scope, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, personaltokens.Scope(scope))
+	}
+	return &Subject{
+		ID:     token.UserID.String(),
+		Type:   SubjectUser,
+		Scopes: scopes,
 	}
 }
 
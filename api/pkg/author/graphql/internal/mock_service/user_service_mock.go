@@ -81,6 +81,20 @@ func (mr *MockServiceMockRecorder) CreateWithPassword(arg0, arg1, arg2, arg3 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithPassword", reflect.TypeOf((*MockService)(nil).CreateWithPassword), arg0, arg1, arg2, arg3)
 }
 
+// Deactivate mocks base method.
+func (m *MockService) Deactivate(arg0 context.Context, arg1 *users.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockServiceMockRecorder) Deactivate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockService)(nil).Deactivate), arg0, arg1)
+}
+
 // GetAsAuthor mocks base method.
 func (m *MockService) GetAsAuthor(arg0 context.Context, arg1 users.ID) (*author.Author, error) {
 	m.ctrl.T.Helper()
@@ -175,6 +189,34 @@ func (mr *MockServiceMockRecorder) Inherit(arg0, arg1, arg2 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Inherit", reflect.TypeOf((*MockService)(nil).Inherit), arg0, arg1, arg2)
 }
 
+// PasswordLoginEnabled mocks base method.
+func (m *MockService) PasswordLoginEnabled(arg0 context.Context) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordLoginEnabled", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// PasswordLoginEnabled indicates an expected call of PasswordLoginEnabled.
+func (mr *MockServiceMockRecorder) PasswordLoginEnabled(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordLoginEnabled", reflect.TypeOf((*MockService)(nil).PasswordLoginEnabled), arg0)
+}
+
+// Reactivate mocks base method.
+func (m *MockService) Reactivate(arg0 context.Context, arg1 *users.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reactivate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reactivate indicates an expected call of Reactivate.
+func (mr *MockServiceMockRecorder) Reactivate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reactivate", reflect.TypeOf((*MockService)(nil).Reactivate), arg0, arg1)
+}
+
 // UsersCount mocks base method.
 func (m *MockService) UsersCount(arg0 context.Context) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -46,6 +46,8 @@ func (r *AuthorResolver) Status() (resolvers.UserStatus, error) {
 		return resolvers.UserStatusActive, nil
 	case users.StatusShadow:
 		return resolvers.UserStatusShadow, nil
+	case users.StatusDeactivated:
+		return resolvers.UserStatusDeactivated, nil
 	default:
 		return resolvers.UserStatusUndefined, gqlerrors.Error(fmt.Errorf("unknown status: %s", r.user.Status))
 	}
@@ -0,0 +1,177 @@
+package autolinks
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+
+	"golang.org/x/net/html"
+)
+
+type ID string
+
+func (id ID) String() string {
+	return string(id)
+}
+
+// NumberPlaceholder is replaced with the number of the reference in URLTemplate.
+const NumberPlaceholder = "<num>"
+
+// Autolink turns references to an external issue tracker, like "JIRA-123", into links. Prefix is a regular
+// expression that is matched right before the number of the reference.
+type Autolink struct {
+	ID          ID           `db:"id" json:"id"`
+	CodebaseID  codebases.ID `db:"codebase_id" json:"codebase_id"`
+	Prefix      string       `db:"prefix" json:"prefix"`
+	URLTemplate string       `db:"url_template" json:"url_template"`
+	CreatedBy   users.ID     `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	DeletedAt   *time.Time   `db:"deleted_at" json:"deleted_at"`
+}
+
+// URL returns the link to the reference with the given number.
+func (a *Autolink) URL(number string) string {
+	return strings.ReplaceAll(a.URLTemplate, NumberPlaceholder, number)
+}
+
+// CompilePrefix returns the regular expression that matches references of the given prefix. The number of the
+// reference is the last submatch.
+func CompilePrefix(prefix string) (*regexp.Regexp, error) {
+	// the prefix must be valid on its own, so that it can't close the group that it's wrapped in
+	if _, err := regexp.Compile(prefix); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(`(?:` + prefix + `)([0-9]+)\b`)
+}
+
+type rule struct {
+	autolink *Autolink
+	re       *regexp.Regexp
+}
+
+type match struct {
+	start, end int
+	url        string
+}
+
+// Linker adds links to the references in texts. The zero value does not add any links.
+type Linker struct {
+	rules []*rule
+}
+
+// NewLinker returns a Linker for the autolinks. Autolinks with an invalid prefix are ignored.
+func NewLinker(autolinks []*Autolink) *Linker {
+	linker := &Linker{}
+	for _, autolink := range autolinks {
+		re, err := CompilePrefix(autolink.Prefix)
+		if err != nil {
+			continue
+		}
+		linker.rules = append(linker.rules, &rule{autolink: autolink, re: re})
+	}
+	return linker
+}
+
+// matches returns the references in the text, ordered by their position. References must not be preceded by a
+// letter, a digit or an underscore, and if references overlap, the first one wins.
+func (l *Linker) matches(text string) []*match {
+	var all []*match
+	for _, r := range l.rules {
+		number := 2 * r.re.NumSubexp()
+		for _, loc := range r.re.FindAllStringSubmatchIndex(text, -1) {
+			if loc[0] == loc[number] {
+				// empty prefix
+				continue
+			}
+			if previous, _ := utf8.DecodeLastRuneInString(text[:loc[0]]); isWordRune(previous) {
+				continue
+			}
+			all = append(all, &match{start: loc[0], end: loc[1], url: r.autolink.URL(text[loc[number]:loc[number+1]])})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].start < all[j].start
+	})
+
+	res := make([]*match, 0, len(all))
+	end := 0
+	for _, m := range all {
+		if m.start < end {
+			continue
+		}
+		res = append(res, m)
+		end = m.end
+	}
+	return res
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Text returns the text as HTML, with the references replaced by links.
+func (l *Linker) Text(text string) string {
+	var builder strings.Builder
+	last := 0
+	for _, m := range l.matches(text) {
+		builder.WriteString(html.EscapeString(text[last:m.start]))
+		builder.WriteString(`<a href="`)
+		builder.WriteString(html.EscapeString(m.url))
+		builder.WriteString(`">`)
+		builder.WriteString(html.EscapeString(text[m.start:m.end]))
+		builder.WriteString(`</a>`)
+		last = m.end
+	}
+	builder.WriteString(html.EscapeString(text[last:]))
+	return builder.String()
+}
+
+// skipTags are the elements that no links are added to.
+var skipTags = map[string]bool{
+	"a":    true,
+	"code": true,
+	"pre":  true,
+}
+
+// HTML adds links to the references in the text of an HTML document. References in links and code are left as is.
+func (l *Linker) HTML(document string) string {
+	if len(l.rules) == 0 {
+		return document
+	}
+
+	var builder strings.Builder
+	skipDepth := 0
+	tokenizer := html.NewTokenizer(strings.NewReader(document))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			// io.EOF, the tokenizer does not fail on malformed documents
+			return builder.String()
+		}
+
+		raw := string(tokenizer.Raw())
+		switch tokenType {
+		case html.TextToken:
+			if skipDepth == 0 {
+				builder.WriteString(l.Text(html.UnescapeString(raw)))
+				continue
+			}
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); skipTags[string(name)] {
+				skipDepth++
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); skipTags[string(name)] && skipDepth > 0 {
+				skipDepth--
+			}
+		}
+		builder.WriteString(raw)
+	}
+}
@@ -0,0 +1,119 @@
+package autolinks_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/autolinks"
+)
+
+var linker = autolinks.NewLinker([]*autolinks.Autolink{
+	{Prefix: "JIRA-", URLTemplate: "https://jira.example.com/browse/JIRA-<num>"},
+	{Prefix: "#", URLTemplate: "https://tracker.example.com/issues/<num>"},
+	{Prefix: "(OPS|PROJ)-", URLTemplate: "https://projects.example.com/<num>"},
+	{Prefix: "(", URLTemplate: "https://invalid.example.com/<num>"},
+})
+
+func TestLinkerText(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "no references",
+			text:     "nothing to see <here>",
+			expected: "nothing to see &lt;here&gt;",
+		},
+		{
+			name:     "reference",
+			text:     "see JIRA-123",
+			expected: `see <a href="https://jira.example.com/browse/JIRA-123">JIRA-123</a>`,
+		},
+		{
+			name:     "multiple rules",
+			text:     "#1 and JIRA-2.",
+			expected: `<a href="https://tracker.example.com/issues/1">#1</a> and <a href="https://jira.example.com/browse/JIRA-2">JIRA-2</a>.`,
+		},
+		{
+			name:     "prefix with groups",
+			text:     "PROJ-7",
+			expected: `<a href="https://projects.example.com/7">PROJ-7</a>`,
+		},
+		{
+			name:     "preceded by a word",
+			text:     "MYJIRA-123 and a#1",
+			expected: "MYJIRA-123 and a#1",
+		},
+		{
+			name:     "followed by a word",
+			text:     "JIRA-123abc",
+			expected: "JIRA-123abc",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, linker.Text(tc.text))
+		})
+	}
+}
+
+func TestLinkerHTML(t *testing.T) {
+	cases := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "text",
+			html:     "<p>Fixes <strong>JIRA-1</strong> &amp; more</p>",
+			expected: `<p>Fixes <strong><a href="https://jira.example.com/browse/JIRA-1">JIRA-1</a></strong> &amp; more</p>`,
+		},
+		{
+			name:     "links and code are skipped",
+			html:     `<p><a href="https://example.com">JIRA-1</a> <code>#2</code> #3</p>`,
+			expected: `<p><a href="https://example.com">JIRA-1</a> <code>#2</code> <a href="https://tracker.example.com/issues/3">#3</a></p>`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, linker.HTML(tc.html))
+		})
+	}
+}
+
+func TestClosingIssues(t *testing.T) {
+	cases := []struct {
+		name        string
+		description string
+		expected    []int
+	}{
+		{
+			name:        "no keywords",
+			description: "<p>Mentions #42</p>",
+		},
+		{
+			name:        "keywords",
+			description: "<p>Fixes #42, closes: #7 and <strong>resolved</strong> #42</p>",
+			expected:    []int{42, 7},
+		},
+		{
+			name:        "case insensitive",
+			description: "<p>FIX #1</p>",
+			expected:    []int{1},
+		},
+		{
+			name:        "part of a word",
+			description: "<p>prefixes #1</p>",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, autolinks.ClosingIssues(tc.description))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/autolinks"
+	"getsturdy.com/api/pkg/codebases"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{
+		db: db,
+	}
+}
+
+func (d *database) Create(ctx context.Context, autolink *autolinks.Autolink) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO codebase_autolinks (
+			id, codebase_id, prefix, url_template, created_by, created_at, deleted_at
+		) VALUES (
+			:id, :codebase_id, :prefix, :url_template, :created_by, :created_at, :deleted_at
+		)
+	`, autolink); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Update(ctx context.Context, autolink *autolinks.Autolink) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE codebase_autolinks
+		SET prefix = :prefix,
+			url_template = :url_template,
+			deleted_at = :deleted_at
+		WHERE id = :id
+	`, autolink); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Get(ctx context.Context, id autolinks.ID) (*autolinks.Autolink, error) {
+	autolink := &autolinks.Autolink{}
+	if err := d.db.GetContext(ctx, autolink, `
+		SELECT
+			id, codebase_id, prefix, url_template, created_by, created_at, deleted_at
+		FROM codebase_autolinks
+		WHERE id = $1
+	`, id); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return autolink, nil
+}
+
+func (d *database) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*autolinks.Autolink, error) {
+	var res []*autolinks.Autolink
+	if err := d.db.SelectContext(ctx, &res, `
+		SELECT
+			id, codebase_id, prefix, url_template, created_by, created_at, deleted_at
+		FROM codebase_autolinks
+		WHERE codebase_id = $1
+		  AND deleted_at IS NULL
+		ORDER BY created_at ASC
+	`, codebaseID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/autolinks"
+	"getsturdy.com/api/pkg/codebases"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	mx   sync.RWMutex
+	byID map[autolinks.ID]*autolinks.Autolink
+}
+
+func NewMemory() *memory {
+	return &memory{
+		byID: map[autolinks.ID]*autolinks.Autolink{},
+	}
+}
+
+func (m *memory) Create(_ context.Context, autolink *autolinks.Autolink) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.byID[autolink.ID] = autolink
+	return nil
+}
+
+func (m *memory) Update(_ context.Context, autolink *autolinks.Autolink) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.byID[autolink.ID] = autolink
+	return nil
+}
+
+func (m *memory) Get(_ context.Context, id autolinks.ID) (*autolinks.Autolink, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	autolink, found := m.byID[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return autolink, nil
+}
+
+func (m *memory) ListByCodebaseID(_ context.Context, codebaseID codebases.ID) ([]*autolinks.Autolink, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	var res []*autolinks.Autolink
+	for _, autolink := range m.byID {
+		if autolink.DeletedAt == nil && autolink.CodebaseID == codebaseID {
+			res = append(res, autolink)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
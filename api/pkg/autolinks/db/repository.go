@@ -0,0 +1,16 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/autolinks"
+	"getsturdy.com/api/pkg/codebases"
+)
+
+type Repository interface {
+	Create(context.Context, *autolinks.Autolink) error
+	Update(context.Context, *autolinks.Autolink) error
+	Get(context.Context, autolinks.ID) (*autolinks.Autolink, error)
+	// ListByCodebaseID returns all autolinks of the codebase that have not been deleted, oldest first.
+	ListByCodebaseID(context.Context, codebases.ID) ([]*autolinks.Autolink, error)
+}
@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/autolinks"
+	service_autolinks "getsturdy.com/api/pkg/autolinks/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type rootResolver struct {
+	autolinksService *service_autolinks.Service
+	codebaseService  *service_codebase.Service
+	authService      *service_auth.Service
+
+	authorRootResolver resolvers.AuthorRootResolver
+}
+
+func New(
+	autolinksService *service_autolinks.Service,
+	codebaseService *service_codebase.Service,
+	authService *service_auth.Service,
+
+	authorRootResolver resolvers.AuthorRootResolver,
+) resolvers.AutolinksRootResolver {
+	return &rootResolver{
+		autolinksService: autolinksService,
+		codebaseService:  codebaseService,
+		authService:      authService,
+
+		authorRootResolver: authorRootResolver,
+	}
+}
+
+func (r *rootResolver) InternalListByCodebaseID(ctx context.Context, codebaseID graphql.ID) ([]resolvers.AutolinkResolver, error) {
+	codebase, err := r.codebaseService.GetByID(ctx, codebases.ID(codebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanRead(ctx, codebase); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	list, err := r.autolinksService.ListByCodebaseID(ctx, codebase.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.AutolinkResolver, 0, len(list))
+	for _, autolink := range list {
+		res = append(res, &resolver{root: r, autolink: autolink})
+	}
+	return res, nil
+}
+
+func (r *rootResolver) CreateAutolink(ctx context.Context, args resolvers.CreateAutolinkArgs) (resolvers.AutolinkResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	codebase, err := r.codebaseService.GetByID(ctx, codebases.ID(args.Input.CodebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(fmt.Errorf("codebase not found: %w", err))
+	}
+
+	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	autolink, err := r.autolinksService.Create(ctx, codebase.ID, userID, args.Input.Prefix, args.Input.URLTemplate)
+	switch {
+	case err == nil:
+	case errors.Is(err, service_autolinks.ErrInvalidPrefix):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "prefix", err.Error())
+	case errors.Is(err, service_autolinks.ErrInvalidURLTemplate):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "urlTemplate", err.Error())
+	default:
+		return nil, gqlerror.Error(fmt.Errorf("failed to create autolink: %w", err))
+	}
+
+	return &resolver{root: r, autolink: autolink}, nil
+}
+
+func (r *rootResolver) DeleteAutolink(ctx context.Context, args resolvers.DeleteAutolinkArgs) (resolvers.AutolinkResolver, error) {
+	autolink, err := r.autolinksService.Get(ctx, autolinks.ID(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	codebase, err := r.codebaseService.GetByID(ctx, autolink.CodebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	switch err := r.autolinksService.Delete(ctx, autolink); {
+	case err == nil:
+	case errors.Is(err, service_autolinks.ErrAlreadyDeleted):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{root: r, autolink: autolink}, nil
+}
+
+type resolver struct {
+	root     *rootResolver
+	autolink *autolinks.Autolink
+}
+
+func (r *resolver) ID() graphql.ID {
+	return graphql.ID(r.autolink.ID)
+}
+
+func (r *resolver) Prefix() string {
+	return r.autolink.Prefix
+}
+
+func (r *resolver) URLTemplate() string {
+	return r.autolink.URLTemplate
+}
+
+func (r *resolver) CreatedBy(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(r.autolink.CreatedBy))
+}
+
+func (r *resolver) CreatedAt() int32 {
+	return int32(r.autolink.CreatedAt.Unix())
+}
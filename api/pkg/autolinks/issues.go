@@ -0,0 +1,60 @@
+package autolinks
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"getsturdy.com/api/pkg/changes"
+
+	"golang.org/x/net/html"
+)
+
+// IssueTracker closes the issues that landed changes reference with closing keywords.
+type IssueTracker interface {
+	CloseIssues(ctx context.Context, change *changes.Change, issues []int) error
+}
+
+// NoopIssueTracker is used when no issue tracker is integrated with Sturdy.
+type NoopIssueTracker struct{}
+
+func (NoopIssueTracker) CloseIssues(context.Context, *changes.Change, []int) error {
+	return nil
+}
+
+var closingKeywordRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#([0-9]+)\b`)
+
+// ClosingIssues returns the numbers of the issues that are referenced with a closing keyword, like "Fixes #42",
+// in the HTML description of a change. Every issue is returned once, in the order that they are first referenced.
+func ClosingIssues(description string) []int {
+	text := textContent(description)
+
+	var res []int
+	seen := map[int]bool{}
+	for _, submatch := range closingKeywordRegexp.FindAllStringSubmatch(text, -1) {
+		number, err := strconv.Atoi(submatch[1])
+		if err != nil || seen[number] {
+			continue
+		}
+		seen[number] = true
+		res = append(res, number)
+	}
+	return res
+}
+
+// textContent returns the text of the HTML document, tags are replaced by spaces.
+func textContent(document string) string {
+	var builder strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(document))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return builder.String()
+		case html.TextToken:
+			builder.Write(tokenizer.Text())
+		default:
+			builder.WriteByte(' ')
+		}
+	}
+}
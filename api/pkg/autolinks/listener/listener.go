@@ -0,0 +1,40 @@
+package listener
+
+import (
+	"context"
+
+	service_autolinks "getsturdy.com/api/pkg/autolinks/service"
+	"getsturdy.com/api/pkg/changes"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+
+	"go.uber.org/zap"
+)
+
+// Listener closes the issues that landed changes reference with closing keywords.
+type Listener struct {
+	logger           *zap.Logger
+	subscriber       *eventsv2.Subscriber
+	autolinksService *service_autolinks.Service
+}
+
+func New(
+	logger *zap.Logger,
+	subscriber *eventsv2.Subscriber,
+	autolinksService *service_autolinks.Service,
+) *Listener {
+	return &Listener{
+		logger:           logger.Named("autolinksListener"),
+		subscriber:       subscriber,
+		autolinksService: autolinksService,
+	}
+}
+
+func (l *Listener) Start(ctx context.Context) error {
+	l.subscriber.OnChangeLanded(ctx, eventsv2.SubscribeAll(), func(ctx context.Context, change *changes.Change) error {
+		return l.autolinksService.CloseIssues(ctx, change)
+	})
+
+	l.logger.Info("listening for events")
+	<-ctx.Done()
+	return nil
+}
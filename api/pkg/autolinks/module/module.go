@@ -0,0 +1,17 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/autolinks/db"
+	"getsturdy.com/api/pkg/autolinks/graphql"
+	"getsturdy.com/api/pkg/autolinks/listener"
+	"getsturdy.com/api/pkg/autolinks/service"
+	"getsturdy.com/api/pkg/di"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Import(graphql.Module)
+	c.Import(listener.Module)
+	c.Import(service.Module)
+	c.Import(issueTrackerModule)
+}
@@ -0,0 +1,17 @@
+//go:build enterprise || cloud
+// +build enterprise cloud
+
+package module
+
+import (
+	"getsturdy.com/api/pkg/autolinks"
+	"getsturdy.com/api/pkg/di"
+	service_github "getsturdy.com/api/pkg/github/enterprise/service"
+)
+
+// issueTrackerModule closes issues in GitHub Issues.
+func issueTrackerModule(c *di.Container) {
+	c.Register(func(githubService *service_github.Service) autolinks.IssueTracker {
+		return githubService
+	})
+}
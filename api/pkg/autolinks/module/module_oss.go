@@ -0,0 +1,17 @@
+//go:build !enterprise && !cloud
+// +build !enterprise,!cloud
+
+package module
+
+import (
+	"getsturdy.com/api/pkg/autolinks"
+	"getsturdy.com/api/pkg/di"
+)
+
+// issueTrackerModule provides an issue tracker that does nothing, there are no issue tracker integrations in the
+// open source build.
+func issueTrackerModule(c *di.Container) {
+	c.Register(func() autolinks.IssueTracker {
+		return autolinks.NoopIssueTracker{}
+	})
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/autolinks"
+	db_autolinks "getsturdy.com/api/pkg/autolinks/db"
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPrefix      = errors.New("prefix must be a regular expression that does not match an empty string")
+	ErrInvalidURLTemplate = errors.New("url template must be an absolute http or https url that contains " + autolinks.NumberPlaceholder)
+	ErrAlreadyDeleted     = errors.New("autolink is already deleted")
+)
+
+type Service struct {
+	repo         db_autolinks.Repository
+	auditService *service_audit.Service
+	issueTracker autolinks.IssueTracker
+}
+
+func New(
+	repo db_autolinks.Repository,
+	auditService *service_audit.Service,
+	issueTracker autolinks.IssueTracker,
+) *Service {
+	return &Service{
+		repo:         repo,
+		auditService: auditService,
+		issueTracker: issueTracker,
+	}
+}
+
+// Create adds an autolink to the codebase. References that match the prefix followed by a number are linked to the
+// url template, with the number in place of <num>.
+func (s *Service) Create(ctx context.Context, codebaseID codebases.ID, createdBy users.ID, prefix, urlTemplate string) (*autolinks.Autolink, error) {
+	if err := validatePrefix(prefix); err != nil {
+		return nil, err
+	}
+	if err := validateURLTemplate(urlTemplate); err != nil {
+		return nil, err
+	}
+
+	autolink := &autolinks.Autolink{
+		ID:          autolinks.ID(uuid.NewString()),
+		CodebaseID:  codebaseID,
+		Prefix:      prefix,
+		URLTemplate: urlTemplate,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.repo.Create(ctx, autolink); err != nil {
+		return nil, fmt.Errorf("failed to create: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionAutolinkCreated, audit.TargetAutolink, autolink.ID.String(),
+		audit.CodebaseID(codebaseID),
+		audit.After(autolink),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return autolink, nil
+}
+
+func validatePrefix(prefix string) error {
+	if _, err := autolinks.CompilePrefix(prefix); err != nil {
+		return ErrInvalidPrefix
+	}
+	// a prefix that matches nothing would link every number
+	if regexp.MustCompile(`^(?:` + prefix + `)$`).MatchString("") {
+		return ErrInvalidPrefix
+	}
+	return nil
+}
+
+func validateURLTemplate(urlTemplate string) error {
+	if !strings.Contains(urlTemplate, autolinks.NumberPlaceholder) {
+		return ErrInvalidURLTemplate
+	}
+	u, err := url.Parse(strings.ReplaceAll(urlTemplate, autolinks.NumberPlaceholder, "1"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURLTemplate
+	}
+	return nil
+}
+
+func (s *Service) Get(ctx context.Context, id autolinks.ID) (*autolinks.Autolink, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*autolinks.Autolink, error) {
+	res, err := s.repo.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list autolinks: %w", err)
+	}
+	return res, nil
+}
+
+func (s *Service) Delete(ctx context.Context, autolink *autolinks.Autolink) error {
+	if autolink.DeletedAt != nil {
+		return ErrAlreadyDeleted
+	}
+
+	before := *autolink
+	now := time.Now()
+	autolink.DeletedAt = &now
+
+	if err := s.repo.Update(ctx, autolink); err != nil {
+		return fmt.Errorf("failed to delete autolink: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionAutolinkDeleted, audit.TargetAutolink, autolink.ID.String(),
+		audit.CodebaseID(autolink.CodebaseID),
+		audit.Before(before),
+		audit.After(autolink),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Linker returns a linker for the autolinks of the codebase.
+func (s *Service) Linker(ctx context.Context, codebaseID codebases.ID) (*autolinks.Linker, error) {
+	list, err := s.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, err
+	}
+	return autolinks.NewLinker(list), nil
+}
+
+// CloseIssues closes the issues that the description of the landed change references with closing keywords, like
+// "Fixes #42", in the issue tracker that is integrated with the codebase.
+func (s *Service) CloseIssues(ctx context.Context, change *changes.Change) error {
+	issues := autolinks.ClosingIssues(change.UpdatedDescription)
+	if len(issues) == 0 {
+		return nil
+	}
+	if err := s.issueTracker.CloseIssues(ctx, change, issues); err != nil {
+		return fmt.Errorf("failed to close issues: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/autolinks"
+	db_autolinks "getsturdy.com/api/pkg/autolinks/db"
+	service_autolinks "getsturdy.com/api/pkg/autolinks/service"
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/internal/inmemory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tracker struct {
+	closed []int
+}
+
+func (t *tracker) CloseIssues(_ context.Context, _ *changes.Change, issues []int) error {
+	t.closed = append(t.closed, issues...)
+	return nil
+}
+
+func newService(t *testing.T, issueTracker autolinks.IssueTracker) *service_autolinks.Service {
+	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
+	assert.NoError(t, codebaseRepo.Create(codebases.Codebase{ID: "codebase"}))
+	return service_autolinks.New(db_autolinks.NewMemory(), service_audit.New(db_audit.NewMemory(), codebaseRepo), issueTracker)
+}
+
+func TestCreate_validation(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, autolinks.NoopIssueTracker{})
+
+	_, err := svc.Create(ctx, "codebase", "user", "(", "https://example.com/<num>")
+	assert.ErrorIs(t, err, service_autolinks.ErrInvalidPrefix)
+
+	_, err = svc.Create(ctx, "codebase", "user", "[A-Z]*", "https://example.com/<num>")
+	assert.ErrorIs(t, err, service_autolinks.ErrInvalidPrefix)
+
+	_, err = svc.Create(ctx, "codebase", "user", "JIRA-", "https://example.com/")
+	assert.ErrorIs(t, err, service_autolinks.ErrInvalidURLTemplate)
+
+	_, err = svc.Create(ctx, "codebase", "user", "JIRA-", "javascript:alert(<num>)")
+	assert.ErrorIs(t, err, service_autolinks.ErrInvalidURLTemplate)
+
+	autolink, err := svc.Create(ctx, "codebase", "user", "JIRA-", "https://example.com/<num>")
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Delete(ctx, autolink))
+	assert.ErrorIs(t, svc.Delete(ctx, autolink), service_autolinks.ErrAlreadyDeleted)
+
+	list, err := svc.ListByCodebaseID(ctx, "codebase")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCloseIssues(t *testing.T) {
+	ctx := context.Background()
+	issueTracker := &tracker{}
+	svc := newService(t, issueTracker)
+
+	assert.NoError(t, svc.CloseIssues(ctx, &changes.Change{UpdatedDescription: "<p>Mentions #1</p>"}))
+	assert.Empty(t, issueTracker.closed)
+
+	assert.NoError(t, svc.CloseIssues(ctx, &changes.Change{UpdatedDescription: "<p>Fixes #1 and closes #2</p>"}))
+	assert.Equal(t, []int{1, 2}, issueTracker.closed)
+}
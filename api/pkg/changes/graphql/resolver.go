@@ -52,6 +52,22 @@ func (r *ChangeResolver) Description() string {
 	return r.ch.UpdatedDescription
 }
 
+func (r *ChangeResolver) RenderedTitle(ctx context.Context) (string, error) {
+	linker, err := r.root.autolinksService.Linker(ctx, r.ch.CodebaseID)
+	if err != nil {
+		return "", gqlerrors.Error(err)
+	}
+	return linker.Text(r.Title()), nil
+}
+
+func (r *ChangeResolver) RenderedDescription(ctx context.Context) (string, error) {
+	linker, err := r.root.autolinksService.Linker(ctx, r.ch.CodebaseID)
+	if err != nil {
+		return "", gqlerrors.Error(err)
+	}
+	return linker.HTML(r.ch.UpdatedDescription), nil
+}
+
 func (r *ChangeResolver) TrunkCommitID() (*string, error) {
 	return r.ch.CommitID, nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	service_auth "getsturdy.com/api/pkg/auth/service"
+	service_autolinks "getsturdy.com/api/pkg/autolinks/service"
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
@@ -22,7 +23,8 @@ type ChangeRootResolver struct {
 
 	commentsRepo db_comments.Repository
 
-	authService *service_auth.Service
+	authService      *service_auth.Service
+	autolinksService *service_autolinks.Service
 
 	commentResolver   *resolvers.CommentRootResolver
 	authorResolver    resolvers.AuthorRootResolver
@@ -43,6 +45,7 @@ func NewResolver(
 	commentsRepo db_comments.Repository,
 
 	authService *service_auth.Service,
+	autolinksService *service_autolinks.Service,
 
 	commentResolver *resolvers.CommentRootResolver,
 	authorResolver resolvers.AuthorRootResolver,
@@ -61,7 +64,8 @@ func NewResolver(
 
 		commentsRepo: commentsRepo,
 
-		authService: authService,
+		authService:      authService,
+		autolinksService: autolinksService,
 
 		commentResolver:   commentResolver,
 		authorResolver:    authorResolver,
@@ -258,6 +258,32 @@ func (svc *Service) ParentChange(ctx context.Context, ch *changes.Change) (*chan
 	return parent, nil
 }
 
+// ImportNewCommits imports the commits on trunk that do not have a change yet. It follows the first parents
+// from head until it reaches a change that is already linked to its parent, or until limit changes have been
+// visited.
+func (svc *Service) ImportNewCommits(ctx context.Context, codebaseID codebases.ID, limit int) error {
+	ch, err := svc.head(ctx, codebaseID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not get head change: %w", err)
+	}
+
+	for i := 0; i < limit && ch.ParentChangeID == nil; i++ {
+		parent, err := svc.ParentChange(ctx, ch)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return nil
+		case err != nil:
+			return fmt.Errorf("could not import parent change: %w", err)
+		}
+		ch = parent
+	}
+
+	return nil
+}
+
 func (svc *Service) getChangeFromCommit(ctx context.Context, codebaseID codebases.ID, commitID string) (*changes.Change, error) {
 	ch, err := svc.changeRepo.GetByCommitID(ctx, commitID, codebaseID)
 	switch {
@@ -8,17 +8,13 @@ import (
 	"getsturdy.com/api/pkg/users"
 )
 
-type userRepository interface {
-	Get(users.ID) (*users.User, error)
-}
-
-type aclProvider interface {
-	GetByCodebaseID(context.Context, string) (acl.ACL, error)
+type identitiesProvider interface {
+	UserIdentities(context.Context, users.ID) ([]acl.Identity, error)
 }
 
 func UserCan(
 	ctx context.Context,
-	userRepo userRepository,
+	identitiesProvider identitiesProvider,
 	aclPolicy acl.Policy,
 	action acl.Action,
 	resource acl.Identity,
@@ -29,36 +25,27 @@ func UserCan(
 		return false, err
 	}
 
-	allowedByID := aclPolicy.Assert(
-		acl.Identity{Type: acl.Users, ID: userID.String()},
-		action,
-		resource,
-	)
-	if allowedByID {
-		return true, nil
-	}
-
-	user, err := userRepo.Get(userID)
+	identities, err := identitiesProvider.UserIdentities(ctx, userID)
 	if err != nil {
 		return false, err
 	}
 
-	allowedByEmail := aclPolicy.Assert(
-		acl.Identity{Type: acl.Users, ID: user.Email},
-		action,
-		resource,
-	)
-	return allowedByEmail, nil
+	for _, identity := range identities {
+		if aclPolicy.Assert(identity, action, resource) {
+			return true, nil
+		}
+	}
 
+	return false, nil
 }
 
 func UserCanWriteACL(
 	ctx context.Context,
-	userRepo userRepository,
+	identitiesProvider identitiesProvider,
 	aclPolicy acl.Policy,
 	aclID string,
 ) (bool, error) {
 	action := acl.ActionWrite
 	resource := acl.Identity{Type: acl.ACLs, ID: aclID}
-	return UserCan(ctx, userRepo, aclPolicy, action, resource)
+	return UserCan(ctx, identitiesProvider, aclPolicy, action, resource)
 }
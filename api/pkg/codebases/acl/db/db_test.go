@@ -178,3 +178,41 @@ func Test_Update_not_found(t *testing.T) {
 
 	assert.Error(t, repo.Update(ctx, entity))
 }
+
+func Test_Apply_conflict(t *testing.T) {
+	d, err := db.Setup(
+		sturdytest.PsqlDbSourceForTesting(),
+	)
+	assert.NoError(t, err)
+
+	aclRepo := acl_db.NewACLRepository(d)
+	versionRepo := acl_db.NewVersionRepository(d)
+	ctx := context.Background()
+
+	entity := acl.ACL{
+		ID:         acl.ID(uuid.New().String()),
+		CodebaseID: codebases.ID(uuid.New().String()),
+		CreatedAt:  time.Now(),
+		RawPolicy:  "{}",
+	}
+	assert.NoError(t, aclRepo.Create(ctx, entity))
+
+	version := func(v int, policy string) acl.Version {
+		return acl.Version{
+			ID:        acl.VersionID(uuid.New().String()),
+			ACLID:     entity.ID,
+			Version:   v,
+			RawPolicy: policy,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	assert.NoError(t, versionRepo.Apply(ctx, entity, version(1, `{"rules": []}`)))
+	// both read version 1, only the first one is applied
+	assert.NoError(t, versionRepo.Apply(ctx, entity, version(2, `{"groups": []}`)))
+	assert.ErrorIs(t, versionRepo.Apply(ctx, entity, version(2, `{"tests": []}`)), acl_db.ErrVersionConflict)
+
+	stored, err := aclRepo.GetByCodebaseID(ctx, entity.CodebaseID)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"groups": []}`, stored.RawPolicy)
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/codebases/acl"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrVersionConflict is returned when a version can not be applied, because another version of the ACL has been
+// created since the previous version was read.
+var ErrVersionConflict = errors.New("the acl has been updated by someone else")
+
+type VersionRepository interface {
+	Create(context.Context, acl.Version) error
+	// Apply stores the version and makes its policy the policy of the ACL. ErrVersionConflict is returned if the
+	// latest version of the ACL is not the version right before it.
+	Apply(context.Context, acl.ACL, acl.Version) error
+	Get(context.Context, acl.VersionID) (acl.Version, error)
+	// ListByACLID returns all versions of the ACL, the newest version first.
+	ListByACLID(context.Context, acl.ID) ([]acl.Version, error)
+}
+
+type versionRepository struct {
+	db *sqlx.DB
+}
+
+func NewVersionRepository(db *sqlx.DB) VersionRepository {
+	return &versionRepository{
+		db: db,
+	}
+}
+
+func (r *versionRepository) Create(ctx context.Context, entity acl.Version) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO acl_versions
+		(id, acl_id, version, policy, created_at, author_id, restored_from_id)
+		VALUES
+		(:id, :acl_id, :version, :policy, :created_at, :author_id, :restored_from_id)`, entity); err != nil {
+		return fmt.Errorf("failed to perform insert: %w", err)
+	}
+	return nil
+}
+
+func (r *versionRepository) Apply(ctx context.Context, a acl.ACL, version acl.Version) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// locks the acl, so that concurrent updates are applied one after the other
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM acls WHERE id = $1 FOR UPDATE`, a.ID); err != nil {
+		return fmt.Errorf("failed to lock acl: %w", err)
+	}
+
+	var latest int
+	if err := tx.GetContext(ctx, &latest, `SELECT COALESCE(MAX(version), 0)
+		FROM acl_versions
+		WHERE acl_id = $1`, a.ID); err != nil {
+		return fmt.Errorf("failed to query table: %w", err)
+	}
+	if latest != version.Version-1 {
+		return ErrVersionConflict
+	}
+
+	if _, err := tx.NamedExecContext(ctx, `INSERT INTO acl_versions
+		(id, acl_id, version, policy, created_at, author_id, restored_from_id)
+		VALUES
+		(:id, :acl_id, :version, :policy, :created_at, :author_id, :restored_from_id)`, version); err != nil {
+		return fmt.Errorf("failed to perform insert: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE acls
+		SET policy = $1
+		WHERE id = $2`, version.RawPolicy, a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to perform update: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return fmt.Errorf("unexpected number of rows affected, expected 1, got %d", rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *versionRepository) Get(ctx context.Context, id acl.VersionID) (acl.Version, error) {
+	entity := acl.Version{}
+	if err := r.db.GetContext(ctx, &entity, `SELECT id, acl_id, version, policy, created_at, author_id, restored_from_id
+		FROM acl_versions
+		WHERE id = $1`, id); err != nil {
+		return acl.Version{}, fmt.Errorf("failed to query table: %w", err)
+	}
+	return entity, nil
+}
+
+func (r *versionRepository) ListByACLID(ctx context.Context, aclID acl.ID) ([]acl.Version, error) {
+	entities := []acl.Version{}
+	if err := r.db.SelectContext(ctx, &entities, `SELECT id, acl_id, version, policy, created_at, author_id, restored_from_id
+		FROM acl_versions
+		WHERE acl_id = $1
+		ORDER BY version DESC`, aclID); err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+	return entities, nil
+}
@@ -2,31 +2,34 @@ package graphql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 
+	"getsturdy.com/api/pkg/auth"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/codebases/acl"
 	"getsturdy.com/api/pkg/codebases/acl/access"
 	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
-	db_user "getsturdy.com/api/pkg/users/db"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/tailscale/hujson"
 )
 
 type ACLRootResolver struct {
-	aclProvider *provider_acl.Provider
-	userRepo    db_user.Repository
+	aclProvider    *provider_acl.Provider
+	authorResolver resolvers.AuthorRootResolver
 }
 
 func NewResolver(
 	aclProvider *provider_acl.Provider,
-	userRepo db_user.Repository,
+	authorResolver resolvers.AuthorRootResolver,
 ) resolvers.ACLRootResolver {
 	return &ACLRootResolver{
-		aclProvider: aclProvider,
-		userRepo:    userRepo,
+		aclProvider:    aclProvider,
+		authorResolver: authorResolver,
 	}
 }
 
@@ -48,7 +51,7 @@ func (r *ACLRootResolver) CanI(ctx context.Context, args resolvers.CanIArgs) (bo
 		return false, gqlerrors.Error(err)
 	}
 
-	allowed, err := access.UserCan(ctx, r.userRepo, a.Policy, action, *resource)
+	allowed, err := access.UserCan(ctx, r.aclProvider, a.Policy, action, *resource)
 	if err != nil {
 		return false, gqlerrors.Error(err)
 	}
@@ -66,44 +69,133 @@ func (r *ACLRootResolver) InternalACLByCodebaseID(ctx context.Context, codebaseI
 }
 
 func (r *ACLRootResolver) UpdateACL(ctx context.Context, args resolvers.UpdateACLArgs) (resolvers.ACLResolver, error) {
-	a, err := r.aclProvider.GetByCodebaseID(ctx, codebases.ID(args.Input.CodebaseID))
+	a, err := r.writableACL(ctx, codebases.ID(args.Input.CodebaseID))
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
 
-	allowed, err := access.UserCanWriteACL(ctx, r.userRepo, a.Policy, string(a.ID))
+	if args.Input.Policy == nil {
+		return &aclResolver{a: a, root: r}, nil
+	}
+
+	policy, err := parsePolicy(*args.Input.Policy, a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := auth.UserID(ctx)
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
-	if !allowed {
-		return nil, gqlerrors.Error(gqlerrors.ErrForbidden)
+
+	a.RawPolicy = *args.Input.Policy
+	a.Policy = policy
+
+	switch err := r.aclProvider.Update(ctx, a, userID); {
+	case err == nil:
+	case errors.Is(err, provider_acl.ErrConflict):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "policy", err.Error())
+	default:
+		return nil, gqlerrors.Error(err)
 	}
 
-	if args.Input.Policy == nil {
+	return &aclResolver{a: a, root: r}, nil
+}
+
+func (r *ACLRootResolver) RollbackACL(ctx context.Context, args resolvers.RollbackACLArgs) (resolvers.ACLResolver, error) {
+	a, err := r.writableACL(ctx, codebases.ID(args.Input.CodebaseID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	version, err := r.aclProvider.GetVersion(ctx, acl.VersionID(args.Input.VersionID))
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, gqlerrors.Error(gqlerrors.ErrNotFound, "versionID", "version not found")
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+
+	// the policy must still be valid, for example it's tests must pass
+	if _, err := parsePolicy(version.RawPolicy, a.ID); err != nil {
+		return nil, err
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	a, err = r.aclProvider.Rollback(ctx, a, version.ID, userID)
+	switch {
+	case err == nil:
 		return &aclResolver{a: a, root: r}, nil
+	case errors.Is(err, provider_acl.ErrVersionNotFound):
+		return nil, gqlerrors.Error(gqlerrors.ErrNotFound, "versionID", "version not found")
+	case errors.Is(err, provider_acl.ErrConflict):
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "versionID", err.Error())
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+}
+
+func (r *ACLRootResolver) SimulateACL(ctx context.Context, args resolvers.SimulateACLArgs) ([]resolvers.ACLPermissionChangeResolver, error) {
+	a, err := r.writableACL(ctx, codebases.ID(args.Input.CodebaseID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
 	}
 
+	draft, err := parsePolicy(args.Input.Policy, a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := r.aclProvider.Simulate(ctx, a, draft)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.ACLPermissionChangeResolver, 0, len(changes))
+	for _, change := range changes {
+		res = append(res, &permissionChangeResolver{change: change, root: r})
+	}
+	return res, nil
+}
+
+// writableACL returns the ACL of the codebase, if the authenticated user is allowed to change it.
+func (r *ACLRootResolver) writableACL(ctx context.Context, codebaseID codebases.ID) (acl.ACL, error) {
+	a, err := r.aclProvider.GetByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return acl.ACL{}, err
+	}
+
+	allowed, err := access.UserCanWriteACL(ctx, r.aclProvider, a.Policy, string(a.ID))
+	if err != nil {
+		return acl.ACL{}, err
+	}
+	if !allowed {
+		return acl.ACL{}, gqlerrors.ErrForbidden
+	}
+
+	return a, nil
+}
+
+func parsePolicy(raw string, aclID acl.ID) (acl.Policy, error) {
 	policy := acl.Policy{}
-	if err := hujson.Unmarshal([]byte(*args.Input.Policy), &policy); err != nil {
-		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "policy", "failed to decode as json")
+	if err := hujson.Unmarshal([]byte(raw), &policy); err != nil {
+		return acl.Policy{}, gqlerrors.Error(gqlerrors.ErrBadRequest, "policy", "failed to decode as json")
 	}
 
-	if errs := policy.Errors(string(a.ID)); len(errs) > 0 {
+	if errs := policy.Errors(string(aclID)); len(errs) > 0 {
 		msgs := make([]string, 0, len(errs)*2)
 		for k, v := range errs {
 			msgs = append(msgs, k, v.Error())
 		}
-		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, msgs...)
-
-	}
-
-	a.RawPolicy = *args.Input.Policy
-
-	if err := r.aclProvider.Update(ctx, a); err != nil {
-		return nil, gqlerrors.Error(err)
+		return acl.Policy{}, gqlerrors.Error(gqlerrors.ErrBadRequest, msgs...)
 	}
 
-	return &aclResolver{a: a, root: r}, nil
+	return policy, nil
 }
 
 type aclResolver struct {
@@ -118,3 +210,115 @@ func (r *aclResolver) ID() graphql.ID {
 func (r *aclResolver) Policy() (string, error) {
 	return r.a.RawPolicy, nil
 }
+
+func (r *aclResolver) Versions(ctx context.Context) ([]resolvers.ACLVersionResolver, error) {
+	versions, err := r.root.aclProvider.ListVersions(ctx, r.a.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.ACLVersionResolver, 0, len(versions))
+	for _, v := range versions {
+		res = append(res, &aclVersionResolver{v: v, root: r.root})
+	}
+	return res, nil
+}
+
+type aclVersionResolver struct {
+	v    acl.Version
+	root *ACLRootResolver
+}
+
+func (r *aclVersionResolver) ID() graphql.ID {
+	return graphql.ID(r.v.ID)
+}
+
+func (r *aclVersionResolver) Version() int32 {
+	return int32(r.v.Version)
+}
+
+func (r *aclVersionResolver) Policy() string {
+	return r.v.RawPolicy
+}
+
+func (r *aclVersionResolver) CreatedAt() int32 {
+	return int32(r.v.CreatedAt.Unix())
+}
+
+func (r *aclVersionResolver) Author(ctx context.Context) (resolvers.AuthorResolver, error) {
+	if r.v.AuthorID == nil {
+		return nil, nil
+	}
+	return r.root.authorResolver.Author(ctx, graphql.ID(*r.v.AuthorID))
+}
+
+func (r *aclVersionResolver) RestoredFrom(ctx context.Context) (resolvers.ACLVersionResolver, error) {
+	if r.v.RestoredFromID == nil {
+		return nil, nil
+	}
+	v, err := r.root.aclProvider.GetVersion(ctx, *r.v.RestoredFromID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return &aclVersionResolver{v: v, root: r.root}, nil
+}
+
+func (r *aclVersionResolver) Diff(ctx context.Context, args resolvers.ACLVersionDiffArgs) (string, error) {
+	against, err := r.against(ctx, args.Against)
+	if err != nil {
+		return "", gqlerrors.Error(err)
+	}
+
+	diff, err := acl.Diff(&against, &r.v)
+	if err != nil {
+		return "", gqlerrors.Error(err)
+	}
+	return diff, nil
+}
+
+// against returns the version with the given id, or the version preceding this one.
+func (r *aclVersionResolver) against(ctx context.Context, id *graphql.ID) (acl.Version, error) {
+	if id != nil {
+		v, err := r.root.aclProvider.GetVersion(ctx, acl.VersionID(*id))
+		if err != nil {
+			return acl.Version{}, err
+		}
+		if v.ACLID != r.v.ACLID {
+			return acl.Version{}, fmt.Errorf("version %s belongs to another acl: %w", v.ID, gqlerrors.ErrBadRequest)
+		}
+		return v, nil
+	}
+
+	versions, err := r.root.aclProvider.ListVersions(ctx, r.v.ACLID)
+	if err != nil {
+		return acl.Version{}, err
+	}
+	for _, v := range versions {
+		if v.Version < r.v.Version {
+			return v, nil
+		}
+	}
+	// the first version is compared against an empty policy
+	return acl.Version{ACLID: r.v.ACLID}, nil
+}
+
+type permissionChangeResolver struct {
+	change provider_acl.SimulatedChange
+	root   *ACLRootResolver
+}
+
+func (r *permissionChangeResolver) User(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorResolver.Author(ctx, graphql.ID(r.change.User.ID))
+}
+
+func (r *permissionChangeResolver) Action() string {
+	return string(r.change.Change.Action)
+}
+
+func (r *permissionChangeResolver) Resource() string {
+	return r.change.Change.Resource.String()
+}
+
+func (r *permissionChangeResolver) Granted() bool {
+	return r.change.Change.Granted
+}
@@ -13,21 +13,26 @@ type Identifier struct {
 	Pattern string       `json:"pattern,omitempty"`
 }
 
-// MarshalJSON implements encoding/json.Marshaller to override resulting format.
-func (i *Identifier) MarshalJSON() ([]byte, error) {
+// String returns the identifier in the same format as it's written in a policy.
+func (i *Identifier) String() string {
 	if i.Pattern == "" && i.Type == "" {
-		return json.Marshal("")
+		return ""
 	}
 	if i.Pattern == "" {
-		return json.Marshal(i.Type)
+		return string(i.Type)
 	}
 	if i.Type == "" {
-		return json.Marshal(i.Pattern)
+		return i.Pattern
 	}
 	if i.Type == Users {
-		return json.Marshal(i.Pattern)
+		return i.Pattern
 	}
-	return json.Marshal(fmt.Sprintf("%s::%s", i.Type, i.Pattern))
+	return fmt.Sprintf("%s::%s", i.Type, i.Pattern)
+}
+
+// MarshalJSON implements encoding/json.Marshaller to override resulting format.
+func (i *Identifier) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
 }
 
 // UnmarshalJSON implements encoding/json.UnmarshalJSON to parse source JSON in a different way.
@@ -10,6 +10,7 @@ type identityType string
 
 var supportedIdentityTypes = map[identityType]bool{
 	Users:     true,
+	Teams:     true,
 	Groups:    true,
 	Codebases: true,
 	ACLs:      true,
@@ -22,6 +23,7 @@ func (it identityType) IsValid() bool {
 
 const (
 	Users     identityType = "users"
+	Teams     identityType = "teams"
 	Codebases identityType = "codebases"
 	Groups    identityType = "groups"
 	ACLs      identityType = "acls"
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"time"
 
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/codebases/acl"
 	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	service_teams "getsturdy.com/api/pkg/organization/teams/service"
 	"getsturdy.com/api/pkg/users"
 	service_users "getsturdy.com/api/pkg/users/service"
 
@@ -18,21 +21,36 @@ import (
 	"github.com/tailscale/hujson"
 )
 
+var (
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrConflict is returned when the ACL is updated by someone else at the same time.
+	ErrConflict = errors.New("the acl has been updated by someone else, try again")
+)
+
 type Provider struct {
 	aclDB          db_acl.ACLRepository
+	versionsDB     db_acl.VersionRepository
 	codebaseUserDB db_codebases.CodebaseUserRepository
 	usersService   service_users.Service
+	teamsService   *service_teams.Service
+	auditService   *service_audit.Service
 }
 
 func New(
 	aclRepo db_acl.ACLRepository,
+	versionsRepo db_acl.VersionRepository,
 	codebaseUserDB db_codebases.CodebaseUserRepository,
 	usersService service_users.Service,
+	teamsService *service_teams.Service,
+	auditService *service_audit.Service,
 ) *Provider {
 	return &Provider{
 		aclDB:          aclRepo,
+		versionsDB:     versionsRepo,
 		codebaseUserDB: codebaseUserDB,
 		usersService:   usersService,
+		teamsService:   teamsService,
+		auditService:   auditService,
 	}
 }
 
@@ -57,11 +75,16 @@ func (p *Provider) GetByCodebaseID(ctx context.Context, codebaseID codebases.ID)
 }
 
 func (p *Provider) createDefaultPolicy(ctx context.Context, codebaseID codebases.ID) (acl.ACL, error) {
-	emails, err := p.getUserEmailsForCodebase(ctx, codebaseID)
+	uu, err := p.getUsersForCodebase(ctx, codebaseID)
 	if err != nil {
 		return acl.ACL{}, err
 	}
 
+	emails := make([]string, 0, len(uu))
+	for _, user := range uu {
+		emails = append(emails, user.Email)
+	}
+
 	a := acl.ACL{
 		ID:         acl.ID(uuid.New().String()),
 		CodebaseID: codebaseID,
@@ -79,10 +102,20 @@ func (p *Provider) createDefaultPolicy(ctx context.Context, codebaseID codebases
 		return acl.ACL{}, fmt.Errorf("failed to create default policy: %w", err)
 	}
 
+	if err := p.versionsDB.Create(ctx, acl.Version{
+		ID:        acl.VersionID(uuid.New().String()),
+		ACLID:     a.ID,
+		Version:   1,
+		RawPolicy: a.RawPolicy,
+		CreatedAt: a.CreatedAt,
+	}); err != nil {
+		return acl.ACL{}, fmt.Errorf("failed to create default policy version: %w", err)
+	}
+
 	return a, nil
 }
 
-func (p *Provider) getUserEmailsForCodebase(ctx context.Context, codebaseID codebases.ID) ([]string, error) {
+func (p *Provider) getUsersForCodebase(ctx context.Context, codebaseID codebases.ID) ([]*users.User, error) {
 	uu, err := p.codebaseUserDB.GetByCodebase(codebaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query codebase users: %w", err)
@@ -98,14 +131,146 @@ func (p *Provider) getUserEmailsForCodebase(ctx context.Context, codebaseID code
 		return nil, fmt.Errorf("failed to query users: %w", err)
 	}
 
-	emails := make([]string, 0, len(users))
-	for _, user := range users {
-		emails = append(emails, user.Email)
+	return users, nil
+}
+
+// Update stores the policy of the ACL as a new version authored by the user.
+func (p *Provider) Update(ctx context.Context, a acl.ACL, userID users.ID) error {
+	return p.update(ctx, a, userID, nil)
+}
+
+// Rollback restores the policy of a previous version. The restored policy is stored as a new version, so that
+// the history is never rewritten.
+func (p *Provider) Rollback(ctx context.Context, a acl.ACL, versionID acl.VersionID, userID users.ID) (acl.ACL, error) {
+	version, err := p.versionsDB.Get(ctx, versionID)
+	if err != nil {
+		return acl.ACL{}, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	if version.ACLID != a.ID {
+		return acl.ACL{}, fmt.Errorf("version %s does not belong to acl %s: %w", versionID, a.ID, ErrVersionNotFound)
+	}
+
+	a.RawPolicy = version.RawPolicy
+	if err := hujson.Unmarshal([]byte(a.RawPolicy), &a.Policy); err != nil {
+		return acl.ACL{}, fmt.Errorf("failed to unmarshal policy: %w", err)
+	}
+
+	if err := p.update(ctx, a, userID, &version.ID); err != nil {
+		return acl.ACL{}, err
+	}
+
+	return a, nil
+}
+
+func (p *Provider) update(ctx context.Context, a acl.ACL, userID users.ID, restoredFromID *acl.VersionID) error {
+	versions, err := p.versionsDB.ListByACLID(ctx, a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	nextVersion := 1
+	var previous *acl.Version
+	if len(versions) > 0 {
+		previous = &versions[0]
+		nextVersion = previous.Version + 1
+	}
+
+	version := acl.Version{
+		ID:             acl.VersionID(uuid.New().String()),
+		ACLID:          a.ID,
+		Version:        nextVersion,
+		RawPolicy:      a.RawPolicy,
+		CreatedAt:      time.Now().UTC(),
+		AuthorID:       &userID,
+		RestoredFromID: restoredFromID,
+	}
+	switch err := p.versionsDB.Apply(ctx, a, version); {
+	case err == nil:
+	case errors.Is(err, db_acl.ErrVersionConflict):
+		return ErrConflict
+	default:
+		return fmt.Errorf("failed to update acl: %w", err)
+	}
+
+	opts := []audit.EntryOption{
+		audit.CodebaseID(a.CodebaseID),
+		audit.After(version),
+	}
+	if previous != nil {
+		opts = append(opts, audit.Before(previous))
+	}
+	if err := p.auditService.Record(ctx, audit.ActionACLUpdated, audit.TargetACL, string(a.ID), opts...); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
 	}
 
-	return emails, nil
+	return nil
+}
+
+// ListVersions returns all versions of the ACL, the newest version first.
+func (p *Provider) ListVersions(ctx context.Context, aclID acl.ID) ([]acl.Version, error) {
+	return p.versionsDB.ListByACLID(ctx, aclID)
+}
+
+func (p *Provider) GetVersion(ctx context.Context, id acl.VersionID) (acl.Version, error) {
+	return p.versionsDB.Get(ctx, id)
 }
 
-func (p *Provider) Update(ctx context.Context, a acl.ACL) error {
-	return p.aclDB.Update(ctx, a)
+type SimulatedChange struct {
+	User   *users.User
+	Change acl.PermissionChange
+}
+
+// Simulate returns the permissions that the members of the codebase gain or lose if the policy of the ACL
+// is replaced by the draft one. Nothing is saved.
+func (p *Provider) Simulate(ctx context.Context, a acl.ACL, draft acl.Policy) ([]SimulatedChange, error) {
+	uu, err := p.getUsersForCodebase(ctx, a.CodebaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []SimulatedChange{}
+	for _, user := range uu {
+		identities, err := p.identities(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range acl.Simulate(a.Policy, draft, identities...) {
+			changes = append(changes, SimulatedChange{
+				User:   user,
+				Change: change,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// UserIdentities returns all identities that the user can be referred to by in a policy: the user ID, the email
+// and all of the teams that the user is a member of.
+func (p *Provider) UserIdentities(ctx context.Context, userID users.ID) ([]acl.Identity, error) {
+	user, err := p.usersService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return p.identities(ctx, user)
+}
+
+func (p *Provider) identities(ctx context.Context, user *users.User) ([]acl.Identity, error) {
+	identities := []acl.Identity{
+		{Type: acl.Users, ID: user.ID.String()},
+		{Type: acl.Users, ID: user.Email},
+	}
+
+	tt, err := p.teamsService.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	for _, team := range tt {
+		identities = append(identities, acl.Identity{Type: acl.Teams, ID: string(team.ID)})
+	}
+
+	return identities, nil
 }
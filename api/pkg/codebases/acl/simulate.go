@@ -0,0 +1,73 @@
+package acl
+
+import (
+	"sort"
+)
+
+// resourceTypes are the identity types that can be used as rule resources.
+var resourceTypes = []identityType{Codebases, ACLs, Files}
+
+// PermissionChange describes a permission that is granted or revoked when a policy is replaced.
+type PermissionChange struct {
+	Action   Action
+	Resource Identifier
+	// Granted is true if the permission is gained, and false if it is lost.
+	Granted bool
+}
+
+// Simulate returns the list of permissions that a principal gains or loses if the current policy is
+// replaced by the draft one.
+//
+// A single principal can be identified in multiple ways (for example, a user by both ID and email),
+// all of the identities should be passed at once.
+func Simulate(current, draft Policy, identities ...Identity) []PermissionChange {
+	changes := []PermissionChange{}
+	for action := range supportedActions {
+		for _, typ := range resourceTypes {
+			before := current.listAll(identities, action, typ)
+			after := draft.listAll(identities, action, typ)
+
+			for pattern := range after {
+				if !before[pattern] {
+					changes = append(changes, PermissionChange{
+						Action:   action,
+						Resource: Identifier{Type: typ, Pattern: pattern},
+						Granted:  true,
+					})
+				}
+			}
+
+			for pattern := range before {
+				if !after[pattern] {
+					changes = append(changes, PermissionChange{
+						Action:   action,
+						Resource: Identifier{Type: typ, Pattern: pattern},
+						Granted:  false,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Action != changes[j].Action {
+			return changes[i].Action < changes[j].Action
+		}
+		if changes[i].Resource.Type != changes[j].Resource.Type {
+			return changes[i].Resource.Type < changes[j].Resource.Type
+		}
+		return changes[i].Resource.Pattern < changes[j].Resource.Pattern
+	})
+
+	return changes
+}
+
+func (p Policy) listAll(identities []Identity, action Action, typ identityType) map[string]bool {
+	patterns := map[string]bool{}
+	for _, identity := range identities {
+		for _, pattern := range p.List(identity, action, typ) {
+			patterns[pattern] = true
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,100 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Simulate(t *testing.T) {
+	current := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "admins can manage acls",
+				Action:     ActionWrite,
+				Principals: []*Identifier{{Type: Groups, Pattern: "admins"}},
+				Resources:  []*Identifier{{Type: ACLs, Pattern: "acl-1"}},
+			},
+			{
+				ID:         "everyone can write files",
+				Action:     ActionWrite,
+				Principals: []*Identifier{{Type: Users, Pattern: "*"}},
+				Resources:  []*Identifier{{Type: Files, Pattern: "*"}},
+			},
+		},
+		Groups: []*Group{
+			{ID: "admins", Members: []*Identifier{{Type: Users, Pattern: "admin@getsturdy.com"}}},
+		},
+	}
+
+	draft := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "admins can manage acls",
+				Action:     ActionWrite,
+				Principals: []*Identifier{{Type: Groups, Pattern: "admins"}},
+				Resources:  []*Identifier{{Type: ACLs, Pattern: "acl-1"}},
+			},
+			{
+				ID:         "everyone can write docs",
+				Action:     ActionWrite,
+				Principals: []*Identifier{{Type: Users, Pattern: "*"}},
+				Resources:  []*Identifier{{Type: Files, Pattern: "docs/*"}},
+			},
+		},
+		Groups: []*Group{
+			{ID: "admins", Members: []*Identifier{{Type: Users, Pattern: "user-2"}}},
+		},
+	}
+
+	admin := Simulate(current, draft,
+		Identity{Type: Users, ID: "user-1"},
+		Identity{Type: Users, ID: "admin@getsturdy.com"},
+	)
+	assert.Equal(t, []PermissionChange{
+		{Action: ActionWrite, Resource: Identifier{Type: ACLs, Pattern: "acl-1"}, Granted: false},
+		{Action: ActionWrite, Resource: Identifier{Type: Files, Pattern: "*"}, Granted: false},
+		{Action: ActionWrite, Resource: Identifier{Type: Files, Pattern: "docs/*"}, Granted: true},
+	}, admin)
+
+	other := Simulate(current, draft,
+		Identity{Type: Users, ID: "user-2"},
+		Identity{Type: Users, ID: "other@getsturdy.com"},
+	)
+	assert.Equal(t, []PermissionChange{
+		{Action: ActionWrite, Resource: Identifier{Type: ACLs, Pattern: "acl-1"}, Granted: true},
+		{Action: ActionWrite, Resource: Identifier{Type: Files, Pattern: "*"}, Granted: false},
+		{Action: ActionWrite, Resource: Identifier{Type: Files, Pattern: "docs/*"}, Granted: true},
+	}, other)
+}
+
+func Test_Simulate_no_changes(t *testing.T) {
+	p := Policy{
+		Rules: []*Rule{
+			{
+				ID:         "everyone can write files",
+				Action:     ActionWrite,
+				Principals: []*Identifier{{Type: Users, Pattern: "*"}},
+				Resources:  []*Identifier{{Type: Files, Pattern: "*"}},
+			},
+		},
+	}
+
+	assert.Empty(t, Simulate(p, p, Identity{Type: Users, ID: "user-1"}))
+}
+
+func Test_Diff(t *testing.T) {
+	from := &Version{Version: 1, RawPolicy: "{\n  \"rules\": [],\n}"}
+	to := &Version{Version: 2, RawPolicy: "{\n  \"rules\": [],\n  \"groups\": [],\n}"}
+
+	diff, err := Diff(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, `--- version 1
++++ version 2
+@@ -1,3 +1,4 @@
+ {
+   "rules": [],
++  "groups": [],
+ }
+`, diff)
+}
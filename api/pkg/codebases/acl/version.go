@@ -0,0 +1,43 @@
+package acl
+
+import (
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+type VersionID string
+
+// Version is an immutable snapshot of an ACL policy. A new version is created every time the policy
+// of an ACL is changed, including rollbacks.
+type Version struct {
+	ID        VersionID `json:"id" db:"id"`
+	ACLID     ID        `json:"acl_id" db:"acl_id"`
+	Version   int       `json:"version" db:"version"`
+	RawPolicy string    `json:"policy" db:"policy"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// AuthorID is the user that made the change. It is nil for versions that were created by Sturdy,
+	// for example the default policy.
+	AuthorID *users.ID `json:"author_id" db:"author_id"`
+	// RestoredFromID is set if the version is a rollback to a previous version.
+	RestoredFromID *VersionID `json:"restored_from_id" db:"restored_from_id"`
+}
+
+// Diff returns a unified diff of the policies between from and to.
+func Diff(from, to *Version) (string, error) {
+	d := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.RawPolicy),
+		B:        difflib.SplitLines(to.RawPolicy),
+		FromFile: fmt.Sprintf("version %d", from.Version),
+		ToFile:   fmt.Sprintf("version %d", to.Version),
+		Context:  3,
+	}
+	diff, err := difflib.GetUnifiedDiffString(d)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff policies: %w", err)
+	}
+	return diff, nil
+}
@@ -23,6 +23,7 @@ import (
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/remote"
 	service_remote "getsturdy.com/api/pkg/remote/service"
 	"getsturdy.com/api/pkg/users"
 	db_user "getsturdy.com/api/pkg/users/db"
@@ -53,6 +54,11 @@ type CodebaseRootResolver struct {
 	codebaseGitHubIntegrationResolver resolvers.CodebaseGitHubIntegrationRootResolver
 	organizationRootResolver          *resolvers.OrganizationRootResolver
 	remoteRootResolver                resolvers.RemoteRootResolver
+	gitLabRootResolver                resolvers.GitLabRootResolver
+	serviceTokensRootResolver         resolvers.ServiceTokensRootResolver
+	webhooksRootResolver              resolvers.WebhooksRootResolver
+	autolinksRootResolver             resolvers.AutolinksRootResolver
+	garbageCollectionRootResolver     resolvers.GarbageCollectionRootResolver
 
 	logger           *zap.Logger
 	viewEvents       events.EventReader
@@ -84,6 +90,11 @@ func NewCodebaseRootResolver(
 	codebaseGitHubIntegrationResolver resolvers.CodebaseGitHubIntegrationRootResolver,
 	organizationRootResolver *resolvers.OrganizationRootResolver,
 	remoteRootResolver resolvers.RemoteRootResolver,
+	gitLabRootResolver resolvers.GitLabRootResolver,
+	serviceTokensRootResolver resolvers.ServiceTokensRootResolver,
+	webhooksRootResolver resolvers.WebhooksRootResolver,
+	autolinksRootResolver resolvers.AutolinksRootResolver,
+	garbageCollectionRootResolver resolvers.GarbageCollectionRootResolver,
 
 	logger *zap.Logger,
 	viewEvents events.EventReader,
@@ -114,6 +125,11 @@ func NewCodebaseRootResolver(
 		codebaseGitHubIntegrationResolver: codebaseGitHubIntegrationResolver,
 		organizationRootResolver:          organizationRootResolver,
 		remoteRootResolver:                remoteRootResolver,
+		gitLabRootResolver:                gitLabRootResolver,
+		serviceTokensRootResolver:         serviceTokensRootResolver,
+		webhooksRootResolver:              webhooksRootResolver,
+		autolinksRootResolver:             autolinksRootResolver,
+		garbageCollectionRootResolver:     garbageCollectionRootResolver,
 
 		logger:           logger.Named("CodebaseRootResolver"),
 		viewEvents:       viewEvents,
@@ -655,6 +671,42 @@ func (r *CodebaseResolver) Remote(ctx context.Context) (resolvers.RemoteResolver
 	}
 }
 
+func (r *CodebaseResolver) Remotes(ctx context.Context) ([]resolvers.RemoteResolver, error) {
+	return r.root.remoteRootResolver.InternalRemotesByCodebaseID(ctx, codebases.ID(r.ID()))
+}
+
+func (r *CodebaseResolver) GitLabIntegration(ctx context.Context) (resolvers.GitLabIntegrationResolver, error) {
+	resolver, err := r.root.gitLabRootResolver.InternalGitLabIntegrationByCodebaseID(ctx, codebases.ID(r.ID()))
+	switch {
+	case err == nil:
+		return resolver, nil
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, gqlerrors.ErrNotFound):
+		return nil, nil
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+}
+
+func (r *CodebaseResolver) ServiceTokens(ctx context.Context) ([]resolvers.ServiceTokenResovler, error) {
+	return r.root.serviceTokensRootResolver.InternalListByCodebaseID(ctx, r.ID())
+}
+
+func (r *CodebaseResolver) Webhooks(ctx context.Context) ([]resolvers.WebhookResolver, error) {
+	return r.root.webhooksRootResolver.InternalListByCodebaseID(ctx, r.ID())
+}
+
+func (r *CodebaseResolver) Autolinks(ctx context.Context) ([]resolvers.AutolinkResolver, error) {
+	return r.root.autolinksRootResolver.InternalListByCodebaseID(ctx, r.ID())
+}
+
+func (r *CodebaseResolver) GarbageCollectionPolicy(ctx context.Context) (resolvers.GarbageCollectionPolicyResolver, error) {
+	return r.root.garbageCollectionRootResolver.InternalPolicyByCodebaseID(ctx, r.ID())
+}
+
+func (r *CodebaseResolver) GarbageCollectionRuns(ctx context.Context, args resolvers.CodebaseGarbageCollectionRunsArgs) ([]resolvers.GarbageCollectionRunResolver, error) {
+	return r.root.garbageCollectionRootResolver.InternalListRunsByCodebaseID(ctx, r.ID(), args.Last)
+}
+
 func (r *CodebaseResolver) Writeable(ctx context.Context) bool {
 	if err := r.root.authService.CanWrite(ctx, r.c); err == nil {
 		return true
@@ -704,12 +756,24 @@ func (r *CodebaseRootResolver) PullCodebase(ctx context.Context, args resolvers.
 	}
 
 	if err := r.remoteService.Pull(ctx, c.ID); err != nil {
-		return nil, gqlerrors.Error(err)
+		return nil, remoteError(err)
 	}
 
 	return &CodebaseResolver{c: c, root: r}, nil
 }
 
+func isHostKeyError(err error) bool {
+	return errors.Is(err, remote.ErrHostKeyNotConfirmed) || errors.Is(err, remote.ErrHostKeyChanged)
+}
+
+// remoteError returns host key verification errors as-is, the user needs to confirm or rotate the key.
+func remoteError(err error) error {
+	if isHostKeyError(err) {
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "message", err.Error())
+	}
+	return gqlerrors.Error(err)
+}
+
 func (r *CodebaseRootResolver) PushCodebase(ctx context.Context, args resolvers.PushCodebaseArgs) (resolvers.CodebaseResolver, error) {
 	c, err := r.codebaseRepo.Get(codebases.ID(args.Input.CodebaseID))
 	if err != nil {
@@ -720,7 +784,17 @@ func (r *CodebaseRootResolver) PushCodebase(ctx context.Context, args resolvers.
 		return nil, gqlerrors.Error(err)
 	}
 
-	if err := r.remoteService.PushTrunk(ctx, c.ID); err != nil {
+	results, err := r.remoteService.PushTrunk(ctx, c.ID)
+	if err != nil {
+		return nil, remoteError(err)
+	}
+	for _, result := range results.Failed() {
+		if isHostKeyError(result.Err) {
+			return nil, remoteError(result.Err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		// the result of each push is also available on the remotes
 		return nil, gqlerrors.Error(err)
 	}
 
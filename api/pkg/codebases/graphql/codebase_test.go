@@ -21,8 +21,8 @@ import (
 func TestCodebaseAccess(t *testing.T) {
 	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
 	codebaseUserRepo := inmemory.NewInMemoryCodebaseUserRepo()
-	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil)
-	authService := service_auth.New(codebaseService, nil, nil, nil, nil, nil)
+	codebaseService := service_codebase.New(codebaseRepo, codebaseUserRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+	authService := service_auth.New(codebaseService, nil, nil, nil, nil, nil, nil)
 	resolver := NewCodebaseRootResolver(
 		codebaseRepo,
 		codebaseUserRepo,
@@ -39,6 +39,11 @@ func TestCodebaseAccess(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		zap.NewNop(),
 		nil,
 		nil,
@@ -12,6 +12,8 @@ import (
 
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/auth"
 	service_changes "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
@@ -38,6 +40,7 @@ type Service struct {
 	eventsSender     events.EventSender
 	analyticsService *service_analytics.Service
 	changeService    *service_changes.Service
+	auditService     *service_audit.Service
 }
 
 func New(
@@ -52,6 +55,7 @@ func New(
 	eventsSender events.EventSender,
 	analyticsService *service_analytics.Service,
 	changeService *service_changes.Service,
+	auditService *service_audit.Service,
 ) *Service {
 	return &Service{
 		repo:             repo,
@@ -65,6 +69,7 @@ func New(
 		eventsSender:     eventsSender,
 		analyticsService: analyticsService,
 		changeService:    changeService,
+		auditService:     auditService,
 	}
 }
 
@@ -178,9 +183,23 @@ func (svc *Service) orgsByUser(ctx context.Context, userID users.ID) (map[string
 }
 
 func (svc *Service) Update(ctx context.Context, cb *codebases.Codebase) error {
+	before, err := svc.repo.GetAllowArchived(cb.ID)
+	if err != nil {
+		return fmt.Errorf("could not get codebase: %w", err)
+	}
+	// copy, the repository might return the same instance that is being updated
+	beforeValue := auditValue(before)
+
 	if err := svc.repo.Update(cb); err != nil {
 		return fmt.Errorf("could not update codebase: %w", err)
 	}
+	if err := svc.auditService.Record(ctx, audit.ActionCodebaseUpdated, audit.TargetCodebase, cb.ID.String(),
+		audit.CodebaseID(cb.ID),
+		audit.Before(beforeValue),
+		audit.After(auditValue(cb)),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
 	if err := svc.eventsSender.Codebase(cb.ID, events.CodebaseUpdated, cb.ID.String()); err != nil {
 		svc.logger.Error("failed to send codebase updated event", zap.Error(err))
 	}
@@ -233,6 +252,13 @@ func (svc *Service) Create(ctx context.Context, name string, organizationID *str
 		return nil, fmt.Errorf("failed to add creator as member: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionCodebaseCreated, audit.TargetCodebase, cb.ID.String(),
+		audit.CodebaseID(cb.ID),
+		audit.After(auditValue(&cb)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	svc.analyticsService.IdentifyCodebase(ctx, &cb)
 
 	opts := []analytics.CaptureOption{
@@ -298,6 +324,13 @@ func (svc *Service) AddUser(ctx context.Context, codebaseID codebases.ID, user *
 		return nil, fmt.Errorf("could not add user: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionCodebaseUserAdded, audit.TargetUser, user.ID.String(),
+		audit.CodebaseID(codebaseID),
+		audit.After(member),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	// Send events
 	if err := svc.eventsSender.Codebase(codebaseID, events.CodebaseUpdated, codebaseID.String()); err != nil {
 		svc.logger.Error("failed to send events", zap.Error(err))
@@ -324,6 +357,13 @@ func (svc *Service) RemoveUser(ctx context.Context, codebaseID codebases.ID, use
 		return fmt.Errorf("failed to delete: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionCodebaseUserRemoved, audit.TargetUser, userID.String(),
+		audit.CodebaseID(codebaseID),
+		audit.Before(member),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	// Send events
 	if err := svc.eventsSender.Codebase(codebaseID, events.CodebaseUpdated, codebaseID.String()); err != nil {
 		svc.logger.Error("failed to send events", zap.Error(err))
@@ -336,3 +376,10 @@ func (svc *Service) RemoveUser(ctx context.Context, codebaseID codebases.ID, use
 
 	return nil
 }
+
+// auditValue returns a copy of the codebase that is safe to store in the audit log.
+func auditValue(cb *codebases.Codebase) codebases.Codebase {
+	c := *cb
+	c.InviteCode = nil
+	return c
+}
@@ -12,6 +12,7 @@ import (
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
 	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
+	service_autolinks "getsturdy.com/api/pkg/autolinks/service"
 	"getsturdy.com/api/pkg/changes"
 	service_change "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
@@ -63,10 +64,12 @@ type CommentRootResolver struct {
 	authService              *service_auth.Service
 	changeService            *service_change.Service
 	userService              service_users.Service
+	autolinksService         *service_autolinks.Service
 
 	eventsReader       events.EventReader
 	eventsSubscriber   *eventsv2.Subscriber
 	eventsSender       events.EventSender
+	eventsPublisher    *eventsv2.Publisher
 	notificationSender notification_sender.NotificationSender
 	activitySender     sender_workspace_activity.ActivitySender
 
@@ -91,9 +94,11 @@ func NewResolver(
 	eventsSender events.EventSender,
 	eventsSubscriber *eventsv2.Subscriber,
 	eventsReader events.EventReader,
+	eventsPublisher *eventsv2.Publisher,
 	notificationSender notification_sender.NotificationSender,
 	activitySender sender_workspace_activity.ActivitySender,
 	userService service_users.Service,
+	autolinksService *service_autolinks.Service,
 
 	authorResolver resolvers.AuthorRootResolver,
 	workspaceResolver *resolvers.WorkspaceRootResolver,
@@ -115,10 +120,12 @@ func NewResolver(
 		authService:              authService,
 		changeService:            changeService,
 		userService:              userService,
+		autolinksService:         autolinksService,
 
 		eventsSender:       eventsSender,
 		eventsSubscriber:   eventsSubscriber,
 		eventsReader:       eventsReader,
+		eventsPublisher:    eventsPublisher,
 		notificationSender: notificationSender,
 		activitySender:     activitySender,
 
@@ -315,6 +322,11 @@ func (r *CommentRootResolver) UpdateComment(ctx context.Context, args resolvers.
 		}
 	}
 
+	if err := r.eventsPublisher.CommentUpdated(ctx, eventsv2.Codebase(comment.CodebaseID), comment); err != nil {
+		r.logger.Error("failed to send comment updated event", zap.Error(err))
+		// do not fail
+	}
+
 	r.analyticsService.Capture(ctx, "updated comment",
 		analytics.CodebaseID(comment.CodebaseID),
 		analytics.Property("comment_id", comment.ID),
@@ -344,6 +356,11 @@ func (r *CommentRootResolver) DeleteComment(ctx context.Context, args resolvers.
 		return nil, gqlerrors.Error(err)
 	}
 
+	if err := r.eventsPublisher.CommentUpdated(ctx, eventsv2.Codebase(comm.CodebaseID), &comm); err != nil {
+		r.logger.Error("failed to send comment updated event", zap.Error(err))
+		// do not fail
+	}
+
 	return &CommentResolver{root: r, comment: comm}, nil
 }
 
@@ -400,6 +417,11 @@ func (r *CommentRootResolver) CreateComment(ctx context.Context, args resolvers.
 		return nil, gqlerrors.Error(err)
 	}
 
+	if err := r.eventsPublisher.CommentUpdated(ctx, eventsv2.Codebase(comment.CodebaseID), comment); err != nil {
+		r.logger.Error("failed to send comment updated event", zap.Error(err))
+		// do not fail
+	}
+
 	if comment.ChangeID != nil {
 		sendNotificationsTo := map[users.ID]struct{}{}
 
@@ -672,6 +694,14 @@ func (r *CommentResolver) Message() string {
 	return r.comment.Message
 }
 
+func (r *CommentResolver) RenderedMessage(ctx context.Context) (string, error) {
+	linker, err := r.root.autolinksService.Linker(ctx, r.comment.CodebaseID)
+	if err != nil {
+		return "", gqlerrors.Error(err)
+	}
+	return linker.Text(r.comment.Message), nil
+}
+
 func allAreEqual(a ...bool) bool {
 	if len(a) == 0 {
 		return true
@@ -5,11 +5,15 @@ import (
 	service_ci "getsturdy.com/api/pkg/ci/service"
 	"getsturdy.com/api/pkg/db"
 	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/emails/smtp"
+	"getsturdy.com/api/pkg/events/fanout"
 	"getsturdy.com/api/pkg/gitserver"
 	"getsturdy.com/api/pkg/http"
+	"getsturdy.com/api/pkg/http/restricted"
 	"getsturdy.com/api/pkg/logger"
 	"getsturdy.com/api/pkg/metrics"
 	"getsturdy.com/api/pkg/pprof"
+	"getsturdy.com/api/pkg/queue/postgres"
 	"getsturdy.com/api/pkg/users/avatars/uploader"
 	"getsturdy.com/api/vcs/provider"
 
@@ -27,6 +31,8 @@ type Base struct {
 	Pprof    *pprof.Configuration      `flags-group:"pprof" namespace:"pprof"`
 	Metrics  *metrics.Configuration    `flags-group:"metrics" namespace:"metrics"`
 	Logger   *logger.Configuration     `flags-group:"logger" namespace:"logger"`
+	Events   *fanout.Configuration     `flags-group:"events" namespace:"events"`
+	Outbound *restricted.Configuration `flags-group:"outbound" namespace:"outbound"`
 }
 
 type Configuration struct {
@@ -34,6 +40,8 @@ type Configuration struct {
 
 	Analytics *proxy.Configuration    `flags-group:"analytics" namespace:"analytics"`
 	Avatars   *uploader.Configuration `flags-group:"avatars" namespace:"users.avatars"`
+	SMTP      *smtp.Configuration     `flags-group:"smtp" namespace:"smtp" env-namespace:"STURDY_SMTP"`
+	Queue     *postgres.Configuration `flags-group:"queue" namespace:"queue"`
 }
 
 func New() (Configuration, error) {
@@ -3,7 +3,10 @@ package selfhosted
 import (
 	"getsturdy.com/api/pkg/analytics/proxy"
 	"getsturdy.com/api/pkg/configuration"
+	"getsturdy.com/api/pkg/emails/smtp"
 	"getsturdy.com/api/pkg/github/enterprise/config"
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/queue/postgres"
 	"getsturdy.com/api/pkg/users/avatars/uploader"
 
 	"github.com/jessevdk/go-flags"
@@ -15,6 +18,9 @@ type Configuration struct {
 	GitHub    *config.GitHubAppConfig `flags-group:"github-app" namespace:"github-app" env-namespace:"STURDY_GITHUB_APP"`
 	Analytics *proxy.Configuration    `flags-group:"analytics" namespace:"analytics"`
 	Avatars   *uploader.Configuration `flags-group:"avatars" namespace:"users.avatars"`
+	OIDC      *oidc.Configuration     `flags-group:"oidc" namespace:"oidc" env-namespace:"STURDY_OIDC"`
+	SMTP      *smtp.Configuration     `flags-group:"smtp" namespace:"smtp" env-namespace:"STURDY_SMTP"`
+	Queue     *postgres.Configuration `flags-group:"queue" namespace:"queue"`
 }
 
 func New() (Configuration, error) {
@@ -10,12 +10,16 @@ import (
 	"getsturdy.com/api/pkg/configuration/flags"
 	"getsturdy.com/api/pkg/db"
 	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/emails/smtp"
+	"getsturdy.com/api/pkg/events/fanout"
 	"getsturdy.com/api/pkg/gitserver"
 	"getsturdy.com/api/pkg/http"
+	"getsturdy.com/api/pkg/http/restricted"
 	"getsturdy.com/api/pkg/internal/sturdytest"
 	"getsturdy.com/api/pkg/logger"
 	"getsturdy.com/api/pkg/metrics"
 	"getsturdy.com/api/pkg/pprof"
+	"getsturdy.com/api/pkg/queue/postgres"
 	"getsturdy.com/api/pkg/users/avatars/uploader"
 	"getsturdy.com/api/vcs/provider"
 )
@@ -74,10 +78,15 @@ func TestingModule(c *di.Container) {
 				Logger: &logger.Configuration{
 					Level: "INFO",
 				},
+				Events: &fanout.Configuration{Backend: fanout.BackendNone},
+				// tests run their servers on localhost
+				Outbound: &restricted.Configuration{AllowPrivateAddresses: true},
 			},
 
 			Analytics: &proxy.Configuration{Disable: true},
 			Avatars:   &uploader.Configuration{},
+			SMTP:      &smtp.Configuration{},
+			Queue:     &postgres.Configuration{Memory: true},
 		}
 	})
 }
@@ -0,0 +1 @@
+DROP TABLE acl_versions;
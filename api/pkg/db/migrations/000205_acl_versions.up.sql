@@ -0,0 +1,22 @@
+CREATE TABLE acl_versions
+(
+    id               TEXT PRIMARY KEY,
+    acl_id           TEXT                     NOT NULL,
+    version          INTEGER                  NOT NULL,
+    policy           TEXT                     NOT NULL,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    author_id        TEXT,
+    restored_from_id TEXT
+);
+
+CREATE UNIQUE INDEX acl_versions_acl_id_version_idx ON
+    acl_versions (acl_id, version);
+
+-- Existing policies become the first version
+INSERT INTO acl_versions (id, acl_id, version, policy, created_at)
+SELECT md5(random()::text || clock_timestamp()::text)::uuid,
+       id,
+       1,
+       policy,
+       created_at
+FROM acls;
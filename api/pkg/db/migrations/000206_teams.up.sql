@@ -0,0 +1,32 @@
+CREATE TABLE teams
+(
+    id              TEXT PRIMARY KEY,
+    organization_id TEXT                     NOT NULL,
+    parent_id       TEXT,
+    name            TEXT                     NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by      TEXT                     NOT NULL,
+    deleted_at      TIMESTAMP WITH TIME ZONE,
+    deleted_by      TEXT
+);
+
+CREATE INDEX teams_organization_id_idx ON
+    teams (organization_id);
+
+CREATE TABLE team_members
+(
+    id         TEXT PRIMARY KEY,
+    team_id    TEXT                     NOT NULL,
+    user_id    TEXT                     NOT NULL,
+    role       TEXT                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by TEXT                     NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    deleted_by TEXT
+);
+
+CREATE UNIQUE INDEX team_members_team_id_user_id_idx ON
+    team_members (team_id, user_id);
+
+CREATE INDEX team_members_user_id_idx ON
+    team_members (user_id);
@@ -0,0 +1,6 @@
+DROP INDEX servicetokens_codebase_id_idx;
+
+ALTER TABLE servicetokens
+    DROP COLUMN scopes,
+    DROP COLUMN expires_at,
+    DROP COLUMN revoked_at;
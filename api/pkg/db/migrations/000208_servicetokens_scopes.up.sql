@@ -0,0 +1,11 @@
+-- existing tokens keep the access that they had before scopes were introduced
+ALTER TABLE servicetokens
+    ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{git:read,statuses:write}',
+    ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN revoked_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE servicetokens
+    ALTER COLUMN scopes DROP DEFAULT;
+
+CREATE INDEX servicetokens_codebase_id_idx ON
+    servicetokens (codebase_id);
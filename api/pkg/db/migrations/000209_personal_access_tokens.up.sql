@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT                     NOT NULL,
+    hash         BYTEA                    NOT NULL,
+    name         TEXT                     NOT NULL,
+    scopes       TEXT[]                   NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    expires_at   TIMESTAMP WITH TIME ZONE,
+    revoked_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX personal_access_tokens_user_id_idx ON
+    personal_access_tokens (user_id);
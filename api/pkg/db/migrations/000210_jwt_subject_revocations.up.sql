@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS jwt_subject_revocations (
+    subject    TEXT PRIMARY KEY,
+    revoked_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS scim_tokens (
+    id              TEXT PRIMARY KEY,
+    organization_id TEXT                     NOT NULL,
+    hash            BYTEA                    NOT NULL,
+    created_by      TEXT                     NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at    TIMESTAMP WITH TIME ZONE,
+    revoked_at      TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS scim_tokens_organization_id_idx ON scim_tokens (organization_id);
+
+CREATE TABLE IF NOT EXISTS scim_users (
+    organization_id TEXT                     NOT NULL,
+    user_id         TEXT                     NOT NULL,
+    external_id     TEXT,
+    active          BOOLEAN                  NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE organizations
+    DROP COLUMN IF EXISTS require_two_factor;
+
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+DROP TABLE IF EXISTS two_factor;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS two_factor (
+    user_id        TEXT PRIMARY KEY,
+    secret         TEXT                     NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    confirmed_at   TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT                   NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT                     NOT NULL,
+    hash       BYTEA                    NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS two_factor_recovery_codes_user_id_idx ON two_factor_recovery_codes (user_id);
+
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS require_two_factor BOOLEAN NOT NULL DEFAULT FALSE;
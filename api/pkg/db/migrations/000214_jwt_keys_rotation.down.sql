@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS jwt_keys_expires_at_idx;
+
+ALTER TABLE jwt_keys
+    DROP COLUMN expires_at,
+    DROP COLUMN revoked_at;
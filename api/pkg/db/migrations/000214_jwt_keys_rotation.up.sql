@@ -0,0 +1,11 @@
+ALTER TABLE jwt_keys
+    ADD COLUMN expires_at TIMESTAMP,
+    ADD COLUMN revoked_at TIMESTAMP;
+
+-- Existing keys were used to sign tokens that are valid for up to a month.
+UPDATE jwt_keys SET expires_at = NOW() + INTERVAL '31 days';
+
+ALTER TABLE jwt_keys
+    ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS jwt_keys_expires_at_idx ON jwt_keys (expires_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_digest_preferences;
+
+ALTER TABLE notifications
+    DROP COLUMN seen_at;
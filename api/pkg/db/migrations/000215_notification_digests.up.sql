@@ -0,0 +1,8 @@
+ALTER TABLE notifications
+    ADD COLUMN seen_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS notification_digest_preferences (
+    user_id      TEXT PRIMARY KEY,
+    frequency    TEXT NOT NULL,
+    last_sent_at TIMESTAMP WITH TIME ZONE
+);
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id              TEXT PRIMARY KEY,
+    codebase_id     TEXT,
+    organization_id TEXT,
+    url             TEXT                     NOT NULL,
+    secret          TEXT                     NOT NULL,
+    events          TEXT[]                   NOT NULL,
+    created_by      TEXT                     NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    deleted_at      TIMESTAMP WITH TIME ZONE,
+    CHECK ((codebase_id IS NULL) != (organization_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_codebase_id_idx ON webhooks (codebase_id);
+CREATE INDEX IF NOT EXISTS webhooks_organization_id_idx ON webhooks (organization_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id          TEXT PRIMARY KEY,
+    webhook_id  TEXT                     NOT NULL,
+    event_id    TEXT                     NOT NULL,
+    event_type  TEXT                     NOT NULL,
+    payload     BYTEA                    NOT NULL,
+    attempt     INTEGER                  NOT NULL,
+    status_code INTEGER,
+    response    TEXT,
+    error       TEXT,
+    duration_ms INTEGER                  NOT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_created_at_idx ON webhook_deliveries (webhook_id, created_at);
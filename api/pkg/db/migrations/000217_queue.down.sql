@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS queue_broadcast_subscriptions;
+DROP TABLE IF EXISTS queue_dead_letters;
+DROP TABLE IF EXISTS queue_messages;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS queue_messages (
+    id         BIGSERIAL PRIMARY KEY,
+    queue      TEXT                     NOT NULL,
+    payload    BYTEA                    NOT NULL,
+    attempts   INTEGER                  NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    visible_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS queue_messages_queue_visible_at_idx ON queue_messages (queue, visible_at);
+
+CREATE TABLE IF NOT EXISTS queue_dead_letters (
+    id         BIGINT PRIMARY KEY,
+    queue      TEXT                     NOT NULL,
+    payload    BYTEA                    NOT NULL,
+    attempts   INTEGER                  NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    dead_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS queue_dead_letters_queue_idx ON queue_dead_letters (queue);
+
+CREATE TABLE IF NOT EXISTS queue_broadcast_subscriptions (
+    topic   TEXT                     NOT NULL,
+    queue   TEXT                     NOT NULL,
+    seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (topic, queue)
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS events_fanout_payloads (
+    id         BIGSERIAL PRIMARY KEY,
+    payload    BYTEA                    NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS codebases_garbage_collection_policies;
+DROP TABLE IF EXISTS codebases_garbage_collection_run_items;
+
+CREATE TABLE IF NOT EXISTS codebases_garbage_collection_status
+(
+    codebase_id     TEXT                     NOT NULL,
+    completed_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_millis INT                      NOT NULL
+);
+
+INSERT INTO codebases_garbage_collection_status (codebase_id, completed_at, duration_millis)
+SELECT codebase_id, completed_at, duration_millis
+FROM codebases_garbage_collection_runs
+WHERE NOT dry_run
+  AND completed_at IS NOT NULL;
+
+DROP TABLE IF EXISTS codebases_garbage_collection_runs;
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS codebases_garbage_collection_runs (
+    id              TEXT PRIMARY KEY,
+    codebase_id     TEXT                     NOT NULL,
+    dry_run         BOOLEAN                  NOT NULL,
+    triggered_by    TEXT,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    started_at      TIMESTAMP WITH TIME ZONE,
+    completed_at    TIMESTAMP WITH TIME ZONE,
+    duration_millis BIGINT                   NOT NULL DEFAULT 0,
+    bytes_reclaimed BIGINT                   NOT NULL DEFAULT 0,
+    errors          TEXT[]                   NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS codebases_garbage_collection_runs_codebase_id_created_at_idx
+    ON codebases_garbage_collection_runs (codebase_id, created_at);
+
+-- completed runs were only recorded with their duration
+INSERT INTO codebases_garbage_collection_runs (id, codebase_id, dry_run, created_at, started_at, completed_at, duration_millis)
+SELECT md5(random()::text || clock_timestamp()::text)::uuid,
+       codebase_id,
+       FALSE,
+       completed_at - duration_millis * INTERVAL '1 millisecond',
+       completed_at - duration_millis * INTERVAL '1 millisecond',
+       completed_at,
+       duration_millis
+FROM codebases_garbage_collection_status;
+
+DROP TABLE IF EXISTS codebases_garbage_collection_status;
+
+CREATE TABLE IF NOT EXISTS codebases_garbage_collection_run_items (
+    run_id  TEXT NOT NULL,
+    type    TEXT NOT NULL,
+    name    TEXT NOT NULL,
+    view_id TEXT
+);
+
+CREATE INDEX IF NOT EXISTS codebases_garbage_collection_run_items_run_id_idx
+    ON codebases_garbage_collection_run_items (run_id);
+
+CREATE TABLE IF NOT EXISTS codebases_garbage_collection_policies (
+    codebase_id        TEXT PRIMARY KEY,
+    -- durations are stored in nanoseconds
+    run_interval       BIGINT                   NOT NULL,
+    snapshot_retention BIGINT                   NOT NULL,
+    updated_by         TEXT                     NOT NULL,
+    updated_at         TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,12 @@
+DROP INDEX remotes_codebase_id_pull_idx;
+DROP INDEX remotes_codebase_id_name_idx;
+
+-- only one remote per codebase is supported
+DELETE FROM remotes
+WHERE direction != 'pull';
+
+ALTER TABLE remotes
+    DROP COLUMN last_push_error,
+    DROP COLUMN last_pushed_at,
+    DROP COLUMN direction,
+    ALTER COLUMN name DROP NOT NULL;
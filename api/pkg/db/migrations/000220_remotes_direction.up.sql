@@ -0,0 +1,16 @@
+UPDATE remotes
+SET name = 'origin'
+WHERE name IS NULL;
+
+ALTER TABLE remotes
+    ALTER COLUMN name SET NOT NULL,
+    ADD COLUMN direction       TEXT NOT NULL DEFAULT 'pull',
+    ADD COLUMN last_pushed_at  TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN last_push_error TEXT;
+
+CREATE UNIQUE INDEX remotes_codebase_id_name_idx
+    ON remotes (codebase_id, name);
+
+-- trunk can only be pulled from one remote
+CREATE UNIQUE INDEX remotes_codebase_id_pull_idx
+    ON remotes (codebase_id) WHERE direction = 'pull';
@@ -0,0 +1,8 @@
+DROP INDEX remotes_next_poll_at_idx;
+
+ALTER TABLE remotes
+    DROP COLUMN poll_failures,
+    DROP COLUMN last_poll_error,
+    DROP COLUMN last_polled_at,
+    DROP COLUMN next_poll_at,
+    DROP COLUMN poll_interval;
@@ -0,0 +1,10 @@
+ALTER TABLE remotes
+    -- nanoseconds, polling is disabled if not set
+    ADD COLUMN poll_interval   BIGINT,
+    ADD COLUMN next_poll_at    TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN last_polled_at  TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN last_poll_error TEXT,
+    ADD COLUMN poll_failures   INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX remotes_next_poll_at_idx
+    ON remotes (next_poll_at) WHERE poll_interval IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE remotes
+    DROP COLUMN pending_known_host,
+    DROP COLUMN known_host;
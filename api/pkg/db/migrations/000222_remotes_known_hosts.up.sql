@@ -0,0 +1,3 @@
+ALTER TABLE remotes
+    ADD COLUMN known_host         TEXT,
+    ADD COLUMN pending_known_host TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS gitlab_merge_requests;
+DROP TABLE IF EXISTS gitlab_integrations;
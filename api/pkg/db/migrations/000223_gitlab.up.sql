@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS gitlab_integrations (
+    id               TEXT PRIMARY KEY,
+    codebase_id      TEXT                     NOT NULL,
+    base_url         TEXT                     NOT NULL,
+    project_id       BIGINT                   NOT NULL,
+    project_path     TEXT                     NOT NULL,
+    web_url          TEXT                     NOT NULL,
+    http_url_to_repo TEXT                     NOT NULL,
+    access_token     TEXT                     NOT NULL,
+    webhook_secret   TEXT                     NOT NULL,
+    tracked_branch   TEXT                     NOT NULL,
+    enabled          BOOLEAN                  NOT NULL,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    synced_at        TIMESTAMP WITH TIME ZONE,
+    last_push_at     TIMESTAMP WITH TIME ZONE,
+    last_push_error  TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS gitlab_integrations_codebase_id_idx ON gitlab_integrations (codebase_id);
+
+CREATE TABLE IF NOT EXISTS gitlab_merge_requests (
+    id             TEXT PRIMARY KEY,
+    integration_id TEXT                     NOT NULL,
+    codebase_id    TEXT                     NOT NULL,
+    workspace_id   TEXT                     NOT NULL,
+    gitlab_id      BIGINT                   NOT NULL,
+    iid            BIGINT                   NOT NULL,
+    source_branch  TEXT                     NOT NULL,
+    target_branch  TEXT                     NOT NULL,
+    head_sha       TEXT,
+    state          TEXT                     NOT NULL,
+    importing      BOOLEAN                  NOT NULL,
+    created_by     TEXT                     NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at     TIMESTAMP WITH TIME ZONE,
+    closed_at      TIMESTAMP WITH TIME ZONE,
+    merged_at      TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS gitlab_merge_requests_integration_id_iid_idx ON gitlab_merge_requests (integration_id, iid);
+CREATE INDEX IF NOT EXISTS gitlab_merge_requests_workspace_id_idx ON gitlab_merge_requests (workspace_id);
+CREATE INDEX IF NOT EXISTS gitlab_merge_requests_codebase_id_head_sha_idx ON gitlab_merge_requests (codebase_id, head_sha);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS remote_branches (
+    id           TEXT PRIMARY KEY,
+    remote_id    TEXT                     NOT NULL,
+    codebase_id  TEXT                     NOT NULL,
+    workspace_id TEXT                     NOT NULL,
+    name         TEXT                     NOT NULL,
+    head_sha     TEXT                     NOT NULL,
+    linked       BOOLEAN                  NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS remote_branches_workspace_id_idx ON remote_branches (workspace_id);
+CREATE INDEX IF NOT EXISTS remote_branches_remote_id_name_idx ON remote_branches (remote_id, name);
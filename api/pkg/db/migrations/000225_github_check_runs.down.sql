@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS github_check_runs;
+
+ALTER TABLE statuses
+    DROP COLUMN IF EXISTS source;
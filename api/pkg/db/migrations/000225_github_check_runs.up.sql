@@ -0,0 +1,18 @@
+ALTER TABLE statuses
+    ADD COLUMN source TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS github_check_runs (
+    id                   TEXT PRIMARY KEY,
+    codebase_id          TEXT                     NOT NULL,
+    github_repository_id BIGINT                   NOT NULL,
+    github_check_run_id  BIGINT                   NOT NULL,
+    head_sha             TEXT                     NOT NULL,
+    name                 TEXT                     NOT NULL,
+    status_id            TEXT                     NOT NULL,
+    status_timestamp     TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at           TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at           TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS github_check_runs_codebase_id_head_sha_name_idx
+    ON github_check_runs (codebase_id, head_sha, name);
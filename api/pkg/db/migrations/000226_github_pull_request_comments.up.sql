@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS github_pull_request_comments (
+    id                TEXT PRIMARY KEY,
+    codebase_id       TEXT                     NOT NULL,
+    pull_request_id   TEXT                     NOT NULL,
+    -- null while the comment is being published to GitHub
+    github_comment_id BIGINT,
+    comment_id        TEXT                     NOT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS github_pull_request_comments_codebase_id_github_comment_id_idx
+    ON github_pull_request_comments (codebase_id, github_comment_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS github_pull_request_comments_comment_id_idx
+    ON github_pull_request_comments (comment_id);
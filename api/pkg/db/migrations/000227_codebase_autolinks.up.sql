@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS codebase_autolinks (
+    id           TEXT PRIMARY KEY,
+    codebase_id  TEXT                     NOT NULL,
+    prefix       TEXT                     NOT NULL,
+    url_template TEXT                     NOT NULL,
+    created_by   TEXT                     NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    deleted_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS codebase_autolinks_codebase_id_idx ON codebase_autolinks (codebase_id);
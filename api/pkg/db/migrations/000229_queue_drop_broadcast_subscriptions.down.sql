@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS queue_broadcast_subscriptions (
+    topic   TEXT                     NOT NULL,
+    queue   TEXT                     NOT NULL,
+    seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (topic, queue)
+);
@@ -0,0 +1 @@
+-- git:write can not be restored, it was never enforced
@@ -0,0 +1,4 @@
+-- service tokens can't be used to push, the scope was never enforced
+UPDATE servicetokens
+SET scopes = array_remove(scopes, 'git:write')
+WHERE 'git:write' = ANY (scopes);
@@ -0,0 +1,10 @@
+-- organization memberships that were created by single sign-on, only those are removed when the user loses
+-- access to the organization in the identity provider
+CREATE TABLE IF NOT EXISTS oidc_organization_members (
+    organization_id TEXT                     NOT NULL,
+    user_id         TEXT                     NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS oidc_organization_members_user_id_idx ON oidc_organization_members (user_id);
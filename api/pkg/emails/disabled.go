@@ -13,3 +13,9 @@ func NewDisabled() Sender {
 func (*disabledClient) Send(context.Context, *Email) error {
 	return nil
 }
+
+// IsDisabled returns true if the sender does not send any emails.
+func IsDisabled(sender Sender) bool {
+	_, disabled := sender.(*disabledClient)
+	return disabled
+}
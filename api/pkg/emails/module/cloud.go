@@ -6,8 +6,15 @@ package module
 import (
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/emails/enterprise/cloud"
+	"getsturdy.com/api/pkg/emails/worker"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/zap"
 )
 
 func Module(c *di.Container) {
-	c.Register(cloud.New)
+	c.Register(func(cfg *cloud.Configuration, awsSession *session.Session, logger *zap.Logger) worker.Transport {
+		return cloud.New(cfg, awsSession, logger)
+	})
+	c.Import(worker.Module)
 }
@@ -6,8 +6,16 @@ package module
 import (
 	"getsturdy.com/api/pkg/di"
 	"getsturdy.com/api/pkg/emails"
+	"getsturdy.com/api/pkg/emails/smtp"
+	"getsturdy.com/api/pkg/emails/worker"
 )
 
 func Module(c *di.Container) {
-	c.Register(emails.NewDisabled)
+	c.Register(func(cfg *smtp.Configuration) worker.Transport {
+		if !cfg.Enabled() {
+			return emails.NewDisabled()
+		}
+		return smtp.New(cfg)
+	})
+	c.Import(worker.Module)
 }
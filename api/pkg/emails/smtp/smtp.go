@@ -0,0 +1,196 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/emails"
+
+	"github.com/google/uuid"
+)
+
+type Security string
+
+const (
+	// SecuritySTARTTLS upgrades a plain text connection to TLS, sending fails if the server doesn't support it.
+	SecuritySTARTTLS Security = "starttls"
+	// SecurityTLS connects using TLS, usually on port 465.
+	SecurityTLS Security = "tls"
+	// SecurityNone sends emails in plain text. Only meant to be used with local relays.
+	SecurityNone Security = "none"
+)
+
+const defaultTimeout = 30 * time.Second
+
+type Configuration struct {
+	Host               string   `long:"host" description:"SMTP server hostname, emails are not sent if not set" env:"HOST"`
+	Port               int      `long:"port" description:"SMTP server port" default:"587" env:"PORT"`
+	Security           Security `long:"security" description:"How to secure the connection to the SMTP server" choice:"starttls" choice:"tls" choice:"none" default:"starttls" env:"SECURITY"`
+	InsecureSkipVerify bool     `long:"insecure-skip-verify" description:"Do not verify the certificate of the SMTP server" env:"INSECURE_SKIP_VERIFY"`
+	Username           string   `long:"username" description:"Username to authenticate with, emails are sent without authentication if not set" env:"USERNAME"`
+	Password           string   `long:"password" description:"Password to authenticate with" env:"PASSWORD"`
+	From               string   `long:"from" description:"Address to send emails from, for example 'Sturdy <sturdy@example.com>'" env:"FROM"`
+}
+
+func (c *Configuration) Enabled() bool {
+	return c != nil && c.Host != ""
+}
+
+var _ emails.Sender = &Client{}
+
+// Client sends emails through an SMTP server. Failed sends are not retried.
+type Client struct {
+	cfg *Configuration
+}
+
+func New(cfg *Configuration) *Client {
+	return &Client{
+		cfg: cfg,
+	}
+}
+
+func (c *Client) Send(ctx context.Context, msg *emails.Email) error {
+	from, err := mail.ParseAddress(c.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+
+	message, err := buildMessage(from, to, msg)
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.cfg.Host, err)
+	}
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	if c.cfg.Security == SecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS", c.cfg.Host)
+		}
+		if err := client.StartTLS(c.tlsConfig()); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start data: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (c *Client) tlsConfig() *tls.Config {
+	return &tls.Config{
+		ServerName:         c.cfg.Host,
+		InsecureSkipVerify: c.cfg.InsecureSkipVerify, //nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	dialer := &net.Dialer{}
+
+	var conn net.Conn
+	var err error
+	if c.cfg.Security == SecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: c.tlsConfig()}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the whole conversation must fit within the deadline
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func buildMessage(from, to *mail.Address, msg *emails.Email) ([]byte, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	var buf bytes.Buffer
+	headers := []struct{ key, value string }{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), domain)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `text/html; charset="UTF-8"`},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.key, h.value)
+	}
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(msg.Html)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,120 @@
+package smtp_test
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"getsturdy.com/api/pkg/emails"
+	"getsturdy.com/api/pkg/emails/smtp"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serve accepts a single connection and records the envelope and the message that it receives.
+func serve(t *testing.T, l net.Listener, commands chan<- string, data chan<- string) {
+	conn, err := l.Accept()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO":
+			_ = tp.PrintfLine("250 localhost")
+		case "MAIL", "RCPT":
+			commands <- line
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			lines, err := tp.ReadDotLines()
+			if !assert.NoError(t, err) {
+				return
+			}
+			data <- strings.Join(lines, "\n")
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	commands := make(chan string, 2)
+	data := make(chan string, 1)
+	go serve(t, l, commands, data)
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	client := smtp.New(&smtp.Configuration{
+		Host:     host,
+		Port:     portNumber,
+		Security: smtp.SecurityNone,
+		From:     "Sturdy <sturdy@example.com>",
+	})
+
+	err = client.Send(context.Background(), &emails.Email{
+		To:      "alice@example.com",
+		Subject: "Hello wörld",
+		Html:    "<p>Hi!</p>",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "MAIL FROM:<sturdy@example.com>", <-commands)
+	assert.Equal(t, "RCPT TO:<alice@example.com>", <-commands)
+
+	message := <-data
+	assert.Contains(t, message, `From: "Sturdy" <sturdy@example.com>`)
+	assert.Contains(t, message, "To: <alice@example.com>")
+	assert.Contains(t, message, "Subject: =?utf-8?q?Hello_w=C3=B6rld?=")
+	assert.Contains(t, message, `Content-Type: text/html; charset="UTF-8"`)
+	assert.Contains(t, message, "<p>Hi!</p>")
+}
+
+func TestSend_shouldRequireStartTLS(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	go serve(t, l, make(chan string, 2), make(chan string, 1))
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	client := smtp.New(&smtp.Configuration{
+		Host:     host,
+		Port:     portNumber,
+		Security: smtp.SecuritySTARTTLS,
+		From:     "sturdy@example.com",
+	})
+
+	err = client.Send(context.Background(), &emails.Email{To: "alice@example.com"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not support STARTTLS")
+	}
+}
@@ -23,6 +23,8 @@ import (
 	db_newsletter "getsturdy.com/api/pkg/newsletter/db"
 	"getsturdy.com/api/pkg/notification"
 	service_notification "getsturdy.com/api/pkg/notification/service"
+	"getsturdy.com/api/pkg/organization"
+	"getsturdy.com/api/pkg/review"
 	db_review "getsturdy.com/api/pkg/review/db"
 	"getsturdy.com/api/pkg/suggestions"
 	db_suggestion "getsturdy.com/api/pkg/suggestions/db"
@@ -36,9 +38,11 @@ import (
 type EmailSender interface {
 	SendWelcome(context.Context, *users.User) error
 	SendNotification(context.Context, *users.User, *notification.Notification) error
+	SendNotificationDigest(context.Context, *users.User, []notification.Notification) error
 	SendConfirmEmail(context.Context, *users.User) error
 	SendMagicLink(context.Context, *users.User, string) error
 	SendInviteToNewUser(ctx context.Context, invitingUser *users.User, invitedUser *users.User, codebase *codebases.Codebase) error
+	SendTwoFactorReset(context.Context, *users.User, *organization.Organization) error
 }
 
 var ErrNotSupported = errors.New("notification type not supported")
@@ -136,32 +140,58 @@ func (e *Sender) SendInviteToNewUser(ctx context.Context, invitingUser *users.Us
 	})
 }
 
-func (e *Sender) shouldSendNotification(ctx context.Context, usr *users.User, notificationType notification.NotificationType) (bool, error) {
+// emailEnabled returns the notification types that the user wants to receive via email.
+// SendTwoFactorReset lets the user know that an administrator of the organization has turned off two-factor
+// authentication for them.
+func (e *Sender) SendTwoFactorReset(ctx context.Context, user *users.User, org *organization.Organization) error {
+	title := "[Sturdy] Two-factor authentication has been turned off"
+	return e.Send(ctx, user, title, templates.TwoFactorResetTemplate, &templates.TwoFactorResetTemplateData{
+		User:         user,
+		Organization: org,
+	})
+}
+
+func (e *Sender) emailEnabled(ctx context.Context, usr *users.User) (map[notification.NotificationType]bool, error) {
 	shouldSendEmail, err := shouldSendEmail(e.notificationSettingsRepository, usr)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
+	enabled := map[notification.NotificationType]bool{}
 	if !shouldSendEmail {
-		return false, nil
+		return enabled, nil
 	}
 
 	pp, err := e.notificationPreferences.ListByUserID(ctx, usr.ID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	for _, preference := range pp {
 		if preference.Channel != notification.ChannelEmail {
 			continue
 		}
-		if preference.Type != notificationType {
-			continue
-		}
-		return preference.Enabled, nil
+		enabled[preference.Type] = preference.Enabled
+	}
+	return enabled, nil
+}
+
+func (e *Sender) shouldSendNotification(ctx context.Context, usr *users.User, notificationType notification.NotificationType) (bool, error) {
+	enabled, err := e.emailEnabled(ctx, usr)
+	if err != nil {
+		return false, err
 	}
 
-	return false, fmt.Errorf("notification preference for %s not found", notificationType)
+	if !enabled[notificationType] {
+		return false, nil
+	}
+
+	// users with a digest get their notifications batched, see SendNotificationDigest
+	digest, err := e.notificationPreferences.Digest(ctx, usr.ID)
+	if err != nil {
+		return false, err
+	}
+	return digest.Frequency == notification.DigestFrequencyImmediate, nil
 }
 
 func (e *Sender) SendNotification(ctx context.Context, usr *users.User, notif *notification.Notification) error {
@@ -200,6 +230,168 @@ func (e *Sender) SendNotification(ctx context.Context, usr *users.User, notif *n
 	}
 }
 
+// SendNotificationDigest sends a single email that summarizes all notifications, grouped by codebase
+// and by the workspace or change that they are about. Notifications of types that the user doesn't want
+// to receive via email are skipped, as well as notifications about deleted objects.
+func (e *Sender) SendNotificationDigest(ctx context.Context, usr *users.User, notifs []notification.Notification) error {
+	enabled, err := e.emailEnabled(ctx, usr)
+	if err != nil {
+		return err
+	}
+
+	data := &templates.NotificationDigestTemplateData{User: usr}
+	for i := range notifs {
+		if !enabled[notifs[i].NotificationType] {
+			continue
+		}
+		err := e.addToDigest(ctx, data, &notifs[i])
+		switch {
+		case err == nil:
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, ErrNotSupported):
+			continue
+		default:
+			return fmt.Errorf("failed to add notification %s to digest: %w", notifs[i].ID, err)
+		}
+	}
+
+	switch n := data.Len(); n {
+	case 0:
+		return nil
+	case 1:
+		return e.Send(ctx, usr, "[Sturdy] You have 1 new notification", templates.NotificationDigestTemplate, data)
+	default:
+		title := fmt.Sprintf("[Sturdy] You have %d new notifications", n)
+		return e.Send(ctx, usr, title, templates.NotificationDigestTemplate, data)
+	}
+}
+
+func (e *Sender) addToDigest(ctx context.Context, data *templates.NotificationDigestTemplateData, notif *notification.Notification) error {
+	switch notif.NotificationType {
+	case notification.CommentNotificationType:
+		return e.addCommentToDigest(ctx, data, comments.ID(notif.ReferenceID))
+	case notification.NewSuggestionNotificationType:
+		s, err := e.suggestionRepo.GetByID(ctx, suggestions.ID(notif.ReferenceID))
+		if err != nil {
+			return fmt.Errorf("failed to find suggestion: %w", err)
+		}
+		author, err := e.userRepo.Get(s.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return e.addToWorkspaceDigest(data, s.ForWorkspaceID, fmt.Sprintf("%s made a suggestion", author.Name))
+	case notification.RequestedReviewNotificationType:
+		r, err := e.reviewRepo.Get(ctx, notif.ReferenceID)
+		if err != nil {
+			return fmt.Errorf("failed to find review: %w", err)
+		}
+		requestedBy, err := e.userRepo.Get(*r.RequestedBy)
+		if err != nil {
+			return fmt.Errorf("failed to find author: %w", err)
+		}
+		return e.addToWorkspaceDigest(data, r.WorkspaceID, fmt.Sprintf("%s asked for your feedback", requestedBy.Name))
+	case notification.ReviewNotificationType:
+		r, err := e.reviewRepo.Get(ctx, notif.ReferenceID)
+		if err != nil {
+			return fmt.Errorf("failed to find review: %w", err)
+		}
+		author, err := e.userRepo.Get(r.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to find author: %w", err)
+		}
+		switch r.Grade {
+		case review.ReviewGradeApprove:
+			return e.addToWorkspaceDigest(data, r.WorkspaceID, fmt.Sprintf("%s approved", author.Name))
+		case review.ReviewGradeReject:
+			return e.addToWorkspaceDigest(data, r.WorkspaceID, fmt.Sprintf("%s has some feedback", author.Name))
+		default:
+			return e.addToWorkspaceDigest(data, r.WorkspaceID, fmt.Sprintf("%s reviewed", author.Name))
+		}
+	case notification.GitHubRepositoryImported:
+		c, err := e.codebaseRepo.Get(notif.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("failed to find codebase: %w", err)
+		}
+		data.Add(c, "", "", fmt.Sprintf("%s has been imported from GitHub", c.Name))
+		return nil
+	default:
+		return ErrNotSupported
+	}
+}
+
+func (e *Sender) addToWorkspaceDigest(data *templates.NotificationDigestTemplateData, workspaceID, item string) error {
+	w, err := e.workspaceRepo.Get(workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to find workspace: %w", err)
+	}
+	c, err := e.codebaseRepo.Get(w.CodebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to find codebase: %w", err)
+	}
+	data.Add(c, w.NameOrFallback(), "/"+w.ID, item)
+	return nil
+}
+
+func (e *Sender) addCommentToDigest(ctx context.Context, data *templates.NotificationDigestTemplateData, commentID comments.ID) error {
+	comment, err := e.commentsRepo.Get(commentID)
+	if err != nil {
+		return fmt.Errorf("failed to find comment: %w", err)
+	}
+	author, err := e.userRepo.Get(comment.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	codebaseUsers, err := e.getUsersByCodebaseID(ctx, comment.CodebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+	mentions := decorate_comments.ExtractIDMentions(comment.Message, codebaseUsers)
+	// replace all mentions with names
+	for mention, user := range mentions {
+		comment.Message = strings.ReplaceAll(comment.Message, mention, fmt.Sprintf("@%s", user.Name))
+	}
+
+	item := fmt.Sprintf("%s commented: %s", author.Name, comment.Message)
+	if comment.ParentComment != nil {
+		item = fmt.Sprintf("%s replied: %s", author.Name, comment.Message)
+	}
+
+	// replies are grouped with the comment they are replying to
+	parent := comment
+	if comment.ParentComment != nil {
+		if parent, err = e.commentsRepo.Get(*comment.ParentComment); err != nil {
+			return fmt.Errorf("failed to get parent comment: %w", err)
+		}
+	}
+
+	switch {
+	case parent.ChangeID != nil:
+		change, err := e.changeService.GetChangeByID(ctx, *parent.ChangeID)
+		if err != nil {
+			return fmt.Errorf("failed to get change: %w", err)
+		}
+		c, err := e.codebaseRepo.Get(comment.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get codebase: %w", err)
+		}
+		title := string(change.ID)
+		if change.Title != nil {
+			title = *change.Title
+		}
+		data.Add(c, title, "/"+string(change.ID), item)
+		return nil
+	case parent.WorkspaceID != nil:
+		return e.addToWorkspaceDigest(data, *parent.WorkspaceID, item)
+	default:
+		c, err := e.codebaseRepo.Get(comment.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get codebase: %w", err)
+		}
+		data.Add(c, "", "", item)
+		return nil
+	}
+}
+
 func (e *Sender) sendReviewNotification(ctx context.Context, usr *users.User, reviewID string) error {
 	r, err := e.reviewRepo.Get(ctx, reviewID)
 	if err != nil {
@@ -9,10 +9,12 @@ yarn install
 
 yarn run mjml "${CWD}/welcome.template.mjml" -o "${CWD}/output/welcome.template.html"
 yarn run mjml "${CWD}/notification/github_repository_imported.template.mjml" -o "${CWD}/output/notification/github_repository_imported.template.html"
+yarn run mjml "${CWD}/notification/digest.template.mjml" -o "${CWD}/output/notification/digest.template.html"
 yarn run mjml "${CWD}/notification/comment.template.mjml" -o "${CWD}/output/notification/comment.template.html"
 yarn run mjml "${CWD}/notification/new_suggestion.template.mjml" -o "${CWD}/output/notification/new_suggestion.template.html"
 yarn run mjml "${CWD}/notification/requested_review.template.mjml" -o "${CWD}/output/notification/requested_review.template.html"
 yarn run mjml "${CWD}/notification/review.template.mjml" -o "${CWD}/output/notification/review.template.html"
 yarn run mjml "${CWD}/verify_email.template.mjml" -o "${CWD}/output/verify_email.template.html"
 yarn run mjml "${CWD}/magic_link.template.mjml" -o "${CWD}/output/magic_link.template.html"
-yarn run mjml "${CWD}/invite_new_user.template.mjml" -o "${CWD}/output/invite_new_user.template.html"
\ No newline at end of file
+yarn run mjml "${CWD}/invite_new_user.template.mjml" -o "${CWD}/output/invite_new_user.template.html"
+yarn run mjml "${CWD}/two_factor_reset.template.mjml" -o "${CWD}/output/two_factor_reset.template.html"
\ No newline at end of file
@@ -12,6 +12,7 @@ import (
 	"getsturdy.com/api/pkg/comments"
 	"getsturdy.com/api/pkg/github"
 	"getsturdy.com/api/pkg/jwt"
+	"getsturdy.com/api/pkg/organization"
 	"getsturdy.com/api/pkg/review"
 	"getsturdy.com/api/pkg/users"
 	"getsturdy.com/api/pkg/workspaces"
@@ -45,12 +46,14 @@ const (
 	WelcomeTemplate                              Template = "welcome.template.html"
 	NotificationGitHubRepositoryImportedTemplate Template = "github_repository_imported.template.html"
 	NotificationCommentTemplate                  Template = "comment.template.html"
+	NotificationDigestTemplate                   Template = "digest.template.html"
 	NotificationNewSuggestionTemplate            Template = "new_suggestion.template.html"
 	NotificationRequestedReviewTemplate          Template = "requested_review.template.html"
 	NotificationReviewTemplate                   Template = "review.template.html"
 	VerifyEmailTemplate                          Template = "verify_email.template.html"
 	MagicLinkTemplate                            Template = "magic_link.template.html"
 	InviteNewUserTemplate                        Template = "invite_new_user.template.html"
+	TwoFactorResetTemplate                       Template = "two_factor_reset.template.html"
 )
 
 type WelcomeTemplateData struct {
@@ -100,6 +103,72 @@ type NotificationReviewTemplateData struct {
 	Codebase  *codebases.Codebase
 }
 
+// NotificationDigestTemplateData summarizes multiple notifications, grouped by codebase and by the
+// workspace or change that they are about.
+type NotificationDigestTemplateData struct {
+	User *users.User
+
+	Codebases []*NotificationDigestCodebase
+}
+
+type NotificationDigestCodebase struct {
+	Codebase *codebases.Codebase
+	Groups   []*NotificationDigestGroup
+}
+
+type NotificationDigestGroup struct {
+	// Title is the name of the workspace or change, empty if the notifications are about the codebase itself.
+	Title string
+	// Path is the location of the workspace or change, relative to the codebase.
+	Path  string
+	Items []string
+}
+
+// Add adds an item to the digest. Codebases and groups are kept in the order they were first added in.
+func (d *NotificationDigestTemplateData) Add(codebase *codebases.Codebase, title, path, item string) {
+	var c *NotificationDigestCodebase
+	for _, existing := range d.Codebases {
+		if existing.Codebase.ID == codebase.ID {
+			c = existing
+			break
+		}
+	}
+	if c == nil {
+		c = &NotificationDigestCodebase{Codebase: codebase}
+		d.Codebases = append(d.Codebases, c)
+	}
+
+	var g *NotificationDigestGroup
+	for _, existing := range c.Groups {
+		if existing.Path == path {
+			g = existing
+			break
+		}
+	}
+	if g == nil {
+		g = &NotificationDigestGroup{Title: title, Path: path}
+		c.Groups = append(c.Groups, g)
+	}
+
+	g.Items = append(g.Items, item)
+}
+
+// Len returns the number of items in the digest.
+func (d *NotificationDigestTemplateData) Len() int {
+	n := 0
+	for _, c := range d.Codebases {
+		for _, g := range c.Groups {
+			n += len(g.Items)
+		}
+	}
+	return n
+}
+
+type TwoFactorResetTemplateData struct {
+	User         *users.User
+	Organization *organization.Organization
+}
+
 type MagicLinkTemplateData struct {
 	User *users.User
 	Code string
@@ -9,6 +9,7 @@ import (
 	"getsturdy.com/api/pkg/comments"
 	"getsturdy.com/api/pkg/github"
 	"getsturdy.com/api/pkg/jwt"
+	"getsturdy.com/api/pkg/organization"
 	"getsturdy.com/api/pkg/review"
 	"getsturdy.com/api/pkg/users"
 	"getsturdy.com/api/pkg/workspaces"
@@ -504,6 +505,57 @@ func TestRenderVerifyEmail(t *testing.T) {
 	assert.Equal(t, mustReadFile(t, "testdata/verify_email.html"), output)
 }
 
+func TestRenderTwoFactorReset(t *testing.T) {
+	usr := &users.User{
+		Name:  "me",
+		ID:    "0",
+		Email: "me@test.com",
+	}
+
+	output, err := Render(TwoFactorResetTemplate, TwoFactorResetTemplateData{
+		User:         usr,
+		Organization: &organization.Organization{Name: "Acme"},
+	})
+
+	// uncomment to make a snapshot
+	// os.WriteFile("testdata/two_factor_reset.html", []byte(output), 0666)
+
+	assert.NoError(t, err)
+	assert.Equal(t, mustReadFile(t, "testdata/two_factor_reset.html"), output)
+}
+
+func TestRenderNotificationDigest(t *testing.T) {
+	usr := &users.User{
+		Name:  "me",
+		ID:    "0",
+		Email: "me@test.com",
+	}
+	codebase := &codebases.Codebase{
+		ID:              "codebase-id",
+		ShortCodebaseID: "short-id",
+		Name:            "codebase",
+	}
+
+	data := &NotificationDigestTemplateData{User: usr}
+	data.Add(codebase, "Workspace", "/workspace-id", "User One commented: This is my comment message")
+	data.Add(codebase, "change", "/change-id", "User Two commented: Looks good")
+	data.Add(codebase, "Workspace", "/workspace-id", "User Two approved")
+	data.Add(codebase, "", "", "codebase has been imported from GitHub")
+
+	assert.Equal(t, 4, data.Len())
+	if assert.Len(t, data.Codebases, 1) {
+		assert.Len(t, data.Codebases[0].Groups, 3)
+	}
+
+	output, err := Render(NotificationDigestTemplate, data)
+
+	// uncomment to make a snapshot
+	// os.WriteFile("testdata/notification/digest.html", []byte(output), 0666)
+
+	assert.NoError(t, err)
+	assert.Equal(t, mustReadFile(t, "testdata/notification/digest.html"), output)
+}
+
 func mustReadFile(t *testing.T, filename string) string {
 	content, err := os.ReadFile(filename)
 	assert.NoError(t, err)
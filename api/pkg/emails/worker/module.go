@@ -0,0 +1,8 @@
+package worker
+
+import "getsturdy.com/api/pkg/di"
+
+func Module(c *di.Container) {
+	c.Register(New)
+	c.Register(NewSender)
+}
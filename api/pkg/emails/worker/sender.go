@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/emails"
+
+	"go.uber.org/zap"
+)
+
+var _ emails.Sender = &sender{}
+
+type sender struct {
+	logger    *zap.Logger
+	transport Transport
+	queue     *Queue
+}
+
+// NewSender returns a sender that sends emails with the transport, and retries failed sends through
+// the queue.
+func NewSender(logger *zap.Logger, transport Transport, queue *Queue) emails.Sender {
+	return &sender{
+		logger:    logger.Named("emailSender"),
+		transport: transport,
+		queue:     queue,
+	}
+}
+
+func (s *sender) Send(ctx context.Context, email *emails.Email) error {
+	err := s.transport.Send(ctx, email)
+	if err == nil {
+		return nil
+	}
+
+	s.logger.Warn("failed to send email, will retry", zap.Error(err))
+
+	if err := s.queue.Enqueue(ctx, email, 1); err != nil {
+		return fmt.Errorf("failed to enqueue email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/emails"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxAttempts is how many times an email is retried before it's dropped.
+	maxAttempts = 5
+	// retryDelay is the delay before the first retry, it doubles with every attempt.
+	retryDelay = 30 * time.Second
+)
+
+// Transport delivers emails, failed deliveries are not retried.
+type Transport interface {
+	emails.Sender
+}
+
+type EmailRetryQueueEntry struct {
+	Email   *emails.Email `json:"email"`
+	Attempt int           `json:"attempt"`
+}
+
+type Queue struct {
+	logger    *zap.Logger
+	queue     queue.Queue
+	name      names.IncompleteQueueName
+	transport Transport
+
+	retryDelay time.Duration
+}
+
+func New(
+	logger *zap.Logger,
+	queue queue.Queue,
+	transport Transport,
+) *Queue {
+	return &Queue{
+		logger:    logger.Named("emailRetryQueue"),
+		queue:     queue,
+		name:      names.EmailRetry,
+		transport: transport,
+
+		retryDelay: retryDelay,
+	}
+}
+
+// Enqueue schedules the email to be sent again, after a delay that doubles with every attempt.
+func (q *Queue) Enqueue(ctx context.Context, email *emails.Email, attempt int) error {
+	if err := q.queue.PublishDelayed(ctx, q.name, &EmailRetryQueueEntry{
+		Email:   email,
+		Attempt: attempt,
+	}, q.retryDelay<<(attempt-1)); err != nil {
+		return fmt.Errorf("could not publish to queue: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) Start(ctx context.Context) error {
+	messages := make(chan queue.Message)
+	go func() {
+		for msg := range messages {
+			m := &EmailRetryQueueEntry{}
+			if err := msg.As(m); err != nil {
+				// the message will never decode, drop it instead of having it redelivered
+				q.logger.Error("failed to decode message", zap.Error(err))
+				if err := msg.Ack(); err != nil {
+					q.logger.Error("failed to ack message", zap.Error(err))
+				}
+				continue
+			}
+
+			// handle in the background to not block the queue on slow sends
+			go q.retry(ctx, msg, m)
+		}
+	}()
+
+	q.logger.Info("starting queue", zap.Stringer("queue_name", q.name))
+	if err := q.queue.Subscribe(ctx, q.name, messages); err != nil {
+		return fmt.Errorf("could not subscribe to queue: %w", err)
+	}
+	q.logger.Info("queue stoped", zap.Stringer("queue_name", q.name))
+
+	return nil
+}
+
+func (q *Queue) retry(ctx context.Context, msg queue.Message, m *EmailRetryQueueEntry) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			q.logger.Error("panic in email retry", zap.String("panic", fmt.Sprintf("%v", rec)))
+		}
+	}()
+
+	logger := q.logger.With(zap.Int("attempt", m.Attempt))
+
+	if err := q.transport.Send(ctx, m.Email); err != nil {
+		if m.Attempt >= maxAttempts {
+			logger.Error("failed to send email, giving up", zap.Error(err))
+		} else if err := q.Enqueue(ctx, m.Email, m.Attempt+1); err != nil {
+			logger.Error("failed to enqueue email", zap.Error(err))
+			return
+		} else {
+			logger.Warn("failed to send email, will retry", zap.Error(err))
+		}
+	}
+
+	if err := msg.Ack(); err != nil {
+		logger.Error("failed to ack message", zap.Error(err))
+	}
+}
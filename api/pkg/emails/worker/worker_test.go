@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/emails"
+	"getsturdy.com/api/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type flakyTransport struct {
+	guard    sync.Mutex
+	failures int
+	attempts int
+	sent     []*emails.Email
+}
+
+func (t *flakyTransport) Send(_ context.Context, email *emails.Email) error {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+	t.attempts++
+	if t.attempts <= t.failures {
+		return errors.New("connection refused")
+	}
+	t.sent = append(t.sent, email)
+	return nil
+}
+
+func (t *flakyTransport) stats() (int, int) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+	return t.attempts, len(t.sent)
+}
+
+func setup(t *testing.T, transport Transport) emails.Sender {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := zap.NewNop()
+	q := New(logger, queue.NewInMemory(logger), transport)
+	q.retryDelay = time.Millisecond
+
+	go func() {
+		assert.NoError(t, q.Start(ctx))
+	}()
+	// wait for the queue to subscribe
+	time.Sleep(10 * time.Millisecond)
+
+	return NewSender(logger, transport, q)
+}
+
+func TestSend_shouldRetryFailedSends(t *testing.T) {
+	transport := &flakyTransport{failures: 3}
+	sender := setup(t, transport)
+
+	assert.NoError(t, sender.Send(context.Background(), &emails.Email{To: "test@getsturdy.com"}))
+
+	assert.Eventually(t, func() bool {
+		_, sent := transport.stats()
+		return sent == 1
+	}, time.Second, time.Millisecond)
+
+	attempts, _ := transport.stats()
+	assert.Equal(t, 4, attempts)
+}
+
+func TestSend_shouldGiveUpAfterMaxAttempts(t *testing.T) {
+	transport := &flakyTransport{failures: 100}
+	sender := setup(t, transport)
+
+	assert.NoError(t, sender.Send(context.Background(), &emails.Email{To: "test@getsturdy.com"}))
+
+	assert.Eventually(t, func() bool {
+		attempts, _ := transport.stats()
+		return attempts == maxAttempts+1
+	}, time.Second, time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	attempts, sent := transport.stats()
+	assert.Equal(t, maxAttempts+1, attempts)
+	assert.Equal(t, 0, sent)
+}
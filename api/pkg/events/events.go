@@ -1,7 +1,10 @@
 package events
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
@@ -9,6 +12,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 
+	"getsturdy.com/api/pkg/events/fanout"
 	"getsturdy.com/api/pkg/users"
 )
 
@@ -75,24 +79,42 @@ var (
 	}, []string{"eventType", "success"})
 )
 
+// fanoutChannel is the channel that events are sent to other instances on.
+const fanoutChannel fanout.Channel = "sturdy_events"
+
 type inMemory struct {
 	mx          sync.RWMutex
 	subscribers map[Topic]map[string]CallbackFunc
 	q           chan payload
+	fanout      fanout.Fanout
 
 	logger *zap.Logger
 }
 
 func NewInMemory(logger *zap.Logger) EventReadWriter {
-	m := &inMemory{
+	m := newInMemory(logger, fanout.NewNoop())
+	go m.work()
+	return m
+}
+
+// New returns an EventReadWriter that also sends events to, and receives events from, the other instances of
+// the API.
+func New(logger *zap.Logger, f fanout.Fanout) (EventReadWriter, error) {
+	m := newInMemory(logger, f)
+	if err := f.Subscribe(context.Background(), fanoutChannel, m.receive); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to other instances: %w", err)
+	}
+	go m.work()
+	return m, nil
+}
+
+func newInMemory(logger *zap.Logger, f fanout.Fanout) *inMemory {
+	return &inMemory{
 		subscribers: make(map[Topic]map[string]CallbackFunc),
 		q:           make(chan payload, 1024),
+		fanout:      f,
 		logger:      logger.Named("EventReadWriter"),
 	}
-
-	go m.work()
-
-	return m
 }
 
 func (i *inMemory) UserEvent(userID users.ID, eventType EventType, reference string) {
@@ -116,8 +138,32 @@ type payload struct {
 	reference string
 }
 
+// remotePayload is a payload that is sent to other instances.
+type remotePayload struct {
+	Topic     Topic     `json:"topic"`
+	EventType EventType `json:"event_type"`
+	Reference string    `json:"reference"`
+}
+
 func (i *inMemory) event(topic Topic, eventType EventType, reference string) {
 	i.q <- payload{topic, eventType, reference}
+
+	msg, err := json.Marshal(remotePayload{Topic: topic, EventType: eventType, Reference: reference})
+	if err != nil {
+		i.logger.Error("failed to marshal event", zap.Error(err))
+		return
+	}
+	i.fanout.Publish(fanoutChannel, msg)
+}
+
+// receive handles an event that was sent by another instance.
+func (i *inMemory) receive(msg []byte) {
+	var p remotePayload
+	if err := json.Unmarshal(msg, &p); err != nil {
+		i.logger.Error("failed to unmarshal event", zap.Error(err))
+		return
+	}
+	i.q <- payload{p.Topic, p.EventType, p.Reference}
 }
 
 var ErrClientDisconnected = errors.New("client disconnected")
@@ -0,0 +1,69 @@
+package fanout
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/db"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Channel separates messages of different kinds. Messages are ordered per channel.
+type Channel string
+
+func (c Channel) String() string {
+	return string(c)
+}
+
+// Fanout delivers messages to the other instances of the API.
+type Fanout interface {
+	// Publish sends the message to every other instance that is subscribed to the channel. Publish does not
+	// wait for the message to be sent, messages from one instance are received in the order they were
+	// published. Messages might be dropped if they can't be sent fast enough.
+	Publish(channel Channel, msg []byte)
+	// Subscribe calls fn for every message that another instance publishes to the channel, until ctx is done.
+	// Messages that are published by this instance are not received.
+	Subscribe(ctx context.Context, channel Channel, fn func([]byte)) error
+}
+
+type Backend string
+
+const (
+	BackendNone     Backend = "none"
+	BackendPostgres Backend = "postgres"
+)
+
+type Configuration struct {
+	Backend Backend `long:"backend" description:"How events are delivered to subscriptions on other instances of the API, set to postgres when running more than one instance" choice:"none" choice:"postgres" default:"none"`
+}
+
+func New(logger *zap.Logger, d *sqlx.DB, dbCfg *db.Configuration, cfg *Configuration) (Fanout, error) {
+	if cfg == nil {
+		return NewNoop(), nil
+	}
+	switch cfg.Backend {
+	case BackendNone, "":
+		return NewNoop(), nil
+	case BackendPostgres:
+		return NewPostgres(logger, d, dbCfg.URL.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown events fanout backend: %s", cfg.Backend)
+	}
+}
+
+var _ Fanout = &noop{}
+
+// noop is used when there is only one instance of the API.
+type noop struct{}
+
+func NewNoop() Fanout {
+	return &noop{}
+}
+
+func (*noop) Publish(Channel, []byte) {}
+
+func (*noop) Subscribe(context.Context, Channel, func([]byte)) error {
+	return nil
+}
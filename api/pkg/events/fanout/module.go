@@ -0,0 +1,11 @@
+package fanout
+
+import "getsturdy.com/api/pkg/di"
+
+func Module(c *di.Container) {
+	c.Register(New)
+}
+
+func TestingModule(c *di.Container) {
+	c.Register(NewNoop)
+}
@@ -0,0 +1,226 @@
+package fanout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxNotifySize is the largest message that is sent in a notification, Postgres allows up to 8000 bytes.
+	// Larger messages are stored in the events_fanout_payloads table.
+	maxNotifySize = 7000
+	// payloadTTL is how long stored messages are kept for other instances to read.
+	payloadTTL = time.Minute
+)
+
+var (
+	publishedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_events_fanout_published_total",
+	}, []string{"channel"})
+	receivedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_events_fanout_received_total",
+	}, []string{"channel"})
+	droppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_events_fanout_dropped_total",
+	}, []string{"channel"})
+)
+
+// envelope is the payload of a notification.
+type envelope struct {
+	Instance string `json:"i"`
+	Message  []byte `json:"m,omitempty"`
+	// PayloadID is set instead of Message if the message is too large for a notification.
+	PayloadID *int64 `json:"p,omitempty"`
+}
+
+type outgoing struct {
+	channel Channel
+	msg     []byte
+}
+
+type subscriptionID string
+
+var _ Fanout = &postgresFanout{}
+
+// postgresFanout sends messages with NOTIFY, and receives them with LISTEN on a dedicated connection.
+type postgresFanout struct {
+	logger   *zap.Logger
+	db       *sqlx.DB
+	listener *pq.Listener
+	instance string
+
+	outgoing chan outgoing
+
+	subscribersGuard sync.RWMutex
+	subscribers      map[Channel]map[subscriptionID]func([]byte)
+}
+
+func NewPostgres(logger *zap.Logger, db *sqlx.DB, dbSourceURL string) *postgresFanout {
+	logger = logger.Named("eventsFanout")
+	f := &postgresFanout{
+		logger:   logger,
+		db:       db,
+		instance: uuid.NewString(),
+		outgoing: make(chan outgoing, 1024),
+
+		subscribers: make(map[Channel]map[subscriptionID]func([]byte)),
+	}
+
+	f.listener = pq.NewListener(dbSourceURL, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			logger.Error("disconnected, events from other instances are lost until reconnected", zap.Error(err))
+		case pq.ListenerEventReconnected:
+			logger.Info("reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			logger.Error("failed to connect", zap.Error(err))
+		}
+	})
+
+	go f.publish()
+	go f.receive()
+
+	return f
+}
+
+// Publish drops the message if the outgoing buffer is full, so that a slow or unavailable database does not
+// block the callers.
+func (f *postgresFanout) Publish(channel Channel, msg []byte) {
+	select {
+	case f.outgoing <- outgoing{channel: channel, msg: msg}:
+	default:
+		droppedCounter.WithLabelValues(channel.String()).Inc()
+		f.logger.Error("outgoing buffer is full, dropping message", zap.Stringer("channel", channel))
+	}
+}
+
+func (f *postgresFanout) Subscribe(ctx context.Context, channel Channel, fn func([]byte)) error {
+	id := subscriptionID(uuid.NewString())
+
+	f.subscribersGuard.Lock()
+	if f.subscribers[channel] == nil {
+		f.subscribers[channel] = make(map[subscriptionID]func([]byte))
+		if err := f.listener.Listen(channel.String()); err != nil {
+			f.subscribersGuard.Unlock()
+			return fmt.Errorf("failed to listen to %s: %w", channel, err)
+		}
+	}
+	f.subscribers[channel][id] = fn
+	f.subscribersGuard.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.subscribersGuard.Lock()
+		delete(f.subscribers[channel], id)
+		f.subscribersGuard.Unlock()
+	}()
+
+	return nil
+}
+
+// publish sends outgoing messages one at a time, to keep them in order.
+func (f *postgresFanout) publish() {
+	ticker := time.NewTicker(payloadTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case out := <-f.outgoing:
+			if err := f.notify(out.channel, out.msg); err != nil {
+				f.logger.Error("failed to publish message", zap.Stringer("channel", out.channel), zap.Error(err))
+				continue
+			}
+			publishedCounter.WithLabelValues(out.channel.String()).Inc()
+		case <-ticker.C:
+			if _, err := f.db.Exec(`DELETE FROM events_fanout_payloads WHERE created_at < NOW() - $1 * INTERVAL '1 millisecond'`, payloadTTL.Milliseconds()); err != nil {
+				f.logger.Error("failed to delete old payloads", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (f *postgresFanout) notify(channel Channel, msg []byte) error {
+	env := envelope{Instance: f.instance, Message: msg}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	if len(payload) > maxNotifySize {
+		var id int64
+		if err := f.db.Get(&id, `INSERT INTO events_fanout_payloads (payload) VALUES ($1) RETURNING id`, msg); err != nil {
+			return fmt.Errorf("failed to store payload: %w", err)
+		}
+		env = envelope{Instance: f.instance, PayloadID: &id}
+		if payload, err = json.Marshal(env); err != nil {
+			return fmt.Errorf("failed to marshal envelope: %w", err)
+		}
+	}
+
+	if _, err := f.db.Exec(`SELECT pg_notify($1, $2)`, channel.String(), string(payload)); err != nil {
+		return fmt.Errorf("failed to notify: %w", err)
+	}
+	return nil
+}
+
+// receive calls the subscribers of every notification, one notification at a time to keep them in order.
+func (f *postgresFanout) receive() {
+	for notification := range f.listener.NotificationChannel() {
+		// nil is sent after a reconnect
+		if notification == nil {
+			continue
+		}
+
+		channel := Channel(notification.Channel)
+		msg, err := f.message(notification.Extra)
+		if err != nil {
+			f.logger.Error("failed to receive message", zap.Stringer("channel", channel), zap.Error(err))
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		receivedCounter.WithLabelValues(channel.String()).Inc()
+
+		f.subscribersGuard.RLock()
+		subscribers := make([]func([]byte), 0, len(f.subscribers[channel]))
+		for _, fn := range f.subscribers[channel] {
+			subscribers = append(subscribers, fn)
+		}
+		f.subscribersGuard.RUnlock()
+
+		for _, fn := range subscribers {
+			fn(msg)
+		}
+	}
+}
+
+// message returns the message of the notification, or nil if it was sent by this instance.
+func (f *postgresFanout) message(extra string) ([]byte, error) {
+	var env envelope
+	if err := json.Unmarshal([]byte(extra), &env); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+	if env.Instance == f.instance {
+		return nil, nil
+	}
+	if env.PayloadID == nil {
+		return env.Message, nil
+	}
+
+	var msg []byte
+	if err := f.db.Get(&msg, `SELECT payload FROM events_fanout_payloads WHERE id = $1`, *env.PayloadID); err != nil {
+		return nil, fmt.Errorf("failed to get payload: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,76 @@
+package fanout
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/db"
+	"getsturdy.com/api/pkg/internal/sturdytest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestPostgres(t *testing.T) {
+	if os.Getenv("E2E_TEST") == "" {
+		t.SkipNow()
+	}
+
+	d, err := db.Setup(sturdytest.PsqlDbSourceForTesting())
+	assert.NoError(t, err)
+
+	a := NewPostgres(zap.NewNop(), d, sturdytest.PsqlDbSourceForTesting())
+	b := NewPostgres(zap.NewNop(), d, sturdytest.PsqlDbSourceForTesting())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	channel := Channel("test_" + uuid.NewString())
+	aReceived, bReceived := make(chan []byte, 10), make(chan []byte, 10)
+	assert.NoError(t, a.Subscribe(ctx, channel, func(msg []byte) { aReceived <- msg }))
+	assert.NoError(t, b.Subscribe(ctx, channel, func(msg []byte) { bReceived <- msg }))
+
+	large := bytes.Repeat([]byte("x"), maxNotifySize*2)
+	a.Publish(channel, []byte("first"))
+	a.Publish(channel, large)
+	a.Publish(channel, []byte("third"))
+
+	for _, expected := range [][]byte{[]byte("first"), large, []byte("third")} {
+		select {
+		case msg := <-bReceived:
+			assert.Equal(t, expected, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+	}
+
+	// messages are not sent back to the publisher
+	select {
+	case <-aReceived:
+		t.Fatal("publisher received its own message")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPostgres_publishShouldNotBlock(t *testing.T) {
+	// nothing is reading the outgoing messages, like when the database is unavailable
+	f := &postgresFanout{logger: zap.NewNop(), outgoing: make(chan outgoing, 1)}
+
+	published := make(chan struct{})
+	go func() {
+		f.Publish("test", []byte("first"))
+		f.Publish("test", []byte("dropped"))
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked on a full buffer")
+	}
+	assert.Equal(t, []byte("first"), (<-f.outgoing).msg)
+}
@@ -1,7 +1,9 @@
 package events
 
 import (
+	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/comments"
 	"getsturdy.com/api/pkg/github"
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/onboarding"
@@ -39,6 +41,8 @@ const (
 	StatusUpdated
 	CompletedOnboardingStep
 	OrganizationUpdated
+	ChangeLanded
+	CommentUpdated
 )
 
 func (t Type) String() string {
@@ -81,6 +85,10 @@ func (t Type) String() string {
 		return "WorkspaceWatchingStatusUpdated"
 	case OrganizationUpdated:
 		return "OrganizationUpdated"
+	case ChangeLanded:
+		return "ChangeLanded"
+	case CommentUpdated:
+		return "CommentUpdated"
 	default:
 		return "Unknown"
 	}
@@ -99,4 +107,6 @@ type event struct {
 	OnboardingStep    *onboarding.Step
 	WorkspaceWatcher  *watchers.Watcher
 	Organization      *organization.Organization
+	Change            *changes.Change
+	Comment           *comments.Comment
 }
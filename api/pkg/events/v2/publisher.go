@@ -3,7 +3,9 @@ package events
 import (
 	"context"
 
+	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/comments"
 	"getsturdy.com/api/pkg/github"
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/onboarding"
@@ -291,3 +293,31 @@ func (p *Publisher) OrganizationUpdated(ctx context.Context, receiver *receiver,
 	}
 	return nil
 }
+
+func (p *Publisher) ChangeLanded(ctx context.Context, receiver *receiver, change *changes.Change) error {
+	topics, err := receiver.Topics(ctx, p.codebaseUserRepo, p.workspaceRepo, p.organizationMemberRepo)
+	if err != nil {
+		return err
+	}
+	for topic := range topics {
+		p.pubSub.pub(topic, &event{
+			Type:   ChangeLanded,
+			Change: change,
+		})
+	}
+	return nil
+}
+
+func (p *Publisher) CommentUpdated(ctx context.Context, receiver *receiver, comment *comments.Comment) error {
+	topics, err := receiver.Topics(ctx, p.codebaseUserRepo, p.workspaceRepo, p.organizationMemberRepo)
+	if err != nil {
+		return err
+	}
+	for topic := range topics {
+		p.pubSub.pub(topic, &event{
+			Type:    CommentUpdated,
+			Comment: comment,
+		})
+	}
+	return nil
+}
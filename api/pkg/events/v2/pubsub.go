@@ -1,15 +1,23 @@
 package events
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"getsturdy.com/api/pkg/events/fanout"
+
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// fanoutChannel is the channel that events are sent to other instances on.
+const fanoutChannel fanout.Channel = "sturdy_events_v2"
+
 type subscriber struct {
 	ctx      context.Context
 	callback callback
@@ -21,20 +29,60 @@ type subscriptionID string
 
 type pubSub struct {
 	logger *zap.Logger
+	fanout fanout.Fanout
 
 	subscribersGuard *sync.RWMutex
 	subscribers      map[Topic]map[Type]map[subscriptionID]subscriber
 }
 
-func New(logger *zap.Logger) *pubSub {
-	return &pubSub{
+func New(logger *zap.Logger, f fanout.Fanout) (*pubSub, error) {
+	r := &pubSub{
 		logger:           logger.Named("events_pubsub"),
+		fanout:           f,
 		subscribersGuard: &sync.RWMutex{},
 		subscribers:      map[Topic]map[Type]map[subscriptionID]subscriber{},
 	}
+	if err := f.Subscribe(context.Background(), fanoutChannel, r.receive); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to other instances: %w", err)
+	}
+	return r, nil
+}
+
+// remoteEvent is an event that is sent to other instances. It's gob encoded, as some fields of the events are
+// not included in their JSON.
+type remoteEvent struct {
+	Topic Topic
+	Event *event
 }
 
 func (r *pubSub) pub(topic Topic, evt *event) {
+	r.dispatch(topic, evt)
+
+	// every instance handles the events it publishes to allTopic, don't send them to the others
+	if topic == allTopic {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&remoteEvent{Topic: topic, Event: evt}); err != nil {
+		r.logger.Error("failed to encode event", zap.Stringer("type", evt.Type), zap.Error(err))
+		return
+	}
+	r.fanout.Publish(fanoutChannel, buf.Bytes())
+}
+
+// receive handles an event that was published by another instance.
+func (r *pubSub) receive(msg []byte) {
+	var remote remoteEvent
+	if err := gob.NewDecoder(bytes.NewReader(msg)).Decode(&remote); err != nil {
+		r.logger.Error("failed to decode event", zap.Error(err))
+		return
+	}
+	r.dispatch(remote.Topic, remote.Event)
+}
+
+// dispatch calls the subscribers of this instance.
+func (r *pubSub) dispatch(topic Topic, evt *event) {
 	r.subscribersGuard.RLock()
 	handlers := r.subscribers[topic][evt.Type]
 	r.subscribersGuard.RUnlock()
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/events/fanout"
+	"getsturdy.com/api/pkg/workspaces"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// loopback delivers published messages to the subscribers of another loopback.
+type loopback struct {
+	to          *loopback
+	subscribers []func([]byte)
+}
+
+func (l *loopback) Publish(_ fanout.Channel, msg []byte) {
+	for _, fn := range l.to.subscribers {
+		fn(msg)
+	}
+}
+
+func (l *loopback) Subscribe(_ context.Context, _ fanout.Channel, fn func([]byte)) error {
+	l.subscribers = append(l.subscribers, fn)
+	return nil
+}
+
+func TestPubSubFanout(t *testing.T) {
+	aFanout, bFanout := &loopback{}, &loopback{}
+	aFanout.to, bFanout.to = bFanout, aFanout
+
+	a, err := New(zap.NewNop(), aFanout)
+	assert.NoError(t, err)
+	b, err := New(zap.NewNop(), bFanout)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *event, 10)
+	b.sub(ctx, func(_ context.Context, evt *event) error {
+		received <- evt
+		return nil
+	}, userTopic("user"), WorkspaceUpdated)
+	b.sub(ctx, func(_ context.Context, evt *event) error {
+		received <- evt
+		return nil
+	}, allTopic, WorkspaceUpdated)
+
+	// fields that are not a part of the workspace JSON are sent too
+	viewID := "view-id"
+	a.pub(userTopic("user"), &event{Type: WorkspaceUpdated, Workspace: &workspaces.Workspace{ID: "workspace-id", ViewID: &viewID}})
+	// allTopic is handled by the publishing instance only
+	a.pub(allTopic, &event{Type: WorkspaceUpdated, Workspace: &workspaces.Workspace{ID: "workspace-id"}})
+
+	select {
+	case evt := <-received:
+		assert.Equal(t, WorkspaceUpdated, evt.Type)
+		assert.Equal(t, "workspace-id", evt.Workspace.ID)
+		if assert.NotNil(t, evt.Workspace.ViewID) {
+			assert.Equal(t, viewID, *evt.Workspace.ViewID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+
+	select {
+	case <-received:
+		t.Fatal("received an event that was published to allTopic by another instance")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -81,5 +81,9 @@ func (r *receiver) Topics(
 		}
 	}
 
+	if len(r.CodebaseIDs) > 0 || len(r.OrganizationIDs) > 0 {
+		topics[allTopic] = true
+	}
+
 	return topics, nil
 }
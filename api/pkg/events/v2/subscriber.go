@@ -3,7 +3,9 @@ package events
 import (
 	"context"
 
+	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/comments"
 	"getsturdy.com/api/pkg/github"
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/onboarding"
@@ -31,6 +33,12 @@ func SubscribeUser(id users.ID) Topic {
 	return userTopic(id)
 }
 
+// SubscribeAll returns a topic that receives every event sent to a codebase, a workspace or an organization,
+// regardless of who the members are.
+func SubscribeAll() Topic {
+	return allTopic
+}
+
 func (s *Subscriber) OnCodebaseEvent(ctx context.Context, topic Topic, callback func(context.Context, *codebases.Codebase) error) {
 	s.pubsub.sub(ctx, func(ctx context.Context, event *event) error {
 		return callback(ctx, event.Codebase)
@@ -138,3 +146,15 @@ func (s *Subscriber) OnOrganizationUpdated(ctx context.Context, topic Topic, cal
 		return callback(ctx, event.Organization)
 	}, topic, OrganizationUpdated)
 }
+
+func (s *Subscriber) OnChangeLanded(ctx context.Context, topic Topic, callback func(context.Context, *changes.Change) error) {
+	s.pubsub.sub(ctx, func(ctx context.Context, event *event) error {
+		return callback(ctx, event.Change)
+	}, topic, ChangeLanded)
+}
+
+func (s *Subscriber) OnCommentUpdated(ctx context.Context, topic Topic, callback func(context.Context, *comments.Comment) error) {
+	s.pubsub.sub(ctx, func(ctx context.Context, event *event) error {
+		return callback(ctx, event.Comment)
+	}, topic, CommentUpdated)
+}
@@ -12,6 +12,9 @@ func (t Topic) String() string {
 	return string(t)
 }
 
+// allTopic receives every event that is sent to a codebase, a workspace or an organization.
+const allTopic Topic = "all"
+
 func userTopic(userID users.ID) Topic {
 	return Topic(fmt.Sprintf("user:%s", userID))
 }
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Table is a table with rows that belong to a codebase.
+type Table struct {
+	Name string
+	// where selects the rows of the codebase, $1 is the ID of the codebase.
+	where string
+	// UserColumns are the columns that reference users.
+	UserColumns []string
+	// CodebaseColumn is the column that references the codebase, if any.
+	CodebaseColumn string
+	// References are the columns that reference rows of other exported tables, by the name of the table.
+	References map[string]string
+}
+
+const workspaceRows = "workspace_id IN (SELECT id FROM workspaces WHERE codebase_id = $1)"
+
+var workspaceReference = map[string]string{"workspace_id": "workspaces"}
+
+// Tables are the tables that are exported, in an order where rows are inserted after the rows that they
+// reference.
+var Tables = []Table{
+	{Name: "codebases", where: "id = $1", CodebaseColumn: "id"},
+	{Name: "codebase_users", where: "codebase_id = $1", UserColumns: []string{"user_id"}, CodebaseColumn: "codebase_id"},
+	{Name: "acls", where: "codebase_id = $1", CodebaseColumn: "codebase_id"},
+	{Name: "acl_versions", where: "acl_id IN (SELECT id FROM acls WHERE codebase_id = $1)", UserColumns: []string{"author_id"}, References: map[string]string{"acl_id": "acls"}},
+	{Name: "workspaces", where: "codebase_id = $1", UserColumns: []string{"user_id"}, CodebaseColumn: "codebase_id"},
+	{Name: "changes", where: "codebase_id = $1", UserColumns: []string{"user_id"}, CodebaseColumn: "codebase_id", References: workspaceReference},
+	{Name: "snapshots", where: "codebase_id = $1", CodebaseColumn: "codebase_id", References: workspaceReference},
+	{Name: "workspace_activity", where: workspaceRows, UserColumns: []string{"user_id"}, References: workspaceReference},
+	{Name: "workspace_activity_reads", where: workspaceRows, UserColumns: []string{"user_id"}, References: workspaceReference},
+	{Name: "workspace_watchers", where: workspaceRows, UserColumns: []string{"user_id"}, References: workspaceReference},
+	{Name: "workspace_reviews", where: "codebase_id = $1", UserColumns: []string{"user_id", "requested_by"}, CodebaseColumn: "codebase_id", References: workspaceReference},
+	{Name: "comments", where: "codebase_id = $1", UserColumns: []string{"user_id"}, CodebaseColumn: "codebase_id", References: workspaceReference},
+	{Name: "suggestions_v2", where: "codebase_id = $1", UserColumns: []string{"user_id"}, CodebaseColumn: "codebase_id", References: map[string]string{"workspace_id": "workspaces", "for_workspace_id": "workspaces"}},
+	{Name: "statuses", where: "codebase_id = $1", CodebaseColumn: "codebase_id"},
+	{Name: "codebases_garbage_collection_policies", where: "codebase_id = $1", UserColumns: []string{"updated_by"}, CodebaseColumn: "codebase_id"},
+}
+
+// TableByName returns the exported table with the given name.
+func TableByName(name string) (Table, bool) {
+	for _, table := range Tables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return Table{}, false
+}
+
+type Repository interface {
+	// SchemaVersion returns the version of the latest database migration.
+	SchemaVersion(context.Context) (int64, error)
+	// BeginExport starts a read only transaction, so that all rows of an export are consistent.
+	BeginExport(context.Context) (Exporter, error)
+	// BeginImport starts a transaction that all rows of an import are inserted in.
+	BeginImport(context.Context) (Importer, error)
+}
+
+type Exporter interface {
+	// ListUserIDs returns the users that are referenced by the rows of the codebase.
+	ListUserIDs(context.Context, codebases.ID) ([]users.ID, error)
+	// Rows calls fn with every row of the table that belongs to the codebase, encoded as a JSON object.
+	Rows(ctx context.Context, table Table, codebaseID codebases.ID, fn func(json.RawMessage) error) error
+	Rollback() error
+}
+
+type Importer interface {
+	// Insert inserts a row, encoded as a JSON object, to the table. Columns that are not in the row are set
+	// to NULL.
+	Insert(ctx context.Context, table Table, row json.RawMessage) error
+	Commit() error
+	Rollback() error
+}
+
+type repo struct {
+	db *sqlx.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repo{db: db}
+}
+
+func (r *repo) SchemaVersion(ctx context.Context) (int64, error) {
+	var version int64
+	if err := r.db.GetContext(ctx, &version, `SELECT version FROM schema_migrations`); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+func (r *repo) BeginExport(ctx context.Context) (Exporter, error) {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &exporter{tx: tx}, nil
+}
+
+func (r *repo) BeginImport(ctx context.Context) (Importer, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &importer{tx: tx}, nil
+}
+
+type exporter struct {
+	tx *sqlx.Tx
+}
+
+func (e *exporter) ListUserIDs(ctx context.Context, codebaseID codebases.ID) ([]users.ID, error) {
+	var selects []string
+	for _, table := range Tables {
+		for _, column := range table.UserColumns {
+			selects = append(selects, fmt.Sprintf("SELECT %s AS user_id FROM %s WHERE %s", column, table.Name, table.where))
+		}
+	}
+
+	var ids []users.ID
+	if err := e.tx.SelectContext(ctx, &ids, `
+		SELECT DISTINCT user_id
+		FROM (`+strings.Join(selects, " UNION ")+`) AS referenced
+		WHERE user_id IS NOT NULL
+		ORDER BY user_id`, codebaseID); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return ids, nil
+}
+
+func (e *exporter) Rows(ctx context.Context, table Table, codebaseID codebases.ID, fn func(json.RawMessage) error) error {
+	rows, err := e.tx.QueryContext(ctx, fmt.Sprintf(`SELECT row_to_json(t) FROM %s t WHERE %s`, table.Name, table.where), codebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", table.Name, err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", table.Name, err)
+	}
+	return nil
+}
+
+func (e *exporter) Rollback() error {
+	return e.tx.Rollback()
+}
+
+type importer struct {
+	tx *sqlx.Tx
+}
+
+func (i *importer) Insert(ctx context.Context, table Table, row json.RawMessage) error {
+	if _, err := i.tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO %[1]s
+		SELECT * FROM json_populate_record(NULL::%[1]s, $1::json)`, table.Name), string(row)); err != nil {
+		return fmt.Errorf("failed to insert into %s: %w", table.Name, err)
+	}
+	return nil
+}
+
+func (i *importer) Commit() error {
+	return i.tx.Commit()
+}
+
+func (i *importer) Rollback() error {
+	return i.tx.Rollback()
+}
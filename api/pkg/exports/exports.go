@@ -0,0 +1,46 @@
+// Package exports moves codebases between installations.
+//
+// An export is a gzipped tar archive, with the following entries, in order:
+//
+//	manifest.json            the Manifest
+//	users.jsonl              the users that are referenced by the codebase, one User per line
+//	tables/<table>.jsonl     the rows of each table that belong to the codebase, one row per line
+//	repository/...           the objects and refs of the trunk repository, including the snapshot branches
+//
+// Views are not exported, as they belong to the computers that they were created on. Remotes and webhooks
+// are not exported either, as they contain secrets of the original installation.
+package exports
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+)
+
+// FormatVersion is the version of the archive format, it's increased on incompatible changes.
+const FormatVersion = 1
+
+const (
+	ManifestPath        = "manifest.json"
+	UsersPath           = "users.jsonl"
+	TablesDirectory     = "tables/"
+	RepositoryDirectory = "repository/"
+)
+
+type Manifest struct {
+	FormatVersion int `json:"format_version"`
+	// SchemaVersion is the database migration version of the installation that created the export. Exports
+	// can only be imported to installations with the same version.
+	SchemaVersion int64        `json:"schema_version"`
+	CodebaseID    codebases.ID `json:"codebase_id"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// User is a user that is referenced by the codebase. Users are matched by their email when the codebase is
+// imported.
+type User struct {
+	ID    users.ID `json:"id"`
+	Email string   `json:"email"`
+	Name  string   `json:"name"`
+}
@@ -0,0 +1,14 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/exports/db"
+	"getsturdy.com/api/pkg/exports/routes"
+	"getsturdy.com/api/pkg/exports/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Import(routes.Module)
+	c.Import(service.Module)
+}
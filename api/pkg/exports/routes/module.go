@@ -0,0 +1,8 @@
+package routes
+
+import "getsturdy.com/api/pkg/di"
+
+func Module(c *di.Container) {
+	c.Register(NewExportRoute)
+	c.Register(NewImportRoute)
+}
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	service_exports "getsturdy.com/api/pkg/exports/service"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ExportRoute func(*gin.Context)
+
+// NewExportRoute returns a route that downloads an archive of a codebase, with the trunk repository and all
+// of the Sturdy data of the codebase. The archive can be imported to another installation with ImportRoute.
+func NewExportRoute(
+	exportsService *service_exports.Service,
+	codebaseService *service_codebase.Service,
+	authService *service_auth.Service,
+	logger *zap.Logger,
+) ExportRoute {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		codebase, err := codebaseService.GetByID(ctx, codebases.ID(c.Param("id")))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if err := authService.CanWrite(ctx, codebase); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		c.Header("Content-Type", "application/gzip")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", codebase.GenerateSlug()+".sturdy.tar.gz"))
+		c.Status(http.StatusOK)
+
+		if err := exportsService.Export(ctx, codebase.ID, c.Writer); err != nil {
+			// headers are already sent, all we can do is to stop writing
+			logger.Error("failed to export codebase", zap.Stringer("codebase_id", codebase.ID), zap.Error(err))
+			return
+		}
+	}
+}
+
+type ImportRoute func(*gin.Context)
+
+// NewImportRoute returns a route that imports an archive, that was created with ExportRoute, to an
+// organization. The archive is the body of the request.
+func NewImportRoute(
+	exportsService *service_exports.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+	logger *zap.Logger,
+) ImportRoute {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		org, err := organizationService.GetByID(ctx, c.Param("id"))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if err := authService.CanWrite(ctx, org); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		codebase, err := exportsService.Import(ctx, org.ID, c.Request.Body)
+		switch {
+		case err == nil:
+		case errors.Is(err, service_exports.ErrInvalidArchive),
+			errors.Is(err, service_exports.ErrIncompatible),
+			errors.Is(err, service_exports.ErrUnknownArchiveUser),
+			errors.Is(err, service_exports.ErrForeignReference):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service_exports.ErrCodebaseExists):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		default:
+			logger.Error("failed to import codebase", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.JSON(http.StatusOK, codebase)
+	}
+}
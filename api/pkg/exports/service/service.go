@@ -0,0 +1,657 @@
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/codebases"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/exports"
+	db_exports "getsturdy.com/api/pkg/exports/db"
+	"getsturdy.com/api/pkg/shortid"
+	"getsturdy.com/api/pkg/users"
+	service_user "getsturdy.com/api/pkg/users/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+	"getsturdy.com/api/vcs/provider"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidArchive     = errors.New("invalid archive")
+	ErrIncompatible       = errors.New("archive was exported from an incompatible version of Sturdy")
+	ErrCodebaseExists     = errors.New("codebase already exists")
+	ErrUnknownArchiveUser = errors.New("archive references a user that is not included in it")
+	ErrForeignReference   = errors.New("archive references a row that is not included in it")
+)
+
+type Service struct {
+	logger           *zap.Logger
+	repo             db_exports.Repository
+	codebaseRepo     db_codebases.CodebaseRepository
+	userService      service_user.Service
+	auditService     *service_audit.Service
+	executorProvider executor.Provider
+}
+
+func New(
+	logger *zap.Logger,
+	repo db_exports.Repository,
+	codebaseRepo db_codebases.CodebaseRepository,
+	userService service_user.Service,
+	auditService *service_audit.Service,
+	executorProvider executor.Provider,
+) *Service {
+	return &Service{
+		logger:           logger.Named("exportsService"),
+		repo:             repo,
+		codebaseRepo:     codebaseRepo,
+		userService:      userService,
+		auditService:     auditService,
+		executorProvider: executorProvider,
+	}
+}
+
+// Export writes an archive of the codebase to w, see package exports for the format.
+func (svc *Service) Export(ctx context.Context, codebaseID codebases.ID, w io.Writer) error {
+	schemaVersion, err := svc.repo.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionCodebaseExported, audit.TargetCodebase, codebaseID.String(),
+		audit.CodebaseID(codebaseID),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeJSON(tw, exports.ManifestPath, &exports.Manifest{
+		FormatVersion: exports.FormatVersion,
+		SchemaVersion: schemaVersion,
+		CodebaseID:    codebaseID,
+		CreatedAt:     time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	if err := svc.exportRows(ctx, tw, codebaseID); err != nil {
+		return err
+	}
+
+	if err := svc.executorProvider.New().
+		Schedule(func(repoProvider provider.RepoProvider) error {
+			return writeDirectory(tw, repoProvider.TrunkPath(codebaseID), exports.RepositoryDirectory)
+		}).ExecTrunk(codebaseID, "exportCodebase"); err != nil {
+		return fmt.Errorf("failed to export repository: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	return nil
+}
+
+func (svc *Service) exportRows(ctx context.Context, tw *tar.Writer, codebaseID codebases.ID) error {
+	exporter, err := svc.repo.BeginExport(ctx)
+	if err != nil {
+		return err
+	}
+	defer exporter.Rollback()
+
+	userIDs, err := exporter.ListUserIDs(ctx, codebaseID)
+	if err != nil {
+		return err
+	}
+
+	uu, err := svc.userService.GetByIDs(ctx, userIDs...)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	if err := writeLines(tw, exports.UsersPath, func(write func(any) error) error {
+		for _, u := range uu {
+			if err := write(&exports.User{ID: u.ID, Email: u.Email, Name: u.Name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, table := range db_exports.Tables {
+		if err := writeLines(tw, exports.TablesDirectory+table.Name+".jsonl", func(write func(any) error) error {
+			return exporter.Rows(ctx, table, codebaseID, func(row json.RawMessage) error {
+				return write(row)
+			})
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeLines writes the values that are passed to write as JSON lines to a file in the archive. The lines
+// are buffered in a temporary file, as the size of the file has to be known before it's written.
+func writeLines(tw *tar.Writer, name string, fn func(write func(any) error) error) error {
+	tmp, err := os.CreateTemp("", "sturdy-export-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	buf := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(buf)
+	if err := fn(func(v any) error {
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, tmp); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeJSON(tw *tar.Writer, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     0o644,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// isRepositoryPath returns true if the path, relative to the root of a bare repository, is exported. Only the
+// objects and the refs are exported. The rest, like the config and the hooks, can run commands, and is created
+// when the repository is initialized on import.
+func isRepositoryPath(rel string) bool {
+	switch {
+	case rel == ".", rel == "HEAD", rel == "packed-refs":
+		return true
+	case rel == "objects/info", strings.HasPrefix(rel, "objects/info/"):
+		// alternates can point to the objects of other repositories
+		return false
+	case rel == "objects", strings.HasPrefix(rel, "objects/"),
+		rel == "refs", strings.HasPrefix(rel, "refs/"):
+		return true
+	default:
+		return false
+	}
+}
+
+// writeDirectory writes the directories and regular files of the repository in root to the archive, under prefix.
+func writeDirectory(tw *tar.Writer, root, prefix string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if !isRepositoryPath(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		header.Mode = 0o644
+		if d.IsDir() {
+			header.Name += "/"
+			header.Mode = 0o755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", header.Name, err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(tw, f); err != nil {
+			return fmt.Errorf("failed to write %s: %w", header.Name, err)
+		}
+		return nil
+	})
+}
+
+// Import creates the codebase in an archive, in the organization. Users are matched by email, and users that
+// don't exist on this installation are created.
+func (svc *Service) Import(ctx context.Context, organizationID string, r io.Reader) (*codebases.Codebase, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	tr := tar.NewReader(gz)
+
+	manifest, err := svc.readManifest(ctx, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch _, err := svc.codebaseRepo.GetAllowArchived(manifest.CodebaseID); {
+	case err == nil:
+		return nil, ErrCodebaseExists
+	case errors.Is(err, sql.ErrNoRows):
+	default:
+		return nil, fmt.Errorf("failed to get codebase: %w", err)
+	}
+
+	userIDs, err := svc.importUsers(ctx, tr, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	importer, err := svc.repo.BeginImport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer importer.Rollback()
+
+	state := newImportState(manifest.CodebaseID, organizationID, userIDs)
+
+	var trunkPath string
+	if err := svc.executorProvider.New().
+		AllowRebasingState(). // allowed because the repo does not exist yet
+		Schedule(func(repoProvider provider.RepoProvider) error {
+			trunkPath = repoProvider.TrunkPath(manifest.CodebaseID)
+			// the config and the hooks are not imported, they are created like for any other repository
+			if _, err := vcs.CreateEmptyBareRepo(trunkPath); err != nil {
+				return fmt.Errorf("failed to create repository: %w", err)
+			}
+			if err := svc.importEntries(ctx, tr, importer, state, trunkPath); err != nil {
+				_ = os.RemoveAll(trunkPath)
+				return err
+			}
+			return nil
+		}).ExecTrunk(manifest.CodebaseID, "importCodebase"); err != nil {
+		return nil, err
+	}
+
+	if err := importer.Commit(); err != nil {
+		_ = os.RemoveAll(trunkPath)
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	codebase, err := svc.codebaseRepo.Get(manifest.CodebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get imported codebase: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionCodebaseImported, audit.TargetCodebase, codebase.ID.String(),
+		audit.CodebaseID(codebase.ID),
+		audit.After(manifest),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return codebase, nil
+}
+
+func (svc *Service) readManifest(ctx context.Context, tr *tar.Reader) (*exports.Manifest, error) {
+	if err := next(tr, exports.ManifestPath); err != nil {
+		return nil, err
+	}
+
+	manifest := &exports.Manifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode manifest: %s", ErrInvalidArchive, err)
+	}
+
+	if manifest.FormatVersion != exports.FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrIncompatible, manifest.FormatVersion)
+	}
+
+	schemaVersion, err := svc.repo.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion != schemaVersion {
+		return nil, fmt.Errorf("%w: the archive has schema version %d, this installation has %d", ErrIncompatible, manifest.SchemaVersion, schemaVersion)
+	}
+
+	return manifest, nil
+}
+
+// importUsers matches the users in the archive with users on this installation by email, creating the ones
+// that don't exist yet. It returns a map from the IDs in the archive to the IDs on this installation.
+func (svc *Service) importUsers(ctx context.Context, tr *tar.Reader, importedBy users.ID) (map[users.ID]users.ID, error) {
+	if err := next(tr, exports.UsersPath); err != nil {
+		return nil, err
+	}
+
+	userIDs := make(map[users.ID]users.ID)
+	if err := readLines(tr, func(line []byte) error {
+		var u exports.User
+		if err := json.Unmarshal(line, &u); err != nil {
+			return fmt.Errorf("%w: failed to decode user: %s", ErrInvalidArchive, err)
+		}
+
+		existing, err := svc.userService.GetByEmail(ctx, u.Email)
+		switch {
+		case err == nil:
+			userIDs[u.ID] = existing.ID
+		case errors.Is(err, sql.ErrNoRows):
+			created, err := svc.userService.CreateShadow(ctx, u.Email, service_user.UserReferer(importedBy), &u.Name)
+			if err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+			userIDs[u.ID] = created.ID
+		default:
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// importState is what rows from the archive are rewritten with.
+type importState struct {
+	codebaseID     codebases.ID
+	organizationID string
+	// userIDs maps the IDs of the users in the archive to the IDs on this installation.
+	userIDs map[users.ID]users.ID
+	// ids are the IDs of the rows that have been imported so far, by table.
+	ids map[string]map[string]bool
+}
+
+func newImportState(codebaseID codebases.ID, organizationID string, userIDs map[users.ID]users.ID) *importState {
+	return &importState{
+		codebaseID:     codebaseID,
+		organizationID: organizationID,
+		userIDs:        userIDs,
+		ids:            make(map[string]map[string]bool),
+	}
+}
+
+func (svc *Service) importEntries(
+	ctx context.Context,
+	tr *tar.Reader,
+	importer db_exports.Importer,
+	state *importState,
+	trunkPath string,
+) error {
+	for {
+		header, err := tr.Next()
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			return nil
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+
+		switch {
+		case strings.HasPrefix(header.Name, exports.TablesDirectory):
+			name := strings.TrimSuffix(strings.TrimPrefix(header.Name, exports.TablesDirectory), ".jsonl")
+			table, ok := db_exports.TableByName(name)
+			if !ok {
+				return fmt.Errorf("%w: unknown table %s", ErrInvalidArchive, name)
+			}
+			if err := readLines(tr, func(line []byte) error {
+				row, err := svc.rewriteRow(table, line, state)
+				if err != nil {
+					return err
+				}
+				return importer.Insert(ctx, table, row)
+			}); err != nil {
+				return err
+			}
+		case strings.HasPrefix(header.Name, exports.RepositoryDirectory):
+			if err := extract(tr, header, trunkPath); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: unexpected file %s", ErrInvalidArchive, header.Name)
+		}
+	}
+}
+
+// rewriteRow updates a row from the archive to be valid on this installation. Rows are moved to the imported
+// codebase, and can only reference rows that have been imported before them.
+func (svc *Service) rewriteRow(table db_exports.Table, line []byte, state *importState) (json.RawMessage, error) {
+	row := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(line, &row); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode %s: %s", ErrInvalidArchive, table.Name, err)
+	}
+
+	set := func(column string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		row[column] = data
+		return nil
+	}
+
+	for _, column := range table.UserColumns {
+		var id *users.ID
+		if err := json.Unmarshal(row[column], &id); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s.%s: %s", ErrInvalidArchive, table.Name, column, err)
+		}
+		if id == nil {
+			continue
+		}
+		newID, ok := state.userIDs[*id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownArchiveUser, *id)
+		}
+		if err := set(column, newID); err != nil {
+			return nil, err
+		}
+	}
+
+	if table.CodebaseColumn != "" {
+		if err := set(table.CodebaseColumn, state.codebaseID); err != nil {
+			return nil, err
+		}
+	}
+
+	for column, referenced := range table.References {
+		var id *string
+		if err := json.Unmarshal(row[column], &id); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s.%s: %s", ErrInvalidArchive, table.Name, column, err)
+		}
+		if id == nil {
+			continue
+		}
+		if !state.ids[referenced][*id] {
+			return nil, fmt.Errorf("%w: %s.%s %s", ErrForeignReference, table.Name, column, *id)
+		}
+	}
+
+	switch table.Name {
+	case "codebases":
+		if err := set("organization_id", state.organizationID); err != nil {
+			return nil, err
+		}
+		if err := set("invite_code", nil); err != nil {
+			return nil, err
+		}
+		var shortID codebases.ShortCodebaseID
+		if err := json.Unmarshal(row["short_id"], &shortID); err != nil {
+			return nil, fmt.Errorf("%w: invalid codebases.short_id: %s", ErrInvalidArchive, err)
+		}
+		if _, err := svc.codebaseRepo.GetByShortID(shortID); err == nil {
+			// taken by another codebase on this installation
+			if err := set("short_id", shortid.New()); err != nil {
+				return nil, err
+			}
+		}
+	case "workspaces":
+		// views are not exported
+		if err := set("view_id", nil); err != nil {
+			return nil, err
+		}
+	case "snapshots":
+		// views are not exported
+		if err := set("view_id", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var id string
+	if err := json.Unmarshal(row["id"], &id); err == nil {
+		if state.ids[table.Name] == nil {
+			state.ids[table.Name] = make(map[string]bool)
+		}
+		state.ids[table.Name][id] = true
+	}
+
+	return json.Marshal(row)
+}
+
+func next(tr *tar.Reader, name string) error {
+	header, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("%w: failed to read %s: %s", ErrInvalidArchive, name, err)
+	}
+	if header.Name != name {
+		return fmt.Errorf("%w: expected %s, got %s", ErrInvalidArchive, name, header.Name)
+	}
+	return nil
+}
+
+func readLines(r io.Reader, fn func([]byte) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			return nil
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+	}
+}
+
+// extract writes a directory or a regular file in the repository directory of the archive to root. Files that
+// are not objects or refs are skipped, and files are never executable.
+func extract(tr *tar.Reader, header *tar.Header, root string) error {
+	rel := path.Clean(strings.TrimPrefix(header.Name, exports.RepositoryDirectory))
+	if rel == "." {
+		return os.MkdirAll(root, 0o755)
+	}
+	if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("%w: invalid path %s", ErrInvalidArchive, header.Name)
+	}
+	if !isRepositoryPath(rel) {
+		return nil
+	}
+	target := filepath.Join(root, filepath.FromSlash(rel))
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer f.Close()
+		if _, err := io.Copy(f, tr); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported file type of %s", ErrInvalidArchive, header.Name)
+	}
+	return nil
+}
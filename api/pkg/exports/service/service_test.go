@@ -0,0 +1,231 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"getsturdy.com/api/pkg/codebases"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/exports"
+	db_exports "getsturdy.com/api/pkg/exports/db"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "refs", "heads"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "HEAD"), []byte("ref: refs/heads/sturdytrunk\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(src, "refs", "heads", "snapshot-1"), []byte("abc\n"), 0o644))
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	assert.NoError(t, writeDirectory(tw, src, "repository/"))
+	assert.NoError(t, tw.Close())
+
+	dst := filepath.Join(t.TempDir(), "trunk")
+	tr := tar.NewReader(buf)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		assert.NoError(t, extract(tr, header, dst))
+	}
+
+	head, err := os.ReadFile(filepath.Join(dst, "HEAD"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ref: refs/heads/sturdytrunk\n", string(head))
+
+	branch, err := os.ReadFile(filepath.Join(dst, "refs", "heads", "snapshot-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc\n", string(branch))
+}
+
+func TestExtractRejectsPathsOutsideOfRepository(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"repository/../outside",
+		"repository/refs/../../outside",
+	} {
+		err := extract(tar.NewReader(&bytes.Buffer{}), &tar.Header{Name: name, Typeflag: tar.TypeReg}, root)
+		assert.True(t, errors.Is(err, ErrInvalidArchive), name)
+	}
+}
+
+func TestExtractOnlyObjectsAndRefs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for name, mode := range map[string]int64{
+		"repository/HEAD":                         0o644,
+		"repository/refs/heads/sturdytrunk":       0o755,
+		"repository/objects/ab/cdef":              0o444,
+		"repository/config":                       0o644,
+		"repository/hooks/post-receive":           0o755,
+		"repository/objects/info/alternates":      0o644,
+		"repository/objects/pack/pack-1.pack":     0o644,
+		"repository/info/attributes":              0o644,
+		"repository/refs/../hooks/pre-receive":    0o755,
+		"repository/objects/../config.worktree":   0o644,
+		"repository/refs/heads/../../description": 0o644,
+	} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: 1, Mode: mode}))
+		_, err := tw.Write([]byte("x"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+
+	root := t.TempDir()
+	tr := tar.NewReader(buf)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		assert.NoError(t, extract(tr, header, root))
+	}
+
+	for _, extracted := range []string{"HEAD", "refs/heads/sturdytrunk", "objects/ab/cdef", "objects/pack/pack-1.pack"} {
+		info, err := os.Stat(filepath.Join(root, extracted))
+		if assert.NoError(t, err, extracted) {
+			assert.Equal(t, os.FileMode(0o644), info.Mode().Perm(), extracted)
+		}
+	}
+	for _, skipped := range []string{"config", "hooks", "objects/info", "info", "config.worktree", "description"} {
+		_, err := os.Stat(filepath.Join(root, skipped))
+		assert.True(t, os.IsNotExist(err), skipped)
+	}
+}
+
+func TestRewriteRow(t *testing.T) {
+	codebaseRepo := db_codebases.NewMemory()
+	assert.NoError(t, codebaseRepo.Create(codebases.Codebase{ID: "other", ShortCodebaseID: "taken"}))
+
+	svc := &Service{codebaseRepo: codebaseRepo}
+	state := newImportState("cb", "new-org", map[users.ID]users.ID{"old-user": "new-user"})
+
+	codebasesTable, _ := db_exports.TableByName("codebases")
+	row, err := svc.rewriteRow(codebasesTable, []byte(`{"id":"cb","short_id":"taken","invite_code":"secret","organization_id":"old-org"}`), state)
+	assert.NoError(t, err)
+	var codebase map[string]any
+	assert.NoError(t, json.Unmarshal(row, &codebase))
+	assert.Equal(t, "cb", codebase["id"])
+	assert.Equal(t, "new-org", codebase["organization_id"])
+	assert.Nil(t, codebase["invite_code"])
+	assert.NotEqual(t, "taken", codebase["short_id"])
+
+	workspacesTable, _ := db_exports.TableByName("workspaces")
+	_, err = svc.rewriteRow(workspacesTable, []byte(`{"id":"ws","codebase_id":"cb","user_id":"old-user"}`), state)
+	assert.NoError(t, err)
+
+	reviewsTable, _ := db_exports.TableByName("workspace_reviews")
+	row, err = svc.rewriteRow(reviewsTable, []byte(`{"id":"r","codebase_id":"cb","workspace_id":"ws","user_id":"old-user","requested_by":null}`), state)
+	assert.NoError(t, err)
+	var review map[string]any
+	assert.NoError(t, json.Unmarshal(row, &review))
+	assert.Equal(t, "new-user", review["user_id"])
+	assert.Nil(t, review["requested_by"])
+
+	_, err = svc.rewriteRow(reviewsTable, []byte(`{"id":"r","codebase_id":"cb","workspace_id":"ws","user_id":"unknown"}`), state)
+	assert.True(t, errors.Is(err, ErrUnknownArchiveUser))
+}
+
+type fakeImporter struct {
+	rows map[string][]map[string]any
+}
+
+func (i *fakeImporter) Insert(_ context.Context, table db_exports.Table, row json.RawMessage) error {
+	var decoded map[string]any
+	if err := json.Unmarshal(row, &decoded); err != nil {
+		return err
+	}
+	i.rows[table.Name] = append(i.rows[table.Name], decoded)
+	return nil
+}
+
+func (*fakeImporter) Commit() error {
+	return nil
+}
+
+func (*fakeImporter) Rollback() error {
+	return nil
+}
+
+// archive returns a tar archive with the given tables, in order.
+func archive(t *testing.T, tables ...string) *tar.Reader {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for i := 0; i < len(tables); i += 2 {
+		name, rows := exports.TablesDirectory+tables[i]+".jsonl", tables[i+1]
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(rows)), Mode: 0o644}))
+		_, err := tw.Write([]byte(rows))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return tar.NewReader(buf)
+}
+
+func TestImportEntries_shouldMoveRowsToTheImportedCodebase(t *testing.T) {
+	svc := &Service{codebaseRepo: db_codebases.NewMemory()}
+	importer := &fakeImporter{rows: map[string][]map[string]any{}}
+
+	tr := archive(t,
+		"codebases", `{"id":"victim","short_id":"short"}`+"\n",
+		"acls", `{"id":"acl","codebase_id":"victim"}`+"\n",
+		"comments", `{"id":"comment","codebase_id":"victim","workspace_id":null,"user_id":null}`+"\n",
+	)
+	assert.NoError(t, svc.importEntries(context.Background(), tr, importer, newImportState("cb", "org", nil), t.TempDir()))
+
+	assert.Equal(t, "cb", importer.rows["codebases"][0]["id"])
+	assert.Equal(t, "cb", importer.rows["acls"][0]["codebase_id"])
+	assert.Equal(t, "cb", importer.rows["comments"][0]["codebase_id"])
+}
+
+func TestImportEntries_shouldRejectForeignReferences(t *testing.T) {
+	cases := []struct {
+		name   string
+		tables []string
+	}{
+		{
+			name: "acl of another codebase",
+			tables: []string{
+				"acls", `{"id":"acl","codebase_id":"cb"}` + "\n",
+				"acl_versions", `{"id":"version","acl_id":"victim-acl","author_id":null}` + "\n",
+			},
+		},
+		{
+			name: "workspace of another codebase",
+			tables: []string{
+				"workspaces", `{"id":"ws","codebase_id":"cb","user_id":null}` + "\n",
+				"comments", `{"id":"comment","codebase_id":"cb","workspace_id":"victim-ws","user_id":null}` + "\n",
+			},
+		},
+		{
+			name: "suggestion for a workspace of another codebase",
+			tables: []string{
+				"workspaces", `{"id":"ws","codebase_id":"cb","user_id":null}` + "\n",
+				"suggestions_v2", `{"id":"s","codebase_id":"cb","workspace_id":"ws","for_workspace_id":"victim-ws","user_id":null}` + "\n",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &Service{codebaseRepo: db_codebases.NewMemory()}
+			importer := &fakeImporter{rows: map[string][]map[string]any{}}
+
+			err := svc.importEntries(context.Background(), archive(t, tc.tables...), importer, newImportState("cb", "org", nil), t.TempDir())
+			assert.True(t, errors.Is(err, ErrForeignReference), err)
+		})
+	}
+}
@@ -3,15 +3,18 @@ package graphql
 import (
 	"getsturdy.com/api/pkg/github/enterprise/config"
 	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/oidc"
 )
 
 type FeaturesRootResolver struct {
 	githubConfig *config.GitHubAppConfig
+	oidcConfig   *oidc.Configuration
 }
 
-func NewFeaturesRootResolver(githubConfig *config.GitHubAppConfig) resolvers.FeaturesRootResolver {
+func NewFeaturesRootResolver(githubConfig *config.GitHubAppConfig, oidcConfig *oidc.Configuration) resolvers.FeaturesRootResolver {
 	return &FeaturesRootResolver{
 		githubConfig: githubConfig,
+		oidcConfig:   oidcConfig,
 	}
 }
 
@@ -33,5 +36,11 @@ func (r *FeaturesRootResolver) Features() []resolvers.Feature {
 	} else {
 		ff = append(ff, resolvers.FeatureGitHubNotConfigured)
 	}
+	if r.oidcConfig.Enabled() {
+		ff = append(ff, resolvers.FeatureSingleSignOn)
+	}
+	if !r.oidcConfig.PasswordLoginEnabled() {
+		ff = append(ff, resolvers.FeaturePasswordLoginDisabled)
+	}
 	return ff
 }
@@ -20,6 +20,8 @@ import (
 	service_file "getsturdy.com/api/pkg/file/service"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/internal/inmemory"
+	db_teams "getsturdy.com/api/pkg/organization/teams/db"
+	service_teams "getsturdy.com/api/pkg/organization/teams/service"
 	db_users "getsturdy.com/api/pkg/users/db"
 	service_user "getsturdy.com/api/pkg/users/service"
 	"getsturdy.com/api/vcs"
@@ -52,11 +54,16 @@ func TestFileResolver(t *testing.T) {
 		zap.NewNop(),
 		userRepo,
 		nil,
+		nil,
+		nil,
 	)
 
 	aclProvider := provider_acl.New(
 		aclRepo,
+		inmemory.NewInMemoryAclVersionRepo(aclRepo),
 		nil,
+		userService,
+		service_teams.New(db_teams.NewMemory(), db_teams.NewMemberMemory(), nil),
 		nil,
 	)
 
@@ -67,6 +74,7 @@ func TestFileResolver(t *testing.T) {
 		nil,
 		aclProvider,
 		nil,
+		nil,
 	)
 
 	fileService := service_file.New(executorProvider, nil, nil)
@@ -12,8 +12,19 @@ import (
 )
 
 type Repository interface {
-	ListSince(ctx context.Context, codebaseID codebases.ID, since time.Time) ([]*gc.CodebaseGarbageStatus, error)
-	Create(context.Context, *gc.CodebaseGarbageStatus) error
+	CreateRun(context.Context, *gc.Run) error
+	UpdateRun(context.Context, *gc.Run) error
+	GetRun(context.Context, gc.RunID) (*gc.Run, error)
+	// ListRuns returns the latest runs in the codebase, newest first.
+	ListRuns(ctx context.Context, codebaseID codebases.ID, limit int) ([]*gc.Run, error)
+	// ListCompletedSince returns the runs that were completed after since, dry runs are not included.
+	ListCompletedSince(ctx context.Context, codebaseID codebases.ID, since time.Time) ([]*gc.Run, error)
+
+	CreateItems(context.Context, []*gc.Item) error
+	ListItems(context.Context, gc.RunID) ([]*gc.Item, error)
+
+	GetPolicy(context.Context, codebases.ID) (*gc.Policy, error)
+	UpsertPolicy(context.Context, *gc.Policy) error
 }
 
 type repo struct {
@@ -24,17 +35,87 @@ func NewRepository(db *sqlx.DB) Repository {
 	return &repo{db: db}
 }
 
-func (r *repo) ListSince(ctx context.Context, codebaseID codebases.ID, since time.Time) ([]*gc.CodebaseGarbageStatus, error) {
-	var res []*gc.CodebaseGarbageStatus
+const runColumns = `
+	id,
+	codebase_id,
+	dry_run,
+	triggered_by,
+	created_at,
+	started_at,
+	completed_at,
+	duration_millis,
+	bytes_reclaimed,
+	errors`
+
+func (r *repo) CreateRun(ctx context.Context, run *gc.Run) error {
+	if _, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO codebases_garbage_collection_runs
+			(id, codebase_id, dry_run, triggered_by, created_at, started_at, completed_at, duration_millis, bytes_reclaimed, errors)
+		VALUES
+			(:id, :codebase_id, :dry_run, :triggered_by, :created_at, :started_at, :completed_at, :duration_millis, :bytes_reclaimed, :errors)
+	`, run); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) UpdateRun(ctx context.Context, run *gc.Run) error {
+	if _, err := r.db.NamedExecContext(ctx, `
+		UPDATE codebases_garbage_collection_runs
+		SET
+			started_at = :started_at,
+			completed_at = :completed_at,
+			duration_millis = :duration_millis,
+			bytes_reclaimed = :bytes_reclaimed,
+			errors = :errors
+		WHERE
+			id = :id
+	`, run); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) GetRun(ctx context.Context, id gc.RunID) (*gc.Run, error) {
+	var res gc.Run
+	if err := r.db.GetContext(ctx, &res, `
+		SELECT`+runColumns+`
+		FROM
+			codebases_garbage_collection_runs
+		WHERE
+			id = $1
+	`, id); err != nil {
+		return nil, fmt.Errorf("failed to get: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *repo) ListRuns(ctx context.Context, codebaseID codebases.ID, limit int) ([]*gc.Run, error) {
+	var res []*gc.Run
 	if err := r.db.SelectContext(ctx, &res, `
-		SELECT
-			codebase_id,
-			completed_at,
-			duration_millis
+		SELECT`+runColumns+`
 		FROM
-			codebases_garbage_collection_status
+			codebases_garbage_collection_runs
 		WHERE
 			codebase_id = $1
+		ORDER BY
+			created_at DESC
+		LIMIT $2
+	`, codebaseID, limit); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
+
+func (r *repo) ListCompletedSince(ctx context.Context, codebaseID codebases.ID, since time.Time) ([]*gc.Run, error) {
+	var res []*gc.Run
+	if err := r.db.SelectContext(ctx, &res, `
+		SELECT`+runColumns+`
+		FROM
+			codebases_garbage_collection_runs
+		WHERE
+			codebase_id = $1
+			AND NOT dry_run
 			AND completed_at > $2
 	`, codebaseID, since); err != nil {
 		return nil, fmt.Errorf("failed to select: %w", err)
@@ -42,31 +123,72 @@ func (r *repo) ListSince(ctx context.Context, codebaseID codebases.ID, since tim
 	return res, nil
 }
 
-func (r *repo) Create(ctx context.Context, status *gc.CodebaseGarbageStatus) error {
+func (r *repo) CreateItems(ctx context.Context, items []*gc.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
 	if _, err := r.db.NamedExecContext(ctx, `
-		INSERT INTO codebases_garbage_collection_status 
-			(codebase_id, completed_at, duration_millis)
+		INSERT INTO codebases_garbage_collection_run_items
+			(run_id, type, name, view_id)
 		VALUES
-			(:codebase_id, :completed_at, :duration_millis)
-	`, status); err != nil {
+			(:run_id, :type, :name, :view_id)
+	`, items); err != nil {
 		return fmt.Errorf("failed to insert: %w", err)
 	}
 	return nil
 }
 
-func (r *repo) GetByCodebaseID(ctx context.Context, codebaseID string) (*gc.CodebaseGarbageStatus, error) {
-	var res gc.CodebaseGarbageStatus
-	if err := r.db.Get(&res, `
-		SELECT 
+func (r *repo) ListItems(ctx context.Context, runID gc.RunID) ([]*gc.Item, error) {
+	var res []*gc.Item
+	if err := r.db.SelectContext(ctx, &res, `
+		SELECT
+			run_id,
+			type,
+			name,
+			view_id
+		FROM
+			codebases_garbage_collection_run_items
+		WHERE
+			run_id = $1
+	`, runID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
+
+func (r *repo) GetPolicy(ctx context.Context, codebaseID codebases.ID) (*gc.Policy, error) {
+	var res gc.Policy
+	if err := r.db.GetContext(ctx, &res, `
+		SELECT
 			codebase_id,
-			completed_at,
-			duration_millis
-		FROM 
-			codebases_garbage_collection_status
-		WHERE 
+			run_interval,
+			snapshot_retention,
+			updated_by,
+			updated_at
+		FROM
+			codebases_garbage_collection_policies
+		WHERE
 			codebase_id = $1
 	`, codebaseID); err != nil {
 		return nil, fmt.Errorf("failed to get: %w", err)
 	}
 	return &res, nil
 }
+
+func (r *repo) UpsertPolicy(ctx context.Context, policy *gc.Policy) error {
+	if _, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO codebases_garbage_collection_policies
+			(codebase_id, run_interval, snapshot_retention, updated_by, updated_at)
+		VALUES
+			(:codebase_id, :run_interval, :snapshot_retention, :updated_by, :updated_at)
+		ON CONFLICT (codebase_id) DO UPDATE
+		SET
+			run_interval = :run_interval,
+			snapshot_retention = :snapshot_retention,
+			updated_by = :updated_by,
+			updated_at = :updated_at
+	`, policy); err != nil {
+		return fmt.Errorf("failed to upsert: %w", err)
+	}
+	return nil
+}
@@ -4,10 +4,92 @@ import (
 	"time"
 
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultInterval          = time.Hour
+	DefaultSnapshotRetention = 3 * time.Hour
+)
+
+// Policy controls how garbage is collected in a codebase. Codebases without a policy use DefaultPolicy.
+type Policy struct {
+	CodebaseID codebases.ID `db:"codebase_id"`
+	// Interval is the minimum time between two scheduled runs.
+	Interval time.Duration `db:"run_interval"`
+	// SnapshotRetention is how long snapshots are kept. Older snapshots are removed, unless they are in use.
+	SnapshotRetention time.Duration `db:"snapshot_retention"`
+	UpdatedBy         *users.ID     `db:"updated_by"`
+	UpdatedAt         *time.Time    `db:"updated_at"`
+}
+
+func DefaultPolicy(codebaseID codebases.ID) *Policy {
+	return &Policy{
+		CodebaseID:        codebaseID,
+		Interval:          DefaultInterval,
+		SnapshotRetention: DefaultSnapshotRetention,
+	}
+}
+
+type RunID string
+
+func (id RunID) String() string {
+	return string(id)
+}
+
+type RunStatus string
+
+const (
+	RunStatusPending   RunStatus = "pending"
+	RunStatusRunning   RunStatus = "running"
+	RunStatusCompleted RunStatus = "completed"
+)
+
+// Run is a record of garbage being collected in a codebase.
+type Run struct {
+	ID         RunID        `db:"id"`
+	CodebaseID codebases.ID `db:"codebase_id"`
+	// DryRun runs list what would be removed, without removing anything.
+	DryRun bool `db:"dry_run"`
+	// TriggeredBy is the user that started the run, it's nil for scheduled runs.
+	TriggeredBy    *users.ID  `db:"triggered_by"`
+	CreatedAt      time.Time  `db:"created_at"`
+	StartedAt      *time.Time `db:"started_at"`
+	CompletedAt    *time.Time `db:"completed_at"`
+	DurationMillis int64      `db:"duration_millis"`
+	// BytesReclaimed is how much smaller the repositories of the codebase got, it's always 0 for dry runs.
+	BytesReclaimed int64 `db:"bytes_reclaimed"`
+	// Errors are the failures that did not stop the run.
+	Errors pq.StringArray `db:"errors"`
+}
+
+func (r *Run) Status() RunStatus {
+	switch {
+	case r.CompletedAt != nil:
+		return RunStatusCompleted
+	case r.StartedAt != nil:
+		return RunStatusRunning
+	default:
+		return RunStatusPending
+	}
+}
+
+type ItemType string
+
+const (
+	ItemTypeSnapshot    ItemType = "snapshot"
+	ItemTypeTrunkBranch ItemType = "trunk_branch"
+	ItemTypeViewBranch  ItemType = "view_branch"
 )
 
-type CodebaseGarbageStatus struct {
-	CodebaseID     codebases.ID `db:"codebase_id"`
-	CompletedAt    time.Time    `db:"completed_at"`
-	DurationMillis int64        `db:"duration_millis"`
+// Item is something that was, or would be for dry runs, removed by a run.
+type Item struct {
+	RunID RunID    `db:"run_id"`
+	Type  ItemType `db:"type"`
+	// Name is the ID of the snapshot, or the name of the branch.
+	Name string `db:"name"`
+	// ViewID is set for view branches.
+	ViewID *string `db:"view_id"`
 }
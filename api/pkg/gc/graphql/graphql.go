@@ -0,0 +1,312 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/gc"
+	service_gc "getsturdy.com/api/pkg/gc/service"
+	worker_gc "getsturdy.com/api/pkg/gc/worker"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const defaultRunsLimit = 20
+
+type rootResolver struct {
+	gcService       *service_gc.Service
+	gcQueue         *worker_gc.Queue
+	codebaseService *service_codebase.Service
+	authService     *service_auth.Service
+
+	authorRootResolver resolvers.AuthorRootResolver
+}
+
+func New(
+	gcService *service_gc.Service,
+	gcQueue *worker_gc.Queue,
+	codebaseService *service_codebase.Service,
+	authService *service_auth.Service,
+
+	authorRootResolver resolvers.AuthorRootResolver,
+) resolvers.GarbageCollectionRootResolver {
+	return &rootResolver{
+		gcService:       gcService,
+		gcQueue:         gcQueue,
+		codebaseService: codebaseService,
+		authService:     authService,
+
+		authorRootResolver: authorRootResolver,
+	}
+}
+
+// canAdministrate returns the codebase if the user can manage garbage collection in it. Garbage collection is
+// managed by the administrators of the installation, that can also write to the codebase.
+func (r *rootResolver) canAdministrate(ctx context.Context, codebaseID codebases.ID) (*codebases.Codebase, error) {
+	subject, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	if subject.IsPersonalAccessToken() {
+		return nil, auth.ErrForbidden
+	}
+
+	codebase, err := r.codebaseService.GetByID(ctx, codebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codebase: %w", err)
+	}
+
+	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.authService.CanAdministrateInstallation(ctx); err != nil {
+		return nil, err
+	}
+
+	return codebase, nil
+}
+
+func (r *rootResolver) GarbageCollectionRun(ctx context.Context, args resolvers.GarbageCollectionRunArgs) (resolvers.GarbageCollectionRunResolver, error) {
+	run, err := r.gcService.GetRun(ctx, gc.RunID(args.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if _, err := r.canAdministrate(ctx, run.CodebaseID); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return &runResolver{root: r, run: run}, nil
+}
+
+func (r *rootResolver) InternalPolicyByCodebaseID(ctx context.Context, codebaseID graphql.ID) (resolvers.GarbageCollectionPolicyResolver, error) {
+	codebase, err := r.canAdministrate(ctx, codebases.ID(codebaseID))
+	if err != nil {
+		// only administrators can see the policy
+		return nil, nil
+	}
+
+	policy, err := r.gcService.GetPolicy(ctx, codebase.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return &policyResolver{root: r, policy: policy}, nil
+}
+
+func (r *rootResolver) InternalListRunsByCodebaseID(ctx context.Context, codebaseID graphql.ID, last *int32) ([]resolvers.GarbageCollectionRunResolver, error) {
+	codebase, err := r.canAdministrate(ctx, codebases.ID(codebaseID))
+	if err != nil {
+		// only administrators can see the runs
+		return []resolvers.GarbageCollectionRunResolver{}, nil
+	}
+
+	limit := defaultRunsLimit
+	if last != nil {
+		limit = int(*last)
+	}
+
+	runs, err := r.gcService.ListRuns(ctx, codebase.ID, limit)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.GarbageCollectionRunResolver, 0, len(runs))
+	for _, run := range runs {
+		res = append(res, &runResolver{root: r, run: run})
+	}
+	return res, nil
+}
+
+func (r *rootResolver) TriggerGarbageCollection(ctx context.Context, args resolvers.TriggerGarbageCollectionArgs) (resolvers.GarbageCollectionRunResolver, error) {
+	codebase, err := r.canAdministrate(ctx, codebases.ID(args.Input.CodebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	dryRun := args.Input.DryRun != nil && *args.Input.DryRun
+
+	run, err := r.gcService.CreateRun(ctx, codebase.ID, userID, dryRun)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.gcQueue.EnqueueRun(ctx, run); err != nil {
+		return nil, gqlerror.Error(fmt.Errorf("failed to enqueue run: %w", err))
+	}
+
+	return &runResolver{root: r, run: run}, nil
+}
+
+func (r *rootResolver) UpdateGarbageCollectionPolicy(ctx context.Context, args resolvers.UpdateGarbageCollectionPolicyArgs) (resolvers.GarbageCollectionPolicyResolver, error) {
+	codebase, err := r.canAdministrate(ctx, codebases.ID(args.Input.CodebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	policy, err := r.gcService.UpdatePolicy(ctx, codebase.ID, userID,
+		time.Duration(args.Input.IntervalSeconds)*time.Second,
+		time.Duration(args.Input.SnapshotRetentionSeconds)*time.Second,
+	)
+	switch {
+	case err == nil:
+	case errors.Is(err, service_gc.ErrInvalidInterval):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "intervalSeconds", err.Error())
+	case errors.Is(err, service_gc.ErrInvalidSnapshotRetention):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "snapshotRetentionSeconds", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &policyResolver{root: r, policy: policy}, nil
+}
+
+type policyResolver struct {
+	root   *rootResolver
+	policy *gc.Policy
+}
+
+func (r *policyResolver) IntervalSeconds() int32 {
+	return int32(r.policy.Interval.Seconds())
+}
+
+func (r *policyResolver) SnapshotRetentionSeconds() int32 {
+	return int32(r.policy.SnapshotRetention.Seconds())
+}
+
+func (r *policyResolver) UpdatedAt() *int32 {
+	return unixPtr(r.policy.UpdatedAt)
+}
+
+func (r *policyResolver) UpdatedBy(ctx context.Context) (resolvers.AuthorResolver, error) {
+	if r.policy.UpdatedBy == nil {
+		return nil, nil
+	}
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(*r.policy.UpdatedBy))
+}
+
+type runResolver struct {
+	root *rootResolver
+	run  *gc.Run
+}
+
+func (r *runResolver) ID() graphql.ID {
+	return graphql.ID(r.run.ID)
+}
+
+func (r *runResolver) DryRun() bool {
+	return r.run.DryRun
+}
+
+func (r *runResolver) Status() (resolvers.GarbageCollectionRunStatus, error) {
+	switch status := r.run.Status(); status {
+	case gc.RunStatusPending:
+		return resolvers.GarbageCollectionRunStatusPending, nil
+	case gc.RunStatusRunning:
+		return resolvers.GarbageCollectionRunStatusRunning, nil
+	case gc.RunStatusCompleted:
+		return resolvers.GarbageCollectionRunStatusCompleted, nil
+	default:
+		return resolvers.GarbageCollectionRunStatusUndefined, fmt.Errorf("unknown run status: %s", status)
+	}
+}
+
+func (r *runResolver) TriggeredBy(ctx context.Context) (resolvers.AuthorResolver, error) {
+	if r.run.TriggeredBy == nil {
+		return nil, nil
+	}
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(*r.run.TriggeredBy))
+}
+
+func (r *runResolver) CreatedAt() int32 {
+	return int32(r.run.CreatedAt.Unix())
+}
+
+func (r *runResolver) StartedAt() *int32 {
+	return unixPtr(r.run.StartedAt)
+}
+
+func (r *runResolver) CompletedAt() *int32 {
+	return unixPtr(r.run.CompletedAt)
+}
+
+func (r *runResolver) DurationMs() int32 {
+	return int32(r.run.DurationMillis)
+}
+
+func (r *runResolver) BytesReclaimed() float64 {
+	return float64(r.run.BytesReclaimed)
+}
+
+func (r *runResolver) Errors() []string {
+	return r.run.Errors
+}
+
+func (r *runResolver) Items(ctx context.Context) ([]resolvers.GarbageCollectionItemResolver, error) {
+	items, err := r.root.gcService.ListItems(ctx, r.run.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.GarbageCollectionItemResolver, 0, len(items))
+	for _, item := range items {
+		res = append(res, &itemResolver{item: item})
+	}
+	return res, nil
+}
+
+type itemResolver struct {
+	item *gc.Item
+}
+
+func (r *itemResolver) Type() (resolvers.GarbageCollectionItemType, error) {
+	switch r.item.Type {
+	case gc.ItemTypeSnapshot:
+		return resolvers.GarbageCollectionItemTypeSnapshot, nil
+	case gc.ItemTypeTrunkBranch:
+		return resolvers.GarbageCollectionItemTypeTrunkBranch, nil
+	case gc.ItemTypeViewBranch:
+		return resolvers.GarbageCollectionItemTypeViewBranch, nil
+	default:
+		return resolvers.GarbageCollectionItemTypeUndefined, fmt.Errorf("unknown item type: %s", r.item.Type)
+	}
+}
+
+func (r *itemResolver) Name() string {
+	return r.item.Name
+}
+
+func (r *itemResolver) ViewID() *graphql.ID {
+	if r.item.ViewID == nil {
+		return nil
+	}
+	id := graphql.ID(*r.item.ViewID)
+	return &id
+}
+
+func unixPtr(t *time.Time) *int32 {
+	if t == nil {
+		return nil
+	}
+	unix := int32(t.Unix())
+	return &unix
+}
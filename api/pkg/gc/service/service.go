@@ -5,24 +5,36 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/provider"
 
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/gc"
 	"getsturdy.com/api/pkg/gc/db"
 	"getsturdy.com/api/pkg/snapshots"
 	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
 	service_suggestion "getsturdy.com/api/pkg/suggestions/service"
+	"getsturdy.com/api/pkg/users"
 	db_view "getsturdy.com/api/pkg/view/db"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
 	"getsturdy.com/api/vcs/executor"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+var (
+	ErrInvalidInterval          = errors.New("interval must be at least one minute")
+	ErrInvalidSnapshotRetention = errors.New("snapshot retention can not be negative")
+)
+
 type Service struct {
 	logger            *zap.Logger
 	gcRepo            db.Repository
@@ -30,6 +42,7 @@ type Service struct {
 	snapshotsRepo     db_snapshots.Repository
 	workspaceReader   db_workspaces.WorkspaceReader
 	suggestionService *service_suggestion.Service
+	auditService      *service_audit.Service
 	executorProvider  executor.Provider
 }
 
@@ -40,6 +53,7 @@ func New(
 	snapshotsRepo db_snapshots.Repository,
 	workspaceReader db_workspaces.WorkspaceReader,
 	suggestionService *service_suggestion.Service,
+	auditService *service_audit.Service,
 	executorProvider executor.Provider,
 ) *Service {
 	return &Service{
@@ -49,13 +63,377 @@ func New(
 		snapshotsRepo:     snapshotsRepo,
 		workspaceReader:   workspaceReader,
 		suggestionService: suggestionService,
+		auditService:      auditService,
 		executorProvider:  executorProvider,
 	}
 }
 
-func (svc *Service) gcSnapshots(ctx context.Context, codebaseID codebases.ID, snapshotThreshold time.Duration) error {
+// GetPolicy returns the policy of the codebase, or the default policy if the codebase doesn't have one.
+func (svc *Service) GetPolicy(ctx context.Context, codebaseID codebases.ID) (*gc.Policy, error) {
+	policy, err := svc.gcRepo.GetPolicy(ctx, codebaseID)
+	switch {
+	case err == nil:
+		return policy, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return gc.DefaultPolicy(codebaseID), nil
+	default:
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+}
+
+func (svc *Service) UpdatePolicy(ctx context.Context, codebaseID codebases.ID, updatedBy users.ID, interval, snapshotRetention time.Duration) (*gc.Policy, error) {
+	if interval < time.Minute {
+		return nil, ErrInvalidInterval
+	}
+	if snapshotRetention < 0 {
+		return nil, ErrInvalidSnapshotRetention
+	}
+
+	before, err := svc.GetPolicy(ctx, codebaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	policy := &gc.Policy{
+		CodebaseID:        codebaseID,
+		Interval:          interval,
+		SnapshotRetention: snapshotRetention,
+		UpdatedBy:         &updatedBy,
+		UpdatedAt:         &now,
+	}
+	if err := svc.gcRepo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to update policy: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionGarbageCollectionPolicyUpdated, audit.TargetCodebase, codebaseID.String(),
+		audit.CodebaseID(codebaseID),
+		audit.Before(before),
+		audit.After(policy),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return policy, nil
+}
+
+// CreateRun creates a pending run, that is started when it's passed to WorkRun.
+func (svc *Service) CreateRun(ctx context.Context, codebaseID codebases.ID, triggeredBy users.ID, dryRun bool) (*gc.Run, error) {
+	run := &gc.Run{
+		ID:          gc.RunID(uuid.NewString()),
+		CodebaseID:  codebaseID,
+		DryRun:      dryRun,
+		TriggeredBy: &triggeredBy,
+		CreatedAt:   time.Now(),
+	}
+	if err := svc.gcRepo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionGarbageCollectionTriggered, audit.TargetCodebase, codebaseID.String(),
+		audit.CodebaseID(codebaseID),
+		audit.After(run),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return run, nil
+}
+
+func (svc *Service) GetRun(ctx context.Context, id gc.RunID) (*gc.Run, error) {
+	return svc.gcRepo.GetRun(ctx, id)
+}
+
+// ListRuns returns the latest runs in the codebase, newest first.
+func (svc *Service) ListRuns(ctx context.Context, codebaseID codebases.ID, limit int) ([]*gc.Run, error) {
+	runs, err := svc.gcRepo.ListRuns(ctx, codebaseID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	return runs, nil
+}
+
+// ListItems returns what was removed by the run, or what would be removed if it's a dry run.
+func (svc *Service) ListItems(ctx context.Context, runID gc.RunID) ([]*gc.Item, error) {
+	items, err := svc.gcRepo.ListItems(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	return items, nil
+}
+
+// recorder collects what a run removes, and the errors that it ignores.
+type recorder struct {
+	logger *zap.Logger
+	run    *gc.Run
+	items  []*gc.Item
+}
+
+func (r *recorder) add(itemType gc.ItemType, name string, viewID *string) {
+	r.items = append(r.items, &gc.Item{
+		RunID:  r.run.ID,
+		Type:   itemType,
+		Name:   name,
+		ViewID: viewID,
+	})
+}
+
+// fail logs and records err, without stopping the run.
+func (r *recorder) fail(logger *zap.Logger, msg string, err error) {
+	logger.Error(msg, zap.Error(err))
+	r.run.Errors = append(r.run.Errors, fmt.Sprintf("%s: %s", msg, err))
+}
+
+type Options struct {
+	// Interval is the minimum time between two runs. It's ignored for dry runs.
+	Interval time.Duration
+	// SnapshotRetention is how long snapshots are kept.
+	SnapshotRetention time.Duration
+	// DryRun records what would be removed, without removing anything.
+	DryRun      bool
+	TriggeredBy *users.ID
+}
+
+// Work collects garbage in the codebase, following the policy of the codebase.
+func (svc *Service) Work(
+	ctx context.Context,
+	logger *zap.Logger,
+	codebaseID codebases.ID,
+) error {
+	policy, err := svc.GetPolicy(ctx, codebaseID)
+	if err != nil {
+		return err
+	}
+	if _, err := svc.WorkWithOptions(ctx, logger, codebaseID, Options{
+		Interval:          policy.Interval,
+		SnapshotRetention: policy.SnapshotRetention,
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WorkWithOptions collects garbage in the codebase, and returns the record of the run. If garbage was collected
+// within the interval, the run is skipped, and nil is returned.
+func (svc *Service) WorkWithOptions(
+	ctx context.Context,
+	logger *zap.Logger,
+	codebaseID codebases.ID,
+	opts Options,
+) (*gc.Run, error) {
+	now := time.Now()
+
+	if !opts.DryRun {
+		// Skip if recently run
+		runs, err := svc.gcRepo.ListCompletedSince(ctx, codebaseID, now.Add(-1*opts.Interval))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last runs: %w", err)
+		}
+		if len(runs) > 0 {
+			logger.Sugar().Infof("skipping gc ran in the last %s", opts.Interval)
+			return nil, nil
+		}
+	}
+
+	run := &gc.Run{
+		ID:          gc.RunID(uuid.NewString()),
+		CodebaseID:  codebaseID,
+		DryRun:      opts.DryRun,
+		TriggeredBy: opts.TriggeredBy,
+		CreatedAt:   now,
+	}
+	if err := svc.gcRepo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+
+	if err := svc.collect(ctx, logger, run, opts.SnapshotRetention); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// WorkRun starts a run that was created with CreateRun, using the snapshot retention of the codebase policy.
+func (svc *Service) WorkRun(ctx context.Context, logger *zap.Logger, runID gc.RunID) error {
+	run, err := svc.gcRepo.GetRun(ctx, runID)
+	if err != nil {
+		return fmt.Errorf("failed to get run: %w", err)
+	}
+	if run.Status() != gc.RunStatusPending {
+		logger.Info("skipping gc run that has already been started", zap.Stringer("run_id", run.ID))
+		return nil
+	}
+
+	policy, err := svc.GetPolicy(ctx, run.CodebaseID)
+	if err != nil {
+		return err
+	}
+
+	return svc.collect(ctx, logger, run, policy.SnapshotRetention)
+}
+
+func (svc *Service) collect(ctx context.Context, logger *zap.Logger, run *gc.Run, snapshotRetention time.Duration) error {
+	logger = logger.With(zap.Stringer("run_id", run.ID), zap.Bool("dry_run", run.DryRun))
+
+	startedAt := time.Now()
+	run.StartedAt = &startedAt
+	if err := svc.gcRepo.UpdateRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to start run: %w", err)
+	}
+
+	logger.Info("starting gc")
+
+	rec := &recorder{logger: logger, run: run}
+
+	if err := svc.gcSnapshots(ctx, rec, run.CodebaseID, snapshotRetention); err != nil {
+		rec.fail(logger, "failed to gc snapshots", err)
+		// do not fail
+	}
+
+	if !run.DryRun {
+		if err := svc.gcRepositories(ctx, rec, run.CodebaseID); err != nil {
+			rec.fail(logger, "failed to gc repositories", err)
+			// do not fail
+		}
+	}
+
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	run.DurationMillis = completedAt.Sub(startedAt).Milliseconds()
+	if run.BytesReclaimed < 0 {
+		// git gc can make a repository larger, for example when it packs loose objects that are then left unreferenced
+		run.BytesReclaimed = 0
+	}
+
+	if err := svc.gcRepo.CreateItems(ctx, rec.items); err != nil {
+		return fmt.Errorf("failed to record gc run items: %w", err)
+	}
+	if err := svc.gcRepo.UpdateRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to record gc run stats: %w", err)
+	}
+
+	logger.Info("gc completed",
+		zap.Int64("bytes_reclaimed", run.BytesReclaimed),
+		zap.Int("items", len(rec.items)),
+		zap.Int("errors", len(run.Errors)),
+	)
+
+	return nil
+}
+
+// gcRepositories runs git gc on the trunk and all views of the codebase, and adds the number of bytes that
+// the repositories shrank by to the run.
+func (svc *Service) gcRepositories(ctx context.Context, rec *recorder, codebaseID codebases.ID) error {
+	logger := rec.logger
+
+	var sizeBefore, sizeAfter int64
+	if err := svc.executorProvider.New().
+		Schedule(func(repoProvider provider.RepoProvider) error {
+			sizeBefore = dirSize(repoProvider.TrunkPath(codebaseID))
+			return nil
+		}).
+		GitWrite(func(trunkRepo vcs.RepoGitWriter) error {
+			if err := trunkRepo.GitReflogExpire(); err != nil {
+				rec.fail(logger, "failed to run git-reflog expire on trunk", err)
+				// don't exit
+			}
+
+			if err := trunkRepo.GitGC(); err != nil {
+				rec.fail(logger, "failed to run git-gc on trunk", err)
+				// don't exit
+			}
+
+			logger.Info("trunk cleaned up")
+
+			return nil
+		}).
+		Schedule(func(repoProvider provider.RepoProvider) error {
+			sizeAfter = dirSize(repoProvider.TrunkPath(codebaseID))
+			return nil
+		}).
+		ExecTrunk(codebaseID, "gcTrunk"); err != nil {
+		rec.fail(logger, "failed to git gc trunk", err)
+		// don't exit
+	} else {
+		rec.run.BytesReclaimed += sizeBefore - sizeAfter
+	}
+
+	// gc all views
+	views, err := svc.viewRepo.ListByCodebase(codebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to list views: %w", err)
+	}
+
+	for _, view := range views {
+		logger := logger.With(zap.String("view_id", view.ID))
+
+		var sizeBefore, sizeAfter int64
+		if err := svc.executorProvider.New().
+			Schedule(func(repoProvider provider.RepoProvider) error {
+				sizeBefore = dirSize(repoProvider.ViewPath(view.CodebaseID, view.ID))
+				return nil
+			}).
+			GitWrite(func(viewGitRepo vcs.RepoGitWriter) error {
+				if err := viewGitRepo.GitReflogExpire(); err != nil {
+					rec.fail(logger, "failed to run git-reflog expire on view "+view.ID, err)
+					// don't exit
+				}
+
+				if err := viewGitRepo.GitGC(); err != nil {
+					rec.fail(logger, "failed to run git-gc on view "+view.ID, err)
+					// don't exit
+				}
+
+				if err := viewGitRepo.GitRemotePrune("origin"); err != nil {
+					rec.fail(logger, "failed to run git remote prune on view "+view.ID, err)
+					// don't exit
+				}
+
+				logger.Info("view cleaned up")
+				return nil
+			}).
+			Schedule(func(repoProvider provider.RepoProvider) error {
+				sizeAfter = dirSize(repoProvider.ViewPath(view.CodebaseID, view.ID))
+				return nil
+			}).
+			ExecView(view.CodebaseID, view.ID, "gcView"); err != nil {
+			// If the view is rebasing, it will be GC'd on the next run, no big deal.
+			if errors.Is(err, executor.ErrIsRebasing) {
+				logger.Warn("failed to run git gc on view", zap.Error(err))
+			} else {
+				rec.fail(logger, "failed to run git gc on view "+view.ID, err)
+			}
+		} else {
+			rec.run.BytesReclaimed += sizeBefore - sizeAfter
+		}
+	}
+
+	return nil
+}
+
+// dirSize returns the total size of the regular files in the directory, files that can't be read are skipped.
+func dirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		return nil
+	})
+	return size
+}
+
+func (svc *Service) gcSnapshots(ctx context.Context, rec *recorder, codebaseID codebases.ID, snapshotRetention time.Duration) error {
 	// Delete snapshots older than
-	threshold := time.Now().Add(snapshotThreshold)
+	threshold := time.Now().Add(-snapshotRetention)
 
 	// GC unused snapshots
 	snapshots, err := svc.snapshotsRepo.ListUndeletedInCodebase(codebaseID, threshold)
@@ -63,18 +441,19 @@ func (svc *Service) gcSnapshots(ctx context.Context, codebaseID codebases.ID, sn
 		return fmt.Errorf("could not get snapshots: %w", err)
 	}
 
-	svc.logger.Info("cleaning up snapshots", zap.Int("total_snapshots", len(snapshots)))
+	rec.logger.Info("cleaning up snapshots", zap.Int("total_snapshots", len(snapshots)))
 
 	for _, snapshot := range snapshots {
-		logger := svc.logger.With(zap.String("snapshot_id", snapshot.ID))
+		logger := rec.logger.With(zap.String("snapshot_id", snapshot.ID))
 
 		if err := svc.gcSnapshot(
 			ctx,
+			rec,
 			snapshot,
 			threshold,
 			logger,
 		); err != nil {
-			logger.Error("failed to gc snapshot", zap.Error(err))
+			rec.fail(logger, "failed to gc snapshot "+snapshot.ID, err)
 			// do not fail
 		}
 	}
@@ -109,6 +488,7 @@ func (svc *Service) isSnapshotUsedAsSuggestion(ctx context.Context, snapshot *sn
 
 func (svc *Service) gcSnapshot(
 	ctx context.Context,
+	rec *recorder,
 	snapshot *snapshots.Snapshot,
 	threshold time.Time,
 	logger *zap.Logger,
@@ -146,33 +526,64 @@ func (svc *Service) gcSnapshot(
 		return nil
 	}
 
-	// Throttle heavy operations
-	time.Sleep(time.Second / 2)
+	if !rec.run.DryRun {
+		// Throttle heavy operations
+		time.Sleep(time.Second / 2)
+	}
 
-	if err := svc.deleteSnapshotBranch(logger, snapshot); err != nil {
+	deleted, err := svc.deleteSnapshotBranch(rec, logger, snapshot)
+	if err != nil {
 		return fmt.Errorf("failed to delete snapshot id=%s: %w", snapshot.ID, err)
 	}
+	if !deleted {
+		return nil
+	}
+
+	if rec.run.DryRun {
+		rec.add(gc.ItemTypeSnapshot, snapshot.ID, nil)
+		return nil
+	}
 
 	t := time.Now()
 	snapshot.DeletedAt = &t
 	if err := svc.snapshotsRepo.Update(snapshot); err != nil {
 		return fmt.Errorf("failed to mark snapshot as deleted: %w", err)
 	}
+	rec.add(gc.ItemTypeSnapshot, snapshot.ID, nil)
 
 	return nil
 }
 
-func (svc *Service) deleteSnapshotBranch(logger *zap.Logger, snapshot *snapshots.Snapshot) error {
+func snapshotBranchName(snapshot *snapshots.Snapshot) string {
+	return "snapshot-" + snapshot.ID
+}
+
+// hasViewBranch returns true if the snapshot has a branch on the view that created it.
+func hasViewBranch(snapshot *snapshots.Snapshot) bool {
+	return snapshot.ViewID != "" && !strings.HasPrefix(snapshot.ViewID, "tmp-")
+}
+
+// deleteSnapshotBranch deletes the branches of the snapshot, and returns false if the snapshot is in use and was
+// skipped. Dry runs only record the branches that would be deleted.
+func (svc *Service) deleteSnapshotBranch(rec *recorder, logger *zap.Logger, snapshot *snapshots.Snapshot) (bool, error) {
 	logger.Info("deleting snapshot")
 
 	if ws, err := svc.workspaceReader.GetBySnapshotID(snapshot.ID); err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("could not get workspace by snapshot: %w", err)
+		return false, fmt.Errorf("could not get workspace by snapshot: %w", err)
 	} else if err == nil && !ws.IsArchived() {
 		logger.Info("snapshot is in use by non-archived workspace, skipping", zap.String("workspace_id", ws.ID))
-		return nil
+		return false, nil
 	}
 
-	snapshotBranchName := "snapshot-" + snapshot.ID
+	snapshotBranchName := snapshotBranchName(snapshot)
+
+	if rec.run.DryRun {
+		rec.add(gc.ItemTypeTrunkBranch, snapshotBranchName, nil)
+		if hasViewBranch(snapshot) {
+			rec.add(gc.ItemTypeViewBranch, snapshotBranchName, &snapshot.ViewID)
+		}
+		return true, nil
+	}
 
 	// Delete branch on trunk
 	if err := svc.executorProvider.New().GitWrite(func(trunkRepo vcs.RepoGitWriter) error {
@@ -181,16 +592,17 @@ func (svc *Service) deleteSnapshotBranch(logger *zap.Logger, snapshot *snapshots
 		}
 
 		logger.Info("trunk branch deleted", zap.String("branch_name", snapshotBranchName))
+		rec.add(gc.ItemTypeTrunkBranch, snapshotBranchName, nil)
 
 		return nil
 	}).ExecTrunk(snapshot.CodebaseID, "deleteTrunkSnapshot"); err != nil {
-		logger.Error("failed to delete snapshot on trunk", zap.Error(err))
+		rec.fail(logger, "failed to delete snapshot on trunk", err)
 		// do not fail
-		return nil
+		return true, nil
 	}
 
 	// Delete branch on the view that created the snapshot
-	if snapshot.ViewID != "" && !strings.HasPrefix(snapshot.ViewID, "tmp-") {
+	if hasViewBranch(snapshot) {
 		if err := svc.executorProvider.New().
 			AllowRebasingState(). // allowed to enable branch deletion even if the view is currently rebasing
 			GitWrite(func(viewGitRepo vcs.RepoGitWriter) error {
@@ -199,124 +611,14 @@ func (svc *Service) deleteSnapshotBranch(logger *zap.Logger, snapshot *snapshots
 				}
 
 				logger.Info("view branch deleted", zap.String("branch_name", snapshotBranchName), zap.String("view_id", snapshot.ViewID))
+				rec.add(gc.ItemTypeViewBranch, snapshotBranchName, &snapshot.ViewID)
 
 				return nil
 			}).ExecView(snapshot.CodebaseID, snapshot.ViewID, "deleteViewSnapshot"); err != nil {
-			logger.Error("failed to open view", zap.Error(err))
-			return nil
-		}
-	}
-
-	return nil
-}
-
-func getGCInterval() time.Duration {
-	return time.Hour
-}
-
-func getSnapshotThreshold() time.Duration {
-	return -3 * time.Hour
-}
-
-func (svc *Service) Work(
-	ctx context.Context,
-	logger *zap.Logger,
-	codebaseID codebases.ID,
-) error {
-	return svc.WorkWithOptions(ctx, logger, codebaseID, getGCInterval(), getSnapshotThreshold())
-}
-
-func (svc *Service) WorkWithOptions(
-	ctx context.Context,
-	logger *zap.Logger,
-	codebaseID codebases.ID,
-	gcInterval time.Duration,
-	gcSnapshotsThreshold time.Duration,
-) error {
-	t0 := time.Now()
-
-	// Skip if recently run
-	entries, err := svc.gcRepo.ListSince(ctx, codebaseID, t0.Add(-1*gcInterval))
-	if err != nil {
-		return fmt.Errorf("failed to get last runs: %w", err)
-
-	}
-	if len(entries) > 0 {
-		logger.Sugar().Infof("skipping gc ran in the last %s", gcInterval)
-		return nil
-	}
-
-	logger.Info("starting gc")
-
-	if err := svc.gcSnapshots(ctx, codebaseID, gcSnapshotsThreshold); err != nil {
-		logger.Error("failed to gc snapshots", zap.Error(err))
-		// do not fail
-	}
-
-	if err := svc.executorProvider.New().GitWrite(func(trunkRepo vcs.RepoGitWriter) error {
-		if err := trunkRepo.GitReflogExpire(); err != nil {
-			logger.Error("failed to run git-reflog expire on trunk", zap.Error(err))
-			// don't exit
-		}
-
-		if err := trunkRepo.GitGC(); err != nil {
-			logger.Error("failed to run git-gc on trunk", zap.Error(err))
-			// don't exit
+			rec.fail(logger, "failed to delete snapshot on view "+snapshot.ViewID, err)
+			return true, nil
 		}
-
-		logger.Info("trunk cleaned up")
-
-		return nil
-	}).ExecTrunk(codebaseID, "gcTrunk"); err != nil {
-		logger.Error("failed to git gc trunk", zap.Error(err))
-		// don't exit
 	}
 
-	// gc all views
-	views, err := svc.viewRepo.ListByCodebase(codebaseID)
-	if err != nil {
-		return err
-	}
-
-	for _, view := range views {
-		logger := logger.With(zap.String("view_id", view.ID))
-
-		if err := svc.executorProvider.New().GitWrite(func(viewGitRepo vcs.RepoGitWriter) error {
-			if err := viewGitRepo.GitReflogExpire(); err != nil {
-				logger.Error("failed to run git-reflog expire on trunk", zap.Error(err))
-				// don't exit
-			}
-
-			if err := viewGitRepo.GitGC(); err != nil {
-				logger.Error("failed to run git-gc on view", zap.Error(err))
-				// don't exit
-			}
-
-			if err := viewGitRepo.GitRemotePrune("origin"); err != nil {
-				logger.Error("failed to run git remote prune on view", zap.Error(err))
-				// don't exit
-			}
-
-			logger.Info("view cleaned up")
-			return nil
-		}).ExecView(view.CodebaseID, view.ID, "gcView"); err != nil {
-			// If the view is rebasing, it will be GC'd on the next run, no big deal.
-			if errors.Is(err, executor.ErrIsRebasing) {
-				logger.Warn("failed to run git gc on view", zap.Error(err))
-			} else {
-				logger.Error("failed to run git gc on view", zap.Error(err))
-			}
-		}
-	}
-
-	now := time.Now()
-	if err := svc.gcRepo.Create(ctx, &gc.CodebaseGarbageStatus{
-		CodebaseID:     codebaseID,
-		CompletedAt:    now,
-		DurationMillis: now.Sub(t0).Milliseconds(),
-	}); err != nil {
-		return fmt.Errorf("failed to record gc run stats: %w", err)
-	}
-
-	return nil
+	return true, nil
 }
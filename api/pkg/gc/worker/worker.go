@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gc"
 	"getsturdy.com/api/pkg/gc/service"
 	"getsturdy.com/api/pkg/queue"
 	"getsturdy.com/api/pkg/queue/names"
@@ -15,6 +16,8 @@ import (
 
 type CodebaseGarbageCollectionQueueEntry struct {
 	CodebaseID codebases.ID `json:"codebase_id"`
+	// RunID is set for runs that were created with service.CreateRun.
+	RunID *gc.RunID `json:"run_id,omitempty"`
 }
 
 type Queue struct {
@@ -47,6 +50,17 @@ func (q *Queue) Enqueue(ctx context.Context, codebaseID codebases.ID) error {
 	return nil
 }
 
+// EnqueueRun enqueues a run that was created with service.CreateRun.
+func (q *Queue) EnqueueRun(ctx context.Context, run *gc.Run) error {
+	if err := q.queue.Publish(ctx, q.name, &CodebaseGarbageCollectionQueueEntry{
+		CodebaseID: run.CodebaseID,
+		RunID:      &run.ID,
+	}); err != nil {
+		return fmt.Errorf("could not publish to queue: %w", err)
+	}
+	return nil
+}
+
 func (q *Queue) Start(ctx context.Context) error {
 	messages := make(chan queue.Message)
 	go func() {
@@ -66,7 +80,13 @@ func (q *Queue) Start(ctx context.Context) error {
 			}
 			logger := q.logger.With(zap.Stringer("codebase_id", m.CodebaseID))
 
-			if err := q.service.Work(context.Background(), logger, m.CodebaseID); err != nil {
+			if m.RunID != nil {
+				logger = logger.With(zap.Stringer("run_id", *m.RunID))
+				if err := q.service.WorkRun(context.Background(), logger, *m.RunID); err != nil {
+					logger.Error("failed to gc codebase", zap.Error(err))
+					continue
+				}
+			} else if err := q.service.Work(context.Background(), logger, m.CodebaseID); err != nil {
 				logger.Error("failed to gc codebase", zap.Error(err))
 				continue
 			}
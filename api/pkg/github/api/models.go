@@ -343,6 +343,89 @@ func (wj *WorkflowJob) GetStartedAt() Timestamp {
 	return *wj.StartedAt
 }
 
+type PullRequestComment struct {
+	ID           *int64     `json:"id,omitempty"`
+	InReplyTo    *int64     `json:"in_reply_to_id,omitempty"`
+	Body         *string    `json:"body,omitempty"`
+	Path         *string    `json:"path,omitempty"`
+	Line         *int       `json:"line,omitempty"`
+	StartLine    *int       `json:"start_line,omitempty"`
+	OriginalLine *int       `json:"original_line,omitempty"`
+	Side         *string    `json:"side,omitempty"`
+	User         *User      `json:"user,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+func (prc *PullRequestComment) GetID() int64 {
+	if prc == nil || prc.ID == nil {
+		return 0
+	}
+	return *prc.ID
+}
+
+func (prc *PullRequestComment) GetInReplyTo() int64 {
+	if prc == nil || prc.InReplyTo == nil {
+		return 0
+	}
+	return *prc.InReplyTo
+}
+
+func (prc *PullRequestComment) GetBody() string {
+	if prc == nil || prc.Body == nil {
+		return ""
+	}
+	return *prc.Body
+}
+
+func (prc *PullRequestComment) GetPath() string {
+	if prc == nil || prc.Path == nil {
+		return ""
+	}
+	return *prc.Path
+}
+
+func (prc *PullRequestComment) GetLine() int {
+	if prc == nil || prc.Line == nil {
+		return 0
+	}
+	return *prc.Line
+}
+
+func (prc *PullRequestComment) GetStartLine() int {
+	if prc == nil || prc.StartLine == nil {
+		return 0
+	}
+	return *prc.StartLine
+}
+
+func (prc *PullRequestComment) GetOriginalLine() int {
+	if prc == nil || prc.OriginalLine == nil {
+		return 0
+	}
+	return *prc.OriginalLine
+}
+
+func (prc *PullRequestComment) GetSide() string {
+	if prc == nil || prc.Side == nil {
+		return ""
+	}
+	return *prc.Side
+}
+
+func (prc *PullRequestComment) GetUser() *User {
+	if prc == nil {
+		return nil
+	}
+	return prc.User
+}
+
+func (prc *PullRequestComment) GetCreatedAt() time.Time {
+	if prc == nil || prc.CreatedAt == nil {
+		return time.Time{}
+	}
+	return *prc.CreatedAt
+}
+
 // Timestamp represents a time that can be unmarshalled from a JSON string
 // formatted as either an RFC3339 or Unix timestamp. This is necessary for some
 // fields since the GitHub API is inconsistent in how it represents times. All
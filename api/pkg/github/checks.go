@@ -0,0 +1,49 @@
+package github
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/statuses"
+)
+
+// CheckRun is a check run on GitHub that mirrors a Sturdy status. There is one check run per status title on the
+// head commit of a pull request, it's updated when the status changes.
+type CheckRun struct {
+	ID                 string       `db:"id"`
+	CodebaseID         codebases.ID `db:"codebase_id"`
+	GitHubRepositoryID int64        `db:"github_repository_id"`
+	GitHubCheckRunID   int64        `db:"github_check_run_id"`
+	HeadSHA            string       `db:"head_sha"`
+	Name               string       `db:"name"`
+	// StatusID and StatusTimestamp are the latest status that was mirrored to the check run.
+	StatusID        string     `db:"status_id"`
+	StatusTimestamp time.Time  `db:"status_timestamp"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       *time.Time `db:"updated_at"`
+}
+
+const (
+	CheckRunStatusInProgress = "in_progress"
+	CheckRunStatusCompleted  = "completed"
+
+	CheckRunConclusionSuccess = "success"
+	CheckRunConclusionFailure = "failure"
+)
+
+// CheckRunState returns the status and the conclusion of a check run that mirrors a status of type t. The
+// conclusion is nil for check runs that are not completed. ok is false if t can not be mirrored.
+func CheckRunState(t statuses.Type) (status string, conclusion *string, ok bool) {
+	switch t {
+	case statuses.TypePending:
+		return CheckRunStatusInProgress, nil, true
+	case statuses.TypeHealty:
+		c := CheckRunConclusionSuccess
+		return CheckRunStatusCompleted, &c, true
+	case statuses.TypeFailing:
+		c := CheckRunConclusionFailure
+		return CheckRunStatusCompleted, &c, true
+	default:
+		return "", nil, false
+	}
+}
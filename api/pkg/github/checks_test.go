@@ -0,0 +1,38 @@
+package github_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/github"
+	"getsturdy.com/api/pkg/statuses"
+)
+
+func TestCheckRunState(t *testing.T) {
+	cases := []struct {
+		statusType         statuses.Type
+		expectedStatus     string
+		expectedConclusion *string
+		expectedOk         bool
+	}{
+		{statusType: statuses.TypePending, expectedStatus: "in_progress", expectedOk: true},
+		{statusType: statuses.TypeHealty, expectedStatus: "completed", expectedConclusion: p("success"), expectedOk: true},
+		{statusType: statuses.TypeFailing, expectedStatus: "completed", expectedConclusion: p("failure"), expectedOk: true},
+		{statusType: statuses.TypeUndefined, expectedOk: false},
+		{statusType: statuses.Type("unknown"), expectedOk: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.statusType), func(t *testing.T) {
+			status, conclusion, ok := github.CheckRunState(tc.statusType)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedConclusion, conclusion)
+		})
+	}
+}
+
+func p(s string) *string {
+	return &s
+}
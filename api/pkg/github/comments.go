@@ -0,0 +1,66 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/comments"
+)
+
+// PullRequestComment connects a review comment on a GitHub pull request with a Sturdy comment.
+type PullRequestComment struct {
+	ID              string       `db:"id"`
+	CodebaseID      codebases.ID `db:"codebase_id"`
+	PullRequestID   string       `db:"pull_request_id"`
+	GitHubCommentID int64        `db:"github_comment_id"`
+	CommentID       comments.ID  `db:"comment_id"`
+	CreatedAt       time.Time    `db:"created_at"`
+}
+
+const (
+	CommentSideLeft  = "LEFT"
+	CommentSideRight = "RIGHT"
+)
+
+// CommentSide returns the side of the pull request diff that a comment on a new or old line is made on.
+func CommentSide(lineIsNew bool) string {
+	if lineIsNew {
+		return CommentSideRight
+	}
+	return CommentSideLeft
+}
+
+// commentMarkerPrefix starts the hidden marker that is added to comments that are posted to GitHub by Sturdy. The
+// marker is used to recognize the comments when GitHub sends them back in webhooks.
+const commentMarkerPrefix = "\n\n<!-- sturdy-comment:"
+
+// CommentBody returns the body of a GitHub comment that mirrors the Sturdy comment. If the comment is not posted by
+// its author on GitHub, authorName is included in the body.
+func CommentBody(message string, commentID comments.ID, authorName string) string {
+	var builder strings.Builder
+	builder.WriteString(message)
+	builder.WriteString(commentMarkerPrefix)
+	builder.WriteString(commentID.String())
+	builder.WriteString(" -->")
+	if authorName != "" {
+		builder.WriteString(fmt.Sprintf("\n<sub>%s commented on Sturdy</sub>", authorName))
+	}
+	return builder.String()
+}
+
+// ParseCommentBody returns the message of a GitHub comment, and the id of the Sturdy comment that it mirrors, if
+// the comment was posted by Sturdy. ok is false for comments that were not posted by Sturdy.
+func ParseCommentBody(body string) (message string, commentID comments.ID, ok bool) {
+	idx := strings.LastIndex(body, commentMarkerPrefix)
+	if idx == -1 {
+		return body, "", false
+	}
+	marker := body[idx+len(commentMarkerPrefix):]
+	end := strings.Index(marker, " -->")
+	if end == -1 {
+		return body, "", false
+	}
+	return body[:idx], comments.ID(marker[:end]), true
+}
@@ -0,0 +1,54 @@
+package github_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/comments"
+	"getsturdy.com/api/pkg/github"
+)
+
+func TestParseCommentBody(t *testing.T) {
+	cases := []struct {
+		name              string
+		body              string
+		expectedMessage   string
+		expectedCommentID comments.ID
+		expectedOk        bool
+	}{
+		{
+			name:            "not from sturdy",
+			body:            "looks good",
+			expectedMessage: "looks good",
+		},
+		{
+			name:              "from sturdy",
+			body:              github.CommentBody("looks good", "comment-id", ""),
+			expectedMessage:   "looks good",
+			expectedCommentID: "comment-id",
+			expectedOk:        true,
+		},
+		{
+			name:              "from sturdy with author",
+			body:              github.CommentBody("looks\n\ngood", "comment-id", "Alice"),
+			expectedMessage:   "looks\n\ngood",
+			expectedCommentID: "comment-id",
+			expectedOk:        true,
+		},
+		{
+			name:            "broken marker",
+			body:            "looks good\n\n<!-- sturdy-comment:comment-id",
+			expectedMessage: "looks good\n\n<!-- sturdy-comment:comment-id",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			message, commentID, ok := github.ParseCommentBody(tc.body)
+			assert.Equal(t, tc.expectedMessage, message)
+			assert.Equal(t, tc.expectedCommentID, commentID)
+			assert.Equal(t, tc.expectedOk, ok)
+		})
+	}
+}
@@ -21,6 +21,8 @@ type GitHubClients struct {
 	Repositories RepositoriesClient
 	PullRequests PullRequestsClient
 	Users        UsersClient
+	Checks       ChecksClient
+	Issues       IssuesClient
 }
 
 type RepositoriesClient interface {
@@ -40,6 +42,20 @@ type PullRequestsClient interface {
 	Create(ctx context.Context, owner string, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
 	Get(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error)
 	Edit(ctx context.Context, owner string, repo string, number int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error)
+	CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.PullRequestComment) (*github.PullRequestComment, *github.Response, error)
+	CreateCommentInReplyTo(ctx context.Context, owner string, repo string, number int, body string, commentID int64) (*github.PullRequestComment, *github.Response, error)
+	EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *github.PullRequestComment) (*github.PullRequestComment, *github.Response, error)
+	DeleteComment(ctx context.Context, owner string, repo string, commentID int64) (*github.Response, error)
+}
+
+type ChecksClient interface {
+	CreateCheckRun(ctx context.Context, owner, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error)
+	UpdateCheckRun(ctx context.Context, owner, repo string, checkRunID int64, opts github.UpdateCheckRunOptions) (*github.CheckRun, *github.Response, error)
+}
+
+type IssuesClient interface {
+	Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
 }
 
 type UsersClient interface {
@@ -62,6 +78,8 @@ func NewInstallationClient(gitHubAppConfig *config.GitHubAppConfig, installation
 			Repositories: ghClient.Repositories,
 			PullRequests: ghClient.PullRequests,
 			Users:        ghClient.Users,
+			Checks:       ghClient.Checks,
+			Issues:       ghClient.Issues,
 		},
 		appsGhClient.Apps, nil
 }
@@ -80,6 +98,8 @@ func NewPersonalClient(personalOauthToken string) (personalClient *GitHubClients
 		Repositories: client.Repositories,
 		PullRequests: client.PullRequests,
 		Users:        client.Users,
+		Checks:       client.Checks,
+		Issues:       client.Issues,
 	}, nil
 }
 
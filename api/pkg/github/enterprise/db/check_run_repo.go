@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/github"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type GitHubCheckRunRepository interface {
+	Create(ctx context.Context, checkRun *github.CheckRun) error
+	Update(ctx context.Context, checkRun *github.CheckRun) error
+	GetByHeadSHAAndName(ctx context.Context, codebaseID codebases.ID, headSHA, name string) (*github.CheckRun, error)
+}
+
+type gitHubCheckRunRepo struct {
+	db *sqlx.DB
+}
+
+func NewGitHubCheckRunRepository(db *sqlx.DB) GitHubCheckRunRepository {
+	return &gitHubCheckRunRepo{db: db}
+}
+
+func (r *gitHubCheckRunRepo) Create(ctx context.Context, checkRun *github.CheckRun) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO github_check_runs
+		(id, codebase_id, github_repository_id, github_check_run_id, head_sha, name, status_id, status_timestamp, created_at, updated_at)
+		VALUES
+		(:id, :codebase_id, :github_repository_id, :github_check_run_id, :head_sha, :name, :status_id, :status_timestamp, :created_at, :updated_at)
+	`, checkRun); err != nil {
+		return fmt.Errorf("failed to perform insert: %w", err)
+	}
+	return nil
+}
+
+func (r *gitHubCheckRunRepo) Update(ctx context.Context, checkRun *github.CheckRun) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE github_check_runs
+		SET status_id = :status_id,
+		    status_timestamp = :status_timestamp,
+		    updated_at = :updated_at
+		WHERE id = :id
+	`, checkRun); err != nil {
+		return fmt.Errorf("failed to perform update: %w", err)
+	}
+	return nil
+}
+
+func (r *gitHubCheckRunRepo) GetByHeadSHAAndName(ctx context.Context, codebaseID codebases.ID, headSHA, name string) (*github.CheckRun, error) {
+	var checkRun github.CheckRun
+	if err := r.db.GetContext(ctx, &checkRun, `
+		SELECT
+			id, codebase_id, github_repository_id, github_check_run_id, head_sha, name, status_id, status_timestamp, created_at, updated_at
+		FROM
+			github_check_runs
+		WHERE
+			codebase_id = $1
+			AND head_sha = $2
+			AND name = $3
+	`, codebaseID, headSHA, name); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return &checkRun, nil
+}
@@ -3,8 +3,10 @@ package db
 import "getsturdy.com/api/pkg/di"
 
 func Module(c *di.Container) {
+	c.Register(NewGitHubCheckRunRepository)
 	c.Register(NewGitHubInstallationRepository)
 	c.Register(NewGitHubPRRepository)
+	c.Register(NewGitHubPRCommentRepository)
 	c.Register(NewGitHubRepositoryRepository)
 	c.Register(NewGitHubUserRepository)
 }
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/comments"
+	"getsturdy.com/api/pkg/github"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type GitHubPRCommentRepository interface {
+	// Claim saves the comment, unless the Sturdy comment or the GitHub comment already has been saved. It returns
+	// false if the comment was not saved. Comments that are being published to GitHub are claimed with a zero
+	// GitHubCommentID, so that they are only published once.
+	Claim(ctx context.Context, comment *github.PullRequestComment) (bool, error)
+	// SetGitHubCommentID saves the id of a claimed comment, once it has been published to GitHub.
+	SetGitHubCommentID(ctx context.Context, id string, gitHubCommentID int64) error
+	Delete(ctx context.Context, id string) error
+	GetByGitHubCommentID(ctx context.Context, codebaseID codebases.ID, gitHubCommentID int64) (*github.PullRequestComment, error)
+	// GetByCommentID returns the comment, the GitHubCommentID is zero if the comment is still being published.
+	GetByCommentID(ctx context.Context, commentID comments.ID) (*github.PullRequestComment, error)
+}
+
+type gitHubPRCommentRepo struct {
+	db *sqlx.DB
+}
+
+func NewGitHubPRCommentRepository(db *sqlx.DB) GitHubPRCommentRepository {
+	return &gitHubPRCommentRepo{db: db}
+}
+
+func (r *gitHubPRCommentRepo) Claim(ctx context.Context, comment *github.PullRequestComment) (bool, error) {
+	res, err := r.db.NamedExecContext(ctx, `INSERT INTO github_pull_request_comments
+		(id, codebase_id, pull_request_id, github_comment_id, comment_id, created_at)
+		VALUES
+		(:id, :codebase_id, :pull_request_id, NULLIF(:github_comment_id, 0), :comment_id, :created_at)
+		ON CONFLICT DO NOTHING
+	`, comment)
+	if err != nil {
+		return false, fmt.Errorf("failed to perform insert: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (r *gitHubPRCommentRepo) SetGitHubCommentID(ctx context.Context, id string, gitHubCommentID int64) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE github_pull_request_comments SET github_comment_id = $2 WHERE id = $1`, id, gitHubCommentID); err != nil {
+		return fmt.Errorf("failed to perform update: %w", err)
+	}
+	return nil
+}
+
+func (r *gitHubPRCommentRepo) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM github_pull_request_comments WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to perform delete: %w", err)
+	}
+	return nil
+}
+
+func (r *gitHubPRCommentRepo) GetByGitHubCommentID(ctx context.Context, codebaseID codebases.ID, gitHubCommentID int64) (*github.PullRequestComment, error) {
+	var comment github.PullRequestComment
+	if err := r.db.GetContext(ctx, &comment, `
+		SELECT
+			id, codebase_id, pull_request_id, COALESCE(github_comment_id, 0) AS github_comment_id, comment_id, created_at
+		FROM
+			github_pull_request_comments
+		WHERE
+			codebase_id = $1
+			AND github_comment_id = $2
+	`, codebaseID, gitHubCommentID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return &comment, nil
+}
+
+func (r *gitHubPRCommentRepo) GetByCommentID(ctx context.Context, commentID comments.ID) (*github.PullRequestComment, error) {
+	var comment github.PullRequestComment
+	if err := r.db.GetContext(ctx, &comment, `
+		SELECT
+			id, codebase_id, pull_request_id, COALESCE(github_comment_id, 0) AS github_comment_id, comment_id, created_at
+		FROM
+			github_pull_request_comments
+		WHERE
+			comment_id = $1
+	`, commentID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return &comment, nil
+}
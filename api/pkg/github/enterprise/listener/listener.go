@@ -0,0 +1,54 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/comments"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	service_github "getsturdy.com/api/pkg/github/enterprise/service"
+	"getsturdy.com/api/pkg/statuses"
+
+	"go.uber.org/zap"
+)
+
+// Listener publishes statuses to GitHub as check runs, and comments as review comments, on the pull requests that
+// they belong to.
+type Listener struct {
+	logger        *zap.Logger
+	subscriber    *eventsv2.Subscriber
+	githubService *service_github.Service
+}
+
+func New(
+	logger *zap.Logger,
+	subscriber *eventsv2.Subscriber,
+	githubService *service_github.Service,
+) *Listener {
+	return &Listener{
+		logger:        logger.Named("githubListener"),
+		subscriber:    subscriber,
+		githubService: githubService,
+	}
+}
+
+func (l *Listener) Start(ctx context.Context) error {
+	topic := eventsv2.SubscribeAll()
+
+	l.subscriber.OnStatusUpdated(ctx, topic, func(ctx context.Context, status *statuses.Status) error {
+		if err := l.githubService.PublishCheckRun(ctx, status); err != nil {
+			return fmt.Errorf("failed to publish check run: %w", err)
+		}
+		return nil
+	})
+	l.subscriber.OnCommentUpdated(ctx, topic, func(ctx context.Context, comment *comments.Comment) error {
+		if err := l.githubService.PublishComment(ctx, comment); err != nil {
+			return fmt.Errorf("failed to publish comment: %w", err)
+		}
+		return nil
+	})
+
+	l.logger.Info("listening for events")
+	<-ctx.Done()
+	return nil
+}
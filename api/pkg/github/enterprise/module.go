@@ -9,6 +9,7 @@ import (
 	"getsturdy.com/api/pkg/github/enterprise/db"
 	"getsturdy.com/api/pkg/github/enterprise/graphql"
 	graphql_pr "getsturdy.com/api/pkg/github/enterprise/graphql/pr"
+	"getsturdy.com/api/pkg/github/enterprise/listener"
 	"getsturdy.com/api/pkg/github/enterprise/service"
 	service_github_webhooks "getsturdy.com/api/pkg/github/enterprise/webhooks"
 	"getsturdy.com/api/pkg/github/enterprise/workers"
@@ -21,6 +22,7 @@ func Module(c *di.Container) {
 	c.Import(db.Module)
 	c.Import(graphql.Module)
 	c.Import(graphql_pr.Module)
+	c.Import(listener.Module)
 	c.Register(service.New)
 	c.Register(service_github_webhooks.New)
 
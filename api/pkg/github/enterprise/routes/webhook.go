@@ -70,6 +70,14 @@ func Webhook(logger *zap.Logger, queue *workers_github.WebhooksQueue) func(c *gi
 				c.AbortWithStatus(http.StatusInternalServerError)
 				return
 			}
+		case *webhooks.PullRequestReviewCommentEvent:
+			if err := queue.Enqueue(c.Request.Context(), &workers_github.WebhookEvent{
+				PullRequestReviewComment: event,
+			}); err != nil {
+				logger.Error("failed to enqueue webhook", zap.Error(err))
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
 		case *webhooks.StatusEvent:
 			if err := queue.Enqueue(c.Request.Context(), &workers_github.WebhookEvent{
 				Status: event,
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	gh "github.com/google/go-github/v39/github"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/github"
+	"getsturdy.com/api/pkg/statuses"
+	"getsturdy.com/api/pkg/workspaces"
+)
+
+// PublishCheckRun mirrors the status as a check run on the head commit of the open pull request that the status
+// belongs to. The check run is updated when a newer status with the same title is published.
+//
+// Statuses that are imported from GitHub are not published, they are already on GitHub.
+func (svc *Service) PublishCheckRun(ctx context.Context, status *statuses.Status) error {
+	if status.Source == statuses.SourceGitHub {
+		return nil
+	}
+
+	ghRepo, err := svc.gitHubRepositoryRepo.GetByCodebaseID(status.CodebaseID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get github repository: %w", err)
+	}
+
+	// pull requests are only created when GitHub is the source of truth
+	if !ghRepo.IntegrationEnabled || !ghRepo.GitHubSourceOfTruth {
+		return nil
+	}
+
+	pr, err := svc.pullRequestForStatus(ctx, status)
+	if err != nil {
+		return err
+	}
+	if pr == nil {
+		return nil
+	}
+
+	return svc.publishCheckRun(ctx, ghRepo, *pr.HeadSHA, status)
+}
+
+// publishCheckRuns publishes the statuses that were reported before the pull request was created or updated.
+func (svc *Service) publishCheckRuns(ctx context.Context, ghRepo *github.Repository, pr *github.PullRequest, ws *workspaces.Workspace) error {
+	if pr.HeadSHA == nil {
+		return nil
+	}
+
+	commitSHAs := []string{*pr.HeadSHA}
+	if ws.LatestSnapshotID != nil {
+		snapshot, err := svc.snap.GetByID(ctx, *ws.LatestSnapshotID)
+		if err != nil {
+			return fmt.Errorf("failed to get snapshot: %w", err)
+		}
+		commitSHAs = append(commitSHAs, snapshot.CommitSHA)
+	}
+
+	for _, commitSHA := range commitSHAs {
+		ss, err := svc.statusService.List(ctx, ws.CodebaseID, commitSHA)
+		if err != nil {
+			return fmt.Errorf("failed to list statuses: %w", err)
+		}
+		for _, status := range ss {
+			if status.Source == statuses.SourceGitHub {
+				continue
+			}
+			if err := svc.publishCheckRun(ctx, ghRepo, *pr.HeadSHA, status); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// pullRequestForStatus returns the open pull request that the status belongs to, or nil if there is none.
+// Statuses belong to a pull request if they are reported on the head commit of the pull request, or on the latest
+// snapshot of its workspace, which is what CI builds for workspaces.
+func (svc *Service) pullRequestForStatus(ctx context.Context, status *statuses.Status) (*github.PullRequest, error) {
+	pr, err := svc.gitHubPullRequestRepo.GetByCodebaseIDaAndHeadSHA(ctx, status.CodebaseID, status.CommitSHA)
+	switch {
+	case err == nil && pr.State == github.PullRequestStateOpen:
+		return pr, nil
+	case err == nil:
+		return nil, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	snapshot, err := svc.snap.GetByCommitSHA(ctx, status.CommitSHA)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	case snapshot.WorkspaceID == nil:
+		return nil, nil
+	case snapshot.CodebaseID != status.CodebaseID:
+		// the commit is in another codebase, statuses can be posted for any commit
+		return nil, nil
+	}
+
+	ws, err := svc.workspaceReader.Get(*snapshot.WorkspaceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	// statuses of older snapshots are not relevant for what's on the pull request
+	if ws.LatestSnapshotID == nil || *ws.LatestSnapshotID != snapshot.ID {
+		return nil, nil
+	}
+
+	prs, err := svc.gitHubPullRequestRepo.ListOpenedByWorkspace(ws.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(prs) != 1 || prs[0].HeadSHA == nil {
+		return nil, nil
+	}
+
+	return prs[0], nil
+}
+
+func (svc *Service) publishCheckRun(ctx context.Context, ghRepo *github.Repository, headSHA string, status *statuses.Status) error {
+	checkRunStatus, conclusion, ok := github.CheckRunState(status.Type)
+	if !ok {
+		return nil
+	}
+
+	checkRun, err := svc.gitHubCheckRunRepo.GetByHeadSHAAndName(ctx, ghRepo.CodebaseID, headSHA, status.Title)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		checkRun = nil
+	case err != nil:
+		return fmt.Errorf("failed to get check run: %w", err)
+	case checkRun.StatusID == status.ID, status.Timestamp.Before(checkRun.StatusTimestamp):
+		// already published, or a newer status has been published
+		return nil
+	}
+
+	installation, err := svc.gitHubInstallationRepo.GetByInstallationID(ghRepo.InstallationID)
+	if err != nil {
+		return fmt.Errorf("failed to get github installation: %w", err)
+	}
+
+	tokenClient, _, err := svc.gitHubInstallationClientProvider(svc.gitHubAppConfig, ghRepo.InstallationID)
+	if err != nil {
+		return fmt.Errorf("failed to get github client: %w", err)
+	}
+
+	summary := status.Title
+	if status.Description != nil && *status.Description != "" {
+		summary = *status.Description
+	}
+	output := &gh.CheckRunOutput{
+		Title:   &status.Title,
+		Summary: &summary,
+	}
+
+	var completedAt *gh.Timestamp
+	if conclusion != nil {
+		completedAt = &gh.Timestamp{Time: status.Timestamp}
+	}
+
+	logger := svc.logger.With(
+		zap.Stringer("codebase_id", ghRepo.CodebaseID),
+		zap.String("head_sha", headSHA),
+		zap.String("status_id", status.ID),
+	)
+
+	now := time.Now()
+
+	if checkRun == nil {
+		apiCheckRun, _, err := tokenClient.Checks.CreateCheckRun(ctx, installation.Owner, ghRepo.Name, gh.CreateCheckRunOptions{
+			Name:        status.Title,
+			HeadSHA:     headSHA,
+			DetailsURL:  status.DetailsURL,
+			ExternalID:  &status.ID,
+			Status:      &checkRunStatus,
+			Conclusion:  conclusion,
+			StartedAt:   &gh.Timestamp{Time: status.Timestamp},
+			CompletedAt: completedAt,
+			Output:      output,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create check run: %w", err)
+		}
+
+		logger.Info("created github check run", zap.Int64("github_check_run_id", apiCheckRun.GetID()))
+
+		if err := svc.gitHubCheckRunRepo.Create(ctx, &github.CheckRun{
+			ID:                 uuid.NewString(),
+			CodebaseID:         ghRepo.CodebaseID,
+			GitHubRepositoryID: ghRepo.GitHubRepositoryID,
+			GitHubCheckRunID:   apiCheckRun.GetID(),
+			HeadSHA:            headSHA,
+			Name:               status.Title,
+			StatusID:           status.ID,
+			StatusTimestamp:    status.Timestamp,
+			CreatedAt:          now,
+		}); err != nil {
+			return fmt.Errorf("failed to save check run: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, _, err := tokenClient.Checks.UpdateCheckRun(ctx, installation.Owner, ghRepo.Name, checkRun.GitHubCheckRunID, gh.UpdateCheckRunOptions{
+		Name:        status.Title,
+		DetailsURL:  status.DetailsURL,
+		ExternalID:  &status.ID,
+		Status:      &checkRunStatus,
+		Conclusion:  conclusion,
+		CompletedAt: completedAt,
+		Output:      output,
+	}); err != nil {
+		return fmt.Errorf("failed to update check run: %w", err)
+	}
+
+	logger.Info("updated github check run", zap.Int64("github_check_run_id", checkRun.GitHubCheckRunID))
+
+	checkRun.StatusID = status.ID
+	checkRun.StatusTimestamp = status.Timestamp
+	checkRun.UpdatedAt = &now
+	if err := svc.gitHubCheckRunRepo.Update(ctx, checkRun); err != nil {
+		return fmt.Errorf("failed to update check run: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	gh "github.com/google/go-github/v39/github"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/changes"
+)
+
+// CloseIssues closes the issues of the GitHub repository that the landed change references with closing keywords.
+// References to pull requests are ignored.
+//
+// When GitHub is the source of truth, changes land by merging pull requests, and GitHub closes the issues itself.
+func (svc *Service) CloseIssues(ctx context.Context, change *changes.Change, issues []int) error {
+	ghRepo, err := svc.gitHubRepositoryRepo.GetByCodebaseID(change.CodebaseID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get github repository: %w", err)
+	}
+
+	if !ghRepo.IntegrationEnabled || ghRepo.GitHubSourceOfTruth {
+		return nil
+	}
+
+	installation, err := svc.gitHubInstallationRepo.GetByInstallationID(ghRepo.InstallationID)
+	if err != nil {
+		return fmt.Errorf("failed to get github installation: %w", err)
+	}
+
+	tokenClient, _, err := svc.gitHubInstallationClientProvider(svc.gitHubAppConfig, ghRepo.InstallationID)
+	if err != nil {
+		return fmt.Errorf("failed to get github client: %w", err)
+	}
+
+	logger := svc.logger.With(
+		zap.Stringer("codebase_id", change.CodebaseID),
+		zap.Stringer("change_id", change.ID),
+	)
+
+	closed := "closed"
+	for _, number := range issues {
+		logger := logger.With(zap.Int("github_issue_number", number))
+
+		issue, _, err := tokenClient.Issues.Get(ctx, installation.Owner, ghRepo.Name, number)
+		switch {
+		case isNotFound(err), isForbidden(err):
+			// the issue does not exist, or the app is not allowed to access issues
+			logger.Info("skipping github issue", zap.Error(err))
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to get issue #%d: %w", number, err)
+		case issue.IsPullRequest(), issue.GetState() == closed:
+			continue
+		}
+
+		if _, _, err := tokenClient.Issues.Edit(ctx, installation.Owner, ghRepo.Name, number, &gh.IssueRequest{State: &closed}); err != nil {
+			if isForbidden(err) {
+				logger.Info("not allowed to close github issue", zap.Error(err))
+				continue
+			}
+			return fmt.Errorf("failed to close issue #%d: %w", number, err)
+		}
+
+		logger.Info("closed github issue")
+	}
+
+	return nil
+}
+
+func isForbidden(err error) bool {
+	var errorResponse *gh.ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Response != nil && errorResponse.Response.StatusCode == http.StatusForbidden
+}
@@ -339,6 +339,10 @@ func (svc *Service) CreateOrUpdatePullRequest(ctx context.Context, user *users.U
 			analytics.Property("github", true),
 		)
 
+		if err := svc.publishCheckRuns(ctx, ghRepo, &pr, ws); err != nil {
+			logger.Error("failed to publish check runs", zap.Error(err))
+		}
+
 		return &pr, nil
 	}
 	if len(prs) > 1 {
@@ -371,6 +375,10 @@ func (svc *Service) CreateOrUpdatePullRequest(ctx context.Context, user *users.U
 		analytics.Property("github", true),
 	)
 
+	if err := svc.publishCheckRuns(ctx, ghRepo, currentPR, ws); err != nil {
+		logger.Error("failed to publish check runs", zap.Error(err))
+	}
+
 	return currentPR, nil
 }
 
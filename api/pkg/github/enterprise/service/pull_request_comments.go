@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v39/github"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/comments"
+	decorate_comment "getsturdy.com/api/pkg/comments/decorate"
+	"getsturdy.com/api/pkg/comments/vcs"
+	"getsturdy.com/api/pkg/events"
+	"getsturdy.com/api/pkg/github"
+	"getsturdy.com/api/pkg/github/api"
+	github_client "getsturdy.com/api/pkg/github/enterprise/client"
+	"getsturdy.com/api/pkg/users"
+)
+
+// PublishComment mirrors the comment as a review comment on the open pull request of its workspace. Comments that
+// are already on GitHub are edited or deleted.
+//
+// Only comments on code, and replies to them, are published. GitHub does not have review comments without a line.
+//
+// Events of the same comment can be handled concurrently, so the comment is reloaded, and the pull request comment
+// is claimed before it's created on GitHub. Changes that are made while the comment is being created are published
+// by the handler that created it.
+func (svc *Service) PublishComment(ctx context.Context, comment *comments.Comment) error {
+	if comment.WorkspaceID == nil {
+		return nil
+	}
+
+	latest, err := svc.commentsRepo.Get(comment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get comment: %w", err)
+	}
+	comment = &latest
+
+	prComment, err := svc.gitHubPRCommentRepo.GetByCommentID(ctx, comment.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		prComment = nil
+	case err != nil:
+		return fmt.Errorf("failed to get pull request comment: %w", err)
+	case prComment.GitHubCommentID == 0:
+		// the comment is being created on GitHub
+		return nil
+	}
+
+	if prComment == nil && comment.DeletedAt != nil {
+		return nil
+	}
+
+	var pr *github.PullRequest
+	if prComment != nil {
+		if pr, err = svc.gitHubPullRequestRepo.Get(prComment.PullRequestID); err != nil {
+			return fmt.Errorf("failed to get pull request: %w", err)
+		}
+	} else {
+		prs, err := svc.gitHubPullRequestRepo.ListOpenedByWorkspace(*comment.WorkspaceID)
+		if err != nil {
+			return fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		if len(prs) != 1 || prs[0].HeadSHA == nil {
+			return nil
+		}
+		pr = prs[0]
+	}
+
+	ghRepo, err := svc.gitHubRepositoryRepo.GetByCodebaseID(pr.CodebaseID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get github repository: %w", err)
+	}
+
+	// pull requests are only created when GitHub is the source of truth
+	if !ghRepo.IntegrationEnabled || !ghRepo.GitHubSourceOfTruth {
+		return nil
+	}
+
+	installation, err := svc.gitHubInstallationRepo.GetByInstallationID(ghRepo.InstallationID)
+	if err != nil {
+		return fmt.Errorf("failed to get github installation: %w", err)
+	}
+
+	client, authorName, err := svc.commentClient(ctx, ghRepo, comment.UserID)
+	if err != nil {
+		return err
+	}
+
+	logger := svc.logger.With(
+		zap.Stringer("codebase_id", pr.CodebaseID),
+		zap.String("pr_id", pr.ID),
+		zap.Stringer("comment_id", comment.ID),
+	)
+
+	if prComment != nil && comment.DeletedAt != nil {
+		if _, err := client.PullRequests.DeleteComment(ctx, installation.Owner, ghRepo.Name, prComment.GitHubCommentID); err != nil && !isNotFound(err) {
+			return fmt.Errorf("failed to delete github comment: %w", err)
+		}
+		logger.Info("deleted github pull request comment", zap.Int64("github_comment_id", prComment.GitHubCommentID))
+		return nil
+	}
+
+	message, err := svc.gitHubCommentMessage(ctx, comment)
+	if err != nil {
+		return err
+	}
+	body := github.CommentBody(message, comment.ID, authorName)
+
+	if prComment != nil {
+		if _, _, err := client.PullRequests.EditComment(ctx, installation.Owner, ghRepo.Name, prComment.GitHubCommentID, &gh.PullRequestComment{
+			Body: &body,
+		}); err != nil {
+			return fmt.Errorf("failed to edit github comment: %w", err)
+		}
+		logger.Info("edited github pull request comment", zap.Int64("github_comment_id", prComment.GitHubCommentID))
+		return nil
+	}
+
+	var parentGitHubCommentID int64
+	if comment.ParentComment != nil {
+		parent, err := svc.gitHubPRCommentRepo.GetByCommentID(ctx, *comment.ParentComment)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// the comment that is replied to is not on GitHub
+			return nil
+		case err != nil:
+			return fmt.Errorf("failed to get pull request comment: %w", err)
+		case parent.GitHubCommentID == 0:
+			// the comment that is replied to is not on GitHub yet
+			return nil
+		}
+		parentGitHubCommentID = parent.GitHubCommentID
+	} else if comment.Path == "" {
+		return nil
+	}
+
+	prComment = &github.PullRequestComment{
+		ID:            uuid.NewString(),
+		CodebaseID:    pr.CodebaseID,
+		PullRequestID: pr.ID,
+		CommentID:     comment.ID,
+		CreatedAt:     time.Now(),
+	}
+	claimed, err := svc.gitHubPRCommentRepo.Claim(ctx, prComment)
+	if err != nil {
+		return fmt.Errorf("failed to save pull request comment: %w", err)
+	}
+	if !claimed {
+		// the comment is published by another handler
+		return nil
+	}
+
+	apiComment, err := svc.createGitHubComment(ctx, client, installation.Owner, ghRepo.Name, pr, comment, body, parentGitHubCommentID)
+	if err != nil {
+		if err := svc.gitHubPRCommentRepo.Delete(ctx, prComment.ID); err != nil {
+			logger.Error("failed to delete pull request comment", zap.Error(err))
+		}
+		return err
+	}
+
+	logger.Info("created github pull request comment", zap.Int64("github_comment_id", apiComment.GetID()))
+
+	if err := svc.gitHubPRCommentRepo.SetGitHubCommentID(ctx, prComment.ID, apiComment.GetID()); err != nil {
+		return fmt.Errorf("failed to save pull request comment: %w", err)
+	}
+
+	// publish the changes that were skipped while the comment was being created
+	latest, err = svc.commentsRepo.Get(comment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get comment: %w", err)
+	}
+	if latest.DeletedAt != nil || latest.Message != comment.Message {
+		return svc.PublishComment(ctx, &latest)
+	}
+
+	return nil
+}
+
+// createGitHubComment creates the review comment on GitHub, as a reply if parentGitHubCommentID is set.
+func (svc *Service) createGitHubComment(ctx context.Context, client *github_client.GitHubClients, owner, repo string, pr *github.PullRequest, comment *comments.Comment, body string, parentGitHubCommentID int64) (*gh.PullRequestComment, error) {
+	if parentGitHubCommentID != 0 {
+		apiComment, _, err := client.PullRequests.CreateCommentInReplyTo(ctx, owner, repo, pr.GitHubPRNumber, body, parentGitHubCommentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create github comment: %w", err)
+		}
+		return apiComment, nil
+	}
+
+	side := github.CommentSide(comment.LineIsNew)
+	newComment := &gh.PullRequestComment{
+		Body:     &body,
+		CommitID: pr.HeadSHA,
+		Path:     &comment.Path,
+		Line:     &comment.LineEnd,
+		Side:     &side,
+	}
+	if comment.LineStart < comment.LineEnd {
+		newComment.StartLine = &comment.LineStart
+		newComment.StartSide = &side
+	}
+
+	apiComment, _, err := client.PullRequests.CreateComment(ctx, owner, repo, pr.GitHubPRNumber, newComment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create github comment: %w", err)
+	}
+	return apiComment, nil
+}
+
+// ImportPullRequestComment creates or updates the Sturdy comment that mirrors a review comment on the pull request.
+// Comments that were posted to GitHub by Sturdy are not imported again.
+func (svc *Service) ImportPullRequestComment(ctx context.Context, pr *github.PullRequest, userID users.ID, apiComment *api.PullRequestComment) error {
+	message, _, fromSturdy := github.ParseCommentBody(apiComment.GetBody())
+
+	prComment, err := svc.gitHubPRCommentRepo.GetByGitHubCommentID(ctx, pr.CodebaseID, apiComment.GetID())
+	switch {
+	case err == nil:
+		return svc.updateImportedComment(ctx, prComment, message)
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to get pull request comment: %w", err)
+	case fromSturdy:
+		// the comment is saved when it's published
+		return nil
+	}
+
+	ws, err := svc.workspaceReader.Get(pr.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if ws.IsArchived() {
+		return nil
+	}
+
+	createdAt := apiComment.GetCreatedAt()
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	comment := comments.Comment{
+		ID:          comments.ID(uuid.NewString()),
+		CodebaseID:  pr.CodebaseID,
+		WorkspaceID: &ws.ID,
+		UserID:      userID,
+		CreatedAt:   createdAt,
+		Message:     message,
+	}
+
+	logger := svc.logger.With(
+		zap.Stringer("codebase_id", pr.CodebaseID),
+		zap.String("pr_id", pr.ID),
+		zap.Int64("github_comment_id", apiComment.GetID()),
+	)
+
+	if inReplyTo := apiComment.GetInReplyTo(); inReplyTo != 0 {
+		parent, err := svc.gitHubPRCommentRepo.GetByGitHubCommentID(ctx, pr.CodebaseID, inReplyTo)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("the comment that is replied to is not imported, skipping")
+			return nil
+		case err != nil:
+			return fmt.Errorf("failed to get pull request comment: %w", err)
+		}
+		comment.ParentComment = &parent.CommentID
+	} else if line := commentLine(apiComment); apiComment.GetPath() != "" && line > 0 {
+		comment.Path = apiComment.GetPath()
+		comment.LineStart = line
+		comment.LineEnd = line
+		if startLine := apiComment.GetStartLine(); startLine > 0 && startLine < line {
+			comment.LineStart = startLine
+		}
+		comment.LineIsNew = apiComment.GetSide() != github.CommentSideLeft
+
+		context, contextStartsAt, err := vcs.GetWorkspaceContext(comment.LineStart, comment.LineIsNew, comment.Path, nil, ws, svc.executorProvider, svc.snapshotRepo)
+		if err != nil {
+			// the comment is still useful without context
+			logger.Warn("failed to get comment context", zap.Error(err))
+		} else {
+			comment.Context = &context
+			comment.ContextStartsAtLine = &contextStartsAt
+		}
+	}
+
+	// the pull request comment is saved first, so that concurrent deliveries of the same comment import it once
+	prComment = &github.PullRequestComment{
+		ID:              uuid.NewString(),
+		CodebaseID:      pr.CodebaseID,
+		PullRequestID:   pr.ID,
+		GitHubCommentID: apiComment.GetID(),
+		CommentID:       comment.ID,
+		CreatedAt:       time.Now(),
+	}
+	claimed, err := svc.gitHubPRCommentRepo.Claim(ctx, prComment)
+	if err != nil {
+		return fmt.Errorf("failed to save pull request comment: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := svc.commentsRepo.Create(comment); err != nil {
+		if err := svc.gitHubPRCommentRepo.Delete(ctx, prComment.ID); err != nil {
+			logger.Error("failed to delete pull request comment", zap.Error(err))
+		}
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	logger.Info("imported github pull request comment", zap.Stringer("comment_id", comment.ID))
+
+	if err := svc.activitySender.Comment(ctx, &comment); err != nil {
+		return fmt.Errorf("failed to create workspace activity: %w", err)
+	}
+
+	if err := svc.eventsSender.Codebase(comment.CodebaseID, events.WorkspaceUpdatedComments, ws.ID); err != nil {
+		logger.Error("failed to send workspace updated comments event", zap.Error(err))
+		// do not fail
+	}
+
+	return nil
+}
+
+// DeletePullRequestComment deletes the Sturdy comment that mirrors a deleted review comment.
+func (svc *Service) DeletePullRequestComment(ctx context.Context, pr *github.PullRequest, gitHubCommentID int64) error {
+	prComment, err := svc.gitHubPRCommentRepo.GetByGitHubCommentID(ctx, pr.CodebaseID, gitHubCommentID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get pull request comment: %w", err)
+	}
+
+	comment, err := svc.commentsRepo.Get(prComment.CommentID)
+	if err != nil {
+		return fmt.Errorf("failed to get comment: %w", err)
+	}
+	if comment.DeletedAt != nil {
+		return nil
+	}
+
+	t := time.Now()
+	comment.DeletedAt = &t
+	if err := svc.commentsRepo.Update(comment); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	svc.sendWorkspaceUpdatedComments(comment)
+
+	return nil
+}
+
+func (svc *Service) updateImportedComment(ctx context.Context, prComment *github.PullRequestComment, message string) error {
+	comment, err := svc.commentsRepo.Get(prComment.CommentID)
+	if err != nil {
+		return fmt.Errorf("failed to get comment: %w", err)
+	}
+	if comment.DeletedAt != nil {
+		return nil
+	}
+
+	// GitHub sends back the edits that are made by Sturdy, they are already up to date
+	published, err := svc.gitHubCommentMessage(ctx, &comment)
+	if err != nil {
+		return err
+	}
+	if message == published || message == comment.Message {
+		return nil
+	}
+
+	comment.Message = message
+	if err := svc.commentsRepo.Update(comment); err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	svc.sendWorkspaceUpdatedComments(comment)
+
+	return nil
+}
+
+func (svc *Service) sendWorkspaceUpdatedComments(comment comments.Comment) {
+	if comment.WorkspaceID == nil {
+		return
+	}
+	if err := svc.eventsSender.Codebase(comment.CodebaseID, events.WorkspaceUpdatedComments, *comment.WorkspaceID); err != nil {
+		svc.logger.Error("failed to send workspace updated comments event", zap.Error(err))
+		// do not fail
+	}
+}
+
+// commentClient returns the client that comments of the user are published with. Comments are published on
+// behalf of the user if they have connected their GitHub account, otherwise by the app. authorName is set if the
+// comment is published by the app.
+func (svc *Service) commentClient(ctx context.Context, ghRepo *github.Repository, userID users.ID) (client *github_client.GitHubClients, authorName string, err error) {
+	ghUser, err := svc.gitHubUserRepo.GetByUserID(userID)
+	switch {
+	case err == nil && ghUser.AccessToken != nil:
+		personalClient, err := svc.gitHubPersonalClientProvider(*ghUser.AccessToken)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get github client: %w", err)
+		}
+		return personalClient, "", nil
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return nil, "", fmt.Errorf("failed to get github user: %w", err)
+	}
+
+	user, err := svc.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	tokenClient, _, err := svc.gitHubInstallationClientProvider(svc.gitHubAppConfig, ghRepo.InstallationID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get github client: %w", err)
+	}
+
+	return tokenClient, user.Name, nil
+}
+
+// gitHubCommentMessage returns the message of the comment as it's published on GitHub, with user id mentions
+// replaced by user names.
+func (svc *Service) gitHubCommentMessage(ctx context.Context, comment *comments.Comment) (string, error) {
+	if !strings.Contains(comment.Message, "@") {
+		return comment.Message, nil
+	}
+
+	codebaseUsers, err := svc.codebaseUserRepo.GetByCodebase(comment.CodebaseID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get codebase users: %w", err)
+	}
+	userIDs := make([]users.ID, 0, len(codebaseUsers))
+	for _, codebaseUser := range codebaseUsers {
+		userIDs = append(userIDs, codebaseUser.UserID)
+	}
+	uu, err := svc.userService.GetByIDs(ctx, userIDs...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get users: %w", err)
+	}
+
+	message := comment.Message
+	for mention, user := range decorate_comment.ExtractIDMentions(message, uu) {
+		message = strings.ReplaceAll(message, mention, "@"+user.Name)
+	}
+	return message, nil
+}
+
+// commentLine returns the line of a review comment. Outdated comments don't have a line in the current diff, their
+// original line is used instead.
+func commentLine(apiComment *api.PullRequestComment) int {
+	if line := apiComment.GetLine(); line > 0 {
+		return line
+	}
+	return apiComment.GetOriginalLine()
+}
+
+func isNotFound(err error) bool {
+	var errorResponse *gh.ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Response != nil && errorResponse.Response.StatusCode == http.StatusNotFound
+}
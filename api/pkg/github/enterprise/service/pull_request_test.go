@@ -657,6 +657,22 @@ func (f *fakeGitHubPullRequestClient) Edit(ctx context.Context, owner string, re
 	panic("implement me")
 }
 
+func (f *fakeGitHubPullRequestClient) CreateComment(ctx context.Context, owner string, repo string, number int, comment *gh.PullRequestComment) (*gh.PullRequestComment, *gh.Response, error) {
+	panic("implement me")
+}
+
+func (f *fakeGitHubPullRequestClient) CreateCommentInReplyTo(ctx context.Context, owner string, repo string, number int, body string, commentID int64) (*gh.PullRequestComment, *gh.Response, error) {
+	panic("implement me")
+}
+
+func (f *fakeGitHubPullRequestClient) EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *gh.PullRequestComment) (*gh.PullRequestComment, *gh.Response, error) {
+	panic("implement me")
+}
+
+func (f *fakeGitHubPullRequestClient) DeleteComment(ctx context.Context, owner string, repo string, commentID int64) (*gh.Response, error) {
+	panic("implement me")
+}
+
 type fakeGitHubAppsClient struct{}
 
 func (f *fakeGitHubAppsClient) CreateInstallationToken(ctx context.Context, id int64, opts *gh.InstallationTokenOptions) (*gh.InstallationToken, *gh.Response, error) {
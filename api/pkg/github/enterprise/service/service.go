@@ -12,6 +12,7 @@ import (
 	workers_ci "getsturdy.com/api/pkg/ci/workers"
 	"getsturdy.com/api/pkg/codebases"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	db_comments "getsturdy.com/api/pkg/comments/db"
 	service_comments "getsturdy.com/api/pkg/comments/service"
 	"getsturdy.com/api/pkg/events"
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
@@ -23,7 +24,9 @@ import (
 	"getsturdy.com/api/pkg/notification/sender"
 	service_remote "getsturdy.com/api/pkg/remote/enterprise/service"
 	db_review "getsturdy.com/api/pkg/review/db"
+	db_snapshots "getsturdy.com/api/pkg/snapshots/db"
 	"getsturdy.com/api/pkg/snapshots/snapshotter"
+	service_statuses "getsturdy.com/api/pkg/statuses/service"
 	service_sync "getsturdy.com/api/pkg/sync/service"
 	"getsturdy.com/api/pkg/users"
 	service_user "getsturdy.com/api/pkg/users/service"
@@ -49,6 +52,8 @@ type Service struct {
 	gitHubInstallationRepo db_github.GitHubInstallationRepository
 	gitHubUserRepo         db_github.GitHubUserRepository
 	gitHubPullRequestRepo  db_github.GitHubPRRepository
+	gitHubCheckRunRepo     db_github.GitHubCheckRunRepository
+	gitHubPRCommentRepo    db_github.GitHubPRCommentRepository
 
 	gitHubPullRequestImporterQueue *ImporterQueue
 	gitHubCloneQueue               *ClonerQueue
@@ -63,6 +68,8 @@ type Service struct {
 	codebaseUserRepo db_codebases.CodebaseUserRepository
 	codebaseRepo     db_codebases.CodebaseRepository
 	reviewRepo       db_review.ReviewRepository
+	commentsRepo     db_comments.Repository
+	snapshotRepo     db_snapshots.Repository
 
 	executorProvider executor.Provider
 
@@ -78,6 +85,7 @@ type Service struct {
 	commentsService *service_comments.Service
 	changeService   *service_change.Service
 	remoteService   *service_remote.EnterpriseService
+	statusService   *service_statuses.Service
 
 	buildQueue *workers_ci.BuildQueue
 }
@@ -89,6 +97,8 @@ func New(
 	gitHubInstallationRepo db_github.GitHubInstallationRepository,
 	gitHubUserRepo db_github.GitHubUserRepository,
 	gitHubPullRequestRepo db_github.GitHubPRRepository,
+	gitHubCheckRunRepo db_github.GitHubCheckRunRepository,
+	gitHubPRCommentRepo db_github.GitHubPRCommentRepository,
 	gitHubAppConfig *config_github.GitHubAppConfig,
 	gitHubInstallationClientProvider github_client.InstallationClientProvider,
 	gitHubPersonalClientProvider github_client.PersonalClientProvider,
@@ -102,6 +112,8 @@ func New(
 	codebaseUserRepo db_codebases.CodebaseUserRepository,
 	codebaseRepo db_codebases.CodebaseRepository,
 	reviewRepo db_review.ReviewRepository,
+	commentsRepo db_comments.Repository,
+	snapshotRepo db_snapshots.Repository,
 
 	executorProvider executor.Provider,
 	snap snapshotter.Snapshotter,
@@ -116,6 +128,7 @@ func New(
 	commentsService *service_comments.Service,
 	changeService *service_change.Service,
 	remoteService *service_remote.EnterpriseService,
+	statusService *service_statuses.Service,
 
 	buildQueue *workers_ci.BuildQueue,
 ) *Service {
@@ -126,6 +139,8 @@ func New(
 		gitHubInstallationRepo:           gitHubInstallationRepo,
 		gitHubUserRepo:                   gitHubUserRepo,
 		gitHubPullRequestRepo:            gitHubPullRequestRepo,
+		gitHubCheckRunRepo:               gitHubCheckRunRepo,
+		gitHubPRCommentRepo:              gitHubPRCommentRepo,
 		gitHubAppConfig:                  gitHubAppConfig,
 		gitHubInstallationClientProvider: gitHubInstallationClientProvider,
 		gitHubPersonalClientProvider:     gitHubPersonalClientProvider,
@@ -139,6 +154,8 @@ func New(
 		codebaseUserRepo: codebaseUserRepo,
 		codebaseRepo:     codebaseRepo,
 		reviewRepo:       reviewRepo,
+		commentsRepo:     commentsRepo,
+		snapshotRepo:     snapshotRepo,
 
 		executorProvider:   executorProvider,
 		snap:               snap,
@@ -153,6 +170,7 @@ func New(
 		commentsService: commentsService,
 		changeService:   changeService,
 		remoteService:   remoteService,
+		statusService:   statusService,
 
 		buildQueue: buildQueue,
 	}
@@ -259,6 +277,9 @@ func (svc *Service) CheckPermissions(ctx context.Context) (bool, []string, []str
 	if permissions.GetStatuses() != "read" {
 		insertMissingPermission("Status")
 	}
+	if permissions.GetChecks() != "write" {
+		insertMissingPermission("Checks")
+	}
 	if permissions.GetWorkflows() != "write" {
 		insertMissingPermission("Workflows")
 	}
@@ -266,6 +287,7 @@ func (svc *Service) CheckPermissions(ctx context.Context) (bool, []string, []str
 	eventsMap := make(map[string]string)
 	eventsMap["pull_request"] = "Pull Request"
 	eventsMap["pull_request_review"] = "Pull Request Review"
+	eventsMap["pull_request_review_comment"] = "Pull Request Review Comment"
 	eventsMap["push"] = "Push"
 	eventsMap["status"] = "Status"
 	eventsMap["workflow_job"] = "Workflow Job"
@@ -12,12 +12,13 @@ import (
 // if we need more fields, they should be copied manually to have control over the structure size
 
 var eventTypeMapping = map[string]string{
-	"installation":              "InstallationEvent",
-	"installation_repositories": "InstallationRepositoriesEvent",
-	"pull_request":              "PullRequestEvent",
-	"push":                      "PushEvent",
-	"status":                    "StatusEvent",
-	"workflow_job":              "WorkflowJobEvent",
+	"installation":                "InstallationEvent",
+	"installation_repositories":   "InstallationRepositoriesEvent",
+	"pull_request":                "PullRequestEvent",
+	"pull_request_review_comment": "PullRequestReviewCommentEvent",
+	"push":                        "PushEvent",
+	"status":                      "StatusEvent",
+	"workflow_job":                "WorkflowJobEvent",
 }
 
 func ParseWebHook(messageType string, payload []byte) (any, error) {
@@ -53,6 +54,8 @@ func (e *Event) ParsePayload() (payload any, err error) {
 		payload = &InstallationRepositoriesEvent{}
 	case "PullRequestEvent":
 		payload = &PullRequestEvent{}
+	case "PullRequestReviewCommentEvent":
+		payload = &PullRequestReviewCommentEvent{}
 	case "PushEvent":
 		payload = &PushEvent{}
 	case "StatusEvent":
@@ -99,6 +102,50 @@ func (pre *PullRequestEvent) GetInstallation() *api.Installation {
 	return pre.Installation
 }
 
+type PullRequestReviewCommentEvent struct {
+	// The action that was performed on the comment. Can be either "created", "edited" or "deleted".
+	Action       *string                 `json:"action,omitempty"`
+	PullRequest  *api.PullRequest        `json:"pull_request,omitempty"`
+	Comment      *api.PullRequestComment `json:"comment,omitempty"`
+	Repo         *api.Repository         `json:"repository,omitempty"`
+	Installation *api.Installation       `json:"installation,omitempty"`
+}
+
+func (prrce *PullRequestReviewCommentEvent) GetAction() string {
+	if prrce == nil || prrce.Action == nil {
+		return ""
+	}
+	return *prrce.Action
+}
+
+func (prrce *PullRequestReviewCommentEvent) GetPullRequest() *api.PullRequest {
+	if prrce == nil {
+		return nil
+	}
+	return prrce.PullRequest
+}
+
+func (prrce *PullRequestReviewCommentEvent) GetComment() *api.PullRequestComment {
+	if prrce == nil {
+		return nil
+	}
+	return prrce.Comment
+}
+
+func (prrce *PullRequestReviewCommentEvent) GetRepo() *api.Repository {
+	if prrce == nil {
+		return nil
+	}
+	return prrce.Repo
+}
+
+func (prrce *PullRequestReviewCommentEvent) GetInstallation() *api.Installation {
+	if prrce == nil {
+		return nil
+	}
+	return prrce.Installation
+}
+
 type PushEvent struct {
 	Ref *string `json:"ref,omitempty"`
 
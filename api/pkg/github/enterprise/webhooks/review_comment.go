@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	service_users "getsturdy.com/api/pkg/users/service"
+)
+
+func (svc *Service) HandlePullRequestReviewCommentEvent(ctx context.Context, event *PullRequestReviewCommentEvent) error {
+	gitHubRepoID := event.GetRepo().GetID()
+	installationID := event.GetInstallation().GetID()
+	repo, err := svc.gitHubRepositoryRepo.GetByInstallationAndGitHubRepoID(installationID, gitHubRepoID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return fmt.Errorf("failed to get repository by id: %w", err)
+	}
+
+	pr, err := svc.gitHubPullRequestRepo.GetByGitHubIDAndCodebaseID(event.GetPullRequest().GetID(), repo.CodebaseID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		// the pull request is not in Sturdy, comments on it are not imported
+		return nil
+	default:
+		return fmt.Errorf("failed to get github pull request from db: %w", err)
+	}
+
+	switch event.GetAction() {
+	case "created", "edited":
+		referer := service_users.GitHubPullRequestReferer(gitHubRepoID, event.GetPullRequest().GetID())
+		user, err := svc.getOrCreateUser(ctx, event.GetComment().GetUser(), referer)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if err := svc.githubService.ImportPullRequestComment(ctx, pr, user.ID, event.GetComment()); err != nil {
+			return fmt.Errorf("failed to import pull request comment: %w", err)
+		}
+		return nil
+	case "deleted":
+		if err := svc.githubService.DeletePullRequestComment(ctx, pr, event.GetComment().GetID()); err != nil {
+			return fmt.Errorf("failed to delete pull request comment: %w", err)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
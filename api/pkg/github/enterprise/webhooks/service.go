@@ -175,7 +175,13 @@ func getPRStatus(apiPR *api.PullRequest) github.PullRequestState {
 }
 
 func (svc *Service) getPullRequestAuthor(ctx context.Context, repo *github.Repository, event *PullRequestEvent) (*users.User, error) {
-	if ghUser, err := svc.gitHubUserRepo.GetByUsername(event.GetPullRequest().GetUser().GetLogin()); errors.Is(err, sql.ErrNoRows) {
+	referer := service_users.GitHubPullRequestReferer(event.GetRepo().GetID(), event.GetPullRequest().GetID())
+	return svc.getOrCreateUser(ctx, event.GetPullRequest().GetUser(), referer)
+}
+
+// getOrCreateUser returns the user that is connected to the GitHub user, or creates a shadow user for them.
+func (svc *Service) getOrCreateUser(ctx context.Context, gitHubUser *api.User, referer service_users.Referer) (*users.User, error) {
+	if ghUser, err := svc.gitHubUserRepo.GetByUsername(gitHubUser.GetLogin()); errors.Is(err, sql.ErrNoRows) {
 		// user with this username doesn't exist yet, will create shadow
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to get github user: %w", err)
@@ -186,9 +192,8 @@ func (svc *Service) getPullRequestAuthor(ctx context.Context, repo *github.Repos
 
 	// make up email from the user's login, similar to what github does
 	// see https://docs.github.com/en/account-and-profile/setting-up-and-managing-your-github-user-account/managing-email-preferences/setting-your-commit-email-address
-	email := fmt.Sprintf("%d+%s@users.noreply.github.com", event.GetPullRequest().GetUser().GetID(), event.GetPullRequest().GetUser().GetLogin())
-	name := event.GetPullRequest().GetUser().GetLogin()
-	referer := service_users.GitHubPullRequestReferer(event.GetRepo().GetID(), event.GetPullRequest().GetID())
+	email := fmt.Sprintf("%d+%s@users.noreply.github.com", gitHubUser.GetID(), gitHubUser.GetLogin())
+	name := gitHubUser.GetLogin()
 	user, err := svc.usersService.CreateShadow(ctx, email, referer, &name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create shadow user: %w", err)
@@ -199,7 +204,7 @@ func (svc *Service) getPullRequestAuthor(ctx context.Context, repo *github.Repos
 	if err := svc.gitHubUserRepo.Create(github.User{
 		ID:        uuid.NewString(),
 		UserID:    user.ID,
-		Username:  event.GetPullRequest().GetUser().GetLogin(),
+		Username:  gitHubUser.GetLogin(),
 		CreatedAt: time.Now(),
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create github user: %w", err)
@@ -385,6 +390,11 @@ func (svc *Service) updateExistingPullRequest(
 		return fmt.Errorf("failed to create workspace activity: %w", err)
 	}
 
+	if err := svc.eventsSenderV2.ChangeLanded(ctx, eventsv2.Codebase(ws.CodebaseID), ch); err != nil {
+		svc.logger.Error("failed to send change landed event", zap.Error(err))
+		// do not fail
+	}
+
 	// copy all workspace activities to change activities
 	if err := svc.activityService.SetChange(ctx, ws.ID, ch.ID); err != nil {
 		return fmt.Errorf("failed to set change: %w", err)
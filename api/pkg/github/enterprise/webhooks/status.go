@@ -54,6 +54,7 @@ func (svc *Service) HandleStatusEvent(ctx context.Context, event *StatusEvent) e
 		Description: event.Description,
 		DetailsURL:  event.TargetURL,
 		Timestamp:   getStatusTime(event),
+		Source:      statuses.SourceGitHub,
 	}
 
 	if err := svc.statusService.Set(ctx, status); err != nil {
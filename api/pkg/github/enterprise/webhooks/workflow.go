@@ -98,6 +98,7 @@ func (svc *Service) HandleWorkflowJobEvent(ctx context.Context, event *WorkflowJ
 		Title:      job.GetName(),
 		Timestamp:  getJobTime(job),
 		DetailsURL: job.HTMLURL,
+		Source:     statuses.SourceGitHub,
 	}
 
 	if err := svc.statusService.Set(ctx, status); err != nil {
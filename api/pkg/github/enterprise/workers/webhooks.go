@@ -34,6 +34,7 @@ type WebhookEvent struct {
 	InstallationRepositories *service_github_webhooks.InstallationRepositoriesEvent
 	Push                     *service_github_webhooks.PushEvent
 	PullRequest              *service_github_webhooks.PullRequestEvent
+	PullRequestReviewComment *service_github_webhooks.PullRequestReviewCommentEvent
 	Status                   *service_github_webhooks.StatusEvent
 	WorkflowJob              *service_github_webhooks.WorkflowJobEvent
 }
@@ -121,6 +122,12 @@ func (q *WebhooksQueue) getLogger(event *WebhookEvent) *zap.Logger {
 			zap.Int64("installation_id", event.PullRequest.GetInstallation().GetID()),
 			zap.String("repo", event.PullRequest.GetRepo().GetFullName()),
 		)
+	} else if event.PullRequestReviewComment != nil {
+		return q.logger.With(
+			zap.String("event_type", "pull request review comment"),
+			zap.Int64("installation_id", event.PullRequestReviewComment.GetInstallation().GetID()),
+			zap.String("repo", event.PullRequestReviewComment.GetRepo().GetFullName()),
+		)
 	} else if event.Status != nil {
 		return q.logger.With(
 			zap.String("event_type", "status"),
@@ -151,6 +158,8 @@ func (q *WebhooksQueue) work(ctx context.Context, event *WebhookEvent) error {
 		return q.webhooksService.HandlePushEvent(ctx, event.Push)
 	} else if event.PullRequest != nil {
 		return q.webhooksService.HandlePullRequestEvent(ctx, event.PullRequest)
+	} else if event.PullRequestReviewComment != nil {
+		return q.webhooksService.HandlePullRequestReviewCommentEvent(ctx, event.PullRequestReviewComment)
 	} else if event.Status != nil {
 		return q.webhooksService.HandleStatusEvent(ctx, event.Status)
 	} else if event.WorkflowJob != nil {
@@ -0,0 +1,236 @@
+// Package api is a client for the parts of the GitLab REST API (v4) that Sturdy uses.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type Project struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	WebURL            string `json:"web_url"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// Email is only set in webhooks.
+	Email string `json:"email,omitempty"`
+}
+
+type MergeRequest struct {
+	ID              int64   `json:"id"`
+	IID             int64   `json:"iid"`
+	ProjectID       int64   `json:"project_id"`
+	SourceProjectID int64   `json:"source_project_id"`
+	TargetProjectID int64   `json:"target_project_id"`
+	Title           string  `json:"title"`
+	Description     string  `json:"description"`
+	State           string  `json:"state"` // "opened", "closed", "locked" or "merged"
+	SourceBranch    string  `json:"source_branch"`
+	TargetBranch    string  `json:"target_branch"`
+	SHA             string  `json:"sha"`
+	MergeCommitSHA  *string `json:"merge_commit_sha"`
+	WebURL          string  `json:"web_url"`
+	Author          User    `json:"author"`
+	CreatedAt       Time    `json:"created_at"`
+	MergedAt        *Time   `json:"merged_at"`
+	ClosedAt        *Time   `json:"closed_at"`
+}
+
+// ErrorResponse is returned when GitLab responds with an unexpected status code.
+type ErrorResponse struct {
+	StatusCode int
+	// Message is the message from GitLab, it's meant to be shown to users.
+	Message string
+}
+
+func (e *ErrorResponse) Error() string {
+	return fmt.Sprintf("gitlab responded with %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if err is a 404 response from GitLab.
+func IsNotFound(err error) bool {
+	var errorResponse *ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.StatusCode == http.StatusNotFound
+}
+
+type Client struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// New returns a client for the GitLab instance at baseURL, for example "https://gitlab.com". Requests are
+// authenticated with the access token.
+func New(client *http.Client, baseURL, token string) *Client {
+	return &Client{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v4",
+		token:   token,
+	}
+}
+
+// GetProject returns the project by its id, or by its full path, for example "sturdy-dev/sturdy".
+func (c *Client) GetProject(ctx context.Context, idOrPath string) (*Project, error) {
+	var project Project
+	if err := c.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(idOrPath), nil, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// ListOpenMergeRequests returns the first 100 open merge requests of the project.
+func (c *Client) ListOpenMergeRequests(ctx context.Context, projectID int64) ([]*MergeRequest, error) {
+	var mergeRequests []*MergeRequest
+	path := fmt.Sprintf("/projects/%d/merge_requests?state=opened&per_page=100", projectID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &mergeRequests); err != nil {
+		return nil, err
+	}
+	return mergeRequests, nil
+}
+
+func (c *Client) GetMergeRequest(ctx context.Context, projectID, iid int64) (*MergeRequest, error) {
+	var mergeRequest MergeRequest
+	path := fmt.Sprintf("/projects/%d/merge_requests/%d", projectID, iid)
+	if err := c.do(ctx, http.MethodGet, path, nil, &mergeRequest); err != nil {
+		return nil, err
+	}
+	return &mergeRequest, nil
+}
+
+type CreateMergeRequestOptions struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+}
+
+func (c *Client) CreateMergeRequest(ctx context.Context, projectID int64, opts *CreateMergeRequestOptions) (*MergeRequest, error) {
+	var mergeRequest MergeRequest
+	path := fmt.Sprintf("/projects/%d/merge_requests", projectID)
+	if err := c.do(ctx, http.MethodPost, path, opts, &mergeRequest); err != nil {
+		return nil, err
+	}
+	return &mergeRequest, nil
+}
+
+type UpdateMergeRequestOptions struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+func (c *Client) UpdateMergeRequest(ctx context.Context, projectID, iid int64, opts *UpdateMergeRequestOptions) (*MergeRequest, error) {
+	var mergeRequest MergeRequest
+	path := fmt.Sprintf("/projects/%d/merge_requests/%d", projectID, iid)
+	if err := c.do(ctx, http.MethodPut, path, opts, &mergeRequest); err != nil {
+		return nil, err
+	}
+	return &mergeRequest, nil
+}
+
+type AcceptMergeRequestOptions struct {
+	MergeCommitMessage string `json:"merge_commit_message,omitempty"`
+	// SHA must match the head of the source branch, if set. This makes sure that nothing is merged that has not
+	// been reviewed.
+	SHA string `json:"sha,omitempty"`
+}
+
+// AcceptMergeRequest merges the merge request. GitLab responds with 405 or 422 if the merge request can not
+// be merged, for example if it's missing approvals, and with 409 if the SHA does not match.
+func (c *Client) AcceptMergeRequest(ctx context.Context, projectID, iid int64, opts *AcceptMergeRequestOptions) (*MergeRequest, error) {
+	var mergeRequest MergeRequest
+	path := fmt.Sprintf("/projects/%d/merge_requests/%d/merge", projectID, iid)
+	if err := c.do(ctx, http.MethodPut, path, opts, &mergeRequest); err != nil {
+		return nil, err
+	}
+	return &mergeRequest, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return &ErrorResponse{StatusCode: res.StatusCode, Message: errorMessage(res.Body)}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// errorMessage returns the message of an error response. GitLab sends either a string, a list of strings or an
+// object with validation errors per field as the message, and some endpoints send an "error" string instead.
+func errorMessage(body io.Reader) string {
+	var res struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, 64*1024)).Decode(&res); err != nil {
+		return "unknown error"
+	}
+
+	var message string
+	if err := json.Unmarshal(res.Message, &message); err == nil {
+		return message
+	}
+
+	var messages []string
+	if err := json.Unmarshal(res.Message, &messages); err == nil && len(messages) > 0 {
+		return strings.Join(messages, ", ")
+	}
+
+	var fieldMessages map[string][]string
+	if err := json.Unmarshal(res.Message, &fieldMessages); err == nil && len(fieldMessages) > 0 {
+		var parts []string
+		for field, messages := range fieldMessages {
+			parts = append(parts, field+" "+strings.Join(messages, ", "))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, "; ")
+	}
+
+	if res.Error != "" {
+		return res.Error
+	}
+
+	return "unknown error"
+}
@@ -0,0 +1,128 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/gitlab/api"
+	"getsturdy.com/api/pkg/gitlab/gitlabtest"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	server := gitlabtest.NewServer("token")
+	defer server.Close()
+
+	project := server.AddProject("sturdy-dev/sturdy", "main")
+	server.SetBranch(project.ID, "main", "aaa")
+	server.SetBranch(project.ID, "sturdy-mr-1", "bbb")
+
+	client := api.New(http.DefaultClient, server.BaseURL(), "token")
+
+	t.Run("unauthorized", func(t *testing.T) {
+		_, err := api.New(http.DefaultClient, server.BaseURL(), "wrong").GetProject(ctx, "sturdy-dev/sturdy")
+		var errorResponse *api.ErrorResponse
+		if assert.True(t, errors.As(err, &errorResponse)) {
+			assert.Equal(t, http.StatusUnauthorized, errorResponse.StatusCode)
+			assert.Equal(t, "401 Unauthorized", errorResponse.Message)
+		}
+	})
+
+	t.Run("get project by path", func(t *testing.T) {
+		got, err := client.GetProject(ctx, "sturdy-dev/sturdy")
+		assert.NoError(t, err)
+		assert.Equal(t, project, got)
+	})
+
+	t.Run("project not found", func(t *testing.T) {
+		_, err := client.GetProject(ctx, "sturdy-dev/missing")
+		assert.True(t, api.IsNotFound(err))
+	})
+
+	t.Run("merge request round trip", func(t *testing.T) {
+		created, err := client.CreateMergeRequest(ctx, project.ID, &api.CreateMergeRequestOptions{
+			SourceBranch: "sturdy-mr-1",
+			TargetBranch: "main",
+			Title:        "Add feature",
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, int64(1), created.IID)
+		assert.Equal(t, "opened", created.State)
+		assert.Equal(t, "bbb", created.SHA)
+
+		_, err = client.CreateMergeRequest(ctx, project.ID, &api.CreateMergeRequestOptions{
+			SourceBranch: "sturdy-mr-1",
+			TargetBranch: "main",
+			Title:        "Add feature again",
+		})
+		var errorResponse *api.ErrorResponse
+		if assert.True(t, errors.As(err, &errorResponse)) {
+			assert.Equal(t, http.StatusConflict, errorResponse.StatusCode)
+			assert.Equal(t, "Another open merge request already exists for this source branch: !1", errorResponse.Message)
+		}
+
+		title := "Add a better feature"
+		updated, err := client.UpdateMergeRequest(ctx, project.ID, created.IID, &api.UpdateMergeRequestOptions{Title: &title})
+		assert.NoError(t, err)
+		assert.Equal(t, title, updated.Title)
+
+		open, err := client.ListOpenMergeRequests(ctx, project.ID)
+		assert.NoError(t, err)
+		assert.Len(t, open, 1)
+
+		// the branch moved since the merge request was reviewed
+		server.SetBranch(project.ID, "sturdy-mr-1", "ccc")
+		_, err = client.AcceptMergeRequest(ctx, project.ID, created.IID, &api.AcceptMergeRequestOptions{SHA: "bbb"})
+		if assert.True(t, errors.As(err, &errorResponse)) {
+			assert.Equal(t, http.StatusConflict, errorResponse.StatusCode)
+		}
+
+		merged, err := client.AcceptMergeRequest(ctx, project.ID, created.IID, &api.AcceptMergeRequestOptions{SHA: "ccc"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "merged", merged.State)
+			if assert.NotNil(t, merged.MergeCommitSHA) {
+				assert.Equal(t, *merged.MergeCommitSHA, server.Branch(project.ID, "main"))
+			}
+		}
+
+		got, err := client.GetMergeRequest(ctx, project.ID, created.IID)
+		assert.NoError(t, err)
+		assert.Equal(t, "merged", got.State)
+
+		open, err = client.ListOpenMergeRequests(ctx, project.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, open)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		_, err := client.CreateMergeRequest(ctx, project.ID, &api.CreateMergeRequestOptions{})
+		var errorResponse *api.ErrorResponse
+		if assert.True(t, errors.As(err, &errorResponse)) {
+			assert.Equal(t, http.StatusBadRequest, errorResponse.StatusCode)
+			assert.Equal(t, "source_branch can't be blank; target_branch can't be blank; title can't be blank", errorResponse.Message)
+		}
+	})
+
+	t.Run("merge blocked", func(t *testing.T) {
+		server.SetBranch(project.ID, "sturdy-mr-2", "ddd")
+		mr := server.AddMergeRequest(project.ID, api.MergeRequest{SourceBranch: "sturdy-mr-2", TargetBranch: "main", Title: "Blocked"})
+
+		server.MergeBlockedReason = "At least 1 approval is required"
+		defer func() { server.MergeBlockedReason = "" }()
+
+		_, err := client.AcceptMergeRequest(ctx, project.ID, mr.IID, &api.AcceptMergeRequestOptions{})
+		var errorResponse *api.ErrorResponse
+		if assert.True(t, errors.As(err, &errorResponse)) {
+			assert.Equal(t, http.StatusMethodNotAllowed, errorResponse.StatusCode)
+			assert.Equal(t, "At least 1 approval is required", errorResponse.Message)
+		}
+		assert.Equal(t, "opened", server.MergeRequest(project.ID, mr.IID).State)
+	})
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Time is a timestamp from GitLab. The REST API uses RFC 3339, but webhooks use a different format depending
+// on the event, for example "2016-08-12 15:23:28 UTC".
+type Time struct {
+	time.Time
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+}
+
+func (t *Time) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid time %q", s)
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+// Webhook event types, as sent in the X-Gitlab-Event header.
+const (
+	EventTypeMergeRequest = "Merge Request Hook"
+	EventTypePipeline     = "Pipeline Hook"
+	EventTypePush         = "Push Hook"
+)
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// ParseWebhook parses the payload of a webhook. It returns one of *MergeRequestEvent, *PipelineEvent or
+// *PushEvent, or ErrUnknownEventType for events that are not handled.
+func ParseWebhook(eventType string, payload []byte) (any, error) {
+	var event any
+	switch eventType {
+	case EventTypeMergeRequest:
+		event = &MergeRequestEvent{}
+	case EventTypePipeline:
+		event = &PipelineEvent{}
+	case EventTypePush:
+		event = &PushEvent{}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", strings.ToLower(eventType), err)
+	}
+	return event, nil
+}
+
+type EventProject struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+type MergeRequestEvent struct {
+	// User is the user that triggered the event.
+	User             User                   `json:"user"`
+	Project          EventProject           `json:"project"`
+	ObjectAttributes MergeRequestAttributes `json:"object_attributes"`
+}
+
+type MergeRequestAttributes struct {
+	ID              int64  `json:"id"`
+	IID             int64  `json:"iid"`
+	SourceProjectID int64  `json:"source_project_id"`
+	TargetProjectID int64  `json:"target_project_id"`
+	AuthorID        int64  `json:"author_id"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	State           string `json:"state"`
+	// Action is one of "open", "close", "reopen", "update", "approved", "unapproved" or "merge".
+	Action         string  `json:"action"`
+	SourceBranch   string  `json:"source_branch"`
+	TargetBranch   string  `json:"target_branch"`
+	MergeCommitSHA *string `json:"merge_commit_sha"`
+	URL            string  `json:"url"`
+	LastCommit     struct {
+		ID string `json:"id"`
+	} `json:"last_commit"`
+	CreatedAt Time  `json:"created_at"`
+	UpdatedAt *Time `json:"updated_at"`
+}
+
+type PipelineEvent struct {
+	Project          EventProject       `json:"project"`
+	ObjectAttributes PipelineAttributes `json:"object_attributes"`
+}
+
+type PipelineAttributes struct {
+	ID         int64  `json:"id"`
+	Ref        string `json:"ref"`
+	SHA        string `json:"sha"`
+	Status     string `json:"status"`
+	CreatedAt  Time   `json:"created_at"`
+	FinishedAt *Time  `json:"finished_at"`
+}
+
+// Time returns when the pipeline finished, or when it was created if it's still running.
+func (a *PipelineAttributes) Time() time.Time {
+	if a.FinishedAt != nil && !a.FinishedAt.IsZero() {
+		return a.FinishedAt.Time
+	}
+	return a.CreatedAt.Time
+}
+
+// URL returns the link to the pipeline on GitLab.
+func (e *PipelineEvent) URL() string {
+	return fmt.Sprintf("%s/-/pipelines/%d", e.Project.WebURL, e.ObjectAttributes.ID)
+}
+
+type PushEvent struct {
+	Ref       string       `json:"ref"`
+	Before    string       `json:"before"`
+	After     string       `json:"after"`
+	ProjectID int64        `json:"project_id"`
+	Project   EventProject `json:"project"`
+}
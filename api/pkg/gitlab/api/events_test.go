@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/gitlab/api"
+)
+
+func TestParseWebhook_MergeRequest(t *testing.T) {
+	payload := []byte(`{
+		"object_kind": "merge_request",
+		"user": {"id": 1, "name": "Administrator", "username": "root", "email": "admin@example.com"},
+		"project": {"id": 1, "path_with_namespace": "gitlabhq/gitlab-test", "web_url": "http://example.com/gitlabhq/gitlab-test"},
+		"object_attributes": {
+			"id": 99,
+			"iid": 1,
+			"target_branch": "master",
+			"source_branch": "ms-viewport",
+			"source_project_id": 14,
+			"target_project_id": 14,
+			"author_id": 51,
+			"title": "MS-Viewport",
+			"created_at": "2013-12-03T17:23:34Z",
+			"updated_at": "2013-12-03 17:23:34 UTC",
+			"state": "merged",
+			"action": "merge",
+			"merge_commit_sha": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+			"last_commit": {"id": "b83d6e391c22777fca1ed3012fce84f633d7fed0"}
+		}
+	}`)
+
+	event, err := api.ParseWebhook(api.EventTypeMergeRequest, payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+	mr, ok := event.(*api.MergeRequestEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "root", mr.User.Username)
+	assert.Equal(t, int64(1), mr.Project.ID)
+	assert.Equal(t, int64(99), mr.ObjectAttributes.ID)
+	assert.Equal(t, int64(1), mr.ObjectAttributes.IID)
+	assert.Equal(t, "merge", mr.ObjectAttributes.Action)
+	assert.Equal(t, "b83d6e391c22777fca1ed3012fce84f633d7fed0", mr.ObjectAttributes.LastCommit.ID)
+	if assert.NotNil(t, mr.ObjectAttributes.MergeCommitSHA) {
+		assert.Equal(t, "da1560886d4f094c3e6c9ef40349f7d38b5d27d7", *mr.ObjectAttributes.MergeCommitSHA)
+	}
+	expected := time.Date(2013, 12, 3, 17, 23, 34, 0, time.UTC)
+	assert.True(t, expected.Equal(mr.ObjectAttributes.CreatedAt.Time))
+	if assert.NotNil(t, mr.ObjectAttributes.UpdatedAt) {
+		assert.True(t, expected.Equal(mr.ObjectAttributes.UpdatedAt.Time))
+	}
+}
+
+func TestParseWebhook_Pipeline(t *testing.T) {
+	payload := []byte(`{
+		"object_kind": "pipeline",
+		"object_attributes": {
+			"id": 31,
+			"ref": "master",
+			"sha": "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+			"status": "success",
+			"created_at": "2016-08-12 15:23:28 UTC",
+			"finished_at": "2016-08-12 15:26:29 UTC"
+		},
+		"project": {"id": 1, "path_with_namespace": "gitlab-org/gitlab-test", "web_url": "http://192.168.64.1:3005/gitlab-org/gitlab-test"}
+	}`)
+
+	event, err := api.ParseWebhook(api.EventTypePipeline, payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pipeline, ok := event.(*api.PipelineEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "success", pipeline.ObjectAttributes.Status)
+	assert.Equal(t, "http://192.168.64.1:3005/gitlab-org/gitlab-test/-/pipelines/31", pipeline.URL())
+	assert.True(t, time.Date(2016, 8, 12, 15, 26, 29, 0, time.UTC).Equal(pipeline.ObjectAttributes.Time()))
+
+	pipeline.ObjectAttributes.FinishedAt = nil
+	assert.True(t, time.Date(2016, 8, 12, 15, 23, 28, 0, time.UTC).Equal(pipeline.ObjectAttributes.Time()))
+}
+
+func TestParseWebhook_Unknown(t *testing.T) {
+	_, err := api.ParseWebhook("Note Hook", []byte(`{}`))
+	assert.True(t, errors.Is(err, api.ErrUnknownEventType))
+
+	_, err = api.ParseWebhook(api.EventTypePush, []byte(`{"ref": 1}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+)
+
+type IntegrationRepository interface {
+	Get(ctx context.Context, id string) (*gitlab.Integration, error)
+	GetByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*gitlab.Integration, error)
+	Create(ctx context.Context, integration *gitlab.Integration) error
+	Update(ctx context.Context, integration *gitlab.Integration) error
+}
+
+func NewIntegrationRepository(db *sqlx.DB) IntegrationRepository {
+	return &integrationRepo{db: db}
+}
+
+type integrationRepo struct {
+	db *sqlx.DB
+}
+
+func (r *integrationRepo) Get(ctx context.Context, id string) (*gitlab.Integration, error) {
+	var res gitlab.Integration
+	if err := r.db.GetContext(ctx, &res, `SELECT * FROM gitlab_integrations WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to Get: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *integrationRepo) GetByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*gitlab.Integration, error) {
+	var res gitlab.Integration
+	if err := r.db.GetContext(ctx, &res, `SELECT * FROM gitlab_integrations WHERE codebase_id = $1`, codebaseID); err != nil {
+		return nil, fmt.Errorf("failed to GetByCodebaseID: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *integrationRepo) Create(ctx context.Context, integration *gitlab.Integration) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO gitlab_integrations (
+			id, codebase_id, base_url, project_id, project_path, web_url, http_url_to_repo, access_token,
+			webhook_secret, tracked_branch, enabled, created_at, synced_at, last_push_at, last_push_error
+		) VALUES (
+			:id, :codebase_id, :base_url, :project_id, :project_path, :web_url, :http_url_to_repo, :access_token,
+			:webhook_secret, :tracked_branch, :enabled, :created_at, :synced_at, :last_push_at, :last_push_error
+		)`, integration); err != nil {
+		return fmt.Errorf("failed to create gitlab integration: %w", err)
+	}
+	return nil
+}
+
+func (r *integrationRepo) Update(ctx context.Context, integration *gitlab.Integration) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE gitlab_integrations
+		SET access_token = :access_token,
+			webhook_secret = :webhook_secret,
+			tracked_branch = :tracked_branch,
+			enabled = :enabled,
+			synced_at = :synced_at,
+			last_push_at = :last_push_at,
+			last_push_error = :last_push_error
+		WHERE id = :id`, integration); err != nil {
+		return fmt.Errorf("failed to update gitlab integration: %w", err)
+	}
+	return nil
+}
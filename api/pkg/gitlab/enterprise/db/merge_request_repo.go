@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+)
+
+type MergeRequestRepository interface {
+	Create(ctx context.Context, mr *gitlab.MergeRequest) error
+	Update(ctx context.Context, mr *gitlab.MergeRequest) error
+	GetByIID(ctx context.Context, integrationID string, iid int64) (*gitlab.MergeRequest, error)
+	GetByCodebaseIDAndHeadSHA(ctx context.Context, codebaseID codebases.ID, headSHA string) (*gitlab.MergeRequest, error)
+	// GetByWorkspaceID returns the open merge request of the workspace, or the most recently closed one if
+	// there is no open merge request.
+	GetByWorkspaceID(ctx context.Context, workspaceID string) (*gitlab.MergeRequest, error)
+	ListOpenByWorkspaceID(ctx context.Context, workspaceID string) ([]*gitlab.MergeRequest, error)
+}
+
+func NewMergeRequestRepository(db *sqlx.DB) MergeRequestRepository {
+	return &mergeRequestRepo{db: db}
+}
+
+type mergeRequestRepo struct {
+	db *sqlx.DB
+}
+
+func (r *mergeRequestRepo) Create(ctx context.Context, mr *gitlab.MergeRequest) error {
+	if _, err := r.db.NamedExecContext(ctx, `INSERT INTO gitlab_merge_requests (
+			id, integration_id, codebase_id, workspace_id, gitlab_id, iid, source_branch, target_branch, head_sha,
+			state, importing, created_by, created_at, updated_at, closed_at, merged_at
+		) VALUES (
+			:id, :integration_id, :codebase_id, :workspace_id, :gitlab_id, :iid, :source_branch, :target_branch, :head_sha,
+			:state, :importing, :created_by, :created_at, :updated_at, :closed_at, :merged_at
+		)`, mr); err != nil {
+		return fmt.Errorf("failed to create gitlab merge request: %w", err)
+	}
+	return nil
+}
+
+func (r *mergeRequestRepo) Update(ctx context.Context, mr *gitlab.MergeRequest) error {
+	if _, err := r.db.NamedExecContext(ctx, `UPDATE gitlab_merge_requests
+		SET source_branch = :source_branch,
+			target_branch = :target_branch,
+			head_sha = :head_sha,
+			state = :state,
+			importing = :importing,
+			updated_at = :updated_at,
+			closed_at = :closed_at,
+			merged_at = :merged_at
+		WHERE id = :id`, mr); err != nil {
+		return fmt.Errorf("failed to update gitlab merge request: %w", err)
+	}
+	return nil
+}
+
+func (r *mergeRequestRepo) GetByIID(ctx context.Context, integrationID string, iid int64) (*gitlab.MergeRequest, error) {
+	var res gitlab.MergeRequest
+	if err := r.db.GetContext(ctx, &res, `SELECT * FROM gitlab_merge_requests WHERE integration_id = $1 AND iid = $2`, integrationID, iid); err != nil {
+		return nil, fmt.Errorf("failed to GetByIID: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *mergeRequestRepo) GetByCodebaseIDAndHeadSHA(ctx context.Context, codebaseID codebases.ID, headSHA string) (*gitlab.MergeRequest, error) {
+	var res gitlab.MergeRequest
+	if err := r.db.GetContext(ctx, &res, `SELECT * FROM gitlab_merge_requests WHERE codebase_id = $1 AND head_sha = $2 ORDER BY created_at DESC LIMIT 1`, codebaseID, headSHA); err != nil {
+		return nil, fmt.Errorf("failed to GetByCodebaseIDAndHeadSHA: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *mergeRequestRepo) GetByWorkspaceID(ctx context.Context, workspaceID string) (*gitlab.MergeRequest, error) {
+	var res gitlab.MergeRequest
+	if err := r.db.GetContext(ctx, &res, `SELECT * FROM gitlab_merge_requests
+		WHERE workspace_id = $1
+		ORDER BY state IN ('open', 'merging') DESC, COALESCE(closed_at, merged_at, created_at) DESC
+		LIMIT 1`, workspaceID); err != nil {
+		return nil, fmt.Errorf("failed to GetByWorkspaceID: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *mergeRequestRepo) ListOpenByWorkspaceID(ctx context.Context, workspaceID string) ([]*gitlab.MergeRequest, error) {
+	var res []*gitlab.MergeRequest
+	if err := r.db.SelectContext(ctx, &res, `SELECT * FROM gitlab_merge_requests WHERE workspace_id = $1 AND state IN ('open', 'merging')`, workspaceID); err != nil {
+		return nil, fmt.Errorf("failed to ListOpenByWorkspaceID: %w", err)
+	}
+	return res, nil
+}
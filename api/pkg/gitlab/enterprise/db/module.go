@@ -0,0 +1,10 @@
+package db
+
+import (
+	"getsturdy.com/api/pkg/di"
+)
+
+func Module(c *di.Container) {
+	c.Register(NewIntegrationRepository)
+	c.Register(NewMergeRequestRepository)
+}
@@ -0,0 +1,146 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"getsturdy.com/api/pkg/gitlab"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+)
+
+type integrationResolver struct {
+	root        *gitLabRootResolver
+	integration *gitlab.Integration
+}
+
+func (r *integrationResolver) ID() graphql.ID {
+	return graphql.ID(r.integration.ID)
+}
+
+func (r *integrationResolver) Codebase(ctx context.Context) (resolvers.CodebaseResolver, error) {
+	id := graphql.ID(r.integration.CodebaseID)
+	return (*r.root.codebaseRootResolver).Codebase(ctx, resolvers.CodebaseArgs{ID: &id})
+}
+
+func (r *integrationResolver) BaseUrl() string {
+	return r.integration.BaseURL
+}
+
+func (r *integrationResolver) ProjectPath() string {
+	return r.integration.ProjectPath
+}
+
+func (r *integrationResolver) WebUrl() string {
+	return r.integration.WebURL
+}
+
+func (r *integrationResolver) TrackedBranch() string {
+	return r.integration.TrackedBranch
+}
+
+func (r *integrationResolver) Enabled() bool {
+	return r.integration.Enabled
+}
+
+func (r *integrationResolver) WebhookPath() string {
+	return "/v3/gitlab/webhook/" + r.integration.ID
+}
+
+func (r *integrationResolver) WebhookSecret() string {
+	return r.integration.WebhookSecret
+}
+
+func (r *integrationResolver) SyncedAt() *int32 {
+	return timestamp(r.integration.SyncedAt)
+}
+
+func (r *integrationResolver) LastPushAt() *int32 {
+	return timestamp(r.integration.LastPushAt)
+}
+
+func (r *integrationResolver) LastPushError() *string {
+	return r.integration.LastPushError
+}
+
+type mergeRequestResolver struct {
+	root *gitLabRootResolver
+	mr   *gitlab.MergeRequest
+}
+
+func (r *mergeRequestResolver) ID() graphql.ID {
+	return graphql.ID(r.mr.ID)
+}
+
+func (r *mergeRequestResolver) Iid() int32 {
+	return int32(r.mr.IID)
+}
+
+func (r *mergeRequestResolver) State() (resolvers.GitLabMergeRequestState, error) {
+	switch r.mr.State {
+	case gitlab.MergeRequestStateOpen:
+		return resolvers.GitLabMergeRequestStateOpen, nil
+	case gitlab.MergeRequestStateClosed:
+		return resolvers.GitLabMergeRequestStateClosed, nil
+	case gitlab.MergeRequestStateMerging:
+		return resolvers.GitLabMergeRequestStateMerging, nil
+	case gitlab.MergeRequestStateMerged:
+		return resolvers.GitLabMergeRequestStateMerged, nil
+	default:
+		return resolvers.GitLabMergeRequestStateUndefined, fmt.Errorf("unknown state: %s", r.mr.State)
+	}
+}
+
+func (r *mergeRequestResolver) SourceBranch() string {
+	return r.mr.SourceBranch
+}
+
+func (r *mergeRequestResolver) TargetBranch() string {
+	return r.mr.TargetBranch
+}
+
+func (r *mergeRequestResolver) HeadSHA() *string {
+	return r.mr.HeadSHA
+}
+
+func (r *mergeRequestResolver) WebUrl(ctx context.Context) (string, error) {
+	integration, err := r.root.service.Get(ctx, r.mr.IntegrationID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/-/merge_requests/%d", integration.WebURL, r.mr.IID), nil
+}
+
+func (r *mergeRequestResolver) Importing() bool {
+	return r.mr.Importing
+}
+
+func (r *mergeRequestResolver) CreatedAt() int32 {
+	return int32(r.mr.CreatedAt.Unix())
+}
+
+func (r *mergeRequestResolver) MergedAt() *int32 {
+	return timestamp(r.mr.MergedAt)
+}
+
+func (r *mergeRequestResolver) ClosedAt() *int32 {
+	return timestamp(r.mr.ClosedAt)
+}
+
+func (r *mergeRequestResolver) Workspace(ctx context.Context) (resolvers.WorkspaceResolver, error) {
+	t := true
+	return (*r.root.workspaceRootResolver).Workspace(ctx, resolvers.WorkspaceArgs{
+		ID:            graphql.ID(r.mr.WorkspaceID),
+		AllowArchived: &t,
+	})
+}
+
+func timestamp(t *time.Time) *int32 {
+	if t == nil {
+		return nil
+	}
+	ts := int32(t.Unix())
+	return &ts
+}
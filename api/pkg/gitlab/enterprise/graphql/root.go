@@ -0,0 +1,222 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	"getsturdy.com/api/pkg/gitlab/enterprise/service"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_user "getsturdy.com/api/pkg/users/service"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+)
+
+type gitLabRootResolver struct {
+	service             *service.Service
+	authService         *service_auth.Service
+	codebaseService     *service_codebase.Service
+	organizationService *service_organization.Service
+	userService         service_user.Service
+	workspaceReader     db_workspaces.WorkspaceReader
+
+	codebaseRootResolver  *resolvers.CodebaseRootResolver
+	workspaceRootResolver *resolvers.WorkspaceRootResolver
+}
+
+func New(
+	service *service.Service,
+	authService *service_auth.Service,
+	codebaseService *service_codebase.Service,
+	organizationService *service_organization.Service,
+	userService service_user.Service,
+	workspaceReader db_workspaces.WorkspaceReader,
+
+	codebaseRootResolver *resolvers.CodebaseRootResolver,
+	workspaceRootResolver *resolvers.WorkspaceRootResolver,
+) resolvers.GitLabRootResolver {
+	return &gitLabRootResolver{
+		service:             service,
+		authService:         authService,
+		codebaseService:     codebaseService,
+		organizationService: organizationService,
+		userService:         userService,
+		workspaceReader:     workspaceReader,
+
+		codebaseRootResolver:  codebaseRootResolver,
+		workspaceRootResolver: workspaceRootResolver,
+	}
+}
+
+func (r *gitLabRootResolver) InternalGitLabIntegrationByCodebaseID(ctx context.Context, codebaseID codebases.ID) (resolvers.GitLabIntegrationResolver, error) {
+	cb, err := r.codebaseService.GetByID(ctx, codebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, cb); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	integration, err := r.service.GetByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return &integrationResolver{root: r, integration: integration}, nil
+}
+
+// InternalGitLabMergeRequestByWorkspaceID is only to be used in contexts where the request is already authenticated
+func (r *gitLabRootResolver) InternalGitLabMergeRequestByWorkspaceID(ctx context.Context, workspaceID string) (resolvers.GitLabMergeRequestResolver, error) {
+	mr, err := r.service.GetMergeRequestByWorkspaceID(ctx, workspaceID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+	return &mergeRequestResolver{root: r, mr: mr}, nil
+}
+
+func (r *gitLabRootResolver) ImportGitLabProject(ctx context.Context, args resolvers.ImportGitLabProjectArgs) (resolvers.GitLabIntegrationResolver, error) {
+	org, err := r.organizationService.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	integration, err := r.service.ImportProject(ctx, service.ImportProjectInput{
+		BaseURL:        args.Input.BaseUrl,
+		ProjectPath:    args.Input.ProjectPath,
+		AccessToken:    args.Input.AccessToken,
+		OrganizationID: &org.ID,
+	})
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &integrationResolver{root: r, integration: integration}, nil
+}
+
+func (r *gitLabRootResolver) UpdateGitLabIntegration(ctx context.Context, args resolvers.UpdateGitLabIntegrationArgs) (resolvers.GitLabIntegrationResolver, error) {
+	integration, err := r.service.Get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	cb, err := r.codebaseService.GetByID(ctx, integration.CodebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, cb); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	updated, err := r.service.Update(ctx, integration.ID, service.UpdateIntegrationInput{
+		AccessToken:   args.Input.AccessToken,
+		TrackedBranch: args.Input.TrackedBranch,
+		Enabled:       args.Input.Enabled,
+	})
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &integrationResolver{root: r, integration: updated}, nil
+}
+
+func (r *gitLabRootResolver) ImportGitLabMergeRequests(ctx context.Context, args resolvers.ImportGitLabMergeRequestsArgs) (resolvers.GitLabIntegrationResolver, error) {
+	cb, err := r.codebaseService.GetByID(ctx, codebases.ID(args.Input.CodebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, cb); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	integration, err := r.service.GetByCodebaseID(ctx, cb.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if !integration.Enabled {
+		return nil, toGqlError(service.ErrIntegrationNotEnabled)
+	}
+
+	if err := r.service.ImportOpenMergeRequests(ctx, integration); err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &integrationResolver{root: r, integration: integration}, nil
+}
+
+func (r *gitLabRootResolver) CreateOrUpdateGitLabMergeRequest(ctx context.Context, args resolvers.CreateOrUpdateGitLabMergeRequestArgs) (resolvers.GitLabMergeRequestResolver, error) {
+	ws, err := r.workspaceReader.Get(string(args.Input.WorkspaceID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, ws); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	user, err := r.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	mr, err := r.service.CreateOrUpdateMergeRequest(ctx, user, ws)
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &mergeRequestResolver{root: r, mr: mr}, nil
+}
+
+func (r *gitLabRootResolver) MergeGitLabMergeRequest(ctx context.Context, args resolvers.MergeGitLabMergeRequestArgs) (resolvers.GitLabMergeRequestResolver, error) {
+	ws, err := r.workspaceReader.Get(string(args.Input.WorkspaceID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, ws); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.service.MergeMergeRequest(ctx, ws); err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return r.InternalGitLabMergeRequestByWorkspaceID(ctx, ws.ID)
+}
+
+func toGqlError(err error) error {
+	var userErr service.GitLabUserError
+	switch {
+	case errors.As(err, &userErr):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "message", userErr.Error())
+	case errors.Is(err, service.ErrInvalidBaseURL),
+		errors.Is(err, service.ErrPrivateBaseURL):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "baseUrl", err.Error())
+	case errors.Is(err, service.ErrProjectNotFound):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "projectPath", err.Error())
+	case errors.Is(err, service.ErrInvalidAccessToken):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "accessToken", err.Error())
+	case errors.Is(err, service.ErrInvalidTrackedBranch):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "trackedBranch", err.Error())
+	case errors.Is(err, service.ErrIntegrationNotEnabled), errors.Is(err, service.ErrNoOpenMergeRequest):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "message", err.Error())
+	default:
+		return gqlerror.Error(err)
+	}
+}
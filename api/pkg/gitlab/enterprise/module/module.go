@@ -0,0 +1,16 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/gitlab/enterprise/db"
+	"getsturdy.com/api/pkg/gitlab/enterprise/graphql"
+	"getsturdy.com/api/pkg/gitlab/enterprise/routes"
+	"getsturdy.com/api/pkg/gitlab/enterprise/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Import(graphql.Module)
+	c.Import(service.Module)
+	c.Import(routes.Module)
+}
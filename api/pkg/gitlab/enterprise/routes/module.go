@@ -0,0 +1,9 @@
+package routes
+
+import (
+	"getsturdy.com/api/pkg/di"
+)
+
+func Module(c *di.Container) {
+	c.Register(Webhook)
+}
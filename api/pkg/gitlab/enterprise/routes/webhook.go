@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/gitlab/enterprise/service"
+)
+
+type WebhookHandler gin.HandlerFunc
+
+// maxWebhookSize is the maximum size of webhook payloads, merge request events of large merge requests can be
+// a few megabytes.
+const maxWebhookSize = 25 * 1024 * 1024
+
+func Webhook(svc *service.Service, logger *zap.Logger) WebhookHandler {
+	logger = logger.Named("GitLabWebhookHandler")
+	return func(c *gin.Context) {
+		integrationID := c.Param("id")
+		eventType := c.GetHeader("X-Gitlab-Event")
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookSize))
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		defer c.Request.Body.Close()
+
+		logger := logger.With(zap.String("integration_id", integrationID), zap.String("event_type", eventType))
+
+		err = svc.HandleWebhook(c.Request.Context(), integrationID, c.GetHeader("X-Gitlab-Token"), eventType, body)
+		switch {
+		case errors.Is(err, service.ErrIntegrationNotFound):
+			c.Status(http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidWebhookToken):
+			logger.Warn("received gitlab webhook with invalid token")
+			c.Status(http.StatusUnauthorized)
+		case err != nil:
+			logger.Error("failed to handle gitlab webhook", zap.Error(err))
+			c.Status(http.StatusInternalServerError)
+		default:
+			c.Status(http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/changes/message"
+	"getsturdy.com/api/pkg/gitlab"
+	"getsturdy.com/api/pkg/gitlab/api"
+	"getsturdy.com/api/pkg/snapshots"
+	"getsturdy.com/api/pkg/snapshots/snapshotter"
+	"getsturdy.com/api/pkg/users"
+	service_users "getsturdy.com/api/pkg/users/service"
+	vcs_view "getsturdy.com/api/pkg/view/vcs"
+	"getsturdy.com/api/pkg/workspaces"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	"getsturdy.com/api/vcs"
+)
+
+var ErrAlreadyImported = errors.New("merge request has already been imported")
+
+// ImportOpenMergeRequests imports all open merge requests of the project as workspaces. Merge requests that
+// are already imported are skipped.
+func (svc *Service) ImportOpenMergeRequests(ctx context.Context, integration *gitlab.Integration) error {
+	mergeRequests, err := svc.client(integration).ListOpenMergeRequests(ctx, integration.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	for _, apiMR := range mergeRequests {
+		svc.logger.Info("importing merge request", zap.Stringer("codebase_id", integration.CodebaseID), zap.Int64("mr_iid", apiMR.IID))
+
+		err := svc.ImportMergeRequest(ctx, integration, apiMR)
+		switch {
+		case errors.Is(err, ErrAlreadyImported):
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to import merge request !%d: %w", apiMR.IID, err)
+		}
+	}
+
+	return nil
+}
+
+// ImportMergeRequest creates a new workspace from the merge request. The workspace is kept up to date with
+// the merge request until it's updated from Sturdy.
+//
+// Merge requests from forks are not imported, as Sturdy can only push to the project itself.
+func (svc *Service) ImportMergeRequest(ctx context.Context, integration *gitlab.Integration, apiMR *api.MergeRequest) error {
+	if apiMR.SourceProjectID != integration.ProjectID {
+		return nil
+	}
+
+	// check that this merge request hasn't been imported before
+	if _, err := svc.mergeRequestRepo.GetByIID(ctx, integration.ID, apiMR.IID); err == nil {
+		return ErrAlreadyImported
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check if merge request is imported: %w", err)
+	}
+
+	author, err := svc.author(ctx, integration, apiMR.ID, apiMR.Author)
+	if err != nil {
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+
+	draftDescription, err := DescriptionFromMergeRequest(apiMR.IID, apiMR.Title, apiMR.Description)
+	if err != nil {
+		return fmt.Errorf("failed to create description: %w", err)
+	}
+
+	t := time.Now()
+	ws := workspaces.Workspace{
+		ID:               uuid.NewString(),
+		CodebaseID:       integration.CodebaseID,
+		UserID:           author.ID,
+		DraftDescription: draftDescription,
+		CreatedAt:        &t,
+	}
+	if err := svc.workspaceWriter.Create(ws); err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	if err := svc.fetchAndSnapshotMergeRequest(ctx, integration, apiMR.IID, &ws); err != nil {
+		// import failed, archive the workspace that we created
+		if err := svc.workspaceWriter.UpdateFields(ctx, ws.ID, db_workspaces.SetArchivedAt(&t)); err != nil {
+			return fmt.Errorf("failed to archive workspace after failed import: %w", err)
+		}
+		return fmt.Errorf("failed to import merge request: %w", err)
+	}
+
+	mr := &gitlab.MergeRequest{
+		ID:            uuid.NewString(),
+		IntegrationID: integration.ID,
+		CodebaseID:    integration.CodebaseID,
+		WorkspaceID:   ws.ID,
+		GitLabID:      apiMR.ID,
+		IID:           apiMR.IID,
+		SourceBranch:  apiMR.SourceBranch,
+		TargetBranch:  apiMR.TargetBranch,
+		HeadSHA:       &apiMR.SHA,
+		State:         gitlab.MergeRequestStateOpen,
+		CreatedBy:     author.ID,
+		CreatedAt:     apiMR.CreatedAt.Time,
+		Importing:     true,
+	}
+	if err := svc.mergeRequestRepo.Create(ctx, mr); err != nil {
+		return fmt.Errorf("failed to save merge request: %w", err)
+	}
+
+	return nil
+}
+
+// author returns the Sturdy user of a GitLab user. GitLab does not share the email addresses of users, so a
+// shadow user with a made up email address is created for users that have not been seen before.
+func (svc *Service) author(ctx context.Context, integration *gitlab.Integration, mergeRequestID int64, gitLabUser api.User) (*users.User, error) {
+	host := "gitlab.com"
+	if u, err := url.Parse(integration.BaseURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	// similar to the private commit emails of GitLab
+	email := fmt.Sprintf("%d-%s@users.noreply.%s", gitLabUser.ID, gitLabUser.Username, host)
+
+	if user, err := svc.usersService.GetByEmail(ctx, email); err == nil {
+		return user, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	name := gitLabUser.Name
+	if name == "" {
+		name = gitLabUser.Username
+	}
+	referer := service_users.GitLabMergeRequestReferer(integration.ProjectID, mergeRequestID)
+	user, err := svc.usersService.CreateShadow(ctx, email, referer, &name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shadow user: %w", err)
+	}
+	return user, nil
+}
+
+func DescriptionFromMergeRequest(iid int64, title, description string) (string, error) {
+	if title == "" {
+		title = fmt.Sprintf("MR %d", iid)
+	}
+	html, err := message.MarkdownToHtml(description)
+	if err != nil {
+		return "", fmt.Errorf("failed to render gitlab description: %w", err)
+	}
+	return "<p>" + title + "</p>" + html, nil
+}
+
+// fetchAndSnapshotMergeRequest fetches the head of the merge request, and snapshots it in the workspace. The
+// changes of the merge request are unsaved changes in the workspace, on top of the commit that the merge request
+// branched off from.
+func (svc *Service) fetchAndSnapshotMergeRequest(ctx context.Context, integration *gitlab.Integration, iid int64, ws *workspaces.Workspace) error {
+	importBranchName := fmt.Sprintf("import-merge-request-%d-%s", iid, uuid.NewString())
+	refspec := fmt.Sprintf("+refs/merge-requests/%d/head:refs/heads/%s", iid, importBranchName)
+
+	if err := svc.fetch(ctx, integration, refspec, "gitLabImportBranchFetch"); err != nil {
+		return err
+	}
+
+	var commonAncestor string
+	if err := svc.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+		head, err := repo.HeadCommit()
+		if err != nil {
+			return fmt.Errorf("could not get head: %w", err)
+		}
+
+		mergeRequestHead, err := repo.BranchCommitID(importBranchName)
+		if err != nil {
+			return fmt.Errorf("could not get head of merge request: %w", err)
+		}
+
+		commonAncestor, err = repo.CommonAncestor(head.Id().String(), mergeRequestHead)
+		if err != nil {
+			return fmt.Errorf("could not find common ancestor: %w", err)
+		}
+
+		// create, or move, the workspace branch
+		if err := repo.CreateNewBranchAt(ws.ID, commonAncestor); err != nil {
+			return fmt.Errorf("failed to create workspace branch: %w", err)
+		}
+
+		return nil
+	}).ExecTrunk(ws.CodebaseID, "gitLabImportBranch"); err != nil {
+		return fmt.Errorf("failed to prepare workspace branch: %w", err)
+	}
+
+	if err := svc.executorProvider.New().
+		Write(vcs_view.CheckoutBranch(importBranchName)).
+		Write(func(repo vcs.RepoWriter) error {
+			// reset to the common ancestor, to make all changes from the merge request unsaved changes
+			if err := repo.ResetMixed(commonAncestor); err != nil {
+				return fmt.Errorf("failed to reset temporary view to common ancestor: %w", err)
+			}
+
+			if _, err := svc.snap.Snapshot(ws.CodebaseID, ws.ID,
+				snapshots.ActionSyncCompleted,
+				snapshotter.WithMarkAsLatestInWorkspace(),
+				snapshotter.WithOnView(*repo.ViewID()),
+				snapshotter.WithOnRepo(repo), // Re-use repo context
+			); err != nil {
+				return fmt.Errorf("failed to create snapshot: %w", err)
+			}
+
+			return nil
+		}).ExecTemporaryView(ws.CodebaseID, "gitLabImportBranch"); err != nil {
+		return fmt.Errorf("failed to create workspace from merge request: %w", err)
+	}
+
+	if err := svc.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+		if err := repo.DeleteBranch(importBranchName); err != nil {
+			return fmt.Errorf("failed to delete import branch: %w", err)
+		}
+		return nil
+	}).ExecTrunk(ws.CodebaseID, "gitLabImportBranchCleanup"); err != nil {
+		return fmt.Errorf("failed to cleanup import branch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/analytics"
+	"getsturdy.com/api/pkg/changes/message"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/gitlab"
+	"getsturdy.com/api/pkg/gitlab/api"
+	"getsturdy.com/api/pkg/users"
+	"getsturdy.com/api/pkg/workspaces"
+)
+
+// GitLabUserError is an error from GitLab that should be shown to the user, for example when a merge request
+// can not be merged because it's missing approvals.
+type GitLabUserError struct {
+	msg string
+}
+
+func (e GitLabUserError) Error() string {
+	return e.msg
+}
+
+var (
+	ErrIntegrationNotEnabled = errors.New("gitlab integration is not enabled")
+	ErrNoOpenMergeRequest    = errors.New("workspace has no open merge request")
+)
+
+func (svc *Service) GetMergeRequestByWorkspaceID(ctx context.Context, workspaceID string) (*gitlab.MergeRequest, error) {
+	return svc.mergeRequestRepo.GetByWorkspaceID(ctx, workspaceID)
+}
+
+// enabledIntegration returns the integration of the codebase, or ErrIntegrationNotEnabled if the codebase
+// does not have an enabled integration.
+func (svc *Service) enabledIntegration(ctx context.Context, codebaseID codebases.ID) (*gitlab.Integration, error) {
+	integration, err := svc.integrationRepo.GetByCodebaseID(ctx, codebaseID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrIntegrationNotEnabled
+	case err != nil:
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	case !integration.Enabled:
+		return nil, ErrIntegrationNotEnabled
+	default:
+		return integration, nil
+	}
+}
+
+// CreateOrUpdateMergeRequest pushes the workspace to GitLab, and opens a merge request for it. If the workspace
+// already has an open merge request, the merge request is updated instead.
+func (svc *Service) CreateOrUpdateMergeRequest(ctx context.Context, user *users.User, ws *workspaces.Workspace) (*gitlab.MergeRequest, error) {
+	integration, err := svc.enabledIntegration(ctx, ws.CodebaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	cb, err := svc.codebaseService.GetByID(ctx, ws.CodebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codebase: %w", err)
+	}
+
+	logger := svc.logger.With(
+		zap.Stringer("codebase_id", ws.CodebaseID),
+		zap.String("workspace_id", ws.ID),
+		zap.Stringer("user_id", user.ID),
+	)
+
+	mergeRequests, err := svc.mergeRequestRepo.ListOpenByWorkspaceID(ctx, ws.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	if len(mergeRequests) > 1 {
+		logger.Error("more than one open merge request for a workspace - this is an erroneous state")
+	}
+
+	mrBranch := "sturdy-mr-" + ws.ID
+	remoteBranchName := mrBranch
+
+	// merge requests that have been imported to Sturdy have user defined branch names, push updates to that branch
+	if len(mergeRequests) > 0 {
+		remoteBranchName = mergeRequests[0].SourceBranch
+	}
+
+	sha, err := svc.remoteService.PrepareBranchForPush(ctx, mrBranch, ws, message.CommitMessage(ws.DraftDescription), user.Name, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare branch: %w", err)
+	}
+
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/heads/%s", mrBranch, remoteBranchName)
+	if err := svc.push(ctx, integration, refspec); err != nil {
+		return nil, err
+	}
+
+	client := svc.client(integration)
+	title := ws.NameOrFallback()
+	description := mrDescription(user.Name, cb, ws)
+
+	if len(mergeRequests) == 0 {
+		apiMR, err := client.CreateMergeRequest(ctx, integration.ProjectID, &api.CreateMergeRequestOptions{
+			SourceBranch: remoteBranchName,
+			TargetBranch: integration.TrackedBranch,
+			Title:        title,
+			Description:  description,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create merge request: %w", err)
+		}
+
+		mr := &gitlab.MergeRequest{
+			ID:            uuid.NewString(),
+			IntegrationID: integration.ID,
+			CodebaseID:    ws.CodebaseID,
+			WorkspaceID:   ws.ID,
+			GitLabID:      apiMR.ID,
+			IID:           apiMR.IID,
+			SourceBranch:  remoteBranchName,
+			TargetBranch:  integration.TrackedBranch,
+			HeadSHA:       &sha,
+			State:         gitlab.MergeRequestStateOpen,
+			CreatedBy:     user.ID,
+			CreatedAt:     time.Now(),
+		}
+		if err := svc.mergeRequestRepo.Create(ctx, mr); err != nil {
+			return nil, fmt.Errorf("failed to save merge request: %w", err)
+		}
+
+		svc.analyticsService.Capture(ctx, "created pull request",
+			analytics.CodebaseID(ws.CodebaseID),
+			analytics.Property("gitlab", true),
+		)
+
+		return mr, nil
+	}
+
+	mr := mergeRequests[0]
+	if _, err := client.UpdateMergeRequest(ctx, integration.ProjectID, mr.IID, &api.UpdateMergeRequestOptions{
+		Title:       &title,
+		Description: &description,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update merge request !%d: %w", mr.IID, err)
+	}
+
+	now := time.Now()
+	mr.UpdatedAt = &now
+	mr.HeadSHA = &sha
+	mr.Importing = false // stop importing changes, the workspace is the source of truth from now on
+	if err := svc.mergeRequestRepo.Update(ctx, mr); err != nil {
+		return nil, fmt.Errorf("failed to update merge request: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "updated pull request",
+		analytics.CodebaseID(ws.CodebaseID),
+		analytics.Property("gitlab", true),
+	)
+
+	return mr, nil
+}
+
+// MergeMergeRequest merges the open merge request of the workspace on GitLab. The merge request is marked as
+// merging, and the merge is completed when GitLab sends the merge request webhook. This way, merge requests
+// that are merged from Sturdy and from GitLab are handled in the same way.
+func (svc *Service) MergeMergeRequest(ctx context.Context, ws *workspaces.Workspace) error {
+	integration, err := svc.enabledIntegration(ctx, ws.CodebaseID)
+	if err != nil {
+		return err
+	}
+
+	mergeRequests, err := svc.mergeRequestRepo.ListOpenByWorkspaceID(ctx, ws.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	if len(mergeRequests) != 1 {
+		return ErrNoOpenMergeRequest
+	}
+	mr := mergeRequests[0]
+
+	previousState := mr.State
+	mr.State = gitlab.MergeRequestStateMerging
+	if err := svc.mergeRequestRepo.Update(ctx, mr); err != nil {
+		return fmt.Errorf("failed to update merge request: %w", err)
+	}
+
+	opts := &api.AcceptMergeRequestOptions{
+		MergeCommitMessage: fmt.Sprintf("Merge branch '%s' into '%s'\n\n%s\n\nMerged via Sturdy",
+			mr.SourceBranch, mr.TargetBranch, message.CommitMessage(ws.DraftDescription)),
+	}
+	// only merge what was pushed from Sturdy
+	if mr.HeadSHA != nil {
+		opts.SHA = *mr.HeadSHA
+	}
+
+	if _, err := svc.client(integration).AcceptMergeRequest(ctx, integration.ProjectID, mr.IID, opts); err != nil {
+		mr.State = previousState
+		if err := svc.mergeRequestRepo.Update(ctx, mr); err != nil {
+			return fmt.Errorf("failed to update merge request: %w", err)
+		}
+
+		var errorResponse *api.ErrorResponse
+		if errors.As(err, &errorResponse) {
+			switch errorResponse.StatusCode {
+			case http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusUnprocessableEntity:
+				// The merge request can't be merged, for example if it's missing approvals or has conflicts.
+				// Proxy GitLab's error message to the end user.
+				return GitLabUserError{errorResponse.Message}
+			case http.StatusConflict:
+				return GitLabUserError{"The merge request has been updated on GitLab, update it from Sturdy and try again"}
+			}
+		}
+
+		return fmt.Errorf("failed to merge merge request: %w", err)
+	}
+
+	return nil
+}
+
+// GitLab renders HTML in merge request descriptions, so the draft description can be used as it is.
+func mrDescription(userName string, cb *codebases.Codebase, ws *workspaces.Workspace) string {
+	var builder strings.Builder
+	builder.WriteString(ws.DraftDescription)
+	builder.WriteString("\n\n---\n\n")
+
+	workspaceUrl := fmt.Sprintf("https://getsturdy.com/%s/%s", cb.GenerateSlug(), ws.ID)
+	builder.WriteString(fmt.Sprintf("This MR was created by %s on [Sturdy](%s).\n\n", userName, workspaceUrl))
+	builder.WriteString("Update this MR by making changes through Sturdy.\n")
+
+	return builder.String()
+}
@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	git_http "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	sender_workspace_activity "getsturdy.com/api/pkg/activity/sender"
+	service_activity "getsturdy.com/api/pkg/activity/service"
+	"getsturdy.com/api/pkg/analytics"
+	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_change "getsturdy.com/api/pkg/changes/service"
+	workers_ci "getsturdy.com/api/pkg/ci/workers"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebases "getsturdy.com/api/pkg/codebases/service"
+	service_comments "getsturdy.com/api/pkg/comments/service"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	"getsturdy.com/api/pkg/gitlab"
+	"getsturdy.com/api/pkg/gitlab/api"
+	db_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/db"
+	"getsturdy.com/api/pkg/http/restricted"
+	service_remote "getsturdy.com/api/pkg/remote/enterprise/service"
+	"getsturdy.com/api/pkg/snapshots/snapshotter"
+	service_statuses "getsturdy.com/api/pkg/statuses/service"
+	service_users "getsturdy.com/api/pkg/users/service"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	service_workspace "getsturdy.com/api/pkg/workspaces/service"
+	"getsturdy.com/api/vcs"
+	"getsturdy.com/api/vcs/executor"
+)
+
+type Service struct {
+	logger *zap.Logger
+
+	integrationRepo  db_gitlab.IntegrationRepository
+	mergeRequestRepo db_gitlab.MergeRequestRepository
+	httpClient       *http.Client
+	outboundCfg      *restricted.Configuration
+
+	workspaceReader db_workspaces.WorkspaceReader
+	workspaceWriter db_workspaces.WorkspaceWriter
+
+	executorProvider executor.Provider
+	snap             snapshotter.Snapshotter
+
+	analyticsService *service_analytics.Service
+	auditService     *service_audit.Service
+	activitySender   sender_workspace_activity.ActivitySender
+	eventsPublisher  *eventsv2.Publisher
+
+	codebaseService  *service_codebases.Service
+	workspaceService service_workspace.Service
+	changeService    *service_change.Service
+	remoteService    *service_remote.EnterpriseService
+	statusService    *service_statuses.Service
+	usersService     service_users.Service
+	commentsService  *service_comments.Service
+	activityService  *service_activity.Service
+
+	buildQueue *workers_ci.BuildQueue
+}
+
+func New(
+	logger *zap.Logger,
+
+	integrationRepo db_gitlab.IntegrationRepository,
+	mergeRequestRepo db_gitlab.MergeRequestRepository,
+
+	workspaceReader db_workspaces.WorkspaceReader,
+	workspaceWriter db_workspaces.WorkspaceWriter,
+
+	executorProvider executor.Provider,
+	snap snapshotter.Snapshotter,
+
+	analyticsService *service_analytics.Service,
+	auditService *service_audit.Service,
+	activitySender sender_workspace_activity.ActivitySender,
+	eventsPublisher *eventsv2.Publisher,
+
+	codebaseService *service_codebases.Service,
+	workspaceService service_workspace.Service,
+	changeService *service_change.Service,
+	remoteService *service_remote.EnterpriseService,
+	statusService *service_statuses.Service,
+	usersService service_users.Service,
+	commentsService *service_comments.Service,
+	activityService *service_activity.Service,
+
+	buildQueue *workers_ci.BuildQueue,
+
+	outboundCfg *restricted.Configuration,
+) *Service {
+	return &Service{
+		logger: logger.Named("gitlab"),
+
+		integrationRepo:  integrationRepo,
+		mergeRequestRepo: mergeRequestRepo,
+		httpClient:       restricted.NewClient(outboundCfg, 30*time.Second),
+
+		workspaceReader: workspaceReader,
+		workspaceWriter: workspaceWriter,
+
+		executorProvider: executorProvider,
+		snap:             snap,
+
+		analyticsService: analyticsService,
+		auditService:     auditService,
+		activitySender:   activitySender,
+		eventsPublisher:  eventsPublisher,
+
+		codebaseService:  codebaseService,
+		workspaceService: workspaceService,
+		changeService:    changeService,
+		remoteService:    remoteService,
+		statusService:    statusService,
+		usersService:     usersService,
+		commentsService:  commentsService,
+		activityService:  activityService,
+
+		buildQueue: buildQueue,
+
+		outboundCfg: outboundCfg,
+	}
+}
+
+func (svc *Service) Get(ctx context.Context, id string) (*gitlab.Integration, error) {
+	return svc.integrationRepo.Get(ctx, id)
+}
+
+func (svc *Service) GetByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*gitlab.Integration, error) {
+	return svc.integrationRepo.GetByCodebaseID(ctx, codebaseID)
+}
+
+// client returns a GitLab API client that is authenticated as the integration.
+func (svc *Service) client(integration *gitlab.Integration) *api.Client {
+	return api.New(svc.httpClient, integration.BaseURL, integration.AccessToken)
+}
+
+// credentials returns the auth method to use when pulling from and pushing to the project. GitLab accepts
+// access tokens as the password of any user, "oauth2" is used by convention.
+func credentials(integration *gitlab.Integration) transport.AuthMethod {
+	return &git_http.BasicAuth{
+		Username: "oauth2",
+		Password: integration.AccessToken,
+	}
+}
+
+var (
+	ErrInvalidBaseURL       = errors.New("the url of the gitlab instance must be an http or https url")
+	ErrPrivateBaseURL       = errors.New("the url of the gitlab instance must not point to a private address")
+	ErrProjectNotFound      = errors.New("the project was not found, or the access token does not have access to it")
+	ErrInvalidAccessToken   = errors.New("the access token is invalid or expired")
+	ErrIntegrationDisabled  = errors.New("the gitlab integration is disabled")
+	ErrInvalidTrackedBranch = errors.New("tracked branch can not be empty")
+)
+
+// defaultTrackedBranch is tracked in projects that don't have any branches yet.
+const defaultTrackedBranch = "main"
+
+type ImportProjectInput struct {
+	// BaseURL is the URL of the GitLab instance, for example "https://gitlab.com".
+	BaseURL string
+	// ProjectPath is the full path of the project, for example "sturdy-dev/sturdy".
+	ProjectPath    string
+	AccessToken    string
+	OrganizationID *string
+}
+
+// ImportProject creates a new codebase from a project on GitLab. Trunk of the codebase is pulled from the
+// default branch of the project.
+func (svc *Service) ImportProject(ctx context.Context, input ImportProjectInput) (*gitlab.Integration, error) {
+	u, err := url.Parse(input.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidBaseURL
+	}
+	// host names are checked again after they are resolved, when calling the api and before fetching and pushing
+	if !svc.outboundCfg.IsAllowedHost(u.Hostname()) {
+		return nil, ErrPrivateBaseURL
+	}
+
+	project, err := api.New(svc.httpClient, input.BaseURL, input.AccessToken).GetProject(ctx, input.ProjectPath)
+	if err != nil {
+		return nil, apiError(err)
+	}
+
+	// the project is cloned from the url in the response, so it must not point to a private address either
+	if repoURL, err := url.Parse(project.HTTPURLToRepo); err != nil || !svc.outboundCfg.IsAllowedHost(repoURL.Hostname()) {
+		return nil, ErrPrivateBaseURL
+	}
+
+	webhookSecret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	cb, err := svc.codebaseService.Create(ctx, project.Name, input.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create codebase: %w", err)
+	}
+
+	trackedBranch := project.DefaultBranch
+	if trackedBranch == "" {
+		trackedBranch = defaultTrackedBranch
+	}
+
+	integration := &gitlab.Integration{
+		ID:            uuid.NewString(),
+		CodebaseID:    cb.ID,
+		BaseURL:       input.BaseURL,
+		ProjectID:     project.ID,
+		ProjectPath:   project.PathWithNamespace,
+		WebURL:        project.WebURL,
+		HTTPURLToRepo: project.HTTPURLToRepo,
+		AccessToken:   input.AccessToken,
+		WebhookSecret: webhookSecret,
+		TrackedBranch: trackedBranch,
+		Enabled:       true,
+		CreatedAt:     time.Now(),
+	}
+	if err := svc.integrationRepo.Create(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionGitLabIntegrationCreated, audit.TargetGitLabIntegration, integration.ID,
+		audit.CodebaseID(cb.ID),
+		audit.After(auditValue(integration)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	// empty projects have nothing to pull
+	if project.DefaultBranch != "" {
+		if err := svc.Pull(ctx, integration); err != nil {
+			return nil, fmt.Errorf("failed to pull from gitlab: %w", err)
+		}
+	}
+
+	svc.analyticsService.Capture(ctx, "imported gitlab project", analytics.CodebaseID(cb.ID))
+
+	return integration, nil
+}
+
+type UpdateIntegrationInput struct {
+	// AccessToken is optional, the current token is kept if not set.
+	AccessToken   *string
+	TrackedBranch string
+	Enabled       bool
+}
+
+func (svc *Service) Update(ctx context.Context, id string, input UpdateIntegrationInput) (*gitlab.Integration, error) {
+	if input.TrackedBranch == "" {
+		return nil, ErrInvalidTrackedBranch
+	}
+
+	integration, err := svc.integrationRepo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	before := auditValue(integration)
+
+	if input.AccessToken != nil {
+		// make sure that the new token has access to the project
+		if _, err := api.New(svc.httpClient, integration.BaseURL, *input.AccessToken).GetProject(ctx, fmt.Sprint(integration.ProjectID)); err != nil {
+			return nil, apiError(err)
+		}
+		integration.AccessToken = *input.AccessToken
+	}
+	integration.TrackedBranch = input.TrackedBranch
+	integration.Enabled = input.Enabled
+
+	if err := svc.integrationRepo.Update(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to update integration: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionGitLabIntegrationUpdated, audit.TargetGitLabIntegration, integration.ID,
+		audit.CodebaseID(integration.CodebaseID),
+		audit.Before(before),
+		audit.After(auditValue(integration)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return integration, nil
+}
+
+// maxImportedCommits is the maximum number of new commits that are imported as changes after a pull, the rest
+// are imported when the changelog is read.
+const maxImportedCommits = 100
+
+// Pull fetches the tracked branch from GitLab to trunk.
+func (svc *Service) Pull(ctx context.Context, integration *gitlab.Integration) error {
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/heads/sturdytrunk", integration.TrackedBranch)
+	if err := svc.fetch(ctx, integration, refspec, "gitLabPull"); err != nil {
+		return err
+	}
+
+	if err := svc.changeService.UnsetHeadChangeCache(integration.CodebaseID); err != nil {
+		return fmt.Errorf("failed to unset head: %w", err)
+	}
+
+	if err := svc.changeService.ImportNewCommits(ctx, integration.CodebaseID, maxImportedCommits); err != nil {
+		return fmt.Errorf("failed to import new commits: %w", err)
+	}
+
+	// Allow all workspaces to be rebased/synced on the latest head
+	if err := svc.workspaceWriter.UnsetUpToDateWithTrunkForAllInCodebase(integration.CodebaseID); err != nil {
+		return fmt.Errorf("failed to unset up to date with trunk for all in codebase: %w", err)
+	}
+
+	now := time.Now()
+	integration.SyncedAt = &now
+	if err := svc.integrationRepo.Update(ctx, integration); err != nil {
+		return fmt.Errorf("failed to update integration: %w", err)
+	}
+
+	return nil
+}
+
+// checkRepoURL resolves the host of the repository, git connections can't check the address when connecting.
+func (svc *Service) checkRepoURL(ctx context.Context, integration *gitlab.Integration) error {
+	u, err := url.Parse(integration.HTTPURLToRepo)
+	if err != nil {
+		return fmt.Errorf("failed to parse repository url: %w", err)
+	}
+	if err := svc.outboundCfg.ResolveAndCheckHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateBaseURL, err)
+	}
+	return nil
+}
+
+func (svc *Service) fetch(ctx context.Context, integration *gitlab.Integration, refspec, name string) error {
+	if err := svc.checkRepoURL(ctx, integration); err != nil {
+		return err
+	}
+
+	fetch := func(repo vcs.RepoGitWriter) error {
+		err := repo.FetchUrlRemoteWithCreds(integration.HTTPURLToRepo, credentials(integration), []config.RefSpec{config.RefSpec(refspec)})
+		switch {
+		case errors.Is(err, gogit.NoErrAlreadyUpToDate):
+			return nil
+		case err != nil:
+			return fmt.Errorf("failed to fetch %s: %w", refspec, err)
+		default:
+			return nil
+		}
+	}
+	if err := svc.executorProvider.New().GitWrite(fetch).ExecTrunk(integration.CodebaseID, name); err != nil {
+		return fmt.Errorf("failed to fetch from gitlab: %w", err)
+	}
+	return nil
+}
+
+// push pushes a branch from trunk to GitLab, and saves the result on the integration.
+func (svc *Service) push(ctx context.Context, integration *gitlab.Integration, refspec string) error {
+	var userError string
+	pushErr := svc.checkRepoURL(ctx, integration)
+	if pushErr == nil {
+		pushErr = svc.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+			var err error
+			userError, err = repo.PushRemoteUrlWithRefspec(integration.HTTPURLToRepo, credentials(integration), []config.RefSpec{config.RefSpec(refspec)})
+			switch {
+			case errors.Is(err, gogit.NoErrAlreadyUpToDate):
+				return nil
+			case err != nil:
+				return fmt.Errorf("failed to push %s: %w", refspec, err)
+			default:
+				return nil
+			}
+		}).ExecTrunk(integration.CodebaseID, "gitLabPush")
+	}
+
+	now := time.Now()
+	integration.LastPushAt = &now
+	integration.LastPushError = nil
+	if pushErr != nil {
+		if userError == "" {
+			userError = pushErr.Error()
+		}
+		integration.LastPushError = &userError
+	}
+	if err := svc.integrationRepo.Update(ctx, integration); err != nil {
+		return fmt.Errorf("failed to save push result: %w", err)
+	}
+
+	if pushErr != nil {
+		return fmt.Errorf("failed to push to gitlab: %w", pushErr)
+	}
+	return nil
+}
+
+// apiError returns a user friendly error for the most common reasons that requests to GitLab fail.
+func apiError(err error) error {
+	var errorResponse *api.ErrorResponse
+	switch {
+	case api.IsNotFound(err):
+		return ErrProjectNotFound
+	case errors.As(err, &errorResponse) && errorResponse.StatusCode == http.StatusUnauthorized:
+		return ErrInvalidAccessToken
+	default:
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// auditValue returns a copy of the integration that is safe to store in the audit log.
+func auditValue(integration *gitlab.Integration) gitlab.Integration {
+	c := *integration
+	c.AccessToken = "[REDACTED]"
+	c.WebhookSecret = "[REDACTED]"
+	return c
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/activity"
+	"getsturdy.com/api/pkg/analytics"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	"getsturdy.com/api/pkg/gitlab"
+	"getsturdy.com/api/pkg/gitlab/api"
+	"getsturdy.com/api/pkg/statuses"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	"getsturdy.com/api/vcs"
+)
+
+var (
+	ErrIntegrationNotFound = errors.New("gitlab integration not found")
+	ErrInvalidWebhookToken = errors.New("invalid webhook token")
+)
+
+// HandleWebhook handles a webhook that GitLab sent for the integration. token is the value of the X-Gitlab-Token
+// header, and eventType is the value of the X-Gitlab-Event header. Events that are not used are ignored.
+func (svc *Service) HandleWebhook(ctx context.Context, integrationID, token, eventType string, payload []byte) error {
+	integration, err := svc.integrationRepo.Get(ctx, integrationID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrIntegrationNotFound
+	case err != nil:
+		return fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(integration.WebhookSecret)) != 1 {
+		return ErrInvalidWebhookToken
+	}
+
+	if !integration.Enabled {
+		return nil
+	}
+
+	event, err := api.ParseWebhook(eventType, payload)
+	switch {
+	case errors.Is(err, api.ErrUnknownEventType):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	switch event := event.(type) {
+	case *api.MergeRequestEvent:
+		return svc.handleMergeRequestEvent(ctx, integration, event)
+	case *api.PipelineEvent:
+		return svc.handlePipelineEvent(ctx, integration, event)
+	case *api.PushEvent:
+		return svc.handlePushEvent(ctx, integration, event)
+	default:
+		return nil
+	}
+}
+
+func (svc *Service) handlePushEvent(ctx context.Context, integration *gitlab.Integration, event *api.PushEvent) error {
+	if event.Ref != "refs/heads/"+integration.TrackedBranch {
+		return nil
+	}
+	if err := svc.Pull(ctx, integration); err != nil {
+		return fmt.Errorf("failed to pull: %w", err)
+	}
+	return nil
+}
+
+func (svc *Service) handlePipelineEvent(ctx context.Context, integration *gitlab.Integration, event *api.PipelineEvent) error {
+	statusType := gitlab.PipelineStatusType(event.ObjectAttributes.Status)
+	if statusType == statuses.TypeUndefined {
+		return nil
+	}
+
+	detailsURL := event.URL()
+	description := event.ObjectAttributes.Status
+	if err := svc.statusService.Set(ctx, &statuses.Status{
+		ID:          uuid.NewString(),
+		CommitSHA:   event.ObjectAttributes.SHA,
+		CodebaseID:  integration.CodebaseID,
+		Type:        statusType,
+		Title:       "GitLab Pipeline",
+		Description: &description,
+		DetailsURL:  &detailsURL,
+		Timestamp:   event.ObjectAttributes.Time(),
+	}); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+
+	return nil
+}
+
+func (svc *Service) handleMergeRequestEvent(ctx context.Context, integration *gitlab.Integration, event *api.MergeRequestEvent) error {
+	attributes := event.ObjectAttributes
+
+	// only merge requests to the project itself are relevant, not merge requests from the project to other projects
+	if attributes.TargetProjectID != integration.ProjectID {
+		return nil
+	}
+
+	logger := svc.logger.With(
+		zap.Stringer("codebase_id", integration.CodebaseID),
+		zap.Int64("mr_iid", attributes.IID),
+		zap.String("mr_state", attributes.State),
+		zap.String("mr_action", attributes.Action),
+	)
+
+	mr, err := svc.mergeRequestRepo.GetByIID(ctx, integration.ID, attributes.IID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if gitlab.MergeRequestStateFromAPI(attributes.State) != gitlab.MergeRequestStateOpen {
+			// noop, only open merge requests are imported
+			return nil
+		}
+		logger.Info("merge request not found, importing")
+		// the webhook does not include everything that's needed, get the full merge request
+		apiMR, err := svc.client(integration).GetMergeRequest(ctx, integration.ProjectID, attributes.IID)
+		if err != nil {
+			return fmt.Errorf("failed to get merge request: %w", err)
+		}
+		if err := svc.ImportMergeRequest(ctx, integration, apiMR); err != nil && !errors.Is(err, ErrAlreadyImported) {
+			return fmt.Errorf("failed to import merge request: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get merge request: %w", err)
+	}
+
+	logger.Info("merge request found, updating")
+
+	now := time.Now()
+	mr.UpdatedAt = &now
+	mr.TargetBranch = attributes.TargetBranch
+	switch gitlab.MergeRequestStateFromAPI(attributes.State) {
+	case gitlab.MergeRequestStateOpen:
+		mr.State = gitlab.MergeRequestStateOpen
+		mr.ClosedAt = nil
+	case gitlab.MergeRequestStateClosed:
+		mr.State = gitlab.MergeRequestStateClosed
+		mr.ClosedAt = &now
+	case gitlab.MergeRequestStateMerged:
+		mr.State = gitlab.MergeRequestStateMerged
+		mr.MergedAt = &now
+	}
+	if mr.Importing && attributes.LastCommit.ID != "" {
+		mr.HeadSHA = &attributes.LastCommit.ID
+	}
+	if err := svc.mergeRequestRepo.Update(ctx, mr); err != nil {
+		return fmt.Errorf("failed to update merge request: %w", err)
+	}
+
+	ws, err := svc.workspaceReader.Get(mr.WorkspaceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		logger.Warn("handled a gitlab merge request webhook for non-existing workspace", zap.String("workspace_id", mr.WorkspaceID))
+		return nil // noop
+	case err != nil:
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	if mr.Importing {
+		newDescription, err := DescriptionFromMergeRequest(attributes.IID, attributes.Title, attributes.Description)
+		if err != nil {
+			return fmt.Errorf("failed to build description: %w", err)
+		}
+		ws.DraftDescription = newDescription
+		if err := svc.workspaceWriter.UpdateFields(ctx, ws.ID, db_workspaces.SetDraftDescription(newDescription)); err != nil {
+			return fmt.Errorf("failed to update workspace: %w", err)
+		}
+	}
+
+	switch {
+	case mr.State == gitlab.MergeRequestStateClosed && mr.Importing:
+		// the merge request was closed on GitLab, archive the workspace
+		return svc.workspaceService.Archive(ctx, ws)
+	case mr.State == gitlab.MergeRequestStateOpen && mr.Importing:
+		// the merge request was reopened or updated on GitLab
+		if ws.ArchivedAt != nil {
+			if err := svc.workspaceService.Unarchive(ctx, ws); err != nil {
+				return fmt.Errorf("failed to unarchive workspace: %w", err)
+			}
+		}
+		if err := svc.fetchAndSnapshotMergeRequest(ctx, integration, mr.IID, ws); err != nil {
+			return fmt.Errorf("failed to update workspace snapshot from merge request: %w", err)
+		}
+		return nil
+	case mr.State != gitlab.MergeRequestStateMerged:
+		return nil
+	}
+
+	// merge request is merged, land the workspace as a change
+
+	// fast-forward merges don't have a merge commit, the head of the merge request is the new head of the target
+	// branch
+	commitSHA := attributes.LastCommit.ID
+	if attributes.MergeCommitSHA != nil && *attributes.MergeCommitSHA != "" {
+		commitSHA = *attributes.MergeCommitSHA
+	}
+
+	// GitLab also sends a push event for the target branch, but the order of webhooks is not guaranteed
+	if err := svc.Pull(ctx, integration); err != nil {
+		return fmt.Errorf("failed to pull: %w", err)
+	}
+
+	var parentSHA string
+	if err := svc.executorProvider.New().GitRead(func(repo vcs.RepoGitReader) error {
+		parents, err := repo.GetCommitParents(commitSHA)
+		if err != nil {
+			return fmt.Errorf("failed to get parents of %s: %w", commitSHA, err)
+		}
+		if len(parents) == 0 {
+			return fmt.Errorf("commit %s has no parents", commitSHA)
+		}
+		parentSHA = parents[0]
+		return nil
+	}).ExecTrunk(integration.CodebaseID, "gitLabMergeRequestParents"); err != nil {
+		return fmt.Errorf("failed to find parent of merge commit: %w", err)
+	}
+
+	ch, err := svc.changeService.CreateWithCommitAsParent(ctx, ws, commitSHA, parentSHA)
+	if err != nil {
+		return fmt.Errorf("failed to create change: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "pull request merged",
+		analytics.DistinctID(ws.UserID.String()),
+		analytics.CodebaseID(ws.CodebaseID),
+		analytics.Property("workspace_id", ws.ID),
+		analytics.Property("gitlab", true),
+		analytics.Property("importing", mr.Importing),
+	)
+
+	// send change to ci
+	if err := svc.buildQueue.EnqueueChange(ctx, ch); err != nil {
+		logger.Error("failed to enqueue change", zap.Error(err))
+		// do not fail
+	}
+
+	// Create workspace activity that it has created a change
+	if err := svc.activitySender.Codebase(ctx, ws.CodebaseID, ws.ID, ws.UserID, activity.TypeCreatedChange, string(ch.ID)); err != nil {
+		return fmt.Errorf("failed to create workspace activity: %w", err)
+	}
+
+	if err := svc.eventsPublisher.ChangeLanded(ctx, eventsv2.Codebase(ws.CodebaseID), ch); err != nil {
+		logger.Error("failed to send change landed event", zap.Error(err))
+		// do not fail
+	}
+
+	// copy all workspace activities to change activities
+	if err := svc.activityService.SetChange(ctx, ws.ID, ch.ID); err != nil {
+		return fmt.Errorf("failed to set change: %w", err)
+	}
+
+	if err := svc.commentsService.MoveCommentsFromWorkspaceToChange(ctx, ws.ID, ch.ID); err != nil {
+		return fmt.Errorf("failed to migrate comments: %w", err)
+	}
+
+	if err := svc.workspaceService.ArchiveWithChange(ctx, ws, ch); err != nil {
+		return fmt.Errorf("failed to archive workspace: %w", err)
+	}
+
+	return nil
+}
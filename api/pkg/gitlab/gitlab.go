@@ -0,0 +1,102 @@
+package gitlab
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/statuses"
+	"getsturdy.com/api/pkg/users"
+)
+
+// Integration connects a codebase to a project on a GitLab instance. The project is the source of truth of
+// trunk, workspaces are pushed to it as merge requests.
+type Integration struct {
+	ID         string       `db:"id"`
+	CodebaseID codebases.ID `db:"codebase_id"`
+	// BaseURL is the URL of the GitLab instance, for example "https://gitlab.com".
+	BaseURL     string `db:"base_url"`
+	ProjectID   int64  `db:"project_id"`
+	ProjectPath string `db:"project_path"`
+	WebURL      string `db:"web_url"`
+	// HTTPURLToRepo is the URL that the project is cloned from, and pushed to.
+	HTTPURLToRepo string `db:"http_url_to_repo"`
+	// AccessToken is a project or personal access token with the "api" and "write_repository" scopes.
+	AccessToken string `json:"-" db:"access_token"`
+	// WebhookSecret is sent by GitLab in the X-Gitlab-Token header of every webhook.
+	WebhookSecret string `json:"-" db:"webhook_secret"`
+	// TrackedBranch is the branch on GitLab that trunk is mapped to, and that merge requests target.
+	TrackedBranch string     `db:"tracked_branch"`
+	Enabled       bool       `db:"enabled"`
+	CreatedAt     time.Time  `db:"created_at"`
+	SyncedAt      *time.Time `db:"synced_at"`
+
+	LastPushAt    *time.Time `db:"last_push_at"`
+	LastPushError *string    `db:"last_push_error"`
+}
+
+type MergeRequestState string
+
+const (
+	MergeRequestStateUnknown MergeRequestState = ""
+	MergeRequestStateOpen    MergeRequestState = "open"
+	// MergeRequestStateClosed is the state of merge requests that are closed without being merged.
+	MergeRequestStateClosed MergeRequestState = "closed"
+	// MergeRequestStateMerging is the state of merge requests that were merged through Sturdy, while Sturdy is
+	// waiting for GitLab's webhook to complete the merge.
+	MergeRequestStateMerging MergeRequestState = "merging"
+	MergeRequestStateMerged  MergeRequestState = "merged"
+)
+
+// MergeRequestStateFromAPI returns the state of a merge request from its state on GitLab.
+func MergeRequestStateFromAPI(state string) MergeRequestState {
+	switch state {
+	case "opened", "locked": // locked merge requests are being merged
+		return MergeRequestStateOpen
+	case "closed":
+		return MergeRequestStateClosed
+	case "merged":
+		return MergeRequestStateMerged
+	default:
+		return MergeRequestStateUnknown
+	}
+}
+
+type MergeRequest struct {
+	ID            string       `db:"id"`
+	IntegrationID string       `db:"integration_id"`
+	CodebaseID    codebases.ID `db:"codebase_id"`
+	WorkspaceID   string       `db:"workspace_id"`
+	// GitLabID is the globally unique id of the merge request, IID is the number of the merge request in the
+	// project.
+	GitLabID     int64  `db:"gitlab_id"`
+	IID          int64  `db:"iid"`
+	SourceBranch string `db:"source_branch"`
+	TargetBranch string `db:"target_branch"`
+	// HeadSHA is the latest commit that was pushed to the source branch.
+	HeadSHA   *string           `db:"head_sha"`
+	State     MergeRequestState `db:"state"`
+	CreatedBy users.ID          `db:"created_by"`
+	CreatedAt time.Time         `db:"created_at"`
+	UpdatedAt *time.Time        `db:"updated_at"`
+	ClosedAt  *time.Time        `db:"closed_at"`
+	MergedAt  *time.Time        `db:"merged_at"`
+
+	// If importing is true, the workspace is updated with the changes that are pushed to the merge request on
+	// GitLab. Importing stops once the workspace has been pushed from Sturdy.
+	Importing bool `db:"importing"`
+}
+
+// PipelineStatusType returns the status type of a pipeline or job status on GitLab. Pipelines that are skipped
+// or waiting for manual actions have no status type.
+func PipelineStatusType(status string) statuses.Type {
+	switch status {
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return statuses.TypePending
+	case "success":
+		return statuses.TypeHealty
+	case "failed", "canceled":
+		return statuses.TypeFailing
+	default:
+		return statuses.TypeUndefined
+	}
+}
@@ -0,0 +1,37 @@
+package gitlab_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/gitlab"
+	"getsturdy.com/api/pkg/statuses"
+)
+
+func TestMergeRequestStateFromAPI(t *testing.T) {
+	assert.Equal(t, gitlab.MergeRequestStateOpen, gitlab.MergeRequestStateFromAPI("opened"))
+	assert.Equal(t, gitlab.MergeRequestStateOpen, gitlab.MergeRequestStateFromAPI("locked"))
+	assert.Equal(t, gitlab.MergeRequestStateClosed, gitlab.MergeRequestStateFromAPI("closed"))
+	assert.Equal(t, gitlab.MergeRequestStateMerged, gitlab.MergeRequestStateFromAPI("merged"))
+	assert.Equal(t, gitlab.MergeRequestStateUnknown, gitlab.MergeRequestStateFromAPI("draft"))
+}
+
+func TestPipelineStatusType(t *testing.T) {
+	cases := map[string]statuses.Type{
+		"created":              statuses.TypePending,
+		"waiting_for_resource": statuses.TypePending,
+		"preparing":            statuses.TypePending,
+		"pending":              statuses.TypePending,
+		"running":              statuses.TypePending,
+		"scheduled":            statuses.TypePending,
+		"success":              statuses.TypeHealty,
+		"failed":               statuses.TypeFailing,
+		"canceled":             statuses.TypeFailing,
+		"skipped":              statuses.TypeUndefined,
+		"manual":               statuses.TypeUndefined,
+	}
+	for status, expected := range cases {
+		assert.Equal(t, expected, gitlab.PipelineStatusType(status), status)
+	}
+}
@@ -0,0 +1,342 @@
+// Package gitlabtest provides a minimal in-memory stand-in for the GitLab REST API, to test the GitLab
+// integration without a real GitLab instance.
+package gitlabtest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/gitlab/api"
+)
+
+// Server serves projects and merge requests from memory. Git operations are not supported, the heads of
+// branches are set with SetBranch instead.
+type Server struct {
+	*httptest.Server
+
+	// Token is the access token that every request must be authenticated with.
+	Token string
+
+	// MergeBlockedReason makes all merges fail with this message, like GitLab does for merge requests
+	// that are missing approvals.
+	MergeBlockedReason string
+
+	guard         sync.Mutex
+	nextID        int64
+	projects      map[int64]*api.Project
+	branches      map[int64]map[string]string
+	mergeRequests map[int64][]*api.MergeRequest
+}
+
+// NewServer starts a new stand-in. The server must be closed after use.
+func NewServer(token string) *Server {
+	s := &Server{
+		Token:         token,
+		nextID:        1,
+		projects:      map[int64]*api.Project{},
+		branches:      map[int64]map[string]string{},
+		mergeRequests: map[int64][]*api.MergeRequest{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// BaseURL returns the URL of the GitLab instance.
+func (s *Server) BaseURL() string {
+	return s.URL
+}
+
+func (s *Server) id() int64 {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// AddProject creates a new project with the given full path, for example "sturdy-dev/sturdy".
+func (s *Server) AddProject(path, defaultBranch string) *api.Project {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	id := s.id()
+	name := path[strings.LastIndex(path, "/")+1:]
+	project := &api.Project{
+		ID:                id,
+		Name:              name,
+		PathWithNamespace: path,
+		DefaultBranch:     defaultBranch,
+		WebURL:            s.URL + "/" + path,
+		HTTPURLToRepo:     s.URL + "/" + path + ".git",
+	}
+	s.projects[id] = project
+	s.branches[id] = map[string]string{}
+
+	p := *project
+	return &p
+}
+
+// SetBranch sets the head of a branch in the project.
+func (s *Server) SetBranch(projectID int64, branch, sha string) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+	s.branches[projectID][branch] = sha
+}
+
+// Branch returns the head of a branch in the project.
+func (s *Server) Branch(projectID int64, branch string) string {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+	return s.branches[projectID][branch]
+}
+
+// AddMergeRequest adds an open merge request to the project, as if it was created on GitLab.
+func (s *Server) AddMergeRequest(projectID int64, mr api.MergeRequest) *api.MergeRequest {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	created := s.createMergeRequest(projectID, mr)
+	return s.copyMergeRequest(projectID, created)
+}
+
+// MergeRequest returns the merge request, or nil if it does not exist.
+func (s *Server) MergeRequest(projectID, iid int64) *api.MergeRequest {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	mr := s.getMergeRequest(projectID, iid)
+	if mr == nil {
+		return nil
+	}
+	return s.copyMergeRequest(projectID, mr)
+}
+
+func (s *Server) createMergeRequest(projectID int64, mr api.MergeRequest) *api.MergeRequest {
+	mr.ID = s.id()
+	mr.IID = int64(len(s.mergeRequests[projectID]) + 1)
+	mr.ProjectID = projectID
+	mr.SourceProjectID = projectID
+	mr.TargetProjectID = projectID
+	mr.State = "opened"
+	mr.WebURL = fmt.Sprintf("%s/-/merge_requests/%d", s.projects[projectID].WebURL, mr.IID)
+	if mr.CreatedAt.IsZero() {
+		mr.CreatedAt = api.Time{Time: time.Now()}
+	}
+	s.mergeRequests[projectID] = append(s.mergeRequests[projectID], &mr)
+	return &mr
+}
+
+func (s *Server) getMergeRequest(projectID, iid int64) *api.MergeRequest {
+	for _, mr := range s.mergeRequests[projectID] {
+		if mr.IID == iid {
+			return mr
+		}
+	}
+	return nil
+}
+
+// copyMergeRequest returns a copy of the merge request, with the sha of open merge requests set to the head of
+// the source branch.
+func (s *Server) copyMergeRequest(projectID int64, mr *api.MergeRequest) *api.MergeRequest {
+	c := *mr
+	if c.State == "opened" {
+		c.SHA = s.branches[projectID][c.SourceBranch]
+	}
+	return &c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message any) {
+	writeJSON(w, status, map[string]any{"message": message})
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("PRIVATE-TOKEN") != s.Token {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/"), "/")
+	if len(parts) < 2 || parts[0] != "projects" {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	project, found := s.findProject(parts[1])
+	if !found {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, project)
+	case len(parts) == 3 && parts[2] == "merge_requests" && r.Method == http.MethodGet:
+		s.handleListMergeRequests(w, r, project)
+	case len(parts) == 3 && parts[2] == "merge_requests" && r.Method == http.MethodPost:
+		s.handleCreateMergeRequest(w, r, project)
+	case len(parts) >= 4 && parts[2] == "merge_requests":
+		iid, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "404 Not Found")
+			return
+		}
+		mr := s.getMergeRequest(project.ID, iid)
+		if mr == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+
+		switch {
+		case len(parts) == 4 && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, s.copyMergeRequest(project.ID, mr))
+		case len(parts) == 4 && r.Method == http.MethodPut:
+			s.handleUpdateMergeRequest(w, r, project, mr)
+		case len(parts) == 5 && parts[4] == "merge" && r.Method == http.MethodPut:
+			s.handleAcceptMergeRequest(w, r, project, mr)
+		default:
+			writeError(w, http.StatusNotFound, "404 Not Found")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+// findProject finds a project by its id, or by its url encoded path.
+func (s *Server) findProject(idOrPath string) (*api.Project, bool) {
+	if id, err := strconv.ParseInt(idOrPath, 10, 64); err == nil {
+		project, found := s.projects[id]
+		return project, found
+	}
+
+	path, err := url.PathUnescape(idOrPath)
+	if err != nil {
+		return nil, false
+	}
+	for _, project := range s.projects {
+		if project.PathWithNamespace == path {
+			return project, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) handleListMergeRequests(w http.ResponseWriter, r *http.Request, project *api.Project) {
+	state := r.URL.Query().Get("state")
+	res := []*api.MergeRequest{}
+	for _, mr := range s.mergeRequests[project.ID] {
+		if state == "" || state == "all" || mr.State == state {
+			res = append(res, s.copyMergeRequest(project.ID, mr))
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleCreateMergeRequest(w http.ResponseWriter, r *http.Request, project *api.Project) {
+	var opts api.CreateMergeRequestOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	invalid := map[string][]string{}
+	if opts.SourceBranch == "" {
+		invalid["source_branch"] = []string{"can't be blank"}
+	}
+	if opts.TargetBranch == "" {
+		invalid["target_branch"] = []string{"can't be blank"}
+	}
+	if opts.Title == "" {
+		invalid["title"] = []string{"can't be blank"}
+	}
+	if len(invalid) > 0 {
+		writeError(w, http.StatusBadRequest, invalid)
+		return
+	}
+
+	if _, found := s.branches[project.ID][opts.SourceBranch]; !found {
+		writeError(w, http.StatusBadRequest, map[string][]string{"source_branch": {"does not exist"}})
+		return
+	}
+
+	for _, mr := range s.mergeRequests[project.ID] {
+		if mr.State == "opened" && mr.SourceBranch == opts.SourceBranch {
+			writeError(w, http.StatusConflict, []string{fmt.Sprintf("Another open merge request already exists for this source branch: !%d", mr.IID)})
+			return
+		}
+	}
+
+	mr := s.createMergeRequest(project.ID, api.MergeRequest{
+		Title:        opts.Title,
+		Description:  opts.Description,
+		SourceBranch: opts.SourceBranch,
+		TargetBranch: opts.TargetBranch,
+	})
+	writeJSON(w, http.StatusCreated, s.copyMergeRequest(project.ID, mr))
+}
+
+func (s *Server) handleUpdateMergeRequest(w http.ResponseWriter, r *http.Request, project *api.Project, mr *api.MergeRequest) {
+	var opts api.UpdateMergeRequestOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if opts.Title != nil {
+		mr.Title = *opts.Title
+	}
+	if opts.Description != nil {
+		mr.Description = *opts.Description
+	}
+	writeJSON(w, http.StatusOK, s.copyMergeRequest(project.ID, mr))
+}
+
+func (s *Server) handleAcceptMergeRequest(w http.ResponseWriter, r *http.Request, project *api.Project, mr *api.MergeRequest) {
+	var opts api.AcceptMergeRequestOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if mr.State != "opened" {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	if s.MergeBlockedReason != "" {
+		writeError(w, http.StatusMethodNotAllowed, s.MergeBlockedReason)
+		return
+	}
+
+	head := s.branches[project.ID][mr.SourceBranch]
+	if opts.SHA != "" && opts.SHA != head {
+		writeError(w, http.StatusConflict, "SHA does not match HEAD of source branch: "+head)
+		return
+	}
+
+	// there is no git repository, make up a merge commit
+	sum := sha1.Sum([]byte(s.branches[project.ID][mr.TargetBranch] + head))
+	mergeCommitSHA := hex.EncodeToString(sum[:])
+	s.branches[project.ID][mr.TargetBranch] = mergeCommitSHA
+
+	now := api.Time{Time: time.Now()}
+	mr.State = "merged"
+	mr.SHA = head
+	mr.MergeCommitSHA = &mergeCommitSHA
+	mr.MergedAt = &now
+
+	writeJSON(w, http.StatusOK, s.copyMergeRequest(project.ID, mr))
+}
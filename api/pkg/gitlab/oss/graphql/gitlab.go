@@ -0,0 +1,43 @@
+package graphql
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/codebases"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+)
+
+type gitLabRootResolver struct{}
+
+func New() resolvers.GitLabRootResolver {
+	return &gitLabRootResolver{}
+}
+
+func (r *gitLabRootResolver) InternalGitLabIntegrationByCodebaseID(ctx context.Context, codebaseID codebases.ID) (resolvers.GitLabIntegrationResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *gitLabRootResolver) InternalGitLabMergeRequestByWorkspaceID(ctx context.Context, workspaceID string) (resolvers.GitLabMergeRequestResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *gitLabRootResolver) ImportGitLabProject(ctx context.Context, args resolvers.ImportGitLabProjectArgs) (resolvers.GitLabIntegrationResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *gitLabRootResolver) UpdateGitLabIntegration(ctx context.Context, args resolvers.UpdateGitLabIntegrationArgs) (resolvers.GitLabIntegrationResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *gitLabRootResolver) ImportGitLabMergeRequests(ctx context.Context, args resolvers.ImportGitLabMergeRequestsArgs) (resolvers.GitLabIntegrationResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *gitLabRootResolver) CreateOrUpdateGitLabMergeRequest(ctx context.Context, args resolvers.CreateOrUpdateGitLabMergeRequestArgs) (resolvers.GitLabMergeRequestResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *gitLabRootResolver) MergeGitLabMergeRequest(ctx context.Context, args resolvers.MergeGitLabMergeRequestArgs) (resolvers.GitLabMergeRequestResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
@@ -0,0 +1,10 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/gitlab/oss/graphql"
+)
+
+func Module(c *di.Container) {
+	c.Import(graphql.Module)
+}
@@ -11,12 +11,16 @@ import (
 	"strings"
 	"time"
 
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
 	"getsturdy.com/api/pkg/configuration/flags"
 	"getsturdy.com/api/pkg/gitserver/pack"
 	"getsturdy.com/api/pkg/jwt"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/personaltokens"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	"getsturdy.com/api/pkg/servicetokens"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
 	"getsturdy.com/api/pkg/users"
@@ -37,10 +41,12 @@ type Server struct {
 	logger *zap.Logger
 	cfg    *Configuration
 
-	serviceTokensService *service_servicetokens.Service
-	jwtTokensService     *service_jwt.Service
-	codebaseService      *service_codebase.Service
-	executorProvider     executor.Provider
+	serviceTokensService  *service_servicetokens.Service
+	personalTokensService *service_personaltokens.Service
+	jwtTokensService      *service_jwt.Service
+	codebaseService       *service_codebase.Service
+	authService           *service_auth.Service
+	executorProvider      executor.Provider
 
 	router *gin.Engine
 }
@@ -56,8 +62,10 @@ func New(
 	logger *zap.Logger,
 	cfg *Configuration,
 	serviceTokensService *service_servicetokens.Service,
+	personalTokensService *service_personaltokens.Service,
 	jwtTokensService *service_jwt.Service,
 	codebaeService *service_codebase.Service,
+	authService *service_auth.Service,
 	executorProvider executor.Provider,
 ) *Server {
 	gin.SetMode(ginMode())
@@ -67,10 +75,12 @@ func New(
 		logger: logger,
 		cfg:    cfg,
 
-		serviceTokensService: serviceTokensService,
-		jwtTokensService:     jwtTokensService,
-		codebaseService:      codebaeService,
-		executorProvider:     executorProvider,
+		serviceTokensService:  serviceTokensService,
+		personalTokensService: personalTokensService,
+		jwtTokensService:      jwtTokensService,
+		codebaseService:       codebaeService,
+		authService:           authService,
+		executorProvider:      executorProvider,
 
 		router: ginRouter,
 	}
@@ -111,31 +121,72 @@ func (h *Server) jwtTokenAuth(c *gin.Context) {
 		return
 	}
 
-	if username != "import" {
-		c.AbortWithStatus(http.StatusUnauthorized)
-		return
-	}
+	var userID users.ID
+	if strings.HasPrefix(password, personaltokens.Prefix) {
+		// personal access tokens can be used with any username
+		token, err := h.personalTokensService.Authenticate(c.Request.Context(), password)
+		switch {
+		case err == nil:
+		case errors.Is(err, service_personaltokens.ErrInvalidToken),
+			errors.Is(err, service_personaltokens.ErrTokenInactive):
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		default:
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
 
-	userToken, err := h.jwtTokensService.Verify(c.Request.Context(), password, jwt.TokenTypeAuth)
-	if err != nil {
-		c.AbortWithStatus(http.StatusUnauthorized)
-		return
+		scope := personaltokens.ScopeGitRead
+		if getServiceName(c.Request) == "receive-pack" || strings.HasSuffix(c.Request.URL.Path, "/git-receive-pack") {
+			scope = personaltokens.ScopeGitWrite
+		}
+		if !token.HasScope(scope) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		userID = token.UserID
+	} else {
+		if username != "import" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		userToken, err := h.jwtTokensService.Verify(c.Request.Context(), password, jwt.TokenTypeAuth)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		userID = users.ID(userToken.Subject)
 	}
 
 	codebaseID := codebases.ID(c.Param("codebaseId"))
-	accessAllowed, err := h.codebaseService.CanAccess(c.Request.Context(), users.ID(userToken.Subject), codebaseID)
-	if err != nil {
-		h.logger.Error("failed to check access", zap.Error(err))
+	cb, err := h.codebaseService.GetByID(c.Request.Context(), codebaseID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	default:
+		h.logger.Error("failed to get codebase", zap.Error(err))
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	if !accessAllowed {
+	// the scopes of personal access tokens are checked above, they don't have the api scopes that the auth
+	// service checks. The auth service also checks two-factor authentication, if the organization requires it.
+	ctx := auth.NewContext(c.Request.Context(), &auth.Subject{ID: userID.String(), Type: auth.SubjectUser})
+	if err := h.authService.CanWrite(ctx, cb); errors.Is(err, auth.ErrForbidden) {
 		c.AbortWithStatus(http.StatusForbidden)
 		return
+	} else if err != nil {
+		h.logger.Error("failed to check access", zap.Error(err))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
 	}
 
-	c.Set(userIDKey, userToken.Subject)
+	c.Set(userIDKey, userID.String())
 }
 
 func (h *Server) serviceTokenAuth(c *gin.Context) {
@@ -146,18 +197,26 @@ func (h *Server) serviceTokenAuth(c *gin.Context) {
 		return
 	}
 
-	token, err := h.serviceTokensService.Get(c.Request.Context(), username)
-	if errors.Is(err, sql.ErrNoRows) {
-		c.AbortWithStatus(http.StatusUnauthorized)
-		return
-	} else if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, err)
+	// service tokens can only be used to clone and fetch
+	if getServiceName(c.Request) == "receive-pack" {
+		c.AbortWithStatus(http.StatusForbidden)
 		return
 	}
 
-	if err := token.Verify(password); err != nil {
+	token, err := h.serviceTokensService.Authenticate(c.Request.Context(), username, password, servicetokens.ScopeGitRead)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows),
+		errors.Is(err, service_servicetokens.ErrInvalidToken),
+		errors.Is(err, service_servicetokens.ErrTokenInactive):
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
+	case errors.Is(err, service_servicetokens.ErrMissingScope):
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	default:
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
 	}
 
 	c.Set(tokenKey, token)
@@ -1,10 +1,13 @@
 package graphql
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"encoding/json"
 	goerrors "errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 	"getsturdy.com/api/pkg/graphql/schema"
 	"getsturdy.com/api/pkg/ip"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/graph-gophers/graphql-go"
@@ -33,6 +37,7 @@ type RootResolver struct {
 	resolvers.ACLRootResolver
 	resolvers.ActivityRootResolver
 	resolvers.AuthorRootResolver
+	resolvers.AutolinksRootResolver
 	resolvers.BuildkiteInstantIntegrationRootResolver
 	resolvers.ChangeRootResolver
 	resolvers.CodebaseGitHubIntegrationRootResolver
@@ -40,39 +45,50 @@ type RootResolver struct {
 	resolvers.CommentRootResolver
 	resolvers.CryptoRootResolver
 	resolvers.FeaturesRootResolver
+	resolvers.GarbageCollectionRootResolver
 	resolvers.GitHubAppRootResolver
 	resolvers.GitHubPullRequestRootResolver
 	resolvers.GitHubRootResolver
+	resolvers.GitLabRootResolver
 	resolvers.InstallationsRootResolver
 	resolvers.IntegrationRootResolver
 	resolvers.LicenseRootResolver
 	resolvers.NotificationRootResolver
 	resolvers.OnboardingRootResolver
 	resolvers.OrganizationRootResolver
+	resolvers.PersonalTokensRootResolver
 	resolvers.PKIRootResolver
 	resolvers.PresenceRootResolver
 	resolvers.RemoteRootResolver
 	resolvers.ReviewRootResolver
+	resolvers.ScimRootResolver
 	resolvers.ServiceTokensRootResolver
+	resolvers.SessionsRootResolver
 	resolvers.StatusesRootResolver
 	resolvers.SuggestionRootResolver
+	resolvers.TeamRootResolver
+	resolvers.TwoFactorRootResolver
 	resolvers.UserRootResolver
 	resolvers.ViewRootResolver
+	resolvers.WebhooksRootResolver
 	resolvers.WorkspaceRootResolver
 	resolvers.WorkspaceWatcherRootResolver
 
-	schema     *graphql.Schema
-	jwtService *service_jwt.Service
-	logger     *zap.Logger
+	schema                *graphql.Schema
+	jwtService            *service_jwt.Service
+	personalTokensService *service_personaltokens.Service
+	logger                *zap.Logger
 }
 
 func NewRootResolver(
 	logger *zap.Logger,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
 
 	aclRootResolver resolvers.ACLRootResolver,
 	activityRootResolver resolvers.ActivityRootResolver,
 	authorRootResolver resolvers.AuthorRootResolver,
+	autolinksRootResolver resolvers.AutolinksRootResolver,
 	buildkiteRootResolver resolvers.BuildkiteInstantIntegrationRootResolver,
 	changeRootResolver resolvers.ChangeRootResolver,
 	codebaseGitHubIntegrationRootResolver resolvers.CodebaseGitHubIntegrationRootResolver,
@@ -80,34 +96,44 @@ func NewRootResolver(
 	commentsRootResolver resolvers.CommentRootResolver,
 	cryptoRootResolver resolvers.CryptoRootResolver,
 	featuresRootResolver resolvers.FeaturesRootResolver,
+	garbageCollectionRootResolver resolvers.GarbageCollectionRootResolver,
 	gitHubRootResolver resolvers.GitHubRootResolver,
 	githubAppRootResolver resolvers.GitHubAppRootResolver,
+	gitLabRootResolver resolvers.GitLabRootResolver,
 	instantIntegrationRootResolver resolvers.IntegrationRootResolver,
 	licenseRootResolver resolvers.LicenseRootResolver,
 	notificationRootResolver resolvers.NotificationRootResolver,
 	onboardingRootResolver resolvers.OnboardingRootResolver,
 	organizationRootResolver resolvers.OrganizationRootResolver,
+	personalTokensRootResolver resolvers.PersonalTokensRootResolver,
 	pkiRootResolver resolvers.PKIRootResolver,
 	gitHubPullRequestRootResolver resolvers.GitHubPullRequestRootResolver,
 	presenceRootResolver resolvers.PresenceRootResolver,
 	remoteRootResolver resolvers.RemoteRootResolver,
 	reviewRootResolver resolvers.ReviewRootResolver,
 	installationsRootResolver resolvers.InstallationsRootResolver,
+	scimRootResolver resolvers.ScimRootResolver,
 	serviceTokensRootResolver resolvers.ServiceTokensRootResolver,
+	sessionsRootResolver resolvers.SessionsRootResolver,
 	statusRootResolver resolvers.StatusesRootResolver,
 	suggestionRootResolver resolvers.SuggestionRootResolver,
+	teamRootResolver resolvers.TeamRootResolver,
+	twoFactorRootResolver resolvers.TwoFactorRootResolver,
 	userRootResolver resolvers.UserRootResolver,
 	viewRootResolver resolvers.ViewRootResolver,
+	webhooksRootResolver resolvers.WebhooksRootResolver,
 	workspaceRootResolver resolvers.WorkspaceRootResolver,
 	workspaceWatcherRootResolver resolvers.WorkspaceWatcherRootResolver,
 ) *RootResolver {
 	r := &RootResolver{
-		jwtService: jwtService,
-		logger:     logger,
+		jwtService:            jwtService,
+		personalTokensService: personalTokensService,
+		logger:                logger,
 
 		ACLRootResolver:                         aclRootResolver,
 		ActivityRootResolver:                    activityRootResolver,
 		AuthorRootResolver:                      authorRootResolver,
+		AutolinksRootResolver:                   autolinksRootResolver,
 		BuildkiteInstantIntegrationRootResolver: buildkiteRootResolver,
 		ChangeRootResolver:                      changeRootResolver,
 		CodebaseGitHubIntegrationRootResolver:   codebaseGitHubIntegrationRootResolver,
@@ -115,24 +141,32 @@ func NewRootResolver(
 		CommentRootResolver:                     commentsRootResolver,
 		CryptoRootResolver:                      cryptoRootResolver,
 		FeaturesRootResolver:                    featuresRootResolver,
+		GarbageCollectionRootResolver:           garbageCollectionRootResolver,
 		GitHubAppRootResolver:                   githubAppRootResolver,
 		GitHubPullRequestRootResolver:           gitHubPullRequestRootResolver,
 		GitHubRootResolver:                      gitHubRootResolver,
+		GitLabRootResolver:                      gitLabRootResolver,
 		InstallationsRootResolver:               installationsRootResolver,
 		IntegrationRootResolver:                 instantIntegrationRootResolver,
 		LicenseRootResolver:                     licenseRootResolver,
 		NotificationRootResolver:                notificationRootResolver,
 		OnboardingRootResolver:                  onboardingRootResolver,
 		OrganizationRootResolver:                organizationRootResolver,
+		PersonalTokensRootResolver:              personalTokensRootResolver,
 		PKIRootResolver:                         pkiRootResolver,
 		PresenceRootResolver:                    presenceRootResolver,
 		RemoteRootResolver:                      remoteRootResolver,
 		ReviewRootResolver:                      reviewRootResolver,
+		ScimRootResolver:                        scimRootResolver,
 		ServiceTokensRootResolver:               serviceTokensRootResolver,
+		SessionsRootResolver:                    sessionsRootResolver,
 		StatusesRootResolver:                    statusRootResolver,
 		SuggestionRootResolver:                  suggestionRootResolver,
+		TeamRootResolver:                        teamRootResolver,
+		TwoFactorRootResolver:                   twoFactorRootResolver,
 		UserRootResolver:                        userRootResolver,
 		ViewRootResolver:                        viewRootResolver,
+		WebhooksRootResolver:                    webhooksRootResolver,
 		WorkspaceRootResolver:                   workspaceRootResolver,
 		WorkspaceWatcherRootResolver:            workspaceWatcherRootResolver,
 	}
@@ -150,6 +184,24 @@ func (r *RootResolver) HttpHandler() gin.HandlerFunc {
 		ctx := c.Request.Context()
 
 		if subject, ok := auth.SubjectFromGinContext(c); ok {
+			if subject.IsPersonalAccessToken() {
+				body, err := io.ReadAll(c.Request.Body)
+				if err != nil {
+					c.AbortWithStatus(http.StatusBadRequest)
+					return
+				}
+				c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+				// invalid requests are rejected by the handler
+				var params struct {
+					Query string `json:"query"`
+				}
+				_ = json.Unmarshal(body, &params)
+				if err := requireOperationScope(subject, params.Query); err != nil {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+			}
 			ctx = auth.NewContext(ctx, subject)
 		}
 
@@ -175,14 +227,18 @@ func (r *RootResolver) UnauthenticatedHttpHandler(logger *zap.Logger) gin.Handle
 }
 
 type websocketContextBuilder struct {
-	jwtService *service_jwt.Service
+	jwtService            *service_jwt.Service
+	personalTokensService *service_personaltokens.Service
 }
 
 func (c *websocketContextBuilder) BuildContext(ctx context.Context, r *http.Request) (context.Context, error) {
-	subject, err := auth.SubjectFromRequest(r, c.jwtService)
+	subject, err := auth.SubjectFromRequest(r, c.jwtService, c.personalTokensService)
 	if err != nil {
 		return nil, err
 	}
+	if err := subject.RequireAPIScope(false); err != nil {
+		return nil, err
+	}
 
 	ctx = auth.NewContext(ctx, subject)
 	ctx = dataloader.NewContext(ctx)
@@ -191,10 +247,11 @@ func (c *websocketContextBuilder) BuildContext(ctx context.Context, r *http.Requ
 }
 
 func (r *RootResolver) WebsocketHandler() gin.HandlerFunc {
-	h := graphqlws.NewHandlerFunc(r.schema, &relay.Handler{
+	h := graphqlws.NewHandlerFunc(&scopedSchema{Schema: r.schema}, &relay.Handler{
 		Schema: r.schema,
 	}, graphqlws.WithContextGenerator(&websocketContextBuilder{
-		jwtService: r.jwtService,
+		jwtService:            r.jwtService,
+		personalTokensService: r.personalTokensService,
 	}))
 
 	return func(c *gin.Context) {
@@ -12,9 +12,11 @@ type ACLRootResolver interface {
 
 	// Queries
 	CanI(ctx context.Context, args CanIArgs) (bool, error)
+	SimulateACL(ctx context.Context, args SimulateACLArgs) ([]ACLPermissionChangeResolver, error)
 
 	// Mutations
 	UpdateACL(ctx context.Context, args UpdateACLArgs) (ACLResolver, error)
+	RollbackACL(ctx context.Context, args RollbackACLArgs) (ACLResolver, error)
 }
 
 type CanIArgs struct {
@@ -32,7 +34,47 @@ type UpdateACLInput struct {
 	Policy     *string
 }
 
+type RollbackACLArgs struct {
+	Input RollbackACLInput
+}
+
+type RollbackACLInput struct {
+	CodebaseID graphql.ID
+	VersionID  graphql.ID
+}
+
+type SimulateACLArgs struct {
+	Input SimulateACLInput
+}
+
+type SimulateACLInput struct {
+	CodebaseID graphql.ID
+	Policy     string
+}
+
 type ACLResolver interface {
 	ID() graphql.ID
 	Policy() (string, error)
+	Versions(context.Context) ([]ACLVersionResolver, error)
+}
+
+type ACLVersionResolver interface {
+	ID() graphql.ID
+	Version() int32
+	Policy() string
+	CreatedAt() int32
+	Author(context.Context) (AuthorResolver, error)
+	RestoredFrom(context.Context) (ACLVersionResolver, error)
+	Diff(context.Context, ACLVersionDiffArgs) (string, error)
+}
+
+type ACLVersionDiffArgs struct {
+	Against *graphql.ID
+}
+
+type ACLPermissionChangeResolver interface {
+	User(context.Context) (AuthorResolver, error)
+	Action() string
+	Resource() string
+	Granted() bool
 }
@@ -0,0 +1,41 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type AuditLogRootResolver interface {
+	// Internal
+	InternalListByOrganizationID(ctx context.Context, organizationID string, args OrganizationAuditLogArgs) ([]AuditLogEntryResolver, error)
+}
+
+type AuditLogEntryResolver interface {
+	ID() graphql.ID
+	Action() string
+	Actor(context.Context) (AuthorResolver, error)
+	IP() *string
+	TargetType() string
+	TargetID() graphql.ID
+	CodebaseID() *graphql.ID
+	Before() *string
+	After() *string
+	CreatedAt() int32
+}
+
+type OrganizationAuditLogArgs struct {
+	Input *OrganizationAuditLogInput
+}
+
+type OrganizationAuditLogInput struct {
+	Actions    *[]string
+	ActorID    *graphql.ID
+	CodebaseID *graphql.ID
+	TargetType *string
+	TargetID   *graphql.ID
+	Since      *int32
+	Until      *int32
+	Before     *graphql.ID
+	Limit      *int32
+}
@@ -0,0 +1,42 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type AutolinksRootResolver interface {
+	// Internal
+	InternalListByCodebaseID(context.Context, graphql.ID) ([]AutolinkResolver, error)
+
+	// Mutations
+	CreateAutolink(context.Context, CreateAutolinkArgs) (AutolinkResolver, error)
+	DeleteAutolink(context.Context, DeleteAutolinkArgs) (AutolinkResolver, error)
+}
+
+type CreateAutolinkArgs struct {
+	Input CreateAutolinkInput
+}
+
+type CreateAutolinkInput struct {
+	CodebaseID  graphql.ID
+	Prefix      string
+	URLTemplate string
+}
+
+type DeleteAutolinkArgs struct {
+	Input DeleteAutolinkInput
+}
+
+type DeleteAutolinkInput struct {
+	ID graphql.ID
+}
+
+type AutolinkResolver interface {
+	ID() graphql.ID
+	Prefix() string
+	URLTemplate() string
+	CreatedBy(context.Context) (AuthorResolver, error)
+	CreatedAt() int32
+}
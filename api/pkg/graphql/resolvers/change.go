@@ -28,6 +28,8 @@ type ChangeResolver interface {
 	Comments() ([]TopCommentResolver, error)
 	Title() string
 	Description() string
+	RenderedTitle(context.Context) (string, error)
+	RenderedDescription(context.Context) (string, error)
 	TrunkCommitID() (*string, error)
 	Author(context.Context) (AuthorResolver, error)
 	CreatedAt() int32
@@ -77,10 +77,21 @@ type CodebaseResolver interface {
 	IsPublic() bool
 	Organization(ctx context.Context) (OrganizationResolver, error)
 	Remote(context.Context) (RemoteResolver, error)
+	Remotes(context.Context) ([]RemoteResolver, error)
+	GitLabIntegration(context.Context) (GitLabIntegrationResolver, error)
+	ServiceTokens(context.Context) ([]ServiceTokenResovler, error)
+	Webhooks(context.Context) ([]WebhookResolver, error)
+	Autolinks(context.Context) ([]AutolinkResolver, error)
+	GarbageCollectionPolicy(context.Context) (GarbageCollectionPolicyResolver, error)
+	GarbageCollectionRuns(context.Context, CodebaseGarbageCollectionRunsArgs) ([]GarbageCollectionRunResolver, error)
 
 	Writeable(context.Context) bool
 }
 
+type CodebaseGarbageCollectionRunsArgs struct {
+	Last *int32
+}
+
 type CodebaseChangesArgs struct {
 	Input *CodebaseChangesInput
 }
@@ -75,6 +75,7 @@ type CommentResolver interface {
 	CreatedAt() int32
 	DeletedAt() *int32
 	Message() string
+	RenderedMessage(context.Context) (string, error)
 }
 
 type TopCommentResolver interface {
@@ -83,6 +84,7 @@ type TopCommentResolver interface {
 	CreatedAt() int32
 	DeletedAt() *int32
 	Message() string
+	RenderedMessage(context.Context) (string, error)
 	Workspace(ctx context.Context) (WorkspaceResolver, error)
 	Change(ctx context.Context) (ChangeResolver, error)
 	Replies() ([]ReplyCommentResolver, error)
@@ -95,6 +97,7 @@ type ReplyCommentResolver interface {
 	CreatedAt() int32
 	DeletedAt() *int32
 	Message() string
+	RenderedMessage(context.Context) (string, error)
 	Parent(context.Context) (TopCommentResolver, error)
 }
 
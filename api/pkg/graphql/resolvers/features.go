@@ -21,4 +21,7 @@ const (
 	FeatureEmails Feature = "Emails"
 
 	FeatureDownloadChanges Feature = "DownloadChanges"
+
+	FeatureSingleSignOn          Feature = "SingleSignOn"
+	FeaturePasswordLoginDisabled Feature = "PasswordLoginDisabled"
 )
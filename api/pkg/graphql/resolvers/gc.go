@@ -0,0 +1,87 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type GarbageCollectionRootResolver interface {
+	GarbageCollectionRun(context.Context, GarbageCollectionRunArgs) (GarbageCollectionRunResolver, error)
+
+	// Internal
+	InternalPolicyByCodebaseID(context.Context, graphql.ID) (GarbageCollectionPolicyResolver, error)
+	InternalListRunsByCodebaseID(context.Context, graphql.ID, *int32) ([]GarbageCollectionRunResolver, error)
+
+	// Mutations
+	TriggerGarbageCollection(context.Context, TriggerGarbageCollectionArgs) (GarbageCollectionRunResolver, error)
+	UpdateGarbageCollectionPolicy(context.Context, UpdateGarbageCollectionPolicyArgs) (GarbageCollectionPolicyResolver, error)
+}
+
+type GarbageCollectionRunArgs struct {
+	ID graphql.ID
+}
+
+type TriggerGarbageCollectionArgs struct {
+	Input TriggerGarbageCollectionInput
+}
+
+type TriggerGarbageCollectionInput struct {
+	CodebaseID graphql.ID
+	DryRun     *bool
+}
+
+type UpdateGarbageCollectionPolicyArgs struct {
+	Input UpdateGarbageCollectionPolicyInput
+}
+
+type UpdateGarbageCollectionPolicyInput struct {
+	CodebaseID               graphql.ID
+	IntervalSeconds          int32
+	SnapshotRetentionSeconds int32
+}
+
+type GarbageCollectionPolicyResolver interface {
+	IntervalSeconds() int32
+	SnapshotRetentionSeconds() int32
+	UpdatedAt() *int32
+	UpdatedBy(context.Context) (AuthorResolver, error)
+}
+
+type GarbageCollectionRunStatus string
+
+const (
+	GarbageCollectionRunStatusUndefined GarbageCollectionRunStatus = ""
+	GarbageCollectionRunStatusPending   GarbageCollectionRunStatus = "Pending"
+	GarbageCollectionRunStatusRunning   GarbageCollectionRunStatus = "Running"
+	GarbageCollectionRunStatusCompleted GarbageCollectionRunStatus = "Completed"
+)
+
+type GarbageCollectionRunResolver interface {
+	ID() graphql.ID
+	DryRun() bool
+	Status() (GarbageCollectionRunStatus, error)
+	TriggeredBy(context.Context) (AuthorResolver, error)
+	CreatedAt() int32
+	StartedAt() *int32
+	CompletedAt() *int32
+	DurationMs() int32
+	BytesReclaimed() float64
+	Errors() []string
+	Items(context.Context) ([]GarbageCollectionItemResolver, error)
+}
+
+type GarbageCollectionItemType string
+
+const (
+	GarbageCollectionItemTypeUndefined   GarbageCollectionItemType = ""
+	GarbageCollectionItemTypeSnapshot    GarbageCollectionItemType = "Snapshot"
+	GarbageCollectionItemTypeTrunkBranch GarbageCollectionItemType = "TrunkBranch"
+	GarbageCollectionItemTypeViewBranch  GarbageCollectionItemType = "ViewBranch"
+)
+
+type GarbageCollectionItemResolver interface {
+	Type() (GarbageCollectionItemType, error)
+	Name() string
+	ViewID() *graphql.ID
+}
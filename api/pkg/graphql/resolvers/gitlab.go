@@ -0,0 +1,107 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"getsturdy.com/api/pkg/codebases"
+)
+
+type GitLabRootResolver interface {
+	InternalGitLabIntegrationByCodebaseID(ctx context.Context, codebaseID codebases.ID) (GitLabIntegrationResolver, error)
+	InternalGitLabMergeRequestByWorkspaceID(ctx context.Context, workspaceID string) (GitLabMergeRequestResolver, error)
+
+	// Mutations
+	ImportGitLabProject(ctx context.Context, args ImportGitLabProjectArgs) (GitLabIntegrationResolver, error)
+	UpdateGitLabIntegration(ctx context.Context, args UpdateGitLabIntegrationArgs) (GitLabIntegrationResolver, error)
+	ImportGitLabMergeRequests(ctx context.Context, args ImportGitLabMergeRequestsArgs) (GitLabIntegrationResolver, error)
+	CreateOrUpdateGitLabMergeRequest(ctx context.Context, args CreateOrUpdateGitLabMergeRequestArgs) (GitLabMergeRequestResolver, error)
+	MergeGitLabMergeRequest(ctx context.Context, args MergeGitLabMergeRequestArgs) (GitLabMergeRequestResolver, error)
+}
+
+type GitLabIntegrationResolver interface {
+	ID() graphql.ID
+	Codebase(context.Context) (CodebaseResolver, error)
+	BaseUrl() string
+	ProjectPath() string
+	WebUrl() string
+	TrackedBranch() string
+	Enabled() bool
+	WebhookPath() string
+	WebhookSecret() string
+	SyncedAt() *int32
+	LastPushAt() *int32
+	LastPushError() *string
+}
+
+type GitLabMergeRequestState string
+
+const (
+	GitLabMergeRequestStateUndefined GitLabMergeRequestState = ""
+	GitLabMergeRequestStateOpen      GitLabMergeRequestState = "Open"
+	GitLabMergeRequestStateClosed    GitLabMergeRequestState = "Closed"
+	GitLabMergeRequestStateMerging   GitLabMergeRequestState = "Merging"
+	GitLabMergeRequestStateMerged    GitLabMergeRequestState = "Merged"
+)
+
+type GitLabMergeRequestResolver interface {
+	ID() graphql.ID
+	Iid() int32
+	State() (GitLabMergeRequestState, error)
+	SourceBranch() string
+	TargetBranch() string
+	HeadSHA() *string
+	WebUrl(context.Context) (string, error)
+	Importing() bool
+	CreatedAt() int32
+	MergedAt() *int32
+	ClosedAt() *int32
+	Workspace(context.Context) (WorkspaceResolver, error)
+}
+
+type ImportGitLabProjectArgs struct {
+	Input ImportGitLabProjectInput
+}
+
+type ImportGitLabProjectInput struct {
+	OrganizationID graphql.ID
+	BaseUrl        string
+	ProjectPath    string
+	AccessToken    string
+}
+
+type UpdateGitLabIntegrationArgs struct {
+	Input UpdateGitLabIntegrationInput
+}
+
+type UpdateGitLabIntegrationInput struct {
+	ID            graphql.ID
+	AccessToken   *string
+	TrackedBranch string
+	Enabled       bool
+}
+
+type ImportGitLabMergeRequestsArgs struct {
+	Input ImportGitLabMergeRequestsInput
+}
+
+type ImportGitLabMergeRequestsInput struct {
+	CodebaseID graphql.ID
+}
+
+type CreateOrUpdateGitLabMergeRequestArgs struct {
+	Input CreateOrUpdateGitLabMergeRequestInput
+}
+
+type CreateOrUpdateGitLabMergeRequestInput struct {
+	WorkspaceID graphql.ID
+}
+
+type MergeGitLabMergeRequestArgs struct {
+	Input MergeGitLabMergeRequestInput
+}
+
+type MergeGitLabMergeRequestInput struct {
+	WorkspaceID graphql.ID
+}
@@ -10,6 +10,8 @@ type InstallationsRootResolver interface {
 	Installation(context.Context) (InstallationsResolver, error)
 
 	UpdateInstallation(context.Context, UpdateInstallationArgs) (InstallationsResolver, error)
+	RotateSigningKeys(context.Context) (InstallationsResolver, error)
+	SendTestEmail(context.Context) (InstallationsResolver, error)
 }
 
 type InstallationsResolver interface {
@@ -12,13 +12,16 @@ type NotificationRootResolver interface {
 
 	// Mutations
 	ArchiveNotifications(ctx context.Context, args ArchiveNotificationsArgs) ([]NotificationResolver, error)
+	MarkNotificationsSeen(ctx context.Context, args MarkNotificationsSeenArgs) ([]NotificationResolver, error)
 	UpdateNotificationPreference(context.Context, UpdateNotificationPreferenceArgs) (NotificationPreferenceResolver, error)
+	UpdateNotificationDigestFrequency(context.Context, UpdateNotificationDigestFrequencyArgs) (NotificationDigestFrequency, error)
 
 	// Subscriptions
 	UpdatedNotifications(ctx context.Context) (chan NotificationResolver, error)
 
 	// Internal
 	InternalNotificationPreferences(context.Context, users.ID) ([]NotificationPreferenceResolver, error)
+	InternalNotificationDigestFrequency(context.Context, users.ID) (NotificationDigestFrequency, error)
 }
 
 type commonNotificationResolver interface {
@@ -26,6 +29,7 @@ type commonNotificationResolver interface {
 	Type() (NotificationType, error)
 	CreatedAt() int32
 	ArchivedAt() *int32
+	SeenAt() *int32
 	Codebase(ctx context.Context) (CodebaseResolver, error)
 }
 
@@ -72,6 +76,14 @@ type ArchiveNotificationsInput struct {
 	IDs []graphql.ID
 }
 
+type MarkNotificationsSeenArgs struct {
+	Input MarkNotificationsSeenInput
+}
+
+type MarkNotificationsSeenInput struct {
+	IDs []graphql.ID
+}
+
 type UpdateNotificationPreferenceArgs struct {
 	Input UpdateNotificationPreferenceInput
 }
@@ -101,6 +113,24 @@ const (
 	NotificationChannelEmail     NotificationChannel = "Email"
 )
 
+type NotificationDigestFrequency string
+
+const (
+	NotificationDigestFrequencyUndefined NotificationDigestFrequency = ""
+	NotificationDigestFrequencyImmediate NotificationDigestFrequency = "Immediate"
+	NotificationDigestFrequencyHourly    NotificationDigestFrequency = "Hourly"
+	NotificationDigestFrequencyDaily     NotificationDigestFrequency = "Daily"
+	NotificationDigestFrequencyWeekly    NotificationDigestFrequency = "Weekly"
+)
+
+type UpdateNotificationDigestFrequencyArgs struct {
+	Input UpdateNotificationDigestFrequencyInput
+}
+
+type UpdateNotificationDigestFrequencyInput struct {
+	Frequency NotificationDigestFrequency
+}
+
 type NotificationPreferenceResolver interface {
 	Type() (NotificationType, error)
 	Channel() (NotificationChannel, error)
@@ -26,6 +26,11 @@ type OrganizationResolver interface {
 	Name() string
 	Members(context.Context) ([]AuthorResolver, error)
 	Codebases(context.Context) ([]CodebaseResolver, error)
+	Teams(context.Context) ([]TeamResolver, error)
+	AuditLog(context.Context, OrganizationAuditLogArgs) ([]AuditLogEntryResolver, error)
+	ScimTokens(context.Context) ([]ScimTokenResolver, error)
+	Webhooks(context.Context) ([]WebhookResolver, error)
+	RequireTwoFactor() bool
 
 	Licenses(context.Context) ([]LicenseResolver, error)
 
@@ -58,8 +63,9 @@ type UpdatedOrganizationArgs struct {
 }
 
 type UpdateOrganizationInput struct {
-	ID   graphql.ID
-	Name string
+	ID               graphql.ID
+	Name             string
+	RequireTwoFactor *bool
 }
 
 type OrganizationArgs struct {
@@ -0,0 +1,47 @@
+package resolvers
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type PersonalTokensRootResolver interface {
+	// Internal
+	InternalListByUserID(context.Context, users.ID) ([]PersonalAccessTokenResolver, error)
+
+	// Mutations
+	CreatePersonalAccessToken(context.Context, CreatePersonalAccessTokenArgs) (PersonalAccessTokenResolver, error)
+	RevokePersonalAccessToken(context.Context, RevokePersonalAccessTokenArgs) (PersonalAccessTokenResolver, error)
+}
+
+type CreatePersonalAccessTokenArgs struct {
+	Input CreatePersonalAccessTokenInput
+}
+
+type CreatePersonalAccessTokenInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *int32
+}
+
+type RevokePersonalAccessTokenArgs struct {
+	Input RevokePersonalAccessTokenInput
+}
+
+type RevokePersonalAccessTokenInput struct {
+	ID graphql.ID
+}
+
+type PersonalAccessTokenResolver interface {
+	ID() graphql.ID
+	Name() string
+	Scopes() []string
+	CreatedAt() int32
+	LastUsedAt() *int32
+	ExpiresAt() *int32
+
+	Token() *string
+}
@@ -10,15 +10,33 @@ import (
 
 type RemoteRootResolver interface {
 	InternalRemoteByCodebaseID(ctx context.Context, codebaseID codebases.ID) (RemoteResolver, error)
+	InternalRemotesByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]RemoteResolver, error)
+	InternalRemoteBranchByWorkspaceID(ctx context.Context, workspaceID string) (RemoteBranchResolver, error)
 
 	// Mutations
 	CreateOrUpdateCodebaseRemote(ctx context.Context, args CreateOrUpdateCodebaseRemoteArgsArgs) (RemoteResolver, error)
+	CreateCodebaseRemote(ctx context.Context, args CreateCodebaseRemoteArgs) (RemoteResolver, error)
+	UpdateCodebaseRemote(ctx context.Context, args UpdateCodebaseRemoteArgs) (RemoteResolver, error)
+	DeleteCodebaseRemote(ctx context.Context, args DeleteCodebaseRemoteArgs) (RemoteResolver, error)
+	ConfirmRemoteHostKey(ctx context.Context, args ConfirmRemoteHostKeyArgs) (RemoteResolver, error)
+	RotateRemoteHostKey(ctx context.Context, args RotateRemoteHostKeyArgs) (RemoteResolver, error)
+	ImportRemoteBranches(ctx context.Context, args ImportRemoteBranchesArgs) ([]WorkspaceResolver, error)
 }
 
+type RemoteDirection string
+
+const (
+	RemoteDirectionUndefined RemoteDirection = ""
+	RemoteDirectionPull      RemoteDirection = "Pull"
+	RemoteDirectionPush      RemoteDirection = "Push"
+	RemoteDirectionMirror    RemoteDirection = "Mirror"
+)
+
 type RemoteResolver interface {
 	ID() graphql.ID
 	Name() string
 	URL() string
+	Direction() (RemoteDirection, error)
 	TrackedBranch() string
 
 	BasicAuthUsername() *string
@@ -30,6 +48,55 @@ type RemoteResolver interface {
 	BrowserLinkBranch() string
 
 	Enabled() bool
+
+	LastPushedAt() *int32
+	LastPushError() *string
+
+	PollIntervalSeconds() *int32
+	Health() RemoteHealthResolver
+
+	HostKey() (RemoteHostKeyResolver, error)
+	PendingHostKey() (RemoteHostKeyResolver, error)
+
+	Branches(context.Context) ([]RemoteBranchHeadResolver, error)
+}
+
+type RemoteBranchHeadResolver interface {
+	Name() string
+	CommitSHA() string
+}
+
+type RemoteBranchResolver interface {
+	ID() graphql.ID
+	Name() string
+	HeadSHA() string
+	Linked() bool
+	Remote(context.Context) (RemoteResolver, error)
+	CreatedAt() int32
+	UpdatedAt() *int32
+}
+
+type RemoteHostKeyResolver interface {
+	KnownHost() string
+	Fingerprint() string
+	KeyType() string
+}
+
+type RemoteHealthStatus string
+
+const (
+	RemoteHealthStatusUndefined RemoteHealthStatus = ""
+	RemoteHealthStatusUnknown   RemoteHealthStatus = "Unknown"
+	RemoteHealthStatusHealthy   RemoteHealthStatus = "Healthy"
+	RemoteHealthStatusFailing   RemoteHealthStatus = "Failing"
+)
+
+type RemoteHealthResolver interface {
+	Status() (RemoteHealthStatus, error)
+	LastPolledAt() *int32
+	NextPollAt() *int32
+	LastPollError() *string
+	PollFailures() int32
 }
 
 type CreateOrUpdateCodebaseRemoteArgsArgs struct {
@@ -50,4 +117,89 @@ type CreateOrUpdateCodebaseRemoteArgsInput struct {
 	BrowserLinkBranch string
 
 	Enabled bool
+
+	PollIntervalSeconds *int32
+}
+
+type CreateCodebaseRemoteArgs struct {
+	Input CreateCodebaseRemoteInput
+}
+
+type CreateCodebaseRemoteInput struct {
+	CodebaseID    graphql.ID
+	Name          string
+	Url           string
+	Direction     RemoteDirection
+	TrackedBranch string
+
+	BasicAuthUsername *string
+	BasicAuthPassword *string
+	KeyPairID         *graphql.ID
+
+	BrowserLinkRepo   string
+	BrowserLinkBranch string
+
+	Enabled bool
+
+	PollIntervalSeconds *int32
+}
+
+type UpdateCodebaseRemoteArgs struct {
+	Input UpdateCodebaseRemoteInput
+}
+
+type UpdateCodebaseRemoteInput struct {
+	ID            graphql.ID
+	Name          string
+	Url           string
+	Direction     RemoteDirection
+	TrackedBranch string
+
+	BasicAuthUsername *string
+	BasicAuthPassword *string
+	KeyPairID         *graphql.ID
+
+	BrowserLinkRepo   string
+	BrowserLinkBranch string
+
+	Enabled bool
+
+	PollIntervalSeconds *int32
+}
+
+type DeleteCodebaseRemoteArgs struct {
+	Input DeleteCodebaseRemoteInput
+}
+
+type DeleteCodebaseRemoteInput struct {
+	ID graphql.ID
+}
+
+type ConfirmRemoteHostKeyArgs struct {
+	Input ConfirmRemoteHostKeyInput
+}
+
+type ConfirmRemoteHostKeyInput struct {
+	ID          graphql.ID
+	Fingerprint string
+}
+
+type RotateRemoteHostKeyArgs struct {
+	Input RotateRemoteHostKeyInput
+}
+
+type RotateRemoteHostKeyInput struct {
+	ID          graphql.ID
+	Fingerprint *string
+	KnownHost   *string
+}
+
+type ImportRemoteBranchesArgs struct {
+	Input ImportRemoteBranchesInput
+}
+
+type ImportRemoteBranchesInput struct {
+	RemoteID graphql.ID
+	Branches []string
+	Link     bool
 }
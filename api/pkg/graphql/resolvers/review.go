@@ -15,6 +15,7 @@ type ReviewRootResolver interface {
 	CreateOrUpdateReview(ctx context.Context, args CreateReviewArgs) (ReviewResolver, error)
 	DismissReview(ctx context.Context, args DismissReviewArgs) (ReviewResolver, error)
 	RequestReview(ctx context.Context, args RequestReviewArgs) (ReviewResolver, error)
+	RequestTeamReview(ctx context.Context, args RequestTeamReviewArgs) ([]ReviewResolver, error)
 
 	// Subscriptions
 	UpdatedReviews(context.Context) (<-chan ReviewResolver, error)
@@ -56,3 +57,12 @@ type RequestReviewInput struct {
 	WorkspaceID graphql.ID
 	UserID      graphql.ID
 }
+
+type RequestTeamReviewArgs struct {
+	Input RequestTeamReviewInput
+}
+
+type RequestTeamReviewInput struct {
+	WorkspaceID graphql.ID
+	TeamID      graphql.ID
+}
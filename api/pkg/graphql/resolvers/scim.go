@@ -0,0 +1,41 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type ScimRootResolver interface {
+	// Internal
+	InternalListByOrganizationID(context.Context, string) ([]ScimTokenResolver, error)
+
+	// Mutations
+	CreateScimToken(context.Context, CreateScimTokenArgs) (ScimTokenResolver, error)
+	RevokeScimToken(context.Context, RevokeScimTokenArgs) (ScimTokenResolver, error)
+}
+
+type CreateScimTokenArgs struct {
+	Input CreateScimTokenInput
+}
+
+type CreateScimTokenInput struct {
+	OrganizationID graphql.ID
+}
+
+type RevokeScimTokenArgs struct {
+	Input RevokeScimTokenInput
+}
+
+type RevokeScimTokenInput struct {
+	ID graphql.ID
+}
+
+type ScimTokenResolver interface {
+	ID() graphql.ID
+	CreatedBy(context.Context) (AuthorResolver, error)
+	CreatedAt() int32
+	LastUsedAt() *int32
+
+	Token() *string
+}
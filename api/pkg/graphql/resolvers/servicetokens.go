@@ -7,7 +7,12 @@ import (
 )
 
 type ServiceTokensRootResolver interface {
+	// Internal
+	InternalListByCodebaseID(context.Context, graphql.ID) ([]ServiceTokenResovler, error)
+
+	// Mutations
 	CreateServiceToken(context.Context, CreateServiceTokenArgs) (ServiceTokenResovler, error)
+	RevokeServiceToken(context.Context, RevokeServiceTokenArgs) (ServiceTokenResovler, error)
 }
 
 type CreateServiceTokenArgs struct {
@@ -17,13 +22,25 @@ type CreateServiceTokenArgs struct {
 type CreateServiceTokenInput struct {
 	Name            string
 	ShortCodebaseID string
+	Scopes          *[]string
+	ExpiresAt       *int32
+}
+
+type RevokeServiceTokenArgs struct {
+	Input RevokeServiceTokenInput
+}
+
+type RevokeServiceTokenInput struct {
+	ID graphql.ID
 }
 
 type ServiceTokenResovler interface {
 	ID() graphql.ID
 	Name() string
+	Scopes() []string
 	CreatedAt() int32
 	LastUsedAt() *int32
+	ExpiresAt() *int32
 
 	Token() *string
 }
@@ -0,0 +1,35 @@
+package resolvers
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type SessionsRootResolver interface {
+	// Internal
+	InternalListByUserID(context.Context, users.ID) ([]SessionResolver, error)
+
+	// Mutations
+	RevokeSession(context.Context, RevokeSessionArgs) (SessionResolver, error)
+}
+
+type RevokeSessionArgs struct {
+	Input RevokeSessionInput
+}
+
+type RevokeSessionInput struct {
+	ID graphql.ID
+}
+
+type SessionResolver interface {
+	ID() graphql.ID
+	Device() string
+	IP() *string
+	CreatedAt() int32
+	LastSeenAt() int32
+	RevokedAt() *int32
+	Current(context.Context) bool
+}
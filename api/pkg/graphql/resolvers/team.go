@@ -0,0 +1,92 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type TeamRootResolver interface {
+	// Internal
+	InternalListByOrganizationID(ctx context.Context, organizationID string) ([]TeamResolver, error)
+
+	// Queries
+	Team(context.Context, TeamArgs) (TeamResolver, error)
+
+	// Mutations
+	CreateTeam(context.Context, CreateTeamArgs) (TeamResolver, error)
+	UpdateTeam(context.Context, UpdateTeamArgs) (TeamResolver, error)
+	MoveTeam(context.Context, MoveTeamArgs) (TeamResolver, error)
+	DeleteTeam(context.Context, DeleteTeamArgs) (TeamResolver, error)
+	AddUserToTeam(context.Context, AddUserToTeamArgs) (TeamResolver, error)
+	RemoveUserFromTeam(context.Context, RemoveUserFromTeamArgs) (TeamResolver, error)
+}
+
+type TeamResolver interface {
+	ID() graphql.ID
+	Name() string
+	Parent(context.Context) (TeamResolver, error)
+	Children(context.Context) ([]TeamResolver, error)
+	Members(context.Context) ([]TeamMemberResolver, error)
+	Writeable(context.Context) bool
+}
+
+type TeamMemberResolver interface {
+	Author(context.Context) (AuthorResolver, error)
+	Role() string
+}
+
+type TeamArgs struct {
+	ID graphql.ID
+}
+
+type CreateTeamArgs struct {
+	Input CreateTeamInput
+}
+
+type CreateTeamInput struct {
+	OrganizationID graphql.ID
+	ParentID       *graphql.ID
+	Name           string
+}
+
+type UpdateTeamArgs struct {
+	Input UpdateTeamInput
+}
+
+type UpdateTeamInput struct {
+	ID   graphql.ID
+	Name string
+}
+
+type MoveTeamArgs struct {
+	Input MoveTeamInput
+}
+
+type MoveTeamInput struct {
+	ID       graphql.ID
+	ParentID *graphql.ID
+}
+
+type DeleteTeamArgs struct {
+	ID graphql.ID
+}
+
+type AddUserToTeamArgs struct {
+	Input AddUserToTeamInput
+}
+
+type AddUserToTeamInput struct {
+	TeamID graphql.ID
+	UserID graphql.ID
+	Role   *string
+}
+
+type RemoveUserFromTeamArgs struct {
+	Input RemoveUserFromTeamInput
+}
+
+type RemoveUserFromTeamInput struct {
+	TeamID graphql.ID
+	UserID graphql.ID
+}
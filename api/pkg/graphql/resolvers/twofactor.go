@@ -0,0 +1,46 @@
+package resolvers
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type TwoFactorRootResolver interface {
+	// Internal
+	InternalTwoFactor(context.Context, users.ID) (TwoFactorResolver, error)
+
+	// Mutations
+	EnrollTwoFactor(context.Context) (TwoFactorResolver, error)
+	ConfirmTwoFactor(context.Context, TwoFactorCodeArgs) (TwoFactorResolver, error)
+	RegenerateTwoFactorRecoveryCodes(context.Context, TwoFactorCodeArgs) (TwoFactorResolver, error)
+	DisableTwoFactor(context.Context, TwoFactorCodeArgs) (TwoFactorResolver, error)
+	ResetTwoFactor(context.Context, ResetTwoFactorArgs) (TwoFactorResolver, error)
+}
+
+type TwoFactorCodeArgs struct {
+	Input TwoFactorCodeInput
+}
+
+type TwoFactorCodeInput struct {
+	Code string
+}
+
+type ResetTwoFactorArgs struct {
+	Input ResetTwoFactorInput
+}
+
+type ResetTwoFactorInput struct {
+	OrganizationID graphql.ID
+	UserID         graphql.ID
+}
+
+type TwoFactorResolver interface {
+	Enabled() bool
+
+	Secret() *string
+	URI() *string
+	RecoveryCodes() *[]string
+}
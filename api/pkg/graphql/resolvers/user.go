@@ -41,9 +41,10 @@ type VerifyEmailInput struct {
 type UserStatus string
 
 const (
-	UserStatusUndefined UserStatus = ""
-	UserStatusActive    UserStatus = "Active"
-	UserStatusShadow    UserStatus = "Shadow"
+	UserStatusUndefined   UserStatus = ""
+	UserStatusActive      UserStatus = "Active"
+	UserStatusShadow      UserStatus = "Shadow"
+	UserStatusDeactivated UserStatus = "Deactivated"
 )
 
 type UserResolver interface {
@@ -54,10 +55,14 @@ type UserResolver interface {
 	AvatarUrl() *string
 	Status() (UserStatus, error)
 	NotificationPreferences(context.Context) ([]NotificationPreferenceResolver, error)
+	NotificationDigestFrequency(context.Context) (NotificationDigestFrequency, error)
 	GitHubAccount(context.Context) (GitHubAccountResolver, error)
 	NotificationsReceiveNewsletter() (bool, error)
 	Views() ([]ViewResolver, error)
 	LastUsedView(ctx context.Context, args LastUsedViewArgs) (ViewResolver, error)
+	PersonalAccessTokens(context.Context) ([]PersonalAccessTokenResolver, error)
+	Sessions(context.Context) ([]SessionResolver, error)
+	TwoFactor(context.Context) (TwoFactorResolver, error)
 }
 
 type LastUsedViewArgs struct {
@@ -0,0 +1,73 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type WebhooksRootResolver interface {
+	// Internal
+	InternalListByCodebaseID(context.Context, graphql.ID) ([]WebhookResolver, error)
+	InternalListByOrganizationID(context.Context, string) ([]WebhookResolver, error)
+
+	// Mutations
+	CreateWebhook(context.Context, CreateWebhookArgs) (WebhookResolver, error)
+	DeleteWebhook(context.Context, DeleteWebhookArgs) (WebhookResolver, error)
+	RedeliverWebhookDelivery(context.Context, RedeliverWebhookDeliveryArgs) (WebhookResolver, error)
+}
+
+type CreateWebhookArgs struct {
+	Input CreateWebhookInput
+}
+
+type CreateWebhookInput struct {
+	CodebaseID     *graphql.ID
+	OrganizationID *graphql.ID
+	URL            string
+	Events         []string
+}
+
+type DeleteWebhookArgs struct {
+	Input DeleteWebhookInput
+}
+
+type DeleteWebhookInput struct {
+	ID graphql.ID
+}
+
+type RedeliverWebhookDeliveryArgs struct {
+	Input RedeliverWebhookDeliveryInput
+}
+
+type RedeliverWebhookDeliveryInput struct {
+	ID graphql.ID
+}
+
+type WebhookResolver interface {
+	ID() graphql.ID
+	URL() string
+	Events() []string
+	CreatedBy(context.Context) (AuthorResolver, error)
+	CreatedAt() int32
+	Deliveries(context.Context, WebhookDeliveriesArgs) ([]WebhookDeliveryResolver, error)
+
+	Secret() *string
+}
+
+type WebhookDeliveriesArgs struct {
+	Last *int32
+}
+
+type WebhookDeliveryResolver interface {
+	ID() graphql.ID
+	EventID() graphql.ID
+	EventType() string
+	Attempt() int32
+	Payload() string
+	StatusCode() *int32
+	Error() *string
+	DurationMs() int32
+	Succeeded() bool
+	CreatedAt() int32
+}
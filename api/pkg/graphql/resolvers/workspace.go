@@ -122,10 +122,13 @@ type WorkspaceResolver interface {
 	UpdatedAt() *int32
 	LastActivityAt() int32
 	DraftDescription() string
+	RenderedDraftDescription(context.Context) (string, error)
 	View(ctx context.Context) (ViewResolver, error)
 	Comments() ([]TopCommentResolver, error)
 	CommentsCount(context.Context) (int32, error)
 	GitHubPullRequest(ctx context.Context) (GitHubPullRequestResolver, error)
+	GitLabMergeRequest(ctx context.Context) (GitLabMergeRequestResolver, error)
+	RemoteBranch(ctx context.Context) (RemoteBranchResolver, error)
 	UpToDateWithTrunk(context.Context) (bool, error)
 	Conflicts(context.Context) (bool, error)
 	HeadChange(ctx context.Context) (ChangeResolver, error)
@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/auth"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// requireOperationScope checks that subjects that are authenticated with a personal access token have the
+// api:write scope if the document contains a mutation. The api:read scope is checked by the auth middleware.
+func requireOperationScope(subject *auth.Subject, document string) error {
+	if !subject.IsPersonalAccessToken() || !hasMutation(document) {
+		return nil
+	}
+	return subject.RequireAPIScope(true)
+}
+
+// hasMutation returns true if any of the operations in the document is a mutation. It does not validate the
+// document, anything that looks like a mutation at the top level counts as one.
+func hasMutation(document string) bool {
+	depth := 0
+	for i := 0; i < len(document); {
+		switch ch := document[i]; {
+		case ch == '#':
+			for i < len(document) && document[i] != '\n' && document[i] != '\r' {
+				i++
+			}
+		case ch == '"':
+			i = skipString(document, i)
+		case ch == '{':
+			depth++
+			i++
+		case ch == '}':
+			depth--
+			i++
+		case isNameStart(ch):
+			start := i
+			for i < len(document) && isNameContinue(document[i]) {
+				i++
+			}
+			if depth <= 0 && document[start:i] == "mutation" {
+				return true
+			}
+		default:
+			i++
+		}
+	}
+	return false
+}
+
+// skipString returns the index after the string or block string that starts at i.
+func skipString(document string, i int) int {
+	if len(document) >= i+3 && document[i:i+3] == `"""` {
+		for i += 3; i < len(document); i++ {
+			if document[i] == '\\' && len(document) >= i+4 && document[i+1:i+4] == `"""` {
+				i += 3
+				continue
+			}
+			if len(document) >= i+3 && document[i:i+3] == `"""` {
+				return i + 3
+			}
+		}
+		return i
+	}
+	for i++; i < len(document); i++ {
+		switch document[i] {
+		case '\\':
+			i++
+		case '"', '\n', '\r':
+			return i + 1
+		}
+	}
+	return i
+}
+
+func isNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isNameContinue(ch byte) bool {
+	return isNameStart(ch) || (ch >= '0' && ch <= '9')
+}
+
+// scopedSchema checks the scopes of mutations that are sent over websockets.
+type scopedSchema struct {
+	*graphql.Schema
+}
+
+func (s *scopedSchema) Subscribe(ctx context.Context, document string, operationName string, variables map[string]any) (<-chan any, error) {
+	if subject, ok := auth.FromContext(ctx); ok {
+		if err := requireOperationScope(subject, document); err != nil {
+			return nil, err
+		}
+	}
+	return s.Schema.Subscribe(ctx, document, operationName, variables)
+}
@@ -0,0 +1,31 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasMutation(t *testing.T) {
+	cases := []struct {
+		name     string
+		document string
+		expected bool
+	}{
+		{name: "shorthand query", document: `{ user { id } }`},
+		{name: "query", document: `query User { user { id } }`},
+		{name: "field named mutation", document: `query { mutation { id } }`},
+		{name: "string", document: `query { user(name: "mutation {") { id } }`},
+		{name: "block string", document: `query { user(name: """ " mutation """) { id } }`},
+		{name: "comment", document: "# mutation\nquery { user { id } }"},
+		{name: "mutation", document: `mutation { updateUser(input: {}) { id } }`, expected: true},
+		{name: "named mutation", document: `mutation Update($id: ID!) { updateUser(id: $id) { id } }`, expected: true},
+		{name: "mutation after query", document: `query A { user { id } } mutation B { updateUser { id } }`, expected: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hasMutation(tc.document))
+		})
+	}
+}
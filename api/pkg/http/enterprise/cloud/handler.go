@@ -11,6 +11,8 @@ import (
 	service_licenses "getsturdy.com/api/pkg/licenses/enterprise/cloud/service"
 	service_validations "getsturdy.com/api/pkg/licenses/enterprise/cloud/validations/service"
 	routes_v3_logger "getsturdy.com/api/pkg/logger/enterprise/cloud/routes"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	routes_v3_user "getsturdy.com/api/pkg/users/enterprise/cloud/routes"
 	service_user "getsturdy.com/api/pkg/users/enterprise/cloud/service"
 
@@ -29,10 +31,12 @@ func ProvideHandler(
 	serviceStatistics *service_statistics.Service,
 	sentryClient *raven.Client,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
+	sessionsService *service_sessions.Service,
 	userService *service_user.Service,
 ) *gin.Engine {
 	auth := enterpriseEngine.Group("")
-	auth.Use(authz.GinMiddleware(logger, jwtService))
+	auth.Use(authz.GinMiddleware(logger, jwtService, personalTokensService, sessionsService))
 	auth.POST("/v3/users/verify-email", routes_v3_user.SendEmailVerification(logger, userService)) // Used by the web (2021-11-14)
 
 	publ := enterpriseEngine.Group("")
@@ -41,6 +45,6 @@ func ProvideHandler(
 	publ.POST("/v3/statistics", gin.WrapF(routes_v3_statistics.Create(logger, serviceStatistics)))
 	publ.POST("v3/sentry/store/", gin.WrapF(routes_v3_logger.Store(logger, sentryClient)))
 	publ.POST("/v3/auth/magic-link/send", routes_v3_user.SendMagicLink(logger, userService))
-	publ.POST("/v3/auth/magic-link/verify", routes_v3_user.VerifyMagicLink(logger, userService, jwtService))
+	publ.POST("/v3/auth/magic-link/verify", routes_v3_user.VerifyMagicLink(logger, userService, jwtService, sessionsService))
 	return (*gin.Engine)(enterpriseEngine)
 }
@@ -11,11 +11,14 @@ import (
 	routes_v3_ghapp "getsturdy.com/api/pkg/github/enterprise/routes"
 	service_github "getsturdy.com/api/pkg/github/enterprise/service"
 	workers_github "getsturdy.com/api/pkg/github/enterprise/workers"
+	routes_gitlab "getsturdy.com/api/pkg/gitlab/enterprise/routes"
 	"getsturdy.com/api/pkg/http"
 	service_buildkite "getsturdy.com/api/pkg/integrations/providers/buildkite/enterprise/service"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	routes_remote "getsturdy.com/api/pkg/remote/enterprise/routes"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	routes_ci "getsturdy.com/api/pkg/statuses/enterprise/routes"
 	service_statuses "getsturdy.com/api/pkg/statuses/service"
 	db_user "getsturdy.com/api/pkg/users/db"
@@ -30,6 +33,8 @@ func ProvideHandler(
 	gitHubAppConfig *config.GitHubAppConfig,
 	statusesService *service_statuses.Service,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
+	sessionsService *service_sessions.Service,
 	gitHubService *service_github.Service,
 	ciService *service_ci.Service,
 	serviceTokensService *service_servicetokens.Service,
@@ -37,14 +42,16 @@ func ProvideHandler(
 	ossEngine *http.Engine,
 	gitHubWebhooksQueue *workers_github.WebhooksQueue,
 	triggerSyncCodebaseWebhookHandler routes_remote.TriggerSyncCodebaseWebhookHandler,
+	gitLabWebhookHandler routes_gitlab.WebhookHandler,
 ) *Engine {
 	auth := ossEngine.Group("")
-	auth.Use(authz.GinMiddleware(logger, jwtService))
+	auth.Use(authz.GinMiddleware(logger, jwtService, personalTokensService, sessionsService))
 	auth.POST("/v3/github/oauth", routes_v3_ghapp.Oauth(logger, gitHubAppConfig, userRepo, gitHubUserRepo, gitHubService))
 
 	publ := ossEngine.Group("")
 	publ.POST("/v3/github/webhook", routes_v3_ghapp.Webhook(logger, gitHubWebhooksQueue))
 	publ.POST("/v3/statuses/webhook", routes_ci.WebhookHandler(logger, statusesService, ciService, serviceTokensService, buildkiteService))
+	publ.POST("/v3/gitlab/webhook/:id", gin.HandlerFunc(gitLabWebhookHandler))
 
 	// Using Any to give friendly error messages if sent a non-POST request
 	publ.Any("/v3/remotes/webhook/sync-codebase/:id", gin.HandlerFunc(triggerSyncCodebaseWebhookHandler))
@@ -0,0 +1,30 @@
+package selfhosted
+
+import (
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/oidc"
+	routes_oidc "getsturdy.com/api/pkg/oidc/enterprise/selfhosted/routes"
+	service_oidc "getsturdy.com/api/pkg/oidc/enterprise/selfhosted/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SSOEngine is the self-hosted engine with single sign-on routes. Single sign-on is only available for
+// self-hosted installations, Engine is shared with the cloud.
+type SSOEngine gin.Engine
+
+func ProvideSSOHandler(
+	logger *zap.Logger,
+	engine *Engine,
+	oidcConfig *oidc.Configuration,
+	oidcService *service_oidc.Service,
+	jwtService *service_jwt.Service,
+	sessionsService *service_sessions.Service,
+) *SSOEngine {
+	publ := (*gin.Engine)(engine).Group("")
+	publ.GET("/v3/auth/oidc/login", routes_oidc.Login(logger, oidcConfig, oidcService))
+	publ.GET("/v3/auth/oidc/callback", routes_oidc.Callback(logger, oidcConfig, oidcService, jwtService, sessionsService))
+	return (*SSOEngine)(engine)
+}
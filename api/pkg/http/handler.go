@@ -7,6 +7,7 @@ import (
 	"time"
 
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	routes_audit "getsturdy.com/api/pkg/audit/routes"
 	authz "getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	routes_blobs "getsturdy.com/api/pkg/blobs/routes"
@@ -19,24 +20,31 @@ import (
 	"getsturdy.com/api/pkg/configuration/flags"
 	"getsturdy.com/api/pkg/events"
 	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	routes_exports "getsturdy.com/api/pkg/exports/routes"
 	routes_file "getsturdy.com/api/pkg/file/routes"
 	worker_gc "getsturdy.com/api/pkg/gc/worker"
 	"getsturdy.com/api/pkg/ginzap"
 	sturdygrapql "getsturdy.com/api/pkg/graphql"
 	"getsturdy.com/api/pkg/ip"
+	routes_jwt "getsturdy.com/api/pkg/jwt/routes"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/metrics/ginprometheus"
 	db_mutagen "getsturdy.com/api/pkg/mutagen/db"
 	routes_v3_mutagen "getsturdy.com/api/pkg/mutagen/routes"
 	db_newsletter "getsturdy.com/api/pkg/newsletter/db"
 	routes_v3_newsletter "getsturdy.com/api/pkg/newsletter/routes"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	db_pki "getsturdy.com/api/pkg/pki/db"
 	routes_v3_pki "getsturdy.com/api/pkg/pki/routes"
 	service_presence "getsturdy.com/api/pkg/presence/service"
+	routes_scim "getsturdy.com/api/pkg/scim/routes"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	worker_snapshots "getsturdy.com/api/pkg/snapshots/worker"
 	service_suggestion "getsturdy.com/api/pkg/suggestions/service"
 	routes_v3_sync "getsturdy.com/api/pkg/sync/routes"
 	service_sync "getsturdy.com/api/pkg/sync/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users/avatars/uploader"
 	db_user "getsturdy.com/api/pkg/users/db"
 	routes_v3_user "getsturdy.com/api/pkg/users/routes"
@@ -103,6 +111,8 @@ func ProvideHandler(
 	userService service_user.Service,
 	syncService *service_sync.Service,
 	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
+	sessionsService *service_sessions.Service,
 	codebaseService *service_codebase.Service,
 	authService *service_auth.Service,
 	grapqhlResolver *sturdygrapql.RootResolver,
@@ -110,6 +120,11 @@ func ProvideHandler(
 	uploader uploader.Uploader,
 	viewService *service_view.Service,
 	getFileRoute routes_file.GetFileRoute,
+	exportAuditLogRoute routes_audit.ExportRoute,
+	exportCodebaseRoute routes_exports.ExportRoute,
+	importCodebaseRoute routes_exports.ImportRoute,
+	scimService *service_scim.Service,
+	twoFactorService *service_twofactor.Service,
 ) *Engine {
 	logger = logger.With(zap.String("component", "http"))
 	allowOrigins := []string{
@@ -149,7 +164,7 @@ func ProvideHandler(
 	ginprom := ginprometheus.NewPrometheus("gin", logger)
 	ginprom.ReqCntURLLabelMappingFn = metricsMapper
 	ginprom.Use(r)
-	graphql := r.Group("/graphql", sturdygrapql.CorsMiddleware(allowOrigins), authz.GinMiddleware(logger, jwtService))
+	graphql := r.Group("/graphql", sturdygrapql.CorsMiddleware(allowOrigins), authz.GinGraphQLMiddleware(logger, jwtService, personalTokensService, sessionsService))
 	graphql.OPTIONS("", func(c *gin.Context) { c.Status(http.StatusOK) })
 	graphql.OPTIONS("ws", func(c *gin.Context) { c.Status(http.StatusOK) })
 	graphql.POST("", grapqhlResolver.HttpHandler())
@@ -159,14 +174,14 @@ func ProvideHandler(
 	publ := r.Group("")
 	// Private endpoints, requires a valid auth cookie
 	auth := r.Group("")
-	auth.Use(authz.GinMiddleware(logger, jwtService))
-	publ.POST("/v3/auth", routes_v3_user.Login(logger, userService, analyticsService, jwtService))
-	publ.POST("/v3/users", routes_v3_user.Signup(logger, userService, jwtService, analyticsService))
-	publ.POST("/v3/auth/destroy", routes_v3_user.AuthDestroy)
-	auth.POST("/v3/auth/client-token", routes_v3_user.ClientToken(userRepo, jwtService))
+	auth.Use(authz.GinMiddleware(logger, jwtService, personalTokensService, sessionsService))
+	publ.POST("/v3/auth", routes_v3_user.Login(logger, userService, analyticsService, jwtService, sessionsService, twoFactorService))
+	publ.POST("/v3/users", routes_v3_user.Signup(logger, userService, jwtService, sessionsService, analyticsService))
+	publ.POST("/v3/auth/destroy", routes_v3_user.AuthDestroy(logger, jwtService, sessionsService))
+	auth.POST("/v3/auth/client-token", routes_v3_user.ClientToken(userRepo, jwtService, sessionsService))
 	auth.POST("/v3/auth/renew-token", routes_v3_user.RenewToken(logger, userRepo, jwtService))
 	auth.POST("/v3/user/update-avatar", routes_v3_user.UpdateAvatar(logger, userRepo, uploader))                                                        // Used by the web (2021-10-04)
-	auth.GET("/v3/user", routes_v3_user.GetSelf(userService, jwtService))                                                                               // Used by the command line client
+	auth.GET("/v3/user", routes_v3_user.GetSelf(userService, jwtService, sessionsService))                                                                               // Used by the command line client
 	auth.POST("/v3/codebases", routes_v3_codebase.Create(logger, codebaseService))                                                                      // Used by the web (2021-10-04)
 	auth.GET("/v3/codebases/:id", routes_v3_codebase.Get(codebaseRepo, codebaseUserRepo, logger, userService))                                          // Used by the command line client
 	auth.POST("/v3/codebases/:id/invite", routes_v3_codebase.Invite(codebaseService, authService))                                                      // No longer used (after 2022-01-31)
@@ -186,6 +201,8 @@ func ProvideHandler(
 	auth.POST("/v3/workspaces", routes_v3_workspace.Create(logger, workspaceService, codebaseUserRepo))                  // Used by the command line client
 	// Used by LBS to check for health
 	publ.GET("/readyz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	// Used by other services to verify tokens issued by Sturdy
+	publ.GET("/.well-known/jwks.json", routes_jwt.JWKS(logger, jwtService))
 	publ.POST("/v3/waitinglist", waitinglist.Insert(logger, analyticsService, waitingListRepo))                                                                                  // Used by the web (2021-10-04)
 	publ.POST("/v3/acl-request-enterprise", acl.Insert(logger, analyticsService, aclInterestRepo))                                                                               // Used by the web (2021-10-04)
 	publ.POST("/v3/instant-integration", instantintegration.Insert(logger, analyticsService, instantIntegrationInterestRepo))                                                    // Used by the web (2021-10-27)
@@ -199,8 +216,12 @@ func ProvideHandler(
 	publ.POST("/v3/unsubscribe", routes_v3_newsletter.Unsubscribe(logger, userRepo, notificationSettingsRepo))
 
 	auth.GET("/v3/file", gin.HandlerFunc(getFileRoute))
+	auth.GET("/v3/organizations/:id/audit-log", gin.HandlerFunc(exportAuditLogRoute))
+	auth.GET("/v3/codebases/:id/export", gin.HandlerFunc(exportCodebaseRoute))
+	auth.POST("/v3/organizations/:id/import", gin.HandlerFunc(importCodebaseRoute))
 
 	routes_blobs.Register(publ.Group("/v3/blobs"), logger, blobsService)
+	routes_scim.Register(publ.Group("/scim/v2"), logger, scimService)
 	return (*Engine)(r)
 }
 
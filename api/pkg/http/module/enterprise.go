@@ -16,7 +16,8 @@ import (
 func Module(c *di.Container) {
 	c.Register(httpx.ProvideHandler)
 	c.Register(selfhosted.ProvideHandler)
-	c.Register(func(e *selfhosted.Engine) http.Handler {
+	c.Register(selfhosted.ProvideSSOHandler)
+	c.Register(func(e *selfhosted.SSOEngine) http.Handler {
 		return (*gin.Engine)(e)
 	})
 	c.Register(httpx.ProvideServer)
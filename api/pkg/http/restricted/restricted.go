@@ -0,0 +1,115 @@
+// Package restricted provides an HTTP client for requests to URLs that are provided by users. Unless configured
+// otherwise, the client refuses to connect to loopback, private, link-local and unspecified addresses, so that users
+// can't make Sturdy send requests to internal services.
+package restricted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrAddressNotAllowed = errors.New("address is not allowed")
+
+type Configuration struct {
+	AllowPrivateAddresses bool `long:"allow-private-addresses" description:"Allow requests to user provided URLs, like webhooks, on loopback, private and link-local addresses"`
+}
+
+func (cfg *Configuration) allowAll() bool {
+	return cfg != nil && cfg.AllowPrivateAddresses
+}
+
+// IsAllowed returns true if requests can be sent to the ip.
+func IsAllowed(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified())
+}
+
+// IsAllowedHost returns false if the host is an address that requests can't be sent to. Host names are not
+// resolved, the address that they resolve to is checked when connecting.
+func (cfg *Configuration) IsAllowedHost(host string) bool {
+	if cfg.allowAll() {
+		return true
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return IsAllowed(ip)
+	}
+	return true
+}
+
+// ResolveAndCheckHost resolves the host, and returns ErrAddressNotAllowed if it, or any of the addresses that it
+// resolves to, is not allowed. It's for connections that are not made by the client, like git fetches and pushes,
+// which can't check the address when connecting.
+func (cfg *Configuration) ResolveAndCheckHost(ctx context.Context, host string) error {
+	if cfg.allowAll() {
+		return nil
+	}
+	if !cfg.IsAllowedHost(host) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !IsAllowed(addr.IP) {
+			return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr.IP)
+		}
+	}
+	return nil
+}
+
+// control runs after the host name has been resolved, right before connecting, so it can't be bypassed with DNS.
+func control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsAllowed(ip) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+	}
+	return nil
+}
+
+// NewClient returns a client that only connects to public addresses, unless private addresses are allowed by the
+// configuration. Redirects are not followed, the redirect response is returned instead.
+func NewClient(cfg *Configuration, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !cfg.allowAll() {
+		dialer.Control = control
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// no proxy, the dialer must see the address of the receiver
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
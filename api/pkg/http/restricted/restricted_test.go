@@ -0,0 +1,93 @@
+package restricted_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/http/restricted"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllowed(t *testing.T) {
+	cases := []struct {
+		ip      string
+		allowed bool
+	}{
+		{ip: "1.1.1.1", allowed: true},
+		{ip: "2606:4700:4700::1111", allowed: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "10.0.0.1"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "fd00::1"},
+		{ip: "169.254.169.254"},
+		{ip: "fe80::1"},
+		{ip: "0.0.0.0"},
+		{ip: "::"},
+		{ip: "::ffff:127.0.0.1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.ip, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, restricted.IsAllowed(net.ParseIP(tc.ip)))
+		})
+	}
+}
+
+func TestIsAllowedHost(t *testing.T) {
+	cfg := &restricted.Configuration{}
+	assert.True(t, cfg.IsAllowedHost("example.com"))
+	assert.True(t, cfg.IsAllowedHost("1.1.1.1"))
+	assert.False(t, cfg.IsAllowedHost("localhost"))
+	assert.False(t, cfg.IsAllowedHost("app.localhost"))
+	assert.False(t, cfg.IsAllowedHost("127.0.0.1"))
+	assert.False(t, cfg.IsAllowedHost("::1"))
+
+	allowAll := &restricted.Configuration{AllowPrivateAddresses: true}
+	assert.True(t, allowAll.IsAllowedHost("localhost"))
+	assert.True(t, allowAll.IsAllowedHost("127.0.0.1"))
+}
+
+func TestResolveAndCheckHost(t *testing.T) {
+	ctx := context.Background()
+	cfg := &restricted.Configuration{}
+	assert.NoError(t, cfg.ResolveAndCheckHost(ctx, "1.1.1.1"))
+	assert.ErrorIs(t, cfg.ResolveAndCheckHost(ctx, "127.0.0.1"), restricted.ErrAddressNotAllowed)
+	assert.ErrorIs(t, cfg.ResolveAndCheckHost(ctx, "localhost"), restricted.ErrAddressNotAllowed)
+
+	allowAll := &restricted.Configuration{AllowPrivateAddresses: true}
+	assert.NoError(t, allowAll.ResolveAndCheckHost(ctx, "localhost"))
+}
+
+func TestClient_shouldNotConnectToLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be made")
+	}))
+	defer srv.Close()
+
+	_, err := restricted.NewClient(nil, time.Second).Get(srv.URL)
+	assert.ErrorIs(t, err, restricted.ErrAddressNotAllowed)
+}
+
+func TestClient_shouldNotFollowRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+			return
+		}
+		t.Error("redirect should not be followed")
+	}))
+	defer srv.Close()
+
+	res, err := restricted.NewClient(&restricted.Configuration{AllowPrivateAddresses: true}, time.Second).Get(srv.URL + "/redirect")
+	if assert.NoError(t, err) {
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+	}
+}
@@ -20,3 +20,11 @@ func (r *rootResolver) Installation(context.Context) (resolvers.InstallationsRes
 func (r *rootResolver) UpdateInstallation(_ context.Context, _ resolvers.UpdateInstallationArgs) (resolvers.InstallationsResolver, error) {
 	return nil, gqlerrors.ErrNotImplemented
 }
+
+func (r *rootResolver) RotateSigningKeys(context.Context) (resolvers.InstallationsResolver, error) {
+	return nil, gqlerrors.ErrNotImplemented
+}
+
+func (r *rootResolver) SendTestEmail(context.Context) (resolvers.InstallationsResolver, error) {
+	return nil, gqlerrors.ErrNotImplemented
+}
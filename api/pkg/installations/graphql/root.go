@@ -3,6 +3,8 @@ package graphql
 import (
 	"context"
 
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	service_installations "getsturdy.com/api/pkg/installations/service"
@@ -15,6 +17,7 @@ type RootResolver struct {
 	licenseResolver     resolvers.LicenseRootResolver
 	organizationService *service_organization.Service
 	usersService        service_users.Service
+	authService         *service_auth.Service
 }
 
 func New(
@@ -22,12 +25,14 @@ func New(
 	licenseResolver resolvers.LicenseRootResolver,
 	organizationService *service_organization.Service,
 	usersService service_users.Service,
+	authService *service_auth.Service,
 ) *RootResolver {
 	return &RootResolver{
 		service:             service,
 		licenseResolver:     licenseResolver,
 		organizationService: organizationService,
 		usersService:        usersService,
+		authService:         authService,
 	}
 }
 
@@ -45,3 +50,47 @@ func (r *RootResolver) Installation(ctx context.Context) (resolvers.Installation
 func (r *RootResolver) UpdateInstallation(context.Context, resolvers.UpdateInstallationArgs) (resolvers.InstallationsResolver, error) {
 	return nil, gqlerrors.ErrNotImplemented
 }
+
+func (r *RootResolver) RotateSigningKeys(ctx context.Context) (resolvers.InstallationsResolver, error) {
+	subject, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, gqlerrors.Error(auth.ErrUnauthenticated)
+	}
+	if subject.IsPersonalAccessToken() {
+		return nil, gqlerrors.Error(auth.ErrForbidden)
+	}
+
+	org, err := r.authService.CanAdministrateInstallation(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.service.RotateSigningKeys(ctx, org.ID); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return r.Installation(ctx)
+}
+
+func (r *RootResolver) SendTestEmail(ctx context.Context) (resolvers.InstallationsResolver, error) {
+	if _, err := r.authService.CanAdministrateInstallation(ctx); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	user, err := r.usersService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	// the error is shown to the administrator, as it's what they need to fix the configuration
+	if err := r.service.SendTestEmail(ctx, user.Email); err != nil {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "message", err.Error())
+	}
+
+	return r.Installation(ctx)
+}
@@ -6,18 +6,29 @@ import (
 	"fmt"
 	"sync"
 
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/emails"
+	worker_emails "getsturdy.com/api/pkg/emails/worker"
 	"getsturdy.com/api/pkg/installations"
 	"getsturdy.com/api/pkg/installations/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/licenses"
 	"getsturdy.com/api/pkg/version"
 
 	"github.com/google/uuid"
 )
 
-var ErrInvalidLicense = errors.New("invalid license")
+var (
+	ErrInvalidLicense = errors.New("invalid license")
+	ErrEmailsDisabled = errors.New("sending emails is not configured")
+)
 
 type Service struct {
-	repo db.Repository
+	repo           db.Repository
+	jwtService     *service_jwt.Service
+	auditService   *service_audit.Service
+	emailTransport worker_emails.Transport
 
 	licenseGuard *sync.RWMutex
 	// license is the latest license that was retrieved from the licensing server.
@@ -26,9 +37,15 @@ type Service struct {
 
 func New(
 	repo db.Repository,
+	jwtService *service_jwt.Service,
+	auditService *service_audit.Service,
+	emailTransport worker_emails.Transport,
 ) *Service {
 	return &Service{
-		repo: repo,
+		repo:           repo,
+		jwtService:     jwtService,
+		auditService:   auditService,
+		emailTransport: emailTransport,
 
 		licenseGuard: &sync.RWMutex{},
 	}
@@ -73,3 +90,42 @@ func (svc *Service) Get(ctx context.Context) (*installations.Installation, error
 		return nil, fmt.Errorf("more than one installation found")
 	}
 }
+
+// RotateSigningKeys immediately replaces the keys that tokens are signed with, and invalidates all tokens
+// issued so far, logging out all users. It's meant to be used if the keys are suspected to be compromised,
+// keys are otherwise rotated on a schedule.
+func (svc *Service) RotateSigningKeys(ctx context.Context, organizationID string) error {
+	installation, err := svc.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.jwtService.RotateAll(ctx); err != nil {
+		return fmt.Errorf("failed to rotate signing keys: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionInstallationSigningKeysRotated, audit.TargetInstallation, installation.ID,
+		audit.OrganizationID(organizationID),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// SendTestEmail sends an email to the given address to verify that emails can be delivered. Unlike other
+// emails, it's not retried if sending fails, the error is returned instead.
+func (svc *Service) SendTestEmail(ctx context.Context, to string) error {
+	if emails.IsDisabled(svc.emailTransport) {
+		return ErrEmailsDisabled
+	}
+
+	if err := svc.emailTransport.Send(ctx, &emails.Email{
+		To:      to,
+		Subject: "[Sturdy] Test email",
+		Html:    "<p>This is a test email from your Sturdy installation. If you are reading this, emails are delivered.</p>",
+	}); err != nil {
+		return fmt.Errorf("failed to send test email: %w", err)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/integrations/providers/buildkite"
 	service_buildkite "getsturdy.com/api/pkg/integrations/providers/buildkite/enterprise/service"
+	"getsturdy.com/api/pkg/servicetokens"
 	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
 	"getsturdy.com/api/pkg/statuses"
 	svc_statuses "getsturdy.com/api/pkg/statuses/service"
@@ -158,6 +159,19 @@ func WebhookHandler(
 			}
 		}
 
+		// the signature is validated first, so that unsigned requests don't mark the token as used
+		if err := serviceTokensService.Authorize(c.Request.Context(), serviceToken, servicetokens.ScopeStatusesWrite); errors.Is(err, service_servicetokens.ErrTokenInactive) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		} else if errors.Is(err, service_servicetokens.ErrMissingScope) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		} else if err != nil {
+			logger.Error("failed to authorize service token", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
 		trunkCommitSHA, err := ciService.GetTrunkCommitSHA(c.Request.Context(), serviceToken.CodebaseID, *payload.Build.Commit)
 		if errors.Is(err, sql.ErrNoRows) {
 			c.AbortWithError(http.StatusBadRequest, fmt.Errorf("unknown commit: %s", *payload.Build.Commit))
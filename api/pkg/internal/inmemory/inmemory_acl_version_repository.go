@@ -0,0 +1,64 @@
+package inmemory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"getsturdy.com/api/pkg/codebases/acl"
+	db_acl "getsturdy.com/api/pkg/codebases/acl/db"
+)
+
+type inMemoryAclVersionRepo struct {
+	aclRepo  db_acl.ACLRepository
+	versions []acl.Version
+}
+
+func NewInMemoryAclVersionRepo(aclRepo db_acl.ACLRepository) db_acl.VersionRepository {
+	return &inMemoryAclVersionRepo{
+		aclRepo:  aclRepo,
+		versions: make([]acl.Version, 0),
+	}
+}
+
+func (r *inMemoryAclVersionRepo) Create(_ context.Context, v acl.Version) error {
+	r.versions = append(r.versions, v)
+	return nil
+}
+
+func (r *inMemoryAclVersionRepo) Apply(ctx context.Context, a acl.ACL, v acl.Version) error {
+	latest := 0
+	for _, existing := range r.versions {
+		if existing.ACLID == a.ID && existing.Version > latest {
+			latest = existing.Version
+		}
+	}
+	if latest != v.Version-1 {
+		return db_acl.ErrVersionConflict
+	}
+	r.versions = append(r.versions, v)
+	a.RawPolicy = v.RawPolicy
+	return r.aclRepo.Update(ctx, a)
+}
+
+func (r *inMemoryAclVersionRepo) Get(_ context.Context, id acl.VersionID) (acl.Version, error) {
+	for _, v := range r.versions {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return acl.Version{}, sql.ErrNoRows
+}
+
+func (r *inMemoryAclVersionRepo) ListByACLID(_ context.Context, aclID acl.ID) ([]acl.Version, error) {
+	res := []acl.Version{}
+	for _, v := range r.versions {
+		if v.ACLID == aclID {
+			res = append(res, v)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version > res[j].Version
+	})
+	return res, nil
+}
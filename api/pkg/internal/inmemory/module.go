@@ -8,6 +8,7 @@ import (
 
 func TestModule(c *di.Container) {
 	c.Register(NewInMemoryAclRepo)
+	c.Register(NewInMemoryAclVersionRepo)
 	c.Register(NewInMemoryCodebaseRepo)
 	c.Register(NewInMemoryCodebaseUserRepo)
 	c.Register(NewInMemoryGitHubInstallationRepository)
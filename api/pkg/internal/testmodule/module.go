@@ -9,6 +9,7 @@ import (
 	module_configuration "getsturdy.com/api/pkg/configuration/module"
 	"getsturdy.com/api/pkg/di"
 	module_events "getsturdy.com/api/pkg/events"
+	module_events_fanout "getsturdy.com/api/pkg/events/fanout"
 	module_events_v2 "getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/installations"
 	"getsturdy.com/api/pkg/internal/inmemory"
@@ -36,6 +37,7 @@ func TestModule(c *di.Container) {
 	c.Import(module_snapshots.InMemoryTestingModule)
 	c.Import(module_events_v2.Module)
 	c.Import(module_events.Module)
+	c.Import(module_events_fanout.TestingModule)
 
 	c.Register(func(repo db_workspaces.Repository) (db_workspaces.WorkspaceReader, db_workspaces.WorkspaceWriter) {
 		return repo, repo
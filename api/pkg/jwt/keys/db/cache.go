@@ -4,24 +4,36 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"getsturdy.com/api/pkg/jwt/keys"
 	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/jwt/keys"
 )
 
 var _ Repository = &cache{}
 
+type cacheEntry struct {
+	key       *keys.Key
+	fetchedAt time.Time
+}
+
+// cache keeps keys in memory for a short period of time, so that keys revoked by other instances stop
+// being accepted within seconds without hitting the database on every request.
 type cache struct {
-	db Repository
+	db  Repository
+	ttl time.Duration
 
-	cache      map[string]*keys.Key
+	cache      map[string]*cacheEntry
 	cacheGuard *sync.RWMutex
+	lastSweep  time.Time
 }
 
-func NewCache(db Repository) *cache {
+func NewCache(db Repository, ttl time.Duration) *cache {
 	return &cache{
-		db: db,
+		db:  db,
+		ttl: ttl,
 
-		cache:      make(map[string]*keys.Key),
+		cache:      make(map[string]*cacheEntry),
 		cacheGuard: &sync.RWMutex{},
 	}
 }
@@ -31,7 +43,7 @@ func (c *cache) Create(ctx context.Context, key *keys.Key) error {
 		return err
 	}
 	c.cacheGuard.Lock()
-	c.cache[key.ID] = key
+	c.put(key.ID, &cacheEntry{key: key, fetchedAt: time.Now()})
 	c.cacheGuard.Unlock()
 	return nil
 }
@@ -41,27 +53,68 @@ func (c *cache) Get(ctx context.Context, id string) (*keys.Key, error) {
 	cached, foundInCache := c.cache[id]
 	c.cacheGuard.RUnlock()
 
-	if !foundInCache {
-		key, err := c.db.Get(ctx, id)
-		switch {
-		case err == nil:
-			c.cacheGuard.Lock()
-			c.cache[id] = key
-			c.cacheGuard.Unlock()
-			return key, nil
-		case errors.Is(err, sql.ErrNoRows):
-			c.cacheGuard.Lock()
-			c.cache[id] = nil
-			c.cacheGuard.Unlock()
+	if foundInCache && time.Since(cached.fetchedAt) < c.ttl {
+		if cached.key == nil {
 			return nil, sql.ErrNoRows
-		default:
-			return nil, err
 		}
-	} else {
-		if cached == nil {
-			return nil, sql.ErrNoRows
-		} else {
-			return cached, nil
+		return cached.key, nil
+	}
+
+	key, err := c.db.Get(ctx, id)
+	switch {
+	case err == nil:
+		c.cacheGuard.Lock()
+		c.put(id, &cacheEntry{key: key, fetchedAt: time.Now()})
+		c.cacheGuard.Unlock()
+		return key, nil
+	case errors.Is(err, sql.ErrNoRows):
+		c.cacheGuard.Lock()
+		c.put(id, &cacheEntry{fetchedAt: time.Now()})
+		c.cacheGuard.Unlock()
+		return nil, sql.ErrNoRows
+	default:
+		return nil, err
+	}
+}
+
+func (c *cache) ListValid(ctx context.Context, at time.Time) ([]*keys.Key, error) {
+	return c.db.ListValid(ctx, at)
+}
+
+func (c *cache) RevokeAll(ctx context.Context, at time.Time) error {
+	if err := c.db.RevokeAll(ctx, at); err != nil {
+		return err
+	}
+	c.reset()
+	return nil
+}
+
+func (c *cache) DeleteExpired(ctx context.Context, before time.Time) error {
+	if err := c.db.DeleteExpired(ctx, before); err != nil {
+		return err
+	}
+	c.reset()
+	return nil
+}
+
+func (c *cache) reset() {
+	c.cacheGuard.Lock()
+	c.cache = make(map[string]*cacheEntry)
+	c.cacheGuard.Unlock()
+}
+
+// put caches the entry. Expired entries are removed at most once per ttl, so that the cache does not grow
+// with every key id that has ever been looked up. Must be called with the write lock held.
+func (c *cache) put(id string, entry *cacheEntry) {
+	c.cache[id] = entry
+
+	if entry.fetchedAt.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	for k, e := range c.cache {
+		if entry.fetchedAt.Sub(e.fetchedAt) >= c.ttl {
+			delete(c.cache, k)
 		}
 	}
+	c.lastSweep = entry.fetchedAt
 }
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"getsturdy.com/api/pkg/jwt/keys"
 
@@ -25,9 +26,12 @@ func (db *database) Create(ctx context.Context, key *keys.Key) error {
 	if _, err := db.db.NamedExecContext(ctx, `
 	INSERT INTO jwt_keys (
 		id,
-		public_der
+		public_der,
+		created_at,
+		expires_at,
+		revoked_at
 	) VALUES (
-		:id, :public_der
+		:id, :public_der, :created_at, :expires_at, :revoked_at
 	)
 	`, key); err != nil {
 		return fmt.Errorf("failed to insert: %w", err)
@@ -39,7 +43,7 @@ func (db *database) Get(ctx context.Context, id string) (*keys.Key, error) {
 	key := &keys.Key{}
 	if err := db.db.GetContext(ctx, key, `
 	SELECT
-		id, public_der
+		id, public_der, created_at, expires_at, revoked_at
 	FROM
 		jwt_keys
 	WHERE
@@ -49,3 +53,47 @@ func (db *database) Get(ctx context.Context, id string) (*keys.Key, error) {
 	}
 	return key, nil
 }
+
+func (db *database) ListValid(ctx context.Context, at time.Time) ([]*keys.Key, error) {
+	var kk []*keys.Key
+	if err := db.db.SelectContext(ctx, &kk, `
+	SELECT
+		id, public_der, created_at, expires_at, revoked_at
+	FROM
+		jwt_keys
+	WHERE
+		revoked_at IS NULL
+		AND expires_at > $1
+	ORDER BY
+		created_at
+	`, at); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return kk, nil
+}
+
+func (db *database) RevokeAll(ctx context.Context, at time.Time) error {
+	if _, err := db.db.ExecContext(ctx, `
+	UPDATE
+		jwt_keys
+	SET
+		revoked_at = $1
+	WHERE
+		revoked_at IS NULL
+	`, at); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (db *database) DeleteExpired(ctx context.Context, before time.Time) error {
+	if _, err := db.db.ExecContext(ctx, `
+	DELETE FROM
+		jwt_keys
+	WHERE
+		expires_at < $1
+	`, before); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
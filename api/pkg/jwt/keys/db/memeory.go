@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"database/sql"
+	"sort"
+	"sync"
+	"time"
 
 	"getsturdy.com/api/pkg/jwt/keys"
 )
@@ -10,24 +13,72 @@ import (
 var _ Repository = &memory{}
 
 type memory struct {
-	byID map[string]*keys.Key
+	byID  map[string]*keys.Key
+	guard *sync.RWMutex
 }
 
 func NewInMemory() *memory {
 	return &memory{
-		byID: map[string]*keys.Key{},
+		byID:  map[string]*keys.Key{},
+		guard: &sync.RWMutex{},
 	}
 }
 
 func (db *memory) Create(ctx context.Context, key *keys.Key) error {
-	db.byID[key.ID] = key
+	db.guard.Lock()
+	defer db.guard.Unlock()
+	cp := *key
+	db.byID[key.ID] = &cp
 	return nil
 }
 
 func (db *memory) Get(ctx context.Context, id string) (*keys.Key, error) {
+	db.guard.RLock()
+	defer db.guard.RUnlock()
 	key, found := db.byID[id]
 	if !found {
 		return nil, sql.ErrNoRows
 	}
-	return key, nil
+	cp := *key
+	return &cp, nil
+}
+
+func (db *memory) ListValid(ctx context.Context, at time.Time) ([]*keys.Key, error) {
+	db.guard.RLock()
+	defer db.guard.RUnlock()
+	var kk []*keys.Key
+	for _, key := range db.byID {
+		if !key.Valid(at) {
+			continue
+		}
+		cp := *key
+		kk = append(kk, &cp)
+	}
+	sort.Slice(kk, func(i, j int) bool {
+		return kk[i].CreatedAt.Before(kk[j].CreatedAt)
+	})
+	return kk, nil
+}
+
+func (db *memory) RevokeAll(ctx context.Context, at time.Time) error {
+	db.guard.Lock()
+	defer db.guard.Unlock()
+	for _, key := range db.byID {
+		if key.RevokedAt == nil {
+			revokedAt := at
+			key.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func (db *memory) DeleteExpired(ctx context.Context, before time.Time) error {
+	db.guard.Lock()
+	defer db.guard.Unlock()
+	for id, key := range db.byID {
+		if key.ExpiresAt.Before(before) {
+			delete(db.byID, id)
+		}
+	}
+	return nil
 }
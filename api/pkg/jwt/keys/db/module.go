@@ -8,6 +8,6 @@ import (
 
 func Module(c *di.Container) {
 	c.Register(func(db *sqlx.DB) Repository {
-		return NewCache(New(db))
+		return New(db)
 	})
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"getsturdy.com/api/pkg/jwt/keys"
 )
@@ -9,4 +10,10 @@ import (
 type Repository interface {
 	Create(context.Context, *keys.Key) error
 	Get(context.Context, string) (*keys.Key, error)
+	// ListValid returns all keys that are neither revoked nor expired at the given time.
+	ListValid(context.Context, time.Time) ([]*keys.Key, error)
+	// RevokeAll revokes all keys that are not revoked yet.
+	RevokeAll(context.Context, time.Time) error
+	// DeleteExpired deletes all keys that expired before the given time.
+	DeleteExpired(context.Context, time.Time) error
 }
@@ -2,6 +2,7 @@ package keys
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -9,12 +10,19 @@ import (
 var ErrKeyIsEmpty = fmt.Errorf("key is empty")
 
 type Key struct {
-	ID        string `db:"id"`
-	PublicDER []byte `db:"public_der"`
+	ID        string    `db:"id"`
+	PublicDER []byte    `db:"public_der"`
+	CreatedAt time.Time `db:"created_at"`
+	// ExpiresAt is the time after which tokens signed with the key are no longer accepted. Expired keys
+	// are retired.
+	ExpiresAt time.Time `db:"expires_at"`
+	// RevokedAt is set if the key was revoked before it expired. Tokens signed with a revoked key are not
+	// accepted.
+	RevokedAt *time.Time `db:"revoked_at"`
 }
 
-// New creates a new key with a public der payload.
-func New(publicDER []byte) (*Key, error) {
+// New creates a new key with a public der payload, that can be used to verify tokens until expiresAt.
+func New(publicDER []byte, createdAt, expiresAt time.Time) (*Key, error) {
 	if len(publicDER) == 0 {
 		return nil, ErrKeyIsEmpty
 	}
@@ -22,5 +30,12 @@ func New(publicDER []byte) (*Key, error) {
 	return &Key{
 		ID:        uuid.New().String(),
 		PublicDER: publicDER,
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
 	}, nil
 }
+
+// Valid returns true if tokens signed with the key can be accepted at the given time.
+func (k *Key) Valid(at time.Time) bool {
+	return k.RevokedAt == nil && at.Before(k.ExpiresAt)
+}
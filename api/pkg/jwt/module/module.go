@@ -3,10 +3,12 @@ package module
 import (
 	"getsturdy.com/api/pkg/di"
 	keys_db "getsturdy.com/api/pkg/jwt/keys/db"
+	revocations_db "getsturdy.com/api/pkg/jwt/revocations/db"
 	"getsturdy.com/api/pkg/jwt/service"
 )
 
 func Module(c *di.Container) {
 	c.Import(keys_db.Module)
+	c.Import(revocations_db.Module)
 	c.Import(service.Module)
 }
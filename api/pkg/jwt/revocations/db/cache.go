@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+var _ Repository = &cache{}
+
+type cacheEntry struct {
+	revokedAt time.Time
+	found     bool
+	fetchedAt time.Time
+}
+
+// cache keeps revocations in memory for a short period of time, so that revocations made by other instances
+// take effect within seconds without hitting the database on every request.
+type cache struct {
+	db  Repository
+	ttl time.Duration
+
+	cache      map[string]*cacheEntry
+	cacheGuard *sync.RWMutex
+	lastSweep  time.Time
+}
+
+func NewCache(db Repository, ttl time.Duration) *cache {
+	return &cache{
+		db:  db,
+		ttl: ttl,
+
+		cache:      make(map[string]*cacheEntry),
+		cacheGuard: &sync.RWMutex{},
+	}
+}
+
+func (c *cache) Set(ctx context.Context, subject string, revokedAt time.Time) error {
+	if err := c.db.Set(ctx, subject, revokedAt); err != nil {
+		return err
+	}
+	c.cacheGuard.Lock()
+	c.put(subject, &cacheEntry{revokedAt: revokedAt, found: true, fetchedAt: time.Now()})
+	c.cacheGuard.Unlock()
+	return nil
+}
+
+func (c *cache) Get(ctx context.Context, subject string) (time.Time, error) {
+	c.cacheGuard.RLock()
+	cached, foundInCache := c.cache[subject]
+	c.cacheGuard.RUnlock()
+
+	if foundInCache && time.Since(cached.fetchedAt) < c.ttl {
+		if !cached.found {
+			return time.Time{}, sql.ErrNoRows
+		}
+		return cached.revokedAt, nil
+	}
+
+	revokedAt, err := c.db.Get(ctx, subject)
+	switch {
+	case err == nil:
+		c.cacheGuard.Lock()
+		c.put(subject, &cacheEntry{revokedAt: revokedAt, found: true, fetchedAt: time.Now()})
+		c.cacheGuard.Unlock()
+		return revokedAt, nil
+	case errors.Is(err, sql.ErrNoRows):
+		c.cacheGuard.Lock()
+		c.put(subject, &cacheEntry{fetchedAt: time.Now()})
+		c.cacheGuard.Unlock()
+		return time.Time{}, sql.ErrNoRows
+	default:
+		return time.Time{}, err
+	}
+}
+
+// put caches the entry. Expired entries are removed at most once per ttl, so that the cache does not grow
+// with every subject that has ever been looked up. Must be called with the write lock held.
+func (c *cache) put(subject string, entry *cacheEntry) {
+	c.cache[subject] = entry
+
+	if entry.fetchedAt.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	for k, e := range c.cache {
+		if entry.fetchedAt.Sub(e.fetchedAt) >= c.ttl {
+			delete(c.cache, k)
+		}
+	}
+	c.lastSweep = entry.fetchedAt
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *database {
+	return &database{
+		db: db,
+	}
+}
+
+func (db *database) Set(ctx context.Context, subject string, revokedAt time.Time) error {
+	if _, err := db.db.ExecContext(ctx, `
+	INSERT INTO jwt_subject_revocations (
+		subject, revoked_at
+	) VALUES (
+		$1, $2
+	)
+	ON CONFLICT (subject) DO UPDATE SET revoked_at = EXCLUDED.revoked_at
+	`, subject, revokedAt); err != nil {
+		return fmt.Errorf("failed to upsert: %w", err)
+	}
+	return nil
+}
+
+func (db *database) Get(ctx context.Context, subject string) (time.Time, error) {
+	var revokedAt time.Time
+	if err := db.db.GetContext(ctx, &revokedAt, `
+	SELECT
+		revoked_at
+	FROM
+		jwt_subject_revocations
+	WHERE
+		subject = $1
+	`, subject); err != nil {
+		return time.Time{}, err
+	}
+	return revokedAt, nil
+}
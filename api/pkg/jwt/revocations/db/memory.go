@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	guard     sync.RWMutex
+	bySubject map[string]time.Time
+}
+
+func NewInMemory() *memory {
+	return &memory{
+		bySubject: map[string]time.Time{},
+	}
+}
+
+func (db *memory) Set(_ context.Context, subject string, revokedAt time.Time) error {
+	db.guard.Lock()
+	defer db.guard.Unlock()
+	db.bySubject[subject] = revokedAt
+	return nil
+}
+
+func (db *memory) Get(_ context.Context, subject string) (time.Time, error) {
+	db.guard.RLock()
+	defer db.guard.RUnlock()
+	revokedAt, found := db.bySubject[subject]
+	if !found {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return revokedAt, nil
+}
@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Repository stores when all tokens of a subject were revoked.
+type Repository interface {
+	// Set revokes all tokens of the subject that were issued before revokedAt.
+	Set(ctx context.Context, subject string, revokedAt time.Time) error
+	// Get returns when the tokens of the subject were last revoked. sql.ErrNoRows is returned if they never
+	// were.
+	Get(ctx context.Context, subject string) (time.Time, error)
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// JWKS returns the public keys that tokens issued by Sturdy can be verified with, as a JSON Web Key Set.
+//
+// Keys are rotated, so clients should refetch the set when they see a token signed with an unknown key.
+func JWKS(logger *zap.Logger, jwtService *service_jwt.Service) func(*gin.Context) {
+	return func(c *gin.Context) {
+		set, err := jwtService.JWKS(c.Request.Context())
+		if err != nil {
+			logger.Error("failed to get jwks", zap.Error(err))
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, set)
+	}
+}
@@ -5,7 +5,8 @@ import (
 )
 
 type jwtClaims struct {
-	Type jwt.TokenType `json:"type,omitempty"`
+	Type      jwt.TokenType `json:"type,omitempty"`
+	SessionID string        `json:"sid,omitempty"`
 }
 
 type deprecatedClaims struct {
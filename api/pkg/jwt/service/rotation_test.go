@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/jwt"
+	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	jose_jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+func keyID(t *testing.T, token *jwt.Token) string {
+	parsed, err := jose_jwt.ParseSigned(token.Token)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return parsed.Headers[0].KeyID
+}
+
+func TestIssueToken_shouldRotateKeys(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	first, err := svc.IssueToken(ctx, "user-id", maxTokenValidity, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	now = now.Add(rotationInterval - time.Hour)
+	sameKey, err := svc.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+	assert.Equal(t, keyID(t, first), keyID(t, sameKey))
+
+	now = now.Add(time.Hour)
+	second, err := svc.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+	assert.NotEqual(t, keyID(t, first), keyID(t, second))
+
+	// tokens signed with the old key are still valid
+	_, err = svc.Verify(ctx, first.Token, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	jwks, err := svc.JWKS(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, jwks.Keys, 2)
+
+	// the old key is retired once all tokens signed with it have expired
+	now = now.Add(maxTokenValidity + time.Minute)
+	_, err = svc.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	jwks, err = svc.JWKS(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, jwks.Key(keyID(t, first)))
+	assert.Len(t, jwks.Keys, 2)
+}
+
+func TestIssueToken_shouldRejectTooLongValidity(t *testing.T) {
+	svc := NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+
+	_, err := svc.IssueToken(context.Background(), "user-id", maxTokenValidity+time.Hour, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, ErrValidityTooLong)
+}
+
+func TestRotateAll_shouldInvalidateAllTokens(t *testing.T) {
+	ctx := context.Background()
+	keysRepo := db_keys.NewInMemory()
+	revocationsRepo := db_revocations.NewInMemory()
+
+	// two instances sharing the same database
+	svc := NewService(zap.NewNop(), keysRepo, revocationsRepo)
+	other := NewService(zap.NewNop(), keysRepo, revocationsRepo)
+
+	token, err := svc.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+	otherToken, err := other.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.RotateAll(ctx))
+
+	_, err = svc.Verify(ctx, token.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = svc.Verify(ctx, otherToken.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	newToken, err := svc.IssueToken(ctx, "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+	assert.NotEqual(t, keyID(t, token), keyID(t, newToken))
+
+	_, err = svc.Verify(ctx, newToken.Token, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	jwks, err := svc.JWKS(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, jwks.Keys, 1) {
+		assert.Equal(t, keyID(t, newToken), jwks.Keys[0].KeyID)
+	}
+}
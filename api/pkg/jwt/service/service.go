@@ -15,6 +15,7 @@ import (
 	"getsturdy.com/api/pkg/jwt"
 	"getsturdy.com/api/pkg/jwt/keys"
 	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -24,58 +25,146 @@ import (
 
 // Known errors.
 var (
-	ErrInvalidToken = fmt.Errorf("token is invalid")
-	ErrTokenExpired = fmt.Errorf("token is expired")
+	ErrInvalidToken    = fmt.Errorf("token is invalid")
+	ErrTokenExpired    = fmt.Errorf("token is expired")
+	ErrTokenRevoked    = fmt.Errorf("token is revoked")
+	ErrValidityTooLong = fmt.Errorf("token validity is too long")
 )
 
 const (
 	defaultIssuer = "https://getsturdy.com"
+
+	// revocationsCacheTTL is how long revocations are cached for. Revocations made on other instances take
+	// effect after at most this long.
+	revocationsCacheTTL = 5 * time.Second
+
+	// keysCacheTTL is how long keys are cached for. Keys revoked on other instances stop being accepted
+	// and used for signing after at most this long.
+	keysCacheTTL = 5 * time.Second
+
+	// rotationInterval is how long a key is used to sign new tokens before it is replaced with a new one.
+	rotationInterval = 7 * 24 * time.Hour
+
+	// maxTokenValidity is the longest a token can be valid for. Keys are kept around for this long after
+	// they are rotated, so that all tokens signed with them can be verified until they expire.
+	maxTokenValidity = 31 * 24 * time.Hour
 )
 
+// signingKey is the private part of a key, it is never stored and only exists on the instance that
+// created it.
+type signingKey struct {
+	key    *keys.Key
+	signer jose.Signer
+}
+
 type Service struct {
-	logger   *zap.Logger
-	keysRepo db_keys.Repository
+	logger          *zap.Logger
+	keysRepo        db_keys.Repository
+	revocationsRepo db_revocations.Repository
+
+	signingKeyGuard *sync.Mutex
+	signingKey      *signingKey
 
-	signerInitOnce *sync.Once
-	signer         jose.Signer
+	now func() time.Time
 }
 
-func NewService(logger *zap.Logger, keysRepo db_keys.Repository) *Service {
+func NewService(logger *zap.Logger, keysRepo db_keys.Repository, revocationsRepo db_revocations.Repository) *Service {
 	return &Service{
-		logger:   logger,
-		keysRepo: db_keys.NewCache(keysRepo),
+		logger:          logger,
+		keysRepo:        db_keys.NewCache(keysRepo, keysCacheTTL),
+		revocationsRepo: db_revocations.NewCache(revocationsRepo, revocationsCacheTTL),
 
-		signerInitOnce: &sync.Once{},
+		signingKeyGuard: &sync.Mutex{},
+
+		now: time.Now,
+	}
+}
+
+// signer returns a signer for new tokens. A new key is generated if the current one is due for rotation,
+// or if it has been revoked.
+func (s *Service) signer(ctx context.Context) (jose.Signer, error) {
+	s.signingKeyGuard.Lock()
+	defer s.signingKeyGuard.Unlock()
+
+	if s.signingKey != nil {
+		rotate, err := s.shouldRotate(ctx, s.signingKey.key)
+		if err != nil {
+			return nil, err
+		}
+		if !rotate {
+			return s.signingKey.signer, nil
+		}
+	}
+
+	if err := s.rotate(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.signingKey.signer, nil
+}
+
+func (s *Service) shouldRotate(ctx context.Context, key *keys.Key) (bool, error) {
+	if s.now().Sub(key.CreatedAt) >= rotationInterval {
+		return true, nil
+	}
+
+	// the key might have been revoked, or retired, by another instance
+	stored, err := s.keysRepo.Get(ctx, key.ID)
+	switch {
+	case err == nil:
+		return !stored.Valid(s.now()), nil
+	case errors.Is(err, sql.ErrNoRows):
+		return true, nil
+	default:
+		return false, fmt.Errorf("failed to get key: %w", err)
 	}
 }
 
-func (s *Service) initOnce(ctx context.Context) error {
-	var err error
-	s.signerInitOnce.Do(func() {
-		err = s.init(ctx)
-	})
-	return err
+// rotate replaces the signing key with a new one, and retires keys that have expired. Tokens signed with
+// the old signing key can be verified until the old key expires.
+func (s *Service) rotate(ctx context.Context) error {
+	signingKey, err := s.newSigningKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.signingKey != nil {
+		s.logger.Info("rotated jwt signing key",
+			zap.String("old_key_id", s.signingKey.key.ID),
+			zap.String("new_key_id", signingKey.key.ID),
+		)
+	}
+	s.signingKey = signingKey
+
+	if err := s.keysRepo.DeleteExpired(ctx, s.now()); err != nil {
+		// expired keys are not accepted anyway, so failing to delete them is not critical
+		s.logger.Error("failed to retire expired jwt keys", zap.Error(err))
+	}
+
+	return nil
 }
 
-// init prepares jwt service for work by generating a jwt signer.
-func (s *Service) init(ctx context.Context) error {
+// newSigningKey generates a new signing key, and stores the public part of it in the database.
+func (s *Service) newSigningKey(ctx context.Context) (*signingKey, error) {
 	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		return fmt.Errorf("failed to generate encryption key: %w", err)
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
 	}
 
 	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
 	if err != nil {
-		return fmt.Errorf("failed to marshal encryption key: %w", err)
+		return nil, fmt.Errorf("failed to marshal encryption key: %w", err)
 	}
 
-	key, err := keys.New(publicDER)
+	// the key signs tokens for rotationInterval, and the last of them is valid for at most maxTokenValidity
+	now := s.now()
+	key, err := keys.New(publicDER, now, now.Add(rotationInterval+maxTokenValidity))
 	if err != nil {
-		return fmt.Errorf("failed to create a key: %w", err)
+		return nil, fmt.Errorf("failed to create a key: %w", err)
 	}
 
 	if err := s.keysRepo.Create(ctx, key); err != nil {
-		return fmt.Errorf("failed to store key in the database: %w", err)
+		return nil, fmt.Errorf("failed to store key in the database: %w", err)
 	}
 
 	options := (&jose.SignerOptions{}).
@@ -87,20 +176,86 @@ func (s *Service) init(ctx context.Context) error {
 		Key:       privateKey,
 	}, options)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	s.signer = signer
+	return &signingKey{
+		key:    key,
+		signer: signer,
+	}, nil
+}
+
+// RotateAll revokes all keys, including the ones used by other instances, and starts signing tokens with
+// a new key. All tokens issued so far are invalidated.
+func (s *Service) RotateAll(ctx context.Context) error {
+	s.signingKeyGuard.Lock()
+	defer s.signingKeyGuard.Unlock()
+
+	if err := s.keysRepo.RevokeAll(ctx, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke keys: %w", err)
+	}
+
+	if err := s.rotate(ctx); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func (s *Service) IssueToken(ctx context.Context, subject string, validFor time.Duration, tokenType jwt.TokenType) (*jwt.Token, error) {
-	if err := s.initOnce(ctx); err != nil {
+// JWKS returns the public keys that tokens can be verified with.
+func (s *Service) JWKS(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	kk, err := s.keysRepo.ListValid(ctx, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	set := &jose.JSONWebKeySet{
+		Keys: make([]jose.JSONWebKey, 0, len(kk)),
+	}
+	for _, key := range kk {
+		publicKey, err := parsePublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       publicKey,
+			KeyID:     key.ID,
+			Algorithm: string(jose.ES256),
+			Use:       "sig",
+		})
+	}
+	return set, nil
+}
+
+type issueOptions struct {
+	sessionID string
+}
+
+type IssueOption func(*issueOptions)
+
+// WithSessionID binds the token to a session. The token is revoked when the session is.
+func WithSessionID(sessionID string) IssueOption {
+	return func(o *issueOptions) {
+		o.sessionID = sessionID
+	}
+}
+
+func (s *Service) IssueToken(ctx context.Context, subject string, validFor time.Duration, tokenType jwt.TokenType, opts ...IssueOption) (*jwt.Token, error) {
+	if validFor > maxTokenValidity {
+		return nil, fmt.Errorf("%s is longer than %s: %w", validFor, maxTokenValidity, ErrValidityTooLong)
+	}
+
+	signer, err := s.signer(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	now := time.Now()
+	options := &issueOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	now := s.now()
 	stdClaims := &jose_jwt.Claims{
 		ID:       uuid.New().String(),
 		Issuer:   defaultIssuer,
@@ -109,10 +264,11 @@ func (s *Service) IssueToken(ctx context.Context, subject string, validFor time.
 		Expiry:   jose_jwt.NewNumericDate(now.Add(validFor)),
 	}
 	sturdyClaims := jwtClaims{
-		Type: tokenType,
+		Type:      tokenType,
+		SessionID: options.sessionID,
 	}
 
-	token, err := jose_jwt.Signed(s.signer).
+	token, err := jose_jwt.Signed(signer).
 		Claims(stdClaims).
 		Claims(sturdyClaims).
 		CompactSerialize()
@@ -124,9 +280,61 @@ func (s *Service) IssueToken(ctx context.Context, subject string, validFor time.
 		Subject:   stdClaims.Subject,
 		ExpiresAt: stdClaims.Expiry.Time(),
 		Type:      sturdyClaims.Type,
+		SessionID: sturdyClaims.SessionID,
 	}, nil
 }
 
+// RevokeSubject revokes all tokens issued to the subject so far. Tokens issued after the call are not
+// affected.
+func (s *Service) RevokeSubject(ctx context.Context, subject string) error {
+	if err := s.revocationsRepo.Set(ctx, subject, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession revokes all tokens that belong to the session. Revoked sessions can not be restored.
+func (s *Service) RevokeSession(ctx context.Context, sessionID string) error {
+	if err := s.revocationsRepo.Set(ctx, sessionSubject(sessionID), time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// sessionSubject is the key that session revocations are stored with. It can't collide with real subjects,
+// which are ids.
+func sessionSubject(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// sessionRevoked returns true if the session has been revoked.
+func (s *Service) sessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	_, err := s.revocationsRepo.Get(ctx, sessionSubject(sessionID))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to get revocation: %w", err)
+	}
+}
+
+// revoked returns true if the token was issued to the subject before the subject's tokens were revoked.
+func (s *Service) revoked(ctx context.Context, subject string, issuedAt time.Time) (bool, error) {
+	revokedAt, err := s.revocationsRepo.Get(ctx, subject)
+	switch {
+	case err == nil:
+		// issuedAt has a precision of a second, so tokens issued within the same second as the revocation
+		// are considered revoked.
+		return !issuedAt.After(revokedAt.Truncate(time.Second)), nil
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to get revocation: %w", err)
+	}
+}
+
 // Verify checks token signature and returns it's meaningful content.
 func (s *Service) Verify(ctx context.Context, rawToken string, expectedTypes ...jwt.TokenType) (*jwt.Token, error) {
 	jwtoken, err := jose_jwt.ParseSigned(rawToken)
@@ -147,12 +355,21 @@ func (s *Service) Verify(ctx context.Context, rawToken string, expectedTypes ...
 	}
 }
 
-func (s *Service) get(ctx context.Context, id string) (*ecdsa.PublicKey, error) {
+func (s *Service) get(ctx context.Context, id string) (*keys.Key, *ecdsa.PublicKey, error) {
 	key, err := s.keysRepo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find key '%s': %w", id, err)
+		return nil, nil, fmt.Errorf("failed to find key '%s': %w", id, err)
+	}
+
+	publicKey, err := parsePublicKey(key)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	return key, publicKey, nil
+}
+
+func parsePublicKey(key *keys.Key) (*ecdsa.PublicKey, error) {
 	untypedResult, err := x509.ParsePKIXPublicKey(key.PublicDER)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse PKIX public key: %w", err)
@@ -179,7 +396,7 @@ func validType(typ jwt.TokenType, expected ...jwt.TokenType) bool {
 func (s *Service) verify(ctx context.Context, rawToken string, jwtoken *jose_jwt.JSONWebToken, expectedTypes ...jwt.TokenType) (*jwt.Token, error) {
 	id := jwtoken.Headers[0].KeyID
 
-	pubicKey, err := s.get(ctx, id)
+	key, pubicKey, err := s.get(ctx, id)
 	switch {
 	case err == nil:
 	case errors.Is(err, sql.ErrNoRows):
@@ -195,19 +412,44 @@ func (s *Service) verify(ctx context.Context, rawToken string, jwtoken *jose_jwt
 	}
 
 	validateErr := stdClaims.ValidateWithLeeway(jose_jwt.Expected{
-		Time:   time.Now(),
+		Time:   s.now(),
 		Issuer: defaultIssuer,
 	}, time.Second)
 	switch {
 	case validateErr == nil:
+		if key.RevokedAt != nil {
+			return nil, ErrTokenRevoked
+		}
+		if !s.now().Before(key.ExpiresAt) {
+			return nil, ErrTokenExpired
+		}
 		if !validType(sturdyClaims.Type, expectedTypes...) {
 			return nil, ErrInvalidToken
 		}
+		if stdClaims.IssuedAt != nil {
+			revoked, err := s.revoked(ctx, stdClaims.Subject, stdClaims.IssuedAt.Time())
+			if err != nil {
+				return nil, err
+			}
+			if revoked {
+				return nil, ErrTokenRevoked
+			}
+		}
+		if sturdyClaims.SessionID != "" {
+			revoked, err := s.sessionRevoked(ctx, sturdyClaims.SessionID)
+			if err != nil {
+				return nil, err
+			}
+			if revoked {
+				return nil, ErrTokenRevoked
+			}
+		}
 		return &jwt.Token{
 			Token:     rawToken,
 			Subject:   stdClaims.Subject,
 			ExpiresAt: stdClaims.Expiry.Time(),
 			Type:      sturdyClaims.Type,
+			SessionID: sturdyClaims.SessionID,
 		}, nil
 	case errors.Is(validateErr, jose_jwt.ErrExpired):
 		return nil, ErrTokenExpired
@@ -7,6 +7,7 @@ import (
 
 	"getsturdy.com/api/pkg/jwt"
 	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
 	"getsturdy.com/api/pkg/jwt/service"
 
 	"github.com/stretchr/testify/assert"
@@ -14,7 +15,7 @@ import (
 )
 
 func TestVerify_shouldVerifyIssuedKey(t *testing.T) {
-	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory())
+	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
 
 	token, err := svc.IssueToken(context.Background(), "user-id", time.Hour, jwt.TokenTypeAuth)
 	assert.NoError(t, err)
@@ -24,3 +25,45 @@ func TestVerify_shouldVerifyIssuedKey(t *testing.T) {
 		assert.Equal(t, token, verifiedToken)
 	}
 }
+
+func TestVerify_shouldRejectRevokedSubject(t *testing.T) {
+	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+
+	token, err := svc.IssueToken(context.Background(), "user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	otherToken, err := svc.IssueToken(context.Background(), "other-user-id", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.RevokeSubject(context.Background(), "user-id"))
+
+	_, err = svc.Verify(context.Background(), token.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, service.ErrTokenRevoked)
+
+	_, err = svc.Verify(context.Background(), otherToken.Token, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+}
+
+func TestVerify_shouldRejectRevokedSession(t *testing.T) {
+	svc := service.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+
+	token, err := svc.IssueToken(context.Background(), "user-id", time.Hour, jwt.TokenTypeAuth, service.WithSessionID("session-id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "session-id", token.SessionID)
+
+	otherToken, err := svc.IssueToken(context.Background(), "user-id", time.Hour, jwt.TokenTypeAuth, service.WithSessionID("other-session-id"))
+	assert.NoError(t, err)
+
+	verified, err := svc.Verify(context.Background(), token.Token, jwt.TokenTypeAuth)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "session-id", verified.SessionID)
+	}
+
+	assert.NoError(t, svc.RevokeSession(context.Background(), "session-id"))
+
+	_, err = svc.Verify(context.Background(), token.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, service.ErrTokenRevoked)
+
+	_, err = svc.Verify(context.Background(), otherToken.Token, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+}
@@ -19,4 +19,6 @@ type Token struct {
 	Type      TokenType
 	Subject   string
 	ExpiresAt time.Time
+	// SessionID is set if the token belongs to a session, the token is revoked together with the session.
+	SessionID string
 }
@@ -23,6 +23,8 @@ import (
 	"getsturdy.com/api/pkg/codebases/acl"
 	provider_acl "getsturdy.com/api/pkg/codebases/acl/provider"
 	"getsturdy.com/api/pkg/internal/inmemory"
+	db_teams "getsturdy.com/api/pkg/organization/teams/db"
+	service_teams "getsturdy.com/api/pkg/organization/teams/service"
 	"getsturdy.com/api/pkg/users"
 	db_users "getsturdy.com/api/pkg/users/db"
 	service_user "getsturdy.com/api/pkg/users/service"
@@ -38,11 +40,16 @@ func TestListAllows(t *testing.T) {
 		zap.NewNop(),
 		userRepo,
 		nil,
+		nil,
+		nil,
 	)
 
 	aclProvider := provider_acl.New(
 		aclRepo,
+		inmemory.NewInMemoryAclVersionRepo(aclRepo),
 		nil,
+		userService,
+		service_teams.New(db_teams.NewMemory(), db_teams.NewMemberMemory(), nil),
 		nil,
 	)
 
@@ -53,6 +60,7 @@ func TestListAllows(t *testing.T) {
 		nil,
 		aclProvider,
 		nil,
+		nil,
 	)
 
 	type listAllowsResponse struct {
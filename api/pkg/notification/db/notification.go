@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"time"
 
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/users"
@@ -17,6 +18,10 @@ type Repository interface {
 	ListByUser(userID users.ID, limit, offset int) ([]notification.Notification, error)
 	ListByUserAndIds(userID users.ID, ids []string) ([]notification.Notification, error)
 	ArchiveByUserAndIds(userID users.ID, ids []string) error
+	// ListUnseenByUser returns notifications created after since, that are neither archived nor seen,
+	// oldest first.
+	ListUnseenByUser(userID users.ID, since time.Time) ([]notification.Notification, error)
+	MarkSeenByUserAndIds(userID users.ID, ids []string) error
 }
 
 type repo struct {
@@ -29,7 +34,7 @@ func NewRepository(db *sqlx.DB) Repository {
 
 func (r *repo) Get(id string) (notification.Notification, error) {
 	var res notification.Notification
-	err := r.db.Get(&res, `SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at
+	err := r.db.Get(&res, `SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at, seen_at
 		FROM notifications
 		WHERE id = $1`, id)
 	if err != nil {
@@ -59,7 +64,7 @@ func (r *repo) Update(notification notification.Notification) error {
 
 func (r *repo) ListByUser(userID users.ID, limit, offset int) ([]notification.Notification, error) {
 	var res []notification.Notification
-	err := r.db.Select(&res, `SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at
+	err := r.db.Select(&res, `SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at, seen_at
 		FROM notifications
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -71,7 +76,7 @@ func (r *repo) ListByUser(userID users.ID, limit, offset int) ([]notification.No
 }
 
 func (r *repo) ListByUserAndIds(userID users.ID, ids []string) ([]notification.Notification, error) {
-	query, args, err := sqlx.In(`SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at
+	query, args, err := sqlx.In(`SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at, seen_at
 	FROM notifications
 	WHERE user_id = ?
 	  AND id IN(?)`,
@@ -106,3 +111,37 @@ func (r *repo) ArchiveByUserAndIds(userID users.ID, ids []string) error {
 	}
 	return nil
 }
+
+func (r *repo) ListUnseenByUser(userID users.ID, since time.Time) ([]notification.Notification, error) {
+	var res []notification.Notification
+	err := r.db.Select(&res, `SELECT id, codebase_id, user_id, type, reference_id, created_at, archived_at, seen_at
+		FROM notifications
+		WHERE user_id = $1
+		  AND created_at > $2
+		  AND archived_at IS NULL
+		  AND seen_at IS NULL
+		ORDER BY created_at ASC`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query table: %w", err)
+	}
+	return res, nil
+}
+
+func (r *repo) MarkSeenByUserAndIds(userID users.ID, ids []string) error {
+	query, args, err := sqlx.In(`UPDATE notifications
+	SET seen_at = NOW()
+	WHERE user_id = ?
+	  AND seen_at IS NULL
+	  AND id IN(?)`,
+		userID,
+		ids)
+	if err != nil {
+		return fmt.Errorf("failed to create query: %w", err)
+	}
+	query = r.db.Rebind(query)
+	_, err = r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query table: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/users"
@@ -42,3 +43,66 @@ func (repo *PreferenceRepository) ListByUserID(ctx context.Context, userID users
 	}
 	return res, nil
 }
+
+func (repo *PreferenceRepository) GetDigest(ctx context.Context, userID users.ID) (*notification.DigestPreference, error) {
+	var res notification.DigestPreference
+	if err := repo.db.GetContext(ctx, &res, `SELECT user_id, frequency, last_sent_at
+		FROM notification_digest_preferences
+		WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (repo *PreferenceRepository) UpsertDigest(ctx context.Context, preference *notification.DigestPreference) error {
+	if _, err := repo.db.NamedExecContext(ctx, `INSERT INTO notification_digest_preferences
+		(user_id, frequency, last_sent_at)
+		VALUES
+		(:user_id, :frequency, :last_sent_at)
+		ON CONFLICT (user_id)
+			DO UPDATE SET frequency = :frequency, last_sent_at = :last_sent_at
+		`, preference); err != nil {
+		return fmt.Errorf("failed to upsert: %w", err)
+	}
+	return nil
+}
+
+// ListDigests returns the preferences of all users that receive notifications in digests.
+func (repo *PreferenceRepository) ListDigests(ctx context.Context) ([]*notification.DigestPreference, error) {
+	var res []*notification.DigestPreference
+	if err := repo.db.SelectContext(ctx, &res, `SELECT user_id, frequency, last_sent_at
+		FROM notification_digest_preferences
+		WHERE frequency != $1`, notification.DigestFrequencyImmediate); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return res, nil
+}
+
+// ClaimDigest sets the time the last digest was sent at, if it has not been changed since the preference
+// was read. It returns false if another instance has claimed the digest first.
+func (repo *PreferenceRepository) ClaimDigest(ctx context.Context, preference *notification.DigestPreference, sentAt time.Time) (bool, error) {
+	res, err := repo.db.ExecContext(ctx, `UPDATE notification_digest_preferences
+		SET last_sent_at = $1
+		WHERE user_id = $2
+		  AND last_sent_at IS NOT DISTINCT FROM $3`, sentAt, preference.UserID, preference.LastSentAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to update: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// ReleaseDigest undoes ClaimDigest, so that the digest is sent again on the next run. It does nothing if the
+// digest has been claimed again since.
+func (repo *PreferenceRepository) ReleaseDigest(ctx context.Context, preference *notification.DigestPreference, sentAt time.Time) error {
+	if _, err := repo.db.ExecContext(ctx, `UPDATE notification_digest_preferences
+		SET last_sent_at = $1
+		WHERE user_id = $2
+		  AND last_sent_at = $3`, preference.LastSentAt, preference.UserID, sentAt); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
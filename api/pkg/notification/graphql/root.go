@@ -256,6 +256,37 @@ func (r *notificationRootResolver) ArchiveNotifications(ctx context.Context, arg
 	return res, nil
 }
 
+func (r *notificationRootResolver) MarkNotificationsSeen(ctx context.Context, args resolvers.MarkNotificationsSeenArgs) ([]resolvers.NotificationResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var notificationIDs []string
+	for _, id := range args.Input.IDs {
+		notificationIDs = append(notificationIDs, string(id))
+	}
+	if len(notificationIDs) == 0 {
+		return nil, nil
+	}
+
+	if err := r.notificationRepository.MarkSeenByUserAndIds(userID, notificationIDs); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	notifications, err := r.notificationRepository.ListByUserAndIds(userID, notificationIDs)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	var res []resolvers.NotificationResolver
+	for _, notif := range notifications {
+		r.eventSender.User(userID, events.NotificationEvent, notif.ID)
+		res = append(res, &notificationResolver{notif: notif, root: r})
+	}
+	return res, nil
+}
+
 func (r *notificationRootResolver) UpdatedNotifications(ctx context.Context) (chan resolvers.NotificationResolver, error) {
 	userID, err := auth.UserID(ctx)
 	if err != nil {
@@ -322,6 +353,63 @@ func (r *notificationRootResolver) InternalNotificationPreferences(ctx context.C
 	return rr, nil
 }
 
+func convertDigestFrequency(in resolvers.NotificationDigestFrequency) (notification.DigestFrequency, error) {
+	switch in {
+	case resolvers.NotificationDigestFrequencyImmediate:
+		return notification.DigestFrequencyImmediate, nil
+	case resolvers.NotificationDigestFrequencyHourly:
+		return notification.DigestFrequencyHourly, nil
+	case resolvers.NotificationDigestFrequencyDaily:
+		return notification.DigestFrequencyDaily, nil
+	case resolvers.NotificationDigestFrequencyWeekly:
+		return notification.DigestFrequencyWeekly, nil
+	default:
+		return notification.DigestFrequencyUndefined, fmt.Errorf("unknown digest frequency: %s", in)
+	}
+}
+
+func resolveDigestFrequency(in notification.DigestFrequency) (resolvers.NotificationDigestFrequency, error) {
+	switch in {
+	case notification.DigestFrequencyImmediate:
+		return resolvers.NotificationDigestFrequencyImmediate, nil
+	case notification.DigestFrequencyHourly:
+		return resolvers.NotificationDigestFrequencyHourly, nil
+	case notification.DigestFrequencyDaily:
+		return resolvers.NotificationDigestFrequencyDaily, nil
+	case notification.DigestFrequencyWeekly:
+		return resolvers.NotificationDigestFrequencyWeekly, nil
+	default:
+		return resolvers.NotificationDigestFrequencyUndefined, fmt.Errorf("unknown digest frequency: %s", in)
+	}
+}
+
+func (r *notificationRootResolver) UpdateNotificationDigestFrequency(ctx context.Context, args resolvers.UpdateNotificationDigestFrequencyArgs) (resolvers.NotificationDigestFrequency, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return resolvers.NotificationDigestFrequencyUndefined, err
+	}
+
+	frequency, err := convertDigestFrequency(args.Input.Frequency)
+	if err != nil {
+		return resolvers.NotificationDigestFrequencyUndefined, gqlerrors.Error(gqlerrors.ErrBadRequest, "frequency", err.Error())
+	}
+
+	pref, err := r.preferencesService.UpdateDigest(ctx, userID, frequency)
+	if err != nil {
+		return resolvers.NotificationDigestFrequencyUndefined, gqlerrors.Error(err)
+	}
+
+	return resolveDigestFrequency(pref.Frequency)
+}
+
+func (r *notificationRootResolver) InternalNotificationDigestFrequency(ctx context.Context, userID users.ID) (resolvers.NotificationDigestFrequency, error) {
+	pref, err := r.preferencesService.Digest(ctx, userID)
+	if err != nil {
+		return resolvers.NotificationDigestFrequencyUndefined, gqlerrors.Error(err)
+	}
+	return resolveDigestFrequency(pref.Frequency)
+}
+
 type notificationPreferenceResolver struct {
 	preference *notification.Preference
 }
@@ -388,6 +476,14 @@ func (r *notificationResolver) ArchivedAt() *int32 {
 	return &t
 }
 
+func (r *notificationResolver) SeenAt() *int32 {
+	if r.notif.SeenAt == nil {
+		return nil
+	}
+	t := int32(r.notif.SeenAt.Unix())
+	return &t
+}
+
 func (r *notificationResolver) Codebase(ctx context.Context) (resolvers.CodebaseResolver, error) {
 	id := graphql.ID(r.notif.CodebaseID)
 	return r.root.codebaseResolver.Codebase(ctx, resolvers.CodebaseArgs{ID: &id})
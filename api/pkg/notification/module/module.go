@@ -6,6 +6,7 @@ import (
 	"getsturdy.com/api/pkg/notification/graphql"
 	"getsturdy.com/api/pkg/notification/sender"
 	"getsturdy.com/api/pkg/notification/service"
+	"getsturdy.com/api/pkg/notification/worker"
 )
 
 func Module(c *di.Container) {
@@ -13,4 +14,5 @@ func Module(c *di.Container) {
 	c.Import(graphql.Module)
 	c.Import(sender.Module)
 	c.Import(service.Module)
+	c.Import(worker.Module)
 }
@@ -15,6 +15,9 @@ type Notification struct {
 	ReferenceID      string           `db:"reference_id"`
 	CreatedAt        time.Time        `db:"created_at"`
 	ArchivedAt       *time.Time       `db:"archived_at"`
+	// SeenAt is set when the user has seen the notification in the web UI. Seen notifications are not
+	// included in digests.
+	SeenAt *time.Time `db:"seen_at"`
 }
 
 type NotificationType string
@@ -1,6 +1,10 @@
 package notification
 
-import "getsturdy.com/api/pkg/users"
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
 
 type Channel string
 
@@ -17,3 +21,56 @@ type Preference struct {
 	Channel Channel          `db:"channel"`
 	Enabled bool             `db:"enabled"`
 }
+
+// DigestFrequency is how often a user wants to receive notifications via email.
+type DigestFrequency string
+
+const (
+	DigestFrequencyUndefined DigestFrequency = ""
+	// DigestFrequencyImmediate sends one email per notification, as soon as it's created.
+	DigestFrequencyImmediate DigestFrequency = "immediate"
+	DigestFrequencyHourly    DigestFrequency = "hourly"
+	DigestFrequencyDaily     DigestFrequency = "daily"
+	DigestFrequencyWeekly    DigestFrequency = "weekly"
+)
+
+// Interval returns how much time passes between two digests, or zero if notifications are not batched.
+func (f DigestFrequency) Interval() time.Duration {
+	switch f {
+	case DigestFrequencyHourly:
+		return time.Hour
+	case DigestFrequencyDaily:
+		return 24 * time.Hour
+	case DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestPreference is used to determine how often user with _UserID_ receives notifications via email.
+type DigestPreference struct {
+	UserID     users.ID        `db:"user_id"`
+	Frequency  DigestFrequency `db:"frequency"`
+	LastSentAt *time.Time      `db:"last_sent_at"`
+}
+
+// Due returns true if it's time to send the next digest.
+func (p *DigestPreference) Due(now time.Time) bool {
+	interval := p.Frequency.Interval()
+	if interval == 0 {
+		return false
+	}
+	if p.LastSentAt == nil {
+		return true
+	}
+	return !now.Before(p.LastSentAt.Add(interval))
+}
+
+// Since returns the time from which notifications are included in the next digest.
+func (p *DigestPreference) Since(now time.Time) time.Time {
+	if p.LastSentAt == nil {
+		return now.Add(-p.Frequency.Interval())
+	}
+	return *p.LastSentAt
+}
@@ -0,0 +1,61 @@
+package notification_test
+
+import (
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/notification"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestPreference(t *testing.T) {
+	now := time.Date(2022, 3, 10, 12, 0, 0, 0, time.UTC)
+	hourAgo := now.Add(-time.Hour)
+	minuteAgo := now.Add(-time.Minute)
+
+	cases := []struct {
+		name       string
+		preference notification.DigestPreference
+		due        bool
+		since      time.Time
+	}{
+		{
+			name:       "immediate",
+			preference: notification.DigestPreference{Frequency: notification.DigestFrequencyImmediate},
+			due:        false,
+			since:      now,
+		},
+		{
+			name:       "never sent",
+			preference: notification.DigestPreference{Frequency: notification.DigestFrequencyDaily},
+			due:        true,
+			since:      now.Add(-24 * time.Hour),
+		},
+		{
+			name:       "hourly, sent an hour ago",
+			preference: notification.DigestPreference{Frequency: notification.DigestFrequencyHourly, LastSentAt: &hourAgo},
+			due:        true,
+			since:      hourAgo,
+		},
+		{
+			name:       "hourly, sent a minute ago",
+			preference: notification.DigestPreference{Frequency: notification.DigestFrequencyHourly, LastSentAt: &minuteAgo},
+			due:        false,
+			since:      minuteAgo,
+		},
+		{
+			name:       "weekly, sent an hour ago",
+			preference: notification.DigestPreference{Frequency: notification.DigestFrequencyWeekly, LastSentAt: &hourAgo},
+			due:        false,
+			since:      hourAgo,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.due, tc.preference.Due(now))
+			assert.Equal(t, tc.since, tc.preference.Since(now))
+		})
+	}
+}
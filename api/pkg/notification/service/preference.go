@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"getsturdy.com/api/pkg/notification"
 	db_notification "getsturdy.com/api/pkg/notification/db"
@@ -84,3 +87,38 @@ func (s *Preferences) ListByUserID(ctx context.Context, userID users.ID) ([]*not
 
 	return result, nil
 }
+
+// Digest returns how often the user receives notifications via email. By default, every notification is
+// sent immediately.
+func (s *Preferences) Digest(ctx context.Context, userID users.ID) (*notification.DigestPreference, error) {
+	p, err := s.preferencesRepo.GetDigest(ctx, userID)
+	switch {
+	case err == nil:
+		return p, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return &notification.DigestPreference{
+			UserID:    userID,
+			Frequency: notification.DigestFrequencyImmediate,
+		}, nil
+	default:
+		return nil, fmt.Errorf("failed to get digest preference: %w", err)
+	}
+}
+
+// UpdateDigest updates or creates the digest preference of the user.
+func (s *Preferences) UpdateDigest(ctx context.Context, userID users.ID, frequency notification.DigestFrequency) (*notification.DigestPreference, error) {
+	p, err := s.Digest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Frequency == notification.DigestFrequencyImmediate && frequency != notification.DigestFrequencyImmediate {
+		// notifications until now have already been sent, the first digest starts from here
+		now := time.Now()
+		p.LastSentAt = &now
+	}
+	p.Frequency = frequency
+	if err := s.preferencesRepo.UpsertDigest(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to upsert: %w", err)
+	}
+	return p, nil
+}
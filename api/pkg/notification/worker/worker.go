@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/emails/transactional"
+	"getsturdy.com/api/pkg/notification"
+	db_notification "getsturdy.com/api/pkg/notification/db"
+	db_users "getsturdy.com/api/pkg/users/db"
+
+	"go.uber.org/zap"
+)
+
+var (
+	runEvery = 5 * time.Minute
+)
+
+// Worker periodically sends notification digests to the users that have opted in to them.
+type Worker struct {
+	logger           *zap.Logger
+	preferencesRepo  *db_notification.PreferenceRepository
+	notificationRepo db_notification.Repository
+	userRepo         db_users.Repository
+	emailSender      transactional.EmailSender
+}
+
+func New(
+	logger *zap.Logger,
+	preferencesRepo *db_notification.PreferenceRepository,
+	notificationRepo db_notification.Repository,
+	userRepo db_users.Repository,
+	emailSender transactional.EmailSender,
+) *Worker {
+	return &Worker{
+		logger:           logger.Named("notification_digests_worker"),
+		preferencesRepo:  preferencesRepo,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		emailSender:      emailSender,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	w.logger.Info("starting")
+
+	ticker := time.NewTicker(runEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.run(ctx, time.Now()); err != nil {
+				w.logger.Error("failed to send digests", zap.Error(err))
+			}
+		case <-ctx.Done():
+			w.logger.Info("stopping")
+			return nil
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context, now time.Time) error {
+	pp, err := w.preferencesRepo.ListDigests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list digest preferences: %w", err)
+	}
+	for _, p := range pp {
+		if !p.Due(now) {
+			continue
+		}
+		if err := w.send(ctx, p, now); err != nil {
+			w.logger.Error("failed to send digest", zap.Stringer("user_id", p.UserID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (w *Worker) send(ctx context.Context, p *notification.DigestPreference, now time.Time) error {
+	since := p.Since(now)
+
+	// another instance might be sending the same digest, only the one that claims it continues
+	claimed, err := w.preferencesRepo.ClaimDigest(ctx, p, now)
+	if err != nil {
+		return fmt.Errorf("failed to claim digest: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := w.sendClaimed(ctx, p, since, now); err != nil {
+		// release the claim, so that the digest is retried on the next run
+		if releaseErr := w.preferencesRepo.ReleaseDigest(ctx, p, now); releaseErr != nil {
+			w.logger.Error("failed to release digest", zap.Stringer("user_id", p.UserID), zap.Error(releaseErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// sendClaimed sends the digest of notifications between since and now.
+func (w *Worker) sendClaimed(ctx context.Context, p *notification.DigestPreference, since, now time.Time) error {
+	notifs, err := w.notificationRepo.ListUnseenByUser(p.UserID, since)
+	if err != nil {
+		return fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	// notifications created after now belong to the next digest
+	included := make([]notification.Notification, 0, len(notifs))
+	for _, notif := range notifs {
+		if notif.CreatedAt.After(now) {
+			continue
+		}
+		included = append(included, notif)
+	}
+	if len(included) == 0 {
+		return nil
+	}
+
+	usr, err := w.userRepo.Get(p.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := w.emailSender.SendNotificationDigest(ctx, usr, included); err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ MembershipRepository = &membershipDatabase{}
+
+type membershipDatabase struct {
+	db *sqlx.DB
+}
+
+func NewMembershipDatabase(db *sqlx.DB) MembershipRepository {
+	return &membershipDatabase{
+		db: db,
+	}
+}
+
+func (d *membershipDatabase) Create(ctx context.Context, membership *oidc.Membership) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO oidc_organization_members (
+			organization_id, user_id, created_at
+		) VALUES (
+			:organization_id, :user_id, :created_at
+		)
+		ON CONFLICT (organization_id, user_id) DO NOTHING
+	`, membership); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *membershipDatabase) ListByUserID(ctx context.Context, userID users.ID) ([]*oidc.Membership, error) {
+	var mm []*oidc.Membership
+	if err := d.db.SelectContext(ctx, &mm, `
+		SELECT
+			organization_id, user_id, created_at
+		FROM oidc_organization_members
+		WHERE user_id = $1
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return mm, nil
+}
+
+func (d *membershipDatabase) Delete(ctx context.Context, organizationID string, userID users.ID) error {
+	if _, err := d.db.ExecContext(ctx, `
+		DELETE FROM oidc_organization_members
+		WHERE organization_id = $1
+		  AND user_id = $2
+	`, organizationID, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
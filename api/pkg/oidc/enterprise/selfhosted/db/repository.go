@@ -0,0 +1,16 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/users"
+)
+
+// MembershipRepository keeps track of the organization memberships that were created by single sign-on.
+type MembershipRepository interface {
+	// Create saves the membership, it's a no-op if it already exists.
+	Create(context.Context, *oidc.Membership) error
+	ListByUserID(context.Context, users.ID) ([]*oidc.Membership, error)
+	Delete(ctx context.Context, organizationID string, userID users.ID) error
+}
@@ -0,0 +1,132 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/oidc"
+	service_oidc "getsturdy.com/api/pkg/oidc/enterprise/selfhosted/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// flowCookieName is the cookie that holds the state, nonce and PKCE code verifier between the login
+	// redirect and the callback.
+	flowCookieName = "oidc_flow"
+	flowCookieAge  = 10 * time.Minute
+)
+
+// setFlowCookie sets the flow cookie. The cookie is sent back to the callback, so it's secure if the callback
+// is served over https.
+func setFlowCookie(c *gin.Context, cfg *oidc.Configuration, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     flowCookieName,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode, // The callback is a cross-site redirect from the identity provider
+		Secure:   strings.HasPrefix(cfg.RedirectURL, "https://") || c.Request.TLS != nil,
+		HttpOnly: true,
+	})
+}
+
+// Login redirects the user to the identity provider.
+func Login(logger *zap.Logger, cfg *oidc.Configuration, oidcService *service_oidc.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !oidcService.Enabled() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		var values [3]string
+		for i := range values {
+			value, err := oidc.RandomString()
+			if err != nil {
+				logger.Error("failed to generate random string", zap.Error(err))
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			values[i] = value
+		}
+		state, nonce, verifier := values[0], values[1], values[2]
+
+		url, err := oidcService.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+		if err != nil {
+			logger.Error("failed to get auth code url", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		setFlowCookie(c, cfg, strings.Join(values[:], "."), int(flowCookieAge/time.Second))
+		c.Redirect(http.StatusFound, url)
+	}
+}
+
+// Callback completes the login when the identity provider redirects the user back.
+func Callback(logger *zap.Logger, cfg *oidc.Configuration, oidcService *service_oidc.Service, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !oidcService.Enabled() {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if idpError := c.Query("error"); idpError != "" {
+			logger.Warn("identity provider returned an error", zap.String("error", idpError), zap.String("description", c.Query("error_description")))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed, please try again"})
+			return
+		}
+
+		flow, err := c.Cookie(flowCookieName)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Single sign-on session not found, please try again"})
+			return
+		}
+		// the flow can only be completed once
+		setFlowCookie(c, cfg, "", -1)
+
+		values := strings.Split(flow, ".")
+		if len(values) != 3 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(c.Query("state"))) != 1 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid single sign-on state, please try again"})
+			return
+		}
+		nonce, verifier := values[1], values[2]
+
+		user, err := oidcService.Login(c.Request.Context(), c.Query("code"), verifier, nonce)
+		switch {
+		case err == nil:
+		case errors.Is(err, oidc.ErrInvalidToken),
+			errors.Is(err, service_oidc.ErrMissingEmail),
+			errors.Is(err, service_oidc.ErrEmailNotVerified):
+			logger.Warn("failed to log in", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed, please try again"})
+			return
+		case errors.Is(err, service_users.ErrDeactivated):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated, please contact your administrator"})
+			return
+		case errors.Is(err, service_users.ErrExceeded):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "This service is exceeding the number of users allowed. Please contact the server administrator or email support@getsturdy.com"})
+			return
+		default:
+			logger.Error("failed to log in", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := auth.SetAuthCookieForUser(c, user.ID, jwtService, sessionsService); err != nil {
+			logger.Error("failed to set auth cookie", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		c.Redirect(http.StatusFound, cfg.AfterLoginURL)
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/oidc"
+	db_oidc "getsturdy.com/api/pkg/oidc/enterprise/selfhosted/db"
+	"getsturdy.com/api/pkg/organization"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/users"
+	service_users "getsturdy.com/api/pkg/users/enterprise/selfhosted/service"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrNotConfigured    = errors.New("single sign-on is not configured")
+	ErrMissingEmail     = errors.New("id token has no email claim")
+	ErrEmailNotVerified = errors.New("email is not verified by the identity provider")
+)
+
+type Service struct {
+	logger              *zap.Logger
+	cfg                 *oidc.Configuration
+	userService         *service_users.Service
+	organizationService *service_organization.Service
+	membershipRepo      db_oidc.MembershipRepository
+
+	providerGuard sync.Mutex
+	provider      *oidc.Provider
+}
+
+func New(
+	logger *zap.Logger,
+	cfg *oidc.Configuration,
+	userService *service_users.Service,
+	organizationService *service_organization.Service,
+	membershipRepo db_oidc.MembershipRepository,
+) *Service {
+	return &Service{
+		logger:              logger.Named("oidc"),
+		cfg:                 cfg,
+		userService:         userService,
+		organizationService: organizationService,
+		membershipRepo:      membershipRepo,
+	}
+}
+
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled()
+}
+
+// oauth2Config discovers the provider on first use, so that the installation can start even if the
+// identity provider is unavailable.
+func (s *Service) oauth2Config(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrNotConfigured
+	}
+
+	s.providerGuard.Lock()
+	defer s.providerGuard.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.Discover(ctx, http.DefaultClient, s.cfg.Issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to discover provider: %w", err)
+		}
+		s.provider = provider
+	}
+
+	return s.provider, s.provider.OAuth2Config(s.cfg.ClientID, s.cfg.ClientSecret, s.cfg.RedirectURL, s.cfg.Scopes), nil
+}
+
+// AuthCodeURL returns the URL of the identity provider to redirect the user to.
+func (s *Service) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	_, cfg, err := s.oauth2Config(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oidc.AuthCodeURL(cfg, state, nonce, verifier), nil
+}
+
+// Login exchanges the authorization code for an ID token, and returns the user that it belongs to. Users
+// are created on their first login.
+func (s *Service) Login(ctx context.Context, code, verifier, nonce string) (*users.User, error) {
+	provider, cfg, err := s.oauth2Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := oidc.Exchange(ctx, cfg, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.Verify(ctx, rawIDToken, s.cfg.ClientID, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, ErrMissingEmail
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	user, err := s.userService.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if err := s.userService.Activate(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to activate user: %w", err)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		name := claims.Name
+		if name == "" {
+			name = users.EmailToName(claims.Email)
+		}
+		user, err = s.userService.CreateWithoutPassword(ctx, name, claims.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+		s.logger.Info("provisioned user", zap.Stringer("user_id", user.ID))
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	switch {
+	case s.cfg.OrganizationsClaim == "":
+		// memberships are not managed by the identity provider
+	case !claims.Has(s.cfg.OrganizationsClaim):
+		// a missing claim is not the same as an empty one, the identity provider might not include it in every token
+		s.logger.Warn("id token has no organizations claim, not syncing organizations",
+			zap.Stringer("user_id", user.ID),
+			zap.String("claim", s.cfg.OrganizationsClaim),
+		)
+	default:
+		// the user is making the changes, so that they are attributed to them in the audit log
+		ctx := auth.NewContext(ctx, &auth.Subject{ID: user.ID.String(), Type: auth.SubjectUser})
+		if err := s.syncOrganizations(ctx, user.ID, claims.Strings(s.cfg.OrganizationsClaim)); err != nil {
+			return nil, fmt.Errorf("failed to sync organizations: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// syncOrganizations makes the user a member of the organizations with the given short ids, and removes the user
+// from the organizations that they were added to by single sign-on before, but that are not in the list anymore.
+// Memberships that were not created by single sign-on are never removed. Unknown organizations are ignored.
+func (s *Service) syncOrganizations(ctx context.Context, userID users.ID, shortIDs []string) error {
+	want := map[string]bool{}
+	for _, shortID := range shortIDs {
+		org, err := s.organizationService.GetByShortID(ctx, organization.ShortOrganizationID(shortID))
+		switch {
+		case err == nil:
+			want[org.ID] = true
+		case errors.Is(err, sql.ErrNoRows):
+			s.logger.Warn("organization from claim not found", zap.String("short_id", shortID))
+		default:
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+	}
+
+	current, err := s.organizationService.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+	isMember := make(map[string]bool, len(current))
+	for _, org := range current {
+		isMember[org.ID] = true
+	}
+
+	memberships, err := s.membershipRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	for _, membership := range memberships {
+		if want[membership.OrganizationID] {
+			continue
+		}
+		if isMember[membership.OrganizationID] {
+			if err := s.organizationService.RemoveMember(ctx, membership.OrganizationID, userID, userID); err != nil {
+				return fmt.Errorf("failed to remove member: %w", err)
+			}
+		}
+		if err := s.membershipRepo.Delete(ctx, membership.OrganizationID, userID); err != nil {
+			return fmt.Errorf("failed to delete membership: %w", err)
+		}
+	}
+
+	for orgID := range want {
+		if isMember[orgID] {
+			continue
+		}
+		if _, err := s.organizationService.AddMember(ctx, orgID, userID, userID); err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		if err := s.membershipRepo.Create(ctx, &oidc.Membership{
+			OrganizationID: orgID,
+			UserID:         userID,
+			CreatedAt:      time.Now(),
+		}); err != nil {
+			return fmt.Errorf("failed to save membership: %w", err)
+		}
+	}
+
+	return nil
+}
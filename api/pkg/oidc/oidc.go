@@ -0,0 +1,54 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+type Configuration struct {
+	Issuer             string   `long:"issuer" description:"OpenID Connect issuer URL, single sign-on is disabled if not set" env:"ISSUER"`
+	ClientID           string   `long:"client-id" description:"OpenID Connect client ID" env:"CLIENT_ID"`
+	ClientSecret       string   `long:"client-secret" description:"OpenID Connect client secret" env:"CLIENT_SECRET"`
+	RedirectURL        string   `long:"redirect-url" description:"URL of the callback endpoint as registered with the identity provider, for example https://sturdy.example.com/api/v3/auth/oidc/callback" env:"REDIRECT_URL"`
+	Scopes             []string `long:"scope" description:"Scopes to request (can be provided multiple times)" default:"openid" default:"email" default:"profile"`
+	OrganizationsClaim string   `long:"organizations-claim" description:"ID token claim with the short IDs of the organizations the user should be a member of, memberships are not managed if not set" env:"ORGANIZATIONS_CLAIM"`
+	AfterLoginURL      string   `long:"after-login-url" description:"URL to redirect to after a successful login" default:"/" env:"AFTER_LOGIN_URL"`
+
+	DisablePasswordLogin bool `long:"disable-password-login" description:"Only allow users to sign up and log in with single sign-on" env:"DISABLE_PASSWORD_LOGIN"`
+}
+
+func (c *Configuration) Enabled() bool {
+	return c != nil && c.Issuer != ""
+}
+
+func (c *Configuration) PasswordLoginEnabled() bool {
+	return c == nil || !c.DisablePasswordLogin
+}
+
+// Membership is an organization membership that was created by single sign-on. Memberships that were created
+// in other ways, like by invites, are not removed when the organizations claim changes.
+type Membership struct {
+	OrganizationID string    `db:"organization_id"`
+	UserID         users.ID  `db:"user_id"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// RandomString returns a random url-safe string, to be used as a state, nonce or PKCE code verifier.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of the verifier.
+func CodeChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
@@ -0,0 +1,245 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gopkg.in/square/go-jose.v2"
+	jose_jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid id token")
+)
+
+// keysRefreshInterval limits how often keys are fetched when a token is signed with an unknown key.
+const keysRefreshInterval = time.Minute
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is an OpenID Connect identity provider.
+type Provider struct {
+	client   *http.Client
+	metadata metadata
+
+	keysGuard     sync.Mutex
+	keys          jose.JSONWebKeySet
+	keysFetchedAt time.Time
+}
+
+// Discover fetches the configuration of the provider from the issuer's discovery document.
+func Discover(ctx context.Context, client *http.Client, issuer string) (*Provider, error) {
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	p := &Provider{client: client}
+	if err := p.getJSON(ctx, wellKnown, &p.metadata); err != nil {
+		return nil, fmt.Errorf("failed to get discovery document: %w", err)
+	}
+
+	if p.metadata.Issuer != issuer {
+		return nil, fmt.Errorf("issuer mismatch: expected %q, discovered %q", issuer, p.metadata.Issuer)
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", res.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	var keys jose.JSONWebKeySet
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &keys); err != nil {
+		return fmt.Errorf("failed to get keys: %w", err)
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return nil
+}
+
+// key returns the key with the given id. Keys are refetched if the key is unknown, to support key rotation.
+func (p *Provider) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	p.keysGuard.Lock()
+	defer p.keysGuard.Unlock()
+
+	if keys := p.keys.Key(kid); len(keys) > 0 {
+		return &keys[0], nil
+	}
+
+	if time.Since(p.keysFetchedAt) < keysRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	if keys := p.keys.Key(kid); len(keys) > 0 {
+		return &keys[0], nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+// OAuth2Config returns the authorization code flow configuration for a client of the provider.
+func (p *Provider) OAuth2Config(clientID, clientSecret, redirectURL string, scopes []string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   p.metadata.AuthorizationEndpoint,
+			TokenURL:  p.metadata.TokenEndpoint,
+			AuthStyle: oauth2.AuthStyleAutoDetect,
+		},
+	}
+}
+
+// AuthCodeURL returns the URL to redirect the user to, to start the authorization code flow with PKCE.
+func AuthCodeURL(cfg *oauth2.Config, state, nonce, verifier string) string {
+	return cfg.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", CodeChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+}
+
+// Exchange exchanges the authorization code for tokens, and returns the raw ID token.
+func Exchange(ctx context.Context, cfg *oauth2.Config, code, verifier string) (string, error) {
+	token, err := cfg.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", ErrInvalidToken)
+	}
+
+	return rawIDToken, nil
+}
+
+// Claims are the claims of a verified ID token.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified *bool
+	Name          string
+
+	all map[string]any
+}
+
+// Strings returns the value of a claim that is a string or a list of strings.
+// Has returns true if the token has the claim.
+func (c *Claims) Has(name string) bool {
+	_, ok := c.all[name]
+	return ok
+}
+
+func (c *Claims) Strings(name string) []string {
+	switch v := c.all[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// Verify checks the signature, issuer, audience, expiry and nonce of the ID token.
+func (p *Provider) Verify(ctx context.Context, rawIDToken, clientID, nonce string) (*Claims, error) {
+	token, err := jose_jwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, fmt.Errorf("%w: expected exactly one signature", ErrInvalidToken)
+	}
+
+	key, err := p.key(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Algorithm != "" && key.Algorithm != token.Headers[0].Algorithm {
+		return nil, fmt.Errorf("%w: unexpected signing algorithm %q", ErrInvalidToken, token.Headers[0].Algorithm)
+	}
+
+	standard := jose_jwt.Claims{}
+	all := map[string]any{}
+	if err := token.Claims(key, &standard, &all); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	if err := standard.ValidateWithLeeway(jose_jwt.Expected{
+		Issuer:   p.metadata.Issuer,
+		Audience: jose_jwt.Audience{clientID},
+		Time:     time.Now(),
+	}, jose_jwt.DefaultLeeway); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+
+	if standard.Expiry == nil {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+
+	if tokenNonce, _ := all["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	claims := &Claims{
+		Subject: standard.Subject,
+		all:     all,
+	}
+	claims.Email, _ = all["email"].(string)
+	claims.Name, _ = all["name"].(string)
+	if verified, ok := all["email_verified"].(bool); ok {
+		claims.EmailVerified = &verified
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,115 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/oidc"
+	"getsturdy.com/api/pkg/oidc/oidctest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost/v3/auth/oidc/callback"
+
+// authorize follows the authorization code flow up until the redirect back to the client, and returns
+// the code.
+func authorize(t *testing.T, authCodeURL, state string) string {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(authCodeURL)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, state, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := oidctest.NewServer("sturdy", "secret")
+	assert.NoError(t, err)
+	defer server.Close()
+
+	server.Claims["email"] = "user@example.com"
+	server.Claims["email_verified"] = true
+	server.Claims["groups"] = []string{"org-1", "org-2"}
+
+	provider, err := oidc.Discover(ctx, http.DefaultClient, server.Issuer())
+	assert.NoError(t, err)
+
+	cfg := provider.OAuth2Config("sturdy", "secret", redirectURL, []string{"openid", "email"})
+
+	t.Run("valid", func(t *testing.T) {
+		code := authorize(t, oidc.AuthCodeURL(cfg, "state", "nonce", "verifier"), "state")
+
+		rawIDToken, err := oidc.Exchange(ctx, cfg, code, "verifier")
+		assert.NoError(t, err)
+
+		claims, err := provider.Verify(ctx, rawIDToken, "sturdy", "nonce")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "oidctest-user", claims.Subject)
+			assert.Equal(t, "user@example.com", claims.Email)
+			if assert.NotNil(t, claims.EmailVerified) {
+				assert.True(t, *claims.EmailVerified)
+			}
+			assert.Equal(t, []string{"org-1", "org-2"}, claims.Strings("groups"))
+			assert.Empty(t, claims.Strings("missing"))
+			assert.True(t, claims.Has("groups"))
+			assert.False(t, claims.Has("missing"))
+		}
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		code := authorize(t, oidc.AuthCodeURL(cfg, "state", "nonce", "verifier"), "state")
+
+		_, err := oidc.Exchange(ctx, cfg, code, "other verifier")
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		code := authorize(t, oidc.AuthCodeURL(cfg, "state", "nonce", "verifier"), "state")
+
+		rawIDToken, err := oidc.Exchange(ctx, cfg, code, "verifier")
+		assert.NoError(t, err)
+
+		_, err = provider.Verify(ctx, rawIDToken, "sturdy", "other nonce")
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		rawIDToken, err := server.IssueIDToken("other client", "nonce", time.Hour)
+		assert.NoError(t, err)
+
+		_, err = provider.Verify(ctx, rawIDToken, "sturdy", "nonce")
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		rawIDToken, err := server.IssueIDToken("sturdy", "nonce", -time.Hour)
+		assert.NoError(t, err)
+
+		_, err = provider.Verify(ctx, rawIDToken, "sturdy", "nonce")
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		rawIDToken, err := server.IssueIDToken("sturdy", "nonce", time.Hour)
+		assert.NoError(t, err)
+
+		_, err = provider.Verify(ctx, rawIDToken+"x", "sturdy", "nonce")
+		assert.ErrorIs(t, err, oidc.ErrInvalidToken)
+	})
+}
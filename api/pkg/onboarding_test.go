@@ -510,7 +510,7 @@ func TestCreate(t *testing.T) {
 
 	{
 		// Trigger GC
-		err := gcService.WorkWithOptions(context.Background(), logger, codebaseRes.ID, 0, 0)
+		_, err := gcService.WorkWithOptions(context.Background(), logger, codebaseRes.ID, service_gc.Options{})
 		assert.NoError(t, err)
 
 		// make another change (after gc)
@@ -16,6 +16,7 @@ import (
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	"getsturdy.com/api/pkg/organization"
 	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
 	"getsturdy.com/api/pkg/users"
 	service_user "getsturdy.com/api/pkg/users/service"
 
@@ -25,14 +26,19 @@ import (
 )
 
 type organizationRootResolver struct {
-	service         *service_organization.Service
-	authService     *service_auth.Service
-	userService     service_user.Service
-	codebaseService *service_codebase.Service
+	service          *service_organization.Service
+	authService      *service_auth.Service
+	userService      service_user.Service
+	codebaseService  *service_codebase.Service
+	twoFactorService *service_twofactor.Service
 
 	authorRootResolver    resolvers.AuthorRootResolver
 	licensesRootResolver  resolvers.LicenseRootResolver
 	codebasesRootResolver resolvers.CodebaseRootResolver
+	teamsRootResolver     resolvers.TeamRootResolver
+	auditLogRootResolver  resolvers.AuditLogRootResolver
+	scimRootResolver      resolvers.ScimRootResolver
+	webhooksRootResolver  resolvers.WebhooksRootResolver
 
 	eventsSubscriber *eventsv2.Subscriber
 	logger           *zap.Logger
@@ -43,24 +49,34 @@ func New(
 	authService *service_auth.Service,
 	userService service_user.Service,
 	codebaseService *service_codebase.Service,
+	twoFactorService *service_twofactor.Service,
 
 	authorRootResolver resolvers.AuthorRootResolver,
 	licensesRootResolver resolvers.LicenseRootResolver,
 	codebasesRootResolver resolvers.CodebaseRootResolver,
+	teamsRootResolver resolvers.TeamRootResolver,
+	auditLogRootResolver resolvers.AuditLogRootResolver,
+	scimRootResolver resolvers.ScimRootResolver,
+	webhooksRootResolver resolvers.WebhooksRootResolver,
 
 	eventsSubscriber *eventsv2.Subscriber,
 	logger *zap.Logger,
 
 ) resolvers.OrganizationRootResolver {
 	return &organizationRootResolver{
-		service:         service,
-		authService:     authService,
-		userService:     userService,
-		codebaseService: codebaseService,
+		service:          service,
+		authService:      authService,
+		userService:      userService,
+		codebaseService:  codebaseService,
+		twoFactorService: twoFactorService,
 
 		authorRootResolver:    authorRootResolver,
 		licensesRootResolver:  licensesRootResolver,
 		codebasesRootResolver: codebasesRootResolver,
+		teamsRootResolver:     teamsRootResolver,
+		auditLogRootResolver:  auditLogRootResolver,
+		scimRootResolver:      scimRootResolver,
+		webhooksRootResolver:  webhooksRootResolver,
 
 		eventsSubscriber: eventsSubscriber,
 		logger:           logger.Named("OrganizationRootResolver"),
@@ -183,9 +199,45 @@ func (r *organizationRootResolver) UpdateOrganization(ctx context.Context, args
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
+
+	if args.Input.RequireTwoFactor != nil && *args.Input.RequireTwoFactor != org.RequireTwoFactor {
+		if *args.Input.RequireTwoFactor {
+			if err := r.canRequireTwoFactor(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if org, err = r.service.SetRequireTwoFactor(ctx, org.ID, *args.Input.RequireTwoFactor); err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+	}
+
 	return &organizationResolver{root: r, org: org}, nil
 }
 
+// canRequireTwoFactor makes sure that users that log in with a password have enabled two-factor
+// authentication themselves before requiring it, so that they don't lock themselves out of the organization.
+func (r *organizationRootResolver) canRequireTwoFactor(ctx context.Context) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return gqlerrors.Error(err)
+	}
+	user, err := r.userService.GetByID(ctx, userID)
+	if err != nil {
+		return gqlerrors.Error(err)
+	}
+	if user.PasswordHash == "" {
+		return nil
+	}
+	enabled, err := r.twoFactorService.Enabled(ctx, userID)
+	if err != nil {
+		return gqlerrors.Error(err)
+	}
+	if !enabled {
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "requireTwoFactor", "enable two-factor authentication for your own account first")
+	}
+	return nil
+}
+
 func (r *organizationRootResolver) AddUserToOrganization(ctx context.Context, args resolvers.AddUserToOrganizationArgs) (resolvers.OrganizationResolver, error) {
 	org, err := r.service.GetByID(ctx, string(args.Input.OrganizationID))
 	if err != nil {
@@ -359,6 +411,26 @@ func (r *organizationResolver) Codebases(ctx context.Context) ([]resolvers.Codeb
 	return res, nil
 }
 
+func (r *organizationResolver) Teams(ctx context.Context) ([]resolvers.TeamResolver, error) {
+	return r.root.teamsRootResolver.InternalListByOrganizationID(ctx, r.org.ID)
+}
+
+func (r *organizationResolver) AuditLog(ctx context.Context, args resolvers.OrganizationAuditLogArgs) ([]resolvers.AuditLogEntryResolver, error) {
+	return r.root.auditLogRootResolver.InternalListByOrganizationID(ctx, r.org.ID, args)
+}
+
+func (r *organizationResolver) ScimTokens(ctx context.Context) ([]resolvers.ScimTokenResolver, error) {
+	return r.root.scimRootResolver.InternalListByOrganizationID(ctx, r.org.ID)
+}
+
+func (r *organizationResolver) Webhooks(ctx context.Context) ([]resolvers.WebhookResolver, error) {
+	return r.root.webhooksRootResolver.InternalListByOrganizationID(ctx, r.org.ID)
+}
+
+func (r *organizationResolver) RequireTwoFactor() bool {
+	return r.org.RequireTwoFactor
+}
+
 func (r *organizationResolver) Licenses(ctx context.Context) ([]resolvers.LicenseResolver, error) {
 	return r.root.licensesRootResolver.InternalListForOrganizationID(ctx, r.org.ID)
 }
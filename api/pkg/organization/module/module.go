@@ -5,10 +5,12 @@ import (
 	"getsturdy.com/api/pkg/organization/db"
 	"getsturdy.com/api/pkg/organization/graphql"
 	"getsturdy.com/api/pkg/organization/service"
+	module_teams "getsturdy.com/api/pkg/organization/teams/module"
 )
 
 func Module(c *di.Container) {
 	c.Import(db.Module)
 	c.Import(graphql.Module)
 	c.Import(service.Module)
+	c.Import(module_teams.Module)
 }
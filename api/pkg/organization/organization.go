@@ -16,6 +16,8 @@ type Organization struct {
 	CreatedBy users.ID            `db:"created_by"`
 	DeletedAt *time.Time          `db:"deleted_at"`
 	DeletedBy *string             `db:"deleted_by"`
+	// RequireTwoFactor makes two-factor authentication mandatory for members that log in with a password.
+	RequireTwoFactor bool `db:"require_two_factor"`
 }
 
 type Member struct {
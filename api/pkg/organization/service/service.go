@@ -9,6 +9,8 @@ import (
 
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/events/v2"
 	"getsturdy.com/api/pkg/organization"
 	db_organization "getsturdy.com/api/pkg/organization/db"
@@ -23,6 +25,7 @@ type Service struct {
 	organizationRepository       db_organization.Repository
 	organizationMemberRepository db_organization.MemberRepository
 	analyticsService             *service_analytics.Service
+	auditService                 *service_audit.Service
 }
 
 func New(
@@ -30,12 +33,14 @@ func New(
 	organizationRepository db_organization.Repository,
 	organizationMemberRepository db_organization.MemberRepository,
 	analyticsService *service_analytics.Service,
+	auditService *service_audit.Service,
 ) *Service {
 	return &Service{
 		eventsSender:                 eventsSender,
 		organizationRepository:       organizationRepository,
 		organizationMemberRepository: organizationMemberRepository,
 		analyticsService:             analyticsService,
+		auditService:                 auditService,
 	}
 }
 
@@ -99,6 +104,13 @@ func (svc *Service) Create(ctx context.Context, userID users.ID, name string) (*
 		return nil, fmt.Errorf("failed to create organization: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionOrganizationCreated, audit.TargetOrganization, org.ID,
+		audit.OrganizationID(org.ID),
+		audit.After(org),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	// add the creator as a member
 	if _, err := svc.AddMember(ctx, org.ID, userID, userID); err != nil {
 		return nil, fmt.Errorf("failed to invite creator to organization: %w", err)
@@ -117,12 +129,55 @@ func (svc *Service) Update(ctx context.Context, organizationID string, newName s
 		return nil, fmt.Errorf("could not get organization by this ID: %w", err)
 	}
 
+	before := *org
 	org.Name = newName
 
 	if err := svc.organizationRepository.Update(ctx, org); err != nil {
 		return nil, fmt.Errorf("could not update organization: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionOrganizationUpdated, audit.TargetOrganization, org.ID,
+		audit.OrganizationID(org.ID),
+		audit.Before(before),
+		audit.After(org),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	if err := svc.eventsSender.OrganizationUpdated(ctx, events.Organization(organizationID), org); err != nil {
+		return nil, fmt.Errorf("failed to send event: %w", err)
+	}
+
+	return org, nil
+}
+
+// SetRequireTwoFactor makes two-factor authentication mandatory, or optional, for members of the organization
+// that log in with a password.
+func (svc *Service) SetRequireTwoFactor(ctx context.Context, organizationID string, required bool) (*organization.Organization, error) {
+	org, err := svc.organizationRepository.Get(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get organization by this ID: %w", err)
+	}
+
+	if org.RequireTwoFactor == required {
+		return org, nil
+	}
+
+	before := *org
+	org.RequireTwoFactor = required
+
+	if err := svc.organizationRepository.Update(ctx, org); err != nil {
+		return nil, fmt.Errorf("could not update organization: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionOrganizationUpdated, audit.TargetOrganization, org.ID,
+		audit.OrganizationID(org.ID),
+		audit.Before(before),
+		audit.After(org),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	if err := svc.eventsSender.OrganizationUpdated(ctx, events.Organization(organizationID), org); err != nil {
 		return nil, fmt.Errorf("failed to send event: %w", err)
 	}
@@ -143,6 +198,13 @@ func (svc *Service) AddMember(ctx context.Context, orgID string, userID, addedBy
 		return nil, fmt.Errorf("failed to create member: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionOrganizationMemberAdded, audit.TargetUser, userID.String(),
+		audit.OrganizationID(orgID),
+		audit.After(member),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	svc.analyticsService.Capture(ctx, "add member to organization",
 		analytics.OrganizationID(orgID),
 		analytics.Property("user_id", userID),
@@ -157,6 +219,7 @@ func (svc *Service) RemoveMember(ctx context.Context, orgID string, userID users
 		return fmt.Errorf("could not get member: %w", err)
 	}
 
+	before := *member
 	t := time.Now()
 	member.DeletedAt = &t
 	member.DeletedBy = &deletedByUserID
@@ -165,6 +228,14 @@ func (svc *Service) RemoveMember(ctx context.Context, orgID string, userID users
 		return fmt.Errorf("could not update member: %w", err)
 	}
 
+	if err := svc.auditService.Record(ctx, audit.ActionOrganizationMemberRemoved, audit.TargetUser, userID.String(),
+		audit.OrganizationID(orgID),
+		audit.Before(before),
+		audit.After(member),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	svc.analyticsService.Capture(ctx, "remove member from organization",
 		analytics.OrganizationID(orgID),
 		analytics.Property("user_id", userID),
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/organization/teams"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) Repository {
+	return &database{db: db}
+}
+
+func (d *database) Get(ctx context.Context, id teams.ID) (*teams.Team, error) {
+	var team teams.Team
+	if err := d.db.GetContext(ctx, &team, `SELECT id, organization_id, parent_id, name, created_at, created_by, deleted_at, deleted_by
+		FROM teams
+		WHERE id = $1
+		  AND deleted_at IS NULL`, id); err != nil {
+		return nil, fmt.Errorf("could not get team: %w", err)
+	}
+	return &team, nil
+}
+
+func (d *database) ListByOrganizationID(ctx context.Context, organizationID string) ([]*teams.Team, error) {
+	var res []*teams.Team
+	if err := d.db.SelectContext(ctx, &res, `SELECT id, organization_id, parent_id, name, created_at, created_by, deleted_at, deleted_by
+		FROM teams
+		WHERE organization_id = $1
+		  AND deleted_at IS NULL
+		ORDER BY name`, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to list teams by organization_id: %w", err)
+	}
+	return res, nil
+}
+
+func (d *database) Create(ctx context.Context, team *teams.Team) error {
+	if _, err := d.db.NamedExecContext(ctx, `INSERT INTO teams (id, organization_id, parent_id, name, created_at, created_by, deleted_at, deleted_by)
+		VALUES (:id, :organization_id, :parent_id, :name, :created_at, :created_by, :deleted_at, :deleted_by)`, team); err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Update(ctx context.Context, team *teams.Team) error {
+	if _, err := d.db.NamedExecContext(ctx, `UPDATE teams
+		SET name = :name,
+		    parent_id = :parent_id,
+		    deleted_at = :deleted_at,
+		    deleted_by = :deleted_by
+		WHERE id = :id`, team); err != nil {
+		return fmt.Errorf("failed to update team: %w", err)
+	}
+	return nil
+}
+
+var _ MemberRepository = &memberDatabase{}
+
+type memberDatabase struct {
+	db *sqlx.DB
+}
+
+func NewMember(db *sqlx.DB) MemberRepository {
+	return &memberDatabase{db: db}
+}
+
+func (d *memberDatabase) GetByTeamIDAndUserID(ctx context.Context, teamID teams.ID, userID users.ID) (*teams.Member, error) {
+	var member teams.Member
+	if err := d.db.GetContext(ctx, &member, `SELECT id, team_id, user_id, role, created_at, created_by, deleted_at, deleted_by
+		FROM team_members
+		WHERE team_id = $1
+		  AND user_id = $2
+		  AND deleted_at IS NULL`, teamID, userID); err != nil {
+		return nil, fmt.Errorf("could not get team member: %w", err)
+	}
+	return &member, nil
+}
+
+func (d *memberDatabase) ListByTeamID(ctx context.Context, teamID teams.ID) ([]*teams.Member, error) {
+	var res []*teams.Member
+	if err := d.db.SelectContext(ctx, &res, `SELECT id, team_id, user_id, role, created_at, created_by, deleted_at, deleted_by
+		FROM team_members
+		WHERE team_id = $1
+		  AND deleted_at IS NULL`, teamID); err != nil {
+		return nil, fmt.Errorf("failed to list team members by team_id: %w", err)
+	}
+	return res, nil
+}
+
+func (d *memberDatabase) ListByUserID(ctx context.Context, userID users.ID) ([]*teams.Member, error) {
+	var res []*teams.Member
+	if err := d.db.SelectContext(ctx, &res, `SELECT id, team_id, user_id, role, created_at, created_by, deleted_at, deleted_by
+		FROM team_members
+		WHERE user_id = $1
+		  AND deleted_at IS NULL`, userID); err != nil {
+		return nil, fmt.Errorf("failed to list team members by user_id: %w", err)
+	}
+	return res, nil
+}
+
+func (d *memberDatabase) Create(ctx context.Context, member *teams.Member) error {
+	if _, err := d.db.NamedExecContext(ctx, `INSERT INTO team_members (id, team_id, user_id, role, created_at, created_by, deleted_at, deleted_by)
+		VALUES (:id, :team_id, :user_id, :role, :created_at, :created_by, :deleted_at, :deleted_by)
+		ON CONFLICT (team_id, user_id) DO UPDATE
+		SET role = :role,
+		    deleted_at = NULL,
+		    deleted_by = NULL`, member); err != nil {
+		return fmt.Errorf("failed to create team member: %w", err)
+	}
+	return nil
+}
+
+func (d *memberDatabase) Update(ctx context.Context, member *teams.Member) error {
+	if _, err := d.db.NamedExecContext(ctx, `UPDATE team_members
+		SET role = :role,
+		    deleted_at = :deleted_at,
+		    deleted_by = :deleted_by
+		WHERE id = :id`, member); err != nil {
+		return fmt.Errorf("failed to update team member: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"getsturdy.com/api/pkg/organization/teams"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	byID map[teams.ID]*teams.Team
+}
+
+func NewMemory() Repository {
+	return &memory{
+		byID: map[teams.ID]*teams.Team{},
+	}
+}
+
+func (m *memory) Get(_ context.Context, id teams.ID) (*teams.Team, error) {
+	team, found := m.byID[id]
+	if !found || team.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	return team, nil
+}
+
+func (m *memory) ListByOrganizationID(_ context.Context, organizationID string) ([]*teams.Team, error) {
+	var res []*teams.Team
+	for _, team := range m.byID {
+		if team.OrganizationID == organizationID && team.DeletedAt == nil {
+			res = append(res, team)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+func (m *memory) Create(_ context.Context, team *teams.Team) error {
+	m.byID[team.ID] = team
+	return nil
+}
+
+func (m *memory) Update(_ context.Context, team *teams.Team) error {
+	m.byID[team.ID] = team
+	return nil
+}
+
+var _ MemberRepository = &memberMemory{}
+
+type memberMemory struct {
+	members []*teams.Member
+}
+
+func NewMemberMemory() MemberRepository {
+	return &memberMemory{}
+}
+
+func (m *memberMemory) GetByTeamIDAndUserID(_ context.Context, teamID teams.ID, userID users.ID) (*teams.Member, error) {
+	for _, member := range m.members {
+		if member.TeamID == teamID && member.UserID == userID && member.DeletedAt == nil {
+			return member, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *memberMemory) ListByTeamID(_ context.Context, teamID teams.ID) ([]*teams.Member, error) {
+	var res []*teams.Member
+	for _, member := range m.members {
+		if member.TeamID == teamID && member.DeletedAt == nil {
+			res = append(res, member)
+		}
+	}
+	return res, nil
+}
+
+func (m *memberMemory) ListByUserID(_ context.Context, userID users.ID) ([]*teams.Member, error) {
+	var res []*teams.Member
+	for _, member := range m.members {
+		if member.UserID == userID && member.DeletedAt == nil {
+			res = append(res, member)
+		}
+	}
+	return res, nil
+}
+
+func (m *memberMemory) Create(_ context.Context, member *teams.Member) error {
+	for i, existing := range m.members {
+		if existing.TeamID == member.TeamID && existing.UserID == member.UserID {
+			m.members[i] = member
+			return nil
+		}
+	}
+	m.members = append(m.members, member)
+	return nil
+}
+
+func (m *memberMemory) Update(_ context.Context, member *teams.Member) error {
+	for i, existing := range m.members {
+		if existing.ID == member.ID {
+			m.members[i] = member
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/organization/teams"
+	"getsturdy.com/api/pkg/users"
+)
+
+type Repository interface {
+	Get(context.Context, teams.ID) (*teams.Team, error)
+	ListByOrganizationID(ctx context.Context, organizationID string) ([]*teams.Team, error)
+	Create(context.Context, *teams.Team) error
+	Update(context.Context, *teams.Team) error
+}
+
+type MemberRepository interface {
+	GetByTeamIDAndUserID(context.Context, teams.ID, users.ID) (*teams.Member, error)
+	ListByTeamID(context.Context, teams.ID) ([]*teams.Member, error)
+	ListByUserID(context.Context, users.ID) ([]*teams.Member, error)
+	Create(context.Context, *teams.Member) error
+	Update(context.Context, *teams.Member) error
+}
@@ -0,0 +1,327 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/organization/teams"
+	service_teams "getsturdy.com/api/pkg/organization/teams/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type teamRootResolver struct {
+	service             *service_teams.Service
+	organizationService *service_organization.Service
+	authService         *service_auth.Service
+
+	authorRootResolver resolvers.AuthorRootResolver
+}
+
+func New(
+	service *service_teams.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+
+	authorRootResolver resolvers.AuthorRootResolver,
+) resolvers.TeamRootResolver {
+	return &teamRootResolver{
+		service:             service,
+		organizationService: organizationService,
+		authService:         authService,
+
+		authorRootResolver: authorRootResolver,
+	}
+}
+
+func (r *teamRootResolver) InternalListByOrganizationID(ctx context.Context, organizationID string) ([]resolvers.TeamResolver, error) {
+	tt, err := r.service.ListByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.TeamResolver, 0, len(tt))
+	for _, team := range tt {
+		res = append(res, &teamResolver{root: r, team: team})
+	}
+	return res, nil
+}
+
+func (r *teamRootResolver) Team(ctx context.Context, args resolvers.TeamArgs) (resolvers.TeamResolver, error) {
+	team, err := r.service.Get(ctx, teams.ID(args.ID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.canRead(ctx, team); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &teamResolver{root: r, team: team}, nil
+}
+
+func (r *teamRootResolver) CreateTeam(ctx context.Context, args resolvers.CreateTeamArgs) (resolvers.TeamResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	org, err := r.organizationService.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	var parentID *teams.ID
+	if args.Input.ParentID != nil {
+		// child teams can only be created by the maintainers of the parent
+		id := teams.ID(*args.Input.ParentID)
+		if err := r.canMaintain(ctx, id); err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		parentID = &id
+	} else if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	team, err := r.service.Create(ctx, org.ID, parentID, args.Input.Name, userID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &teamResolver{root: r, team: team}, nil
+}
+
+func (r *teamRootResolver) UpdateTeam(ctx context.Context, args resolvers.UpdateTeamArgs) (resolvers.TeamResolver, error) {
+	if err := r.canMaintain(ctx, teams.ID(args.Input.ID)); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	team, err := r.service.Rename(ctx, teams.ID(args.Input.ID), args.Input.Name)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &teamResolver{root: r, team: team}, nil
+}
+
+func (r *teamRootResolver) MoveTeam(ctx context.Context, args resolvers.MoveTeamArgs) (resolvers.TeamResolver, error) {
+	team, err := r.service.Get(ctx, teams.ID(args.Input.ID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.canMaintain(ctx, team.ID); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	var parentID *teams.ID
+	if args.Input.ParentID != nil {
+		id := teams.ID(*args.Input.ParentID)
+		if err := r.canMaintain(ctx, id); err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		parentID = &id
+	} else {
+		org, err := r.organizationService.GetByID(ctx, team.OrganizationID)
+		if err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		if err := r.authService.CanWrite(ctx, org); err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+	}
+
+	team, err = r.service.SetParent(ctx, team.ID, parentID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &teamResolver{root: r, team: team}, nil
+}
+
+func (r *teamRootResolver) DeleteTeam(ctx context.Context, args resolvers.DeleteTeamArgs) (resolvers.TeamResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	team, err := r.service.Get(ctx, teams.ID(args.ID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.canMaintain(ctx, team.ID); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.service.Delete(ctx, team.ID, userID); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &teamResolver{root: r, team: team}, nil
+}
+
+func (r *teamRootResolver) AddUserToTeam(ctx context.Context, args resolvers.AddUserToTeamArgs) (resolvers.TeamResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if err := r.canMaintain(ctx, teams.ID(args.Input.TeamID)); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	role := teams.RoleMember
+	if args.Input.Role != nil {
+		role = teams.Role(*args.Input.Role)
+	}
+
+	if _, err := r.service.AddMember(ctx, teams.ID(args.Input.TeamID), users.ID(args.Input.UserID), role, userID); err != nil {
+		return nil, serviceError(err)
+	}
+
+	return r.Team(ctx, resolvers.TeamArgs{ID: args.Input.TeamID})
+}
+
+func (r *teamRootResolver) RemoveUserFromTeam(ctx context.Context, args resolvers.RemoveUserFromTeamArgs) (resolvers.TeamResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	// members can always leave the team
+	if userID != users.ID(args.Input.UserID) {
+		if err := r.canMaintain(ctx, teams.ID(args.Input.TeamID)); err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+	}
+
+	if err := r.service.RemoveMember(ctx, teams.ID(args.Input.TeamID), users.ID(args.Input.UserID), userID); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return r.Team(ctx, resolvers.TeamArgs{ID: args.Input.TeamID})
+}
+
+func (r *teamRootResolver) canRead(ctx context.Context, team *teams.Team) error {
+	org, err := r.organizationService.GetByID(ctx, team.OrganizationID)
+	if err != nil {
+		return err
+	}
+	return r.authService.CanRead(ctx, org)
+}
+
+// canMaintain returns nil if the authenticated user is a maintainer of the team, or of any of it's parents.
+func (r *teamRootResolver) canMaintain(ctx context.Context, teamID teams.ID) error {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.service.CanMaintain(ctx, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("user is not a maintainer of the team: %w", auth.ErrForbidden)
+	}
+	return nil
+}
+
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, service_teams.ErrEmptyName):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "name", "can't be empty")
+	case errors.Is(err, service_teams.ErrInvalidRole):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "role", "invalid role")
+	case errors.Is(err, service_teams.ErrCycle):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "parentID", "team can't be a child of itself")
+	case errors.Is(err, service_teams.ErrDifferentOrganization):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "parentID", "parent team belongs to another organization")
+	case errors.Is(err, service_teams.ErrNotOrganizationMember):
+		return gqlerrors.Error(gqlerrors.ErrBadRequest, "userID", "user is not a member of the organization")
+	default:
+		return gqlerrors.Error(err)
+	}
+}
+
+type teamResolver struct {
+	root *teamRootResolver
+	team *teams.Team
+}
+
+func (r *teamResolver) ID() graphql.ID {
+	return graphql.ID(r.team.ID)
+}
+
+func (r *teamResolver) Name() string {
+	return r.team.Name
+}
+
+func (r *teamResolver) Parent(ctx context.Context) (resolvers.TeamResolver, error) {
+	if r.team.ParentID == nil {
+		return nil, nil
+	}
+	parent, err := r.root.service.Get(ctx, *r.team.ParentID)
+	switch {
+	case err == nil:
+		return &teamResolver{root: r.root, team: parent}, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+}
+
+func (r *teamResolver) Children(ctx context.Context) ([]resolvers.TeamResolver, error) {
+	tt, err := r.root.service.ListByOrganizationID(ctx, r.team.OrganizationID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := []resolvers.TeamResolver{}
+	for _, team := range tt {
+		if team.ParentID != nil && *team.ParentID == r.team.ID {
+			res = append(res, &teamResolver{root: r.root, team: team})
+		}
+	}
+	return res, nil
+}
+
+func (r *teamResolver) Members(ctx context.Context) ([]resolvers.TeamMemberResolver, error) {
+	members, err := r.root.service.Members(ctx, r.team.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	res := make([]resolvers.TeamMemberResolver, 0, len(members))
+	for _, member := range members {
+		res = append(res, &teamMemberResolver{root: r.root, member: member})
+	}
+	return res, nil
+}
+
+func (r *teamResolver) Writeable(ctx context.Context) bool {
+	return r.root.canMaintain(ctx, r.team.ID) == nil
+}
+
+type teamMemberResolver struct {
+	root   *teamRootResolver
+	member *teams.Member
+}
+
+func (r *teamMemberResolver) Author(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(r.member.UserID))
+}
+
+func (r *teamMemberResolver) Role() string {
+	return string(r.member.Role)
+}
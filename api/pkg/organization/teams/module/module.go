@@ -0,0 +1,14 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/organization/teams/db"
+	"getsturdy.com/api/pkg/organization/teams/graphql"
+	"getsturdy.com/api/pkg/organization/teams/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Import(graphql.Module)
+	c.Import(service.Module)
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db_organization "getsturdy.com/api/pkg/organization/db"
+	"getsturdy.com/api/pkg/organization/teams"
+	db_teams "getsturdy.com/api/pkg/organization/teams/db"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyName             = errors.New("team name can't be empty")
+	ErrInvalidRole           = errors.New("invalid role")
+	ErrCycle                 = errors.New("team can't be a child of itself")
+	ErrDifferentOrganization = errors.New("teams belong to different organizations")
+	ErrNotOrganizationMember = errors.New("user is not a member of the organization")
+)
+
+type Service struct {
+	repo                         db_teams.Repository
+	memberRepo                   db_teams.MemberRepository
+	organizationMemberRepository db_organization.MemberRepository
+}
+
+func New(
+	repo db_teams.Repository,
+	memberRepo db_teams.MemberRepository,
+	organizationMemberRepository db_organization.MemberRepository,
+) *Service {
+	return &Service{
+		repo:                         repo,
+		memberRepo:                   memberRepo,
+		organizationMemberRepository: organizationMemberRepository,
+	}
+}
+
+// Create creates a new team in the organization. The creator becomes the maintainer of the team.
+func (s *Service) Create(ctx context.Context, organizationID string, parentID *teams.ID, name string, createdBy users.ID) (*teams.Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	if parentID != nil {
+		parent, err := s.repo.Get(ctx, *parentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent team: %w", err)
+		}
+		if parent.OrganizationID != organizationID {
+			return nil, ErrDifferentOrganization
+		}
+	}
+
+	team := &teams.Team{
+		ID:             teams.ID(uuid.NewString()),
+		OrganizationID: organizationID,
+		ParentID:       parentID,
+		Name:           name,
+		CreatedAt:      time.Now(),
+		CreatedBy:      createdBy,
+	}
+
+	if err := s.repo.Create(ctx, team); err != nil {
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	if _, err := s.AddMember(ctx, team.ID, createdBy, teams.RoleMaintainer, createdBy); err != nil {
+		return nil, fmt.Errorf("failed to add creator to the team: %w", err)
+	}
+
+	return team, nil
+}
+
+func (s *Service) Get(ctx context.Context, id teams.ID) (*teams.Team, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) ListByOrganizationID(ctx context.Context, organizationID string) ([]*teams.Team, error) {
+	return s.repo.ListByOrganizationID(ctx, organizationID)
+}
+
+// Rename changes the name of the team. Teams are referenced by their IDs, so renaming a team doesn't affect
+// policies or review requests that use it.
+func (s *Service) Rename(ctx context.Context, id teams.ID, name string) (*teams.Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	team, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	team.Name = name
+
+	if err := s.repo.Update(ctx, team); err != nil {
+		return nil, fmt.Errorf("failed to update team: %w", err)
+	}
+
+	return team, nil
+}
+
+// SetParent moves the team under the parent team. If parentID is nil, the team becomes a top level team.
+func (s *Service) SetParent(ctx context.Context, id teams.ID, parentID *teams.ID) (*teams.Team, error) {
+	team, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	if parentID != nil {
+		ancestors, err := s.ancestors(ctx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.ID == team.ID {
+				return nil, ErrCycle
+			}
+			if ancestor.OrganizationID != team.OrganizationID {
+				return nil, ErrDifferentOrganization
+			}
+		}
+	}
+
+	team.ParentID = parentID
+
+	if err := s.repo.Update(ctx, team); err != nil {
+		return nil, fmt.Errorf("failed to update team: %w", err)
+	}
+
+	return team, nil
+}
+
+// Delete deletes the team. Child teams of the deleted team are moved to it's parent.
+func (s *Service) Delete(ctx context.Context, id teams.ID, deletedBy users.ID) error {
+	team, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+
+	children, err := s.children(ctx, team)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		child.ParentID = team.ParentID
+		if err := s.repo.Update(ctx, child); err != nil {
+			return fmt.Errorf("failed to update child team: %w", err)
+		}
+	}
+
+	now := time.Now()
+	team.DeletedAt = &now
+	team.DeletedBy = &deletedBy
+
+	if err := s.repo.Update(ctx, team); err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	return nil
+}
+
+// AddMember adds a member of the organization to the team. If the user is already a member of the team,
+// their role is updated.
+func (s *Service) AddMember(ctx context.Context, teamID teams.ID, userID users.ID, role teams.Role, addedBy users.ID) (*teams.Member, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	team, err := s.repo.Get(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	if _, err := s.organizationMemberRepository.GetByUserIDAndOrganizationID(ctx, userID, team.OrganizationID); errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotOrganizationMember
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	member := &teams.Member{
+		ID:        uuid.NewString(),
+		TeamID:    teamID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: time.Now(),
+		CreatedBy: addedBy,
+	}
+
+	if err := s.memberRepo.Create(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to create team member: %w", err)
+	}
+
+	return member, nil
+}
+
+func (s *Service) RemoveMember(ctx context.Context, teamID teams.ID, userID users.ID, removedBy users.ID) error {
+	member, err := s.memberRepo.GetByTeamIDAndUserID(ctx, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get team member: %w", err)
+	}
+
+	now := time.Now()
+	member.DeletedAt = &now
+	member.DeletedBy = &removedBy
+
+	if err := s.memberRepo.Update(ctx, member); err != nil {
+		return fmt.Errorf("failed to update team member: %w", err)
+	}
+
+	return nil
+}
+
+// Members returns the direct members of the team.
+func (s *Service) Members(ctx context.Context, teamID teams.ID) ([]*teams.Member, error) {
+	team, err := s.repo.Get(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return s.organizationMembers(ctx, team)
+}
+
+// organizationMembers returns the direct members of the team that are still members of the organization. Users
+// that are removed from the organization keep their team memberships, but they are not members of the team
+// anymore, unless they are added back to the organization.
+func (s *Service) organizationMembers(ctx context.Context, team *teams.Team) ([]*teams.Member, error) {
+	members, err := s.memberRepo.ListByTeamID(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+
+	res := make([]*teams.Member, 0, len(members))
+	for _, member := range members {
+		isMember, err := s.isOrganizationMember(ctx, team.OrganizationID, member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			res = append(res, member)
+		}
+	}
+	return res, nil
+}
+
+func (s *Service) isOrganizationMember(ctx context.Context, organizationID string, userID users.ID) (bool, error) {
+	_, err := s.organizationMemberRepository.GetByUserIDAndOrganizationID(ctx, userID, organizationID)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to get organization member: %w", err)
+	}
+}
+
+// ListUserIDs returns IDs of all users that are members of the team, or of any of it's child teams.
+func (s *Service) ListUserIDs(ctx context.Context, teamID teams.ID) ([]users.ID, error) {
+	team, err := s.repo.Get(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	descendants, err := s.descendants(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[users.ID]bool{}
+	userIDs := []users.ID{}
+	for _, t := range append([]*teams.Team{team}, descendants...) {
+		members, err := s.organizationMembers(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if seen[member.UserID] {
+				continue
+			}
+			seen[member.UserID] = true
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+// ListByUserID returns all teams that the user is a member of, including the parents of the teams that the
+// user is a direct member of. Teams of organizations that the user is not a member of are not returned.
+func (s *Service) ListByUserID(ctx context.Context, userID users.ID) ([]*teams.Team, error) {
+	memberships, err := s.memberRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team memberships: %w", err)
+	}
+
+	seen := map[teams.ID]bool{}
+	isMemberOf := map[string]bool{}
+	res := []*teams.Team{}
+	for _, membership := range memberships {
+		ancestors, err := s.ancestors(ctx, membership.TeamID)
+		switch {
+		case err == nil:
+		case errors.Is(err, sql.ErrNoRows):
+			// team is deleted
+			continue
+		default:
+			return nil, err
+		}
+
+		organizationID := ancestors[0].OrganizationID
+		isMember, checked := isMemberOf[organizationID]
+		if !checked {
+			if isMember, err = s.isOrganizationMember(ctx, organizationID, userID); err != nil {
+				return nil, err
+			}
+			isMemberOf[organizationID] = isMember
+		}
+		if !isMember {
+			continue
+		}
+
+		for _, team := range ancestors {
+			if seen[team.ID] {
+				continue
+			}
+			seen[team.ID] = true
+			res = append(res, team)
+		}
+	}
+
+	return res, nil
+}
+
+// CanMaintain returns true if the user is a maintainer of the team, or of any of it's parents.
+func (s *Service) CanMaintain(ctx context.Context, teamID teams.ID, userID users.ID) (bool, error) {
+	ancestors, err := s.ancestors(ctx, teamID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, team := range ancestors {
+		member, err := s.memberRepo.GetByTeamIDAndUserID(ctx, team.ID, userID)
+		switch {
+		case err == nil:
+			if member.Role == teams.RoleMaintainer {
+				return true, nil
+			}
+		case errors.Is(err, sql.ErrNoRows):
+		default:
+			return false, fmt.Errorf("failed to get team member: %w", err)
+		}
+	}
+
+	return false, nil
+}
+
+// ancestors returns the team, followed by all of it's parents.
+func (s *Service) ancestors(ctx context.Context, teamID teams.ID) ([]*teams.Team, error) {
+	res := []*teams.Team{}
+	seen := map[teams.ID]bool{}
+	for id := &teamID; id != nil && !seen[*id]; {
+		team, err := s.repo.Get(ctx, *id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get team: %w", err)
+		}
+		seen[team.ID] = true
+		res = append(res, team)
+		id = team.ParentID
+	}
+	return res, nil
+}
+
+func (s *Service) children(ctx context.Context, team *teams.Team) ([]*teams.Team, error) {
+	all, err := s.repo.ListByOrganizationID(ctx, team.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	res := []*teams.Team{}
+	for _, t := range all {
+		if t.ParentID != nil && *t.ParentID == team.ID {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+// descendants returns all children of the team, and their children.
+func (s *Service) descendants(ctx context.Context, team *teams.Team) ([]*teams.Team, error) {
+	all, err := s.repo.ListByOrganizationID(ctx, team.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	byParent := map[teams.ID][]*teams.Team{}
+	for _, t := range all {
+		if t.ParentID != nil {
+			byParent[*t.ParentID] = append(byParent[*t.ParentID], t)
+		}
+	}
+
+	res := []*teams.Team{}
+	seen := map[teams.ID]bool{team.ID: true}
+	queue := []teams.ID{team.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range byParent[id] {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			res = append(res, child)
+			queue = append(queue, child.ID)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"getsturdy.com/api/pkg/internal/inmemory"
+	"getsturdy.com/api/pkg/organization"
+	"getsturdy.com/api/pkg/organization/teams"
+	db_teams "getsturdy.com/api/pkg/organization/teams/db"
+	service_teams "getsturdy.com/api/pkg/organization/teams/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newService(t *testing.T, organizationID string, userIDs ...users.ID) *service_teams.Service {
+	orgMembers := inmemory.NewInMemoryOrganizationMemberRepository()
+	for _, userID := range userIDs {
+		assert.NoError(t, orgMembers.Create(context.Background(), organization.Member{
+			ID:             string(userID),
+			UserID:         userID,
+			OrganizationID: organizationID,
+		}))
+	}
+	return service_teams.New(db_teams.NewMemory(), db_teams.NewMemberMemory(), orgMembers)
+}
+
+func TestNestedTeams(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, "org", "alice", "bob", "carol")
+
+	engineering, err := svc.Create(ctx, "org", nil, "Engineering", "alice")
+	assert.NoError(t, err)
+
+	backend, err := svc.Create(ctx, "org", &engineering.ID, "Backend", "bob")
+	assert.NoError(t, err)
+
+	_, err = svc.AddMember(ctx, backend.ID, "carol", teams.RoleMember, "bob")
+	assert.NoError(t, err)
+
+	// members of child teams are members of the parent team
+	userIDs, err := svc.ListUserIDs(ctx, engineering.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []users.ID{"alice", "bob", "carol"}, userIDs)
+
+	userIDs, err = svc.ListUserIDs(ctx, backend.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []users.ID{"bob", "carol"}, userIDs)
+
+	carolsTeams, err := svc.ListByUserID(ctx, "carol")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*teams.Team{engineering, backend}, carolsTeams)
+
+	// maintainers of the parent team can maintain child teams
+	canMaintain, err := svc.CanMaintain(ctx, backend.ID, "alice")
+	assert.NoError(t, err)
+	assert.True(t, canMaintain)
+
+	canMaintain, err = svc.CanMaintain(ctx, engineering.ID, "bob")
+	assert.NoError(t, err)
+	assert.False(t, canMaintain)
+
+	canMaintain, err = svc.CanMaintain(ctx, backend.ID, "carol")
+	assert.NoError(t, err)
+	assert.False(t, canMaintain)
+
+	// a team can't become a child of it's own child
+	_, err = svc.SetParent(ctx, engineering.ID, &backend.ID)
+	assert.ErrorIs(t, err, service_teams.ErrCycle)
+
+	// renaming doesn't change the identity of the team
+	renamed, err := svc.Rename(ctx, engineering.ID, "R&D")
+	assert.NoError(t, err)
+	assert.Equal(t, engineering.ID, renamed.ID)
+
+	// deleting a team moves it's children up
+	assert.NoError(t, svc.Delete(ctx, engineering.ID, "alice"))
+	backend, err = svc.Get(ctx, backend.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, backend.ParentID)
+}
+
+func TestAddMember_not_organization_member(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, "org", "alice")
+
+	team, err := svc.Create(ctx, "org", nil, "Engineering", "alice")
+	assert.NoError(t, err)
+
+	_, err = svc.AddMember(ctx, team.ID, "mallory", teams.RoleMember, "alice")
+	assert.ErrorIs(t, err, service_teams.ErrNotOrganizationMember)
+
+	_, err = svc.AddMember(ctx, team.ID, "alice", "owner", "alice")
+	assert.ErrorIs(t, err, service_teams.ErrInvalidRole)
+}
+
+func TestRemovedOrganizationMember(t *testing.T) {
+	ctx := context.Background()
+	orgMembers := inmemory.NewInMemoryOrganizationMemberRepository()
+	assert.NoError(t, orgMembers.Create(ctx, organization.Member{ID: "alice", UserID: "alice", OrganizationID: "org"}))
+	memberRepo := db_teams.NewMemberMemory()
+	svc := service_teams.New(db_teams.NewMemory(), memberRepo, orgMembers)
+
+	team, err := svc.Create(ctx, "org", nil, "Engineering", "alice")
+	assert.NoError(t, err)
+
+	// mallory was a member of the team, but has been removed from the organization
+	assert.NoError(t, memberRepo.Create(ctx, &teams.Member{ID: "mallory", TeamID: team.ID, UserID: "mallory", Role: teams.RoleMember}))
+
+	userIDs, err := svc.ListUserIDs(ctx, team.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []users.ID{"alice"}, userIDs)
+
+	members, err := svc.Members(ctx, team.ID)
+	assert.NoError(t, err)
+	assert.Len(t, members, 1)
+
+	mallorysTeams, err := svc.ListByUserID(ctx, "mallory")
+	assert.NoError(t, err)
+	assert.Empty(t, mallorysTeams)
+}
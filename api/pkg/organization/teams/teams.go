@@ -0,0 +1,45 @@
+package teams
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+type ID string
+
+// Team is a group of organization members. Teams can be nested, members of a child team are also
+// considered members of all of it's parent teams.
+type Team struct {
+	ID             ID         `db:"id"`
+	OrganizationID string     `db:"organization_id"`
+	ParentID       *ID        `db:"parent_id"`
+	Name           string     `db:"name"`
+	CreatedAt      time.Time  `db:"created_at"`
+	CreatedBy      users.ID   `db:"created_by"`
+	DeletedAt      *time.Time `db:"deleted_at"`
+	DeletedBy      *users.ID  `db:"deleted_by"`
+}
+
+type Role string
+
+const (
+	RoleMember Role = "member"
+	// RoleMaintainer can manage the team, it's members and it's child teams.
+	RoleMaintainer Role = "maintainer"
+)
+
+func (r Role) IsValid() bool {
+	return r == RoleMember || r == RoleMaintainer
+}
+
+type Member struct {
+	ID        string     `db:"id"`
+	TeamID    ID         `db:"team_id"`
+	UserID    users.ID   `db:"user_id"`
+	Role      Role       `db:"role"`
+	CreatedAt time.Time  `db:"created_at"`
+	CreatedBy users.ID   `db:"created_by"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	DeletedBy *users.ID  `db:"deleted_by"`
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{
+		db: db,
+	}
+}
+
+func (d *database) Create(ctx context.Context, token *personaltokens.Token) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO personal_access_tokens (
+			id, user_id, hash, name, scopes, created_at, last_used_at, expires_at, revoked_at
+		) VALUES (
+			:id, :user_id, :hash, :name, :scopes, :created_at, :last_used_at, :expires_at, :revoked_at
+		)
+	`, token); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Update(ctx context.Context, token *personaltokens.Token) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE personal_access_tokens
+		SET name = :name,
+			scopes = :scopes,
+			last_used_at = :last_used_at,
+			expires_at = :expires_at,
+			revoked_at = :revoked_at
+		WHERE id = :id
+	`, token); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *database) GetByID(ctx context.Context, id string) (*personaltokens.Token, error) {
+	token := &personaltokens.Token{}
+	if err := d.db.GetContext(ctx, token, `
+		SELECT
+			id, user_id, hash, name, scopes, created_at, last_used_at, expires_at, revoked_at
+		FROM personal_access_tokens
+		WHERE id = $1
+	`, id); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return token, nil
+}
+
+func (d *database) ListByUserID(ctx context.Context, userID users.ID) ([]*personaltokens.Token, error) {
+	var tokens []*personaltokens.Token
+	if err := d.db.SelectContext(ctx, &tokens, `
+		SELECT
+			id, user_id, hash, name, scopes, created_at, last_used_at, expires_at, revoked_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	byID map[string]*personaltokens.Token
+}
+
+func NewMemory() *memory {
+	return &memory{
+		byID: map[string]*personaltokens.Token{},
+	}
+}
+
+func (m *memory) Create(_ context.Context, token *personaltokens.Token) error {
+	m.byID[token.ID] = token
+	return nil
+}
+
+func (m *memory) Update(_ context.Context, token *personaltokens.Token) error {
+	m.byID[token.ID] = token
+	return nil
+}
+
+func (m *memory) GetByID(_ context.Context, id string) (*personaltokens.Token, error) {
+	token, found := m.byID[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return token, nil
+}
+
+func (m *memory) ListByUserID(_ context.Context, userID users.ID) ([]*personaltokens.Token, error) {
+	var tokens []*personaltokens.Token
+	for _, token := range m.byID {
+		if token.UserID == userID && token.RevokedAt == nil {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	"getsturdy.com/api/pkg/users"
+)
+
+type Repository interface {
+	Create(context.Context, *personaltokens.Token) error
+	Update(context.Context, *personaltokens.Token) error
+	GetByID(context.Context, string) (*personaltokens.Token, error)
+	// ListByUserID returns all tokens of the user that have not been revoked.
+	ListByUserID(context.Context, users.ID) ([]*personaltokens.Token, error)
+}
@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/auth"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/personaltokens"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type rootResolver struct {
+	personalTokensService *service_personaltokens.Service
+}
+
+func New(
+	personalTokensService *service_personaltokens.Service,
+) resolvers.PersonalTokensRootResolver {
+	return &rootResolver{
+		personalTokensService: personalTokensService,
+	}
+}
+
+func (r *rootResolver) InternalListByUserID(ctx context.Context, userID users.ID) ([]resolvers.PersonalAccessTokenResolver, error) {
+	// users can only see their own tokens
+	if authUserID, err := auth.UserID(ctx); err != nil || authUserID != userID {
+		return []resolvers.PersonalAccessTokenResolver{}, nil
+	}
+
+	tokens, err := r.personalTokensService.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.PersonalAccessTokenResolver, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, &resolver{token: token})
+	}
+	return res, nil
+}
+
+// sessionUserID returns the id of the authenticated user. Tokens can only be managed from a browser
+// session, otherwise a token could be used to create another token with more scopes.
+func sessionUserID(ctx context.Context) (users.ID, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if subject, _ := auth.FromContext(ctx); subject.IsPersonalAccessToken() {
+		return "", fmt.Errorf("personal access tokens can not be managed with a personal access token: %w", auth.ErrForbidden)
+	}
+	return userID, nil
+}
+
+func (r *rootResolver) CreatePersonalAccessToken(ctx context.Context, args resolvers.CreatePersonalAccessTokenArgs) (resolvers.PersonalAccessTokenResolver, error) {
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	scopes := make([]personaltokens.Scope, 0, len(args.Input.Scopes))
+	for _, scope := range args.Input.Scopes {
+		scopes = append(scopes, personaltokens.Scope(scope))
+	}
+
+	var expiresAt *time.Time
+	if args.Input.ExpiresAt != nil {
+		t := time.Unix(int64(*args.Input.ExpiresAt), 0)
+		expiresAt = &t
+	}
+
+	plainTextToken, token, err := r.personalTokensService.Create(ctx, userID, args.Input.Name, scopes, expiresAt)
+	switch {
+	case err == nil:
+	case errors.Is(err, service_personaltokens.ErrNoScopes),
+		errors.Is(err, service_personaltokens.ErrInvalidScope):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "scopes", err.Error())
+	case errors.Is(err, service_personaltokens.ErrInvalidExpiry):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "expiresAt", err.Error())
+	default:
+		return nil, gqlerror.Error(fmt.Errorf("failed to create token: %w", err))
+	}
+
+	return &resolver{
+		token:          token,
+		plainTextToken: &plainTextToken,
+	}, nil
+}
+
+func (r *rootResolver) RevokePersonalAccessToken(ctx context.Context, args resolvers.RevokePersonalAccessTokenArgs) (resolvers.PersonalAccessTokenResolver, error) {
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	token, err := r.personalTokensService.Get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if token.UserID != userID {
+		return nil, gqlerror.Error(auth.ErrForbidden)
+	}
+
+	switch err := r.personalTokensService.Revoke(ctx, token); {
+	case err == nil:
+	case errors.Is(err, service_personaltokens.ErrAlreadyRevoked):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{token: token}, nil
+}
+
+type resolver struct {
+	plainTextToken *string
+	token          *personaltokens.Token
+}
+
+func (r *resolver) ID() graphql.ID {
+	return graphql.ID(r.token.ID)
+}
+
+func (r *resolver) Name() string {
+	return r.token.Name
+}
+
+func (r *resolver) Scopes() []string {
+	return r.token.Scopes
+}
+
+func (r *resolver) CreatedAt() int32 {
+	return int32(r.token.CreatedAt.Unix())
+}
+
+func (r *resolver) LastUsedAt() *int32 {
+	if r.token.LastUsedAt == nil {
+		return nil
+	}
+	luat := int32(r.token.LastUsedAt.Unix())
+	return &luat
+}
+
+func (r *resolver) ExpiresAt() *int32 {
+	if r.token.ExpiresAt == nil {
+		return nil
+	}
+	eat := int32(r.token.ExpiresAt.Unix())
+	return &eat
+}
+
+func (r *resolver) Token() *string {
+	return r.plainTextToken
+}
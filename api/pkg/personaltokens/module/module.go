@@ -0,0 +1,14 @@
+package module
+
+import (
+	"getsturdy.com/api/pkg/di"
+	"getsturdy.com/api/pkg/personaltokens/db"
+	"getsturdy.com/api/pkg/personaltokens/graphql"
+	"getsturdy.com/api/pkg/personaltokens/service"
+)
+
+func Module(c *di.Container) {
+	c.Import(db.Module)
+	c.Import(graphql.Module)
+	c.Import(service.Module)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	db_personaltokens "getsturdy.com/api/pkg/personaltokens/db"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+)
+
+const (
+	secretBytes = 32
+
+	// lastUsedPrecision limits how often the last used timestamp is written, tokens can be used on every
+	// request.
+	lastUsedPrecision = time.Minute
+)
+
+var (
+	ErrNoScopes       = errors.New("at least one scope is required")
+	ErrInvalidScope   = errors.New("invalid scope")
+	ErrInvalidExpiry  = errors.New("expiry must be in the future")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrTokenInactive  = errors.New("token has expired or been revoked")
+	ErrAlreadyRevoked = errors.New("token is already revoked")
+)
+
+type Service struct {
+	repo db_personaltokens.Repository
+}
+
+func New(repo db_personaltokens.Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// Create creates a new personal access token for the user. It returns the token in plaintext (not stored),
+// and the stored token.
+//
+// If expiresAt is nil, the token never expires.
+func (s *Service) Create(ctx context.Context, userID users.ID, name string, scopes []personaltokens.Scope, expiresAt *time.Time) (string, *personaltokens.Token, error) {
+	if len(scopes) == 0 {
+		return "", nil, ErrNoScopes
+	}
+
+	scopeStrings := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		scopeStrings = append(scopeStrings, string(scope))
+	}
+
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return "", nil, ErrInvalidExpiry
+	}
+
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	plainTextSecret := hex.EncodeToString(secret)
+
+	token := &personaltokens.Token{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Hash:      personaltokens.Hash(plainTextSecret),
+		Name:      name,
+		Scopes:    scopeStrings,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := s.repo.Create(ctx, token); err != nil {
+		return "", nil, fmt.Errorf("failed to create: %w", err)
+	}
+
+	return personaltokens.Format(token.ID, plainTextSecret), token, nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*personaltokens.Token, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListByUserID returns all tokens of the user that have not been revoked, including expired tokens.
+func (s *Service) ListByUserID(ctx context.Context, userID users.ID) ([]*personaltokens.Token, error) {
+	tokens, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke makes the token unusable. Revoked tokens can not be restored.
+func (s *Service) Revoke(ctx context.Context, token *personaltokens.Token) error {
+	if token.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+
+	if err := s.repo.Update(ctx, token); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllByUserID revokes all tokens of the user.
+func (s *Service) RevokeAllByUserID(ctx context.Context, userID users.ID) error {
+	tokens, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if err := s.Revoke(ctx, token); err != nil && !errors.Is(err, ErrAlreadyRevoked) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the active token that matches the plain text token. On success, the last used
+// timestamp of the token is updated.
+//
+// Scopes are not checked, it's up to the caller to verify that the token has the scopes it needs.
+func (s *Service) Authenticate(ctx context.Context, plainText string) (*personaltokens.Token, error) {
+	id, secret, ok := personaltokens.Parse(plainText)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.repo.GetByID(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrInvalidToken
+	default:
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if !token.Verify(secret) {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, ErrTokenInactive
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedPrecision {
+		token.LastUsedAt = &now
+		if err := s.repo.Update(ctx, token); err != nil {
+			return nil, fmt.Errorf("failed to update last used: %w", err)
+		}
+	}
+
+	return token, nil
+}
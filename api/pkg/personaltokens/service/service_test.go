@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/personaltokens"
+	db_personaltokens "getsturdy.com/api/pkg/personaltokens/db"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreate_validation(t *testing.T) {
+	ctx := context.Background()
+	svc := service_personaltokens.New(db_personaltokens.NewMemory())
+
+	_, _, err := svc.Create(ctx, "user", "no scopes", nil, nil)
+	assert.ErrorIs(t, err, service_personaltokens.ErrNoScopes)
+
+	_, _, err = svc.Create(ctx, "user", "invalid scope", []personaltokens.Scope{"root"}, nil)
+	assert.ErrorIs(t, err, service_personaltokens.ErrInvalidScope)
+
+	past := time.Now().Add(-time.Hour)
+	_, _, err = svc.Create(ctx, "user", "expired", []personaltokens.Scope{personaltokens.ScopeAPIRead}, &past)
+	assert.ErrorIs(t, err, service_personaltokens.ErrInvalidExpiry)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repo := db_personaltokens.NewMemory()
+	svc := service_personaltokens.New(repo)
+
+	plainText, token, err := svc.Create(ctx, "user", "bot", []personaltokens.Scope{personaltokens.ScopeAPIRead}, nil)
+	assert.NoError(t, err)
+	assert.True(t, len(plainText) > len(personaltokens.Prefix))
+
+	_, err = svc.Authenticate(ctx, "not a token")
+	assert.ErrorIs(t, err, service_personaltokens.ErrInvalidToken)
+
+	_, err = svc.Authenticate(ctx, personaltokens.Format(token.ID, "wrong"))
+	assert.ErrorIs(t, err, service_personaltokens.ErrInvalidToken)
+
+	_, err = svc.Authenticate(ctx, personaltokens.Format("unknown", "wrong"))
+	assert.ErrorIs(t, err, service_personaltokens.ErrInvalidToken)
+
+	authenticated, err := svc.Authenticate(ctx, plainText)
+	if assert.NoError(t, err) {
+		assert.Equal(t, token.ID, authenticated.ID)
+		assert.NotNil(t, authenticated.LastUsedAt)
+	}
+
+	assert.NoError(t, svc.Revoke(ctx, token))
+	assert.ErrorIs(t, svc.Revoke(ctx, token), service_personaltokens.ErrAlreadyRevoked)
+
+	_, err = svc.Authenticate(ctx, plainText)
+	assert.ErrorIs(t, err, service_personaltokens.ErrTokenInactive)
+
+	tokens, err := svc.ListByUserID(ctx, "user")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}
@@ -0,0 +1,101 @@
+package personaltokens
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/lib/pq"
+)
+
+type Scope string
+
+const (
+	// ScopeAPIRead allows reading everything the user has access to through the API
+	ScopeAPIRead Scope = "api:read"
+	// ScopeAPIWrite allows making changes on behalf of the user through the API
+	ScopeAPIWrite Scope = "api:write"
+	// ScopeGitRead allows cloning and fetching codebases over git
+	ScopeGitRead Scope = "git:read"
+	// ScopeGitWrite allows pushing to codebases over git
+	ScopeGitWrite Scope = "git:write"
+)
+
+var validScopes = map[Scope]bool{
+	ScopeAPIRead:  true,
+	ScopeAPIWrite: true,
+	ScopeGitRead:  true,
+	ScopeGitWrite: true,
+}
+
+func (s Scope) IsValid() bool {
+	return validScopes[s]
+}
+
+// Prefix is prepended to all personal access tokens, so that they can be told apart from JWTs.
+const Prefix = "sturdy_pat_"
+
+type Token struct {
+	ID     string   `db:"id" json:"id"`
+	UserID users.ID `db:"user_id" json:"user_id"`
+	// Hash is a sha256 hash of the secret. Secrets are long and random, so unlike passwords they don't
+	// need a slow hash, which would be too expensive to verify on every request.
+	Hash       []byte         `db:"hash" json:"-"`
+	Name       string         `db:"name" json:"name"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at"`
+}
+
+func Hash(secret string) []byte {
+	h := sha256.Sum256([]byte(secret))
+	return h[:]
+}
+
+func (t *Token) Verify(secret string) bool {
+	return subtle.ConstantTimeCompare(t.Hash, Hash(secret)) == 1
+}
+
+func (t *Token) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if Scope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns false if the token has been revoked or has expired.
+func (t *Token) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+		return false
+	}
+	return true
+}
+
+// Format returns the plain text token that is given to the user.
+func Format(id, secret string) string {
+	return fmt.Sprintf("%s%s_%s", Prefix, id, secret)
+}
+
+// Parse splits a plain text token into the token id and the secret. ok is false if the value is not a
+// personal access token.
+func Parse(plainText string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(plainText, Prefix) {
+		return "", "", false
+	}
+	idx := strings.LastIndex(plainText, "_")
+	if idx <= len(Prefix) || idx == len(plainText)-1 {
+		return "", "", false
+	}
+	return plainText[len(Prefix):idx], plainText[idx+1:], true
+}
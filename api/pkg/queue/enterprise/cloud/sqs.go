@@ -78,7 +78,29 @@ func (q *sqsQueue) Publish(_ context.Context, name names.IncompleteQueueName, v
 		return fmt.Errorf("failed to create sqs publisher: %w", err)
 	}
 
-	if err := publish(v); err != nil {
+	if err := publish(v, 0); err != nil {
+		return fmt.Errorf("failed to publish message to sqs: %w", err)
+	}
+	return nil
+}
+
+// maxDelay is the longest delay that SQS supports.
+const maxDelay = 15 * time.Minute
+
+// PublishDelayed publishes a message with a delay. Delays longer than 15 minutes are shortened to 15 minutes.
+func (q *sqsQueue) PublishDelayed(_ context.Context, name names.IncompleteQueueName, v any, delay time.Duration) error {
+	q.logger.Info("publishing delayed message", zap.String("queue", string(name)), zap.Duration("delay", delay))
+
+	publish, err := q.getPublisher(name)
+	if err != nil {
+		return fmt.Errorf("failed to create sqs publisher: %w", err)
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if err := publish(v, delay); err != nil {
 		return fmt.Errorf("failed to publish message to sqs: %w", err)
 	}
 	return nil
@@ -193,7 +215,7 @@ func sub(awsSession *session.Session, logger *zap.Logger, queueName string, snsT
 	return nil
 }
 
-type publisher func(msg any) error
+type publisher func(msg any, delay time.Duration) error
 
 func newPublisher(logger *zap.Logger, awsSession *session.Session, queueName names.QueueName) (publisher, error) {
 	q := sqs.New(awsSession)
@@ -204,15 +226,16 @@ func newPublisher(logger *zap.Logger, awsSession *session.Session, queueName nam
 		return nil, err
 	}
 
-	publ := func(msg any) error {
+	publ := func(msg any, delay time.Duration) error {
 		body, err := marshal(msg)
 		if err != nil {
 			return err
 		}
 
 		_, err = q.SendMessage(&sqs.SendMessageInput{
-			QueueUrl:    &queueUrl,
-			MessageBody: aws.String(string(body)),
+			QueueUrl:     &queueUrl,
+			MessageBody:  aws.String(string(body)),
+			DelaySeconds: aws.Int64(int64(delay.Seconds())),
 		})
 		if err != nil {
 			return err
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"getsturdy.com/api/pkg/queue/names"
 	"golang.org/x/sync/errgroup"
@@ -79,6 +80,27 @@ func (q *memoryQueue) Publish(ctx context.Context, name names.IncompleteQueueNam
 	return wg.Wait()
 }
 
+// PublishDelayed publishes the message once the delay has passed. Like all messages in the in-memory queue,
+// delayed messages are lost on restart.
+func (q *memoryQueue) PublishDelayed(ctx context.Context, name names.IncompleteQueueName, msg any, delay time.Duration) error {
+	if delay <= 0 {
+		return q.Publish(ctx, name, msg)
+	}
+
+	// marshal right away, so that later changes to msg are not published
+	marshaled, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	time.AfterFunc(delay, func() {
+		if err := q.Publish(context.Background(), name, json.RawMessage(marshaled)); err != nil {
+			q.logger.Error("failed to publish delayed message", zap.Stringer("queue", name), zap.Error(err))
+		}
+	})
+	return nil
+}
+
 func (q *memoryQueue) Subscribe(ctx context.Context, name names.IncompleteQueueName, messages chan<- Message) error {
 	q.chansGuard.Lock()
 	q.chans[name] = append(q.chans[name], messages)
@@ -5,9 +5,9 @@ package module
 
 import (
 	"getsturdy.com/api/pkg/di"
-	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/postgres"
 )
 
 func Module(c *di.Container) {
-	c.Register(queue.NewInMemory, new(queue.Queue))
+	c.Register(postgres.New)
 }
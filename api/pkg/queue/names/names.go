@@ -29,6 +29,8 @@ const (
 	GithubWebhooks                    IncompleteQueueName = "github_webhooks"
 	ViewSnapshot                      IncompleteQueueName = "view_snapshot"
 	CITriggerQueue                    IncompleteQueueName = "ci_trigger"
+	EmailRetry                        IncompleteQueueName = "email_retry"
+	WebhookDeliveries                 IncompleteQueueName = "webhook_deliveries"
 	longestAllowedName                IncompleteQueueName = "xxxxxXXXXXxxxxxXXXXXxxxx" // To highlight how long a name can be
 )
 
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultVisibilityTimeout = 5 * time.Minute
+	defaultMaxReceiveCount   = 5
+)
+
+var (
+	// pollInterval is how often an empty queue is checked for new messages.
+	pollInterval = time.Second
+)
+
+var (
+	publishedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_queue_published_total",
+	}, []string{"queue"})
+	receivedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_queue_received_total",
+	}, []string{"queue", "redelivery"})
+	ackedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_queue_acked_total",
+	}, []string{"queue"})
+	deadLetteredCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sturdy_queue_dead_lettered_total",
+	}, []string{"queue"})
+)
+
+type Configuration struct {
+	Memory            bool          `long:"memory" description:"Use the in-memory queue instead of Postgres, queued messages are lost on restart"`
+	VisibilityTimeout time.Duration `long:"visibility-timeout" description:"How long a received message is hidden from other subscribers, it is redelivered if it's not acknowledged in time" default:"5m"`
+	MaxReceiveCount   int           `long:"max-receive-count" description:"How many times a message is received before it's moved to the dead-letter table" default:"5"`
+}
+
+func New(logger *zap.Logger, db *sqlx.DB, cfg *Configuration) (queue.Queue, error) {
+	if cfg == nil {
+		cfg = &Configuration{}
+	}
+	if cfg.Memory {
+		return queue.NewInMemory(logger), nil
+	}
+	return NewPostgres(logger, db, cfg), nil
+}
+
+var _ queue.Queue = &Queue{}
+
+// Queue stores messages in Postgres, so that they survive restarts and are shared between all instances
+// that use the same database.
+//
+// A received message is hidden from other subscribers for the visibility timeout. If it's not acknowledged
+// before the timeout, it is received again. Messages that have been received too many times are moved to the
+// queue_dead_letters table.
+type Queue struct {
+	logger *zap.Logger
+	db     *sqlx.DB
+
+	visibilityTimeout time.Duration
+	maxReceiveCount   int
+}
+
+func NewPostgres(logger *zap.Logger, db *sqlx.DB, cfg *Configuration) *Queue {
+	q := &Queue{
+		logger:            logger.Named("postgresQueue"),
+		db:                db,
+		visibilityTimeout: cfg.VisibilityTimeout,
+		maxReceiveCount:   cfg.MaxReceiveCount,
+	}
+	if q.visibilityTimeout <= 0 {
+		q.visibilityTimeout = defaultVisibilityTimeout
+	}
+	if q.maxReceiveCount <= 0 {
+		q.maxReceiveCount = defaultMaxReceiveCount
+	}
+	return q
+}
+
+func (q *Queue) Publish(ctx context.Context, name names.IncompleteQueueName, v any) error {
+	return q.PublishDelayed(ctx, name, v, 0)
+}
+
+// PublishDelayed publishes a message that is hidden from subscribers until the delay has passed.
+func (q *Queue) PublishDelayed(ctx context.Context, name names.IncompleteQueueName, v any, delay time.Duration) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if _, err := q.db.ExecContext(ctx, `INSERT INTO queue_messages (queue, payload, visible_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 millisecond')`, name.String(), payload, delay.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+	publishedCounter.WithLabelValues(name.String()).Inc()
+	return nil
+}
+
+func (q *Queue) Subscribe(ctx context.Context, name names.IncompleteQueueName, messages chan<- queue.Message) error {
+	q.logger.Info("new subscription", zap.Stringer("queue", name))
+	q.subscribe(ctx, name, messages)
+	q.logger.Info("stopping subscription", zap.Stringer("queue", name))
+	return nil
+}
+
+// subscribe sends messages from the queue to messages until ctx is done. When the queue is empty, it's polled
+// every pollInterval.
+func (q *Queue) subscribe(ctx context.Context, name names.IncompleteQueueName, messages chan<- queue.Message) {
+	logger := q.logger.With(zap.Stringer("queue", name))
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.deadLetter(ctx, name); err != nil && ctx.Err() == nil {
+			logger.Error("failed to move messages to the dead-letter table", zap.Error(err))
+		}
+
+		for {
+			msg, err := q.receive(ctx, name)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error("failed to receive message", zap.Error(err))
+				}
+				break
+			}
+			if msg == nil {
+				break
+			}
+
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// receive returns the oldest visible message of the queue, or nil if there is none.
+func (q *Queue) receive(ctx context.Context, name names.IncompleteQueueName) (*message, error) {
+	msg := &message{
+		db:   q.db,
+		name: name,
+	}
+	err := q.db.QueryRowxContext(ctx, `UPDATE queue_messages
+		SET visible_at = NOW() + $2 * INTERVAL '1 millisecond',
+			attempts = attempts + 1
+		WHERE id = (
+			SELECT id
+			FROM queue_messages
+			WHERE queue = $1
+			  AND visible_at <= NOW()
+			  AND attempts < $3
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, attempts`, name.String(), q.visibilityTimeout.Milliseconds(), q.maxReceiveCount).
+		Scan(&msg.id, &msg.payload, &msg.attempts)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to select message: %w", err)
+	}
+
+	receivedCounter.WithLabelValues(name.String(), strconv.FormatBool(msg.attempts > 1)).Inc()
+	return msg, nil
+}
+
+// deadLetter moves messages that have been received maxReceiveCount times without being acknowledged to the
+// queue_dead_letters table.
+func (q *Queue) deadLetter(ctx context.Context, name names.IncompleteQueueName) error {
+	res, err := q.db.ExecContext(ctx, `WITH dead AS (
+			DELETE FROM queue_messages
+			WHERE id IN (
+				SELECT id
+				FROM queue_messages
+				WHERE queue = $1
+				  AND visible_at <= NOW()
+				  AND attempts >= $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, queue, payload, attempts, created_at
+		)
+		INSERT INTO queue_dead_letters (id, queue, payload, attempts, created_at)
+		SELECT id, queue, payload, attempts, created_at
+		FROM dead`, name.String(), q.maxReceiveCount)
+	if err != nil {
+		return fmt.Errorf("failed to move messages: %w", err)
+	}
+	dead, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if dead > 0 {
+		q.logger.Warn("moved messages to the dead-letter table", zap.Stringer("queue", name), zap.Int64("count", dead))
+		deadLetteredCounter.WithLabelValues(name.String()).Add(float64(dead))
+	}
+	return nil
+}
+
+type message struct {
+	db   *sqlx.DB
+	name names.IncompleteQueueName
+
+	id       int64
+	payload  []byte
+	attempts int
+}
+
+func (m *message) As(v any) error {
+	if err := json.Unmarshal(m.payload, v); err != nil {
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return nil
+}
+
+func (m *message) Ack() error {
+	if _, err := m.db.Exec(`DELETE FROM queue_messages WHERE id = $1`, m.id); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+	ackedCounter.WithLabelValues(m.name.String()).Inc()
+	return nil
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/db"
+	"getsturdy.com/api/pkg/internal/sturdytest"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testMessage struct {
+	Value string
+}
+
+func setup(t *testing.T, cfg *Configuration) *Queue {
+	if os.Getenv("E2E_TEST") == "" {
+		t.SkipNow()
+	}
+
+	d, err := db.Setup(sturdytest.PsqlDbSourceForTesting())
+	assert.NoError(t, err)
+
+	pollInterval = 10 * time.Millisecond
+	return NewPostgres(zap.NewNop(), d, cfg)
+}
+
+func receive(t *testing.T, messages <-chan queue.Message) queue.Message {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	q := setup(t, &Configuration{})
+	name := names.IncompleteQueueName(uuid.NewString())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, q.Publish(ctx, name, &testMessage{Value: "first"}))
+	assert.NoError(t, q.Publish(ctx, name, &testMessage{Value: "second"}))
+
+	messages := make(chan queue.Message)
+	go func() {
+		assert.NoError(t, q.Subscribe(ctx, name, messages))
+	}()
+
+	for _, expected := range []string{"first", "second"} {
+		msg := receive(t, messages)
+		var m testMessage
+		assert.NoError(t, msg.As(&m))
+		assert.Equal(t, expected, m.Value)
+		assert.NoError(t, msg.Ack())
+	}
+}
+
+func TestPublishDelayed(t *testing.T) {
+	q := setup(t, &Configuration{})
+	name := names.IncompleteQueueName(uuid.NewString())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	assert.NoError(t, q.PublishDelayed(ctx, name, &testMessage{Value: "delayed"}, 500*time.Millisecond))
+
+	messages := make(chan queue.Message)
+	go func() {
+		assert.NoError(t, q.Subscribe(ctx, name, messages))
+	}()
+
+	msg := receive(t, messages)
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	var m testMessage
+	assert.NoError(t, msg.As(&m))
+	assert.Equal(t, "delayed", m.Value)
+	assert.NoError(t, msg.Ack())
+}
+
+func TestRedeliveryAndDeadLetter(t *testing.T) {
+	q := setup(t, &Configuration{
+		VisibilityTimeout: 50 * time.Millisecond,
+		MaxReceiveCount:   2,
+	})
+	name := names.IncompleteQueueName(uuid.NewString())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, q.Publish(ctx, name, &testMessage{Value: "unacked"}))
+
+	messages := make(chan queue.Message)
+	go func() {
+		assert.NoError(t, q.Subscribe(ctx, name, messages))
+	}()
+
+	// not acked, so it's received again after the visibility timeout
+	first := receive(t, messages).(*message)
+	second := receive(t, messages).(*message)
+	assert.Equal(t, first.id, second.id)
+	assert.Equal(t, 2, second.attempts)
+
+	assert.Eventually(t, func() bool {
+		var count int
+		assert.NoError(t, q.db.Get(&count, `SELECT COUNT(*) FROM queue_dead_letters WHERE id = $1`, first.id))
+		return count == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var remaining int
+	assert.NoError(t, q.db.Get(&remaining, `SELECT COUNT(*) FROM queue_messages WHERE queue = $1`, name.String()))
+	assert.Equal(t, 0, remaining)
+}
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"time"
 
 	"getsturdy.com/api/pkg/queue/names"
 )
@@ -9,6 +10,9 @@ import (
 type Queue interface {
 	// Publish publishes a message to the queue.
 	Publish(context.Context, names.IncompleteQueueName, any) error
+	// PublishDelayed publishes a message to the queue that is not received until the delay has passed. Use it
+	// to retry later, instead of holding on to a received message.
+	PublishDelayed(context.Context, names.IncompleteQueueName, any, time.Duration) error
 	// Subscribe returns a channel that will receive messages from the queue.
 	Subscribe(context.Context, names.IncompleteQueueName, chan<- Message) error
 }
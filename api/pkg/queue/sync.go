@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -48,6 +49,16 @@ func (q *Sync) Publish(ctx context.Context, name names.IncompleteQueueName, msg
 	return wg.Wait()
 }
 
+// PublishDelayed waits for the delay, and then publishes the message like Publish.
+func (q *Sync) PublishDelayed(ctx context.Context, name names.IncompleteQueueName, msg any, delay time.Duration) error {
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return q.Publish(ctx, name, msg)
+}
+
 func (q *Sync) Subscribe(ctx context.Context, name names.IncompleteQueueName, messages chan<- Message) error {
 	q.chansGuard.Lock()
 	q.chans[name] = append(q.chans[name], messages)
@@ -0,0 +1,57 @@
+package remote
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"getsturdy.com/api/pkg/codebases"
+)
+
+// BranchHead is a branch on a remote, and the commit that it points to.
+type BranchHead struct {
+	Name      string
+	CommitSHA string
+}
+
+// workspaceBranchPrefix is the prefix of the branches that workspaces are pushed to.
+const workspaceBranchPrefix = "sturdy-"
+
+// BranchHeads returns the branches in refs that can be imported as workspaces, sorted by name. The tracked
+// branch is mapped to trunk, and the branches that Sturdy has pushed workspaces to already have a workspace,
+// they are not included.
+func BranchHeads(refs []*plumbing.Reference, trackedBranch string) []*BranchHead {
+	var heads []*BranchHead
+	for _, ref := range refs {
+		if ref.Type() != plumbing.HashReference || !ref.Name().IsBranch() {
+			continue
+		}
+		name := ref.Name().Short()
+		if name == trackedBranch || strings.HasPrefix(name, workspaceBranchPrefix) {
+			continue
+		}
+		heads = append(heads, &BranchHead{Name: name, CommitSHA: ref.Hash().String()})
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return heads[i].Name < heads[j].Name
+	})
+	return heads
+}
+
+// Branch is a branch on a remote that has been imported as a workspace.
+type Branch struct {
+	ID          string       `db:"id"`
+	RemoteID    string       `db:"remote_id"`
+	CodebaseID  codebases.ID `db:"codebase_id"`
+	WorkspaceID string       `db:"workspace_id"`
+	Name        string       `db:"name"`
+	// HeadSHA is the commit on the remote that the workspace was last updated from.
+	HeadSHA string `db:"head_sha"`
+	// Linked branches are imported again when they are updated on the remote. Branches are unlinked when the
+	// workspace is pushed from Sturdy, the workspace is the source of truth from then on.
+	Linked    bool       `db:"linked"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
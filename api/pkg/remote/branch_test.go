@@ -0,0 +1,33 @@
+package remote_test
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/remote"
+)
+
+func TestBranchHeads(t *testing.T) {
+	hash := func(s string) plumbing.Hash {
+		return plumbing.NewHash(s)
+	}
+
+	refs := []*plumbing.Reference{
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main"),
+		plumbing.NewHashReference("refs/heads/main", hash("1111111111111111111111111111111111111111")),
+		plumbing.NewHashReference("refs/heads/feature/login", hash("2222222222222222222222222222222222222222")),
+		plumbing.NewHashReference("refs/heads/bugfix", hash("3333333333333333333333333333333333333333")),
+		plumbing.NewHashReference("refs/heads/sturdy-8d7c3b9e", hash("4444444444444444444444444444444444444444")),
+		plumbing.NewHashReference("refs/tags/v1.0.0", hash("5555555555555555555555555555555555555555")),
+		plumbing.NewHashReference("refs/pull/1/head", hash("6666666666666666666666666666666666666666")),
+	}
+
+	assert.Equal(t, []*remote.BranchHead{
+		{Name: "bugfix", CommitSHA: "3333333333333333333333333333333333333333"},
+		{Name: "feature/login", CommitSHA: "2222222222222222222222222222222222222222"},
+	}, remote.BranchHeads(refs, "main"))
+
+	assert.Empty(t, remote.BranchHeads(nil, "main"))
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"getsturdy.com/api/pkg/remote"
+)
+
+type BranchRepository interface {
+	Create(ctx context.Context, branch *remote.Branch) error
+	Update(ctx context.Context, branch *remote.Branch) error
+	GetByWorkspaceID(ctx context.Context, workspaceID string) (*remote.Branch, error)
+	ListByRemoteIDAndName(ctx context.Context, remoteID, name string) ([]*remote.Branch, error)
+	ListLinkedByRemoteID(ctx context.Context, remoteID string) ([]*remote.Branch, error)
+}
+
+func NewBranchRepository(db *sqlx.DB) BranchRepository {
+	return &branchRepo{db: db}
+}
+
+type branchRepo struct {
+	db *sqlx.DB
+}
+
+func (r *branchRepo) Create(ctx context.Context, branch *remote.Branch) error {
+	_, err := r.db.NamedExecContext(ctx, `INSERT INTO remote_branches (id, remote_id, codebase_id, workspace_id, name, head_sha, linked, created_at, updated_at)
+		VALUES (:id, :remote_id, :codebase_id, :workspace_id, :name, :head_sha, :linked, :created_at, :updated_at)`, branch)
+	if err != nil {
+		return fmt.Errorf("failed to create remote branch: %w", err)
+	}
+	return nil
+}
+
+func (r *branchRepo) Update(ctx context.Context, branch *remote.Branch) error {
+	_, err := r.db.NamedExecContext(ctx, `UPDATE remote_branches
+		SET head_sha = :head_sha,
+		    linked = :linked,
+		    updated_at = :updated_at
+		WHERE id = :id`, branch)
+	if err != nil {
+		return fmt.Errorf("failed to update remote branch: %w", err)
+	}
+	return nil
+}
+
+func (r *branchRepo) GetByWorkspaceID(ctx context.Context, workspaceID string) (*remote.Branch, error) {
+	var res remote.Branch
+	if err := r.db.GetContext(ctx, &res, `SELECT * FROM remote_branches WHERE workspace_id = $1`, workspaceID); err != nil {
+		return nil, fmt.Errorf("failed to GetByWorkspaceID: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *branchRepo) ListByRemoteIDAndName(ctx context.Context, remoteID, name string) ([]*remote.Branch, error) {
+	var res []*remote.Branch
+	if err := r.db.SelectContext(ctx, &res, `SELECT * FROM remote_branches WHERE remote_id = $1 AND name = $2 ORDER BY created_at`, remoteID, name); err != nil {
+		return nil, fmt.Errorf("failed to ListByRemoteIDAndName: %w", err)
+	}
+	return res, nil
+}
+
+func (r *branchRepo) ListLinkedByRemoteID(ctx context.Context, remoteID string) ([]*remote.Branch, error) {
+	var res []*remote.Branch
+	if err := r.db.SelectContext(ctx, &res, `SELECT * FROM remote_branches WHERE remote_id = $1 AND linked ORDER BY name`, remoteID); err != nil {
+		return nil, fmt.Errorf("failed to ListLinkedByRemoteID: %w", err)
+	}
+	return res, nil
+}
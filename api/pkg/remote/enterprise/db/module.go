@@ -6,4 +6,5 @@ import (
 
 func Module(c *di.Container) {
 	c.Register(New)
+	c.Register(NewBranchRepository)
 }
\ No newline at end of file
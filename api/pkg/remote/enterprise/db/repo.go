@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -11,9 +12,25 @@ import (
 )
 
 type Repository interface {
-	GetByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error)
+	Get(ctx context.Context, id string) (*remote.Remote, error)
+	// GetPullByCodebaseID returns the remote that trunk of the codebase is pulled from.
+	GetPullByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error)
+	ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*remote.Remote, error)
 	Create(ctx context.Context, r remote.Remote) error
+	// Update saves the settings of the remote. The results of pushes and polls are not saved by Update, see
+	// SetPushResult and SetPollResult.
 	Update(ctx context.Context, r *remote.Remote) error
+	// SetPushResult saves the result of the latest push.
+	SetPushResult(ctx context.Context, id string, pushedAt time.Time, pushError *string) error
+	// SetPollResult saves the result of the latest poll. The next poll is only scheduled at nextPollAt if the
+	// poll interval is still pollInterval, the remote has been rescheduled if the interval was updated.
+	SetPollResult(ctx context.Context, id string, polledAt time.Time, pollError *string, failures int, pollInterval *time.Duration, nextPollAt time.Time) error
+	Delete(ctx context.Context, id string) error
+	// SetKnownHosts sets the pinned and the pending host keys of the remote. The keys are not saved by Update.
+	SetKnownHosts(ctx context.Context, id string, knownHost, pendingKnownHost *string) error
+	// ClaimDueForPolling returns the enabled pull remotes that are due to be polled at now, and postpones
+	// their next poll until leaseUntil, so that they are not polled by other instances in the meantime.
+	ClaimDueForPolling(ctx context.Context, now, leaseUntil time.Time) ([]*remote.Remote, error)
 }
 
 func New(db *sqlx.DB) Repository {
@@ -24,18 +41,36 @@ type repo struct {
 	db *sqlx.DB
 }
 
-func (r *repo) GetByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error) {
+func (r *repo) Get(ctx context.Context, id string) (*remote.Remote, error) {
 	var res remote.Remote
-	err := r.db.GetContext(ctx, &res, `SELECT * FROM remotes WHERE codebase_id = $1`, codebaseID)
+	err := r.db.GetContext(ctx, &res, `SELECT * FROM remotes WHERE id = $1`, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetByCodebaseID: %w", err)
+		return nil, fmt.Errorf("failed to Get: %w", err)
 	}
 	return &res, nil
 }
 
+func (r *repo) GetPullByCodebaseID(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error) {
+	var res remote.Remote
+	err := r.db.GetContext(ctx, &res, `SELECT * FROM remotes WHERE codebase_id = $1 AND direction = $2`, codebaseID, remote.DirectionPull)
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetPullByCodebaseID: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *repo) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*remote.Remote, error) {
+	var res []*remote.Remote
+	err := r.db.SelectContext(ctx, &res, `SELECT * FROM remotes WHERE codebase_id = $1 ORDER BY name`, codebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListByCodebaseID: %w", err)
+	}
+	return res, nil
+}
+
 func (r *repo) Create(ctx context.Context, val remote.Remote) error {
-	_, err := r.db.NamedExecContext(ctx, `INSERT INTO remotes (id, codebase_id, name, url, basic_username, basic_password, tracked_branch, browser_link_repo, browser_link_branch, keypair_id, enabled)
-		VALUES(:id, :codebase_id, :name, :url, :basic_username, :basic_password, :tracked_branch, :browser_link_repo, :browser_link_branch, :keypair_id, :enabled)`, val)
+	_, err := r.db.NamedExecContext(ctx, `INSERT INTO remotes (id, codebase_id, name, url, basic_username, basic_password, direction, tracked_branch, browser_link_repo, browser_link_branch, keypair_id, enabled, poll_interval, next_poll_at)
+		VALUES(:id, :codebase_id, :name, :url, :basic_username, :basic_password, :direction, :tracked_branch, :browser_link_repo, :browser_link_branch, :keypair_id, :enabled, :poll_interval, :next_poll_at)`, val)
 	if err != nil {
 		return fmt.Errorf("failed to create remote: %w", err)
 	}
@@ -49,14 +84,70 @@ func (r *repo) Update(ctx context.Context, val *remote.Remote) error {
 	        url = :url,
 	        basic_username = :basic_username,
 			basic_password = :basic_password,
+			direction = :direction,
 			tracked_branch = :tracked_branch,
 			browser_link_repo = :browser_link_repo, 
 			browser_link_branch = :browser_link_branch,
 			keypair_id = :keypair_id,
-			enabled = :enabled
+			enabled = :enabled,
+			poll_interval = :poll_interval,
+			next_poll_at = :next_poll_at,
+			poll_failures = :poll_failures
 		WHERE id = :id`, val)
 	if err != nil {
 		return fmt.Errorf("failed to update remote: %w", err)
 	}
 	return nil
 }
+
+func (r *repo) SetPushResult(ctx context.Context, id string, pushedAt time.Time, pushError *string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE remotes SET last_pushed_at = $2, last_push_error = $3 WHERE id = $1`, id, pushedAt, pushError); err != nil {
+		return fmt.Errorf("failed to set push result: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) SetPollResult(ctx context.Context, id string, polledAt time.Time, pollError *string, failures int, pollInterval *time.Duration, nextPollAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE remotes
+		SET last_polled_at = $2,
+			last_poll_error = $3,
+			poll_failures = $4,
+			next_poll_at = CASE WHEN poll_interval IS NOT DISTINCT FROM $5 THEN $6 ELSE next_poll_at END
+		WHERE id = $1`, id, polledAt, pollError, failures, pollInterval, nextPollAt); err != nil {
+		return fmt.Errorf("failed to set poll result: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM remotes WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete remote: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) SetKnownHosts(ctx context.Context, id string, knownHost, pendingKnownHost *string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE remotes SET known_host = $2, pending_known_host = $3 WHERE id = $1`, id, knownHost, pendingKnownHost); err != nil {
+		return fmt.Errorf("failed to set known hosts: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) ClaimDueForPolling(ctx context.Context, now, leaseUntil time.Time) ([]*remote.Remote, error) {
+	var res []*remote.Remote
+	err := r.db.SelectContext(ctx, &res, `UPDATE remotes
+		SET next_poll_at = $3
+		WHERE id IN (
+			SELECT id FROM remotes
+			WHERE poll_interval IS NOT NULL
+			  AND enabled
+			  AND direction = $1
+			  AND (next_poll_at IS NULL OR next_poll_at <= $2)
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, remote.DirectionPull, now, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ClaimDueForPolling: %w", err)
+	}
+	return res, nil
+}
@@ -2,6 +2,8 @@ package graphql
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
 
@@ -27,6 +29,19 @@ func (r *resolver) URL() string {
 	return r.remote.URL
 }
 
+func (r *resolver) Direction() (resolvers.RemoteDirection, error) {
+	switch r.remote.Direction {
+	case remote.DirectionPull:
+		return resolvers.RemoteDirectionPull, nil
+	case remote.DirectionPush:
+		return resolvers.RemoteDirectionPush, nil
+	case remote.DirectionMirror:
+		return resolvers.RemoteDirectionMirror, nil
+	default:
+		return resolvers.RemoteDirectionUndefined, gqlerrors.Error(fmt.Errorf("unknown direction: %s", r.remote.Direction))
+	}
+}
+
 func (r *resolver) TrackedBranch() string {
 	return r.remote.TrackedBranch
 }
@@ -61,3 +76,163 @@ func (r *resolver) KeyPair(ctx context.Context) (resolvers.KeyPairResolver, erro
 func (r *resolver) Enabled() bool {
 	return r.remote.Enabled
 }
+
+func (r *resolver) LastPushedAt() *int32 {
+	return unix(r.remote.LastPushedAt)
+}
+
+func (r *resolver) LastPushError() *string {
+	return r.remote.LastPushError
+}
+
+func (r *resolver) PollIntervalSeconds() *int32 {
+	if r.remote.PollInterval == nil {
+		return nil
+	}
+	seconds := int32(r.remote.PollInterval.Seconds())
+	return &seconds
+}
+
+func (r *resolver) Health() resolvers.RemoteHealthResolver {
+	return &healthResolver{remote: r.remote}
+}
+
+type healthResolver struct {
+	remote *remote.Remote
+}
+
+func (r *healthResolver) Status() (resolvers.RemoteHealthStatus, error) {
+	switch r.remote.Health() {
+	case remote.HealthStatusUnknown:
+		return resolvers.RemoteHealthStatusUnknown, nil
+	case remote.HealthStatusHealthy:
+		return resolvers.RemoteHealthStatusHealthy, nil
+	case remote.HealthStatusFailing:
+		return resolvers.RemoteHealthStatusFailing, nil
+	default:
+		return resolvers.RemoteHealthStatusUndefined, gqlerrors.Error(fmt.Errorf("unknown health status: %s", r.remote.Health()))
+	}
+}
+
+func (r *healthResolver) LastPolledAt() *int32 {
+	return unix(r.remote.LastPolledAt)
+}
+
+func (r *healthResolver) NextPollAt() *int32 {
+	if r.remote.PollInterval == nil {
+		return nil
+	}
+	return unix(r.remote.NextPollAt)
+}
+
+func (r *healthResolver) LastPollError() *string {
+	return r.remote.LastPollError
+}
+
+func (r *healthResolver) PollFailures() int32 {
+	return int32(r.remote.PollFailures)
+}
+
+func unix(t *time.Time) *int32 {
+	if t == nil {
+		return nil
+	}
+	u := int32(t.Unix())
+	return &u
+}
+
+func (r *resolver) HostKey() (resolvers.RemoteHostKeyResolver, error) {
+	return hostKey(r.remote.KnownHost)
+}
+
+func (r *resolver) PendingHostKey() (resolvers.RemoteHostKeyResolver, error) {
+	return hostKey(r.remote.PendingKnownHost)
+}
+
+func hostKey(knownHost *string) (resolvers.RemoteHostKeyResolver, error) {
+	if knownHost == nil {
+		return nil, nil
+	}
+	key, err := remote.ParseHostKey(*knownHost)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return &hostKeyResolver{key: key}, nil
+}
+
+type hostKeyResolver struct {
+	key *remote.HostKey
+}
+
+func (r *hostKeyResolver) KnownHost() string {
+	return r.key.String()
+}
+
+func (r *hostKeyResolver) Fingerprint() string {
+	return r.key.Fingerprint()
+}
+
+func (r *hostKeyResolver) KeyType() string {
+	return r.key.Key.Type()
+}
+
+func (r *resolver) Branches(ctx context.Context) ([]resolvers.RemoteBranchHeadResolver, error) {
+	heads, err := r.root.service.ListBranches(ctx, r.remote)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	res := make([]resolvers.RemoteBranchHeadResolver, 0, len(heads))
+	for _, head := range heads {
+		res = append(res, &branchHeadResolver{head: head})
+	}
+	return res, nil
+}
+
+type branchHeadResolver struct {
+	head *remote.BranchHead
+}
+
+func (r *branchHeadResolver) Name() string {
+	return r.head.Name
+}
+
+func (r *branchHeadResolver) CommitSHA() string {
+	return r.head.CommitSHA
+}
+
+type branchResolver struct {
+	branch *remote.Branch
+	root   *remoteRootResolver
+}
+
+func (r *branchResolver) ID() graphql.ID {
+	return graphql.ID(r.branch.ID)
+}
+
+func (r *branchResolver) Name() string {
+	return r.branch.Name
+}
+
+func (r *branchResolver) HeadSHA() string {
+	return r.branch.HeadSHA
+}
+
+func (r *branchResolver) Linked() bool {
+	return r.branch.Linked
+}
+
+func (r *branchResolver) Remote(ctx context.Context) (resolvers.RemoteResolver, error) {
+	rem, err := r.root.service.GetByID(ctx, r.branch.RemoteID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+	return &resolver{remote: rem, root: r.root}, nil
+}
+
+func (r *branchResolver) CreatedAt() int32 {
+	return int32(r.branch.CreatedAt.Unix())
+}
+
+func (r *branchResolver) UpdatedAt() *int32 {
+	return unix(r.branch.UpdatedAt)
+}
@@ -2,14 +2,19 @@ package graphql
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"time"
 
+	"github.com/graph-gophers/graphql-go"
+
+	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
 	service_codebase "getsturdy.com/api/pkg/codebases/service"
 	"getsturdy.com/api/pkg/crypto"
 	gqlerror "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/remote"
 	"getsturdy.com/api/pkg/remote/enterprise/service"
 	service_user "getsturdy.com/api/pkg/users/service"
 	service_workspace "getsturdy.com/api/pkg/workspaces/service"
@@ -22,6 +27,8 @@ type remoteRootResolver struct {
 	codebaseService    *service_codebase.Service
 	userService        service_user.Service
 	cryptoRootResolver resolvers.CryptoRootResolver
+
+	workspaceRootResolver *resolvers.WorkspaceRootResolver
 }
 
 func New(
@@ -31,6 +38,8 @@ func New(
 	codebaseService *service_codebase.Service,
 	userService service_user.Service,
 	cryptoRootResolver resolvers.CryptoRootResolver,
+
+	workspaceRootResolver *resolvers.WorkspaceRootResolver,
 ) resolvers.RemoteRootResolver {
 	return &remoteRootResolver{
 		service:            service,
@@ -39,6 +48,8 @@ func New(
 		codebaseService:    codebaseService,
 		userService:        userService,
 		cryptoRootResolver: cryptoRootResolver,
+
+		workspaceRootResolver: workspaceRootResolver,
 	}
 }
 
@@ -60,6 +71,37 @@ func (r *remoteRootResolver) InternalRemoteByCodebaseID(ctx context.Context, cod
 	return &resolver{remote: rem, root: r}, nil
 }
 
+func (r *remoteRootResolver) InternalRemotesByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]resolvers.RemoteResolver, error) {
+	cb, err := r.codebaseService.GetByID(ctx, codebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, cb); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	rr, err := r.service.List(ctx, codebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.RemoteResolver, 0, len(rr))
+	for _, rem := range rr {
+		res = append(res, &resolver{remote: rem, root: r})
+	}
+	return res, nil
+}
+
+// InternalRemoteBranchByWorkspaceID is only to be used in contexts where the request is already authenticated
+func (r *remoteRootResolver) InternalRemoteBranchByWorkspaceID(ctx context.Context, workspaceID string) (resolvers.RemoteBranchResolver, error) {
+	branch, err := r.service.GetBranchByWorkspaceID(ctx, workspaceID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+	return &branchResolver{branch: branch, root: r}, nil
+}
+
 func (r *remoteRootResolver) CreateOrUpdateCodebaseRemote(ctx context.Context, args resolvers.CreateOrUpdateCodebaseRemoteArgsArgs) (resolvers.RemoteResolver, error) {
 	codebaseID := codebases.ID(args.Input.CodebaseID)
 	cb, err := r.codebaseService.GetByID(ctx, codebaseID)
@@ -90,11 +132,237 @@ func (r *remoteRootResolver) CreateOrUpdateCodebaseRemote(ctx context.Context, a
 			BrowserLinkBranch: args.Input.BrowserLinkBranch,
 			KeyPairID:         keyPairID,
 			Enabled:           args.Input.Enabled,
+			PollInterval:      toPollInterval(args.Input.PollIntervalSeconds),
 		},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to add remote: %w", err)
+		return nil, toGqlError(err)
 	}
 
 	return &resolver{remote: rem, root: r}, nil
 }
+
+func (r *remoteRootResolver) CreateCodebaseRemote(ctx context.Context, args resolvers.CreateCodebaseRemoteArgs) (resolvers.RemoteResolver, error) {
+	codebaseID := codebases.ID(args.Input.CodebaseID)
+	cb, err := r.codebaseService.GetByID(ctx, codebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, cb); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	direction, err := toDirection(args.Input.Direction)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	rem, err := r.service.Create(ctx, codebaseID, &service.SetRemoteInput{
+		Name:              args.Input.Name,
+		URL:               args.Input.Url,
+		Direction:         direction,
+		TrackedBranch:     args.Input.TrackedBranch,
+		BasicAuthUsername: args.Input.BasicAuthUsername,
+		BasicAuthPassword: args.Input.BasicAuthPassword,
+		KeyPairID:         toKeyPairID(args.Input.KeyPairID),
+		BrowserLinkRepo:   args.Input.BrowserLinkRepo,
+		BrowserLinkBranch: args.Input.BrowserLinkBranch,
+		Enabled:           args.Input.Enabled,
+		PollInterval:      toPollInterval(args.Input.PollIntervalSeconds),
+	})
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &resolver{remote: rem, root: r}, nil
+}
+
+func (r *remoteRootResolver) UpdateCodebaseRemote(ctx context.Context, args resolvers.UpdateCodebaseRemoteArgs) (resolvers.RemoteResolver, error) {
+	rem, err := r.get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	direction, err := toDirection(args.Input.Direction)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	rem, err = r.service.Update(ctx, rem.ID, &service.SetRemoteInput{
+		Name:              args.Input.Name,
+		URL:               args.Input.Url,
+		Direction:         direction,
+		TrackedBranch:     args.Input.TrackedBranch,
+		BasicAuthUsername: args.Input.BasicAuthUsername,
+		BasicAuthPassword: args.Input.BasicAuthPassword,
+		KeyPairID:         toKeyPairID(args.Input.KeyPairID),
+		BrowserLinkRepo:   args.Input.BrowserLinkRepo,
+		BrowserLinkBranch: args.Input.BrowserLinkBranch,
+		Enabled:           args.Input.Enabled,
+		PollInterval:      toPollInterval(args.Input.PollIntervalSeconds),
+	})
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &resolver{remote: rem, root: r}, nil
+}
+
+func (r *remoteRootResolver) DeleteCodebaseRemote(ctx context.Context, args resolvers.DeleteCodebaseRemoteArgs) (resolvers.RemoteResolver, error) {
+	rem, err := r.get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	rem, err = r.service.Delete(ctx, rem.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{remote: rem, root: r}, nil
+}
+
+func (r *remoteRootResolver) ConfirmRemoteHostKey(ctx context.Context, args resolvers.ConfirmRemoteHostKeyArgs) (resolvers.RemoteResolver, error) {
+	rem, err := r.get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	rem, err = r.service.ConfirmHostKey(ctx, rem.ID, args.Input.Fingerprint)
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &resolver{remote: rem, root: r}, nil
+}
+
+func (r *remoteRootResolver) RotateRemoteHostKey(ctx context.Context, args resolvers.RotateRemoteHostKeyArgs) (resolvers.RemoteResolver, error) {
+	subject, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, gqlerror.Error(auth.ErrUnauthenticated)
+	}
+	if subject.IsPersonalAccessToken() {
+		return nil, gqlerror.Error(auth.ErrForbidden)
+	}
+
+	rem, err := r.get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if _, err := r.authService.CanAdministrateInstallation(ctx); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	rem, err = r.service.RotateHostKey(ctx, rem.ID, service.RotateHostKeyInput{
+		Fingerprint: args.Input.Fingerprint,
+		KnownHost:   args.Input.KnownHost,
+	})
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	return &resolver{remote: rem, root: r}, nil
+}
+
+func (r *remoteRootResolver) ImportRemoteBranches(ctx context.Context, args resolvers.ImportRemoteBranchesArgs) ([]resolvers.WorkspaceResolver, error) {
+	rem, err := r.get(ctx, string(args.Input.RemoteID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	imported, err := r.service.ImportBranches(ctx, rem, userID, service.ImportBranchesInput{
+		Branches: args.Input.Branches,
+		Link:     args.Input.Link,
+	})
+	if err != nil {
+		return nil, toGqlError(err)
+	}
+
+	res := make([]resolvers.WorkspaceResolver, 0, len(imported))
+	for _, ws := range imported {
+		res = append(res, (*r.workspaceRootResolver).InternalWorkspace(ws))
+	}
+	return res, nil
+}
+
+// get returns the remote, if the user is allowed to write to its codebase.
+func (r *remoteRootResolver) get(ctx context.Context, id string) (*remote.Remote, error) {
+	rem, err := r.service.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cb, err := r.codebaseService.GetByID(ctx, rem.CodebaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.authService.CanWrite(ctx, cb); err != nil {
+		return nil, err
+	}
+
+	return rem, nil
+}
+
+func toDirection(direction resolvers.RemoteDirection) (remote.Direction, error) {
+	switch direction {
+	case resolvers.RemoteDirectionPull:
+		return remote.DirectionPull, nil
+	case resolvers.RemoteDirectionPush:
+		return remote.DirectionPush, nil
+	case resolvers.RemoteDirectionMirror:
+		return remote.DirectionMirror, nil
+	default:
+		return "", gqlerror.Error(gqlerror.ErrBadRequest, "direction", "unknown direction")
+	}
+}
+
+func toKeyPairID(id *graphql.ID) *crypto.KeyPairID {
+	if id == nil {
+		return nil
+	}
+	kpi := crypto.KeyPairID(*id)
+	return &kpi
+}
+
+func toPollInterval(seconds *int32) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	interval := time.Duration(*seconds) * time.Second
+	return &interval
+}
+
+func toGqlError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNameTaken):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "name", err.Error())
+	case errors.Is(err, service.ErrPullRemoteExists), errors.Is(err, service.ErrInvalidDirection):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "direction", err.Error())
+	case errors.Is(err, service.ErrInvalidPollInterval), errors.Is(err, service.ErrPollNotPull):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "pollIntervalSeconds", err.Error())
+	case errors.Is(err, service.ErrInvalidKnownHost):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "knownHost", err.Error())
+	case errors.Is(err, service.ErrFingerprintMismatch):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "fingerprint", err.Error())
+	case errors.Is(err, service.ErrHostKeyAlreadyConfirmed),
+		errors.Is(err, service.ErrNoPendingHostKey),
+		errors.Is(err, service.ErrInvalidRotateHostKeyInput):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "message", err.Error())
+	case errors.Is(err, service.ErrNoBranches),
+		errors.Is(err, service.ErrBranchNotFound),
+		errors.Is(err, service.ErrBranchAlreadyImported):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "branches", err.Error())
+	case errors.Is(err, service.ErrImportNotPull), errors.Is(err, service.ErrRemoteDisabled):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "remoteID", err.Error())
+	default:
+		return gqlerror.Error(err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"getsturdy.com/api/pkg/remote/enterprise/graphql"
 	"getsturdy.com/api/pkg/remote/enterprise/routes"
 	"getsturdy.com/api/pkg/remote/enterprise/service"
+	"getsturdy.com/api/pkg/remote/enterprise/worker"
 )
 
 func Module(c *di.Container) {
@@ -13,4 +14,5 @@ func Module(c *di.Container) {
 	c.Import(graphql.Module)
 	c.Import(service.Module)
 	c.Import(routes.Module)
+	c.Import(worker.Module)
 }
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/analytics"
+	"getsturdy.com/api/pkg/remote"
+	"getsturdy.com/api/pkg/snapshots"
+	"getsturdy.com/api/pkg/snapshots/snapshotter"
+	"getsturdy.com/api/pkg/users"
+	vcs_view "getsturdy.com/api/pkg/view/vcs"
+	"getsturdy.com/api/pkg/workspaces"
+	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
+	"getsturdy.com/api/vcs"
+)
+
+var (
+	ErrImportNotPull         = errors.New("branches can only be imported from the remote that trunk is pulled from")
+	ErrNoBranches            = errors.New("no branches to import")
+	ErrBranchNotFound        = errors.New("branch not found on the remote")
+	ErrBranchAlreadyImported = errors.New("branch has already been imported to a workspace")
+)
+
+// ListBranches returns the branches on the remote that can be imported as workspaces.
+func (svc *EnterpriseService) ListBranches(ctx context.Context, rem *remote.Remote) ([]*remote.BranchHead, error) {
+	creds, verifier, err := svc.newCredentialsCallback(ctx, rem)
+	if err != nil {
+		return nil, fmt.Errorf("could not get creds: %w", err)
+	}
+
+	// list the references of the remote without fetching anything, like git ls-remote
+	lister := gogit.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "anonymous",
+		URLs: []string{fixedURL(rem)},
+	})
+	refs, err := lister.ListContext(ctx, &gogit.ListOptions{Auth: creds})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", verifier.wrap(err))
+	}
+
+	return remote.BranchHeads(refs, rem.TrackedBranch), nil
+}
+
+func (svc *EnterpriseService) GetBranchByWorkspaceID(ctx context.Context, workspaceID string) (*remote.Branch, error) {
+	return svc.branchRepo.GetByWorkspaceID(ctx, workspaceID)
+}
+
+type ImportBranchesInput struct {
+	Branches []string
+	// Link keeps the workspaces up to date with the branches, until the workspaces are pushed from Sturdy.
+	Link bool
+}
+
+// ImportBranches creates a workspace for each of the branches on the remote. The changes of the branch are
+// unsaved changes in the workspace, on top of the commit where the branch diverged from trunk.
+func (svc *EnterpriseService) ImportBranches(ctx context.Context, rem *remote.Remote, userID users.ID, input ImportBranchesInput) ([]*workspaces.Workspace, error) {
+	if !rem.Enabled {
+		return nil, ErrRemoteDisabled
+	}
+	if rem.Direction != remote.DirectionPull {
+		return nil, ErrImportNotPull
+	}
+
+	names := uniqueNames(input.Branches)
+	if len(names) == 0 {
+		return nil, ErrNoBranches
+	}
+
+	heads, err := svc.ListBranches(ctx, rem)
+	if err != nil {
+		return nil, err
+	}
+	headsByName := make(map[string]*remote.BranchHead, len(heads))
+	for _, head := range heads {
+		headsByName[head.Name] = head
+	}
+
+	// validate all branches before importing any of them
+	for _, name := range names {
+		if _, ok := headsByName[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, name)
+		}
+		if err := svc.checkNotImported(ctx, rem, name); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]*workspaces.Workspace, 0, len(names))
+	for _, name := range names {
+		svc.logger.Info("importing remote branch",
+			zap.Stringer("codebase_id", rem.CodebaseID),
+			zap.String("remote_id", rem.ID),
+			zap.String("branch", name),
+		)
+
+		ws, err := svc.importBranch(ctx, rem, userID, name, input.Link)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import branch %s: %w", name, err)
+		}
+		res = append(res, ws)
+	}
+
+	svc.analyticsService.Capture(ctx, "imported remote branches",
+		analytics.CodebaseID(rem.CodebaseID),
+		analytics.Property("remote_name", rem.Name),
+		analytics.Property("branches", len(res)),
+		analytics.Property("linked", input.Link),
+	)
+
+	return res, nil
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		res = append(res, name)
+	}
+	return res
+}
+
+// checkNotImported returns ErrBranchAlreadyImported if the branch has been imported to a workspace that is not
+// archived.
+func (svc *EnterpriseService) checkNotImported(ctx context.Context, rem *remote.Remote, name string) error {
+	imported, err := svc.branchRepo.ListByRemoteIDAndName(ctx, rem.ID, name)
+	if err != nil {
+		return fmt.Errorf("failed to list imported branches: %w", err)
+	}
+	for _, branch := range imported {
+		ws, err := svc.workspaceReader.Get(branch.WorkspaceID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return fmt.Errorf("failed to get workspace: %w", err)
+		case ws.ArchivedAt == nil:
+			return fmt.Errorf("%w: %s", ErrBranchAlreadyImported, name)
+		}
+	}
+	return nil
+}
+
+func (svc *EnterpriseService) importBranch(ctx context.Context, rem *remote.Remote, userID users.ID, name string, link bool) (*workspaces.Workspace, error) {
+	branchName := name
+	t := time.Now()
+	ws := workspaces.Workspace{
+		ID:         uuid.NewString(),
+		CodebaseID: rem.CodebaseID,
+		UserID:     userID,
+		Name:       &branchName,
+		CreatedAt:  &t,
+	}
+	if err := svc.workspaceWriter.Create(ws); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	headSHA, err := svc.fetchAndSnapshotBranch(ctx, rem, &ws, name)
+	if err != nil {
+		// import failed, archive the workspace that we created
+		if err := svc.workspaceWriter.UpdateFields(ctx, ws.ID, db_workspaces.SetArchivedAt(&t)); err != nil {
+			return nil, fmt.Errorf("failed to archive workspace after failed import: %w", err)
+		}
+		return nil, err
+	}
+
+	if err := svc.branchRepo.Create(ctx, &remote.Branch{
+		ID:          uuid.NewString(),
+		RemoteID:    rem.ID,
+		CodebaseID:  rem.CodebaseID,
+		WorkspaceID: ws.ID,
+		Name:        name,
+		HeadSHA:     headSHA,
+		Linked:      link,
+		CreatedAt:   t,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save imported branch: %w", err)
+	}
+
+	// get the workspace again, to get the latest snapshot
+	imported, err := svc.workspaceReader.Get(ws.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	return imported, nil
+}
+
+// syncLinkedBranches updates the workspaces of the linked branches of the remote that have been updated since
+// they were last imported. Branches that have been deleted from the remote, or whose workspace has been
+// archived, are unlinked.
+func (svc *EnterpriseService) syncLinkedBranches(ctx context.Context, rem *remote.Remote) error {
+	branches, err := svc.branchRepo.ListLinkedByRemoteID(ctx, rem.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list linked branches: %w", err)
+	}
+	if len(branches) == 0 {
+		return nil
+	}
+
+	heads, err := svc.ListBranches(ctx, rem)
+	if err != nil {
+		return err
+	}
+	headsByName := make(map[string]*remote.BranchHead, len(heads))
+	for _, head := range heads {
+		headsByName[head.Name] = head
+	}
+
+	for _, branch := range branches {
+		if err := svc.syncLinkedBranch(ctx, rem, branch, headsByName[branch.Name]); err != nil {
+			svc.logger.Error("failed to sync linked branch",
+				zap.Stringer("codebase_id", rem.CodebaseID),
+				zap.String("workspace_id", branch.WorkspaceID),
+				zap.String("branch", branch.Name),
+				zap.Error(err),
+			)
+			// do not fail, sync the other branches
+		}
+	}
+
+	return nil
+}
+
+// syncLinkedBranch updates the workspace of the branch. head is the branch on the remote, and is nil if the
+// branch has been deleted.
+func (svc *EnterpriseService) syncLinkedBranch(ctx context.Context, rem *remote.Remote, branch *remote.Branch, head *remote.BranchHead) error {
+	if head == nil {
+		return svc.unlinkBranch(ctx, branch)
+	}
+	if head.CommitSHA == branch.HeadSHA {
+		return nil
+	}
+
+	ws, err := svc.workspaceReader.Get(branch.WorkspaceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return svc.unlinkBranch(ctx, branch)
+	case err != nil:
+		return fmt.Errorf("failed to get workspace: %w", err)
+	case ws.ArchivedAt != nil:
+		return svc.unlinkBranch(ctx, branch)
+	}
+
+	headSHA, err := svc.fetchAndSnapshotBranch(ctx, rem, ws, branch.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	branch.HeadSHA = headSHA
+	branch.UpdatedAt = &now
+	if err := svc.branchRepo.Update(ctx, branch); err != nil {
+		return fmt.Errorf("failed to update linked branch: %w", err)
+	}
+
+	return nil
+}
+
+func (svc *EnterpriseService) unlinkBranch(ctx context.Context, branch *remote.Branch) error {
+	now := time.Now()
+	branch.Linked = false
+	branch.UpdatedAt = &now
+	if err := svc.branchRepo.Update(ctx, branch); err != nil {
+		return fmt.Errorf("failed to unlink branch: %w", err)
+	}
+	return nil
+}
+
+// fetchAndSnapshotBranch fetches the branch from the remote, and snapshots it in the workspace. The changes of
+// the branch are unsaved changes in the workspace, on top of the merge-base of the branch and trunk. Returns the
+// commit that the branch pointed to.
+func (svc *EnterpriseService) fetchAndSnapshotBranch(ctx context.Context, rem *remote.Remote, ws *workspaces.Workspace, name string) (string, error) {
+	importBranchName := fmt.Sprintf("import-remote-branch-%s", uuid.NewString())
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/heads/%s", name, importBranchName)
+
+	creds, verifier, err := svc.newCredentialsCallback(ctx, rem)
+	if err != nil {
+		return "", fmt.Errorf("could not get creds: %w", err)
+	}
+
+	url := fixedURL(rem)
+	var branchHead, commonAncestor string
+	if err := svc.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+		if err := repo.FetchUrlRemoteWithCreds(url, creds, []config.RefSpec{config.RefSpec(refspec)}); err != nil {
+			return fmt.Errorf("failed to fetch branch: %w", verifier.wrap(err))
+		}
+
+		head, err := repo.HeadCommit()
+		if err != nil {
+			return fmt.Errorf("could not get head: %w", err)
+		}
+
+		branchHead, err = repo.BranchCommitID(importBranchName)
+		if err != nil {
+			return fmt.Errorf("could not get head of branch: %w", err)
+		}
+
+		commonAncestor, err = repo.CommonAncestor(head.Id().String(), branchHead)
+		if err != nil {
+			return fmt.Errorf("could not find common ancestor: %w", err)
+		}
+
+		// create, or move, the workspace branch
+		if err := repo.CreateNewBranchAt(ws.ID, commonAncestor); err != nil {
+			return fmt.Errorf("failed to create workspace branch: %w", err)
+		}
+
+		return nil
+	}).ExecTrunk(ws.CodebaseID, "remoteImportBranch"); err != nil {
+		return "", fmt.Errorf("failed to prepare workspace branch: %w", err)
+	}
+
+	if err := svc.executorProvider.New().
+		Write(vcs_view.CheckoutBranch(importBranchName)).
+		Write(func(repo vcs.RepoWriter) error {
+			// reset to the common ancestor, to make all changes on the branch unsaved changes
+			if err := repo.ResetMixed(commonAncestor); err != nil {
+				return fmt.Errorf("failed to reset temporary view to common ancestor: %w", err)
+			}
+
+			if _, err := svc.snap.Snapshot(ws.CodebaseID, ws.ID,
+				snapshots.ActionSyncCompleted,
+				snapshotter.WithMarkAsLatestInWorkspace(),
+				snapshotter.WithOnView(*repo.ViewID()),
+				snapshotter.WithOnRepo(repo), // Re-use repo context
+			); err != nil {
+				return fmt.Errorf("failed to create snapshot: %w", err)
+			}
+
+			return nil
+		}).ExecTemporaryView(ws.CodebaseID, "remoteImportBranch"); err != nil {
+		return "", fmt.Errorf("failed to create workspace from branch: %w", err)
+	}
+
+	if err := svc.executorProvider.New().GitWrite(func(repo vcs.RepoGitWriter) error {
+		if err := repo.DeleteBranch(importBranchName); err != nil {
+			return fmt.Errorf("failed to delete import branch: %w", err)
+		}
+		return nil
+	}).ExecTrunk(ws.CodebaseID, "remoteImportBranchCleanup"); err != nil {
+		return "", fmt.Errorf("failed to cleanup import branch: %w", err)
+	}
+
+	return branchHead, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+
+	"getsturdy.com/api/pkg/audit"
+	"getsturdy.com/api/pkg/remote"
+	db_remote "getsturdy.com/api/pkg/remote/enterprise/db"
+)
+
+var (
+	ErrHostKeyAlreadyConfirmed = errors.New("the remote already has a confirmed host key, it can only be rotated by an administrator")
+	ErrNoPendingHostKey        = errors.New("the remote has not presented a new host key, pull from or push to the remote first")
+	ErrFingerprintMismatch     = errors.New("the fingerprint does not match the host key presented by the remote")
+)
+
+// hostKeyVerifier verifies the host key that is presented when connecting to an SSH remote. Unknown keys are
+// saved as pending, so that they can be confirmed by a user.
+type hostKeyVerifier struct {
+	ctx    context.Context
+	repo   db_remote.Repository
+	remote *remote.Remote
+
+	err error
+}
+
+func (v *hostKeyVerifier) callback(hostname string, _ net.Addr, key ssh.PublicKey) error {
+	verifyErr := v.remote.VerifyHostKey(hostname, key)
+	if verifyErr == nil {
+		return nil
+	}
+
+	pending := remote.NewHostKey(hostname, key).String()
+	if v.remote.PendingKnownHost == nil || *v.remote.PendingKnownHost != pending {
+		if err := v.repo.SetKnownHosts(v.ctx, v.remote.ID, v.remote.KnownHost, &pending); err != nil {
+			v.err = fmt.Errorf("failed to save pending host key: %w", err)
+			return v.err
+		}
+		v.remote.PendingKnownHost = &pending
+	}
+
+	v.err = verifyErr
+	return v.err
+}
+
+// wrap returns the host key verification error if the connection was refused because of the host key. The
+// ssh client does not wrap the errors that are returned by the callback.
+func (v *hostKeyVerifier) wrap(err error) error {
+	if v != nil && v.err != nil {
+		return v.err
+	}
+	return err
+}
+
+// ConfirmHostKey pins the pending host key of a remote that does not have a pinned key yet. The fingerprint
+// must match the pending key, to make sure that the user confirmed the key that is pinned.
+func (svc *EnterpriseService) ConfirmHostKey(ctx context.Context, id string, fingerprint string) (*remote.Remote, error) {
+	rem, err := svc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	if rem.KnownHost != nil {
+		return nil, ErrHostKeyAlreadyConfirmed
+	}
+
+	pending, err := pendingHostKey(rem, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := svc.pinHostKey(ctx, rem, pending, audit.ActionRemoteHostKeyConfirmed); err != nil {
+		return nil, err
+	}
+
+	return rem, nil
+}
+
+type RotateHostKeyInput struct {
+	// Fingerprint of the pending host key to pin.
+	Fingerprint *string
+	// KnownHost is a host key in the known_hosts format to pin, for rotating the key before the remote
+	// presents it.
+	KnownHost *string
+}
+
+var (
+	ErrInvalidRotateHostKeyInput = errors.New("either fingerprint or knownHost must be set")
+	ErrInvalidKnownHost          = errors.New("invalid known_hosts line")
+)
+
+// RotateHostKey replaces the pinned host key of the remote.
+func (svc *EnterpriseService) RotateHostKey(ctx context.Context, id string, input RotateHostKeyInput) (*remote.Remote, error) {
+	rem, err := svc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	var hostKey *remote.HostKey
+	switch {
+	case input.Fingerprint != nil && input.KnownHost == nil:
+		if hostKey, err = pendingHostKey(rem, *input.Fingerprint); err != nil {
+			return nil, err
+		}
+	case input.KnownHost != nil && input.Fingerprint == nil:
+		if hostKey, err = remote.ParseHostKey(*input.KnownHost); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidKnownHost, err)
+		}
+	default:
+		return nil, ErrInvalidRotateHostKeyInput
+	}
+
+	if err := svc.pinHostKey(ctx, rem, hostKey, audit.ActionRemoteHostKeyRotated); err != nil {
+		return nil, err
+	}
+
+	return rem, nil
+}
+
+func pendingHostKey(rem *remote.Remote, fingerprint string) (*remote.HostKey, error) {
+	if rem.PendingKnownHost == nil {
+		return nil, ErrNoPendingHostKey
+	}
+	pending, err := remote.ParseHostKey(*rem.PendingKnownHost)
+	if err != nil {
+		return nil, err
+	}
+	if pending.Fingerprint() != fingerprint {
+		return nil, ErrFingerprintMismatch
+	}
+	return pending, nil
+}
+
+func (svc *EnterpriseService) pinHostKey(ctx context.Context, rem *remote.Remote, hostKey *remote.HostKey, action audit.Action) error {
+	before := rem.KnownHost
+
+	knownHost := hostKey.String()
+	if err := svc.repo.SetKnownHosts(ctx, rem.ID, &knownHost, nil); err != nil {
+		return fmt.Errorf("failed to pin host key: %w", err)
+	}
+	rem.KnownHost = &knownHost
+	rem.PendingKnownHost = nil
+
+	if err := svc.auditService.Record(ctx, action, audit.TargetRemote, rem.ID,
+		audit.CodebaseID(rem.CodebaseID),
+		audit.Before(before),
+		audit.After(rem.KnownHost),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
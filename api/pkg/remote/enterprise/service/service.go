@@ -15,10 +15,11 @@ import (
 	"github.com/google/uuid"
 	git "github.com/libgit2/git2go/v33"
 	"go.uber.org/zap"
-	ssh2 "golang.org/x/crypto/ssh"
 
 	"getsturdy.com/api/pkg/analytics"
 	analytics_service "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/changes/message"
 	service_change "getsturdy.com/api/pkg/changes/service"
 	vcs_change "getsturdy.com/api/pkg/changes/vcs"
@@ -38,6 +39,7 @@ import (
 
 type EnterpriseService struct {
 	repo              db_remote.Repository
+	branchRepo        db_remote.BranchRepository
 	executorProvider  executor.Provider
 	logger            *zap.Logger
 	workspaceReader   db_workspaces.WorkspaceReader
@@ -46,12 +48,14 @@ type EnterpriseService struct {
 	changeService     *service_change.Service
 	analyticsService  *analytics_service.Service
 	keyPairRepository db_crypto.KeyPairRepository
+	auditService      *service_audit.Service
 }
 
 var _ service.Service = (*EnterpriseService)(nil)
 
 func New(
 	repo db_remote.Repository,
+	branchRepo db_remote.BranchRepository,
 	executorProvider executor.Provider,
 	logger *zap.Logger,
 	workspaceReader db_workspaces.WorkspaceReader,
@@ -60,9 +64,11 @@ func New(
 	changeService *service_change.Service,
 	analyticsService *analytics_service.Service,
 	keyPairRepository db_crypto.KeyPairRepository,
+	auditService *service_audit.Service,
 ) *EnterpriseService {
 	return &EnterpriseService{
 		repo:              repo,
+		branchRepo:        branchRepo,
 		executorProvider:  executorProvider,
 		logger:            logger,
 		workspaceReader:   workspaceReader,
@@ -71,33 +77,67 @@ func New(
 		changeService:     changeService,
 		analyticsService:  analyticsService,
 		keyPairRepository: keyPairRepository,
+		auditService:      auditService,
 	}
 }
 
+// Get returns the remote that trunk of the codebase is pulled from.
 func (svc *EnterpriseService) Get(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error) {
-	rep, err := svc.repo.GetByCodebaseID(ctx, codebaseID)
+	rep, err := svc.repo.GetPullByCodebaseID(ctx, codebaseID)
 	if err != nil {
 		return nil, err
 	}
 	return rep, nil
 }
 
-func (svc *EnterpriseService) GetWithFixedURL(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error) {
-	rep, err := svc.repo.GetByCodebaseID(ctx, codebaseID)
+func (svc *EnterpriseService) GetByID(ctx context.Context, id string) (*remote.Remote, error) {
+	rep, err := svc.repo.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	return rep, nil
+}
 
-	if rep.KeyPairID != nil {
-		rep.URL = rewriteSshUrl(rep.URL)
+func (svc *EnterpriseService) List(ctx context.Context, codebaseID codebases.ID) ([]*remote.Remote, error) {
+	rr, err := svc.repo.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+func (svc *EnterpriseService) GetWithFixedURL(ctx context.Context, codebaseID codebases.ID) (*remote.Remote, error) {
+	rep, err := svc.repo.GetPullByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, err
 	}
 
+	rep.URL = fixedURL(rep)
+
 	return rep, nil
 }
 
+// fixedURL returns the URL that is used to connect to the remote.
+func fixedURL(rep *remote.Remote) string {
+	if rep.KeyPairID != nil {
+		return rewriteSshUrl(rep.URL)
+	}
+	return rep.URL
+}
+
+var (
+	ErrNameTaken        = errors.New("a remote with this name already exists")
+	ErrPullRemoteExists = errors.New("the codebase already has a remote to pull from")
+	ErrInvalidDirection = errors.New("invalid direction")
+
+	ErrInvalidPollInterval = fmt.Errorf("poll interval must be at least %s", remote.MinPollInterval)
+	ErrPollNotPull         = errors.New("only remotes that trunk is pulled from can be polled")
+)
+
 type SetRemoteInput struct {
 	Name              string
 	URL               string
+	Direction         remote.Direction
 	TrackedBranch     string
 	BasicAuthUsername *string
 	BasicAuthPassword *string
@@ -105,17 +145,150 @@ type SetRemoteInput struct {
 	BrowserLinkRepo   string
 	BrowserLinkBranch string
 	Enabled           bool
+	// PollInterval is optional, and can only be set on remotes that trunk is pulled from.
+	PollInterval *time.Duration
 }
 
+// SetRemote creates or updates the remote that trunk of the codebase is pulled from.
 func (svc *EnterpriseService) SetRemote(ctx context.Context, codebaseID codebases.ID, input *SetRemoteInput) (*remote.Remote, error) {
+	input.Direction = remote.DirectionPull
+
+	// update existing if exists
+	rep, err := svc.repo.GetPullByCodebaseID(ctx, codebaseID)
+	switch {
+	case err == nil:
+		return svc.Update(ctx, rep.ID, input)
+	case errors.Is(err, sql.ErrNoRows):
+		return svc.Create(ctx, codebaseID, input)
+	default:
+		return nil, fmt.Errorf("failed to set remote: %w", err)
+	}
+}
+
+func (svc *EnterpriseService) Create(ctx context.Context, codebaseID codebases.ID, input *SetRemoteInput) (*remote.Remote, error) {
+	if err := svc.validate(ctx, codebaseID, nil, input); err != nil {
+		return nil, err
+	}
+
+	r := remote.Remote{
+		ID:                uuid.NewString(),
+		CodebaseID:        codebaseID,
+		Name:              input.Name,
+		URL:               input.URL,
+		Direction:         input.Direction,
+		TrackedBranch:     input.TrackedBranch,
+		BasicAuthUsername: input.BasicAuthUsername,
+		BasicAuthPassword: input.BasicAuthPassword,
+		KeyPairID:         input.KeyPairID,
+		BrowserLinkRepo:   input.BrowserLinkRepo,
+		BrowserLinkBranch: input.BrowserLinkBranch,
+		Enabled:           input.Enabled,
+		PollInterval:      input.PollInterval,
+	}
+	r.NextPollAt = nextPollAt(&r)
+
+	if err := svc.repo.Create(ctx, r); err != nil {
+		return nil, fmt.Errorf("failed to add remote: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionRemoteCreated, audit.TargetRemote, r.ID,
+		audit.CodebaseID(codebaseID),
+		audit.After(auditValue(&r)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "created remote integration", analytics.CodebaseID(codebaseID), analytics.Property("remote_name", r.Name))
+
+	return &r, nil
+}
+
+func (svc *EnterpriseService) Update(ctx context.Context, id string, input *SetRemoteInput) (*remote.Remote, error) {
+	rep, err := svc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	if err := svc.validate(ctx, rep.CodebaseID, &rep.ID, input); err != nil {
+		return nil, err
+	}
+
+	before := auditValue(rep)
+	urlChanged := rep.URL != input.URL
+
+	rep.Name = input.Name
+	rep.URL = input.URL
+	rep.Direction = input.Direction
+	rep.TrackedBranch = input.TrackedBranch
+	rep.BasicAuthUsername = input.BasicAuthUsername
+	rep.BasicAuthPassword = input.BasicAuthPassword
+	rep.KeyPairID = input.KeyPairID
+	rep.BrowserLinkRepo = input.BrowserLinkRepo
+	rep.BrowserLinkBranch = input.BrowserLinkBranch
+	rep.Enabled = input.Enabled
+	rep.PollInterval = input.PollInterval
+	// the remote might have been fixed, poll it right away
+	rep.PollFailures = 0
+	rep.NextPollAt = nextPollAt(rep)
+	if err := svc.repo.Update(ctx, rep); err != nil {
+		return nil, fmt.Errorf("failed to update remote: %w", err)
+	}
+
+	// the pending host key was presented by the old url. The pinned key is kept, it's pinned to a host name, so
+	// if the host has changed the new host is refused until an administrator pins its key with RotateHostKey.
+	if urlChanged && rep.PendingKnownHost != nil {
+		rep.PendingKnownHost = nil
+		if err := svc.repo.SetKnownHosts(ctx, rep.ID, rep.KnownHost, nil); err != nil {
+			return nil, fmt.Errorf("failed to reset pending host key: %w", err)
+		}
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionRemoteUpdated, audit.TargetRemote, rep.ID,
+		audit.CodebaseID(rep.CodebaseID),
+		audit.Before(before),
+		audit.After(auditValue(rep)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "updated remote integration", analytics.CodebaseID(rep.CodebaseID), analytics.Property("remote_name", rep.Name))
+
+	return rep, nil
+}
+
+func (svc *EnterpriseService) Delete(ctx context.Context, id string) (*remote.Remote, error) {
+	rep, err := svc.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	if err := svc.repo.Delete(ctx, rep.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete remote: %w", err)
+	}
+
+	if err := svc.auditService.Record(ctx, audit.ActionRemoteDeleted, audit.TargetRemote, rep.ID,
+		audit.CodebaseID(rep.CodebaseID),
+		audit.Before(auditValue(rep)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	svc.analyticsService.Capture(ctx, "deleted remote integration", analytics.CodebaseID(rep.CodebaseID), analytics.Property("remote_name", rep.Name))
+
+	return rep, nil
+}
+
+// validate validates the input, and makes sure that only the relevant auth fields are set. id is the remote
+// that is being updated, and is nil for new remotes.
+func (svc *EnterpriseService) validate(ctx context.Context, codebaseID codebases.ID, id *string, input *SetRemoteInput) error {
 	hasBasic := input.BasicAuthUsername != nil && input.BasicAuthPassword != nil
 	hasKeyPair := input.KeyPairID != nil
 
 	if hasBasic && hasKeyPair {
-		return nil, fmt.Errorf("basic auth and keypair auth are mutually exclusive")
+		return fmt.Errorf("basic auth and keypair auth are mutually exclusive")
 	}
 	if !hasBasic && !hasKeyPair {
-		return nil, fmt.Errorf("no auth method set")
+		return fmt.Errorf("no auth method set")
 	}
 
 	// make sure that only the relevant fields are set
@@ -125,59 +298,64 @@ func (svc *EnterpriseService) SetRemote(ctx context.Context, codebaseID codebase
 		input.BasicAuthUsername = nil
 		input.BasicAuthPassword = nil
 	} else {
-		return nil, fmt.Errorf("unexpected auth configuration")
+		return fmt.Errorf("unexpected auth configuration")
 	}
 
-	// update existing if exists
-	rep, err := svc.repo.GetByCodebaseID(ctx, codebaseID)
-	switch {
-	case err == nil:
-		// update
-		rep.Name = input.Name
-		rep.URL = input.URL
-		rep.TrackedBranch = input.TrackedBranch
-		rep.BasicAuthUsername = input.BasicAuthUsername
-		rep.BasicAuthPassword = input.BasicAuthPassword
-		rep.KeyPairID = input.KeyPairID
-		rep.BrowserLinkRepo = input.BrowserLinkRepo
-		rep.BrowserLinkBranch = input.BrowserLinkBranch
-		rep.Enabled = input.Enabled
-		if err := svc.repo.Update(ctx, rep); err != nil {
-			return nil, fmt.Errorf("failed to update remote: %w", err)
-		}
-
-		svc.analyticsService.Capture(ctx, "updated remote integration", analytics.CodebaseID(codebaseID), analytics.Property("remote_name", rep.Name))
+	if !input.Direction.Valid() {
+		return ErrInvalidDirection
+	}
 
-		return rep, nil
-	case errors.Is(err, sql.ErrNoRows):
-		// create
-		r := remote.Remote{
-			ID:                uuid.NewString(),
-			CodebaseID:        codebaseID,
-			Name:              input.Name,
-			URL:               input.URL,
-			TrackedBranch:     input.TrackedBranch,
-			BasicAuthUsername: input.BasicAuthUsername,
-			BasicAuthPassword: input.BasicAuthPassword,
-			KeyPairID:         input.KeyPairID,
-			BrowserLinkRepo:   input.BrowserLinkRepo,
-			BrowserLinkBranch: input.BrowserLinkBranch,
-			Enabled:           input.Enabled,
+	if input.PollInterval != nil {
+		if *input.PollInterval < remote.MinPollInterval {
+			return ErrInvalidPollInterval
 		}
+		if input.Direction != remote.DirectionPull {
+			return ErrPollNotPull
+		}
+	}
 
-		if err := svc.repo.Create(ctx, r); err != nil {
-			return nil, fmt.Errorf("failed to add remote: %w", err)
+	existing, err := svc.repo.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to list remotes: %w", err)
+	}
+	for _, rep := range existing {
+		if id != nil && rep.ID == *id {
+			continue
 		}
+		if rep.Name == input.Name {
+			return ErrNameTaken
+		}
+		if rep.Direction == remote.DirectionPull && input.Direction == remote.DirectionPull {
+			return ErrPullRemoteExists
+		}
+	}
 
-		svc.analyticsService.Capture(ctx, "created remote integration", analytics.CodebaseID(codebaseID), analytics.Property("remote_name", r.Name))
+	return nil
+}
 
-		return &r, nil
-	default:
-		return nil, fmt.Errorf("failed to set remote: %w", err)
+// nextPollAt returns when a remote that has been created or updated should be polled next.
+func nextPollAt(r *remote.Remote) *time.Time {
+	if r.PollInterval == nil {
+		return nil
 	}
+	now := time.Now()
+	return &now
 }
 
-var ErrRemoteDisabled = errors.New("this remote is disabled")
+// auditValue returns a copy of the remote that is safe to store in the audit log.
+func auditValue(r *remote.Remote) remote.Remote {
+	c := *r
+	if c.BasicAuthPassword != nil {
+		redacted := "[REDACTED]"
+		c.BasicAuthPassword = &redacted
+	}
+	return c
+}
+
+var (
+	ErrRemoteDisabled = errors.New("this remote is disabled")
+	ErrNoRemotes      = errors.New("the codebase has no enabled remotes")
+)
 
 func (svc *EnterpriseService) Push(ctx context.Context, user *users.User, ws *workspaces.Workspace) error {
 	rem, err := svc.GetWithFixedURL(ctx, ws.CodebaseID)
@@ -191,14 +369,30 @@ func (svc *EnterpriseService) Push(ctx context.Context, user *users.User, ws *wo
 	localBranchName := "sturdy-" + ws.ID
 	gitCommitMessage := message.CommitMessage(ws.DraftDescription)
 
-	_, err = svc.PrepareBranchForPush(ctx, localBranchName, ws, gitCommitMessage, user.Name, user.Email)
+	// workspaces that have been imported from a branch on the remote are pushed back to that branch
+	importedBranch, err := svc.branchRepo.GetByWorkspaceID(ctx, ws.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		importedBranch = nil
+	case err != nil:
+		return fmt.Errorf("could not get imported branch: %w", err)
+	case importedBranch.RemoteID != rem.ID:
+		importedBranch = nil
+	}
+
+	remoteBranchName := "sturdy-" + ws.ID
+	if importedBranch != nil {
+		remoteBranchName = importedBranch.Name
+	}
+
+	sha, err := svc.PrepareBranchForPush(ctx, localBranchName, ws, gitCommitMessage, user.Name, user.Email)
 	if err != nil {
 		return err
 	}
 
-	refspec := fmt.Sprintf("+refs/heads/%s:refs/heads/sturdy-%s", localBranchName, ws.ID)
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/heads/%s", localBranchName, remoteBranchName)
 
-	creds, err := svc.newCredentialsCallback(ctx, rem)
+	creds, verifier, err := svc.newCredentialsCallback(ctx, rem)
 	if err != nil {
 		return fmt.Errorf("could not get creds: %w", err)
 	}
@@ -216,7 +410,17 @@ func (svc *EnterpriseService) Push(ctx context.Context, user *users.User, ws *wo
 	}
 
 	if err := svc.executorProvider.New().GitWrite(push).ExecTrunk(ws.CodebaseID, "pushRemote"); err != nil {
-		return fmt.Errorf("failed to push workspace to remote: %w", err)
+		return fmt.Errorf("failed to push workspace to remote: %w", verifier.wrap(err))
+	}
+
+	if importedBranch != nil {
+		now := time.Now()
+		importedBranch.HeadSHA = sha
+		importedBranch.Linked = false // stop importing changes, the workspace is the source of truth from now on
+		importedBranch.UpdatedAt = &now
+		if err := svc.branchRepo.Update(ctx, importedBranch); err != nil {
+			return fmt.Errorf("failed to update imported branch: %w", err)
+		}
 	}
 
 	svc.analyticsService.Capture(ctx, "pushed workspace to remote", analytics.CodebaseID(ws.CodebaseID), analytics.Property("workspace_id", ws.ID))
@@ -224,24 +428,57 @@ func (svc *EnterpriseService) Push(ctx context.Context, user *users.User, ws *wo
 	return nil
 }
 
-func (svc *EnterpriseService) PushTrunk(ctx context.Context, codebaseID codebases.ID) error {
-	rem, err := svc.GetWithFixedURL(ctx, codebaseID)
+// PushTrunk pushes trunk to all enabled remotes of the codebase, and returns the result of each push. The
+// result of the latest push is saved on each remote.
+func (svc *EnterpriseService) PushTrunk(ctx context.Context, codebaseID codebases.ID) (remote.PushResults, error) {
+	rr, err := svc.repo.ListByCodebaseID(ctx, codebaseID)
 	if err != nil {
-		return fmt.Errorf("could not get remote: %w", err)
+		return nil, fmt.Errorf("could not list remotes: %w", err)
 	}
-	if !rem.Enabled {
-		return ErrRemoteDisabled
+
+	var results remote.PushResults
+	for _, rem := range rr {
+		if !rem.Enabled {
+			continue
+		}
+
+		pushErr := svc.pushTrunk(ctx, rem)
+
+		now := time.Now()
+		rem.LastPushedAt = &now
+		rem.LastPushError = nil
+		if pushErr != nil {
+			msg := pushErr.Error()
+			rem.LastPushError = &msg
+		}
+		if err := svc.repo.SetPushResult(ctx, rem.ID, now, rem.LastPushError); err != nil {
+			return nil, fmt.Errorf("failed to save push result: %w", err)
+		}
+
+		results = append(results, &remote.PushResult{Remote: rem, Err: pushErr})
 	}
 
+	if len(results) == 0 {
+		return nil, ErrNoRemotes
+	}
+
+	return results, nil
+}
+
+func (svc *EnterpriseService) pushTrunk(ctx context.Context, rem *remote.Remote) error {
 	refspec := fmt.Sprintf("refs/heads/sturdytrunk:refs/heads/%s", rem.TrackedBranch)
+	if rem.Direction == remote.DirectionMirror {
+		refspec = "+" + refspec
+	}
 
-	creds, err := svc.newCredentialsCallback(ctx, rem)
+	creds, verifier, err := svc.newCredentialsCallback(ctx, rem)
 	if err != nil {
 		return fmt.Errorf("could not get creds: %w", err)
 	}
 
+	url := fixedURL(rem)
 	push := func(repo vcs.RepoGitWriter) error {
-		_, err := repo.PushRemoteUrlWithRefspec(rem.URL, creds, []config.RefSpec{config.RefSpec(refspec)})
+		_, err := repo.PushRemoteUrlWithRefspec(url, creds, []config.RefSpec{config.RefSpec(refspec)})
 		switch {
 		case errors.Is(err, gogit.NoErrAlreadyUpToDate):
 			return nil
@@ -252,33 +489,41 @@ func (svc *EnterpriseService) PushTrunk(ctx context.Context, codebaseID codebase
 		}
 	}
 
-	if err := svc.executorProvider.New().GitWrite(push).ExecTrunk(codebaseID, "pushTrunkRemote"); err != nil {
-		return fmt.Errorf("failed to push trunk to remote: %w", err)
+	if err := svc.executorProvider.New().GitWrite(push).ExecTrunk(rem.CodebaseID, "pushTrunkRemote"); err != nil {
+		return fmt.Errorf("failed to push trunk to remote: %w", verifier.wrap(err))
 	}
 
-	svc.analyticsService.Capture(ctx, "pushed trunk to remote", analytics.CodebaseID(codebaseID))
+	svc.analyticsService.Capture(ctx, "pushed trunk to remote", analytics.CodebaseID(rem.CodebaseID), analytics.Property("remote_name", rem.Name))
 
 	return nil
 }
 
 func (svc *EnterpriseService) Pull(ctx context.Context, codebaseID codebases.ID) error {
-	rem, err := svc.GetWithFixedURL(ctx, codebaseID)
+	rem, err := svc.Get(ctx, codebaseID)
 	if err != nil {
 		return fmt.Errorf("could not get remote: %w", err)
 	}
 	if !rem.Enabled {
 		return ErrRemoteDisabled
 	}
+	return svc.pull(ctx, rem)
+}
+
+// maxImportedCommits is the maximum number of new commits that are imported as changes after a pull, the rest
+// are imported when the changelog is read.
+const maxImportedCommits = 100
 
+func (svc *EnterpriseService) pull(ctx context.Context, rem *remote.Remote) error {
 	refspec := fmt.Sprintf("+refs/heads/%s:refs/heads/sturdytrunk", rem.TrackedBranch)
 
-	creds, err := svc.newCredentialsCallback(ctx, rem)
+	creds, verifier, err := svc.newCredentialsCallback(ctx, rem)
 	if err != nil {
 		return fmt.Errorf("could not get creds: %w", err)
 	}
 
+	url := fixedURL(rem)
 	pull := func(repo vcs.RepoGitWriter) error {
-		err := repo.FetchUrlRemoteWithCreds(rem.URL, creds, []config.RefSpec{config.RefSpec(refspec)})
+		err := repo.FetchUrlRemoteWithCreds(url, creds, []config.RefSpec{config.RefSpec(refspec)})
 		switch {
 		case errors.Is(err, gogit.NoErrAlreadyUpToDate):
 			return nil
@@ -289,8 +534,9 @@ func (svc *EnterpriseService) Pull(ctx context.Context, codebaseID codebases.ID)
 		}
 	}
 
+	codebaseID := rem.CodebaseID
 	if err := svc.executorProvider.New().GitWrite(pull).ExecTrunk(codebaseID, "pullRemote"); err != nil {
-		return fmt.Errorf("failed to pull: %w", err)
+		return fmt.Errorf("failed to pull: %w", verifier.wrap(err))
 	}
 
 	svc.analyticsService.Capture(ctx, "pulled trunk from remote", analytics.CodebaseID(codebaseID))
@@ -299,39 +545,100 @@ func (svc *EnterpriseService) Pull(ctx context.Context, codebaseID codebases.ID)
 		return fmt.Errorf("failed to unset head: %w", err)
 	}
 
+	if err := svc.changeService.ImportNewCommits(ctx, codebaseID, maxImportedCommits); err != nil {
+		return fmt.Errorf("failed to import new commits: %w", err)
+	}
+
 	// Allow all workspaces to be rebased/synced on the latest head
 	if err := svc.workspaceWriter.UnsetUpToDateWithTrunkForAllInCodebase(codebaseID); err != nil {
 		return fmt.Errorf("failed to unset up to date with trunk for all in codebase: %w", err)
 	}
 
+	if err := svc.syncLinkedBranches(ctx, rem); err != nil {
+		return fmt.Errorf("failed to sync linked branches: %w", err)
+	}
+
 	return nil
 }
 
-func (svc *EnterpriseService) newCredentialsCallback(ctx context.Context, rem *remote.Remote) (cb transport.AuthMethod, err error) {
+// pollLease is how long a remote that is being polled is hidden from other pollers.
+const pollLease = 10 * time.Minute
+
+// PollDue pulls trunk from all remotes that are due to be polled.
+func (svc *EnterpriseService) PollDue(ctx context.Context) error {
+	now := time.Now()
+	rr, err := svc.repo.ClaimDueForPolling(ctx, now, now.Add(pollLease))
+	if err != nil {
+		return fmt.Errorf("failed to claim remotes: %w", err)
+	}
+
+	for _, rem := range rr {
+		if err := svc.Poll(ctx, rem); err != nil {
+			svc.logger.Warn("failed to poll remote",
+				zap.Stringer("codebase_id", rem.CodebaseID),
+				zap.String("remote_id", rem.ID),
+				zap.Int("failures", rem.PollFailures),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Poll pulls trunk from the remote, and schedules the next poll. Failing remotes are polled less often, see
+// remote.Remote.PollBackoff.
+func (svc *EnterpriseService) Poll(ctx context.Context, rem *remote.Remote) error {
+	pullErr := svc.pull(ctx, rem)
+
+	now := time.Now()
+	rem.LastPolledAt = &now
+	if pullErr != nil {
+		msg := pullErr.Error()
+		rem.LastPollError = &msg
+		rem.PollFailures++
+	} else {
+		rem.LastPollError = nil
+		rem.PollFailures = 0
+	}
+	next := now.Add(rem.PollBackoff())
+	rem.NextPollAt = &next
+
+	if err := svc.repo.SetPollResult(ctx, rem.ID, now, rem.LastPollError, rem.PollFailures, rem.PollInterval, next); err != nil {
+		return fmt.Errorf("failed to save poll result: %w", err)
+	}
+
+	return pullErr
+}
+
+// newCredentialsCallback returns the auth method to use when connecting to the remote. For SSH remotes, the
+// returned verifier checks the host key of the remote, it's nil for other remotes.
+func (svc *EnterpriseService) newCredentialsCallback(ctx context.Context, rem *remote.Remote) (cb transport.AuthMethod, verifier *hostKeyVerifier, err error) {
 	if rem.KeyPairID != nil {
 		kp, kpErr := svc.keyPairRepository.Get(ctx, *rem.KeyPairID)
 		if kpErr != nil {
-			return nil, fmt.Errorf("could not get kp: %w", kpErr)
+			return nil, nil, fmt.Errorf("could not get kp: %w", kpErr)
 		}
 
 		am, err := ssh.NewPublicKeys("git", []byte(kp.PrivateKey), "")
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		am.HostKeyCallback = ssh2.InsecureIgnoreHostKey()
+		verifier := &hostKeyVerifier{ctx: ctx, repo: svc.repo, remote: rem}
+		am.HostKeyCallback = verifier.callback
 
-		return am, nil
+		return am, verifier, nil
 	}
 
 	if rem.BasicAuthUsername != nil && rem.BasicAuthPassword != nil {
 		return &http.BasicAuth{
 			Username: *rem.BasicAuthUsername,
 			Password: *rem.BasicAuthPassword,
-		}, nil
+		}, nil, nil
 	}
 
-	return nil, errors.New("no auth method found")
+	return nil, nil, errors.New("no auth method found")
 }
 
 func (svc *EnterpriseService) PrepareBranchForPush(ctx context.Context, prBranchName string, ws *workspaces.Workspace, commitMessage, userName, userEmail string) (commitSha string, err error) {
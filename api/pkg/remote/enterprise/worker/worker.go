@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"getsturdy.com/api/pkg/remote/enterprise/service"
+)
+
+var (
+	// runEvery is how often the worker looks for remotes that are due to be polled, the interval of each
+	// remote is configured on the remote.
+	runEvery = 30 * time.Second
+)
+
+// Worker polls remotes that have a poll interval.
+type Worker struct {
+	logger  *zap.Logger
+	service *service.EnterpriseService
+}
+
+func New(
+	logger *zap.Logger,
+	service *service.EnterpriseService,
+) *Worker {
+	return &Worker{
+		logger:  logger.Named("remotes_poller"),
+		service: service,
+	}
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	w.logger.Info("starting")
+
+	ticker := time.NewTicker(runEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.service.PollDue(ctx); err != nil {
+				w.logger.Error("failed to poll remotes", zap.Error(err))
+			}
+		case <-ctx.Done():
+			w.logger.Info("stopping")
+			return nil
+		}
+	}
+}
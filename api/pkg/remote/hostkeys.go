@@ -0,0 +1,88 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	ErrHostKeyNotConfirmed = errors.New("the host key of the remote has not been confirmed")
+	ErrHostKeyChanged      = errors.New("the host key of the remote has changed")
+)
+
+// HostKey is the public key that an SSH remote uses to identify itself. Host keys are stored in the
+// known_hosts format.
+type HostKey struct {
+	Hosts []string
+	Key   ssh.PublicKey
+}
+
+// NewHostKey returns the host key that was presented by hostname when connecting.
+func NewHostKey(hostname string, key ssh.PublicKey) *HostKey {
+	return &HostKey{
+		Hosts: []string{knownhosts.Normalize(hostname)},
+		Key:   key,
+	}
+}
+
+// ParseHostKey parses a single line in the known_hosts format.
+func ParseHostKey(line string) (*HostKey, error) {
+	marker, hosts, key, _, rest, err := ssh.ParseKnownHosts([]byte(line))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse host key: %w", err)
+	}
+	if marker != "" {
+		return nil, fmt.Errorf("unsupported host key marker: %s", marker)
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, fmt.Errorf("expected a single host key")
+	}
+	return &HostKey{Hosts: hosts, Key: key}, nil
+}
+
+// String returns the host key in the known_hosts format.
+func (k *HostKey) String() string {
+	return knownhosts.Line(k.Hosts, k.Key)
+}
+
+func (k *HostKey) Fingerprint() string {
+	return ssh.FingerprintSHA256(k.Key)
+}
+
+// Matches returns true if key is the pinned key of hostname.
+func (k *HostKey) Matches(hostname string, key ssh.PublicKey) bool {
+	if !bytes.Equal(k.Key.Marshal(), key.Marshal()) {
+		return false
+	}
+	normalized := knownhosts.Normalize(hostname)
+	for _, host := range k.Hosts {
+		if host == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyHostKey verifies the key that hostname presented when connecting to the remote against the pinned
+// host key of the remote.
+func (r *Remote) VerifyHostKey(hostname string, key ssh.PublicKey) error {
+	if r.KnownHost == nil {
+		return fmt.Errorf("%w: %s presented a key with fingerprint %s", ErrHostKeyNotConfirmed, hostname, ssh.FingerprintSHA256(key))
+	}
+
+	pinned, err := ParseHostKey(*r.KnownHost)
+	if err != nil {
+		return fmt.Errorf("failed to parse pinned host key: %w", err)
+	}
+
+	if !pinned.Matches(hostname, key) {
+		return fmt.Errorf("%w: expected a key with fingerprint %s, but %s presented %s",
+			ErrHostKeyChanged, pinned.Fingerprint(), hostname, ssh.FingerprintSHA256(key))
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package remote_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+
+	"getsturdy.com/api/pkg/remote"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return key
+}
+
+func TestHostKey_String(t *testing.T) {
+	key := newHostKey(t)
+
+	hostKey := remote.NewHostKey("git.example.com:22", key)
+	parsed, err := remote.ParseHostKey(hostKey.String())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"git.example.com"}, parsed.Hosts)
+		assert.Equal(t, hostKey.Fingerprint(), parsed.Fingerprint())
+		assert.Equal(t, "ssh-ed25519", parsed.Key.Type())
+	}
+
+	_, err = remote.ParseHostKey("not a key")
+	assert.Error(t, err)
+
+	_, err = remote.ParseHostKey("@revoked " + hostKey.String())
+	assert.Error(t, err)
+}
+
+func TestRemote_VerifyHostKey(t *testing.T) {
+	key := newHostKey(t)
+	otherKey := newHostKey(t)
+
+	t.Run("not confirmed", func(t *testing.T) {
+		r := &remote.Remote{}
+		err := r.VerifyHostKey("git.example.com:22", key)
+		assert.True(t, errors.Is(err, remote.ErrHostKeyNotConfirmed))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), ssh.FingerprintSHA256(key))
+		}
+	})
+
+	knownHost := remote.NewHostKey("git.example.com:22", key).String()
+	r := &remote.Remote{KnownHost: &knownHost}
+
+	t.Run("pinned", func(t *testing.T) {
+		assert.NoError(t, r.VerifyHostKey("git.example.com:22", key))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		err := r.VerifyHostKey("git.example.com:22", otherKey)
+		assert.True(t, errors.Is(err, remote.ErrHostKeyChanged))
+	})
+
+	t.Run("other host", func(t *testing.T) {
+		err := r.VerifyHostKey("git.example.com:2222", key)
+		assert.True(t, errors.Is(err, remote.ErrHostKeyChanged))
+	})
+}
@@ -21,3 +21,35 @@ func (r *remoteRootResolver) InternalRemoteByCodebaseID(ctx context.Context, cod
 func (r *remoteRootResolver) CreateOrUpdateCodebaseRemote(ctx context.Context, args resolvers.CreateOrUpdateCodebaseRemoteArgsArgs) (resolvers.RemoteResolver, error) {
 	return nil, gqlerror.ErrNotImplemented
 }
+
+func (r *remoteRootResolver) InternalRemotesByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]resolvers.RemoteResolver, error) {
+	return nil, nil
+}
+
+func (r *remoteRootResolver) CreateCodebaseRemote(ctx context.Context, args resolvers.CreateCodebaseRemoteArgs) (resolvers.RemoteResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *remoteRootResolver) UpdateCodebaseRemote(ctx context.Context, args resolvers.UpdateCodebaseRemoteArgs) (resolvers.RemoteResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *remoteRootResolver) DeleteCodebaseRemote(ctx context.Context, args resolvers.DeleteCodebaseRemoteArgs) (resolvers.RemoteResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *remoteRootResolver) ConfirmRemoteHostKey(ctx context.Context, args resolvers.ConfirmRemoteHostKeyArgs) (resolvers.RemoteResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *remoteRootResolver) RotateRemoteHostKey(ctx context.Context, args resolvers.RotateRemoteHostKeyArgs) (resolvers.RemoteResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *remoteRootResolver) InternalRemoteBranchByWorkspaceID(ctx context.Context, workspaceID string) (resolvers.RemoteBranchResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
+
+func (r *remoteRootResolver) ImportRemoteBranches(ctx context.Context, args resolvers.ImportRemoteBranchesArgs) ([]resolvers.WorkspaceResolver, error) {
+	return nil, gqlerror.ErrNotImplemented
+}
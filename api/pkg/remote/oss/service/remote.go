@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/remote"
 	remote_service "getsturdy.com/api/pkg/remote/service"
 )
 
@@ -18,6 +19,6 @@ func (*service) Pull(_ context.Context, _ codebases.ID) error {
 	return errors.New("not available")
 }
 
-func (*service) PushTrunk(_ context.Context, _ codebases.ID) error {
-	return errors.New("not available")
+func (*service) PushTrunk(_ context.Context, _ codebases.ID) (remote.PushResults, error) {
+	return nil, errors.New("not available")
 }
@@ -1,10 +1,36 @@
 package remote
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/crypto"
 )
 
+// Direction is how trunk is synchronized with a remote.
+type Direction string
+
+const (
+	// DirectionPull remotes are the source of truth of trunk. Trunk is pulled from the remote, and landed
+	// changes and workspaces are pushed back to it. A codebase can have at most one pull remote.
+	DirectionPull Direction = "pull"
+	// DirectionPush remotes receive landed changes. Pushes fail if the branch on the remote has diverged.
+	DirectionPush Direction = "push"
+	// DirectionMirror remotes receive landed changes, overwriting whatever is on the branch on the remote.
+	DirectionMirror Direction = "mirror"
+)
+
+func (d Direction) Valid() bool {
+	switch d {
+	case DirectionPull, DirectionPush, DirectionMirror:
+		return true
+	default:
+		return false
+	}
+}
+
 type Remote struct {
 	ID                string            `db:"id"`
 	CodebaseID        codebases.ID      `db:"codebase_id"`
@@ -13,8 +39,105 @@ type Remote struct {
 	BasicAuthUsername *string           `db:"basic_username"`
 	BasicAuthPassword *string           `db:"basic_password"`
 	KeyPairID         *crypto.KeyPairID `db:"keypair_id"`
-	TrackedBranch     string            `db:"tracked_branch"`
-	BrowserLinkRepo   string            `db:"browser_link_repo"`
-	BrowserLinkBranch string            `db:"browser_link_branch"`
-	Enabled           bool              `db:"enabled"`
+	Direction         Direction         `db:"direction"`
+	// TrackedBranch is the branch on the remote that trunk is mapped to.
+	TrackedBranch     string `db:"tracked_branch"`
+	BrowserLinkRepo   string `db:"browser_link_repo"`
+	BrowserLinkBranch string `db:"browser_link_branch"`
+	Enabled           bool   `db:"enabled"`
+
+	// LastPushedAt and LastPushError are the result of the latest push of trunk to the remote.
+	LastPushedAt  *time.Time `db:"last_pushed_at"`
+	LastPushError *string    `db:"last_push_error"`
+
+	// PollInterval is how often trunk is pulled from the remote. Remotes without an interval are only pulled
+	// when triggered by a user or a webhook.
+	PollInterval *time.Duration `db:"poll_interval"`
+	NextPollAt   *time.Time     `db:"next_poll_at"`
+	// LastPolledAt and LastPollError are the result of the latest poll.
+	LastPolledAt  *time.Time `db:"last_polled_at"`
+	LastPollError *string    `db:"last_poll_error"`
+	// PollFailures is the number of polls in a row that have failed.
+	PollFailures int `db:"poll_failures"`
+
+	// KnownHost is the pinned host key of SSH remotes, in the known_hosts format. Connections are refused if
+	// the remote presents any other key.
+	KnownHost *string `db:"known_host"`
+	// PendingKnownHost is the latest key that the remote presented that did not match KnownHost. It's pinned
+	// when confirmed by a user, or when an administrator rotates the pinned key.
+	PendingKnownHost *string `db:"pending_known_host"`
+}
+
+const (
+	MinPollInterval = time.Minute
+	// MaxPollBackoff is the longest time between two polls of a failing remote.
+	MaxPollBackoff = 6 * time.Hour
+)
+
+// PollBackoff returns the time to wait until the next poll. The interval is doubled for every failed poll.
+func (r *Remote) PollBackoff() time.Duration {
+	if r.PollInterval == nil {
+		return 0
+	}
+	backoff := *r.PollInterval
+	for i := 0; i < r.PollFailures && backoff < MaxPollBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > MaxPollBackoff && *r.PollInterval < MaxPollBackoff {
+		return MaxPollBackoff
+	}
+	return backoff
+}
+
+type HealthStatus string
+
+const (
+	// HealthStatusUnknown remotes have not been pulled from or pushed to yet.
+	HealthStatusUnknown HealthStatus = "unknown"
+	HealthStatusHealthy HealthStatus = "healthy"
+	// HealthStatusFailing remotes failed the latest poll or push.
+	HealthStatusFailing HealthStatus = "failing"
+)
+
+func (r *Remote) Health() HealthStatus {
+	switch {
+	case r.LastPollError != nil, r.LastPushError != nil:
+		return HealthStatusFailing
+	case r.LastPolledAt != nil, r.LastPushedAt != nil:
+		return HealthStatusHealthy
+	default:
+		return HealthStatusUnknown
+	}
+}
+
+// PushResult is the result of pushing trunk to one remote.
+type PushResult struct {
+	Remote *Remote
+	Err    error
+}
+
+type PushResults []*PushResult
+
+// Failed returns the results of the remotes that trunk could not be pushed to.
+func (results PushResults) Failed() PushResults {
+	var failed PushResults
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns an error that describes all failed pushes, or nil if trunk was pushed to all remotes.
+func (results PushResults) Err() error {
+	failed := results.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(failed))
+	for _, result := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %s", result.Remote.Name, result.Err))
+	}
+	return fmt.Errorf("failed to push to %d of %d remotes: %s", len(failed), len(results), strings.Join(messages, "; "))
 }
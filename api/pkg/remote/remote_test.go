@@ -0,0 +1,64 @@
+package remote_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"getsturdy.com/api/pkg/remote"
+)
+
+func TestPushResults_Err(t *testing.T) {
+	gitea := &remote.Remote{Name: "gitea"}
+	backup := &remote.Remote{Name: "backup"}
+
+	ok := remote.PushResults{{Remote: gitea}, {Remote: backup}}
+	assert.Empty(t, ok.Failed())
+	assert.NoError(t, ok.Err())
+
+	failed := remote.PushResults{{Remote: gitea}, {Remote: backup, Err: errors.New("rejected")}}
+	if assert.Len(t, failed.Failed(), 1) {
+		assert.Equal(t, backup, failed.Failed()[0].Remote)
+	}
+	if assert.Error(t, failed.Err()) {
+		assert.Equal(t, "failed to push to 1 of 2 remotes: backup: rejected", failed.Err().Error())
+	}
+}
+
+func TestRemote_PollBackoff(t *testing.T) {
+	minute := time.Minute
+	day := 24 * time.Hour
+
+	cases := []struct {
+		name     string
+		interval *time.Duration
+		failures int
+		expected time.Duration
+	}{
+		{name: "not polled", interval: nil, expected: 0},
+		{name: "healthy", interval: &minute, failures: 0, expected: time.Minute},
+		{name: "failing", interval: &minute, failures: 3, expected: 8 * time.Minute},
+		{name: "capped", interval: &minute, failures: 100, expected: remote.MaxPollBackoff},
+		{name: "interval longer than cap", interval: &day, failures: 2, expected: day},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &remote.Remote{PollInterval: tc.interval, PollFailures: tc.failures}
+			assert.Equal(t, tc.expected, r.PollBackoff())
+		})
+	}
+}
+
+func TestRemote_Health(t *testing.T) {
+	now := time.Now()
+	msg := "authentication required"
+
+	assert.Equal(t, remote.HealthStatusUnknown, (&remote.Remote{}).Health())
+	assert.Equal(t, remote.HealthStatusHealthy, (&remote.Remote{LastPolledAt: &now}).Health())
+	assert.Equal(t, remote.HealthStatusHealthy, (&remote.Remote{LastPushedAt: &now}).Health())
+	assert.Equal(t, remote.HealthStatusFailing, (&remote.Remote{LastPolledAt: &now, LastPollError: &msg}).Health())
+	assert.Equal(t, remote.HealthStatusFailing, (&remote.Remote{LastPushedAt: &now, LastPushError: &msg}).Health())
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 
 	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/remote"
 )
 
 type Service interface {
 	Pull(ctx context.Context, codebaseID codebases.ID) error
-	PushTrunk(ctx context.Context, codebaseID codebases.ID) error
+	// PushTrunk pushes trunk to all remotes of the codebase.
+	PushTrunk(ctx context.Context, codebaseID codebases.ID) (remote.PushResults, error)
 }
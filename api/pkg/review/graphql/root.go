@@ -13,14 +13,19 @@ import (
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
 	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
 	"getsturdy.com/api/pkg/events"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
 	gqlerrors "getsturdy.com/api/pkg/graphql/errors"
 	"getsturdy.com/api/pkg/graphql/resolvers"
 	"getsturdy.com/api/pkg/notification"
 	"getsturdy.com/api/pkg/notification/sender"
+	"getsturdy.com/api/pkg/organization/teams"
+	service_teams "getsturdy.com/api/pkg/organization/teams/service"
 	"getsturdy.com/api/pkg/review"
 	db_review "getsturdy.com/api/pkg/review/db"
 	"getsturdy.com/api/pkg/users"
+	"getsturdy.com/api/pkg/workspaces"
 	db_workspaces "getsturdy.com/api/pkg/workspaces/db"
 	service_workspace_watchers "getsturdy.com/api/pkg/workspaces/watchers/service"
 
@@ -33,13 +38,16 @@ type reviewRootResolver struct {
 
 	reviewRepo      db_review.ReviewRepository
 	workspaceReader db_workspaces.WorkspaceReader
+	codebaseRepo    db_codebases.CodebaseRepository
 	authService     *service_auth.Service
+	teamsService    *service_teams.Service
 
 	authorRootResolver    resolvers.AuthorRootResolver
 	workspaceRootResolver *resolvers.WorkspaceRootResolver
 
 	eventsSender       events.EventSender
 	eventsReader       events.EventReader
+	eventsPublisher    *eventsv2.Publisher
 	notificationSender sender.NotificationSender
 	activitySender     activity_sender.ActivitySender
 
@@ -52,13 +60,16 @@ func New(
 	logger *zap.Logger,
 	reviewRepo db_review.ReviewRepository,
 	workspaceReader db_workspaces.WorkspaceReader,
+	codebaseRepo db_codebases.CodebaseRepository,
 	authService *service_auth.Service,
+	teamsService *service_teams.Service,
 
 	authorRootResolver resolvers.AuthorRootResolver,
 	workspaceRootResolver *resolvers.WorkspaceRootResolver,
 
 	eventsSender events.EventSender,
 	eventsReader events.EventReader,
+	eventsPublisher *eventsv2.Publisher,
 	notificationSender sender.NotificationSender,
 	activitySender activity_sender.ActivitySender,
 
@@ -71,13 +82,16 @@ func New(
 
 		reviewRepo:      reviewRepo,
 		workspaceReader: workspaceReader,
+		codebaseRepo:    codebaseRepo,
 		authService:     authService,
+		teamsService:    teamsService,
 
 		authorRootResolver:    authorRootResolver,
 		workspaceRootResolver: workspaceRootResolver,
 
 		eventsSender:       eventsSender,
 		eventsReader:       eventsReader,
+		eventsPublisher:    eventsPublisher,
 		notificationSender: notificationSender,
 		activitySender:     activitySender,
 
@@ -195,6 +209,11 @@ func (r *reviewRootResolver) CreateOrUpdateReview(ctx context.Context, args reso
 		// do not fail
 	}
 
+	if err := r.eventsPublisher.ReviewUpdated(ctx, eventsv2.Codebase(ws.CodebaseID), &rev); err != nil {
+		r.logger.Error("failed to send review updated event", zap.Error(err))
+		// do not fail
+	}
+
 	r.analyticsService.Capture(ctx, "review created",
 		analytics.CodebaseID(ws.CodebaseID),
 		analytics.Property("workspace_id", ws.ID),
@@ -210,9 +229,32 @@ func (r *reviewRootResolver) RequestReview(ctx context.Context, args resolvers.R
 		return nil, gqlerrors.Error(err)
 	}
 
-	workspaceID := string(args.Input.WorkspaceID)
+	ws, err := r.workspaceReader.Get(string(args.Input.WorkspaceID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
 
-	ws, err := r.workspaceReader.Get(workspaceID)
+	if err := r.authService.CanWrite(ctx, ws); err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	rev, err := r.requestReview(ctx, ws, userID, users.ID(args.Input.UserID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	return &reviewResolver{root: r, rev: rev}, nil
+}
+
+// RequestTeamReview requests a review from every member of the team, including the members of it's child
+// teams.
+func (r *reviewRootResolver) RequestTeamReview(ctx context.Context, args resolvers.RequestTeamReviewArgs) ([]resolvers.ReviewResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	ws, err := r.workspaceReader.Get(string(args.Input.WorkspaceID))
 	if err != nil {
 		return nil, gqlerrors.Error(err)
 	}
@@ -221,55 +263,92 @@ func (r *reviewRootResolver) RequestReview(ctx context.Context, args resolvers.R
 		return nil, gqlerrors.Error(err)
 	}
 
+	team, err := r.teamsService.Get(ctx, teams.ID(args.Input.TeamID))
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	cb, err := r.codebaseRepo.Get(ws.CodebaseID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	if cb.OrganizationID == nil || *cb.OrganizationID != team.OrganizationID {
+		return nil, gqlerrors.Error(gqlerrors.ErrBadRequest, "teamID", "team is not in the organization of the codebase")
+	}
+
+	reviewerIDs, err := r.teamsService.ListUserIDs(ctx, team.ID)
+	if err != nil {
+		return nil, gqlerrors.Error(err)
+	}
+
+	var res []resolvers.ReviewResolver
+	for _, reviewerID := range reviewerIDs {
+		// don't request a review from yourself
+		if reviewerID == userID {
+			continue
+		}
+
+		rev, err := r.requestReview(ctx, ws, userID, reviewerID)
+		if err != nil {
+			return nil, gqlerrors.Error(err)
+		}
+		res = append(res, &reviewResolver{root: r, rev: rev})
+	}
+
+	return res, nil
+}
+
+func (r *reviewRootResolver) requestReview(ctx context.Context, ws *workspaces.Workspace, userID, reviewerID users.ID) (*review.Review, error) {
 	// requester starts watching the workspace
 	if _, err := r.workspaceWatchersService.Watch(ctx, userID, ws.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to watch workspace: %w", err))
+		return nil, fmt.Errorf("failed to watch workspace: %w", err)
 	}
 
 	// user requested review from starts watching the workspace
-	if _, err := r.workspaceWatchersService.Watch(ctx, users.ID(args.Input.UserID), ws.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to watch workspace: %w", err))
+	if _, err := r.workspaceWatchersService.Watch(ctx, reviewerID, ws.ID); err != nil {
+		return nil, fmt.Errorf("failed to watch workspace: %w", err)
 	}
 
-	if existing, err := r.reviewRepo.GetLatestByUserAndWorkspace(ctx, users.ID(args.Input.UserID), workspaceID); err == nil {
+	if existing, err := r.reviewRepo.GetLatestByUserAndWorkspace(ctx, reviewerID, ws.ID); err == nil {
 		// Don't request a review if this user already has a approved or rejected review
 		if existing.DismissedAt == nil && !existing.IsReplaced {
-			return &reviewResolver{root: r, rev: existing}, nil
+			return existing, nil
 		}
 
 		// Mark as replaced, and create a new review
 		existing.IsReplaced = true
 		if err := r.reviewRepo.Update(ctx, existing); err != nil {
-			return nil, gqlerrors.Error(err)
+			return nil, err
 		}
 
 		// Keep going
 	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return nil, gqlerrors.Error(err)
+		return nil, err
 	}
 
 	// Create new
 	rev := review.Review{
 		ID:          uuid.NewString(),
-		UserID:      users.ID(args.Input.UserID),
+		UserID:      reviewerID,
 		CodebaseID:  ws.CodebaseID,
-		WorkspaceID: workspaceID,
+		WorkspaceID: ws.ID,
 		Grade:       review.ReviewGradeRequested,
 		CreatedAt:   time.Now(),
 		RequestedBy: &userID,
 	}
 
 	if err := r.reviewRepo.Create(ctx, rev); err != nil {
-		return nil, gqlerrors.Error(err)
+		return nil, err
 	}
 
 	if err := r.activitySender.Codebase(ctx, ws.CodebaseID, ws.ID, userID, activity.TypeRequestedReview, rev.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to create activity: %w", err))
+		return nil, fmt.Errorf("failed to create activity: %w", err)
 	}
 
 	// Send notification to the user that the review was requested from
-	if err := r.notificationSender.User(ctx, users.ID(args.Input.UserID), ws.CodebaseID, notification.RequestedReviewNotificationType, rev.ID); err != nil {
-		return nil, gqlerrors.Error(fmt.Errorf("failed to send notification: %w", err))
+	if err := r.notificationSender.User(ctx, reviewerID, ws.CodebaseID, notification.RequestedReviewNotificationType, rev.ID); err != nil {
+		return nil, fmt.Errorf("failed to send notification: %w", err)
 	}
 
 	// Send events
@@ -282,13 +361,18 @@ func (r *reviewRootResolver) RequestReview(ctx context.Context, args resolvers.R
 		r.logger.Error("failed to send workspace event", zap.Error(err))
 		// do not fail
 	}
+
+	if err := r.eventsPublisher.ReviewUpdated(ctx, eventsv2.Codebase(ws.CodebaseID), &rev); err != nil {
+		r.logger.Error("failed to send review updated event", zap.Error(err))
+		// do not fail
+	}
 	r.analyticsService.Capture(ctx, "review requested",
 		analytics.CodebaseID(ws.CodebaseID),
 		analytics.Property("workspace_id", ws.ID),
 		analytics.Property("user_id", rev.UserID),
 	)
 
-	return &reviewResolver{root: r, rev: &rev}, nil
+	return &rev, nil
 }
 
 func (r *reviewRootResolver) DismissReview(ctx context.Context, args resolvers.DismissReviewArgs) (resolvers.ReviewResolver, error) {
@@ -318,6 +402,11 @@ func (r *reviewRootResolver) DismissReview(ctx context.Context, args resolvers.D
 		// do not fail
 	}
 
+	if err := r.eventsPublisher.ReviewUpdated(ctx, eventsv2.Codebase(rev.CodebaseID), rev); err != nil {
+		r.logger.Error("failed to send review updated event", zap.Error(err))
+		// do not fail
+	}
+
 	r.analyticsService.Capture(ctx, "review dismissed",
 		analytics.CodebaseID(rev.CodebaseID),
 		analytics.Property("workspace_id", rev.WorkspaceID),
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ TokenRepository = &tokenDatabase{}
+	_ UserRepository  = &userDatabase{}
+)
+
+type tokenDatabase struct {
+	db *sqlx.DB
+}
+
+func NewTokenDatabase(db *sqlx.DB) TokenRepository {
+	return &tokenDatabase{
+		db: db,
+	}
+}
+
+func (d *tokenDatabase) Create(ctx context.Context, token *scim.Token) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO scim_tokens (
+			id, organization_id, hash, created_by, created_at, last_used_at, revoked_at
+		) VALUES (
+			:id, :organization_id, :hash, :created_by, :created_at, :last_used_at, :revoked_at
+		)
+	`, token); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *tokenDatabase) Update(ctx context.Context, token *scim.Token) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE scim_tokens
+		SET last_used_at = :last_used_at,
+			revoked_at = :revoked_at
+		WHERE id = :id
+	`, token); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *tokenDatabase) GetByID(ctx context.Context, id string) (*scim.Token, error) {
+	token := &scim.Token{}
+	if err := d.db.GetContext(ctx, token, `
+		SELECT
+			id, organization_id, hash, created_by, created_at, last_used_at, revoked_at
+		FROM scim_tokens
+		WHERE id = $1
+	`, id); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return token, nil
+}
+
+func (d *tokenDatabase) ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.Token, error) {
+	var tokens []*scim.Token
+	if err := d.db.SelectContext(ctx, &tokens, `
+		SELECT
+			id, organization_id, hash, created_by, created_at, last_used_at, revoked_at
+		FROM scim_tokens
+		WHERE organization_id = $1
+		  AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return tokens, nil
+}
+
+type userDatabase struct {
+	db *sqlx.DB
+}
+
+func NewUserDatabase(db *sqlx.DB) UserRepository {
+	return &userDatabase{
+		db: db,
+	}
+}
+
+func (d *userDatabase) Upsert(ctx context.Context, user *scim.ProvisionedUser) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO scim_users (
+			organization_id, user_id, external_id, active, created_at, updated_at
+		) VALUES (
+			:organization_id, :user_id, :external_id, :active, :created_at, :updated_at
+		)
+		ON CONFLICT (organization_id, user_id) DO UPDATE
+		SET external_id = :external_id,
+			active = :active,
+			updated_at = :updated_at
+	`, user); err != nil {
+		return fmt.Errorf("failed to upsert: %w", err)
+	}
+	return nil
+}
+
+func (d *userDatabase) Get(ctx context.Context, organizationID string, userID users.ID) (*scim.ProvisionedUser, error) {
+	user := &scim.ProvisionedUser{}
+	if err := d.db.GetContext(ctx, user, `
+		SELECT
+			organization_id, user_id, external_id, active, created_at, updated_at
+		FROM scim_users
+		WHERE organization_id = $1
+		  AND user_id = $2
+	`, organizationID, userID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return user, nil
+}
+
+func (d *userDatabase) ListByOrganizationID(ctx context.Context, organizationID string) ([]*scim.ProvisionedUser, error) {
+	var uu []*scim.ProvisionedUser
+	if err := d.db.SelectContext(ctx, &uu, `
+		SELECT
+			organization_id, user_id, external_id, active, created_at, updated_at
+		FROM scim_users
+		WHERE organization_id = $1
+		ORDER BY created_at
+	`, organizationID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return uu, nil
+}
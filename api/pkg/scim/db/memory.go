@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+)
+
+var (
+	_ TokenRepository = &tokenMemory{}
+	_ UserRepository  = &userMemory{}
+)
+
+type tokenMemory struct {
+	byID map[string]*scim.Token
+}
+
+func NewTokenMemory() *tokenMemory {
+	return &tokenMemory{
+		byID: map[string]*scim.Token{},
+	}
+}
+
+func (m *tokenMemory) Create(_ context.Context, token *scim.Token) error {
+	m.byID[token.ID] = token
+	return nil
+}
+
+func (m *tokenMemory) Update(_ context.Context, token *scim.Token) error {
+	m.byID[token.ID] = token
+	return nil
+}
+
+func (m *tokenMemory) GetByID(_ context.Context, id string) (*scim.Token, error) {
+	token, found := m.byID[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return token, nil
+}
+
+func (m *tokenMemory) ListByOrganizationID(_ context.Context, organizationID string) ([]*scim.Token, error) {
+	var tokens []*scim.Token
+	for _, token := range m.byID {
+		if token.OrganizationID == organizationID && token.RevokedAt == nil {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+type userKey struct {
+	organizationID string
+	userID         users.ID
+}
+
+type userMemory struct {
+	byKey map[userKey]*scim.ProvisionedUser
+}
+
+func NewUserMemory() *userMemory {
+	return &userMemory{
+		byKey: map[userKey]*scim.ProvisionedUser{},
+	}
+}
+
+func (m *userMemory) Upsert(_ context.Context, user *scim.ProvisionedUser) error {
+	key := userKey{organizationID: user.OrganizationID, userID: user.UserID}
+	if existing, found := m.byKey[key]; found {
+		user.CreatedAt = existing.CreatedAt
+	}
+	m.byKey[key] = user
+	return nil
+}
+
+func (m *userMemory) Get(_ context.Context, organizationID string, userID users.ID) (*scim.ProvisionedUser, error) {
+	user, found := m.byKey[userKey{organizationID: organizationID, userID: userID}]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (m *userMemory) ListByOrganizationID(_ context.Context, organizationID string) ([]*scim.ProvisionedUser, error) {
+	var uu []*scim.ProvisionedUser
+	for _, user := range m.byKey {
+		if user.OrganizationID == organizationID {
+			uu = append(uu, user)
+		}
+	}
+	sort.Slice(uu, func(i, j int) bool {
+		return uu[i].CreatedAt.Before(uu[j].CreatedAt)
+	})
+	return uu, nil
+}
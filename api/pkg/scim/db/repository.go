@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+)
+
+type TokenRepository interface {
+	Create(context.Context, *scim.Token) error
+	Update(context.Context, *scim.Token) error
+	GetByID(context.Context, string) (*scim.Token, error)
+	// ListByOrganizationID returns all tokens of the organization that have not been revoked.
+	ListByOrganizationID(context.Context, string) ([]*scim.Token, error)
+}
+
+type UserRepository interface {
+	// Upsert creates or updates the provisioned user.
+	Upsert(context.Context, *scim.ProvisionedUser) error
+	Get(ctx context.Context, organizationID string, userID users.ID) (*scim.ProvisionedUser, error)
+	ListByOrganizationID(context.Context, string) ([]*scim.ProvisionedUser, error)
+}
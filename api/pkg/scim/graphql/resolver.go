@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/scim"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type rootResolver struct {
+	scimService         *service_scim.Service
+	organizationService *service_organization.Service
+	authService         *service_auth.Service
+
+	authorRootResolver resolvers.AuthorRootResolver
+}
+
+func New(
+	scimService *service_scim.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+
+	authorRootResolver resolvers.AuthorRootResolver,
+) resolvers.ScimRootResolver {
+	return &rootResolver{
+		scimService:         scimService,
+		organizationService: organizationService,
+		authService:         authService,
+
+		authorRootResolver: authorRootResolver,
+	}
+}
+
+func (r *rootResolver) InternalListByOrganizationID(ctx context.Context, organizationID string) ([]resolvers.ScimTokenResolver, error) {
+	org, err := r.organizationService.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// only users that can create tokens can see them
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return []resolvers.ScimTokenResolver{}, nil
+	}
+
+	tokens, err := r.scimService.ListTokensByOrganizationID(ctx, org.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.ScimTokenResolver, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, &resolver{root: r, token: token})
+	}
+	return res, nil
+}
+
+func (r *rootResolver) CreateScimToken(ctx context.Context, args resolvers.CreateScimTokenArgs) (resolvers.ScimTokenResolver, error) {
+	org, err := r.organizationService.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerror.Error(fmt.Errorf("organization not found: %w", err))
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// a SCIM token can manage the whole organization, don't allow creating one with a personal access token
+	if subject, _ := auth.FromContext(ctx); subject.IsPersonalAccessToken() {
+		return nil, gqlerror.Error(auth.ErrForbidden)
+	}
+
+	plainTextToken, token, err := r.scimService.CreateToken(ctx, org.ID, userID)
+	if err != nil {
+		return nil, gqlerror.Error(fmt.Errorf("failed to create token: %w", err))
+	}
+
+	return &resolver{
+		root:           r,
+		token:          token,
+		plainTextToken: &plainTextToken,
+	}, nil
+}
+
+func (r *rootResolver) RevokeScimToken(ctx context.Context, args resolvers.RevokeScimTokenArgs) (resolvers.ScimTokenResolver, error) {
+	token, err := r.scimService.GetToken(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	org, err := r.organizationService.GetByID(ctx, token.OrganizationID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	switch err := r.scimService.RevokeToken(ctx, token); {
+	case err == nil:
+	case errors.Is(err, service_scim.ErrAlreadyRevoked):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{root: r, token: token}, nil
+}
+
+type resolver struct {
+	root           *rootResolver
+	plainTextToken *string
+	token          *scim.Token
+}
+
+func (r *resolver) ID() graphql.ID {
+	return graphql.ID(r.token.ID)
+}
+
+func (r *resolver) CreatedBy(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(r.token.CreatedBy))
+}
+
+func (r *resolver) CreatedAt() int32 {
+	return int32(r.token.CreatedAt.Unix())
+}
+
+func (r *resolver) LastUsedAt() *int32 {
+	if r.token.LastUsedAt == nil {
+		return nil
+	}
+	luat := int32(r.token.LastUsedAt.Unix())
+	return &luat
+}
+
+func (r *resolver) Token() *string {
+	return r.plainTextToken
+}
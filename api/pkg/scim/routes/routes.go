@@ -0,0 +1,306 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"getsturdy.com/api/pkg/scim"
+	service_scim "getsturdy.com/api/pkg/scim/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	tokenKey = "scim_token"
+
+	defaultCount = 100
+)
+
+// Register mounts the SCIM 2.0 Users and Groups endpoints. All requests must be authenticated with a
+// SCIM token of the organization that is being provisioned.
+func Register(rg *gin.RouterGroup, logger *zap.Logger, scimService *service_scim.Service) {
+	logger = logger.With(zap.String("handler", "routes/scim"))
+
+	rg.Use(authenticate(logger, scimService))
+
+	rg.GET("/ServiceProviderConfig", serviceProviderConfig)
+
+	rg.GET("/Users", listUsers(logger, scimService))
+	rg.POST("/Users", createUser(logger, scimService))
+	rg.GET("/Users/:id", getUser(logger, scimService))
+	rg.PUT("/Users/:id", replaceUser(logger, scimService))
+	rg.PATCH("/Users/:id", patchUser(logger, scimService))
+	rg.DELETE("/Users/:id", deleteUser(logger, scimService))
+
+	rg.GET("/Groups", listGroups(logger, scimService))
+	rg.GET("/Groups/:id", getGroup(logger, scimService))
+	rg.PUT("/Groups/:id", replaceGroup(logger, scimService))
+	rg.PATCH("/Groups/:id", patchGroup(logger, scimService))
+	// groups are codebases, and are created and deleted in Sturdy
+	rg.POST("/Groups", notImplemented)
+	rg.DELETE("/Groups/:id", notImplemented)
+}
+
+func authenticate(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			respondError(c, http.StatusUnauthorized, "", "missing bearer token")
+			return
+		}
+
+		token, err := scimService.Authenticate(c.Request.Context(), strings.TrimPrefix(header, "Bearer "))
+		switch {
+		case err == nil:
+		case errors.Is(err, service_scim.ErrInvalidToken),
+			errors.Is(err, service_scim.ErrTokenRevoked):
+			respondError(c, http.StatusUnauthorized, "", err.Error())
+			return
+		default:
+			logger.Error("failed to authenticate", zap.Error(err))
+			respondError(c, http.StatusInternalServerError, "", "internal error")
+			return
+		}
+
+		c.Set(tokenKey, token)
+		c.Next()
+	}
+}
+
+func tokenFromContext(c *gin.Context) *scim.Token {
+	return c.MustGet(tokenKey).(*scim.Token)
+}
+
+func respond(c *gin.Context, status int, v any) {
+	c.Header("Content-Type", scim.ContentType)
+	c.JSON(status, v)
+}
+
+func respondError(c *gin.Context, status int, scimType, detail string) {
+	c.Header("Content-Type", scim.ContentType)
+	c.AbortWithStatusJSON(status, scim.Error{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func handleError(c *gin.Context, logger *zap.Logger, err error) {
+	switch {
+	case errors.Is(err, service_scim.ErrNotFound):
+		respondError(c, http.StatusNotFound, "", err.Error())
+	case errors.Is(err, service_scim.ErrUniqueness):
+		respondError(c, http.StatusConflict, "uniqueness", err.Error())
+	case errors.Is(err, service_scim.ErrInvalidValue):
+		respondError(c, http.StatusBadRequest, "invalidValue", err.Error())
+	case errors.Is(err, scim.ErrInvalidFilter):
+		respondError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+	default:
+		logger.Error("scim request failed", zap.Error(err))
+		respondError(c, http.StatusInternalServerError, "", "internal error")
+	}
+}
+
+func notImplemented(c *gin.Context) {
+	respondError(c, http.StatusNotImplemented, "", "not supported")
+}
+
+func serviceProviderConfig(c *gin.Context) {
+	supported := func(b bool) gin.H { return gin.H{"supported": b} }
+	respond(c, http.StatusOK, gin.H{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": defaultCount},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with an organization SCIM token",
+		}},
+	})
+}
+
+// list responds with a page of resources. startIndex is 1-based.
+func list[T any](c *gin.Context, resources []T) {
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(defaultCount)))
+	if err != nil || count < 0 {
+		count = defaultCount
+	}
+
+	page := make([]any, 0, count)
+	for i := startIndex - 1; i < len(resources) && len(page) < count; i++ {
+		page = append(page, resources[i])
+	}
+
+	respond(c, http.StatusOK, scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+func listUsers(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := scim.ParseFilter(c.Query("filter"))
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+
+		uu, err := scimService.ListUsers(c.Request.Context(), tokenFromContext(c), filter)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+
+		list(c, uu)
+	}
+}
+
+func getUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := scimService.GetUser(c.Request.Context(), tokenFromContext(c), c.Param("id"))
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, user)
+	}
+}
+
+func createUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var in scim.User
+		if err := c.ShouldBindJSON(&in); err != nil {
+			respondError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		user, err := scimService.CreateUser(c.Request.Context(), tokenFromContext(c), &in)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusCreated, user)
+	}
+}
+
+func replaceUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var in scim.User
+		if err := c.ShouldBindJSON(&in); err != nil {
+			respondError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		user, err := scimService.ReplaceUser(c.Request.Context(), tokenFromContext(c), c.Param("id"), &in)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, user)
+	}
+}
+
+func patchUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req scim.PatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		user, err := scimService.PatchUser(c.Request.Context(), tokenFromContext(c), c.Param("id"), &req)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, user)
+	}
+}
+
+func deleteUser(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := scimService.DeleteUser(c.Request.Context(), tokenFromContext(c), c.Param("id")); err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func listGroups(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := scim.ParseFilter(c.Query("filter"))
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+
+		groups, err := scimService.ListGroups(c.Request.Context(), tokenFromContext(c), filter)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+
+		list(c, groups)
+	}
+}
+
+func getGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group, err := scimService.GetGroup(c.Request.Context(), tokenFromContext(c), c.Param("id"))
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, group)
+	}
+}
+
+func replaceGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var in scim.Group
+		if err := c.ShouldBindJSON(&in); err != nil {
+			respondError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		group, err := scimService.ReplaceGroup(c.Request.Context(), tokenFromContext(c), c.Param("id"), &in)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, group)
+	}
+}
+
+func patchGroup(logger *zap.Logger, scimService *service_scim.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req scim.PatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		group, err := scimService.PatchGroup(c.Request.Context(), tokenFromContext(c), c.Param("id"), &req)
+		if err != nil {
+			handleError(c, logger, err)
+			return
+		}
+		respond(c, http.StatusOK, group)
+	}
+}
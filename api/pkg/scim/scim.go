@@ -0,0 +1,199 @@
+// Package scim implements the parts of SCIM 2.0 (RFC 7643 and RFC 7644) that are needed to provision users
+// into an organization from an identity provider.
+//
+// Users are the users of the organization, and groups are the codebases of the organization.
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// ContentType is the media type of all SCIM requests and responses.
+const ContentType = "application/scim+json"
+
+// ProvisionedUser is a user that has been provisioned into an organization through SCIM. It's kept after
+// the user is deprovisioned, so that the identity provider can still see the user as inactive.
+type ProvisionedUser struct {
+	OrganizationID string    `db:"organization_id"`
+	UserID         users.ID  `db:"user_id"`
+	ExternalID     *string   `db:"external_id"`
+	Active         bool      `db:"active"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// String returns the formatted name, or the given and family names joined if it's not set.
+func (n *Name) String() string {
+	if n == nil {
+		return ""
+	}
+	if n.Formatted != "" {
+		return n.Formatted
+	}
+	return strings.TrimSpace(n.GivenName + " " + n.FamilyName)
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  *string  `json:"externalId,omitempty"`
+	UserName    string   `json:"userName"`
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	// Active is a pointer so that a missing value can be told apart from false, users are active by default.
+	Active *bool `json:"active,omitempty"`
+	Meta   *Meta `json:"meta,omitempty"`
+}
+
+// Email returns the email address of the user. Sturdy identifies users by email, so userName is expected
+// to be an email address, with the primary email as a fallback.
+func (u *User) Email() string {
+	if strings.Contains(u.UserName, "@") {
+		return strings.TrimSpace(u.UserName)
+	}
+	for _, email := range u.Emails {
+		if email.Primary {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	if len(u.Emails) > 0 {
+		return strings.TrimSpace(u.Emails[0].Value)
+	}
+	return ""
+}
+
+// IsActive returns false only if the user is explicitly marked as inactive.
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+)
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// ErrInvalidFilter is returned for filters that are not supported.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Filter is a single equality filter, which is what identity providers use to look up users and groups.
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+// ParseFilter parses filters of the form `attribute eq "value"`. An empty filter returns nil.
+func ParseFilter(raw string) (*Filter, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(raw, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, fmt.Errorf("%w: only 'eq' filters are supported", ErrInvalidFilter)
+	}
+
+	value := strings.TrimSpace(parts[2])
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return nil, fmt.Errorf("%w: value must be a quoted string", ErrInvalidFilter)
+	}
+
+	var unquoted string
+	if err := json.Unmarshal([]byte(value), &unquoted); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+	}
+
+	return &Filter{
+		Attribute: parts[0],
+		Value:     unquoted,
+	}, nil
+}
+
+// ParseMemberPath returns the member id from a path like `members[value eq "id"]`. ok is false if the
+// path doesn't select a single member.
+func ParseMemberPath(path string) (string, bool) {
+	if !strings.HasPrefix(path, "members[") || !strings.HasSuffix(path, "]") {
+		return "", false
+	}
+	filter, err := ParseFilter(path[len("members[") : len(path)-1])
+	if err != nil || filter == nil || filter.Attribute != "value" {
+		return "", false
+	}
+	return filter.Value, true
+}
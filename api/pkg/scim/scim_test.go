@@ -0,0 +1,74 @@
+package scim_test
+
+import (
+	"testing"
+
+	"getsturdy.com/api/pkg/scim"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      string
+		expected *scim.Filter
+		err      bool
+	}{
+		{name: "empty", raw: "", expected: nil},
+		{name: "username", raw: `userName eq "jane@example.com"`, expected: &scim.Filter{Attribute: "userName", Value: "jane@example.com"}},
+		{name: "uppercase operator", raw: `displayName EQ "my codebase"`, expected: &scim.Filter{Attribute: "displayName", Value: "my codebase"}},
+		{name: "escaped quote", raw: `displayName eq "a \"b\""`, expected: &scim.Filter{Attribute: "displayName", Value: `a "b"`}},
+		{name: "unsupported operator", raw: `userName co "jane"`, err: true},
+		{name: "unquoted value", raw: `userName eq jane`, err: true},
+		{name: "missing value", raw: `userName eq`, err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := scim.ParseFilter(tc.raw)
+			if tc.err {
+				assert.ErrorIs(t, err, scim.ErrInvalidFilter)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, filter)
+			}
+		})
+	}
+}
+
+func TestParseMemberPath(t *testing.T) {
+	id, ok := scim.ParseMemberPath(`members[value eq "user-id"]`)
+	assert.True(t, ok)
+	assert.Equal(t, "user-id", id)
+
+	_, ok = scim.ParseMemberPath("members")
+	assert.False(t, ok)
+
+	_, ok = scim.ParseMemberPath(`members[display eq "jane"]`)
+	assert.False(t, ok)
+}
+
+func TestUser_Email(t *testing.T) {
+	assert.Equal(t, "jane@example.com", (&scim.User{UserName: "jane@example.com"}).Email())
+	assert.Equal(t, "jane@example.com", (&scim.User{UserName: "jane", Emails: []scim.Email{
+		{Value: "other@example.com"},
+		{Value: "jane@example.com", Primary: true},
+	}}).Email())
+	assert.Equal(t, "", (&scim.User{UserName: "jane"}).Email())
+}
+
+func TestParseToken(t *testing.T) {
+	id, secret, ok := scim.ParseToken(scim.FormatToken("token-id", "secret"))
+	assert.True(t, ok)
+	assert.Equal(t, "token-id", id)
+	assert.Equal(t, "secret", secret)
+
+	_, _, ok = scim.ParseToken("sturdy_pat_token-id_secret")
+	assert.False(t, ok)
+
+	token := &scim.Token{Hash: scim.HashSecret("secret")}
+	assert.True(t, token.Verify("secret"))
+	assert.False(t, token.Verify("other"))
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+)
+
+// ListGroups returns the codebases of the organization as groups.
+func (s *Service) ListGroups(ctx context.Context, token *scim.Token, filter *scim.Filter) ([]*scim.Group, error) {
+	if filter != nil && filter.Attribute != "displayName" {
+		return nil, fmt.Errorf("%w: can not filter groups by %s", scim.ErrInvalidFilter, filter.Attribute)
+	}
+
+	cbs, err := s.codebaseService.ListByOrganization(ctx, token.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list codebases: %w", err)
+	}
+
+	res := make([]*scim.Group, 0, len(cbs))
+	for _, cb := range cbs {
+		if filter != nil && !strings.EqualFold(cb.Name, filter.Value) {
+			continue
+		}
+		group, err := s.toGroup(ctx, cb)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, group)
+	}
+	return res, nil
+}
+
+func (s *Service) GetGroup(ctx context.Context, token *scim.Token, id string) (*scim.Group, error) {
+	cb, err := s.getCodebase(ctx, token, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toGroup(ctx, cb)
+}
+
+func (s *Service) getCodebase(ctx context.Context, token *scim.Token, id string) (*codebases.Codebase, error) {
+	cb, err := s.codebaseService.GetByID(ctx, codebases.ID(id))
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("failed to get codebase: %w", err)
+	}
+
+	if cb.OrganizationID == nil || *cb.OrganizationID != token.OrganizationID {
+		return nil, ErrNotFound
+	}
+
+	return cb, nil
+}
+
+// ReplaceGroup sets the members of the codebase. The codebase itself can not be changed.
+func (s *Service) ReplaceGroup(ctx context.Context, token *scim.Token, id string, in *scim.Group) (*scim.Group, error) {
+	cb, err := s.getCodebase(ctx, token, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.replaceMembers(ctx, token, cb, in.Members); err != nil {
+		return nil, err
+	}
+
+	return s.toGroup(ctx, cb)
+}
+
+// PatchGroup applies a PATCH request to the members of the codebase. Operations on other attributes are
+// ignored.
+func (s *Service) PatchGroup(ctx context.Context, token *scim.Token, id string, req *scim.PatchRequest) (*scim.Group, error) {
+	cb, err := s.getCodebase(ctx, token, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range req.Operations {
+		if op.Path != "members" && !strings.HasPrefix(op.Path, "members[") {
+			continue
+		}
+
+		var members []scim.Member
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return nil, fmt.Errorf("%w: members: %s", ErrInvalidValue, err)
+			}
+		}
+
+		switch strings.ToLower(op.Op) {
+		case scim.PatchOpAdd:
+			for _, member := range members {
+				if err := s.addMember(ctx, token, cb, users.ID(member.Value)); err != nil {
+					return nil, err
+				}
+			}
+		case scim.PatchOpRemove:
+			if id, ok := scim.ParseMemberPath(op.Path); ok {
+				members = append(members, scim.Member{Value: id})
+			}
+			for _, member := range members {
+				if err := s.removeMember(ctx, cb, users.ID(member.Value)); err != nil {
+					return nil, err
+				}
+			}
+		case scim.PatchOpReplace:
+			if err := s.replaceMembers(ctx, token, cb, members); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported operation %q", ErrInvalidValue, op.Op)
+		}
+	}
+
+	return s.toGroup(ctx, cb)
+}
+
+func (s *Service) replaceMembers(ctx context.Context, token *scim.Token, cb *codebases.Codebase, members []scim.Member) error {
+	current, err := s.codebaseUserRepo.GetByCodebase(cb.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list members: %w", err)
+	}
+
+	keep := make(map[users.ID]bool, len(members))
+	for _, member := range members {
+		keep[users.ID(member.Value)] = true
+		if err := s.addMember(ctx, token, cb, users.ID(member.Value)); err != nil {
+			return err
+		}
+	}
+
+	for _, member := range current {
+		if keep[member.UserID] {
+			continue
+		}
+		if err := s.removeMember(ctx, cb, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addMember adds the user to the codebase. Users that are not members of the organization are provisioned
+// into it first.
+func (s *Service) addMember(ctx context.Context, token *scim.Token, cb *codebases.Codebase, userID users.ID) error {
+	user, provisioned, isMember, err := s.getUser(ctx, token, userID)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotFound):
+		return fmt.Errorf("%w: unknown member %s", ErrInvalidValue, userID)
+	default:
+		return err
+	}
+
+	if !isMember || user.Status == users.StatusDeactivated {
+		var externalID *string
+		if provisioned != nil {
+			externalID = provisioned.ExternalID
+		}
+		if _, err := s.setActive(ctx, token, user, true, externalID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.codebaseService.AddUser(ctx, cb.ID, user); err != nil {
+		return fmt.Errorf("failed to add user to codebase: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) removeMember(ctx context.Context, cb *codebases.Codebase, userID users.ID) error {
+	_, err := s.codebaseUserRepo.GetByUserAndCodebase(userID, cb.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return fmt.Errorf("failed to get codebase member: %w", err)
+	}
+
+	if err := s.codebaseService.RemoveUser(ctx, cb.ID, userID); err != nil {
+		return fmt.Errorf("failed to remove user from codebase: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) toGroup(ctx context.Context, cb *codebases.Codebase) (*scim.Group, error) {
+	codebaseUsers, err := s.codebaseUserRepo.GetByCodebase(cb.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	members := make([]scim.Member, 0, len(codebaseUsers))
+	if len(codebaseUsers) > 0 {
+		ids := make([]users.ID, 0, len(codebaseUsers))
+		for _, cu := range codebaseUsers {
+			ids = append(ids, cu.UserID)
+		}
+		uu, err := s.userService.GetByIDs(ctx, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		for _, u := range uu {
+			members = append(members, scim.Member{Value: u.ID.String(), Display: u.Email})
+		}
+	}
+
+	return &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          cb.ID.String(),
+		DisplayName: cb.Name,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      cb.CreatedAt,
+		},
+	}, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/scim"
+	db_scim "getsturdy.com/api/pkg/scim/db"
+	"getsturdy.com/api/pkg/users"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	secretBytes = 32
+
+	// lastUsedPrecision limits how often the last used timestamp is written.
+	lastUsedPrecision = time.Minute
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrTokenRevoked   = errors.New("token has been revoked")
+	ErrAlreadyRevoked = errors.New("token is already revoked")
+
+	ErrNotFound     = errors.New("resource not found")
+	ErrUniqueness   = errors.New("resource already exists")
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+type Service struct {
+	logger              *zap.Logger
+	tokenRepo           db_scim.TokenRepository
+	userRepo            db_scim.UserRepository
+	userService         service_users.Service
+	organizationService *service_organization.Service
+	codebaseService     *service_codebase.Service
+	codebaseUserRepo    db_codebases.CodebaseUserRepository
+}
+
+func New(
+	logger *zap.Logger,
+	tokenRepo db_scim.TokenRepository,
+	userRepo db_scim.UserRepository,
+	userService service_users.Service,
+	organizationService *service_organization.Service,
+	codebaseService *service_codebase.Service,
+	codebaseUserRepo db_codebases.CodebaseUserRepository,
+) *Service {
+	return &Service{
+		logger:              logger.Named("scim"),
+		tokenRepo:           tokenRepo,
+		userRepo:            userRepo,
+		userService:         userService,
+		organizationService: organizationService,
+		codebaseService:     codebaseService,
+		codebaseUserRepo:    codebaseUserRepo,
+	}
+}
+
+// CreateToken creates a new SCIM token for the organization. It returns the token in plaintext (not
+// stored), and the stored token.
+func (s *Service) CreateToken(ctx context.Context, organizationID string, createdBy users.ID) (string, *scim.Token, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	plainTextSecret := hex.EncodeToString(secret)
+
+	token := &scim.Token{
+		ID:             uuid.NewString(),
+		OrganizationID: organizationID,
+		Hash:           scim.HashSecret(plainTextSecret),
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", nil, fmt.Errorf("failed to create: %w", err)
+	}
+
+	return scim.FormatToken(token.ID, plainTextSecret), token, nil
+}
+
+func (s *Service) GetToken(ctx context.Context, id string) (*scim.Token, error) {
+	return s.tokenRepo.GetByID(ctx, id)
+}
+
+// ListTokensByOrganizationID returns all tokens of the organization that have not been revoked.
+func (s *Service) ListTokensByOrganizationID(ctx context.Context, organizationID string) ([]*scim.Token, error) {
+	tokens, err := s.tokenRepo.ListByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken makes the token unusable. Revoked tokens can not be restored.
+func (s *Service) RevokeToken(ctx context.Context, token *scim.Token) error {
+	if token.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+
+	if err := s.tokenRepo.Update(ctx, token); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the token that matches the plain text token, if it has not been revoked.
+func (s *Service) Authenticate(ctx context.Context, plainText string) (*scim.Token, error) {
+	id, secret, ok := scim.ParseToken(plainText)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.tokenRepo.GetByID(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrInvalidToken
+	default:
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if !token.Verify(secret) {
+		return nil, ErrInvalidToken
+	}
+
+	if token.RevokedAt != nil {
+		return nil, ErrTokenRevoked
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedPrecision {
+		token.LastUsedAt = &now
+		if err := s.tokenRepo.Update(ctx, token); err != nil {
+			return nil, fmt.Errorf("failed to update last used: %w", err)
+		}
+	}
+
+	return token, nil
+}
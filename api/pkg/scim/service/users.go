@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/scim"
+	"getsturdy.com/api/pkg/users"
+)
+
+// referer is the referer of shadow users that are created through SCIM.
+type referer struct {
+	organizationID string
+}
+
+func (r *referer) URL() string {
+	u := url.URL{
+		Scheme: "referer",
+		Host:   "scim",
+		Path:   r.organizationID,
+	}
+	return u.String()
+}
+
+// isProvisionedBy returns true if the user was created through SCIM by the organization. Only the accounts
+// of those users are managed by the organization, other users are only added to and removed from it.
+func isProvisionedBy(user *users.User, organizationID string) bool {
+	return user.Referer != nil && *user.Referer == (&referer{organizationID: organizationID}).URL()
+}
+
+// ListUsers returns the members of the organization, and users that have been deprovisioned from it.
+func (s *Service) ListUsers(ctx context.Context, token *scim.Token, filter *scim.Filter) ([]*scim.User, error) {
+	if filter != nil && filter.Attribute != "userName" && filter.Attribute != "externalId" {
+		return nil, fmt.Errorf("%w: can not filter users by %s", scim.ErrInvalidFilter, filter.Attribute)
+	}
+
+	members, err := s.organizationService.Members(ctx, token.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	provisioned, err := s.userRepo.ListByOrganizationID(ctx, token.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provisioned users: %w", err)
+	}
+
+	isMember := make(map[users.ID]bool, len(members))
+	ids := make([]users.ID, 0, len(members)+len(provisioned))
+	for _, member := range members {
+		isMember[member.UserID] = true
+		ids = append(ids, member.UserID)
+	}
+	provisionedByID := make(map[users.ID]*scim.ProvisionedUser, len(provisioned))
+	for _, p := range provisioned {
+		provisionedByID[p.UserID] = p
+		if !isMember[p.UserID] {
+			ids = append(ids, p.UserID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return []*scim.User{}, nil
+	}
+
+	uu, err := s.userService.GetByIDs(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	res := make([]*scim.User, 0, len(uu))
+	for _, u := range uu {
+		user := toUser(u, provisionedByID[u.ID], isMember[u.ID])
+		if filter != nil && !matchesUser(user, filter) {
+			continue
+		}
+		res = append(res, user)
+	}
+	return res, nil
+}
+
+func matchesUser(user *scim.User, filter *scim.Filter) bool {
+	switch filter.Attribute {
+	case "userName":
+		return strings.EqualFold(user.UserName, filter.Value)
+	case "externalId":
+		return user.ExternalID != nil && *user.ExternalID == filter.Value
+	default:
+		return false
+	}
+}
+
+// GetUser returns a user that is, or has been, provisioned into the organization.
+func (s *Service) GetUser(ctx context.Context, token *scim.Token, id string) (*scim.User, error) {
+	user, provisioned, isMember, err := s.getUser(ctx, token, users.ID(id))
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user, provisioned, isMember), nil
+}
+
+func (s *Service) getUser(ctx context.Context, token *scim.Token, id users.ID) (*users.User, *scim.ProvisionedUser, bool, error) {
+	user, err := s.userService.GetByID(ctx, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil, false, ErrNotFound
+	default:
+		return nil, nil, false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	isMember, err := s.organizationService.CanAccess(ctx, user.ID, token.OrganizationID)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	provisioned, err := s.userRepo.Get(ctx, token.OrganizationID, user.ID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		provisioned = nil
+		// users that are not visible to the token are reported as not found
+		if !isMember {
+			return nil, nil, false, ErrNotFound
+		}
+	default:
+		return nil, nil, false, fmt.Errorf("failed to get provisioned user: %w", err)
+	}
+
+	return user, provisioned, isMember, nil
+}
+
+// CreateUser provisions a user into the organization. If there is no user with the email address, a new
+// user is created, who can log in with single sign-on or by signing up with the same email address.
+func (s *Service) CreateUser(ctx context.Context, token *scim.Token, in *scim.User) (*scim.User, error) {
+	email := in.Email()
+	if email == "" {
+		return nil, fmt.Errorf("%w: userName must be an email address", ErrInvalidValue)
+	}
+
+	user, err := s.userService.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		isMember, err := s.organizationService.CanAccess(ctx, user.ID, token.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check membership: %w", err)
+		}
+		if isMember {
+			return nil, fmt.Errorf("%w: %s is already a member of the organization", ErrUniqueness, email)
+		}
+		if !in.IsActive() {
+			return nil, fmt.Errorf("%w: %s is not a member of the organization, and can not be provisioned as inactive", ErrInvalidValue, email)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		var name *string
+		if n := in.Name.String(); n != "" {
+			name = &n
+		} else if in.DisplayName != "" {
+			name = &in.DisplayName
+		}
+		if user, err = s.userService.CreateShadow(ctx, email, &referer{organizationID: token.OrganizationID}, name); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	provisioned, err := s.setActive(ctx, token, user, in.IsActive(), in.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUser(user, provisioned, provisioned.Active), nil
+}
+
+// ReplaceUser updates the user from a full representation of it. Only active and externalId can be
+// changed, other attributes are owned by the user.
+func (s *Service) ReplaceUser(ctx context.Context, token *scim.Token, id string, in *scim.User) (*scim.User, error) {
+	user, _, _, err := s.getUser(ctx, token, users.ID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	provisioned, err := s.setActive(ctx, token, user, in.IsActive(), in.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUser(user, provisioned, provisioned.Active), nil
+}
+
+// PatchUser applies a PATCH request to the user. Only active and externalId can be changed, operations on
+// other attributes are ignored.
+func (s *Service) PatchUser(ctx context.Context, token *scim.Token, id string, req *scim.PatchRequest) (*scim.User, error) {
+	user, provisioned, isMember, err := s.getUser(ctx, token, users.ID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	active := isMember && user.Status != users.StatusDeactivated
+	var externalID *string
+	if provisioned != nil {
+		externalID = provisioned.ExternalID
+	}
+
+	for _, op := range req.Operations {
+		switch strings.ToLower(op.Op) {
+		case scim.PatchOpAdd, scim.PatchOpReplace:
+		default:
+			return nil, fmt.Errorf("%w: unsupported operation %q", ErrInvalidValue, op.Op)
+		}
+
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidValue, err)
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+
+		for path, value := range values {
+			switch path {
+			case "active":
+				if active, err = parseBool(value); err != nil {
+					return nil, err
+				}
+			case "externalId":
+				var v string
+				if err := json.Unmarshal(value, &v); err != nil {
+					return nil, fmt.Errorf("%w: externalId: %s", ErrInvalidValue, err)
+				}
+				externalID = &v
+			}
+		}
+	}
+
+	if provisioned, err = s.setActive(ctx, token, user, active, externalID); err != nil {
+		return nil, err
+	}
+
+	return toUser(user, provisioned, provisioned.Active), nil
+}
+
+// parseBool parses a boolean, some identity providers send booleans as strings.
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, fmt.Errorf("%w: expected a boolean", ErrInvalidValue)
+	}
+	b, err := strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, fmt.Errorf("%w: expected a boolean", ErrInvalidValue)
+	}
+	return b, nil
+}
+
+// DeleteUser deprovisions the user from the organization, the same way as setting active to false.
+func (s *Service) DeleteUser(ctx context.Context, token *scim.Token, id string) error {
+	user, provisioned, _, err := s.getUser(ctx, token, users.ID(id))
+	if err != nil {
+		return err
+	}
+
+	var externalID *string
+	if provisioned != nil {
+		externalID = provisioned.ExternalID
+	}
+
+	if _, err := s.setActive(ctx, token, user, false, externalID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setActive provisions the user into, or deprovisions the user from, the organization.
+//
+// Deprovisioned users are removed from the organization and all of its codebases. Users that were created by
+// the organization are also deactivated if they are not a member of any other organization, which revokes all
+// of their sessions and tokens. Only those users are reactivated when they are provisioned again.
+func (s *Service) setActive(ctx context.Context, token *scim.Token, user *users.User, active bool, externalID *string) (*scim.ProvisionedUser, error) {
+	isMember, err := s.organizationService.CanAccess(ctx, user.ID, token.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+
+	if active {
+		if isProvisionedBy(user, token.OrganizationID) {
+			if err := s.userService.Reactivate(ctx, user); err != nil {
+				return nil, fmt.Errorf("failed to reactivate user: %w", err)
+			}
+		}
+		if !isMember {
+			if _, err := s.organizationService.AddMember(ctx, token.OrganizationID, user.ID, token.CreatedBy); err != nil {
+				return nil, fmt.Errorf("failed to add member: %w", err)
+			}
+		}
+	} else {
+		if err := s.deprovision(ctx, token, user, isMember); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	provisioned := &scim.ProvisionedUser{
+		OrganizationID: token.OrganizationID,
+		UserID:         user.ID,
+		ExternalID:     externalID,
+		Active:         active,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.userRepo.Upsert(ctx, provisioned); err != nil {
+		return nil, fmt.Errorf("failed to save provisioned user: %w", err)
+	}
+
+	return provisioned, nil
+}
+
+func (s *Service) deprovision(ctx context.Context, token *scim.Token, user *users.User, isMember bool) error {
+	cbs, err := s.codebaseService.ListByOrganizationAndUser(ctx, token.OrganizationID, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list codebases: %w", err)
+	}
+	for _, cb := range cbs {
+		if err := s.codebaseService.RemoveUser(ctx, cb.ID, user.ID); err != nil {
+			return fmt.Errorf("failed to remove user from codebase: %w", err)
+		}
+	}
+
+	if isMember {
+		if err := s.organizationService.RemoveMember(ctx, token.OrganizationID, user.ID, token.CreatedBy); err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+	}
+
+	if !isProvisionedBy(user, token.OrganizationID) {
+		return nil
+	}
+
+	otherOrganizations, err := s.organizationService.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+	if len(otherOrganizations) > 0 {
+		return nil
+	}
+
+	if err := s.userService.Deactivate(ctx, user); err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+	return nil
+}
+
+func toUser(u *users.User, provisioned *scim.ProvisionedUser, isMember bool) *scim.User {
+	active := isMember && u.Status != users.StatusDeactivated
+	user := &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          u.ID.String(),
+		UserName:    u.Email,
+		Name:        &scim.Name{Formatted: u.Name},
+		DisplayName: u.Name,
+		Emails:      []scim.Email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+		},
+	}
+	if provisioned != nil {
+		user.ExternalID = provisioned.ExternalID
+		user.Meta.LastModified = &provisioned.UpdatedAt
+	}
+	return user
+}
@@ -0,0 +1,55 @@
+package scim
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+// TokenPrefix is prepended to all SCIM tokens, so that they can be told apart from other tokens.
+const TokenPrefix = "sturdy_scim_"
+
+// Token authenticates an identity provider that provisions users into an organization.
+type Token struct {
+	ID             string `db:"id" json:"id"`
+	OrganizationID string `db:"organization_id" json:"organization_id"`
+	// Hash is a sha256 hash of the secret.
+	Hash []byte `db:"hash" json:"-"`
+	// CreatedBy is the user that created the token. Changes made with the token are made on behalf of
+	// this user.
+	CreatedBy  users.ID   `db:"created_by" json:"created_by"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+func HashSecret(secret string) []byte {
+	h := sha256.Sum256([]byte(secret))
+	return h[:]
+}
+
+func (t *Token) Verify(secret string) bool {
+	return subtle.ConstantTimeCompare(t.Hash, HashSecret(secret)) == 1
+}
+
+// FormatToken returns the plain text token that is given to the user.
+func FormatToken(id, secret string) string {
+	return fmt.Sprintf("%s%s_%s", TokenPrefix, id, secret)
+}
+
+// ParseToken splits a plain text token into the token id and the secret. ok is false if the value is not
+// a SCIM token.
+func ParseToken(plainText string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(plainText, TokenPrefix) {
+		return "", "", false
+	}
+	idx := strings.LastIndex(plainText, "_")
+	if idx <= len(TokenPrefix) || idx == len(plainText)-1 {
+		return "", "", false
+	}
+	return plainText[len(TokenPrefix):idx], plainText[idx+1:], true
+}
@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/servicetokens"
 
 	"github.com/jmoiron/sqlx"
@@ -23,9 +26,9 @@ func NewDatabase(db *sqlx.DB) Repository {
 func (d *database) Create(ctx context.Context, token *servicetokens.Token) error {
 	if _, err := d.db.NamedExecContext(ctx, `
 		INSERT INTO servicetokens (
-			id, codebase_id, hash, name, created_at, last_used_at
+			id, codebase_id, hash, name, scopes, created_at, last_used_at, expires_at, revoked_at
 		) VALUES (
-			:id, :codebase_id, :hash, :name, :created_at, :last_used_at
+			:id, :codebase_id, :hash, :name, :scopes, :created_at, :last_used_at, :expires_at, :revoked_at
 		)
 	`, token); err != nil {
 		return fmt.Errorf("failed to insert: %w", err)
@@ -33,11 +36,37 @@ func (d *database) Create(ctx context.Context, token *servicetokens.Token) error
 	return nil
 }
 
+func (d *database) Update(ctx context.Context, token *servicetokens.Token) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE servicetokens
+		SET name = :name,
+			scopes = :scopes,
+			last_used_at = :last_used_at,
+			expires_at = :expires_at,
+			revoked_at = :revoked_at
+		WHERE id = :id
+	`, token); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *database) UpdateLastUsedAt(ctx context.Context, id string, lastUsedAt time.Time) error {
+	if _, err := d.db.ExecContext(ctx, `
+		UPDATE servicetokens
+		SET last_used_at = $2
+		WHERE id = $1
+	`, id, lastUsedAt); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
 func (d *database) GetByID(ctx context.Context, id string) (*servicetokens.Token, error) {
 	token := &servicetokens.Token{}
 	if err := d.db.GetContext(ctx, token, `
 		SELECT
-			id, codebase_id, hash, name, created_at, last_used_at
+			id, codebase_id, hash, name, scopes, created_at, last_used_at, expires_at, revoked_at
 		FROM servicetokens
 		WHERE id = $1
 	`, id); err != nil {
@@ -45,3 +74,18 @@ func (d *database) GetByID(ctx context.Context, id string) (*servicetokens.Token
 	}
 	return token, nil
 }
+
+func (d *database) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*servicetokens.Token, error) {
+	var tokens []*servicetokens.Token
+	if err := d.db.SelectContext(ctx, &tokens, `
+		SELECT
+			id, codebase_id, hash, name, scopes, created_at, last_used_at, expires_at, revoked_at
+		FROM servicetokens
+		WHERE codebase_id = $1
+		  AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, codebaseID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return tokens, nil
+}
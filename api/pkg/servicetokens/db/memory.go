@@ -3,7 +3,10 @@ package db
 import (
 	"context"
 	"database/sql"
+	"sort"
+	"time"
 
+	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/servicetokens"
 )
 
@@ -29,6 +32,15 @@ func (m *memory) Update(_ context.Context, token *servicetokens.Token) error {
 	return nil
 }
 
+func (m *memory) UpdateLastUsedAt(_ context.Context, id string, lastUsedAt time.Time) error {
+	token, found := m.byID[id]
+	if !found {
+		return sql.ErrNoRows
+	}
+	token.LastUsedAt = &lastUsedAt
+	return nil
+}
+
 func (m *memory) GetByID(_ context.Context, id string) (*servicetokens.Token, error) {
 	token, found := m.byID[id]
 	if !found {
@@ -37,6 +49,19 @@ func (m *memory) GetByID(_ context.Context, id string) (*servicetokens.Token, er
 	return token, nil
 }
 
+func (m *memory) ListByCodebaseID(_ context.Context, codebaseID codebases.ID) ([]*servicetokens.Token, error) {
+	var tokens []*servicetokens.Token
+	for _, token := range m.byID {
+		if token.CodebaseID == codebaseID && token.RevokedAt == nil {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
 func (m *memory) DeleteByID(_ context.Context, id string) error {
 	delete(m.byID, id)
 	return nil
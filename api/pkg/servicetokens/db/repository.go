@@ -2,11 +2,18 @@ package db
 
 import (
 	"context"
+	"time"
 
+	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/servicetokens"
 )
 
 type Repository interface {
 	Create(context.Context, *servicetokens.Token) error
+	Update(context.Context, *servicetokens.Token) error
+	// UpdateLastUsedAt only sets the last used timestamp, so that it can't undo a concurrent revocation.
+	UpdateLastUsedAt(ctx context.Context, id string, lastUsedAt time.Time) error
 	GetByID(context.Context, string) (*servicetokens.Token, error)
+	// ListByCodebaseID returns all tokens of the codebase that have not been revoked.
+	ListByCodebaseID(context.Context, codebases.ID) ([]*servicetokens.Token, error)
 }
@@ -2,7 +2,9 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	service_auth "getsturdy.com/api/pkg/auth/service"
 	"getsturdy.com/api/pkg/codebases"
@@ -33,6 +35,29 @@ func New(
 	}
 }
 
+func (r *rootResolver) InternalListByCodebaseID(ctx context.Context, codebaseID graphql.ID) ([]resolvers.ServiceTokenResovler, error) {
+	codebase, err := r.codebaseService.GetByID(ctx, codebases.ID(codebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// only users that can create tokens can see them
+	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+		return []resolvers.ServiceTokenResovler{}, nil
+	}
+
+	tokens, err := r.serviceTokensService.ListByCodebaseID(ctx, codebase.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.ServiceTokenResovler, 0, len(tokens))
+	for _, token := range tokens {
+		res = append(res, &resolver{token: token})
+	}
+	return res, nil
+}
+
 func (r *rootResolver) CreateServiceToken(ctx context.Context, args resolvers.CreateServiceTokenArgs) (resolvers.ServiceTokenResovler, error) {
 	codebase, err := r.codebaseService.GetByShortID(ctx, codebases.ShortCodebaseID(args.Input.ShortCodebaseID))
 	if err != nil {
@@ -43,8 +68,29 @@ func (r *rootResolver) CreateServiceToken(ctx context.Context, args resolvers.Cr
 		return nil, gqlerror.Error(err)
 	}
 
-	plainTextToken, token, err := r.serviceTokensService.Create(ctx, codebase.ID, args.Input.Name)
-	if err != nil {
+	scopes := servicetokens.DefaultScopes
+	if args.Input.Scopes != nil {
+		scopes = make([]servicetokens.Scope, 0, len(*args.Input.Scopes))
+		for _, scope := range *args.Input.Scopes {
+			scopes = append(scopes, servicetokens.Scope(scope))
+		}
+	}
+
+	var expiresAt *time.Time
+	if args.Input.ExpiresAt != nil {
+		t := time.Unix(int64(*args.Input.ExpiresAt), 0)
+		expiresAt = &t
+	}
+
+	plainTextToken, token, err := r.serviceTokensService.Create(ctx, codebase.ID, args.Input.Name, scopes, expiresAt)
+	switch {
+	case err == nil:
+	case errors.Is(err, service_servicetokens.ErrNoScopes),
+		errors.Is(err, service_servicetokens.ErrInvalidScope):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "scopes", err.Error())
+	case errors.Is(err, service_servicetokens.ErrInvalidExpiry):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "expiresAt", err.Error())
+	default:
 		return nil, gqlerror.Error(fmt.Errorf("failed to create token: %w", err))
 	}
 
@@ -54,6 +100,32 @@ func (r *rootResolver) CreateServiceToken(ctx context.Context, args resolvers.Cr
 	}, nil
 }
 
+func (r *rootResolver) RevokeServiceToken(ctx context.Context, args resolvers.RevokeServiceTokenArgs) (resolvers.ServiceTokenResovler, error) {
+	token, err := r.serviceTokensService.Get(ctx, string(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	codebase, err := r.codebaseService.GetByID(ctx, token.CodebaseID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	switch err := r.serviceTokensService.Revoke(ctx, token); {
+	case err == nil:
+	case errors.Is(err, service_servicetokens.ErrAlreadyRevoked):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{token: token}, nil
+}
+
 type resolver struct {
 	plainTextToken *string
 	token          *servicetokens.Token
@@ -67,6 +139,10 @@ func (r *resolver) Name() string {
 	return r.token.Name
 }
 
+func (r *resolver) Scopes() []string {
+	return r.token.Scopes
+}
+
 func (r *resolver) CreatedAt() int32 {
 	return int32(r.token.CreatedAt.Unix())
 }
@@ -79,6 +155,14 @@ func (r *resolver) LastUsedAt() *int32 {
 	return &luat
 }
 
+func (r *resolver) ExpiresAt() *int32 {
+	if r.token.ExpiresAt == nil {
+		return nil
+	}
+	eat := int32(r.token.ExpiresAt.Unix())
+	return &eat
+}
+
 func (r *resolver) Token() *string {
 	return r.plainTextToken
 }
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/codebases"
 	"getsturdy.com/api/pkg/servicetokens"
 	db_servicetokens "getsturdy.com/api/pkg/servicetokens/db"
@@ -17,21 +20,53 @@ const (
 	bcryptCost = bcrypt.DefaultCost
 )
 
+var (
+	ErrNoScopes       = errors.New("at least one scope is required")
+	ErrInvalidScope   = errors.New("invalid scope")
+	ErrInvalidExpiry  = errors.New("expiry must be in the future")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrTokenInactive  = errors.New("token has expired or been revoked")
+	ErrMissingScope   = errors.New("token is missing a required scope")
+	ErrAlreadyRevoked = errors.New("token is already revoked")
+)
+
 type Service struct {
-	repo db_servicetokens.Repository
+	repo         db_servicetokens.Repository
+	auditService *service_audit.Service
 }
 
 func New(
 	repo db_servicetokens.Repository,
+	auditService *service_audit.Service,
 ) *Service {
 	return &Service{
-		repo: repo,
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
 // Create creates a new service token. It returns the created service token in plaintext (not stored) and the
 // servicetoken in encrypted form (can be recovered from the database).
-func (s *Service) Create(ctx context.Context, codebaseID codebases.ID, name string) (string, *servicetokens.Token, error) {
+//
+// If expiresAt is nil, the token never expires.
+func (s *Service) Create(ctx context.Context, codebaseID codebases.ID, name string, scopes []servicetokens.Scope, expiresAt *time.Time) (string, *servicetokens.Token, error) {
+	if len(scopes) == 0 {
+		return "", nil, ErrNoScopes
+	}
+
+	scopeStrings := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		scopeStrings = append(scopeStrings, string(scope))
+	}
+
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return "", nil, ErrInvalidExpiry
+	}
+
 	plainTextToken := uuid.New().String()
 	hashedToken, err := bcrypt.GenerateFromPassword([]byte(plainTextToken), bcryptCost)
 	if err != nil {
@@ -43,18 +78,98 @@ func (s *Service) Create(ctx context.Context, codebaseID codebases.ID, name stri
 		CodebaseID: codebaseID,
 		Hash:       hashedToken,
 		Name:       name,
-		CreatedAt:  time.Now(),
+		Scopes:     scopeStrings,
+		CreatedAt:  now,
+		ExpiresAt:  expiresAt,
 	}
 
 	if err := s.repo.Create(ctx, token); err != nil {
 		return "", nil, fmt.Errorf("failed to create: %w", err)
 	}
 
+	if err := s.auditService.Record(ctx, audit.ActionServiceTokenCreated, audit.TargetServiceToken, token.ID,
+		audit.CodebaseID(codebaseID),
+		audit.After(token),
+	); err != nil {
+		return "", nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	return plainTextToken, token, nil
 }
 
-// todo: updated last used
-
 func (s *Service) Get(ctx context.Context, id string) (*servicetokens.Token, error) {
 	return s.repo.GetByID(ctx, id)
 }
+
+// ListByCodebaseID returns all tokens of the codebase that have not been revoked, including expired tokens.
+func (s *Service) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*servicetokens.Token, error) {
+	tokens, err := s.repo.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke makes the token unusable. Revoked tokens can not be restored.
+func (s *Service) Revoke(ctx context.Context, token *servicetokens.Token) error {
+	if token.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+
+	before := *token
+	now := time.Now()
+	token.RevokedAt = &now
+
+	if err := s.repo.Update(ctx, token); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionServiceTokenRevoked, audit.TargetServiceToken, token.ID,
+		audit.CodebaseID(token.CodebaseID),
+		audit.Before(before),
+		audit.After(token),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the token with the given id if the secret matches, and if the token has the required
+// scope. See Authorize.
+func (s *Service) Authenticate(ctx context.Context, id, secret string, scope servicetokens.Scope) (*servicetokens.Token, error) {
+	token, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := token.Verify(secret); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.Authorize(ctx, token, scope); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Authorize checks that the token is active and has the required scope. On success, the last used
+// timestamp of the token is updated.
+func (s *Service) Authorize(ctx context.Context, token *servicetokens.Token, scope servicetokens.Scope) error {
+	now := time.Now()
+	if !token.IsActive(now) {
+		return ErrTokenInactive
+	}
+
+	if !token.HasScope(scope) {
+		return fmt.Errorf("%w: %s", ErrMissingScope, scope)
+	}
+
+	token.LastUsedAt = &now
+	if err := s.repo.UpdateLastUsedAt(ctx, token.ID, now); err != nil {
+		return fmt.Errorf("failed to update last used: %w", err)
+	}
+
+	return nil
+}
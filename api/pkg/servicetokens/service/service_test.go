@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/internal/inmemory"
+	"getsturdy.com/api/pkg/servicetokens"
+	db_servicetokens "getsturdy.com/api/pkg/servicetokens/db"
+	service_servicetokens "getsturdy.com/api/pkg/servicetokens/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newService(t *testing.T) (*service_servicetokens.Service, db_servicetokens.Repository) {
+	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
+	assert.NoError(t, codebaseRepo.Create(codebases.Codebase{ID: "codebase"}))
+	repo := db_servicetokens.NewMemory()
+	return service_servicetokens.New(repo, service_audit.New(db_audit.NewMemory(), codebaseRepo)), repo
+}
+
+func TestCreate_validation(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newService(t)
+
+	_, _, err := svc.Create(ctx, "codebase", "no scopes", nil, nil)
+	assert.ErrorIs(t, err, service_servicetokens.ErrNoScopes)
+
+	_, _, err = svc.Create(ctx, "codebase", "invalid scope", []servicetokens.Scope{"root"}, nil)
+	assert.ErrorIs(t, err, service_servicetokens.ErrInvalidScope)
+
+	// service tokens can't authenticate against the API
+	_, _, err = svc.Create(ctx, "codebase", "api", []servicetokens.Scope{"api:read"}, nil)
+	assert.ErrorIs(t, err, service_servicetokens.ErrInvalidScope)
+
+	past := time.Now().Add(-time.Hour)
+	_, _, err = svc.Create(ctx, "codebase", "expired", []servicetokens.Scope{servicetokens.ScopeGitRead}, &past)
+	assert.ErrorIs(t, err, service_servicetokens.ErrInvalidExpiry)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	svc, repo := newService(t)
+
+	secret, token, err := svc.Create(ctx, "codebase", "ci", []servicetokens.Scope{servicetokens.ScopeGitRead}, nil)
+	assert.NoError(t, err)
+
+	_, err = svc.Authenticate(ctx, token.ID, "wrong", servicetokens.ScopeGitRead)
+	assert.ErrorIs(t, err, service_servicetokens.ErrInvalidToken)
+
+	_, err = svc.Authenticate(ctx, token.ID, secret, servicetokens.ScopeStatusesWrite)
+	assert.ErrorIs(t, err, service_servicetokens.ErrMissingScope)
+
+	authenticated, err := svc.Authenticate(ctx, token.ID, secret, servicetokens.ScopeGitRead)
+	assert.NoError(t, err)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	past := time.Now().Add(-time.Minute)
+	token.ExpiresAt = &past
+	assert.NoError(t, repo.Update(ctx, token))
+
+	_, err = svc.Authenticate(ctx, token.ID, secret, servicetokens.ScopeGitRead)
+	assert.ErrorIs(t, err, service_servicetokens.ErrTokenInactive)
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newService(t)
+
+	secret, token, err := svc.Create(ctx, "codebase", "ci", servicetokens.DefaultScopes, nil)
+	assert.NoError(t, err)
+
+	tokens, err := svc.ListByCodebaseID(ctx, "codebase")
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	assert.NoError(t, svc.Revoke(ctx, token))
+	assert.ErrorIs(t, svc.Revoke(ctx, token), service_servicetokens.ErrAlreadyRevoked)
+
+	_, err = svc.Authenticate(ctx, token.ID, secret, servicetokens.ScopeGitRead)
+	assert.ErrorIs(t, err, service_servicetokens.ErrTokenInactive)
+
+	tokens, err = svc.ListByCodebaseID(ctx, "codebase")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+}
@@ -4,18 +4,65 @@ import (
 	"time"
 
 	"getsturdy.com/api/pkg/codebases"
+
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
+type Scope string
+
+const (
+	// ScopeGitRead allows cloning and fetching the codebase over git
+	ScopeGitRead Scope = "git:read"
+	// ScopeStatusesWrite allows posting statuses, for example from CI webhooks
+	ScopeStatusesWrite Scope = "statuses:write"
+)
+
+var validScopes = map[Scope]bool{
+	ScopeGitRead:       true,
+	ScopeStatusesWrite: true,
+}
+
+func (s Scope) IsValid() bool {
+	return validScopes[s]
+}
+
+// DefaultScopes are the scopes that tokens created before scopes were introduced have, and what CI
+// integrations need.
+var DefaultScopes = []Scope{ScopeGitRead, ScopeStatusesWrite}
+
 type Token struct {
-	ID         string       `db:"id"`
-	CodebaseID codebases.ID `db:"codebase_id"`
-	Hash       []byte       `db:"hash"`
-	Name       string       `db:"name"`
-	CreatedAt  time.Time    `db:"created_at"`
-	LastUsedAt *time.Time   `db:"last_used_at"`
+	ID         string         `db:"id" json:"id"`
+	CodebaseID codebases.ID   `db:"codebase_id" json:"codebase_id"`
+	Hash       []byte         `db:"hash" json:"-"`
+	Name       string         `db:"name" json:"name"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at"`
 }
 
 func (t *Token) Verify(c string) error {
 	return bcrypt.CompareHashAndPassword(t.Hash, []byte(c))
 }
+
+func (t *Token) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if Scope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns false if the token has been revoked or has expired.
+func (t *Token) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+		return false
+	}
+	return true
+}
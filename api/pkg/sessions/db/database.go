@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/sessions"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ Repository = &database{}
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{
+		db: db,
+	}
+}
+
+func (d *database) Create(ctx context.Context, session *sessions.Session) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO sessions (
+			id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at
+		) VALUES (
+			:id, :user_id, :user_agent, :ip, :created_at, :last_seen_at, :revoked_at
+		)
+	`, session); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Update(ctx context.Context, session *sessions.Session) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE sessions
+		SET ip = :ip,
+			last_seen_at = :last_seen_at,
+			revoked_at = :revoked_at
+		WHERE id = :id
+	`, session); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *database) GetByID(ctx context.Context, id string) (*sessions.Session, error) {
+	session := &sessions.Session{}
+	if err := d.db.GetContext(ctx, session, `
+		SELECT
+			id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`, id); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return session, nil
+}
+
+func (d *database) ListByUserID(ctx context.Context, userID users.ID, seenSince time.Time) ([]*sessions.Session, error) {
+	var ss []*sessions.Session
+	if err := d.db.SelectContext(ctx, &ss, `
+		SELECT
+			id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND last_seen_at >= $2
+		ORDER BY last_seen_at DESC
+	`, userID, seenSince); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return ss, nil
+}
+
+func (d *database) Touch(ctx context.Context, id string, lastSeenAt time.Time, ip *string) error {
+	if _, err := d.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET last_seen_at = $2,
+			ip = COALESCE($3, ip)
+		WHERE id = $1
+		  AND revoked_at IS NULL
+	`, id, lastSeenAt, ip); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
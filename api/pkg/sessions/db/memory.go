@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"getsturdy.com/api/pkg/sessions"
+	"getsturdy.com/api/pkg/users"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	guard sync.RWMutex
+	byID  map[string]sessions.Session
+}
+
+func NewMemory() *memory {
+	return &memory{
+		byID: map[string]sessions.Session{},
+	}
+}
+
+func (m *memory) Create(_ context.Context, session *sessions.Session) error {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+	m.byID[session.ID] = *session
+	return nil
+}
+
+func (m *memory) Update(_ context.Context, session *sessions.Session) error {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+	m.byID[session.ID] = *session
+	return nil
+}
+
+func (m *memory) GetByID(_ context.Context, id string) (*sessions.Session, error) {
+	m.guard.RLock()
+	defer m.guard.RUnlock()
+	session, found := m.byID[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return &session, nil
+}
+
+func (m *memory) ListByUserID(_ context.Context, userID users.ID, seenSince time.Time) ([]*sessions.Session, error) {
+	m.guard.RLock()
+	defer m.guard.RUnlock()
+	var ss []*sessions.Session
+	for _, session := range m.byID {
+		session := session
+		if session.UserID == userID && session.RevokedAt == nil && !session.LastSeenAt.Before(seenSince) {
+			ss = append(ss, &session)
+		}
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].LastSeenAt.After(ss[j].LastSeenAt)
+	})
+	return ss, nil
+}
+
+func (m *memory) Touch(_ context.Context, id string, lastSeenAt time.Time, ip *string) error {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+	session, found := m.byID[id]
+	if !found || session.RevokedAt != nil {
+		return nil
+	}
+	session.LastSeenAt = lastSeenAt
+	if ip != nil {
+		session.IP = ip
+	}
+	m.byID[id] = session
+	return nil
+}
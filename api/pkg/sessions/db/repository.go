@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"getsturdy.com/api/pkg/sessions"
+	"getsturdy.com/api/pkg/users"
+)
+
+type Repository interface {
+	Create(context.Context, *sessions.Session) error
+	Update(context.Context, *sessions.Session) error
+	GetByID(context.Context, string) (*sessions.Session, error)
+	// ListByUserID returns the sessions of the user that have not been revoked, and that have been seen
+	// since the given time. Most recently seen first.
+	ListByUserID(ctx context.Context, userID users.ID, seenSince time.Time) ([]*sessions.Session, error)
+	// Touch sets the last seen time and ip address of a session that has not been revoked.
+	Touch(ctx context.Context, id string, lastSeenAt time.Time, ip *string) error
+}
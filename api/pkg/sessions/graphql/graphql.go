@@ -0,0 +1,121 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	"getsturdy.com/api/pkg/sessions"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type rootResolver struct {
+	sessionsService *service_sessions.Service
+}
+
+func New(
+	sessionsService *service_sessions.Service,
+) resolvers.SessionsRootResolver {
+	return &rootResolver{
+		sessionsService: sessionsService,
+	}
+}
+
+func (r *rootResolver) InternalListByUserID(ctx context.Context, userID users.ID) ([]resolvers.SessionResolver, error) {
+	// users can only see their own sessions
+	if authUserID, err := auth.UserID(ctx); err != nil || authUserID != userID {
+		return []resolvers.SessionResolver{}, nil
+	}
+
+	ss, err := r.sessionsService.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.SessionResolver, 0, len(ss))
+	for _, session := range ss {
+		res = append(res, &resolver{session: session})
+	}
+	return res, nil
+}
+
+func (r *rootResolver) RevokeSession(ctx context.Context, args resolvers.RevokeSessionArgs) (resolvers.SessionResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// sessions can only be managed from a browser session, a personal access token should not be able to
+	// log the user out
+	if subject, _ := auth.FromContext(ctx); subject.IsPersonalAccessToken() {
+		return nil, gqlerror.Error(fmt.Errorf("sessions can not be managed with a personal access token: %w", auth.ErrForbidden))
+	}
+
+	session, err := r.sessionsService.GetByID(ctx, string(args.Input.ID))
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, gqlerror.Error(gqlerror.ErrNotFound)
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	if session.UserID != userID {
+		return nil, gqlerror.Error(gqlerror.ErrNotFound)
+	}
+
+	switch err := r.sessionsService.Revoke(ctx, session); {
+	case err == nil:
+	case errors.Is(err, service_sessions.ErrAlreadyRevoked):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{session: session}, nil
+}
+
+type resolver struct {
+	session *sessions.Session
+}
+
+func (r *resolver) ID() graphql.ID {
+	return graphql.ID(r.session.ID)
+}
+
+func (r *resolver) Device() string {
+	return r.session.Device()
+}
+
+func (r *resolver) IP() *string {
+	return r.session.IP
+}
+
+func (r *resolver) CreatedAt() int32 {
+	return int32(r.session.CreatedAt.Unix())
+}
+
+func (r *resolver) LastSeenAt() int32 {
+	return int32(r.session.LastSeenAt.Unix())
+}
+
+func (r *resolver) RevokedAt() *int32 {
+	if r.session.RevokedAt == nil {
+		return nil
+	}
+	t := int32(r.session.RevokedAt.Unix())
+	return &t
+}
+
+// Current is true for the session that the request is made with.
+func (r *resolver) Current(ctx context.Context) bool {
+	subject, ok := auth.FromContext(ctx)
+	return ok && subject.SessionID == r.session.ID
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	"getsturdy.com/api/pkg/sessions"
+	db_sessions "getsturdy.com/api/pkg/sessions/db"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// lastSeenPrecision limits how often the last seen time is written, sessions are used on every request.
+	lastSeenPrecision = time.Minute
+
+	// maxIdle is how long a session can go unused. Auth tokens are valid for 30 days and are refreshed when
+	// used, so a session that has not been seen for longer than that has no valid tokens left.
+	maxIdle = 30 * 24 * time.Hour
+
+	// maxUserAgentLength limits how much of the user agent is stored.
+	maxUserAgentLength = 512
+)
+
+var ErrAlreadyRevoked = errors.New("session is already revoked")
+
+type Service struct {
+	repo       db_sessions.Repository
+	jwtService *service_jwt.Service
+
+	touchedGuard *sync.Mutex
+	touched      map[string]time.Time
+	lastSweep    time.Time
+}
+
+func New(repo db_sessions.Repository, jwtService *service_jwt.Service) *Service {
+	return &Service{
+		repo:       repo,
+		jwtService: jwtService,
+
+		touchedGuard: &sync.Mutex{},
+		touched:      map[string]time.Time{},
+	}
+}
+
+// Create starts a new session for the user.
+func (s *Service) Create(ctx context.Context, userID users.ID, userAgent string, ip net.IP) (*sessions.Session, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	now := time.Now()
+	session := &sessions.Session{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ipString(ip),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+func ipString(ip net.IP) *string {
+	if ip == nil {
+		return nil
+	}
+	str := ip.String()
+	return &str
+}
+
+func (s *Service) GetByID(ctx context.Context, id string) (*sessions.Session, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListByUserID returns the sessions of the user that can still be used, most recently seen first.
+func (s *Service) ListByUserID(ctx context.Context, userID users.ID) ([]*sessions.Session, error) {
+	ss, err := s.repo.ListByUserID(ctx, userID, time.Now().Add(-maxIdle))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return ss, nil
+}
+
+// Touch records that the session has been used from the ip address. To avoid writing on every request, the
+// session is only updated if this instance has not done so recently.
+func (s *Service) Touch(ctx context.Context, id string, ip net.IP) error {
+	now := time.Now()
+
+	s.touchedGuard.Lock()
+	if last, ok := s.touched[id]; ok && now.Sub(last) < lastSeenPrecision {
+		s.touchedGuard.Unlock()
+		return nil
+	}
+	s.touched[id] = now
+	s.sweepTouched(now)
+	s.touchedGuard.Unlock()
+
+	if err := s.repo.Touch(ctx, id, now, ipString(ip)); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+// sweepTouched forgets sessions that have not been touched recently, they would be written again anyway. It
+// runs at most once per lastSeenPrecision, so that the map doesn't grow with every session that has ever been
+// used. Must be called with touchedGuard held.
+func (s *Service) sweepTouched(now time.Time) {
+	if now.Sub(s.lastSweep) < lastSeenPrecision {
+		return
+	}
+	for id, last := range s.touched {
+		if now.Sub(last) >= lastSeenPrecision {
+			delete(s.touched, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+// Revoke logs the session out. Its tokens are rejected by all instances within seconds.
+func (s *Service) Revoke(ctx context.Context, session *sessions.Session) error {
+	if session.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+
+	if err := s.jwtService.RevokeSession(ctx, session.ID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	if err := s.repo.Update(ctx, session); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	s.touchedGuard.Lock()
+	delete(s.touched, session.ID)
+	s.touchedGuard.Unlock()
+
+	return nil
+}
+
+// RevokeAllByUserID logs the user out of all sessions, including tokens that were issued before sessions
+// existed.
+func (s *Service) RevokeAllByUserID(ctx context.Context, userID users.ID) error {
+	if err := s.jwtService.RevokeSubject(ctx, userID.String()); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	ss, err := s.repo.ListByUserID(ctx, userID, time.Now().Add(-maxIdle))
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	now := time.Now()
+	for _, session := range ss {
+		session.RevokedAt = &now
+		if err := s.repo.Update(ctx, session); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+
+		s.touchedGuard.Lock()
+		delete(s.touched, session.ID)
+		s.touchedGuard.Unlock()
+	}
+
+	return nil
+}
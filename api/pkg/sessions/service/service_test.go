@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/jwt"
+	db_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	db_sessions "getsturdy.com/api/pkg/sessions/db"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	jwtService := service_jwt.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+	svc := service_sessions.New(db_sessions.NewMemory(), jwtService)
+
+	laptop, err := svc.Create(ctx, "user", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0", net.ParseIP("10.0.0.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", *laptop.IP)
+	phone, err := svc.Create(ctx, "user", "", nil)
+	assert.NoError(t, err)
+
+	token, err := jwtService.IssueToken(ctx, "user", time.Hour, jwt.TokenTypeAuth, service_jwt.WithSessionID(laptop.ID))
+	assert.NoError(t, err)
+
+	list, err := svc.ListByUserID(ctx, "user")
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	assert.NoError(t, svc.Revoke(ctx, laptop))
+	assert.ErrorIs(t, svc.Revoke(ctx, laptop), service_sessions.ErrAlreadyRevoked)
+
+	_, err = jwtService.Verify(ctx, token.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, service_jwt.ErrTokenRevoked)
+
+	list, err = svc.ListByUserID(ctx, "user")
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, phone.ID, list[0].ID)
+	}
+}
+
+func TestRevokeAllByUserID(t *testing.T) {
+	ctx := context.Background()
+	jwtService := service_jwt.NewService(zap.NewNop(), db_keys.NewInMemory(), db_revocations.NewInMemory())
+	svc := service_sessions.New(db_sessions.NewMemory(), jwtService)
+
+	session, err := svc.Create(ctx, "user", "", nil)
+	assert.NoError(t, err)
+	other, err := svc.Create(ctx, "other", "", nil)
+	assert.NoError(t, err)
+
+	token, err := jwtService.IssueToken(ctx, "user", time.Hour, jwt.TokenTypeAuth, service_jwt.WithSessionID(session.ID))
+	assert.NoError(t, err)
+	legacyToken, err := jwtService.IssueToken(ctx, "user", time.Hour, jwt.TokenTypeAuth)
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.RevokeAllByUserID(ctx, "user"))
+
+	_, err = jwtService.Verify(ctx, token.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, service_jwt.ErrTokenRevoked)
+	_, err = jwtService.Verify(ctx, legacyToken.Token, jwt.TokenTypeAuth)
+	assert.ErrorIs(t, err, service_jwt.ErrTokenRevoked)
+
+	list, err := svc.ListByUserID(ctx, "user")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	list, err = svc.ListByUserID(ctx, "other")
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, other.ID, list[0].ID)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	ctx := context.Background()
+	repo := db_sessions.NewMemory()
+	svc := service_sessions.New(repo, nil)
+
+	session, err := svc.Create(ctx, "user", "curl/7.79.1", net.ParseIP("10.0.0.1"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.Touch(ctx, session.ID, net.ParseIP("10.0.0.2")))
+	touched, err := repo.GetByID(ctx, session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", *touched.IP)
+	assert.True(t, !touched.LastSeenAt.Before(session.LastSeenAt))
+
+	// not written again within a minute
+	assert.NoError(t, svc.Touch(ctx, session.ID, net.ParseIP("10.0.0.3")))
+	touched, err = repo.GetByID(ctx, session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", *touched.IP)
+}
@@ -0,0 +1,72 @@
+package sessions
+
+import (
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+// Session is a browser or client login of a user. All auth tokens issued for the session have its id in the
+// sid claim, so that they can be revoked together.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     users.ID   `db:"user_id" json:"user_id"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	IP         *string    `db:"ip" json:"ip"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// Device returns a short human readable summary of the user agent, like "Firefox on macOS".
+func (s *Session) Device() string {
+	return Device(s.UserAgent)
+}
+
+// Device returns a short human readable summary of a user agent.
+func Device(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	client := "Unknown browser"
+	for _, c := range []struct{ token, name string }{
+		// order matters, most user agents also mention the browsers they are based on
+		{"Sturdy", "Sturdy app"},
+		{"sturdy-cli", "Sturdy CLI"},
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(userAgent, c.token) {
+			client = c.name
+			break
+		}
+	}
+
+	os := ""
+	for _, o := range []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Mac OS X", "macOS"},
+		{"Macintosh", "macOS"},
+		{"Windows", "Windows"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, o.token) {
+			os = o.name
+			break
+		}
+	}
+
+	if os == "" {
+		return client
+	}
+	return client + " on " + os
+}
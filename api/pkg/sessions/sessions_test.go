@@ -0,0 +1,28 @@
+package sessions_test
+
+import (
+	"testing"
+
+	"getsturdy.com/api/pkg/sessions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevice(t *testing.T) {
+	cases := []struct {
+		userAgent string
+		expected  string
+	}{
+		{"", "Unknown device"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0", "Firefox on macOS"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.51 Safari/537.36", "Chrome on Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.51 Safari/537.36 Edg/99.0.1150.36", "Edge on Windows"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 15_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Sturdy/0.9.0 Chrome/98.0.4758.102 Electron/17.1.0 Safari/537.36", "Sturdy app on Linux"},
+		{"curl/7.79.1", "curl"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, sessions.Device(tc.userAgent), tc.userAgent)
+	}
+}
@@ -30,7 +30,8 @@ func (r *Repository) Create(ctx context.Context, status *statuses.Status) error
 			description,
 			type,
 			timestamp,
-			details_url
+			details_url,
+			source
 		) VALUES (
 			:id,
 			:commit_id,
@@ -39,7 +40,8 @@ func (r *Repository) Create(ctx context.Context, status *statuses.Status) error
 			:description,
 			:type,
 			:timestamp,
-			:details_url
+			:details_url,
+			:source
 		)
 	`, status); err != nil {
 		return fmt.Errorf("failed to insert: %w", err)
@@ -58,7 +60,8 @@ func (r *Repository) Get(ctx context.Context, id string) (*statuses.Status, erro
 			description,
 			type,
 			timestamp,
-			details_url
+			details_url,
+			source
 		FROM
 			statuses
 		WHERE
@@ -94,7 +97,8 @@ func (r *Repository) ListByWorkspaceID(ctx context.Context, workspaceID string)
 			statuses.description,
 			statuses.type,
 			statuses.timestamp,
-			statuses.details_url
+			statuses.details_url,
+			statuses.source
         FROM
             statuses 
 				JOIN latest ON
@@ -132,7 +136,8 @@ func (r *Repository) ListByCodebaseIDAndCommitID(ctx context.Context, codebaseID
 				statuses.description,
 				statuses.type,
 				statuses.timestamp,
-				statuses.details_url
+				statuses.details_url,
+				statuses.source
 		FROM
 			statuses JOIN latest ON
 				statuses.commit_id       = latest.commit_id
@@ -21,6 +21,16 @@ var ValidType = map[Type]bool{
 	TypeFailing: true,
 }
 
+// Source is where a status was reported from.
+type Source string
+
+const (
+	// SourceUndefined statuses are reported to Sturdy, by CI providers or by webhooks.
+	SourceUndefined Source = ""
+	// SourceGitHub statuses are imported from GitHub commit statuses and workflow jobs.
+	SourceGitHub Source = "github"
+)
+
 type Status struct {
 	ID          string       `db:"id" json:"id"`
 	CommitSHA   string       `db:"commit_id" json:"commit_id"`
@@ -30,4 +40,5 @@ type Status struct {
 	DetailsURL  *string      `db:"details_url" json:"details_url"`
 	Description *string      `db:"description" json:"description,omitempty"`
 	Timestamp   time.Time    `db:"timestamp" json:"timestamp"`
+	Source      Source       `db:"source" json:"-"`
 }
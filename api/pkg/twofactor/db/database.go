@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/twofactor"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ Repository             = &database{}
+	_ RecoveryCodeRepository = &recoveryCodeDatabase{}
+)
+
+type database struct {
+	db *sqlx.DB
+}
+
+func NewDatabase(db *sqlx.DB) Repository {
+	return &database{
+		db: db,
+	}
+}
+
+func (d *database) Upsert(ctx context.Context, tf *twofactor.TwoFactor) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO two_factor (
+			user_id, secret, created_at, confirmed_at, last_used_step
+		) VALUES (
+			:user_id, :secret, :created_at, :confirmed_at, :last_used_step
+		)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = :secret,
+			created_at = :created_at,
+			confirmed_at = :confirmed_at,
+			last_used_step = :last_used_step
+	`, tf); err != nil {
+		return fmt.Errorf("failed to upsert: %w", err)
+	}
+	return nil
+}
+
+func (d *database) Get(ctx context.Context, userID users.ID) (*twofactor.TwoFactor, error) {
+	tf := &twofactor.TwoFactor{}
+	if err := d.db.GetContext(ctx, tf, `
+		SELECT
+			user_id, secret, created_at, confirmed_at, last_used_step
+		FROM two_factor
+		WHERE user_id = $1
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return tf, nil
+}
+
+func (d *database) Delete(ctx context.Context, userID users.ID) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM two_factor WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
+
+type recoveryCodeDatabase struct {
+	db *sqlx.DB
+}
+
+func NewRecoveryCodeDatabase(db *sqlx.DB) RecoveryCodeRepository {
+	return &recoveryCodeDatabase{
+		db: db,
+	}
+}
+
+func (d *recoveryCodeDatabase) Create(ctx context.Context, code *twofactor.RecoveryCode) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		INSERT INTO two_factor_recovery_codes (
+			id, user_id, hash, created_at, used_at
+		) VALUES (
+			:id, :user_id, :hash, :created_at, :used_at
+		)
+	`, code); err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+	return nil
+}
+
+func (d *recoveryCodeDatabase) Update(ctx context.Context, code *twofactor.RecoveryCode) error {
+	if _, err := d.db.NamedExecContext(ctx, `
+		UPDATE two_factor_recovery_codes
+		SET used_at = :used_at
+		WHERE id = :id
+	`, code); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+	return nil
+}
+
+func (d *recoveryCodeDatabase) ListUnusedByUserID(ctx context.Context, userID users.ID) ([]*twofactor.RecoveryCode, error) {
+	var codes []*twofactor.RecoveryCode
+	if err := d.db.SelectContext(ctx, &codes, `
+		SELECT
+			id, user_id, hash, created_at, used_at
+		FROM two_factor_recovery_codes
+		WHERE user_id = $1
+		  AND used_at IS NULL
+	`, userID); err != nil {
+		return nil, fmt.Errorf("failed to select: %w", err)
+	}
+	return codes, nil
+}
+
+func (d *recoveryCodeDatabase) DeleteByUserID(ctx context.Context, userID users.ID) error {
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"getsturdy.com/api/pkg/twofactor"
+	"getsturdy.com/api/pkg/users"
+)
+
+var (
+	_ Repository             = &memory{}
+	_ RecoveryCodeRepository = &recoveryCodeMemory{}
+)
+
+type memory struct {
+	byUserID map[users.ID]*twofactor.TwoFactor
+}
+
+func NewMemory() *memory {
+	return &memory{
+		byUserID: map[users.ID]*twofactor.TwoFactor{},
+	}
+}
+
+func (m *memory) Upsert(_ context.Context, tf *twofactor.TwoFactor) error {
+	m.byUserID[tf.UserID] = tf
+	return nil
+}
+
+func (m *memory) Get(_ context.Context, userID users.ID) (*twofactor.TwoFactor, error) {
+	tf, found := m.byUserID[userID]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return tf, nil
+}
+
+func (m *memory) Delete(_ context.Context, userID users.ID) error {
+	delete(m.byUserID, userID)
+	return nil
+}
+
+type recoveryCodeMemory struct {
+	byID map[string]*twofactor.RecoveryCode
+}
+
+func NewRecoveryCodeMemory() *recoveryCodeMemory {
+	return &recoveryCodeMemory{
+		byID: map[string]*twofactor.RecoveryCode{},
+	}
+}
+
+func (m *recoveryCodeMemory) Create(_ context.Context, code *twofactor.RecoveryCode) error {
+	m.byID[code.ID] = code
+	return nil
+}
+
+func (m *recoveryCodeMemory) Update(_ context.Context, code *twofactor.RecoveryCode) error {
+	m.byID[code.ID] = code
+	return nil
+}
+
+func (m *recoveryCodeMemory) ListUnusedByUserID(_ context.Context, userID users.ID) ([]*twofactor.RecoveryCode, error) {
+	var codes []*twofactor.RecoveryCode
+	for _, code := range m.byID {
+		if code.UserID == userID && code.UsedAt == nil {
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+func (m *recoveryCodeMemory) DeleteByUserID(_ context.Context, userID users.ID) error {
+	for id, code := range m.byID {
+		if code.UserID == userID {
+			delete(m.byID, id)
+		}
+	}
+	return nil
+}
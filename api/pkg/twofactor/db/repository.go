@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/twofactor"
+	"getsturdy.com/api/pkg/users"
+)
+
+type Repository interface {
+	// Upsert creates or replaces the enrollment of the user.
+	Upsert(context.Context, *twofactor.TwoFactor) error
+	Get(context.Context, users.ID) (*twofactor.TwoFactor, error)
+	Delete(context.Context, users.ID) error
+}
+
+type RecoveryCodeRepository interface {
+	Create(context.Context, *twofactor.RecoveryCode) error
+	Update(context.Context, *twofactor.RecoveryCode) error
+	// ListUnusedByUserID returns the recovery codes of the user that have not been used.
+	ListUnusedByUserID(context.Context, users.ID) ([]*twofactor.RecoveryCode, error)
+	DeleteByUserID(context.Context, users.ID) error
+}
@@ -0,0 +1,237 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/emails/transactional"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
+	service_users "getsturdy.com/api/pkg/users/service"
+
+	"go.uber.org/zap"
+)
+
+type rootResolver struct {
+	logger              *zap.Logger
+	twoFactorService    *service_twofactor.Service
+	userService         service_users.Service
+	organizationService *service_organization.Service
+	authService         *service_auth.Service
+	emailSender         transactional.EmailSender
+}
+
+func New(
+	logger *zap.Logger,
+	twoFactorService *service_twofactor.Service,
+	userService service_users.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+	emailSender transactional.EmailSender,
+) resolvers.TwoFactorRootResolver {
+	return &rootResolver{
+		logger:              logger,
+		twoFactorService:    twoFactorService,
+		userService:         userService,
+		organizationService: organizationService,
+		authService:         authService,
+		emailSender:         emailSender,
+	}
+}
+
+func (r *rootResolver) InternalTwoFactor(ctx context.Context, userID users.ID) (resolvers.TwoFactorResolver, error) {
+	// users can only see their own two-factor settings
+	if authUserID, err := auth.UserID(ctx); err != nil || authUserID != userID {
+		return nil, nil
+	}
+
+	enabled, err := r.twoFactorService.Enabled(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{enabled: enabled}, nil
+}
+
+// sessionUserID returns the id of the authenticated user. Two-factor authentication can only be managed from
+// a browser session, a personal access token must not be enough to turn it off.
+func sessionUserID(ctx context.Context) (users.ID, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return "", err
+	}
+	if subject, _ := auth.FromContext(ctx); subject.IsPersonalAccessToken() {
+		return "", fmt.Errorf("two-factor authentication can not be managed with a personal access token: %w", auth.ErrForbidden)
+	}
+	return userID, nil
+}
+
+func codeError(err error) error {
+	switch {
+	case errors.Is(err, service_twofactor.ErrInvalidCode):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "code", err.Error())
+	case errors.Is(err, service_twofactor.ErrAlreadyEnabled),
+		errors.Is(err, service_twofactor.ErrNotEnrolled),
+		errors.Is(err, service_twofactor.ErrNotEnabled):
+		return gqlerror.Error(gqlerror.ErrBadRequest, "message", err.Error())
+	default:
+		return gqlerror.Error(err)
+	}
+}
+
+func (r *rootResolver) EnrollTwoFactor(ctx context.Context) (resolvers.TwoFactorResolver, error) {
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	user, err := r.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	enrollment, err := r.twoFactorService.Enroll(ctx, user)
+	if err != nil {
+		return nil, codeError(err)
+	}
+
+	return &resolver{
+		secret: &enrollment.Secret,
+		uri:    &enrollment.URI,
+	}, nil
+}
+
+func (r *rootResolver) ConfirmTwoFactor(ctx context.Context, args resolvers.TwoFactorCodeArgs) (resolvers.TwoFactorResolver, error) {
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	recoveryCodes, err := r.twoFactorService.Confirm(ctx, userID, args.Input.Code)
+	if err != nil {
+		return nil, codeError(err)
+	}
+
+	return &resolver{enabled: true, recoveryCodes: &recoveryCodes}, nil
+}
+
+func (r *rootResolver) RegenerateTwoFactorRecoveryCodes(ctx context.Context, args resolvers.TwoFactorCodeArgs) (resolvers.TwoFactorResolver, error) {
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	recoveryCodes, err := r.twoFactorService.RegenerateRecoveryCodes(ctx, userID, args.Input.Code)
+	if err != nil {
+		return nil, codeError(err)
+	}
+
+	return &resolver{enabled: true, recoveryCodes: &recoveryCodes}, nil
+}
+
+func (r *rootResolver) DisableTwoFactor(ctx context.Context, args resolvers.TwoFactorCodeArgs) (resolvers.TwoFactorResolver, error) {
+	userID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.twoFactorService.Disable(ctx, userID, args.Input.Code); err != nil {
+		return nil, codeError(err)
+	}
+
+	return &resolver{enabled: false}, nil
+}
+
+// ResetTwoFactor lets the creator of the organization turn off two-factor authentication for a member that
+// has lost access to it. Members that are also members of other organizations can't be reset, that would
+// turn off two-factor authentication for organizations that the caller does not administer. The member is
+// logged out everywhere and notified by email.
+func (r *rootResolver) ResetTwoFactor(ctx context.Context, args resolvers.ResetTwoFactorArgs) (resolvers.TwoFactorResolver, error) {
+	authUserID, err := sessionUserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	org, err := r.organizationService.GetByID(ctx, string(args.Input.OrganizationID))
+	if err != nil {
+		return nil, gqlerror.Error(fmt.Errorf("organization not found: %w", err))
+	}
+
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if org.CreatedBy != authUserID {
+		return nil, gqlerror.Error(gqlerror.ErrForbidden, "message", "only the creator of the organization can reset two-factor authentication")
+	}
+
+	userID := users.ID(args.Input.UserID)
+	if userID == authUserID {
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "userID", "you can't reset your own two-factor authentication")
+	}
+
+	switch _, err := r.organizationService.GetMember(ctx, org.ID, userID); {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "userID", "user is not a member of the organization")
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	memberOf, err := r.organizationService.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+	for _, other := range memberOf {
+		if other.ID != org.ID {
+			return nil, gqlerror.Error(gqlerror.ErrBadRequest, "userID", "user is a member of other organizations")
+		}
+	}
+
+	user, err := r.userService.GetByID(ctx, userID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.twoFactorService.Reset(ctx, org.ID, userID); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// two-factor authentication has already been turned off, the email is best effort
+	if err := r.emailSender.SendTwoFactorReset(ctx, user, org); err != nil {
+		r.logger.Error("failed to notify user of two-factor reset", zap.Stringer("user_id", userID), zap.Error(err))
+	}
+
+	return &resolver{enabled: false}, nil
+}
+
+type resolver struct {
+	enabled bool
+
+	secret        *string
+	uri           *string
+	recoveryCodes *[]string
+}
+
+func (r *resolver) Enabled() bool {
+	return r.enabled
+}
+
+func (r *resolver) Secret() *string {
+	return r.secret
+}
+
+func (r *resolver) URI() *string {
+	return r.uri
+}
+
+func (r *resolver) RecoveryCodes() *[]string {
+	return r.recoveryCodes
+}
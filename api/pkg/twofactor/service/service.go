@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+	"getsturdy.com/api/pkg/twofactor"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/google/uuid"
+)
+
+const (
+	issuer = "Sturdy"
+
+	recoveryCodesCount = 10
+)
+
+var (
+	ErrAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrNotEnrolled    = errors.New("two-factor authentication enrollment has not been started")
+	ErrNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode    = errors.New("invalid code")
+)
+
+type Service struct {
+	repo             db_twofactor.Repository
+	recoveryCodeRepo db_twofactor.RecoveryCodeRepository
+	auditService     *service_audit.Service
+	sessionsService  *service_sessions.Service
+}
+
+func New(
+	repo db_twofactor.Repository,
+	recoveryCodeRepo db_twofactor.RecoveryCodeRepository,
+	auditService *service_audit.Service,
+	sessionsService *service_sessions.Service,
+) *Service {
+	return &Service{
+		repo:             repo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		auditService:     auditService,
+		sessionsService:  sessionsService,
+	}
+}
+
+type Enrollment struct {
+	Secret string
+	// URI is the otpauth:// URI of the secret, that can be shown as a QR code.
+	URI string
+}
+
+// Enabled returns true if the user has confirmed two-factor authentication.
+func (s *Service) Enabled(ctx context.Context, userID users.ID) (bool, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	switch {
+	case err == nil:
+		return tf.Enabled(), nil
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to get two-factor: %w", err)
+	}
+}
+
+// Enroll starts enrollment of the user, by generating a new secret. Two-factor authentication is not enabled
+// until the enrollment is confirmed with a code. Enrolling again replaces an unconfirmed secret.
+func (s *Service) Enroll(ctx context.Context, user *users.User) (*Enrollment, error) {
+	enabled, err := s.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := twofactor.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Upsert(ctx, &twofactor.TwoFactor{
+		UserID:    user.ID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor: %w", err)
+	}
+
+	return &Enrollment{
+		Secret: secret,
+		URI:    twofactor.URI(issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication for the user, if the code is valid for the enrolled secret. It
+// returns the recovery codes of the user in plain text, they are not stored.
+func (s *Service) Confirm(ctx context.Context, userID users.ID, code string) ([]string, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNotEnrolled
+	default:
+		return nil, fmt.Errorf("failed to get two-factor: %w", err)
+	}
+
+	if tf.Enabled() {
+		return nil, ErrAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := twofactor.Validate(tf.Secret, code, now, tf.LastUsedStep)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	tf.ConfirmedAt = &now
+	tf.LastUsedStep = step
+	if err := s.repo.Upsert(ctx, tf); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor: %w", err)
+	}
+
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+func (s *Service) generateRecoveryCodes(ctx context.Context, userID users.ID) ([]string, error) {
+	if err := s.recoveryCodeRepo.DeleteByUserID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := twofactor.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.recoveryCodeRepo.Create(ctx, &twofactor.RecoveryCode{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			Hash:      twofactor.HashRecoveryCode(code),
+			CreatedAt: time.Now(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// Verify checks a code from the authenticator, or a recovery code. Each code can only be used once.
+func (s *Service) Verify(ctx context.Context, userID users.ID, code string) error {
+	tf, err := s.repo.Get(ctx, userID)
+	switch {
+	case err == nil:
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotEnabled
+	default:
+		return fmt.Errorf("failed to get two-factor: %w", err)
+	}
+
+	if !tf.Enabled() {
+		return ErrNotEnabled
+	}
+
+	if step, ok := twofactor.Validate(tf.Secret, code, time.Now(), tf.LastUsedStep); ok {
+		tf.LastUsedStep = step
+		if err := s.repo.Upsert(ctx, tf); err != nil {
+			return fmt.Errorf("failed to save two-factor: %w", err)
+		}
+		return nil
+	}
+
+	recoveryCodes, err := s.recoveryCodeRepo.ListUnusedByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list recovery codes: %w", err)
+	}
+	for _, recoveryCode := range recoveryCodes {
+		if !recoveryCode.Verify(code) {
+			continue
+		}
+		now := time.Now()
+		recoveryCode.UsedAt = &now
+		if err := s.recoveryCodeRepo.Update(ctx, recoveryCode); err != nil {
+			return fmt.Errorf("failed to use recovery code: %w", err)
+		}
+		return nil
+	}
+
+	return ErrInvalidCode
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user, if the code is valid.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID users.ID, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+// Disable turns off two-factor authentication for the user, if the code is valid.
+func (s *Service) Disable(ctx context.Context, userID users.ID, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.delete(ctx, userID)
+}
+
+// Reset turns off two-factor authentication for a member of the organization without a code, for example
+// when the member has lost both the authenticator and the recovery codes. The member is logged out of all
+// sessions, so that nobody stays logged in on the member's behalf. It's up to the caller to check that this
+// is allowed.
+func (s *Service) Reset(ctx context.Context, organizationID string, userID users.ID) error {
+	if err := s.delete(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.sessionsService.RevokeAllByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionOrganizationMemberTwoFactorReset, audit.TargetUser, userID.String(),
+		audit.OrganizationID(organizationID),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) delete(ctx context.Context, userID users.ID) error {
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete two-factor: %w", err)
+	}
+	if err := s.recoveryCodeRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/internal/inmemory"
+	db_jwt_keys "getsturdy.com/api/pkg/jwt/keys/db"
+	db_jwt_revocations "getsturdy.com/api/pkg/jwt/revocations/db"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	db_sessions "getsturdy.com/api/pkg/sessions/db"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+	"getsturdy.com/api/pkg/twofactor"
+	db_twofactor "getsturdy.com/api/pkg/twofactor/db"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func currentCode(t *testing.T, secret string, offset time.Duration) string {
+	code, err := twofactor.Code(secret, twofactor.Step(time.Now().Add(offset)))
+	assert.NoError(t, err)
+	return code
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	auditRepo := db_audit.NewMemory()
+	auditService := service_audit.New(auditRepo, inmemory.NewInMemoryCodebaseRepo())
+	sessionsService := service_sessions.New(db_sessions.NewMemory(), service_jwt.NewService(zap.NewNop(), db_jwt_keys.NewInMemory(), db_jwt_revocations.NewInMemory()))
+	svc := service_twofactor.New(db_twofactor.NewMemory(), db_twofactor.NewRecoveryCodeMemory(), auditService, sessionsService)
+	user := &users.User{ID: "user-id", Email: "jane@example.com"}
+
+	_, err := sessionsService.Create(ctx, user.ID, "", nil)
+	assert.NoError(t, err)
+
+	enrollment, err := svc.Enroll(ctx, user)
+	assert.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+
+	// not enabled until confirmed
+	enabled, err := svc.Enabled(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, currentCode(t, enrollment.Secret, 0)), service_twofactor.ErrNotEnabled)
+
+	_, err = svc.Confirm(ctx, user.ID, "000000")
+	assert.ErrorIs(t, err, service_twofactor.ErrInvalidCode)
+
+	recoveryCodes, err := svc.Confirm(ctx, user.ID, currentCode(t, enrollment.Secret, -twofactor.Period))
+	assert.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	enabled, err = svc.Enabled(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	_, err = svc.Enroll(ctx, user)
+	assert.ErrorIs(t, err, service_twofactor.ErrAlreadyEnabled)
+
+	// a code can only be used once
+	code := currentCode(t, enrollment.Secret, 0)
+	assert.NoError(t, svc.Verify(ctx, user.ID, code))
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, code), service_twofactor.ErrInvalidCode)
+
+	// so can recovery codes
+	assert.NoError(t, svc.Verify(ctx, user.ID, recoveryCodes[0]))
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, recoveryCodes[0]), service_twofactor.ErrInvalidCode)
+	assert.NoError(t, svc.Verify(ctx, user.ID, recoveryCodes[1]))
+
+	assert.NoError(t, svc.Reset(ctx, "org-id", user.ID))
+	enabled, err = svc.Enabled(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	assert.ErrorIs(t, svc.Verify(ctx, user.ID, recoveryCodes[2]), service_twofactor.ErrNotEnabled)
+
+	// the user is logged out everywhere
+	sessions, err := sessionsService.ListByUserID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	entries, err := auditRepo.ListByOrganizationID(ctx, "org-id", db_audit.Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, audit.ActionOrganizationMemberTwoFactorReset, entries[0].Action)
+		assert.Equal(t, user.ID.String(), entries[0].TargetID)
+	}
+}
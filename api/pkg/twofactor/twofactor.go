@@ -0,0 +1,161 @@
+// Package twofactor implements time-based one-time password (TOTP, RFC 6238) two-factor authentication for
+// users that log in with a password.
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"getsturdy.com/api/pkg/users"
+)
+
+const (
+	// Period is how long a code is valid for.
+	Period = 30 * time.Second
+	// Digits is the number of digits in a code.
+	Digits = 6
+	// skew is the number of periods before and after the current one that are accepted, to allow for clock
+	// drift between the server and the authenticator.
+	skew = 1
+
+	secretBytes = 20
+)
+
+// TwoFactor is the two-factor authentication enrollment of a user. Two-factor authentication is enabled
+// once the enrollment is confirmed with a valid code.
+type TwoFactor struct {
+	UserID users.ID `db:"user_id"`
+	// Secret is the base32 encoded shared secret.
+	Secret      string     `db:"secret"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ConfirmedAt *time.Time `db:"confirmed_at"`
+	// LastUsedStep is the time step of the last accepted code, codes can not be used more than once.
+	LastUsedStep int64 `db:"last_used_step"`
+}
+
+func (t *TwoFactor) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// RecoveryCode can be used instead of a code from the authenticator, once.
+type RecoveryCode struct {
+	ID        string     `db:"id"`
+	UserID    users.ID   `db:"user_id"`
+	Hash      []byte     `db:"hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+func HashRecoveryCode(code string) []byte {
+	h := sha256.Sum256([]byte(NormalizeRecoveryCode(code)))
+	return h[:]
+}
+
+// NormalizeRecoveryCode makes recovery codes case and dash insensitive.
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func (c *RecoveryCode) Verify(code string) bool {
+	return subtle.ConstantTimeCompare(c.Hash, HashRecoveryCode(code)) == 1
+}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// GenerateRecoveryCode returns a random code formatted as xxxxx-xxxxx.
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	code := strings.ToLower(fmt.Sprintf("%x", b))
+	return code[:5] + "-" + code[5:], nil
+}
+
+// Step returns the time step of t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of the time step.
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid secret: %w", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, see RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
+
+// Validate returns the time step that the code is valid for at time t. Only steps after lastUsedStep are
+// accepted. ok is false if the code is not valid.
+func Validate(secret, code string, t time.Time, lastUsedStep int64) (step int64, ok bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(t)
+	for s := current - skew; s <= current+skew; s++ {
+		if s <= lastUsedStep {
+			continue
+		}
+		expected, err := Code(secret, s)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// URI returns the otpauth:// URI that authenticator apps can import, usually by scanning a QR code.
+func URI(issuer, accountName, secret string) string {
+	u := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + accountName,
+	}
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period/time.Second)))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
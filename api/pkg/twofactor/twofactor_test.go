@@ -0,0 +1,68 @@
+package twofactor_test
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/twofactor"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret from the test vectors in RFC 6238, appendix B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	cases := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	}
+
+	for _, tc := range cases {
+		code, err := twofactor.Code(rfcSecret, twofactor.Step(time.Unix(tc.unix, 0)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.expected, code, "at %d", tc.unix)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := twofactor.Step(now)
+
+	s, ok := twofactor.Validate(rfcSecret, "081804", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, s)
+
+	// codes of the adjacent steps are accepted
+	_, ok = twofactor.Validate(rfcSecret, "081804", now.Add(twofactor.Period), 0)
+	assert.True(t, ok)
+
+	// but not older ones
+	_, ok = twofactor.Validate(rfcSecret, "081804", now.Add(2*twofactor.Period), 0)
+	assert.False(t, ok)
+
+	// codes can only be used once
+	_, ok = twofactor.Validate(rfcSecret, "081804", now, step)
+	assert.False(t, ok)
+
+	_, ok = twofactor.Validate(rfcSecret, "000000", now, 0)
+	assert.False(t, ok)
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := twofactor.GenerateRecoveryCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, 11)
+
+	rc := &twofactor.RecoveryCode{Hash: twofactor.HashRecoveryCode(code)}
+	assert.True(t, rc.Verify(code))
+	assert.True(t, rc.Verify(twofactor.NormalizeRecoveryCode(code)))
+	assert.False(t, rc.Verify("00000-00000"))
+}
@@ -7,13 +7,15 @@ import (
 	"getsturdy.com/api/pkg/auth"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
 	"getsturdy.com/api/pkg/onetime/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	service_user "getsturdy.com/api/pkg/users/enterprise/cloud/service"
+	service_users "getsturdy.com/api/pkg/users/service"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-func VerifyMagicLink(logger *zap.Logger, userService *service_user.Service, jwtService *service_jwt.Service) gin.HandlerFunc {
+func VerifyMagicLink(logger *zap.Logger, userService *service_user.Service, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) gin.HandlerFunc {
 	type request struct {
 		Code  string `json:"code" binding:"required"`
 		Email string `json:"email" binding:"required"`
@@ -46,12 +48,15 @@ func VerifyMagicLink(logger *zap.Logger, userService *service_user.Service, jwtS
 			return
 		}
 
-		if err := userService.Activate(c.Request.Context(), user); err != nil {
+		if err := userService.Activate(c.Request.Context(), user); errors.Is(err, service_users.ErrDeactivated) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "account deactivated"})
+			return
+		} else if err != nil {
 			logger.Error("failed to update user's status", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
 
-		auth.SetAuthCookieForUser(c, user.ID, jwtService)
+		auth.SetAuthCookieForUser(c, user.ID, jwtService, sessionsService)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	service_installations "getsturdy.com/api/pkg/installations/service"
+	"getsturdy.com/api/pkg/oidc"
 	"getsturdy.com/api/pkg/users"
 	service_oss_selfhosted "getsturdy.com/api/pkg/users/oss/selfhosted/service"
 	"getsturdy.com/api/pkg/users/service"
@@ -16,15 +17,18 @@ type Service struct {
 	*service_oss_selfhosted.Service
 
 	installationService *service_installations.Service
+	oidcConfig          *oidc.Configuration
 }
 
 func New(
 	userService *service_oss_selfhosted.Service,
 	installationService *service_installations.Service,
+	oidcConfig *oidc.Configuration,
 ) *Service {
 	return &Service{
 		Service:             userService,
 		installationService: installationService,
+		oidcConfig:          oidcConfig,
 	}
 }
 
@@ -46,7 +50,16 @@ func (s *Service) ValidateUserCount(ctx context.Context) error {
 	return nil
 }
 
+// PasswordLoginEnabled returns false if the installation only allows single sign-on.
+func (s *Service) PasswordLoginEnabled(context.Context) bool {
+	return s.oidcConfig.PasswordLoginEnabled()
+}
+
 func (s *Service) CreateWithPassword(ctx context.Context, name, password, email string) (*users.User, error) {
+	if !s.PasswordLoginEnabled(ctx) {
+		return nil, service.ErrPasswordLoginDisabled
+	}
+
 	if err := s.ValidateUserCount(ctx); err != nil {
 		return nil, err
 	}
@@ -58,3 +71,11 @@ func (s *Service) CreateWithPassword(ctx context.Context, name, password, email
 
 	return usr, nil
 }
+
+func (s *Service) CreateWithoutPassword(ctx context.Context, name, email string) (*users.User, error) {
+	if err := s.ValidateUserCount(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.Service.CreateWithoutPassword(ctx, name, email)
+}
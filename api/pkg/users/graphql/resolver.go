@@ -28,10 +28,13 @@ type userRootResolver struct {
 
 	userService service_user.Service
 
-	viewRootResolver          resolvers.ViewRootResolver
-	notificationRootResolver  resolvers.NotificationRootResolver
-	githubAccountRootResolver resolvers.GitHubAccountRootResolver
-	analyticsService          *service_analytics.Service
+	viewRootResolver           resolvers.ViewRootResolver
+	notificationRootResolver   resolvers.NotificationRootResolver
+	githubAccountRootResolver  resolvers.GitHubAccountRootResolver
+	personalTokensRootResolver resolvers.PersonalTokensRootResolver
+	sessionsRootResolver       resolvers.SessionsRootResolver
+	twoFactorRootResolver      resolvers.TwoFactorRootResolver
+	analyticsService           *service_analytics.Service
 }
 
 func NewResolver(
@@ -43,6 +46,9 @@ func NewResolver(
 	viewRootResolver resolvers.ViewRootResolver,
 	notificationRootResolver resolvers.NotificationRootResolver,
 	githubAccountRootResolver resolvers.GitHubAccountRootResolver,
+	personalTokensRootResolver resolvers.PersonalTokensRootResolver,
+	sessionsRootResolver resolvers.SessionsRootResolver,
+	twoFactorRootResolver resolvers.TwoFactorRootResolver,
 
 	logger *zap.Logger,
 	analyticsService *service_analytics.Service,
@@ -53,10 +59,13 @@ func NewResolver(
 
 		userService: userService,
 
-		viewRootResolver:          viewRootResolver,
-		notificationRootResolver:  notificationRootResolver,
-		githubAccountRootResolver: githubAccountRootResolver,
-		analyticsService:          analyticsService,
+		viewRootResolver:           viewRootResolver,
+		notificationRootResolver:   notificationRootResolver,
+		githubAccountRootResolver:  githubAccountRootResolver,
+		personalTokensRootResolver: personalTokensRootResolver,
+		sessionsRootResolver:       sessionsRootResolver,
+		twoFactorRootResolver:      twoFactorRootResolver,
+		analyticsService:           analyticsService,
 	}, logger)
 }
 
@@ -154,6 +163,8 @@ func (r *userResolver) Status() (resolvers.UserStatus, error) {
 		return resolvers.UserStatusActive, nil
 	case users.StatusShadow:
 		return resolvers.UserStatusShadow, nil
+	case users.StatusDeactivated:
+		return resolvers.UserStatusDeactivated, nil
 	default:
 		return resolvers.UserStatusUndefined, gqlerrors.Error(fmt.Errorf("unknown status: %s", r.u.Status))
 	}
@@ -183,6 +194,10 @@ func (r *userResolver) NotificationPreferences(ctx context.Context) ([]resolvers
 	return r.root.notificationRootResolver.InternalNotificationPreferences(ctx, r.u.ID)
 }
 
+func (r *userResolver) NotificationDigestFrequency(ctx context.Context) (resolvers.NotificationDigestFrequency, error) {
+	return r.root.notificationRootResolver.InternalNotificationDigestFrequency(ctx, r.u.ID)
+}
+
 func (r *userResolver) NotificationsReceiveNewsletter() (bool, error) {
 	settings, err := r.root.notificationSettingsRepo.GetByUser(r.u.ID)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -225,3 +240,15 @@ func (r *userResolver) LastUsedView(ctx context.Context, args resolvers.LastUsed
 	}
 	return resolver, err
 }
+
+func (r *userResolver) PersonalAccessTokens(ctx context.Context) ([]resolvers.PersonalAccessTokenResolver, error) {
+	return r.root.personalTokensRootResolver.InternalListByUserID(ctx, r.u.ID)
+}
+
+func (r *userResolver) Sessions(ctx context.Context) ([]resolvers.SessionResolver, error) {
+	return r.root.sessionsRootResolver.InternalListByUserID(ctx, r.u.ID)
+}
+
+func (r *userResolver) TwoFactor(ctx context.Context) (resolvers.TwoFactorResolver, error) {
+	return r.root.twoFactorRootResolver.InternalTwoFactor(ctx, r.u.ID)
+}
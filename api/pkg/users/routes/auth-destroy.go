@@ -1,13 +1,45 @@
 package routes
 
 import (
-	"getsturdy.com/api/pkg/auth"
 	"net/http"
 
+	"getsturdy.com/api/pkg/auth"
+	"getsturdy.com/api/pkg/jwt"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-func AuthDestroy(c *gin.Context) {
-	auth.RemoveAuthCookie(c.Writer)
-	c.Status(http.StatusOK)
+// AuthDestroy logs out, by removing the auth cookie and revoking the session that it belongs to.
+func AuthDestroy(logger *zap.Logger, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) func(*gin.Context) {
+	return func(c *gin.Context) {
+		auth.RemoveAuthCookie(c.Writer)
+
+		jwtString, err := c.Cookie("auth")
+		if err != nil {
+			c.Status(http.StatusOK)
+			return
+		}
+
+		token, err := jwtService.Verify(c.Request.Context(), jwtString, jwt.TokenTypeAuth)
+		if err != nil || token.SessionID == "" {
+			c.Status(http.StatusOK)
+			return
+		}
+
+		session, err := sessionsService.GetByID(c.Request.Context(), token.SessionID)
+		if err != nil {
+			logger.Error("failed to get session", zap.Error(err))
+			c.Status(http.StatusOK)
+			return
+		}
+
+		if err := sessionsService.Revoke(c.Request.Context(), session); err != nil {
+			logger.Error("failed to revoke session", zap.Error(err))
+		}
+
+		c.Status(http.StatusOK)
+	}
 }
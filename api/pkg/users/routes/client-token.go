@@ -11,6 +11,7 @@ import (
 	"getsturdy.com/api/pkg/users/db"
 
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -21,7 +22,7 @@ var (
 	oneMonth = 30 * oneDay
 )
 
-func ClientToken(db db.Repository, jwtService *service_jwt.Service) func(*gin.Context) {
+func ClientToken(db db.Repository, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) func(*gin.Context) {
 	return func(c *gin.Context) {
 		userID, err := auth.UserID(c.Request.Context())
 		if err != nil {
@@ -29,6 +30,12 @@ func ClientToken(db db.Repository, jwtService *service_jwt.Service) func(*gin.Co
 			return
 		}
 
+		// personal access tokens can not be exchanged for a session, that would bypass the token scopes
+		if subject, _ := auth.FromContext(c.Request.Context()); subject.IsPersonalAccessToken() {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
 		user, err := db.Get(userID)
 		if err != nil {
 			log.Println(err)
@@ -36,7 +43,16 @@ func ClientToken(db db.Repository, jwtService *service_jwt.Service) func(*gin.Co
 			return
 		}
 
-		token, err := jwtService.IssueToken(c.Request.Context(), user.ID.String(), oneMonth, jwt.TokenTypeAuth)
+		// the client gets a session of its own, so that it can be revoked separately from the browser
+		remoteIP, _ := c.RemoteIP()
+		session, err := sessionsService.Create(c.Request.Context(), user.ID, c.Request.UserAgent(), remoteIP)
+		if err != nil {
+			log.Println(err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		token, err := jwtService.IssueToken(c.Request.Context(), user.ID.String(), oneMonth, jwt.TokenTypeAuth, service_jwt.WithSessionID(session.ID))
 		if err != nil {
 			log.Println(err)
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
@@ -85,7 +101,7 @@ func RenewToken(logger *zap.Logger, db db.Repository, jwtService *service_jwt.Se
 
 		// If expires within 25 days, renew it! (original expire duration is 30 days)
 		if token.ExpiresAt.Before(time.Now().Add(time.Hour * 24 * 25)) {
-			newToken, err := jwtService.IssueToken(c.Request.Context(), user.ID.String(), oneMonth, jwt.TokenTypeAuth)
+			newToken, err := jwtService.IssueToken(c.Request.Context(), user.ID.String(), oneMonth, jwt.TokenTypeAuth, service_jwt.WithSessionID(token.SessionID))
 			if err != nil {
 				logger.Error("failed to renew token for user", zap.Error(err))
 				c.AbortWithStatus(http.StatusBadRequest)
@@ -6,12 +6,13 @@ import (
 
 	"getsturdy.com/api/pkg/auth"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	service_users "getsturdy.com/api/pkg/users/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func GetSelf(userService service_users.Service, jwtService *service_jwt.Service) func(c *gin.Context) {
+func GetSelf(userService service_users.Service, jwtService *service_jwt.Service, sessionsService *service_sessions.Service) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		userID, err := auth.UserID(c.Request.Context())
 		if err != nil {
@@ -27,7 +28,7 @@ func GetSelf(userService service_users.Service, jwtService *service_jwt.Service)
 		}
 		// Refresh the users auth cookie
 		// For requests from a browser, this takes care of all of the auth renewal we need. :-)
-		auth.SetAuthCookieForUser(c, u.ID, jwtService)
+		auth.RefreshAuthCookie(c, jwtService, sessionsService)
 		c.JSON(http.StatusOK, u)
 	}
 }
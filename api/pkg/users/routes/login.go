@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -8,6 +9,9 @@ import (
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
 	"getsturdy.com/api/pkg/auth"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
+	service_twofactor "getsturdy.com/api/pkg/twofactor/service"
+	"getsturdy.com/api/pkg/users"
 	service_users "getsturdy.com/api/pkg/users/service"
 
 	"github.com/gin-gonic/gin"
@@ -15,10 +19,13 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func Login(logger *zap.Logger, userService service_users.Service, analyticsService *service_analytics.Service, jwtService *service_jwt.Service) func(c *gin.Context) {
+func Login(logger *zap.Logger, userService service_users.Service, analyticsService *service_analytics.Service, jwtService *service_jwt.Service, sessionsService *service_sessions.Service, twoFactorService *service_twofactor.Service) func(c *gin.Context) {
 	type request struct {
 		Email    string `json:"email" binding:"required"`
 		Password string `json:"password" binding:"required"`
+		// Code is a two-factor authentication code, or a recovery code. It's required if the user has
+		// enabled two-factor authentication.
+		Code string `json:"code"`
 	}
 
 	return func(c *gin.Context) {
@@ -29,6 +36,11 @@ func Login(logger *zap.Logger, userService service_users.Service, analyticsServi
 			return
 		}
 
+		if !userService.PasswordLoginEnabled(c.Request.Context()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Password login is disabled, please log in with single sign-on"})
+			return
+		}
+
 		req.Email = strings.TrimSpace(req.Email)
 
 		// Get user by email
@@ -48,7 +60,36 @@ func Login(logger *zap.Logger, userService service_users.Service, analyticsServi
 			return
 		}
 
-		if err := auth.SetAuthCookieForUser(c, getUser.ID, jwtService); err != nil {
+		if getUser.Status == users.StatusDeactivated {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated, please contact your administrator"})
+			return
+		}
+
+		twoFactorEnabled, err := twoFactorService.Enabled(c.Request.Context(), getUser.ID)
+		if err != nil {
+			logger.Error("failed to get two-factor authentication", zap.Error(err))
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if twoFactorEnabled {
+			if req.Code == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Two-factor authentication code is required", "two_factor_required": true})
+				return
+			}
+			switch err := twoFactorService.Verify(c.Request.Context(), getUser.ID, strings.TrimSpace(req.Code)); {
+			case err == nil:
+			case errors.Is(err, service_twofactor.ErrInvalidCode):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor authentication code, please try again", "two_factor_required": true})
+				return
+			default:
+				logger.Error("failed to verify two-factor authentication code", zap.Error(err))
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := auth.SetAuthCookieForUser(c, getUser.ID, jwtService, sessionsService); err != nil {
 			logger.Error("failed to set auth cookie", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
@@ -9,6 +9,7 @@ import (
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
 	"getsturdy.com/api/pkg/auth"
 	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_sessions "getsturdy.com/api/pkg/sessions/service"
 	service_user "getsturdy.com/api/pkg/users/service"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,7 @@ func Signup(
 	logger *zap.Logger,
 	userService service_user.Service,
 	jwtService *service_jwt.Service,
+	sessionsService *service_sessions.Service,
 	analyticsService *service_analytics.Service,
 ) func(c *gin.Context) {
 	type request struct {
@@ -46,13 +48,16 @@ func Signup(
 		} else if errors.Is(err, service_user.ErrExceeded) {
 			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "This service is exceeding the number of users allowed. Please contact the server administrator or email support@getsturdy.com"})
 			return
+		} else if errors.Is(err, service_user.ErrPasswordLoginDisabled) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Password signup is disabled, please sign up with single sign-on"})
+			return
 		} else if err != nil {
 			logger.Error("failed to create user", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
 
-		if err := auth.SetAuthCookieForUser(c, newUser.ID, jwtService); err != nil {
+		if err := auth.SetAuthCookieForUser(c, newUser.ID, jwtService, sessionsService); err != nil {
 			logger.Error("failed to set auth cookie", zap.Error(err))
 			c.AbortWithStatus(http.StatusInternalServerError)
 			return
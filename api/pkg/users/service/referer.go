@@ -29,6 +29,24 @@ func (ghpr *gitHubPullRequestReferer) URL() string {
 	return u.String()
 }
 
+type gitLabMergeRequestReferer struct {
+	projectID      int64
+	mergeRequestID int64
+}
+
+func GitLabMergeRequestReferer(projectID, mergeRequestID int64) *gitLabMergeRequestReferer {
+	return &gitLabMergeRequestReferer{projectID: projectID, mergeRequestID: mergeRequestID}
+}
+
+func (glmr *gitLabMergeRequestReferer) URL() string {
+	u := url.URL{
+		Scheme: "referer",
+		Host:   "gitlab",
+		Path:   fmt.Sprintf("%d/mrs/%d", glmr.projectID, glmr.mergeRequestID),
+	}
+	return u.String()
+}
+
 type userReferer struct {
 	userID users.ID
 }
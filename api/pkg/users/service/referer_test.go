@@ -16,3 +16,7 @@ func Test_UserReferer(t *testing.T) {
 func Test_GitHubPullRequestReferer(t *testing.T) {
 	assert.Equal(t, "referer://github/1234/prs/56789", service_users.GitHubPullRequestReferer(1234, 56789).URL())
 }
+
+func Test_GitLabMergeRequestReferer(t *testing.T) {
+	assert.Equal(t, "referer://gitlab/1234/mrs/56789", service_users.GitLabMergeRequestReferer(1234, 56789).URL())
+}
@@ -12,6 +12,8 @@ import (
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
 	"getsturdy.com/api/pkg/author"
+	service_jwt "getsturdy.com/api/pkg/jwt/service"
+	service_personaltokens "getsturdy.com/api/pkg/personaltokens/service"
 	"getsturdy.com/api/pkg/users"
 	db_user "getsturdy.com/api/pkg/users/db"
 
@@ -20,13 +22,18 @@ import (
 )
 
 var (
-	ErrExceeded = fmt.Errorf("maximum number of users exceeded")
+	ErrExceeded              = fmt.Errorf("maximum number of users exceeded")
+	ErrPasswordLoginDisabled = fmt.Errorf("password login is disabled")
+	ErrDeactivated           = fmt.Errorf("user is deactivated")
 )
 
 type UserService struct {
 	logger           *zap.Logger
 	userRepo         db_user.Repository
 	analyticsService *service_analytics.Service
+
+	jwtService            *service_jwt.Service
+	personalTokensService *service_personaltokens.Service
 }
 
 type Service interface {
@@ -38,19 +45,27 @@ type Service interface {
 	GetFirstUser(ctx context.Context) (*users.User, error)
 	GetAsAuthor(context.Context, users.ID) (*author.Author, error)
 	Activate(context.Context, *users.User) error
+	Deactivate(context.Context, *users.User) error
+	Reactivate(context.Context, *users.User) error
 	Inherit(context.Context, users.ID, *users.User) error
 	CreateShadow(ctx context.Context, email string, referer Referer, name *string) (*users.User, error)
+	PasswordLoginEnabled(context.Context) bool
 }
 
 func New(
 	logger *zap.Logger,
 	userRepo db_user.Repository,
 	analyticsService *service_analytics.Service,
+	jwtService *service_jwt.Service,
+	personalTokensService *service_personaltokens.Service,
 ) *UserService {
 	return &UserService{
 		logger:           logger,
 		userRepo:         userRepo,
 		analyticsService: analyticsService,
+
+		jwtService:            jwtService,
+		personalTokensService: personalTokensService,
 	}
 }
 
@@ -94,8 +109,11 @@ func (s *UserService) Inherit(ctx context.Context, userID users.ID, user *users.
 }
 
 func (s *UserService) Activate(ctx context.Context, user *users.User) error {
-	if user.Status == users.StatusActive {
+	switch user.Status {
+	case users.StatusActive:
 		return nil
+	case users.StatusDeactivated:
+		return ErrDeactivated
 	}
 
 	user.Status = users.StatusActive
@@ -109,6 +127,45 @@ func (s *UserService) Activate(ctx context.Context, user *users.User) error {
 	return nil
 }
 
+// Deactivate makes the user unable to log in. All of the user's sessions and personal access tokens are
+// revoked immediately.
+func (s *UserService) Deactivate(ctx context.Context, user *users.User) error {
+	if user.Status != users.StatusDeactivated {
+		user.Status = users.StatusDeactivated
+		if err := s.userRepo.Update(user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+
+	// revoke even if the user was already deactivated, in case a previous attempt failed half way
+	if err := s.jwtService.RevokeSubject(ctx, user.ID.String()); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.personalTokensService.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	s.analyticsService.Capture(ctx, "deactivated account", analytics.UserID(user.ID))
+
+	return nil
+}
+
+// Reactivate makes a deactivated user able to log in again. Revoked sessions and tokens are not restored.
+func (s *UserService) Reactivate(ctx context.Context, user *users.User) error {
+	if user.Status != users.StatusDeactivated {
+		return nil
+	}
+
+	user.Status = users.StatusActive
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	s.analyticsService.Capture(ctx, "reactivated account", analytics.UserID(user.ID))
+
+	return nil
+}
+
 func (s *UserService) CreateShadow(ctx context.Context, email string, referer Referer, name *string) (*users.User, error) {
 	if _, err := s.userRepo.GetByEmail(email); errors.Is(err, sql.ErrNoRows) {
 		// all good
@@ -177,6 +234,42 @@ func (s *UserService) CreateWithPassword(ctx context.Context, name, password, em
 	return newUser, nil
 }
 
+// CreateWithoutPassword creates an active user that can't log in with a password, for example a user
+// that is provisioned by an identity provider. The email address is trusted to be verified.
+func (s *UserService) CreateWithoutPassword(ctx context.Context, name, email string) (*users.User, error) {
+	if _, err := s.userRepo.GetByEmail(email); errors.Is(err, sql.ErrNoRows) {
+		// all good
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	} else {
+		return nil, ErrExists
+	}
+
+	t := time.Now()
+	newUser := &users.User{
+		ID:            users.ID(uuid.New().String()),
+		Name:          name,
+		Email:         email,
+		EmailVerified: true,
+		CreatedAt:     &t,
+		Status:        users.StatusActive,
+	}
+
+	if err := s.userRepo.Create(newUser); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.analyticsService.IdentifyUser(ctx, newUser)
+	s.analyticsService.Capture(ctx, "created account")
+
+	return newUser, nil
+}
+
+// PasswordLoginEnabled returns true if users can sign up and log in with a password.
+func (s *UserService) PasswordLoginEnabled(context.Context) bool {
+	return true
+}
+
 func (s *UserService) GetByIDs(ctx context.Context, ids ...users.ID) ([]*users.User, error) {
 	uu, err := s.userRepo.GetByIDs(ctx, ids...)
 	if err != nil {
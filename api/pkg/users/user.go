@@ -21,6 +21,9 @@ const (
 	// StatusShadow means the user never logged in, see Referer field to know
 	// why it's created.
 	StatusShadow Status = "shadow"
+	// StatusDeactivated means the user has been deactivated, for example by an identity provider, and can't
+	// log in.
+	StatusDeactivated Status = "deactivated"
 )
 
 type User struct {
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/webhooks"
+)
+
+var _ Repository = &memory{}
+
+type memory struct {
+	mx         sync.RWMutex
+	byID       map[webhooks.ID]*webhooks.Webhook
+	deliveries map[webhooks.DeliveryID]*webhooks.Delivery
+}
+
+func NewMemory() *memory {
+	return &memory{
+		byID:       map[webhooks.ID]*webhooks.Webhook{},
+		deliveries: map[webhooks.DeliveryID]*webhooks.Delivery{},
+	}
+}
+
+func (m *memory) Create(_ context.Context, webhook *webhooks.Webhook) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.byID[webhook.ID] = webhook
+	return nil
+}
+
+func (m *memory) Update(_ context.Context, webhook *webhooks.Webhook) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.byID[webhook.ID] = webhook
+	return nil
+}
+
+func (m *memory) Get(_ context.Context, id webhooks.ID) (*webhooks.Webhook, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	webhook, found := m.byID[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return webhook, nil
+}
+
+func (m *memory) list(match func(*webhooks.Webhook) bool) []*webhooks.Webhook {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	var res []*webhooks.Webhook
+	for _, webhook := range m.byID {
+		if webhook.DeletedAt == nil && match(webhook) {
+			res = append(res, webhook)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	return res
+}
+
+func (m *memory) ListByCodebaseID(_ context.Context, codebaseID codebases.ID) ([]*webhooks.Webhook, error) {
+	return m.list(func(w *webhooks.Webhook) bool {
+		return w.CodebaseID != nil && *w.CodebaseID == codebaseID
+	}), nil
+}
+
+func (m *memory) ListByOrganizationID(_ context.Context, organizationID string) ([]*webhooks.Webhook, error) {
+	return m.list(func(w *webhooks.Webhook) bool {
+		return w.OrganizationID != nil && *w.OrganizationID == organizationID
+	}), nil
+}
+
+func (m *memory) CreateDelivery(_ context.Context, delivery *webhooks.Delivery) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *memory) GetDelivery(_ context.Context, id webhooks.DeliveryID) (*webhooks.Delivery, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	delivery, found := m.deliveries[id]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return delivery, nil
+}
+
+func (m *memory) ListDeliveries(_ context.Context, webhookID webhooks.ID, limit int) ([]*webhooks.Delivery, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	var res []*webhooks.Delivery
+	for _, delivery := range m.deliveries {
+		if delivery.WebhookID == webhookID {
+			res = append(res, delivery)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
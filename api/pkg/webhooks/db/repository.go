@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/webhooks"
+)
+
+type Repository interface {
+	Create(context.Context, *webhooks.Webhook) error
+	Update(context.Context, *webhooks.Webhook) error
+	Get(context.Context, webhooks.ID) (*webhooks.Webhook, error)
+	// ListByCodebaseID returns all webhooks of the codebase that have not been deleted.
+	ListByCodebaseID(context.Context, codebases.ID) ([]*webhooks.Webhook, error)
+	// ListByOrganizationID returns all webhooks of the organization that have not been deleted.
+	ListByOrganizationID(context.Context, string) ([]*webhooks.Webhook, error)
+
+	CreateDelivery(context.Context, *webhooks.Delivery) error
+	GetDelivery(context.Context, webhooks.DeliveryID) (*webhooks.Delivery, error)
+	// ListDeliveries returns the latest deliveries of the webhook, newest first.
+	ListDeliveries(ctx context.Context, webhookID webhooks.ID, limit int) ([]*webhooks.Delivery, error)
+}
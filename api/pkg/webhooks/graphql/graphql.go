@@ -0,0 +1,305 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"getsturdy.com/api/pkg/auth"
+	service_auth "getsturdy.com/api/pkg/auth/service"
+	"getsturdy.com/api/pkg/codebases"
+	service_codebase "getsturdy.com/api/pkg/codebases/service"
+	gqlerror "getsturdy.com/api/pkg/graphql/errors"
+	"getsturdy.com/api/pkg/graphql/resolvers"
+	service_organization "getsturdy.com/api/pkg/organization/service"
+	"getsturdy.com/api/pkg/webhooks"
+	service_webhooks "getsturdy.com/api/pkg/webhooks/service"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const defaultDeliveriesLimit = 20
+
+type rootResolver struct {
+	webhooksService     *service_webhooks.Service
+	codebaseService     *service_codebase.Service
+	organizationService *service_organization.Service
+	authService         *service_auth.Service
+
+	authorRootResolver resolvers.AuthorRootResolver
+}
+
+func New(
+	webhooksService *service_webhooks.Service,
+	codebaseService *service_codebase.Service,
+	organizationService *service_organization.Service,
+	authService *service_auth.Service,
+
+	authorRootResolver resolvers.AuthorRootResolver,
+) resolvers.WebhooksRootResolver {
+	return &rootResolver{
+		webhooksService:     webhooksService,
+		codebaseService:     codebaseService,
+		organizationService: organizationService,
+		authService:         authService,
+
+		authorRootResolver: authorRootResolver,
+	}
+}
+
+func (r *rootResolver) InternalListByCodebaseID(ctx context.Context, codebaseID graphql.ID) ([]resolvers.WebhookResolver, error) {
+	codebase, err := r.codebaseService.GetByID(ctx, codebases.ID(codebaseID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// only users that can create webhooks can see them
+	if err := r.authService.CanWrite(ctx, codebase); err != nil {
+		return []resolvers.WebhookResolver{}, nil
+	}
+
+	list, err := r.webhooksService.ListByCodebaseID(ctx, codebase.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return r.resolvers(list), nil
+}
+
+func (r *rootResolver) InternalListByOrganizationID(ctx context.Context, organizationID string) ([]resolvers.WebhookResolver, error) {
+	org, err := r.organizationService.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	// only users that can create webhooks can see them
+	if err := r.authService.CanWrite(ctx, org); err != nil {
+		return []resolvers.WebhookResolver{}, nil
+	}
+
+	list, err := r.webhooksService.ListByOrganizationID(ctx, org.ID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	return r.resolvers(list), nil
+}
+
+func (r *rootResolver) resolvers(list []*webhooks.Webhook) []resolvers.WebhookResolver {
+	res := make([]resolvers.WebhookResolver, 0, len(list))
+	for _, webhook := range list {
+		res = append(res, &resolver{root: r, webhook: webhook})
+	}
+	return res
+}
+
+func (r *rootResolver) CreateWebhook(ctx context.Context, args resolvers.CreateWebhookArgs) (resolvers.WebhookResolver, error) {
+	userID, err := auth.UserID(ctx)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	events := make([]webhooks.EventType, 0, len(args.Input.Events))
+	for _, event := range args.Input.Events {
+		events = append(events, webhooks.EventType(event))
+	}
+
+	var webhook *webhooks.Webhook
+	switch {
+	case args.Input.CodebaseID != nil && args.Input.OrganizationID == nil:
+		codebase, getErr := r.codebaseService.GetByID(ctx, codebases.ID(*args.Input.CodebaseID))
+		if getErr != nil {
+			return nil, gqlerror.Error(fmt.Errorf("codebase not found: %w", getErr))
+		}
+		if err := r.authService.CanWrite(ctx, codebase); err != nil {
+			return nil, gqlerror.Error(err)
+		}
+		webhook, err = r.webhooksService.CreateForCodebase(ctx, codebase.ID, userID, args.Input.URL, events)
+	case args.Input.OrganizationID != nil && args.Input.CodebaseID == nil:
+		org, getErr := r.organizationService.GetByID(ctx, string(*args.Input.OrganizationID))
+		if getErr != nil {
+			return nil, gqlerror.Error(fmt.Errorf("organization not found: %w", getErr))
+		}
+		if err := r.authService.CanWrite(ctx, org); err != nil {
+			return nil, gqlerror.Error(err)
+		}
+		webhook, err = r.webhooksService.CreateForOrganization(ctx, org.ID, userID, args.Input.URL, events)
+	default:
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "codebaseID", "exactly one of codebaseID and organizationID must be set")
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, service_webhooks.ErrInvalidURL),
+		errors.Is(err, service_webhooks.ErrPrivateURL):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "url", err.Error())
+	case errors.Is(err, service_webhooks.ErrNoEvents),
+		errors.Is(err, service_webhooks.ErrInvalidEvent):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "events", err.Error())
+	default:
+		return nil, gqlerror.Error(fmt.Errorf("failed to create webhook: %w", err))
+	}
+
+	return &resolver{root: r, webhook: webhook, secret: &webhook.Secret}, nil
+}
+
+func (r *rootResolver) DeleteWebhook(ctx context.Context, args resolvers.DeleteWebhookArgs) (resolvers.WebhookResolver, error) {
+	webhook, err := r.webhooksService.Get(ctx, webhooks.ID(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.canWrite(ctx, webhook); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	switch err := r.webhooksService.Delete(ctx, webhook); {
+	case err == nil:
+	case errors.Is(err, service_webhooks.ErrAlreadyDeleted):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{root: r, webhook: webhook}, nil
+}
+
+func (r *rootResolver) RedeliverWebhookDelivery(ctx context.Context, args resolvers.RedeliverWebhookDeliveryArgs) (resolvers.WebhookResolver, error) {
+	delivery, err := r.webhooksService.GetDelivery(ctx, webhooks.DeliveryID(args.Input.ID))
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	webhook, err := r.webhooksService.Get(ctx, delivery.WebhookID)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	if err := r.canWrite(ctx, webhook); err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	switch err := r.webhooksService.Redeliver(ctx, delivery); {
+	case err == nil:
+	case errors.Is(err, service_webhooks.ErrAlreadyDeleted):
+		return nil, gqlerror.Error(gqlerror.ErrBadRequest, "id", err.Error())
+	default:
+		return nil, gqlerror.Error(err)
+	}
+
+	return &resolver{root: r, webhook: webhook}, nil
+}
+
+// canWrite returns nil if the user can manage the webhook, that is if they can write to the codebase or the
+// organization that owns it.
+func (r *rootResolver) canWrite(ctx context.Context, webhook *webhooks.Webhook) error {
+	if webhook.CodebaseID != nil {
+		codebase, err := r.codebaseService.GetByID(ctx, *webhook.CodebaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get codebase: %w", err)
+		}
+		return r.authService.CanWrite(ctx, codebase)
+	}
+
+	org, err := r.organizationService.GetByID(ctx, *webhook.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+	return r.authService.CanWrite(ctx, org)
+}
+
+type resolver struct {
+	root    *rootResolver
+	secret  *string
+	webhook *webhooks.Webhook
+}
+
+func (r *resolver) ID() graphql.ID {
+	return graphql.ID(r.webhook.ID)
+}
+
+func (r *resolver) URL() string {
+	return r.webhook.URL
+}
+
+func (r *resolver) Events() []string {
+	return r.webhook.Events
+}
+
+func (r *resolver) CreatedBy(ctx context.Context) (resolvers.AuthorResolver, error) {
+	return r.root.authorRootResolver.Author(ctx, graphql.ID(r.webhook.CreatedBy))
+}
+
+func (r *resolver) CreatedAt() int32 {
+	return int32(r.webhook.CreatedAt.Unix())
+}
+
+func (r *resolver) Deliveries(ctx context.Context, args resolvers.WebhookDeliveriesArgs) ([]resolvers.WebhookDeliveryResolver, error) {
+	limit := defaultDeliveriesLimit
+	if args.Last != nil {
+		limit = int(*args.Last)
+	}
+
+	deliveries, err := r.root.webhooksService.ListDeliveries(ctx, r.webhook.ID, limit)
+	if err != nil {
+		return nil, gqlerror.Error(err)
+	}
+
+	res := make([]resolvers.WebhookDeliveryResolver, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		res = append(res, &deliveryResolver{delivery: delivery})
+	}
+	return res, nil
+}
+
+func (r *resolver) Secret() *string {
+	return r.secret
+}
+
+type deliveryResolver struct {
+	delivery *webhooks.Delivery
+}
+
+func (r *deliveryResolver) ID() graphql.ID {
+	return graphql.ID(r.delivery.ID)
+}
+
+func (r *deliveryResolver) EventID() graphql.ID {
+	return graphql.ID(r.delivery.EventID)
+}
+
+func (r *deliveryResolver) EventType() string {
+	return string(r.delivery.EventType)
+}
+
+func (r *deliveryResolver) Attempt() int32 {
+	return int32(r.delivery.Attempt)
+}
+
+func (r *deliveryResolver) Payload() string {
+	return string(r.delivery.Payload)
+}
+
+func (r *deliveryResolver) StatusCode() *int32 {
+	if r.delivery.StatusCode == nil {
+		return nil
+	}
+	code := int32(*r.delivery.StatusCode)
+	return &code
+}
+
+func (r *deliveryResolver) Error() *string {
+	return r.delivery.Error
+}
+
+func (r *deliveryResolver) DurationMs() int32 {
+	return int32(r.delivery.DurationMS)
+}
+
+func (r *deliveryResolver) Succeeded() bool {
+	return r.delivery.Succeeded()
+}
+
+func (r *deliveryResolver) CreatedAt() int32 {
+	return int32(r.delivery.CreatedAt.Unix())
+}
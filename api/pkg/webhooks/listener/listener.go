@@ -0,0 +1,64 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+	eventsv2 "getsturdy.com/api/pkg/events/v2"
+	"getsturdy.com/api/pkg/github"
+	"getsturdy.com/api/pkg/review"
+	"getsturdy.com/api/pkg/statuses"
+	"getsturdy.com/api/pkg/webhooks"
+	service_webhooks "getsturdy.com/api/pkg/webhooks/service"
+
+	"go.uber.org/zap"
+)
+
+// Listener triggers webhooks for the events that are sent to codebases and organizations.
+type Listener struct {
+	logger          *zap.Logger
+	subscriber      *eventsv2.Subscriber
+	webhooksService *service_webhooks.Service
+}
+
+func New(
+	logger *zap.Logger,
+	subscriber *eventsv2.Subscriber,
+	webhooksService *service_webhooks.Service,
+) *Listener {
+	return &Listener{
+		logger:          logger.Named("webhooksListener"),
+		subscriber:      subscriber,
+		webhooksService: webhooksService,
+	}
+}
+
+func (l *Listener) Start(ctx context.Context) error {
+	topic := eventsv2.SubscribeAll()
+
+	l.subscriber.OnChangeLanded(ctx, topic, func(ctx context.Context, change *changes.Change) error {
+		return l.trigger(ctx, change.CodebaseID, webhooks.EventTypeChangeLanded, webhooks.NewChangeData(change))
+	})
+	l.subscriber.OnReviewUpdated(ctx, topic, func(ctx context.Context, r *review.Review) error {
+		return l.trigger(ctx, r.CodebaseID, webhooks.EventTypeReviewUpdated, webhooks.NewReviewData(r))
+	})
+	l.subscriber.OnStatusUpdated(ctx, topic, func(ctx context.Context, status *statuses.Status) error {
+		return l.trigger(ctx, status.CodebaseID, webhooks.EventTypeStatusUpdated, status)
+	})
+	l.subscriber.OnGitHubPRUpdated(ctx, topic, func(ctx context.Context, pr *github.PullRequest) error {
+		return l.trigger(ctx, pr.CodebaseID, webhooks.EventTypeGitHubPullRequestUpdated, webhooks.NewPullRequestData(pr))
+	})
+
+	l.logger.Info("listening for events")
+	<-ctx.Done()
+	return nil
+}
+
+func (l *Listener) trigger(ctx context.Context, codebaseID codebases.ID, eventType webhooks.EventType, data any) error {
+	if err := l.webhooksService.Trigger(ctx, codebaseID, eventType, data); err != nil {
+		return fmt.Errorf("failed to trigger %s webhooks: %w", eventType, err)
+	}
+	return nil
+}
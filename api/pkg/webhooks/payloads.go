@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"time"
+
+	"getsturdy.com/api/pkg/changes"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/github"
+	"getsturdy.com/api/pkg/review"
+	"getsturdy.com/api/pkg/statuses"
+	"getsturdy.com/api/pkg/users"
+)
+
+// ChangeData is the data of EventTypeChangeLanded events.
+type ChangeData struct {
+	ID          changes.ID   `json:"id"`
+	CodebaseID  codebases.ID `json:"codebase_id"`
+	WorkspaceID *string      `json:"workspace_id"`
+	Title       *string      `json:"title"`
+	Description string       `json:"description"`
+	CommitSHA   *string      `json:"commit_sha"`
+	AuthorID    *users.ID    `json:"author_id"`
+	CreatedAt   *time.Time   `json:"created_at"`
+}
+
+func NewChangeData(change *changes.Change) *ChangeData {
+	return &ChangeData{
+		ID:          change.ID,
+		CodebaseID:  change.CodebaseID,
+		WorkspaceID: change.WorkspaceID,
+		Title:       change.Title,
+		Description: change.UpdatedDescription,
+		CommitSHA:   change.CommitID,
+		AuthorID:    change.UserID,
+		CreatedAt:   change.CreatedAt,
+	}
+}
+
+// ReviewData is the data of EventTypeReviewUpdated events.
+type ReviewData struct {
+	ID          string             `json:"id"`
+	CodebaseID  codebases.ID       `json:"codebase_id"`
+	WorkspaceID string             `json:"workspace_id"`
+	ReviewerID  users.ID           `json:"reviewer_id"`
+	Grade       review.ReviewGrade `json:"grade"`
+	RequestedBy *users.ID          `json:"requested_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	DismissedAt *time.Time         `json:"dismissed_at"`
+}
+
+func NewReviewData(r *review.Review) *ReviewData {
+	return &ReviewData{
+		ID:          r.ID,
+		CodebaseID:  r.CodebaseID,
+		WorkspaceID: r.WorkspaceID,
+		ReviewerID:  r.UserID,
+		Grade:       r.Grade,
+		RequestedBy: r.RequestedBy,
+		CreatedAt:   r.CreatedAt,
+		DismissedAt: r.DismissedAt,
+	}
+}
+
+// StatusData is the data of EventTypeStatusUpdated events.
+type StatusData = statuses.Status
+
+// PullRequestData is the data of EventTypeGitHubPullRequestUpdated events.
+type PullRequestData struct {
+	ID          string                  `json:"id"`
+	CodebaseID  codebases.ID            `json:"codebase_id"`
+	WorkspaceID string                  `json:"workspace_id"`
+	Number      int                     `json:"number"`
+	Head        string                  `json:"head"`
+	HeadSHA     *string                 `json:"head_sha"`
+	Base        string                  `json:"base"`
+	State       github.PullRequestState `json:"state"`
+	CreatedAt   time.Time               `json:"created_at"`
+	ClosedAt    *time.Time              `json:"closed_at"`
+	MergedAt    *time.Time              `json:"merged_at"`
+}
+
+func NewPullRequestData(pr *github.PullRequest) *PullRequestData {
+	return &PullRequestData{
+		ID:          pr.ID,
+		CodebaseID:  pr.CodebaseID,
+		WorkspaceID: pr.WorkspaceID,
+		Number:      pr.GitHubPRNumber,
+		Head:        pr.Head,
+		HeadSHA:     pr.HeadSHA,
+		Base:        pr.Base,
+		State:       pr.State,
+		CreatedAt:   pr.CreatedAt,
+		ClosedAt:    pr.ClosedAt,
+		MergedAt:    pr.MergedAt,
+	}
+}
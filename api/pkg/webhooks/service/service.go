@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/codebases"
+	db_codebases "getsturdy.com/api/pkg/codebases/db"
+	"getsturdy.com/api/pkg/http/restricted"
+	"getsturdy.com/api/pkg/users"
+	"getsturdy.com/api/pkg/webhooks"
+	db_webhooks "getsturdy.com/api/pkg/webhooks/db"
+	worker_webhooks "getsturdy.com/api/pkg/webhooks/worker"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidURL     = errors.New("url must be an absolute http or https url")
+	ErrPrivateURL     = errors.New("url must not point to a private address")
+	ErrNoEvents       = errors.New("at least one event is required")
+	ErrInvalidEvent   = errors.New("invalid event")
+	ErrAlreadyDeleted = errors.New("webhook is already deleted")
+)
+
+type Service struct {
+	repo         db_webhooks.Repository
+	codebaseRepo db_codebases.CodebaseRepository
+	auditService *service_audit.Service
+	queue        *worker_webhooks.Queue
+	outboundCfg  *restricted.Configuration
+}
+
+func New(
+	repo db_webhooks.Repository,
+	codebaseRepo db_codebases.CodebaseRepository,
+	auditService *service_audit.Service,
+	queue *worker_webhooks.Queue,
+	outboundCfg *restricted.Configuration,
+) *Service {
+	return &Service{
+		repo:         repo,
+		codebaseRepo: codebaseRepo,
+		auditService: auditService,
+		queue:        queue,
+		outboundCfg:  outboundCfg,
+	}
+}
+
+// CreateForCodebase subscribes the url to the given events in the codebase. A secret to verify the signature of
+// the deliveries with is generated.
+func (s *Service) CreateForCodebase(ctx context.Context, codebaseID codebases.ID, createdBy users.ID, rawURL string, events []webhooks.EventType) (*webhooks.Webhook, error) {
+	webhook, err := s.newWebhook(createdBy, rawURL, events)
+	if err != nil {
+		return nil, err
+	}
+	webhook.CodebaseID = &codebaseID
+	if err := s.create(ctx, webhook, audit.CodebaseID(codebaseID)); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// CreateForOrganization subscribes the url to the given events in all codebases of the organization. A secret to
+// verify the signature of the deliveries with is generated.
+func (s *Service) CreateForOrganization(ctx context.Context, organizationID string, createdBy users.ID, rawURL string, events []webhooks.EventType) (*webhooks.Webhook, error) {
+	webhook, err := s.newWebhook(createdBy, rawURL, events)
+	if err != nil {
+		return nil, err
+	}
+	webhook.OrganizationID = &organizationID
+	if err := s.create(ctx, webhook, audit.OrganizationID(organizationID)); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *Service) create(ctx context.Context, webhook *webhooks.Webhook, owner audit.EntryOption) error {
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return fmt.Errorf("failed to create: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, audit.ActionWebhookCreated, audit.TargetWebhook, webhook.ID.String(),
+		owner,
+		audit.After(webhook),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) newWebhook(createdBy users.ID, rawURL string, events []webhooks.EventType) (*webhooks.Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	// host names are checked again after they are resolved, when the webhook is delivered
+	if !s.outboundCfg.IsAllowedHost(u.Hostname()) {
+		return nil, ErrPrivateURL
+	}
+
+	if len(events) == 0 {
+		return nil, ErrNoEvents
+	}
+	eventStrings := make([]string, 0, len(events))
+	for _, event := range events {
+		if !event.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidEvent, event)
+		}
+		eventStrings = append(eventStrings, string(event))
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return &webhooks.Webhook{
+		ID:        webhooks.ID(uuid.NewString()),
+		URL:       u.String(),
+		Secret:    secret,
+		Events:    eventStrings,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *Service) Get(ctx context.Context, id webhooks.ID) (*webhooks.Webhook, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) ListByCodebaseID(ctx context.Context, codebaseID codebases.ID) ([]*webhooks.Webhook, error) {
+	res, err := s.repo.ListByCodebaseID(ctx, codebaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return res, nil
+}
+
+func (s *Service) ListByOrganizationID(ctx context.Context, organizationID string) ([]*webhooks.Webhook, error) {
+	res, err := s.repo.ListByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return res, nil
+}
+
+// Delete stops all deliveries to the webhook, including retries that are in progress.
+func (s *Service) Delete(ctx context.Context, webhook *webhooks.Webhook) error {
+	if webhook.DeletedAt != nil {
+		return ErrAlreadyDeleted
+	}
+
+	before := *webhook
+	now := time.Now()
+	webhook.DeletedAt = &now
+
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	opts := []audit.EntryOption{audit.Before(before), audit.After(webhook)}
+	if webhook.CodebaseID != nil {
+		opts = append(opts, audit.CodebaseID(*webhook.CodebaseID))
+	}
+	if webhook.OrganizationID != nil {
+		opts = append(opts, audit.OrganizationID(*webhook.OrganizationID))
+	}
+	if err := s.auditService.Record(ctx, audit.ActionWebhookDeleted, audit.TargetWebhook, webhook.ID.String(), opts...); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetDelivery(ctx context.Context, id webhooks.DeliveryID) (*webhooks.Delivery, error) {
+	return s.repo.GetDelivery(ctx, id)
+}
+
+// ListDeliveries returns the latest delivery attempts of the webhook, newest first.
+func (s *Service) ListDeliveries(ctx context.Context, webhookID webhooks.ID, limit int) ([]*webhooks.Delivery, error) {
+	res, err := s.repo.ListDeliveries(ctx, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries: %w", err)
+	}
+	return res, nil
+}
+
+// Redeliver sends the payload of the delivery again. The new delivery is retried like any other.
+func (s *Service) Redeliver(ctx context.Context, delivery *webhooks.Delivery) error {
+	webhook, err := s.repo.Get(ctx, delivery.WebhookID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if webhook.DeletedAt != nil {
+		return ErrAlreadyDeleted
+	}
+
+	if err := s.queue.Enqueue(ctx, &worker_webhooks.DeliveryQueueEntry{
+		WebhookID: delivery.WebhookID,
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		Payload:   delivery.Payload,
+		Attempt:   1,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue delivery: %w", err)
+	}
+	return nil
+}
+
+// Trigger delivers the event to all webhooks of the codebase and of its organization that are subscribed to it.
+func (s *Service) Trigger(ctx context.Context, codebaseID codebases.ID, eventType webhooks.EventType, data any) error {
+	codebase, err := s.codebaseRepo.Get(codebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get codebase: %w", err)
+	}
+
+	subscribed, err := s.repo.ListByCodebaseID(ctx, codebase.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list codebase webhooks: %w", err)
+	}
+	if codebase.OrganizationID != nil {
+		organizationWebhooks, err := s.repo.ListByOrganizationID(ctx, *codebase.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to list organization webhooks: %w", err)
+		}
+		subscribed = append(subscribed, organizationWebhooks...)
+	}
+
+	payload := &webhooks.Payload{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		CodebaseID: codebase.ID,
+		CreatedAt:  time.Now(),
+		Data:       data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	for _, webhook := range subscribed {
+		if !webhook.IsSubscribed(eventType) {
+			continue
+		}
+		if err := s.queue.Enqueue(ctx, &worker_webhooks.DeliveryQueueEntry{
+			WebhookID: webhook.ID,
+			EventID:   payload.ID,
+			EventType: eventType,
+			Payload:   body,
+			Attempt:   1,
+		}); err != nil {
+			return fmt.Errorf("failed to enqueue delivery: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	db_audit "getsturdy.com/api/pkg/audit/db"
+	service_audit "getsturdy.com/api/pkg/audit/service"
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/http/restricted"
+	"getsturdy.com/api/pkg/internal/inmemory"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/webhooks"
+	db_webhooks "getsturdy.com/api/pkg/webhooks/db"
+	service_webhooks "getsturdy.com/api/pkg/webhooks/service"
+	worker_webhooks "getsturdy.com/api/pkg/webhooks/worker"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type receiver struct {
+	guard    sync.Mutex
+	payloads map[string][]webhooks.Payload
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var p webhooks.Payload
+	_ = json.Unmarshal(body, &p)
+	r.guard.Lock()
+	r.payloads[req.URL.Path] = append(r.payloads[req.URL.Path], p)
+	r.guard.Unlock()
+}
+
+func (r *receiver) received(path string) []webhooks.Payload {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+	return r.payloads[path]
+}
+
+func newService(t *testing.T, outboundCfg *restricted.Configuration) (*service_webhooks.Service, string, *receiver) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	organizationID := "organization"
+	codebaseRepo := inmemory.NewInMemoryCodebaseRepo()
+	assert.NoError(t, codebaseRepo.Create(codebases.Codebase{ID: "codebase", OrganizationID: &organizationID}))
+
+	rcv := &receiver{payloads: map[string][]webhooks.Payload{}}
+	srv := httptest.NewServer(rcv)
+	t.Cleanup(srv.Close)
+
+	logger := zap.NewNop()
+	repo := db_webhooks.NewMemory()
+	q := worker_webhooks.New(logger, queue.NewInMemory(logger), repo, outboundCfg)
+	go func() {
+		assert.NoError(t, q.Start(ctx))
+	}()
+	// wait for the queue to subscribe
+	time.Sleep(10 * time.Millisecond)
+
+	svc := service_webhooks.New(repo, codebaseRepo, service_audit.New(db_audit.NewMemory(), codebaseRepo), q, outboundCfg)
+	return svc, srv.URL, rcv
+}
+
+func TestCreate_validation(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newService(t, &restricted.Configuration{})
+
+	_, err := svc.CreateForCodebase(ctx, "codebase", "user", "ftp://example.com", []webhooks.EventType{webhooks.EventTypeChangeLanded})
+	assert.ErrorIs(t, err, service_webhooks.ErrInvalidURL)
+
+	for _, private := range []string{"http://localhost:8080", "http://127.0.0.1", "http://[::1]", "http://169.254.169.254/latest/meta-data", "http://10.0.0.1"} {
+		_, err = svc.CreateForCodebase(ctx, "codebase", "user", private, []webhooks.EventType{webhooks.EventTypeChangeLanded})
+		assert.ErrorIs(t, err, service_webhooks.ErrPrivateURL, private)
+	}
+
+	_, err = svc.CreateForCodebase(ctx, "codebase", "user", "https://example.com", nil)
+	assert.ErrorIs(t, err, service_webhooks.ErrNoEvents)
+
+	_, err = svc.CreateForCodebase(ctx, "codebase", "user", "https://example.com", []webhooks.EventType{"codebase.deleted"})
+	assert.ErrorIs(t, err, service_webhooks.ErrInvalidEvent)
+
+	webhook, err := svc.CreateForOrganization(ctx, "organization", "user", "https://example.com", []webhooks.EventType{webhooks.EventTypeChangeLanded})
+	assert.NoError(t, err)
+	assert.Len(t, webhook.Secret, 64)
+}
+
+func TestTrigger(t *testing.T) {
+	ctx := context.Background()
+	// the receiver runs on localhost
+	svc, url, rcv := newService(t, &restricted.Configuration{AllowPrivateAddresses: true})
+
+	_, err := svc.CreateForCodebase(ctx, "codebase", "user", url+"/codebase", []webhooks.EventType{webhooks.EventTypeChangeLanded})
+	assert.NoError(t, err)
+	_, err = svc.CreateForOrganization(ctx, "organization", "user", url+"/organization", []webhooks.EventType{webhooks.EventTypeChangeLanded, webhooks.EventTypeStatusUpdated})
+	assert.NoError(t, err)
+	deleted, err := svc.CreateForCodebase(ctx, "codebase", "user", url+"/deleted", []webhooks.EventType{webhooks.EventTypeChangeLanded})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Delete(ctx, deleted))
+	assert.ErrorIs(t, svc.Delete(ctx, deleted), service_webhooks.ErrAlreadyDeleted)
+
+	assert.NoError(t, svc.Trigger(ctx, "codebase", webhooks.EventTypeChangeLanded, map[string]string{"id": "change"}))
+	assert.NoError(t, svc.Trigger(ctx, "codebase", webhooks.EventTypeStatusUpdated, map[string]string{"id": "status"}))
+
+	assert.Eventually(t, func() bool {
+		return len(rcv.received("/codebase")) == 1 && len(rcv.received("/organization")) == 2
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, webhooks.EventTypeChangeLanded, rcv.received("/codebase")[0].Type)
+	assert.Equal(t, codebases.ID("codebase"), rcv.received("/codebase")[0].CodebaseID)
+	assert.Empty(t, rcv.received("/deleted"))
+}
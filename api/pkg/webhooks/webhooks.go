@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"getsturdy.com/api/pkg/codebases"
+	"getsturdy.com/api/pkg/users"
+
+	"github.com/lib/pq"
+)
+
+type ID string
+
+func (id ID) String() string {
+	return string(id)
+}
+
+type DeliveryID string
+
+func (id DeliveryID) String() string {
+	return string(id)
+}
+
+// EventType is the type of event that a webhook is subscribed to. Event types mirror the events in events/v2.
+type EventType string
+
+const (
+	EventTypeUndefined EventType = ""
+	// EventTypeChangeLanded is sent when a change is landed on the trunk of a codebase.
+	EventTypeChangeLanded EventType = "change.landed"
+	// EventTypeReviewUpdated is sent when a review is created, requested or dismissed.
+	EventTypeReviewUpdated EventType = "review.updated"
+	// EventTypeStatusUpdated is sent when a status is reported for a commit, for example by CI.
+	EventTypeStatusUpdated EventType = "status.updated"
+	// EventTypeGitHubPullRequestUpdated is sent when a pull request on GitHub is opened, updated or merged.
+	EventTypeGitHubPullRequestUpdated EventType = "github_pull_request.updated"
+)
+
+var validEventTypes = map[EventType]bool{
+	EventTypeChangeLanded:             true,
+	EventTypeReviewUpdated:            true,
+	EventTypeStatusUpdated:            true,
+	EventTypeGitHubPullRequestUpdated: true,
+}
+
+func (t EventType) IsValid() bool {
+	return validEventTypes[t]
+}
+
+// Webhook subscribes a URL to events of a codebase, or of all codebases in an organization. Exactly one of
+// CodebaseID and OrganizationID is set.
+type Webhook struct {
+	ID             ID             `db:"id" json:"id"`
+	CodebaseID     *codebases.ID  `db:"codebase_id" json:"codebase_id"`
+	OrganizationID *string        `db:"organization_id" json:"organization_id"`
+	URL            string         `db:"url" json:"url"`
+	Secret         string         `db:"secret" json:"-"`
+	Events         pq.StringArray `db:"events" json:"events"`
+	CreatedBy      users.ID       `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	DeletedAt      *time.Time     `db:"deleted_at" json:"deleted_at"`
+}
+
+// IsSubscribed returns true if the webhook wants to receive events of the given type.
+func (w *Webhook) IsSubscribed(t EventType) bool {
+	for _, e := range w.Events {
+		if EventType(e) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body of every delivery.
+type Payload struct {
+	// ID is the id of the event, redeliveries of the same event have the same id.
+	ID         string       `json:"id"`
+	Type       EventType    `json:"type"`
+	CodebaseID codebases.ID `json:"codebase_id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Data       any          `json:"data"`
+}
+
+// Delivery is a single attempt to deliver an event to a webhook.
+type Delivery struct {
+	ID         DeliveryID `db:"id"`
+	WebhookID  ID         `db:"webhook_id"`
+	EventID    string     `db:"event_id"`
+	EventType  EventType  `db:"event_type"`
+	Payload    []byte     `db:"payload"`
+	Attempt    int        `db:"attempt"`
+	StatusCode *int       `db:"status_code"`
+	// Error is set if the request could not be made, or if the response status code was not 2xx.
+	Error      *string   `db:"error"`
+	DurationMS int       `db:"duration_ms"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// Succeeded returns true if the webhook responded with a 2xx status code.
+func (d *Delivery) Succeeded() bool {
+	return d.StatusCode != nil && *d.StatusCode >= 200 && *d.StatusCode < 300
+}
+
+// Sign returns the signature of the payload, that is sent in the X-Sturdy-Signature-256 header. It is the
+// hex encoded HMAC-SHA256 of the payload, keyed with the secret of the webhook.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
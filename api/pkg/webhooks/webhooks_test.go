@@ -0,0 +1,31 @@
+package webhooks_test
+
+import (
+	"testing"
+
+	"getsturdy.com/api/pkg/webhooks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// generated with: echo -n '{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=6146142a2ce0159e84c0767881e4ec80bc397da62526e7d19f70795eb79460c0",
+		webhooks.Sign("secret", []byte(`{"id":"1"}`)),
+	)
+	assert.NotEqual(t, webhooks.Sign("secret", []byte(`{"id":"1"}`)), webhooks.Sign("other", []byte(`{"id":"1"}`)))
+}
+
+func TestIsSubscribed(t *testing.T) {
+	w := &webhooks.Webhook{Events: []string{string(webhooks.EventTypeChangeLanded)}}
+	assert.True(t, w.IsSubscribed(webhooks.EventTypeChangeLanded))
+	assert.False(t, w.IsSubscribed(webhooks.EventTypeStatusUpdated))
+}
+
+func TestDeliverySucceeded(t *testing.T) {
+	code := func(c int) *int { return &c }
+	assert.False(t, (&webhooks.Delivery{}).Succeeded())
+	assert.True(t, (&webhooks.Delivery{StatusCode: code(204)}).Succeeded())
+	assert.False(t, (&webhooks.Delivery{StatusCode: code(500)}).Succeeded())
+}
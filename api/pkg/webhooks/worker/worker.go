@@ -0,0 +1,203 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"getsturdy.com/api/pkg/http/restricted"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/queue/names"
+	"getsturdy.com/api/pkg/webhooks"
+	db_webhooks "getsturdy.com/api/pkg/webhooks/db"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxAttempts is how many times a delivery is attempted before it's dropped.
+	maxAttempts = 5
+	// retryDelay is the delay before the first retry, it doubles with every attempt.
+	retryDelay = 30 * time.Second
+	// timeout is how long the receiver has to respond.
+	timeout = 10 * time.Second
+)
+
+type DeliveryQueueEntry struct {
+	WebhookID webhooks.ID        `json:"webhook_id"`
+	EventID   string             `json:"event_id"`
+	EventType webhooks.EventType `json:"event_type"`
+	Payload   []byte             `json:"payload"`
+	Attempt   int                `json:"attempt"`
+}
+
+type Queue struct {
+	logger *zap.Logger
+	queue  queue.Queue
+	name   names.IncompleteQueueName
+	repo   db_webhooks.Repository
+	client *http.Client
+
+	retryDelay time.Duration
+}
+
+func New(
+	logger *zap.Logger,
+	queue queue.Queue,
+	repo db_webhooks.Repository,
+	outboundCfg *restricted.Configuration,
+) *Queue {
+	return &Queue{
+		logger: logger.Named("webhookDeliveryQueue"),
+		queue:  queue,
+		name:   names.WebhookDeliveries,
+		repo:   repo,
+		client: restricted.NewClient(outboundCfg, timeout),
+
+		retryDelay: retryDelay,
+	}
+}
+
+// Enqueue schedules the payload to be delivered to the webhook. The first attempt is made right away,
+// following attempts are delayed.
+func (q *Queue) Enqueue(ctx context.Context, entry *DeliveryQueueEntry) error {
+	var delay time.Duration
+	if entry.Attempt > 1 {
+		delay = q.retryDelay << (entry.Attempt - 2)
+	}
+	if err := q.queue.PublishDelayed(ctx, q.name, entry, delay); err != nil {
+		return fmt.Errorf("could not publish to queue: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) Start(ctx context.Context) error {
+	messages := make(chan queue.Message)
+	go func() {
+		for msg := range messages {
+			m := &DeliveryQueueEntry{}
+			if err := msg.As(m); err != nil {
+				// the message will never decode, drop it instead of having it redelivered
+				q.logger.Error("failed to decode message", zap.Error(err))
+				if err := msg.Ack(); err != nil {
+					q.logger.Error("failed to ack message", zap.Error(err))
+				}
+				continue
+			}
+
+			// handle in the background to not block the queue on slow receivers
+			go q.handle(ctx, msg, m)
+		}
+	}()
+
+	q.logger.Info("starting queue", zap.Stringer("queue_name", q.name))
+	if err := q.queue.Subscribe(ctx, q.name, messages); err != nil {
+		return fmt.Errorf("could not subscribe to queue: %w", err)
+	}
+	q.logger.Info("queue stoped", zap.Stringer("queue_name", q.name))
+
+	return nil
+}
+
+func (q *Queue) handle(ctx context.Context, msg queue.Message, m *DeliveryQueueEntry) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			q.logger.Error("panic in webhook delivery", zap.String("panic", fmt.Sprintf("%v", rec)))
+		}
+	}()
+
+	logger := q.logger.With(
+		zap.Stringer("webhook_id", m.WebhookID),
+		zap.String("event_id", m.EventID),
+		zap.Int("attempt", m.Attempt),
+	)
+
+	if err := q.deliver(ctx, m); err != nil {
+		if m.Attempt >= maxAttempts {
+			logger.Error("failed to deliver webhook, giving up", zap.Error(err))
+		} else if err := q.Enqueue(ctx, &DeliveryQueueEntry{
+			WebhookID: m.WebhookID,
+			EventID:   m.EventID,
+			EventType: m.EventType,
+			Payload:   m.Payload,
+			Attempt:   m.Attempt + 1,
+		}); err != nil {
+			logger.Error("failed to enqueue webhook delivery", zap.Error(err))
+			return
+		} else {
+			logger.Warn("failed to deliver webhook, will retry", zap.Error(err))
+		}
+	}
+
+	if err := msg.Ack(); err != nil {
+		logger.Error("failed to ack message", zap.Error(err))
+	}
+}
+
+var errUnsuccessful = errors.New("webhook responded with a non-2xx status code")
+
+// deliver sends the payload to the webhook, and records the attempt in the delivery log. Deliveries to deleted
+// webhooks are dropped.
+func (q *Queue) deliver(ctx context.Context, m *DeliveryQueueEntry) error {
+	webhook, err := q.repo.Get(ctx, m.WebhookID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if webhook.DeletedAt != nil {
+		return nil
+	}
+
+	delivery := &webhooks.Delivery{
+		ID:        webhooks.DeliveryID(uuid.NewString()),
+		WebhookID: webhook.ID,
+		EventID:   m.EventID,
+		EventType: m.EventType,
+		Payload:   m.Payload,
+		Attempt:   m.Attempt,
+		CreatedAt: time.Now(),
+	}
+
+	deliveryErr := q.send(ctx, webhook, delivery)
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		delivery.Error = &msg
+	}
+	delivery.DurationMS = int(time.Since(delivery.CreatedAt).Milliseconds())
+
+	if err := q.repo.CreateDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to create delivery: %w", err)
+	}
+
+	return deliveryErr
+}
+
+func (q *Queue) send(ctx context.Context, webhook *webhooks.Webhook, delivery *webhooks.Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sturdy-Webhooks")
+	req.Header.Set("X-Sturdy-Event", string(delivery.EventType))
+	req.Header.Set("X-Sturdy-Delivery", delivery.ID.String())
+	req.Header.Set("X-Sturdy-Signature-256", webhooks.Sign(webhook.Secret, delivery.Payload))
+
+	res, err := q.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	// the response body is not read, only the status code is recorded
+	defer res.Body.Close()
+
+	statusCode := res.StatusCode
+	delivery.StatusCode = &statusCode
+
+	if !delivery.Succeeded() {
+		return fmt.Errorf("%w: %d", errUnsuccessful, res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"getsturdy.com/api/pkg/http/restricted"
+	"getsturdy.com/api/pkg/queue"
+	"getsturdy.com/api/pkg/webhooks"
+	db_webhooks "getsturdy.com/api/pkg/webhooks/db"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type flakyServer struct {
+	guard      sync.Mutex
+	failures   int
+	attempts   int
+	signatures []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+	s.attempts++
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Sturdy-Signature-256") == webhooks.Sign("secret", body) {
+		s.signatures = append(s.signatures, r.Header.Get("X-Sturdy-Signature-256"))
+	}
+	if s.attempts <= s.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *flakyServer) stats() (int, int) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+	return s.attempts, len(s.signatures)
+}
+
+func setup(t *testing.T, server *flakyServer) (*Queue, db_webhooks.Repository, *webhooks.Webhook) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	logger := zap.NewNop()
+	repo := db_webhooks.NewMemory()
+	q := New(logger, queue.NewInMemory(logger), repo, &restricted.Configuration{AllowPrivateAddresses: true})
+	q.retryDelay = time.Millisecond
+
+	webhook := &webhooks.Webhook{
+		ID:     "webhook-id",
+		URL:    srv.URL,
+		Secret: "secret",
+		Events: []string{string(webhooks.EventTypeChangeLanded)},
+	}
+	assert.NoError(t, repo.Create(ctx, webhook))
+
+	go func() {
+		assert.NoError(t, q.Start(ctx))
+	}()
+	// wait for the queue to subscribe
+	time.Sleep(10 * time.Millisecond)
+
+	return q, repo, webhook
+}
+
+func entry(webhookID webhooks.ID) *DeliveryQueueEntry {
+	return &DeliveryQueueEntry{
+		WebhookID: webhookID,
+		EventID:   "event-id",
+		EventType: webhooks.EventTypeChangeLanded,
+		Payload:   []byte(`{"id":"event-id"}`),
+		Attempt:   1,
+	}
+}
+
+func TestDeliver_shouldRetryAndLogFailedDeliveries(t *testing.T) {
+	server := &flakyServer{failures: 2}
+	q, repo, webhook := setup(t, server)
+
+	assert.NoError(t, q.Enqueue(context.Background(), entry(webhook.ID)))
+
+	assert.Eventually(t, func() bool {
+		attempts, _ := server.stats()
+		return attempts == 3
+	}, time.Second, time.Millisecond)
+
+	_, signed := server.stats()
+	assert.Equal(t, 3, signed, "all requests should be signed")
+
+	assert.Eventually(t, func() bool {
+		deliveries, err := repo.ListDeliveries(context.Background(), webhook.ID, 10)
+		return err == nil && len(deliveries) == 3
+	}, time.Second, time.Millisecond)
+
+	deliveries, err := repo.ListDeliveries(context.Background(), webhook.ID, 10)
+	assert.NoError(t, err)
+	var succeeded, failed int
+	for _, d := range deliveries {
+		if d.Succeeded() {
+			succeeded++
+			assert.Nil(t, d.Error)
+		} else {
+			failed++
+			assert.Equal(t, http.StatusInternalServerError, *d.StatusCode)
+			assert.NotNil(t, d.Error)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 2, failed)
+}
+
+func TestDeliver_shouldGiveUpAfterMaxAttempts(t *testing.T) {
+	server := &flakyServer{failures: 100}
+	q, _, webhook := setup(t, server)
+
+	assert.NoError(t, q.Enqueue(context.Background(), entry(webhook.ID)))
+
+	assert.Eventually(t, func() bool {
+		attempts, _ := server.stats()
+		return attempts == maxAttempts
+	}, time.Second, time.Millisecond)
+
+	// no more attempts are made
+	time.Sleep(50 * time.Millisecond)
+	attempts, _ := server.stats()
+	assert.Equal(t, maxAttempts, attempts)
+}
+
+func TestDeliver_shouldDropDeliveriesToDeletedWebhooks(t *testing.T) {
+	server := &flakyServer{}
+	q, repo, webhook := setup(t, server)
+
+	now := time.Now()
+	webhook.DeletedAt = &now
+	assert.NoError(t, repo.Update(context.Background(), webhook))
+
+	assert.NoError(t, q.deliver(context.Background(), entry(webhook.ID)))
+
+	attempts, _ := server.stats()
+	assert.Equal(t, 0, attempts)
+	deliveries, err := repo.ListDeliveries(context.Background(), webhook.ID, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestDeliver_shouldNotDeliverToPrivateAddresses(t *testing.T) {
+	server := &flakyServer{}
+	q, repo, webhook := setup(t, server)
+	q.client = restricted.NewClient(&restricted.Configuration{}, timeout)
+
+	assert.ErrorIs(t, q.deliver(context.Background(), entry(webhook.ID)), restricted.ErrAddressNotAllowed)
+
+	attempts, _ := server.stats()
+	assert.Equal(t, 0, attempts)
+	deliveries, err := repo.ListDeliveries(context.Background(), webhook.ID, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Nil(t, deliveries[0].StatusCode)
+		assert.NotNil(t, deliveries[0].Error)
+	}
+}
@@ -92,7 +92,11 @@ func (s *Service) LandOnSturdyAndPushTracked(ctx context.Context, ws *workspaces
 		return fmt.Errorf("failed to land change: %w", err)
 	}
 
-	if err := s.remoteService.PushTrunk(ctx, ws.CodebaseID); err != nil {
+	results, err := s.remoteService.PushTrunk(ctx, ws.CodebaseID)
+	if err != nil {
+		return fmt.Errorf("failed to push trunk: %w", err)
+	}
+	if err := results.Err(); err != nil {
 		return fmt.Errorf("failed to push trunk: %w", err)
 	}
 
@@ -126,6 +126,14 @@ func (r *WorkspaceResolver) DraftDescription() string {
 	return r.w.DraftDescription
 }
 
+func (r *WorkspaceResolver) RenderedDraftDescription(ctx context.Context) (string, error) {
+	linker, err := r.root.autolinksService.Linker(ctx, r.w.CodebaseID)
+	if err != nil {
+		return "", gqlerrors.Error(err)
+	}
+	return linker.HTML(r.w.DraftDescription), nil
+}
+
 func (r *WorkspaceResolver) View(ctx context.Context) (resolvers.ViewResolver, error) {
 	if r.w.ViewID == nil {
 		return nil, nil
@@ -177,6 +185,30 @@ func (r *WorkspaceResolver) GitHubPullRequest(ctx context.Context) (resolvers.Gi
 	}
 }
 
+func (r *WorkspaceResolver) GitLabMergeRequest(ctx context.Context) (resolvers.GitLabMergeRequestResolver, error) {
+	mr, err := r.root.gitLabResolver.InternalGitLabMergeRequestByWorkspaceID(ctx, r.w.ID)
+	switch {
+	case err == nil:
+		return mr, nil
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, gqlerrors.ErrNotFound):
+		return nil, nil
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+}
+
+func (r *WorkspaceResolver) RemoteBranch(ctx context.Context) (resolvers.RemoteBranchResolver, error) {
+	branch, err := r.root.remoteResolver.InternalRemoteBranchByWorkspaceID(ctx, r.w.ID)
+	switch {
+	case err == nil:
+		return branch, nil
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, gqlerrors.ErrNotFound):
+		return nil, nil
+	default:
+		return nil, gqlerrors.Error(err)
+	}
+}
+
 func (r *WorkspaceResolver) UpToDateWithTrunk(ctx context.Context) (bool, error) {
 	if err := r.updateIsUpToDateWithTrunk(ctx); err != nil {
 		return false, gqlerrors.Error(err)
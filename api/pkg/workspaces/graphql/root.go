@@ -6,6 +6,7 @@ import (
 
 	"getsturdy.com/api/pkg/auth"
 	service_auth "getsturdy.com/api/pkg/auth/service"
+	service_autolinks "getsturdy.com/api/pkg/autolinks/service"
 	service_change "getsturdy.com/api/pkg/changes/service"
 	"getsturdy.com/api/pkg/codebases"
 	db_codebases "getsturdy.com/api/pkg/codebases/db"
@@ -40,6 +41,8 @@ type WorkspaceRootResolver struct {
 	viewResolver                  resolvers.ViewRootResolver
 	commentResolver               resolvers.CommentRootResolver
 	prResolver                    resolvers.GitHubPullRequestRootResolver
+	gitLabResolver                resolvers.GitLabRootResolver
+	remoteResolver                resolvers.RemoteRootResolver
 	changeResolver                resolvers.ChangeRootResolver
 	workspaceActivityRootResolver resolvers.ActivityRootResolver
 	reviewRootResolver            resolvers.ReviewRootResolver
@@ -56,6 +59,7 @@ type WorkspaceRootResolver struct {
 	authService        *service_auth.Service
 	changeService      *service_change.Service
 	userService        service_user.Service
+	autolinksService   *service_autolinks.Service
 
 	logger           *zap.Logger
 	viewEvents       events.EventReadWriter
@@ -78,6 +82,8 @@ func NewResolver(
 	viewResolver resolvers.ViewRootResolver,
 	commentResolver resolvers.CommentRootResolver,
 	prResolver resolvers.GitHubPullRequestRootResolver,
+	gitLabResolver resolvers.GitLabRootResolver,
+	remoteResolver resolvers.RemoteRootResolver,
 	changeResolver resolvers.ChangeRootResolver,
 	workspaceActivityRootResolver resolvers.ActivityRootResolver,
 	reviewRootResolver resolvers.ReviewRootResolver,
@@ -94,6 +100,7 @@ func NewResolver(
 	authService *service_auth.Service,
 	changeService *service_change.Service,
 	userService service_user.Service,
+	autolinksService *service_autolinks.Service,
 
 	logger *zap.Logger,
 	viewEventsWriter events.EventReadWriter,
@@ -115,6 +122,8 @@ func NewResolver(
 		viewResolver:                  viewResolver,
 		commentResolver:               commentResolver,
 		prResolver:                    prResolver,
+		gitLabResolver:                gitLabResolver,
+		remoteResolver:                remoteResolver,
 		changeResolver:                changeResolver,
 		workspaceActivityRootResolver: workspaceActivityRootResolver,
 		reviewRootResolver:            reviewRootResolver,
@@ -131,6 +140,7 @@ func NewResolver(
 		authService:        authService,
 		changeService:      changeService,
 		userService:        userService,
+		autolinksService:   autolinksService,
 
 		logger:           logger.Named("workspaceRootResolver"),
 		viewEvents:       viewEventsWriter,
@@ -13,6 +13,8 @@ import (
 	service_activity "getsturdy.com/api/pkg/activity/service"
 	"getsturdy.com/api/pkg/analytics"
 	service_analytics "getsturdy.com/api/pkg/analytics/service"
+	"getsturdy.com/api/pkg/audit"
+	service_audit "getsturdy.com/api/pkg/audit/service"
 	"getsturdy.com/api/pkg/changes"
 	"getsturdy.com/api/pkg/changes/message"
 	service_change "getsturdy.com/api/pkg/changes/service"
@@ -97,6 +99,7 @@ type WorkspaceService struct {
 	executorProvider executor.Provider
 	snap             snapshotter.Snapshotter
 	buildQueue       *workers_ci.BuildQueue
+	auditService     *service_audit.Service
 }
 
 func New(
@@ -121,6 +124,7 @@ func New(
 	snapshotterQueue worker_snapshots.Queue,
 	snap snapshotter.Snapshotter,
 	buildQueue *workers_ci.BuildQueue,
+	auditService *service_audit.Service,
 ) *WorkspaceService {
 	return &WorkspaceService{
 		logger:           logger,
@@ -144,6 +148,7 @@ func New(
 		snapshotterQueue: snapshotterQueue,
 		snap:             snap,
 		buildQueue:       buildQueue,
+		auditService:     auditService,
 	}
 }
 
@@ -664,10 +669,21 @@ func (s *WorkspaceService) LandChange(ctx context.Context, ws *workspaces.Worksp
 		s.logger.Error("failed to send workspace event", zap.Error(err))
 	}
 
+	if err := s.eventsSenderV2.ChangeLanded(ctx, eventsv2.Codebase(ws.CodebaseID), change); err != nil {
+		s.logger.Error("failed to send change landed event", zap.Error(err))
+	}
+
 	if err := s.buildQueue.EnqueueChange(ctx, change); err != nil {
 		s.logger.Error("failed to enqueue change", zap.Error(err))
 	}
 
+	if err := s.auditService.Record(ctx, audit.ActionChangeLanded, audit.TargetChange, string(change.ID),
+		audit.CodebaseID(ws.CodebaseID),
+		audit.After(change),
+	); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	if err := s.Archive(ctx, ws); err != nil {
 		return nil, fmt.Errorf("failed to archive workspace: %w", err)
 	}
@@ -908,6 +924,12 @@ func (s *WorkspaceService) archive(ctx context.Context, ws *workspaces.Workspace
 		return fmt.Errorf("failed to archive workspace: %w", err)
 	}
 
+	if err := s.auditService.Record(ctx, audit.ActionWorkspaceArchived, audit.TargetWorkspace, ws.ID,
+		audit.CodebaseID(ws.CodebaseID),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
 	s.analyticsService.Capture(ctx, "workspace archived", analytics.CodebaseID(ws.CodebaseID),
 		analytics.Property("workspace_id", ws.ID),
 	)
@@ -962,6 +984,12 @@ func (s *WorkspaceService) Unarchive(ctx context.Context, ws *workspaces.Workspa
 	); err != nil {
 		return fmt.Errorf("failed to unarchive workspace: %w", err)
 	}
+
+	if err := s.auditService.Record(ctx, audit.ActionWorkspaceUnarchived, audit.TargetWorkspace, ws.ID,
+		audit.CodebaseID(ws.CodebaseID),
+	); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
 	s.analyticsService.Capture(ctx, "workspace unarchived", analytics.CodebaseID(ws.CodebaseID),
 		analytics.Property("workspace_id", ws.ID),
 	)
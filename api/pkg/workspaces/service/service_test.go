@@ -70,6 +70,7 @@ func setup(t *testing.T) *testCollaborators {
 		nil, // snapshotterQueue
 		gitSnapshotter,
 		buildQueue,
+		nil, // auditService
 	)
 
 	return &testCollaborators{